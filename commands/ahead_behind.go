@@ -0,0 +1,64 @@
+package commands
+
+import (
+	"fmt"
+
+	"github.com/github/hub/git"
+	"github.com/github/hub/github"
+)
+
+// aheadBehind counts the commits of a pull request head that its base branch
+// doesn't have, and the commits on the base branch that the head doesn't have.
+type aheadBehind struct {
+	ahead  int
+	behind int
+}
+
+func (ab *aheadBehind) String() string {
+	return fmt.Sprintf("+%d -%d", ab.ahead, ab.behind)
+}
+
+// localAheadBehindRefs returns the refs to count commits between locally for
+// pr: the remote-tracking branch for the base branch, which is looked up on
+// the git remote for the base repository rather than assuming "origin", and
+// the head commit. It returns false when either isn't available locally.
+func localAheadBehindRefs(localRepo *github.GitHubRepo, pr github.PullRequest) (base, head string, ok bool) {
+	if localRepo == nil || pr.Base == nil || pr.Base.Repo == nil || pr.Head == nil || pr.Head.Sha == "" {
+		return
+	}
+
+	remote, err := localRepo.RemoteForRepo(pr.Base.Repo)
+	if err != nil {
+		return
+	}
+
+	base = fmt.Sprintf("refs/remotes/%s/%s", remote.Name, pr.Base.Ref)
+	if !git.HasCommit(base) || !git.HasCommit(pr.Head.Sha) {
+		return "", "", false
+	}
+	return base, pr.Head.Sha, true
+}
+
+// pullRequestAheadBehind counts commits with git when the base branch has been
+// fetched along with the head commit, and uses the compare API otherwise.
+func pullRequestAheadBehind(gh *github.Client, localRepo *github.GitHubRepo, pr github.PullRequest) (*aheadBehind, error) {
+	if base, head, ok := localAheadBehindRefs(localRepo, pr); ok {
+		if ahead, behind, err := git.AheadBehind(base, head); err == nil {
+			return &aheadBehind{ahead: ahead, behind: behind}, nil
+		}
+	}
+
+	if pr.Base == nil || pr.Base.Repo == nil || pr.Head == nil {
+		return nil, fmt.Errorf("missing base or head information for pull request #%d", pr.Number)
+	}
+	project, err := github.NewProjectFromRepo(pr.Base.Repo)
+	if err != nil {
+		return nil, err
+	}
+
+	comparison, err := gh.FetchComparison(project, pr.Base.Ref, pr.Head.Sha)
+	if err != nil {
+		return nil, err
+	}
+	return &aheadBehind{ahead: comparison.AheadBy, behind: comparison.BehindBy}, nil
+}
@@ -0,0 +1,66 @@
+package commands
+
+import (
+	"testing"
+
+	"github.com/bmizerany/assert"
+	"github.com/github/hub/fixtures"
+	"github.com/github/hub/git"
+	"github.com/github/hub/github"
+)
+
+func TestLocalAheadBehindRefs(t *testing.T) {
+	repo := fixtures.SetupTestRepo()
+	defer repo.TearDown()
+
+	repo.AddRemote("mislav", "https://github.com/mislav/hub.git", "")
+	repo.AddRemote("upstream", "https://github.com/github/hub.git", "")
+	localRepo, err := github.LocalRepo()
+	assert.Equal(t, nil, err)
+
+	pr := github.PullRequest{
+		Number: 12,
+		Base: &github.PullRequestSpec{
+			Ref: "master",
+			Repo: &github.Repository{
+				Name:    "hub",
+				Owner:   &github.User{Login: "github"},
+				HtmlUrl: "https://github.com/github/hub",
+			},
+		},
+		Head: &github.PullRequestSpec{
+			Ref: "feature",
+			Sha: "9b5a719a3d76ac9dc2fa635d9b1f34fd73994c06",
+		},
+	}
+
+	// the base branch was only fetched from the fork, not from upstream
+	assert.T(t, git.Quiet("update-ref", "refs/remotes/mislav/master", "08f4b7b6513dffc6245857e497cfd6101dc47818"))
+	_, _, ok := localAheadBehindRefs(localRepo, pr)
+	assert.Equal(t, false, ok)
+
+	assert.T(t, git.Quiet("update-ref", "refs/remotes/upstream/master", "08f4b7b6513dffc6245857e497cfd6101dc47818"))
+	base, head, ok := localAheadBehindRefs(localRepo, pr)
+	assert.Equal(t, true, ok)
+	assert.Equal(t, "refs/remotes/upstream/master", base)
+	assert.Equal(t, "9b5a719a3d76ac9dc2fa635d9b1f34fd73994c06", head)
+
+	ab, err := pullRequestAheadBehind(nil, localRepo, pr)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, "+1 -0", ab.String())
+
+	// the head commit hasn't been fetched
+	pr.Head.Sha = "0000000000000000000000000000000000000001"
+	_, _, ok = localAheadBehindRefs(localRepo, pr)
+	assert.Equal(t, false, ok)
+
+	// there's no git remote for the base repository
+	pr.Head.Sha = "9b5a719a3d76ac9dc2fa635d9b1f34fd73994c06"
+	pr.Base.Repo = &github.Repository{
+		Name:    "hub",
+		Owner:   &github.User{Login: "jingweno"},
+		HtmlUrl: "https://github.com/jingweno/hub",
+	}
+	_, _, ok = localAheadBehindRefs(localRepo, pr)
+	assert.Equal(t, false, ok)
+}
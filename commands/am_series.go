@@ -0,0 +1,312 @@
+package commands
+
+import (
+	"fmt"
+	"io/ioutil"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/github/hub/cmd"
+	"github.com/github/hub/git"
+	"github.com/github/hub/github"
+)
+
+var (
+	mboxFromRegexp   = regexp.MustCompile(`^From ([0-9a-f]{40}) Mon Sep 17 00:00:00 2001$`)
+	patchTagRegexp   = regexp.MustCompile(`^\[PATCH[^\]]*\]\s*`)
+	excludeSHARegexp = regexp.MustCompile(`^[0-9a-f]{7,40}$`)
+)
+
+// seriesPatch is one commit of the mbox that GitHub serves for a pull request.
+// The From and Date headers of the patch carry the original authorship, which
+// git-am(1) preserves.
+type seriesPatch struct {
+	Number  int
+	Sha     string
+	Author  string
+	Subject string
+	content string
+}
+
+// amSeries holds the options of `hub am` that control which commits of a pull
+// request get applied, and onto which branch.
+type amSeries struct {
+	interactive bool
+	excludes    []string
+	onto        string
+
+	project *github.Project
+	number  string
+	total   int
+	patches []*seriesPatch
+}
+
+// parseAmSeriesFlags removes the series options from the arguments to git-am.
+// Since git-am(1) has an '--interactive' mode and an '--exclude=<path>' option
+// of its own, those are only claimed when a pull request is being applied and,
+// in the case of '--exclude', when the value looks like a commit SHA.
+func parseAmSeriesFlags(args *Args) *amSeries {
+	series := &amSeries{}
+	params := []string{}
+	for i := 0; i < len(args.Params); i++ {
+		arg := args.Params[i]
+		name, value, hasValue := arg, "", false
+		if strings.HasPrefix(arg, "--") && strings.Contains(arg, "=") {
+			parts := strings.SplitN(arg, "=", 2)
+			name, value, hasValue = parts[0], parts[1], true
+		}
+		nextValue := func() string {
+			if !hasValue && i+1 < len(args.Params) {
+				i++
+				value = args.Params[i]
+			}
+			return value
+		}
+
+		switch name {
+		case "-i", "--interactive":
+			series.interactive = true
+		case "--onto":
+			series.onto = nextValue()
+		case "--exclude":
+			if !hasValue && (i+1 >= len(args.Params) || !excludeSHARegexp.MatchString(args.Params[i+1])) ||
+				hasValue && !excludeSHARegexp.MatchString(value) {
+				params = append(params, arg)
+				continue
+			}
+			series.excludes = append(series.excludes, nextValue())
+		default:
+			params = append(params, arg)
+		}
+	}
+
+	if !series.isSet() {
+		return nil
+	}
+	args.Params = params
+	return series
+}
+
+func (s *amSeries) isSet() bool {
+	return s.interactive || len(s.excludes) > 0 || s.onto != ""
+}
+
+// parseSeries splits a pull request mbox into its commits.
+func parseSeries(mbox string) []*seriesPatch {
+	patches := []*seriesPatch{}
+	var current *seriesPatch
+	inHeaders := false
+	lastHeader := ""
+
+	lines := strings.SplitAfter(mbox, "\n")
+	for _, line := range lines {
+		trimmed := strings.TrimRight(line, "\r\n")
+		if match := mboxFromRegexp.FindStringSubmatch(trimmed); match != nil {
+			current = &seriesPatch{Number: len(patches) + 1, Sha: match[1]}
+			patches = append(patches, current)
+			inHeaders = true
+		} else if current != nil && inHeaders {
+			if trimmed == "" {
+				inHeaders = false
+			} else if strings.HasPrefix(trimmed, " ") || strings.HasPrefix(trimmed, "\t") {
+				if lastHeader == "subject" {
+					current.Subject += " " + strings.TrimSpace(trimmed)
+				}
+			} else if strings.HasPrefix(trimmed, "From: ") {
+				lastHeader = "from"
+				current.Author = strings.TrimPrefix(trimmed, "From: ")
+			} else if strings.HasPrefix(trimmed, "Subject: ") {
+				lastHeader = "subject"
+				current.Subject = strings.TrimPrefix(trimmed, "Subject: ")
+			} else {
+				lastHeader = ""
+			}
+		}
+		if current != nil {
+			current.content += line
+		}
+	}
+
+	for _, patch := range patches {
+		patch.Subject = patchTagRegexp.ReplaceAllString(patch.Subject, "")
+		if i := strings.Index(patch.Author, " <"); i > 0 {
+			patch.Author = patch.Author[:i]
+		}
+	}
+	return patches
+}
+
+func (p *seriesPatch) shortSha() string {
+	return p.Sha[:7]
+}
+
+// exclude drops the patches whose SHA starts with any of the given SHAs.
+func (s *amSeries) exclude() error {
+	for _, sha := range s.excludes {
+		found := false
+		patches := []*seriesPatch{}
+		for _, patch := range s.patches {
+			if strings.HasPrefix(patch.Sha, sha) {
+				found = true
+			} else {
+				patches = append(patches, patch)
+			}
+		}
+		if !found {
+			return fmt.Errorf("Error: commit %s is not part of pull request #%s", sha, s.number)
+		}
+		s.patches = patches
+	}
+	return nil
+}
+
+func (s *amSeries) todoList() string {
+	lines := []string{}
+	for _, patch := range s.patches {
+		lines = append(lines, fmt.Sprintf("pick %s %s: %s", patch.shortSha(), patch.Author, patch.Subject))
+	}
+	return strings.Join(lines, "\n") + "\n"
+}
+
+// pick keeps the patches that are marked with "pick" in an edited todo list,
+// in their original order.
+func (s *amSeries) pick(todo, cs string) error {
+	picked := map[string]bool{}
+	for _, line := range strings.Split(todo, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) == 0 || strings.HasPrefix(fields[0], cs) {
+			continue
+		}
+		if len(fields) < 2 {
+			return fmt.Errorf("Error: invalid line in commit list: %q", line)
+		}
+		switch fields[0] {
+		case "pick", "p":
+			picked[fields[1]] = true
+		case "skip", "s", "drop", "d":
+		default:
+			return fmt.Errorf("Error: unknown command %q in commit list", fields[0])
+		}
+	}
+
+	patches := []*seriesPatch{}
+	for _, patch := range s.patches {
+		if picked[patch.shortSha()] {
+			patches = append(patches, patch)
+		}
+	}
+	s.patches = patches
+	return nil
+}
+
+func (s *amSeries) edit() error {
+	editor, err := github.NewEditor("AM_SERIES_EDITMSG", "commit list", s.todoList())
+	if err != nil {
+		return err
+	}
+	editor.AddCommentedLines(fmt.Sprintf(`Commits of pull request #%s to apply, in order.
+
+Commands:
+pick <commit> = apply the commit
+skip <commit> = leave the commit out
+
+Removing a line also leaves the commit out.`, s.number))
+
+	todo, err := editor.EditContent()
+	if err != nil {
+		return err
+	}
+	defer editor.DeleteFile()
+	return s.pick(todo, editor.CS)
+}
+
+// prepare narrows down the commits of the pull request mbox and writes the
+// remaining ones to patchFile.
+func (s *amSeries) prepare(mbox []byte, patchFile string) error {
+	s.patches = parseSeries(string(mbox))
+	s.total = len(s.patches)
+	if err := s.exclude(); err != nil {
+		return err
+	}
+	if s.interactive && len(s.patches) > 0 {
+		if err := s.edit(); err != nil {
+			return err
+		}
+	}
+	if len(s.patches) == 0 {
+		return fmt.Errorf("Error: no commits of pull request #%s left to apply", s.number)
+	}
+
+	content := ""
+	for _, patch := range s.patches {
+		content += patch.content
+	}
+	return ioutil.WriteFile(patchFile, []byte(content), 0644)
+}
+
+// checkoutOnto switches to the '--onto' branch before applying, creating it
+// from the base branch of the pull request if it doesn't exist yet.
+func (s *amSeries) checkoutOnto(gh *github.Client, args *Args) error {
+	if git.HasFile("refs", "heads", s.onto) {
+		args.Before("git", "checkout", s.onto)
+		return nil
+	}
+
+	pullRequest, err := gh.PullRequest(s.project, s.number)
+	if err != nil {
+		return err
+	}
+
+	startPoint := "FETCH_HEAD"
+	baseRef := "refs/heads/" + pullRequest.Base.Ref
+	remote := s.project.GitURL("", "", false)
+	if localRepo, err := github.LocalRepo(); err == nil {
+		if baseRemote, err := localRepo.RemoteForProject(s.project); err == nil {
+			remote = baseRemote.Name
+			startPoint = fmt.Sprintf("refs/remotes/%s/%s", baseRemote.Name, pullRequest.Base.Ref)
+			baseRef = fmt.Sprintf("+%s:%s", baseRef, startPoint)
+		}
+	}
+
+	args.Before("git", "fetch", remote, baseRef)
+	args.Before("git", "checkout", "-b", s.onto, "--no-track", startPoint)
+	return nil
+}
+
+// apply runs git-am(1) with the prepared patches and, when one of them
+// doesn't apply, reports which commit of the pull request it was.
+func (s *amSeries) apply(amArgs []string) error {
+	am := cmd.New("git")
+	am.WithArgs(git.GlobalFlags...)
+	am.WithArg("am")
+	am.WithArgs(amArgs...)
+	if err := am.Spawn(); err == nil {
+		return nil
+	}
+
+	patch := s.failedPatch()
+	if patch == nil {
+		return fmt.Errorf("Error: applying pull request #%s failed", s.number)
+	}
+	return fmt.Errorf("Error: commit %d/%d of pull request #%s (%s %q) failed to apply\n%s",
+		patch.Number, s.total, s.number, patch.shortSha(), patch.Subject,
+		s.project.WebURL("", "", fmt.Sprintf("pull/%s/commits/%s", s.number, patch.Sha)))
+}
+
+// failedPatch looks up the patch that git-am stopped at.
+func (s *amSeries) failedPatch() *seriesPatch {
+	nextFile, err := git.GitPath("rebase-apply", "next")
+	if err != nil {
+		return nil
+	}
+	content, err := ioutil.ReadFile(nextFile)
+	if err != nil {
+		return nil
+	}
+	next, err := strconv.Atoi(strings.TrimSpace(string(content)))
+	if err != nil || next < 1 || next > len(s.patches) {
+		return nil
+	}
+	return s.patches[next-1]
+}
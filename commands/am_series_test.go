@@ -0,0 +1,100 @@
+package commands
+
+import (
+	"testing"
+
+	"github.com/bmizerany/assert"
+)
+
+const testSeriesMbox = `From 1111111111111111111111111111111111111111 Mon Sep 17 00:00:00 2001
+From: Mislav <mislav.marohnic@gmail.com>
+Date: Tue, 24 Jun 2014 11:07:05 -0700
+Subject: [PATCH 1/3] Create a README
+
+---
+ README.md | 1 +
+-- 
+2.20.1
+
+From 2222222222222222222222222222222222222222 Mon Sep 17 00:00:00 2001
+From: Jingwen Owen Ou <jingweno@gmail.com>
+Date: Wed, 25 Jun 2014 09:00:00 -0700
+Subject: [PATCH 2/3] Add a rather long subject line that git folded over
+ two lines
+
+---
+ main.go | 2 +-
+-- 
+2.20.1
+
+From 3333333333333333333333333333333333333333 Mon Sep 17 00:00:00 2001
+From: Mislav <mislav.marohnic@gmail.com>
+Date: Thu, 26 Jun 2014 10:00:00 -0700
+Subject: [PATCH 3/3] Fix typo
+
+---
+ README.md | 2 +-
+-- 
+2.20.1
+`
+
+func TestParseSeries(t *testing.T) {
+	patches := parseSeries(testSeriesMbox)
+	assert.Equal(t, 3, len(patches))
+
+	assert.Equal(t, 1, patches[0].Number)
+	assert.Equal(t, "1111111111111111111111111111111111111111", patches[0].Sha)
+	assert.Equal(t, "Mislav", patches[0].Author)
+	assert.Equal(t, "Create a README", patches[0].Subject)
+
+	assert.Equal(t, "Jingwen Owen Ou", patches[1].Author)
+	assert.Equal(t, "Add a rather long subject line that git folded over two lines", patches[1].Subject)
+
+	content := ""
+	for _, patch := range patches {
+		content += patch.content
+	}
+	assert.Equal(t, testSeriesMbox, content)
+}
+
+func TestParseAmSeriesFlags(t *testing.T) {
+	args := NewArgs([]string{"am", "-3", "--exclude", "2222222", "--exclude=docs/*", "--onto=backport", "-i", "https://github.com/github/hub/pull/55"})
+	series := parseAmSeriesFlags(args)
+	assert.Equal(t, []string{"-3", "--exclude=docs/*", "https://github.com/github/hub/pull/55"}, args.Params)
+	assert.Equal(t, []string{"2222222"}, series.excludes)
+	assert.Equal(t, "backport", series.onto)
+	assert.Equal(t, true, series.interactive)
+
+	args = NewArgs([]string{"am", "--exclude", "vendor", "https://github.com/github/hub/pull/55"})
+	assert.Equal(t, (*amSeries)(nil), parseAmSeriesFlags(args))
+	assert.Equal(t, []string{"--exclude", "vendor", "https://github.com/github/hub/pull/55"}, args.Params)
+}
+
+func TestAmSeriesExclude(t *testing.T) {
+	series := &amSeries{number: "55", excludes: []string{"3333333", "1111111111"}}
+	series.patches = parseSeries(testSeriesMbox)
+	assert.Equal(t, nil, series.exclude())
+	assert.Equal(t, 1, len(series.patches))
+	assert.Equal(t, 2, series.patches[0].Number)
+
+	series = &amSeries{number: "55", excludes: []string{"4444444"}}
+	series.patches = parseSeries(testSeriesMbox)
+	assert.Equal(t, "Error: commit 4444444 is not part of pull request #55", series.exclude().Error())
+}
+
+func TestAmSeriesPick(t *testing.T) {
+	series := &amSeries{number: "55", patches: parseSeries(testSeriesMbox)}
+	assert.Equal(t, `pick 1111111 Mislav: Create a README
+pick 2222222 Jingwen Owen Ou: Add a rather long subject line that git folded over two lines
+pick 3333333 Mislav: Fix typo
+`, series.todoList())
+
+	err := series.pick("pick 3333333 Mislav: Fix typo\nskip 2222222\n# pick 1111111\np 1111111 Mislav: Create a README\n", "#")
+	assert.Equal(t, nil, err)
+	assert.Equal(t, 2, len(series.patches))
+	assert.Equal(t, 1, series.patches[0].Number)
+	assert.Equal(t, 3, series.patches[1].Number)
+
+	err = series.pick("squash 3333333\n", "#")
+	assert.Equal(t, `Error: unknown command "squash" in commit list`, err.Error())
+}
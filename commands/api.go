@@ -1,13 +1,18 @@
 package commands
 
 import (
+	"bytes"
+	"encoding/json"
 	"fmt"
 	"io"
 	"io/ioutil"
 	"os"
+	"os/signal"
 	"regexp"
+	"sort"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/github/hub/github"
 	"github.com/github/hub/ui"
@@ -15,8 +20,12 @@ import (
 )
 
 var cmdApi = &Command{
-	Run:   apiCommand,
-	Usage: "api [-it] [-X <METHOD>] [-H <HEADER>] [--cache <TTL>] <ENDPOINT> [-F <FIELD>|--input <FILE>]",
+	Run: apiCommand,
+	Usage: `
+api [-it] [-X <METHOD>] [-H <HEADER>] [-o <FILE>] [--paginate] [--obey-rate-limit=false] [--cache <TTL>] [--idempotency-key <KEY>] <ENDPOINT> [-F <FIELD>|--input <FILE>]
+api [-it] [--paginate] [--cache <TTL>] graphql (--query-file <FILE>|--saved <NAME>) [-F <FIELD>]
+api --list-saved
+`,
 	Long: `Low-level GitHub API request interface.
 
 ## Options:
@@ -38,20 +47,41 @@ var cmdApi = &Command{
 		If <VALUE> is "true", "false", "null", or looks like a number, an
 		appropriate JSON type is used instead of a string.
 
-		It is not possible to serialize <VALUE> as a nested JSON array or hash.
-		Instead, construct the request payload externally and pass it via
-		'--input'.
+		If <KEY> ends with ":", as in '-F labels:=["bug", "docs"]', <VALUE> is
+		parsed as JSON, which may be a nested array or hash. Combine it with "@"
+		to read the JSON from a file. To construct the whole request payload
+		externally, pass it via '--input' instead.
+
+		For GraphQL queries, the <VALUE> "@me" stands for the login of the
+		authenticated user.
 
 		Unless '-XGET' was used, all fields are sent serialized as JSON within the
 		request body. When <ENDPOINT> is "graphql", all fields other than "query"
 		are grouped under "variables". See
 		<https://graphql.org/learn/queries/#variables>
 
+		For GraphQL queries, '-F variables=<JSON>' is merged into "variables"
+		as a JSON object, which may have nested values. Combine it with "@" to
+		read the object from a file, e.g. '-F variables=@vars.json'. Other fields
+		take precedence over values of the same name in that object.
+
 	-f, --raw-field <KEY>=<VALUE>
 		Same as '--field', except that it allows values starting with "@", literal
 		strings "true", "false", and "null", as well as strings that look like
 		numbers.
 
+	--query-file <FILE>
+		Read the GraphQL query from <FILE>, like '-F query=@<FILE>' does.
+
+	--saved <NAME>
+		Read the GraphQL query from the file "<NAME>.graphql" in the library of
+		saved queries, which is the "hub-queries" directory within
+		'$XDG_CONFIG_HOME' or "~/.config".
+
+	--list-saved
+		List the names of the saved queries. A comment on the first line of a
+		query file is shown as its description.
+
 	--input <FILE>
 		The filename to read the raw request body from. Use "-" to read from standard
 		input. Use this when you want to manually construct the request payload.
@@ -64,12 +94,37 @@ var cmdApi = &Command{
 
 	-t, --flat
 		Parse response JSON and output the data in a line-based key-value format
-		suitable for use in shell scripts.
+		suitable for use in shell scripts. Responses that aren't JSON, such as
+		archives, are output as-is.
+
+	-o, --output <FILE>
+		Write the response to <FILE> instead of standard output. The body is
+		streamed to disk as it's received, which makes this suitable for
+		downloading large or binary content such as repository archives.
+		Redirects to hosts other than the GitHub host are followed without
+		sending the access token along.
 
 	--color[=<WHEN>]
 		Enable colored output even if stdout is not a terminal. <WHEN> can be one
 		of "always" (default for '--color'), "never", or "auto" (default).
 
+	--paginate
+		Automatically request and output the next page of results until all
+		resources have been listed. For REST endpoints, this follows the "next"
+		resource of the "Link" response header. For GraphQL queries, this passes
+		the "endCursor" of a "pageInfo" object that has "hasNextPage" set as the
+		'$endCursor' variable, which the query has to declare; see EXAMPLES.
+
+		Every page is output as a separate JSON document.
+
+	--obey-rate-limit[=<BOOL>]
+		When the API rate limit is exhausted, pause until it resets and then carry
+		on, printing a notice to standard error. This applies to the primary rate
+		limit, which may mean a pause of up to an hour, and to secondary rate
+		limits that ask to retry after a while. Pausing can be cut short with
+		Ctrl-C. Enabled by default; use '--obey-rate-limit=false' to fail as soon
+		as a rate limit is hit instead.
+
 	--cache <TTL>
 		Cache successful responses to GET requests for <TTL> seconds.
 
@@ -77,6 +132,15 @@ var cmdApi = &Command{
 		requests as well. Just make sure to not use '--cache' for any GraphQL
 		mutations.
 
+		Unlike the cache enabled with 'HUB_CACHE' (see hub(1)), these responses
+		are reused without asking GitHub whether they have changed.
+
+	--idempotency-key <KEY>
+		Record the output of a successful request under <KEY>. When the command is
+		repeated with the same <KEY> within 24 hours, the recorded output is
+		printed again instead of sending the request. Use this to safely retry
+		requests that create or modify resources.
+
 	<ENDPOINT>
 		The GitHub API endpoint to send the HTTP request to (default: "/").
 		
@@ -98,9 +162,32 @@ var cmdApi = &Command{
 		# post a comment to issue #23 of the current repository
 		$ hub api repos/{owner}/{repo}/issues/23/comments --raw-field "body=Nice job!"
 
+		# download a tarball of the main branch
+		$ hub api repos/{owner}/{repo}/tarball/main -o main.tgz
+
 		# perform a GraphQL query read from a file
 		$ hub api graphql -F query=@path/to/myquery.graphql
 
+		# perform a saved GraphQL query with variables
+		$ cat ~/.config/hub-queries/review-queue.graphql
+		# Pull requests awaiting my review
+		query($login: String!, $first: Int!) { ... }
+		$ hub api graphql --saved review-queue -F login=@me -F first=20
+
+		# list all issues of the current repository
+		$ hub api --paginate repos/{owner}/{repo}/issues
+
+		# list all repositories of a user using GraphQL pagination
+		$ hub api --paginate graphql -f query='
+		  query($endCursor: String) {
+		    viewer {
+		      repositories(first: 100, after: $endCursor) {
+		        nodes { nameWithOwner }
+		        pageInfo { hasNextPage endCursor }
+		      }
+		    }
+		  }'
+
 ## See also:
 
 hub(1)
@@ -120,30 +207,50 @@ func apiCommand(cmd *Command, args *Args) {
 	method := "GET"
 	if args.Flag.HasReceived("--method") {
 		method = args.Flag.Value("--method")
-	} else if args.Flag.HasReceived("--field") || args.Flag.HasReceived("--raw-field") || args.Flag.HasReceived("--input") {
+	} else if args.Flag.HasReceived("--field") || args.Flag.HasReceived("--raw-field") || args.Flag.HasReceived("--input") ||
+		args.Flag.HasReceived("--query-file") || args.Flag.HasReceived("--saved") {
 		method = "POST"
 	}
 	cacheTTL := args.Flag.Int("--cache")
 
-	params := make(map[string]interface{})
-	for _, val := range args.Flag.AllValues("--field") {
-		parts := strings.SplitN(val, "=", 2)
-		if len(parts) >= 2 {
-			params[parts[0]] = magicValue(parts[1])
-		}
+	outputFile := args.Flag.Value("--output")
+	if outputFile != "" && args.Flag.HasReceived("--idempotency-key") {
+		utils.Check(cmd.UsageError("'--output' can't be combined with '--idempotency-key'"))
 	}
-	for _, val := range args.Flag.AllValues("--raw-field") {
-		parts := strings.SplitN(val, "=", 2)
-		if len(parts) >= 2 {
-			params[parts[0]] = parts[1]
+
+	if args.Flag.Bool("--list-saved") {
+		dir, err := savedQueriesDir()
+		utils.Check(err)
+		queries, err := listSavedQueries(dir)
+		utils.Check(err)
+		nameWidth := 0
+		for _, query := range queries {
+			if len(query.name) > nameWidth {
+				nameWidth = len(query.name)
+			}
+		}
+		for _, query := range queries {
+			ui.Println(strings.TrimRight(fmt.Sprintf("%-*s  %s", nameWidth, query.name, query.description), " "))
 		}
+		args.NoForward()
+		return
 	}
 
-	headers := make(map[string]string)
-	for _, val := range args.Flag.AllValues("--header") {
-		parts := strings.SplitN(val, ":", 2)
-		if len(parts) >= 2 {
-			headers[parts[0]] = strings.TrimLeft(parts[1], " ")
+	savedQuery := ""
+	if args.Flag.HasReceived("--query-file") || args.Flag.HasReceived("--saved") {
+		if path != "graphql" {
+			utils.Check(cmd.UsageError("'--query-file' and '--saved' can only be used with the \"graphql\" endpoint"))
+		}
+		if args.Flag.HasReceived("--query-file") && args.Flag.HasReceived("--saved") {
+			utils.Check(cmd.UsageError("the '--query-file' and '--saved' options are mutually exclusive"))
+		}
+		if args.Flag.HasReceived("--query-file") {
+			savedQuery = string(readFile(args.Flag.Value("--query-file")))
+		} else {
+			dir, err := savedQueriesDir()
+			utils.Check(err)
+			savedQuery, err = readSavedQuery(dir, args.Flag.Value("--saved"))
+			utils.Check(err)
 		}
 	}
 
@@ -165,6 +272,49 @@ func apiCommand(cmd *Command, args *Args) {
 		host = defHost.Host
 	}
 
+	login := func() (string, error) {
+		h, err := github.CurrentConfig().PromptForHost(host)
+		if err != nil {
+			return "", err
+		}
+		return h.User, nil
+	}
+
+	params := make(map[string]interface{})
+	var variablesJSON interface{}
+	for _, val := range args.Flag.AllValues("--field") {
+		parts := strings.SplitN(val, "=", 2)
+		if len(parts) >= 2 {
+			if path == "graphql" && parts[0] == "variables" {
+				variablesJSON = magicValue(parts[1])
+				continue
+			}
+			key, value, err := apiFieldValue(parts[0], parts[1], path == "graphql", login)
+			utils.Check(err)
+			params[key] = value
+		}
+	}
+	for _, val := range args.Flag.AllValues("--raw-field") {
+		parts := strings.SplitN(val, "=", 2)
+		if len(parts) >= 2 {
+			params[parts[0]] = parts[1]
+		}
+	}
+	if savedQuery != "" {
+		if _, found := params["query"]; found {
+			utils.Check(cmd.UsageError("the \"query\" field can't be combined with '--query-file' or '--saved'"))
+		}
+		params["query"] = savedQuery
+	}
+
+	headers := make(map[string]string)
+	for _, val := range args.Flag.AllValues("--header") {
+		parts := strings.SplitN(val, ":", 2)
+		if len(parts) >= 2 {
+			headers[parts[0]] = strings.TrimLeft(parts[1], " ")
+		}
+	}
+
 	if path == "graphql" && params["query"] != nil {
 		query := params["query"].(string)
 		query = strings.Replace(query, quote("{owner}"), quote(owner), 1)
@@ -176,6 +326,22 @@ func apiCommand(cmd *Command, args *Args) {
 				variables[key] = value
 			}
 		}
+		if variablesJSON != nil {
+			objectVariables, err := parseGraphQLVariables(variablesJSON)
+			utils.Check(err)
+			keys := make([]string, 0, len(objectVariables))
+			for key := range objectVariables {
+				keys = append(keys, key)
+			}
+			sort.Strings(keys)
+			for _, key := range keys {
+				if _, found := variables[key]; found {
+					ui.Errorf("warning: the '%s' field overrides the variable of the same name in 'variables'\n", key)
+					continue
+				}
+				variables[key] = objectVariables[key]
+			}
+		}
 		if len(variables) > 0 {
 			params = make(map[string]interface{})
 			params["variables"] = variables
@@ -187,49 +353,126 @@ func apiCommand(cmd *Command, args *Args) {
 		path = strings.Replace(path, "{repo}", repo, 1)
 	}
 
-	var body interface{}
+	var input []byte
 	if args.Flag.HasReceived("--input") {
-		fn := args.Flag.Value("--input")
-		if fn == "-" {
-			body = os.Stdin
-		} else {
-			fi, err := os.Open(fn)
-			utils.Check(err)
-			body = fi
-			defer fi.Close()
-		}
-	} else {
-		body = params
+		input = readFile(args.Flag.Value("--input"))
 	}
 
 	gh := github.NewClient(host)
-	response, err := gh.GenericAPIRequest(method, path, body, headers, cacheTTL)
-	utils.Check(err)
-
-	args.NoForward()
-
-	out := ui.Stdout
 	colorize := colorizeOutput(args.Flag.HasReceived("--color"), args.Flag.Value("--color"))
-	success := response.StatusCode < 300
-	parseJSON := args.Flag.Bool("--flat")
+	paginate := args.Flag.Bool("--paginate")
+	obeyRateLimit := !args.Flag.HasReceived("--obey-rate-limit") || args.Flag.Bool("--obey-rate-limit")
+	isGraphQL := path == "graphql" && params["query"] != nil
+	success := false
 
-	if !success {
-		jsonType, _ := regexp.MatchString(`[/+]json(?:;|$)`, response.Header.Get("Content-Type"))
-		parseJSON = parseJSON && jsonType
+	if obeyRateLimit {
+		gh.PauseOnRateLimit(waitForRateLimit)
 	}
 
-	if args.Flag.Bool("--include") {
-		fmt.Fprintf(out, "%s %s\r\n", response.Proto, response.Status)
-		response.Header.Write(out)
-		fmt.Fprintf(out, "\r\n")
+	performRequest := func(out io.Writer) error {
+		for {
+			var body interface{} = params
+			if input != nil {
+				body = bytes.NewReader(input)
+			}
+			response, err := gh.GenericAPIRequest(method, path, body, headers, cacheTTL)
+			if err != nil {
+				return err
+			}
+
+			success = response.StatusCode < 300
+			jsonType, _ := regexp.MatchString(`[/+]json(?:;|$)`, response.Header.Get("Content-Type"))
+			parseJSON := args.Flag.Bool("--flat") && jsonType
+
+			if args.Flag.Bool("--include") {
+				fmt.Fprintf(out, "%s %s\r\n", response.Proto, response.Status)
+				response.Header.Write(out)
+				fmt.Fprintf(out, "\r\n")
+			}
+
+			// the GraphQL cursor for the next page is only known once the
+			// response has been read in full
+			var responseBody io.Reader = response.Body
+			endCursor := ""
+			if paginate && success && isGraphQL && jsonType {
+				data, err := ioutil.ReadAll(response.Body)
+				if err != nil {
+					response.Body.Close()
+					return err
+				}
+				endCursor = graphQLEndCursor(data)
+				responseBody = bytes.NewReader(data)
+			}
+
+			if parseJSON {
+				utils.JSONPath(out, responseBody, colorize)
+			} else if outputFile != "" {
+				progress := ui.NewProgress("Downloading "+outputFile, response.ContentLength)
+				_, err = io.Copy(progress.Writer(out), responseBody)
+				progress.Done()
+			} else {
+				io.Copy(out, responseBody)
+			}
+			response.Body.Close()
+			if err != nil || !paginate || !success {
+				return err
+			}
+
+			if isGraphQL {
+				if endCursor == "" {
+					return nil
+				}
+				variables, _ := params["variables"].(map[string]interface{})
+				if variables == nil {
+					variables = map[string]interface{}{}
+					params["variables"] = variables
+				}
+				variables["endCursor"] = endCursor
+			} else if next := response.Link("next"); next != "" {
+				path = next
+				// the link to the next page already has the query string
+				if method == "GET" {
+					params = map[string]interface{}{}
+				}
+			} else {
+				return nil
+			}
+
+			if obeyRateLimit {
+				if err := waitForRateLimit(response.RateLimitWait(time.Now())); err != nil {
+					return err
+				}
+			}
+		}
 	}
 
-	if parseJSON {
-		utils.JSONPath(out, response.Body, colorize)
+	args.NoForward()
+
+	if outputFile != "" {
+		file, err := os.Create(outputFile)
+		utils.Check(err)
+		err = performRequest(file)
+		if closeErr := file.Close(); err == nil {
+			err = closeErr
+		}
+		if err != nil {
+			os.Remove(outputFile)
+		}
+		utils.Check(err)
+	} else if args.Flag.HasReceived("--idempotency-key") {
+		output, replayed, err := performIdempotently(args, host, func() (string, bool, error) {
+			out := &bytes.Buffer{}
+			err := performRequest(out)
+			return out.String(), success, err
+		})
+		utils.Check(err)
+		if replayed {
+			success = true
+		}
+		io.WriteString(ui.Stdout, output)
 	} else {
-		io.Copy(out, response.Body)
+		utils.Check(performRequest(ui.Stdout))
 	}
-	response.Body.Close()
 
 	if !success {
 		os.Exit(22)
@@ -272,6 +515,91 @@ func readFile(file string) (content []byte) {
 	return
 }
 
+// parseGraphQLVariables reads the JSON object passed as the "variables" field
+// of a GraphQL query. Numbers are kept as they were written so that large
+// integers don't lose precision.
+func parseGraphQLVariables(value interface{}) (map[string]interface{}, error) {
+	data, ok := value.(string)
+	if !ok {
+		return nil, fmt.Errorf("the 'variables' field must be a JSON object")
+	}
+	variables := map[string]interface{}{}
+	decoder := json.NewDecoder(strings.NewReader(data))
+	decoder.UseNumber()
+	if err := decoder.Decode(&variables); err != nil {
+		return nil, fmt.Errorf("the 'variables' field must be a JSON object: %s", err)
+	}
+	return variables, nil
+}
+
+// waitForRateLimit pauses for as long as the API rate limit requires, unless
+// the user interrupts it with Ctrl-C.
+func waitForRateLimit(wait time.Duration) error {
+	if wait <= 0 {
+		return nil
+	}
+	if wait%time.Second != 0 {
+		wait = wait.Truncate(time.Second) + time.Second
+	}
+	ui.Errorf("API rate limit exceeded; pausing until %s (%s) before continuing...\n", time.Now().Add(wait).Format("15:04:05"), wait)
+
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, os.Interrupt)
+	defer signal.Stop(signals)
+
+	select {
+	case <-time.After(wait):
+		return nil
+	case <-signals:
+		return utils.WithExitStatus(fmt.Errorf("interrupted while waiting for the API rate limit to reset"), utils.ExitInterrupted)
+	}
+}
+
+// graphQLEndCursor finds the first "pageInfo" object in a GraphQL response
+// that has another page and returns its "endCursor", or an empty string if
+// there's no next page.
+func graphQLEndCursor(data []byte) string {
+	var response interface{}
+	if err := json.Unmarshal(data, &response); err != nil {
+		return ""
+	}
+
+	var find func(node interface{}) string
+	find = func(node interface{}) string {
+		switch value := node.(type) {
+		case map[string]interface{}:
+			if pageInfo, ok := value["pageInfo"].(map[string]interface{}); ok {
+				if hasNextPage, _ := pageInfo["hasNextPage"].(bool); hasNextPage {
+					if endCursor, ok := pageInfo["endCursor"].(string); ok && endCursor != "" {
+						return endCursor
+					}
+				}
+			}
+			keys := make([]string, 0, len(value))
+			for key := range value {
+				keys = append(keys, key)
+			}
+			sort.Strings(keys)
+			for _, key := range keys {
+				if endCursor := find(value[key]); endCursor != "" {
+					return endCursor
+				}
+			}
+		case []interface{}:
+			for _, item := range value {
+				if endCursor := find(item); endCursor != "" {
+					return endCursor
+				}
+			}
+		}
+		return ""
+	}
+	if root, ok := response.(map[string]interface{}); ok {
+		return find(root["data"])
+	}
+	return ""
+}
+
 func quote(s string) string {
 	return fmt.Sprintf("%q", s)
 }
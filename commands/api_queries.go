@@ -0,0 +1,118 @@
+package commands
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/mitchellh/go-homedir"
+)
+
+// savedQueryExt is the extension of the files in the saved query library.
+const savedQueryExt = ".graphql"
+
+// savedQueriesDir is "hub-queries" within XDG_CONFIG_HOME, or within
+// "~/.config" if that isn't set. It can't live within "~/.config/hub", which
+// is the configuration file itself.
+func savedQueriesDir() (string, error) {
+	configHome := os.Getenv("XDG_CONFIG_HOME")
+	if configHome == "" {
+		home, err := homedir.Dir()
+		if err != nil {
+			return "", err
+		}
+		configHome = filepath.Join(home, ".config")
+	}
+	return filepath.Join(configHome, "hub-queries"), nil
+}
+
+// readSavedQuery reads the query named name from the library in dir.
+func readSavedQuery(dir, name string) (string, error) {
+	if name == "" || strings.ContainsAny(name, `/\`) {
+		return "", fmt.Errorf("invalid name of a saved query: %q", name)
+	}
+	content, err := ioutil.ReadFile(filepath.Join(dir, name+savedQueryExt))
+	if os.IsNotExist(err) {
+		return "", fmt.Errorf("no saved query named '%s' in %s", name, dir)
+	}
+	return string(content), err
+}
+
+type savedQuery struct {
+	name        string
+	description string
+}
+
+// listSavedQueries lists the queries in the library in dir by name. The
+// description of a query is the first line of the file if that's a comment.
+func listSavedQueries(dir string) ([]savedQuery, error) {
+	files, err := ioutil.ReadDir(dir)
+	if os.IsNotExist(err) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	queries := []savedQuery{}
+	for _, file := range files {
+		if file.IsDir() || !strings.HasSuffix(file.Name(), savedQueryExt) {
+			continue
+		}
+		content, err := ioutil.ReadFile(filepath.Join(dir, file.Name()))
+		if err != nil {
+			return nil, err
+		}
+		queries = append(queries, savedQuery{
+			name:        strings.TrimSuffix(file.Name(), savedQueryExt),
+			description: savedQueryDescription(string(content)),
+		})
+	}
+	sort.Slice(queries, func(a, b int) bool {
+		return queries[a].name < queries[b].name
+	})
+	return queries, nil
+}
+
+func savedQueryDescription(query string) string {
+	scanner := bufio.NewScanner(strings.NewReader(query))
+	if !scanner.Scan() {
+		return ""
+	}
+	line := strings.TrimSpace(scanner.Text())
+	if !strings.HasPrefix(line, "#") {
+		return ""
+	}
+	return strings.TrimSpace(strings.TrimLeft(line, "#"))
+}
+
+// apiFieldValue converts a '--field' to the key and the value to send. Like
+// with magicValue, the value may be read from a file, but a key ending with
+// ":" marks a value as JSON, as in "ids:=[1, 2]". In GraphQL queries, "@me"
+// stands for the login of the authenticated user, which login looks up.
+func apiFieldValue(key, value string, graphQL bool, login func() (string, error)) (string, interface{}, error) {
+	if strings.HasSuffix(key, ":") {
+		key = strings.TrimSuffix(key, ":")
+		data := value
+		if strings.HasPrefix(value, "@") {
+			data = string(readFile(value[1:]))
+		}
+		var decoded interface{}
+		decoder := json.NewDecoder(strings.NewReader(data))
+		decoder.UseNumber()
+		if err := decoder.Decode(&decoded); err != nil {
+			return key, nil, fmt.Errorf("the '%s' field must be JSON: %s", key, err)
+		}
+		return key, decoded, nil
+	}
+
+	if graphQL && value == "@me" {
+		user, err := login()
+		return key, user, err
+	}
+	return key, magicValue(value), nil
+}
@@ -0,0 +1,118 @@
+package commands
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/bmizerany/assert"
+)
+
+func TestGraphQLEndCursor(t *testing.T) {
+	assert.Equal(t, "Y3Vyc29yOjEwMA==", graphQLEndCursor([]byte(`{"data":{"viewer":{"repositories":{
+		"nodes":[{"name":"hub"}],
+		"pageInfo":{"hasNextPage":true,"endCursor":"Y3Vyc29yOjEwMA=="}
+	}}}}`)))
+
+	assert.Equal(t, "c2", graphQLEndCursor([]byte(`{"data":{"search":[
+		{"pageInfo":{"hasNextPage":false,"endCursor":"c1"}},
+		{"pageInfo":{"hasNextPage":true,"endCursor":"c2"}}
+	]}}`)))
+
+	assert.Equal(t, "", graphQLEndCursor([]byte(`{"data":{"pageInfo":{"hasNextPage":false,"endCursor":"c1"}}}`)))
+	assert.Equal(t, "", graphQLEndCursor([]byte(`{"errors":[{"message":"oops"}]}`)))
+	assert.Equal(t, "", graphQLEndCursor([]byte(`not json`)))
+}
+
+func TestParseGraphQLVariables(t *testing.T) {
+	variables, err := parseGraphQLVariables(`{
+		"first": 100,
+		"id": 9007199254740993,
+		"draft": false,
+		"after": null,
+		"filter": {"labels": ["bug", "docs"], "states": {"open": true}}
+	}`)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, json.Number("100"), variables["first"])
+	assert.Equal(t, json.Number("9007199254740993"), variables["id"])
+	assert.Equal(t, false, variables["draft"])
+	assert.Equal(t, nil, variables["after"])
+	assert.Equal(t, map[string]interface{}{
+		"labels": []interface{}{"bug", "docs"},
+		"states": map[string]interface{}{"open": true},
+	}, variables["filter"])
+
+	_, err = parseGraphQLVariables(`["first", 100]`)
+	assert.NotEqual(t, nil, err)
+	_, err = parseGraphQLVariables(true)
+	assert.Equal(t, "the 'variables' field must be a JSON object", err.Error())
+}
+
+func TestAPIFieldValue(t *testing.T) {
+	lookups := 0
+	login := func() (string, error) {
+		lookups++
+		return "mislav", nil
+	}
+
+	key, value, err := apiFieldValue("first", "20", true, login)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, "first", key)
+	assert.Equal(t, 20, value)
+
+	_, value, _ = apiFieldValue("draft", "false", true, login)
+	assert.Equal(t, false, value)
+
+	key, value, err = apiFieldValue("labels:", `["bug", {"name": "docs"}]`, true, login)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, "labels", key)
+	assert.Equal(t, []interface{}{"bug", map[string]interface{}{"name": "docs"}}, value)
+
+	_, value, _ = apiFieldValue("id:", "9007199254740993", false, login)
+	assert.Equal(t, json.Number("9007199254740993"), value)
+
+	_, value, _ = apiFieldValue("title:", `"20"`, false, login)
+	assert.Equal(t, "20", value)
+
+	_, _, err = apiFieldValue("labels:", "[bug]", true, login)
+	assert.NotEqual(t, nil, err)
+
+	assert.Equal(t, 0, lookups)
+	key, value, err = apiFieldValue("login", "@me", true, login)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, "login", key)
+	assert.Equal(t, "mislav", value)
+	assert.Equal(t, 1, lookups)
+}
+
+func TestSavedQueries(t *testing.T) {
+	dir, err := ioutil.TempDir("", "hub-queries")
+	assert.Equal(t, nil, err)
+	defer os.RemoveAll(dir)
+
+	ioutil.WriteFile(filepath.Join(dir, "review-queue.graphql"), []byte("# Pull requests awaiting my review\nquery { viewer { login } }\n"), 0644)
+	ioutil.WriteFile(filepath.Join(dir, "me.graphql"), []byte("query { viewer { login } }\n"), 0644)
+	ioutil.WriteFile(filepath.Join(dir, "notes.txt"), []byte("# not a query\n"), 0644)
+
+	queries, err := listSavedQueries(dir)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, []savedQuery{
+		{name: "me"},
+		{name: "review-queue", description: "Pull requests awaiting my review"},
+	}, queries)
+
+	query, err := readSavedQuery(dir, "me")
+	assert.Equal(t, nil, err)
+	assert.Equal(t, "query { viewer { login } }\n", query)
+
+	_, err = readSavedQuery(dir, "notes")
+	assert.Equal(t, "no saved query named 'notes' in "+dir, err.Error())
+	_, err = readSavedQuery(dir, "../notes")
+	assert.NotEqual(t, nil, err)
+
+	queries, err = listSavedQueries(filepath.Join(dir, "missing"))
+	assert.Equal(t, nil, err)
+	assert.Equal(t, 0, len(queries))
+}
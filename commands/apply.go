@@ -34,21 +34,47 @@ hub-am(1), hub(1), git-apply(1)
 var cmdAm = &Command{
 	Run:          apply,
 	GitExtension: true,
-	Usage:        "am [-3] <GITHUB-URL>",
+	Usage: `
+am [-3] <GITHUB-URL>
+am [-3] [-i] [--exclude <SHA>] [--onto <BRANCH>] <PULL-REQUEST-URL>
+`,
 	Long: `Replicate commits from a GitHub pull request locally.
 
 ## Options:
 	-3
 		(Recommended) See git-am(1).
 
+	-i, --interactive
+		List the commits of the pull request in a text editor, with their
+		abbreviated SHA, author, and subject, to pick the ones to apply.
+
+	--exclude <SHA>
+		Leave out the commit of the pull request whose SHA starts with <SHA>.
+		This option can be repeated. Values that don't look like a SHA are passed
+		on to git-am(1).
+
+	--onto <BRANCH>
+		Apply the commits onto <BRANCH> instead of the current branch. If it
+		doesn't exist, <BRANCH> is created from the base branch of the pull
+		request.
+
 	<GITHUB-URL>
 		A URL to a pull request or commit on GitHub.
 
+The original author and date of each commit are preserved. With '-i',
+'--exclude' or '--onto', when a commit of the pull request fails to apply, its
+position in the pull request and its URL are reported.
+
 ## Examples:
 		$ hub am -3 https://github.com/jingweno/gh/pull/55
 		> curl https://github.com/jingweno/gh/pull/55.patch -o /tmp/55.patch
 		> git am -3 /tmp/55.patch
 
+		$ hub am --exclude 1a2b3c4 --onto backport https://github.com/jingweno/gh/pull/55
+		> git fetch origin +refs/heads/master:refs/remotes/origin/master
+		> git checkout -b backport --no-track refs/remotes/origin/master
+		> git am /tmp/55.patch
+
 ## See also:
 
 hub-apply(1), hub-cherry-pick(1), hub(1), git-am(1)
@@ -60,28 +86,56 @@ func init() {
 	CmdRunner.Use(cmdAm)
 }
 
+var (
+	commitRegexp = regexp.MustCompile("^(commit|pull/[0-9]+/commits)/([0-9a-f]+)")
+	pullRegexp   = regexp.MustCompile("^pull/([0-9]+)")
+)
+
 func apply(command *Command, args *Args) {
 	if !args.IsParamsEmpty() {
-		transformApplyArgs(args)
+		var series *amSeries
+		if command.Name() == "am" && pullRequestParams(args) > 0 {
+			series = parseAmSeriesFlags(args)
+			if series != nil && pullRequestParams(args) > 1 {
+				utils.Check(command.UsageError("commits can only be picked from a single pull request"))
+			}
+		}
+		transformApplyArgs(args, series)
+	}
+}
+
+// pullRequestParams counts the arguments that are pull request URLs.
+func pullRequestParams(args *Args) int {
+	count := 0
+	for _, arg := range args.Params {
+		if projectURL, err := github.ParseURL(arg); err == nil {
+			path := projectURL.ProjectPath()
+			if pullRegexp.MatchString(path) && !commitRegexp.MatchString(path) {
+				count++
+			}
+		}
 	}
+	return count
 }
 
-func transformApplyArgs(args *Args) {
+func transformApplyArgs(args *Args, series *amSeries) {
 	gistRegexp := regexp.MustCompile("^https?://gist\\.github\\.com/([\\w.-]+/)?([a-f0-9]+)")
-	commitRegexp := regexp.MustCompile("^(commit|pull/[0-9]+/commits)/([0-9a-f]+)")
-	pullRegexp := regexp.MustCompile("^pull/([0-9]+)")
 	for idx, arg := range args.Params {
 		var (
 			patch    io.ReadCloser
 			apiError error
+			gh       *github.Client
+			pullURL  *github.URL
+			pullID   string
 		)
 		projectURL, err := github.ParseURL(arg)
 		if err == nil {
-			gh := github.NewClient(projectURL.Project.Host)
+			gh = github.NewClient(projectURL.Project.Host)
 			if match := commitRegexp.FindStringSubmatch(projectURL.ProjectPath()); match != nil {
 				patch, apiError = gh.CommitPatch(projectURL.Project, match[2])
 			} else if match := pullRegexp.FindStringSubmatch(projectURL.ProjectPath()); match != nil {
 				patch, apiError = gh.PullRequestPatch(projectURL.Project, match[1])
+				pullURL, pullID = projectURL, match[1]
 			}
 		} else {
 			match := gistRegexp.FindStringSubmatch(arg)
@@ -103,12 +157,32 @@ func transformApplyArgs(args *Args) {
 		patchFile, err := ioutil.TempFile(tempDir, "hub")
 		utils.Check(err)
 
-		_, err = io.Copy(patchFile, patch)
-		utils.Check(err)
+		if series != nil && pullURL != nil {
+			series.project, series.number = pullURL.Project, pullID
+			mbox, err := ioutil.ReadAll(patch)
+			utils.Check(err)
+			patchFile.Close()
+			patch.Close()
 
-		patchFile.Close()
-		patch.Close()
+			utils.Check(series.prepare(mbox, patchFile.Name()))
+			if series.onto != "" {
+				utils.Check(series.checkoutOnto(gh, args))
+			}
+		} else {
+			_, err = io.Copy(patchFile, patch)
+			utils.Check(err)
+
+			patchFile.Close()
+			patch.Close()
+		}
 
 		args.ReplaceParam(idx, patchFile.Name())
 	}
+
+	if series != nil && !args.Noop {
+		args.NoForward()
+		args.AfterFn(func() error {
+			return series.apply(args.Params)
+		})
+	}
 }
@@ -0,0 +1,319 @@
+package commands
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// isUnpackableArchive reports whether a release asset can be extracted with
+// 'release download --unpack'.
+func isUnpackableArchive(name string) bool {
+	name = strings.ToLower(name)
+	for _, ext := range []string{".tar.gz", ".tgz", ".tar", ".zip"} {
+		if strings.HasSuffix(name, ext) {
+			return true
+		}
+	}
+	return false
+}
+
+// unpackArchive extracts a tar, gzipped tar or zip file into dest. The first
+// strip components of each entry name are removed like with tar's
+// '--strip-components', and entries left without a name are skipped. Entries
+// that would end up outside of dest are rejected.
+func unpackArchive(filename, dest string, strip int) error {
+	if strings.HasSuffix(strings.ToLower(filename), ".zip") {
+		return unpackZip(filename, dest, strip)
+	}
+	return unpackTar(filename, dest, strip)
+}
+
+func unpackTar(filename, dest string, strip int) error {
+	file, err := os.Open(filename)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	var reader io.Reader = file
+	if lower := strings.ToLower(filename); strings.HasSuffix(lower, ".gz") || strings.HasSuffix(lower, ".tgz") {
+		gzipReader, err := gzip.NewReader(file)
+		if err != nil {
+			return fmt.Errorf("error unpacking %s: %s", filename, err)
+		}
+		defer gzipReader.Close()
+		reader = gzipReader
+	}
+
+	tarReader := tar.NewReader(reader)
+	for {
+		header, err := tarReader.Next()
+		if err == io.EOF {
+			return nil
+		} else if err != nil {
+			return fmt.Errorf("error unpacking %s: %s", filename, err)
+		}
+
+		target, err := archiveEntryPath(dest, header.Name, strip)
+		if err != nil {
+			return fmt.Errorf("error unpacking %s: %s", filename, err)
+		} else if target == "" {
+			continue
+		}
+
+		mode := header.FileInfo().Mode()
+		switch header.Typeflag {
+		case tar.TypeDir:
+			err = mkdirArchiveDir(dest, target, mode.Perm())
+		case tar.TypeReg, tar.TypeRegA:
+			err = writeArchiveFile(dest, target, mode.Perm(), tarReader)
+		case tar.TypeSymlink:
+			err = writeArchiveSymlink(dest, target, header.Linkname)
+		default:
+			// hard links, devices and the like aren't needed for release assets
+			continue
+		}
+		if err != nil {
+			return fmt.Errorf("error unpacking %s: %s", filename, err)
+		}
+	}
+}
+
+func unpackZip(filename, dest string, strip int) error {
+	zipReader, err := zip.OpenReader(filename)
+	if err != nil {
+		return fmt.Errorf("error unpacking %s: %s", filename, err)
+	}
+	defer zipReader.Close()
+
+	for _, entry := range zipReader.File {
+		target, err := archiveEntryPath(dest, entry.Name, strip)
+		if err != nil {
+			return fmt.Errorf("error unpacking %s: %s", filename, err)
+		} else if target == "" {
+			continue
+		}
+
+		mode := entry.Mode()
+		if mode.IsDir() {
+			err = mkdirArchiveDir(dest, target, mode.Perm())
+		} else if mode&os.ModeSymlink != 0 {
+			err = unpackZipSymlink(dest, target, entry)
+		} else {
+			err = unpackZipFile(dest, target, entry)
+		}
+		if err != nil {
+			return fmt.Errorf("error unpacking %s: %s", filename, err)
+		}
+	}
+
+	return nil
+}
+
+func unpackZipFile(dest, target string, entry *zip.File) error {
+	reader, err := entry.Open()
+	if err != nil {
+		return err
+	}
+	defer reader.Close()
+
+	mode := entry.Mode().Perm()
+	if mode == 0 {
+		// zip files created on Windows don't record Unix permissions
+		mode = 0644
+	}
+	return writeArchiveFile(dest, target, mode, reader)
+}
+
+func unpackZipSymlink(dest, target string, entry *zip.File) error {
+	reader, err := entry.Open()
+	if err != nil {
+		return err
+	}
+	defer reader.Close()
+
+	linkname := &bytes.Buffer{}
+	if _, err := io.Copy(linkname, reader); err != nil {
+		return err
+	}
+	return writeArchiveSymlink(dest, target, linkname.String())
+}
+
+// archiveEntryPath returns where an archive entry should be written within
+// dest, or an empty string if nothing remains of its name after stripping.
+func archiveEntryPath(dest, name string, strip int) (string, error) {
+	name = strings.Replace(name, "\\", "/", -1)
+	if path.IsAbs(name) || filepath.IsAbs(name) {
+		return "", fmt.Errorf("refusing to extract absolute path %q", name)
+	}
+
+	parts := []string{}
+	for _, part := range strings.Split(name, "/") {
+		if part != "" && part != "." {
+			parts = append(parts, part)
+		}
+	}
+	if len(parts) <= strip {
+		return "", nil
+	}
+	parts = parts[strip:]
+
+	relative := path.Clean(strings.Join(parts, "/"))
+	if relative == ".." || strings.HasPrefix(relative, "../") {
+		return "", fmt.Errorf("refusing to extract %q outside of the destination", name)
+	}
+
+	return filepath.Join(dest, filepath.FromSlash(relative)), nil
+}
+
+// maxArchiveSymlinks bounds how many symlinks resolveArchivePath follows, so
+// that a loop of links can't keep it busy forever.
+const maxArchiveSymlinks = 255
+
+var errOutsideDestination = errors.New("path leads outside of the destination")
+
+// resolveArchivePath follows name from dir, an already resolved directory
+// within dest, through the symlinks that have been extracted so far and
+// returns the path that it leads to. Unlike filepath.Join, a ".." after a
+// symlink goes up from where the link points. An error is returned if the
+// path leaves dest at any point, so that an archive can't write through a
+// chain of links that each look harmless on their own. Components that don't
+// exist yet are taken as is.
+func resolveArchivePath(dest, dir, name string) (string, error) {
+	relative, err := filepath.Rel(dest, dir)
+	if err != nil || relative == ".." || strings.HasPrefix(relative, ".."+string(filepath.Separator)) {
+		return "", errOutsideDestination
+	}
+
+	parts := []string{}
+	if relative != "." {
+		parts = strings.Split(filepath.ToSlash(relative), "/")
+	}
+	var pending []string
+	enter := func(name string) error {
+		name = filepath.ToSlash(name)
+		if path.IsAbs(name) {
+			root := strings.TrimSuffix(filepath.ToSlash(dest), "/")
+			if name != root && !strings.HasPrefix(name, root+"/") {
+				return errOutsideDestination
+			}
+			parts = []string{}
+			name = strings.TrimPrefix(name, root)
+		}
+		pending = append(strings.Split(name, "/"), pending...)
+		return nil
+	}
+	if err := enter(name); err != nil {
+		return "", err
+	}
+
+	followed := 0
+	for len(pending) > 0 {
+		part := pending[0]
+		pending = pending[1:]
+		switch part {
+		case "", ".":
+			continue
+		case "..":
+			if len(parts) == 0 {
+				return "", errOutsideDestination
+			}
+			parts = parts[:len(parts)-1]
+			continue
+		}
+
+		current := filepath.Join(dest, filepath.Join(parts...), part)
+		info, err := os.Lstat(current)
+		if err != nil || info.Mode()&os.ModeSymlink == 0 {
+			parts = append(parts, part)
+			continue
+		}
+
+		followed++
+		if followed > maxArchiveSymlinks {
+			return "", fmt.Errorf("too many levels of symbolic links")
+		}
+		linkname, err := os.Readlink(current)
+		if err != nil {
+			return "", err
+		}
+		if err := enter(linkname); err != nil {
+			return "", err
+		}
+	}
+
+	return filepath.Join(dest, filepath.Join(parts...)), nil
+}
+
+// resolveArchiveParent resolves the directory that target is to be created in
+// and creates it. The last component is left alone, so that an existing
+// symlink there gets replaced rather than followed.
+func resolveArchiveParent(dest, target string) (string, error) {
+	parent, err := resolveArchivePath(dest, dest, archiveRelativePath(dest, filepath.Dir(target)))
+	if err != nil {
+		return "", fmt.Errorf("refusing to extract %s: %s", target, err)
+	}
+	if err := os.MkdirAll(parent, 0755); err != nil {
+		return "", err
+	}
+	return filepath.Join(parent, filepath.Base(target)), nil
+}
+
+func archiveRelativePath(dest, target string) string {
+	relative, _ := filepath.Rel(dest, target)
+	return relative
+}
+
+func mkdirArchiveDir(dest, target string, mode os.FileMode) error {
+	resolved, err := resolveArchivePath(dest, dest, archiveRelativePath(dest, target))
+	if err != nil {
+		return fmt.Errorf("refusing to extract %s: %s", target, err)
+	}
+	return os.MkdirAll(resolved, mode)
+}
+
+func writeArchiveFile(dest, target string, mode os.FileMode, reader io.Reader) error {
+	target, err := resolveArchiveParent(dest, target)
+	if err != nil {
+		return err
+	}
+	// remove whatever is in the way, in particular a symlink that could
+	// otherwise redirect the write outside of the destination
+	os.Remove(target)
+
+	file, err := os.OpenFile(target, os.O_WRONLY|os.O_CREATE|os.O_EXCL, mode)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	if _, err = io.Copy(file, reader); err != nil {
+		return err
+	}
+	// the mode passed to OpenFile is subject to umask
+	return file.Chmod(mode)
+}
+
+func writeArchiveSymlink(dest, target, linkname string) error {
+	resolved, err := resolveArchiveParent(dest, target)
+	if err != nil {
+		return err
+	}
+	// the link may pass through links extracted before it, which a check of
+	// its name alone wouldn't catch
+	if _, err := resolveArchivePath(dest, filepath.Dir(resolved), linkname); err != nil {
+		return fmt.Errorf("refusing to create symlink %s pointing outside of the destination", target)
+	}
+
+	os.Remove(resolved)
+	return os.Symlink(linkname, resolved)
+}
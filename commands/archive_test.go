@@ -0,0 +1,184 @@
+package commands
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"compress/gzip"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/bmizerany/assert"
+)
+
+type testArchiveEntry struct {
+	name     string
+	mode     int64
+	body     string
+	linkname string
+}
+
+func writeTestTarGz(t *testing.T, filename string, entries []testArchiveEntry) {
+	file, err := os.Create(filename)
+	assert.Equal(t, nil, err)
+	defer file.Close()
+
+	gzipWriter := gzip.NewWriter(file)
+	defer gzipWriter.Close()
+	tarWriter := tar.NewWriter(gzipWriter)
+	defer tarWriter.Close()
+
+	for _, entry := range entries {
+		header := &tar.Header{Name: entry.name, Mode: entry.mode, Size: int64(len(entry.body))}
+		if entry.linkname != "" {
+			header.Typeflag = tar.TypeSymlink
+			header.Linkname = entry.linkname
+			header.Size = 0
+		} else if entry.name[len(entry.name)-1] == '/' {
+			header.Typeflag = tar.TypeDir
+		}
+		assert.Equal(t, nil, tarWriter.WriteHeader(header))
+		tarWriter.Write([]byte(entry.body))
+	}
+}
+
+func setupUnpackDir(t *testing.T) (string, func()) {
+	dir, err := ioutil.TempDir("", "hub-unpack")
+	assert.Equal(t, nil, err)
+	return dir, func() { os.RemoveAll(dir) }
+}
+
+func TestUnpackArchive_TarGz(t *testing.T) {
+	dir, cleanup := setupUnpackDir(t)
+	defer cleanup()
+
+	archive := filepath.Join(dir, "hub-1.0.tar.gz")
+	writeTestTarGz(t, archive, []testArchiveEntry{
+		{name: "hub-1.0/", mode: 0755},
+		{name: "hub-1.0/bin/hub", mode: 0755, body: "#!/bin/sh\n"},
+		{name: "hub-1.0/README.md", mode: 0600, body: "readme"},
+		{name: "hub-1.0/docs", linkname: "README.md"},
+	})
+
+	dest := filepath.Join(dir, "out")
+	assert.Equal(t, nil, unpackArchive(archive, dest, 1))
+
+	info, err := os.Stat(filepath.Join(dest, "bin", "hub"))
+	assert.Equal(t, nil, err)
+	assert.Equal(t, os.FileMode(0755), info.Mode().Perm())
+
+	info, err = os.Stat(filepath.Join(dest, "README.md"))
+	assert.Equal(t, nil, err)
+	assert.Equal(t, os.FileMode(0600), info.Mode().Perm())
+
+	content, err := ioutil.ReadFile(filepath.Join(dest, "docs"))
+	assert.Equal(t, nil, err)
+	assert.Equal(t, "readme", string(content))
+
+	_, err = os.Stat(filepath.Join(dest, "hub-1.0"))
+	assert.T(t, os.IsNotExist(err))
+}
+
+func TestUnpackArchive_RejectsTraversal(t *testing.T) {
+	dir, cleanup := setupUnpackDir(t)
+	defer cleanup()
+
+	archive := filepath.Join(dir, "evil.tar.gz")
+	writeTestTarGz(t, archive, []testArchiveEntry{
+		{name: "pkg/../../escaped", mode: 0644, body: "oops"},
+	})
+	err := unpackArchive(archive, filepath.Join(dir, "out"), 0)
+	assert.Equal(t, `error unpacking `+archive+`: refusing to extract "pkg/../../escaped" outside of the destination`, err.Error())
+	_, err = os.Stat(filepath.Join(dir, "escaped"))
+	assert.T(t, os.IsNotExist(err))
+
+	writeTestTarGz(t, archive, []testArchiveEntry{
+		{name: "link", linkname: "../../etc"},
+	})
+	err = unpackArchive(archive, filepath.Join(dir, "out"), 0)
+	assert.NotEqual(t, nil, err)
+
+	writeTestTarGz(t, archive, []testArchiveEntry{
+		{name: "/etc/passwd", mode: 0644, body: "oops"},
+	})
+	err = unpackArchive(archive, filepath.Join(dir, "out"), 0)
+	assert.Equal(t, `error unpacking `+archive+`: refusing to extract absolute path "/etc/passwd"`, err.Error())
+}
+
+func TestUnpackArchive_RejectsChainedSymlinks(t *testing.T) {
+	dir, cleanup := setupUnpackDir(t)
+	defer cleanup()
+
+	archive := filepath.Join(dir, "evil.tar.gz")
+	dest := filepath.Join(dir, "out", "dest")
+	writeTestTarGz(t, archive, []testArchiveEntry{
+		{name: "b/", mode: 0755},
+		{name: "b/l", linkname: ".."},
+		{name: "c", linkname: "b/l/.."},
+		{name: "c/evil", mode: 0644, body: "oops"},
+	})
+	err := unpackArchive(archive, dest, 0)
+	assert.Equal(t, `error unpacking `+archive+`: refusing to create symlink `+filepath.Join(dest, "c")+` pointing outside of the destination`, err.Error())
+	_, err = os.Stat(filepath.Join(dir, "out", "evil"))
+	assert.T(t, os.IsNotExist(err))
+
+	// a link that only escapes once another link is extracted after it
+	os.RemoveAll(dest)
+	writeTestTarGz(t, archive, []testArchiveEntry{
+		{name: "b/", mode: 0755},
+		{name: "c", linkname: "b/x/.."},
+		{name: "b/x", linkname: ".."},
+		{name: "c/evil", mode: 0644, body: "oops"},
+	})
+	err = unpackArchive(archive, dest, 0)
+	assert.NotEqual(t, nil, err)
+	_, err = os.Stat(filepath.Join(dir, "out", "evil"))
+	assert.T(t, os.IsNotExist(err))
+
+	os.RemoveAll(dest)
+	writeTestTarGz(t, archive, []testArchiveEntry{
+		{name: "c", linkname: "c"},
+		{name: "c/evil", mode: 0644, body: "oops"},
+	})
+	err = unpackArchive(archive, dest, 0)
+	assert.NotEqual(t, nil, err)
+}
+
+func TestUnpackArchive_Zip(t *testing.T) {
+	dir, cleanup := setupUnpackDir(t)
+	defer cleanup()
+
+	archive := filepath.Join(dir, "hub.zip")
+	file, err := os.Create(archive)
+	assert.Equal(t, nil, err)
+	zipWriter := zip.NewWriter(file)
+	header := &zip.FileHeader{Name: "hub/bin/hub"}
+	header.SetMode(0755)
+	w, err := zipWriter.CreateHeader(header)
+	assert.Equal(t, nil, err)
+	w.Write([]byte("binary"))
+	assert.Equal(t, nil, zipWriter.Close())
+	file.Close()
+
+	dest := filepath.Join(dir, "out")
+	assert.Equal(t, nil, unpackArchive(archive, dest, 0))
+
+	info, err := os.Stat(filepath.Join(dest, "hub", "bin", "hub"))
+	assert.Equal(t, nil, err)
+	assert.Equal(t, os.FileMode(0755), info.Mode().Perm())
+}
+
+func TestArchiveEntryPath(t *testing.T) {
+	path, err := archiveEntryPath("dest", "./a/b/c.txt", 2)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, filepath.Join("dest", "c.txt"), path)
+
+	path, err = archiveEntryPath("dest", "a/b/", 2)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, "", path)
+
+	path, err = archiveEntryPath("dest", "a/b/../c", 1)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, filepath.Join("dest", "c"), path)
+}
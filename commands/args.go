@@ -17,6 +17,9 @@ type Args struct {
 	beforeChain []*cmd.Cmd
 	afterChain  []*cmd.Cmd
 	Noop        bool
+	FixRemote   bool
+	Porcelain   bool
+	Identity    string
 	Terminator  bool
 	noForward   bool
 	Callbacks   []func() error
@@ -184,22 +187,37 @@ func (a *Args) AppendParams(params ...string) {
 
 func NewArgs(args []string) *Args {
 	var (
-		command string
-		params  []string
-		noop    bool
+		command   string
+		params    []string
+		noop      bool
+		fixRemote bool
+		porcelain bool
+		identity  string
 	)
 
 	cmdIdx := findCommandIndex(args)
-	globalFlags := args[:cmdIdx]
-	if cmdIdx > 0 {
-		args = args[cmdIdx:]
-		for i := len(globalFlags) - 1; i >= 0; i-- {
-			if globalFlags[i] == noopFlag {
-				noop = true
-				globalFlags = append(globalFlags[:i], globalFlags[i+1:]...)
-			}
+	globalFlags := []string{}
+	for i := 0; i < cmdIdx; i++ {
+		switch flag := args[i]; {
+		case flag == noopFlag:
+			noop = true
+		case flag == fixRemoteFlag:
+			fixRemote = true
+		case flag == porcelainFlag:
+			porcelain = true
+		case flag == identityFlag && i+1 < cmdIdx:
+			i++
+			identity = args[i]
+		case strings.HasPrefix(flag, identityFlag+"="):
+			identity = strings.TrimPrefix(flag, identityFlag+"=")
+		case (flag == configFlag || flag == chdirFlag) && i+1 < cmdIdx:
+			globalFlags = append(globalFlags, args[i:i+2]...)
+			i++
+		default:
+			globalFlags = append(globalFlags, flag)
 		}
 	}
+	args = args[cmdIdx:]
 
 	if len(args) != 0 {
 		command = args[0]
@@ -212,19 +230,25 @@ func NewArgs(args []string) *Args {
 		Command:     command,
 		Params:      params,
 		Noop:        noop,
+		FixRemote:   fixRemote,
+		Porcelain:   porcelain,
+		Identity:    identity,
 		beforeChain: make([]*cmd.Cmd, 0),
 		afterChain:  make([]*cmd.Cmd, 0),
 	}
 }
 
 const (
-	noopFlag    = "--noop"
-	versionFlag = "--version"
-	listCmds    = "--list-cmds="
-	helpFlag    = "--help"
-	configFlag  = "-c"
-	chdirFlag   = "-C"
-	flagPrefix  = "-"
+	noopFlag      = "--noop"
+	fixRemoteFlag = "--fix-remote"
+	porcelainFlag = "--porcelain"
+	identityFlag  = "--as"
+	versionFlag   = "--version"
+	listCmds      = "--list-cmds="
+	helpFlag      = "--help"
+	configFlag    = "-c"
+	chdirFlag     = "-C"
+	flagPrefix    = "-"
 )
 
 func looksLikeFlag(value string) bool {
@@ -243,7 +267,7 @@ func findCommandIndex(args []string) int {
 			break
 		} else {
 			commandIndex = i + 1
-			if arg == configFlag || arg == chdirFlag {
+			if arg == configFlag || arg == chdirFlag || arg == identityFlag {
 				slurpNextValue = true
 			}
 		}
@@ -106,6 +106,44 @@ func TestArgs_GlobalFlags_Repeated(t *testing.T) {
 	assert.Equal(t, false, args.Noop)
 }
 
+func TestArgs_GlobalFlags_Identity(t *testing.T) {
+	args := NewArgs([]string{"--as", "bot", "-c", "a=b", "pr", "list"})
+	assert.Equal(t, "pr", args.Command)
+	assert.Equal(t, "bot", args.Identity)
+	assert.Equal(t, []string{"-c", "a=b"}, args.GlobalFlags)
+	assert.Equal(t, []string{"list"}, args.Params)
+
+	args = NewArgs([]string{"--as=personal", "--noop", "issue"})
+	assert.Equal(t, "issue", args.Command)
+	assert.Equal(t, "personal", args.Identity)
+	assert.Equal(t, []string{}, args.GlobalFlags)
+	assert.Equal(t, true, args.Noop)
+}
+
+func TestArgs_GlobalFlags_FixRemote(t *testing.T) {
+	args := NewArgs([]string{"--fix-remote", "-c", "a=b", "pr", "list"})
+	assert.Equal(t, "pr", args.Command)
+	assert.Equal(t, true, args.FixRemote)
+	assert.Equal(t, []string{"-c", "a=b"}, args.GlobalFlags)
+	assert.Equal(t, []string{"list"}, args.Params)
+
+	args = NewArgs([]string{"pr", "--fix-remote"})
+	assert.Equal(t, false, args.FixRemote)
+	assert.Equal(t, []string{"--fix-remote"}, args.Params)
+}
+
+func TestArgs_GlobalFlags_Porcelain(t *testing.T) {
+	args := NewArgs([]string{"--porcelain", "--noop", "release", "download", "v1"})
+	assert.Equal(t, "release", args.Command)
+	assert.Equal(t, true, args.Porcelain)
+	assert.Equal(t, true, args.Noop)
+	assert.Equal(t, []string{"download", "v1"}, args.Params)
+
+	args = NewArgs([]string{"status", "--porcelain"})
+	assert.Equal(t, false, args.Porcelain)
+	assert.Equal(t, []string{"--porcelain"}, args.Params)
+}
+
 func TestArgs_GlobalFlags_Propagate(t *testing.T) {
 	args := NewArgs([]string{"-c", "key=value", "status"})
 	cmd := args.ToCmd()
@@ -0,0 +1,156 @@
+package commands
+
+import (
+	"fmt"
+
+	"github.com/github/hub/github"
+	"github.com/github/hub/ui"
+	"github.com/github/hub/utils"
+)
+
+var (
+	cmdAuth = &Command{
+		Run: listIdentities,
+		Usage: `
+auth
+auth switch <NAME>
+auth status
+`,
+		Long: `Manage GitHub identities stored for a host.
+
+## Commands:
+
+With no arguments, list the identities stored for the current host. The default
+identity is marked with "*".
+
+	* _switch_:
+		Make the identity known by <NAME> the default one for the current host.
+
+	* _status_:
+		Verify the access token of the identity in use for the current host and
+		show when it expires. Fine-grained personal access tokens expire, and hub
+		warns about them once a day during the final week before expiration.
+
+## Options:
+	<NAME>
+		The name of an identity. Identities in the configuration file that don't
+		have an explicit "name" are known by their user login.
+
+## Configuration:
+
+The hub configuration file may list several identities for a single host:
+
+	github.com:
+	- user: mislav
+	  oauth_token: TOKEN
+	- name: bot
+	  user: mislav-bot
+	  oauth_token: OTHER_TOKEN
+
+The first identity listed is the default one. Use the global '--as <NAME>' flag
+to select a different identity for a single invocation. If no identity with
+<NAME> exists yet, hub will authenticate and store a new one under that name.
+
+## Examples:
+		$ hub --as bot pr list
+
+		$ hub auth switch bot
+
+## See also:
+
+hub(1)
+`,
+	}
+
+	cmdSwitchIdentity = &Command{
+		Key: "switch",
+		Run: switchIdentity,
+	}
+
+	cmdAuthStatus = &Command{
+		Key: "status",
+		Run: authStatus,
+	}
+)
+
+func init() {
+	cmdAuth.Use(cmdSwitchIdentity)
+	cmdAuth.Use(cmdAuthStatus)
+	CmdRunner.Use(cmdAuth)
+}
+
+func authHost() string {
+	if localRepo, err := github.LocalRepo(); err == nil {
+		if project, err := localRepo.MainProject(); err == nil {
+			return project.Host
+		}
+	}
+	return github.DefaultGitHubHost()
+}
+
+func listIdentities(cmd *Command, args *Args) {
+	host := authHost()
+
+	args.NoForward()
+	identities := github.CurrentConfig().Identities(host)
+	if len(identities) == 0 {
+		utils.Check(fmt.Errorf("No identities stored for %s", host))
+	}
+
+	for i, identity := range identities {
+		marker := " "
+		if i == 0 {
+			marker = "*"
+		}
+		name := identity.IdentityName()
+		if name != identity.User {
+			ui.Printf("%s %s (%s)\n", marker, name, identity.User)
+		} else {
+			ui.Printf("%s %s\n", marker, name)
+		}
+	}
+}
+
+func switchIdentity(cmd *Command, args *Args) {
+	if args.IsParamsEmpty() {
+		utils.Check(cmd.UsageError(""))
+	}
+	name := args.FirstParam()
+	host := authHost()
+
+	args.NoForward()
+	if args.Noop {
+		ui.Printf("Would switch the default identity for %s to %s\n", host, name)
+		return
+	}
+
+	err := github.CurrentConfig().SwitchIdentity(host, name)
+	utils.Check(err)
+
+	ui.Printf("Switched the default identity for %s to %s\n", host, name)
+}
+
+func authStatus(cmd *Command, args *Args) {
+	host := authHost()
+
+	args.NoForward()
+	if len(github.CurrentConfig().Identities(host)) == 0 {
+		utils.Check(fmt.Errorf("No identities stored for %s", host))
+	}
+	if args.Noop {
+		ui.Printf("Would verify the access token for %s\n", host)
+		return
+	}
+
+	gh := github.NewClient(host)
+	expiresAt, err := gh.TokenExpiration()
+	utils.Check(err)
+
+	ui.Printf("Host: %s\n", host)
+	ui.Printf("User: %s\n", gh.Host.User)
+	if expiresAt.IsZero() {
+		ui.Println("Token expiration: never")
+	} else {
+		ui.Printf("Token expiration: %s\n", expiresAt.Format("2006-01-02 15:04:05 MST"))
+	}
+}
@@ -1,17 +1,30 @@
 package commands
 
 import (
+	"bufio"
 	"fmt"
 	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
 	"strings"
 
+	"github.com/github/hub/git"
 	"github.com/github/hub/github"
+	"github.com/github/hub/ui"
 	"github.com/github/hub/utils"
 )
 
 var cmdBrowse = &Command{
-	Run:   browse,
-	Usage: "browse [-uc] [[<USER>/]<REPOSITORY>|--] [<SUBPAGE>]",
+	Run: browse,
+	Usage: `
+browse [-uc] [[<USER>/]<REPOSITORY>|--] [<SUBPAGE>]
+browse [-uc] <FILE>[:<LINE>[-<LINE>]]|<SHA>|#<NUMBER>
+browse [-uc] --settings[=<PAGE>] [[<USER>/]<REPOSITORY>]
+browse [-uc] --org <ORG> [--settings[=<PAGE>]]
+`,
 	Long: `Open a GitHub repository in a web browser.
 
 ## Options:
@@ -24,6 +37,45 @@ var cmdBrowse = &Command{
 	[<USER>/]<REPOSITORY>
 		Defaults to repository in the current working directory.
 
+		Outside of a git repository, the repository is looked up on GitHub: an
+		exact <USER>/<REPOSITORY> match is preferred, then a <REPOSITORY> owned by
+		you or by one of your organizations, and finally repositories found by
+		searching for <REPOSITORY>. When that leaves several candidates, you're
+		asked to pick one if hub runs in a terminal; otherwise the candidates
+		are listed and hub exits with status 1.
+
+	<FILE>[:<LINE>[-<LINE>]]
+		Within a git repository, open a file or a directory of the working tree,
+		optionally highlighting a line or a range of lines. Paths are relative
+		to the current directory. The file is shown as of the upstream branch
+		of the current branch, or as of the default branch if the current branch
+		has no upstream.
+
+		A name without a "/" or a line number is taken to be a <REPOSITORY>
+		even if a file by that name exists; spell it as "./<FILE>" to open the
+		file instead.
+
+	<SHA>
+		Within a git repository, open the commit that an abbreviated or full
+		SHA of 7 to 40 characters refers to.
+
+	#<NUMBER>
+		Within a git repository, open the pull request <NUMBER>. A pull request
+		URL, "pull/<NUMBER>", or "PR<NUMBER>" works as well.
+
+	--settings[=<PAGE>]
+		Open the settings of the repository, or one of its settings pages:
+		"collaborators", "branches", "secrets", "webhooks", or "pages". With
+		'--org', <PAGE> is one of "member-privileges", "secrets", "webhooks", or
+		"billing" instead.
+
+		The URL is opened even without admin rights to the repository, which
+		GitHub will then refuse. If a response cached by 'hub api --cache' within
+		the last day shows that you lack those rights, a note says so.
+
+	--org <ORG>
+		Open the page of the organization <ORG> rather than of a repository.
+
 	<SUBPAGE>
 		One of "wiki", "commits", "issues", or other (default: "tree").
 
@@ -40,6 +92,21 @@ var cmdBrowse = &Command{
 		$ hub browse gh wiki
 		> open https://github.com/USER/gh/wiki
 
+		$ hub browse lib/parser.go:42-50
+		> open https://github.com/REPO/blob/BRANCH/lib/parser.go#L42-L50
+
+		$ hub browse 4173c3b
+		> open https://github.com/REPO/commit/4173c3b5b6d5e3c8c0d7a1e2f3a4b5c6d7e8f9a0
+
+		$ hub browse '#123'
+		> open https://github.com/REPO/pull/123
+
+		$ hub browse --settings=webhooks
+		> open https://github.com/REPO/settings/hooks
+
+		$ hub browse --org acme --settings=member-privileges
+		> open https://github.com/organizations/acme/settings/member_privileges
+
 ## See also:
 
 hub-compare(1), hub(1)
@@ -50,7 +117,35 @@ func init() {
 	CmdRunner.Use(cmdBrowse)
 }
 
+// repositorySettingsPages maps the names that '--settings' accepts to the
+// paths of settings pages of a repository.
+var repositorySettingsPages = map[string]string{
+	"collaborators": "settings/access",
+	"branches":      "settings/branches",
+	"secrets":       "settings/secrets/actions",
+	"webhooks":      "settings/hooks",
+	"pages":         "settings/pages",
+}
+
+// orgSettingsPages maps the names that '--settings' accepts together with
+// '--org' to the paths of settings pages of an organization.
+var orgSettingsPages = map[string]string{
+	"member-privileges": "settings/member_privileges",
+	"secrets":           "settings/secrets/actions",
+	"webhooks":          "settings/hooks",
+	"billing":           "settings/billing",
+}
+
+// browsePermissionsCacheTTL is how old a cached response may be for its
+// repository permissions to be trusted, in seconds.
+const browsePermissionsCacheTTL = 24 * 60 * 60
+
 func browse(command *Command, args *Args) {
+	if args.Flag.HasReceived("--org") {
+		browseOrg(command, args)
+		return
+	}
+
 	var (
 		dest    string
 		subpage string
@@ -73,8 +168,31 @@ func browse(command *Command, args *Args) {
 		dest = ""
 	}
 
-	localRepo, _ := github.LocalRepo()
-	if dest != "" {
+	settingsPath := ""
+	if args.Flag.HasReceived("--settings") {
+		if subpage != "" {
+			utils.Check(command.UsageError("'--settings' can't be combined with <SUBPAGE>"))
+		}
+		settingsPath = settingsPagePath(args.Flag.Value("--settings"), repositorySettingsPages)
+		subpage = "settings"
+	}
+
+	localRepo, localRepoErr := github.LocalRepo()
+	if dest != "" && subpage == "" && localRepoErr == nil {
+		if project, path := browseLocalTarget(localRepo, dest); project != nil {
+			args.NoForward()
+			flagBrowseURLPrint := args.Flag.Bool("--url")
+			flagBrowseURLCopy := args.Flag.Bool("--copy")
+			printBrowseOrCopy(args, project.WebURL("", "", path), !flagBrowseURLPrint && !flagBrowseURLCopy, flagBrowseURLCopy)
+			return
+		}
+	}
+
+	if dest != "" && localRepoErr != nil && !isLocalPath(dest) {
+		project, err = resolveBrowseProject(dest)
+		utils.Check(err)
+		branch = localRepo.MasterBranch()
+	} else if dest != "" {
 		project = github.NewProject("", dest, "")
 		branch = localRepo.MasterBranch()
 	} else if subpage != "" && subpage != "commits" && subpage != "tree" && subpage != "blob" && subpage != "settings" {
@@ -108,7 +226,10 @@ func browse(command *Command, args *Args) {
 		utils.Check(command.UsageError(""))
 	}
 
-	if subpage == "commits" {
+	if settingsPath != "" {
+		path = settingsPath
+		warnWithoutAdminRights(project)
+	} else if subpage == "commits" {
 		path = fmt.Sprintf("commits/%s", branchInURL(branch))
 	} else if subpage == "tree" || subpage == "" {
 		if !branch.IsMaster() {
@@ -126,6 +247,63 @@ func browse(command *Command, args *Args) {
 	printBrowseOrCopy(args, pageUrl, !flagBrowseURLPrint && !flagBrowseURLCopy, flagBrowseURLCopy)
 }
 
+// browseOrg handles '--org', which opens the page or the settings of an
+// organization.
+func browseOrg(command *Command, args *Args) {
+	if !args.IsParamsEmpty() {
+		utils.Check(command.UsageError("'--org' can't be combined with a repository"))
+	}
+	org := args.Flag.Value("--org")
+
+	path := ""
+	if args.Flag.HasReceived("--settings") {
+		path = utils.ConcatPaths("organizations", org, settingsPagePath(args.Flag.Value("--settings"), orgSettingsPages))
+	} else {
+		path = org
+	}
+
+	project := github.NewProject(org, "", "")
+	pageUrl := fmt.Sprintf("%s://%s", project.Protocol, utils.ConcatPaths(project.Host, path))
+
+	args.NoForward()
+	flagBrowseURLPrint := args.Flag.Bool("--url")
+	flagBrowseURLCopy := args.Flag.Bool("--copy")
+	printBrowseOrCopy(args, pageUrl, !flagBrowseURLPrint && !flagBrowseURLCopy, flagBrowseURLCopy)
+}
+
+// settingsPagePath returns the path of the settings page called name, or of
+// the main settings page if name is empty. Unknown names are an error that
+// lists the known ones.
+func settingsPagePath(name string, pages map[string]string) string {
+	if name == "" {
+		return "settings"
+	}
+	if path, ok := pages[name]; ok {
+		return path
+	}
+	names := make([]string, 0, len(pages))
+	for page := range pages {
+		names = append(names, page)
+	}
+	sort.Strings(names)
+	utils.Check(fmt.Errorf("Error: unknown settings page '%s' (supported: %s)", name, strings.Join(names, ", ")))
+	return ""
+}
+
+// warnWithoutAdminRights notes that the settings of project are likely off
+// limits if a cached API response says that the user isn't an admin of it.
+// Nothing is requested from the API just for that.
+func warnWithoutAdminRights(project *github.Project) {
+	host := github.CurrentConfig().Find(project.Host)
+	if host == nil {
+		return
+	}
+	repo := github.NewClientWithHost(host).CachedRepository(project, browsePermissionsCacheTTL)
+	if repo != nil && repo.Permissions != nil && !repo.Permissions.Admin {
+		ui.Errorf("Note: you don't have admin rights to %s, so GitHub may deny access to its settings\n", project)
+	}
+}
+
 func branchInURL(branch *github.Branch) string {
 	parts := strings.Split(branch.ShortName(), "/")
 	newPath := make([]string, len(parts))
@@ -134,3 +312,223 @@ func branchInURL(branch *github.Branch) string {
 	}
 	return strings.Join(newPath, "/")
 }
+
+var (
+	// fileLinesRe matches a path followed by a line, as in "main.go:42", or
+	// by a range of lines, as in "main.go:42-50"
+	fileLinesRe = regexp.MustCompile(`^(.+):(\d+)(?:-(\d+))?$`)
+	shaRe       = regexp.MustCompile(`^[0-9a-fA-F]{7,40}$`)
+	// pullRequestNumberRe matches "#123", "pull/123", and "PR123" in any case
+	pullRequestNumberRe = regexp.MustCompile(`^(?:#|pull/|[pP][rR])(\d+)$`)
+)
+
+// browseLocalTarget recognizes dest as a file or a directory of the working
+// tree, a commit, or a pull request, in that order, and returns the project
+// and the path of the page to open. The project is nil when dest is none of
+// those, so that it's taken to be the name of a repository.
+func browseLocalTarget(localRepo *github.GitHubRepo, dest string) (*github.Project, string) {
+	if file, lines := splitFileLines(dest); file != "" {
+		repoPath, err := git.RepoPath(file)
+		if err == nil {
+			branch, project := browseBranchAndProject(localRepo)
+			return project, filePagePath(branch, repoPath, isDir(file), lines)
+		}
+	}
+
+	if shaRe.MatchString(dest) {
+		if sha, err := git.Ref(dest + "^{commit}"); err == nil {
+			project, err := localRepo.MainProject()
+			utils.Check(err)
+			return project, "commit/" + sha
+		}
+	}
+
+	if match := pullRequestNumberRe.FindStringSubmatch(dest); match != nil {
+		project, err := localRepo.MainProject()
+		utils.Check(err)
+		return project, "pull/" + match[1]
+	}
+	if project, number := parsePullRequestURL(dest); project != nil {
+		return project, "pull/" + number
+	}
+
+	return nil, ""
+}
+
+// splitFileLines separates the lines that dest may end with from the path of
+// a file that exists. The file is empty if there's no such file. Without lines,
+// dest only counts as a file if it contains a "/" or is spelled like a path,
+// which leaves bare names to mean repositories.
+func splitFileLines(dest string) (file string, lines []string) {
+	if strings.Contains(dest, "/") || isLocalPath(dest) {
+		if _, err := os.Stat(dest); err == nil {
+			return dest, nil
+		}
+	}
+	match := fileLinesRe.FindStringSubmatch(dest)
+	if match == nil {
+		return "", nil
+	}
+	if _, err := os.Stat(match[1]); err != nil {
+		return "", nil
+	}
+	lines = []string{match[2]}
+	if match[3] != "" {
+		lines = append(lines, match[3])
+	}
+	return match[1], lines
+}
+
+// filePagePath is the path of the page that shows repoPath as of branch,
+// with lines highlighted as in "#L42-L50".
+func filePagePath(branch *github.Branch, repoPath string, dir bool, lines []string) string {
+	kind := "blob"
+	if dir {
+		kind = "tree"
+	}
+	path := fmt.Sprintf("%s/%s", kind, branchInURL(branch))
+	if repoPath != "." {
+		parts := strings.Split(repoPath, "/")
+		for i, s := range parts {
+			parts[i] = url.PathEscape(s)
+		}
+		path = utils.ConcatPaths(path, strings.Join(parts, "/"))
+	}
+	if !dir && len(lines) > 0 {
+		path += "#L" + strings.Join(lines, "-L")
+	}
+	return path
+}
+
+// browseBranchAndProject picks the branch to show files as of: the upstream
+// of the current branch if it has one, or else the default branch.
+func browseBranchAndProject(localRepo *github.GitHubRepo) (*github.Branch, *github.Project) {
+	if currentBranch, err := localRepo.CurrentBranch(); err == nil {
+		if upstream, err := currentBranch.Upstream(); err == nil && upstream.IsRemote() {
+			if remote, err := localRepo.RemoteByName(upstream.RemoteName()); err == nil {
+				if project, err := remote.Project(); err == nil {
+					return upstream, project
+				}
+			}
+		}
+	}
+
+	project, err := localRepo.MainProject()
+	utils.Check(err)
+	return localRepo.MasterBranch(), project
+}
+
+func isDir(file string) bool {
+	info, err := os.Stat(file)
+	return err == nil && info.IsDir()
+}
+
+// isLocalPath reports whether dest is spelled like a filesystem path rather
+// than a repository name. Bare names aren't checked against the filesystem,
+// since a directory named like the repository is common, and repositories
+// such as ".github" start with a dot.
+func isLocalPath(dest string) bool {
+	for _, prefix := range []string{"./", "../", "~"} {
+		if strings.HasPrefix(dest, prefix) {
+			return true
+		}
+	}
+	return dest == "." || dest == ".." || filepath.IsAbs(dest)
+}
+
+// resolveBrowseProject looks up the repository that dest, in "OWNER/NAME" or
+// "NAME" form, refers to when there's no local repository to go by.
+func resolveBrowseProject(dest string) (*github.Project, error) {
+	wanted := github.NewProject("", dest, "")
+	gh := github.NewClient(wanted.Host)
+
+	if strings.Contains(dest, "/") {
+		if repo, err := findRepository(gh, wanted); repo != nil || err != nil {
+			return repositoryProject(repo, wanted.Host), err
+		}
+	} else {
+		// wanted is owned by the current user when dest has no owner
+		if repo, err := findRepository(gh, wanted); repo != nil || err != nil {
+			return repositoryProject(repo, wanted.Host), err
+		}
+
+		orgs, err := gh.CurrentUserOrgs()
+		if err != nil {
+			return nil, err
+		}
+		candidates := []github.Repository{}
+		for _, org := range orgs {
+			repo, err := findRepository(gh, github.NewProject(org.Login, wanted.Name, wanted.Host))
+			if err != nil {
+				return nil, err
+			} else if repo != nil {
+				candidates = append(candidates, *repo)
+			}
+		}
+		if len(candidates) == 1 {
+			return repositoryProject(&candidates[0], wanted.Host), nil
+		} else if len(candidates) > 1 {
+			return pickRepository(dest, candidates, wanted.Host)
+		}
+	}
+
+	candidates, err := gh.SearchRepositories(wanted.Name+" in:name", 10)
+	if err != nil {
+		return nil, err
+	}
+	if len(candidates) == 0 {
+		return nil, utils.WithExitStatus(fmt.Errorf("Error: no repository found for '%s'", dest), utils.ExitNotFound)
+	}
+	// unlike a lone search result with the exact name, a fuzzy match is a
+	// guess that needs confirming
+	if len(candidates) == 1 && strings.EqualFold(candidates[0].Name, wanted.Name) {
+		return repositoryProject(&candidates[0], wanted.Host), nil
+	}
+	return pickRepository(dest, candidates, wanted.Host)
+}
+
+// findRepository returns nil without an error if project doesn't exist.
+func findRepository(gh *github.Client, project *github.Project) (*github.Repository, error) {
+	repo, err := gh.Repository(project)
+	if err != nil && utils.ExitStatus(err) == utils.ExitNotFound {
+		return nil, nil
+	}
+	return repo, err
+}
+
+func repositoryProject(repo *github.Repository, host string) *github.Project {
+	if repo == nil {
+		return nil
+	}
+	return github.NewProject("", repo.FullName, host)
+}
+
+// pickRepository asks the user to choose one of repos if hub runs in a
+// terminal, and otherwise fails with an error that lists them.
+func pickRepository(dest string, repos []github.Repository, host string) (*github.Project, error) {
+	if !ui.IsTerminal(os.Stdin) || !ui.IsTerminal(os.Stderr) {
+		names := []string{}
+		for _, repo := range repos {
+			names = append(names, "  "+repo.FullName)
+		}
+		return nil, fmt.Errorf("Aborted: '%s' is ambiguous. Matching repositories:\n%s", dest, strings.Join(names, "\n"))
+	}
+
+	for i, repo := range repos {
+		if repo.Description != "" {
+			ui.Errorf("%2d. %s - %s\n", i+1, repo.FullName, repo.Description)
+		} else {
+			ui.Errorf("%2d. %s\n", i+1, repo.FullName)
+		}
+	}
+	ui.Errorf("Choose a repository [1-%d]: ", len(repos))
+	answer := ""
+	scanner := bufio.NewScanner(os.Stdin)
+	if scanner.Scan() {
+		answer = strings.TrimSpace(scanner.Text())
+	}
+	if choice, err := strconv.Atoi(answer); err == nil && choice >= 1 && choice <= len(repos) {
+		return repositoryProject(&repos[choice-1], host), nil
+	}
+	return nil, fmt.Errorf("Aborted: no repository chosen")
+}
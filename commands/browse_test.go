@@ -0,0 +1,29 @@
+package commands
+
+import (
+	"testing"
+
+	"github.com/bmizerany/assert"
+	"github.com/github/hub/github"
+)
+
+func TestFilePagePath(t *testing.T) {
+	branch := &github.Branch{Name: "refs/remotes/origin/feature/x"}
+
+	assert.Equal(t, "blob/feature/x/lib/main.go", filePagePath(branch, "lib/main.go", false, nil))
+	assert.Equal(t, "blob/feature/x/lib/main.go#L4", filePagePath(branch, "lib/main.go", false, []string{"4"}))
+	assert.Equal(t, "blob/feature/x/lib/main.go#L4-L10", filePagePath(branch, "lib/main.go", false, []string{"4", "10"}))
+	assert.Equal(t, "blob/feature/x/docs/read%20me.md", filePagePath(branch, "docs/read me.md", false, nil))
+	assert.Equal(t, "tree/feature/x/lib", filePagePath(branch, "lib", true, []string{"4"}))
+	assert.Equal(t, "tree/feature/x", filePagePath(branch, ".", true, nil))
+}
+
+func TestPullRequestNumberRe(t *testing.T) {
+	for _, dest := range []string{"#12", "pull/12", "PR12", "pr12", "Pr12"} {
+		match := pullRequestNumberRe.FindStringSubmatch(dest)
+		assert.Equal(t, []string{dest, "12"}, match)
+	}
+	assert.Equal(t, false, pullRequestNumberRe.MatchString("12"))
+	assert.Equal(t, false, pullRequestNumberRe.MatchString("pulls/12"))
+	assert.Equal(t, false, pullRequestNumberRe.MatchString("prs12"))
+}
@@ -0,0 +1,73 @@
+package commands
+
+import (
+	"fmt"
+	"io/ioutil"
+	"regexp"
+	"strings"
+)
+
+const defaultChangelogFile = "CHANGELOG.md"
+
+var (
+	changelogHeadingRe = regexp.MustCompile(`^##\s+\[?([^\]\s]+)\]?`)
+	changelogLinkRefRe = regexp.MustCompile(`^\[[^\]]+\]:\s`)
+)
+
+// changelogSection reads the section for version from a changelog in the
+// "Keep a Changelog" format, where each release has a heading such as
+// "## [1.2.3] - 2024-01-01" or "## v1.2.3".
+func changelogSection(filename, version string) (string, error) {
+	content, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return "", err
+	}
+	section, found, versions := extractChangelogSection(string(content), version)
+	if !found {
+		if len(versions) == 0 {
+			return "", fmt.Errorf("no version sections found in %s", filename)
+		}
+		return "", fmt.Errorf("no section for %s found in %s (found versions: %s)", version, filename, strings.Join(versions, ", "))
+	}
+	return section, nil
+}
+
+// extractChangelogSection returns the text between the heading for version
+// and the next heading of the same level. Versions match regardless of a
+// leading "v". Link reference definitions, which Keep a Changelog collects at
+// the end of the file, are left out. All versions that have a heading are
+// returned as well so that callers can report what's available.
+func extractChangelogSection(content, version string) (section string, found bool, versions []string) {
+	lines := []string{}
+	inSection := false
+	inFence := false
+
+	for _, line := range strings.Split(strings.Replace(content, "\r\n", "\n", -1), "\n") {
+		if strings.HasPrefix(line, "```") {
+			inFence = !inFence
+		}
+		if !inFence {
+			if m := changelogHeadingRe.FindStringSubmatch(line); m != nil {
+				versions = append(versions, m[1])
+				inSection = !found && sameVersion(m[1], version)
+				found = found || inSection
+				continue
+			} else if strings.HasPrefix(line, "# ") {
+				inSection = false
+				continue
+			} else if changelogLinkRefRe.MatchString(line) {
+				continue
+			}
+		}
+		if inSection {
+			lines = append(lines, line)
+		}
+	}
+
+	section = strings.TrimSpace(strings.Join(lines, "\n"))
+	return
+}
+
+func sameVersion(a, b string) bool {
+	return strings.TrimPrefix(a, "v") == strings.TrimPrefix(b, "v")
+}
@@ -0,0 +1,47 @@
+package commands
+
+import (
+	"testing"
+
+	"github.com/bmizerany/assert"
+)
+
+const testChangelog = `# Changelog
+
+## [Unreleased]
+
+- Upcoming work
+
+## [1.2.3] - 2024-01-01
+
+### Added
+
+- Shiny things
+
+` + "```" + `
+## not a heading
+` + "```" + `
+
+## v1.2.0
+
+### Fixed
+
+- Old bugs
+
+[Unreleased]: https://github.com/octo/app/compare/v1.2.3...HEAD
+[1.2.3]: https://github.com/octo/app/compare/v1.2.0...v1.2.3
+`
+
+func TestExtractChangelogSection(t *testing.T) {
+	section, found, versions := extractChangelogSection(testChangelog, "v1.2.3")
+	assert.T(t, found)
+	assert.Equal(t, "### Added\n\n- Shiny things\n\n```\n## not a heading\n```", section)
+	assert.Equal(t, []string{"Unreleased", "1.2.3", "v1.2.0"}, versions)
+
+	section, found, _ = extractChangelogSection(testChangelog, "1.2.0")
+	assert.T(t, found)
+	assert.Equal(t, "### Fixed\n\n- Old bugs", section)
+
+	_, found, _ = extractChangelogSection(testChangelog, "v2.0.0")
+	assert.T(t, !found)
+}
@@ -26,6 +26,8 @@ hub-merge(1), hub-am(1), hub(1), git-checkout(1)
 `,
 }
 
+var pullURLRegexp = regexp.MustCompile(`^pull/(\d+)`)
+
 func init() {
 	CmdRunner.Use(cmdCheckout)
 }
@@ -43,28 +45,20 @@ func checkout(command *Command, args *Args) {
 		newBranchName = words[1]
 	}
 
-	url, err := github.ParseURL(checkoutURL)
-	if err != nil {
-		// not a valid GitHub URL
-		return
-	}
-
-	pullURLRegex := regexp.MustCompile("^pull/(\\d+)")
-	projectPath := url.ProjectPath()
-	if !pullURLRegex.MatchString(projectPath) {
+	project, id := parsePullRequestURL(checkoutURL)
+	if project == nil {
 		// not a valid PR URL
 		return
 	}
 
-	err = sanitizeCheckoutFlags(args)
+	err := sanitizeCheckoutFlags(args)
 	utils.Check(err)
 
-	id := pullURLRegex.FindStringSubmatch(projectPath)[1]
-	gh := github.NewClient(url.Project.Host)
-	pullRequest, err := gh.PullRequest(url.Project, id)
+	gh := github.NewClient(project.Host)
+	pullRequest, err := gh.PullRequest(project, id)
 	utils.Check(err)
 
-	newArgs, err := transformCheckoutArgs(args, pullRequest, newBranchName)
+	newArgs, _, err := transformCheckoutArgs(args, pullRequest, newBranchName)
 	utils.Check(err)
 
 	if idx := args.IndexOfParam(newBranchName); idx >= 0 {
@@ -73,39 +67,78 @@ func checkout(command *Command, args *Args) {
 	replaceCheckoutParam(args, checkoutURL, newArgs...)
 }
 
-func transformCheckoutArgs(args *Args, pullRequest *github.PullRequest, newBranchName string) (newArgs []string, err error) {
-	repo, err := github.LocalRepo()
+// parsePullRequestURL returns the project and the number of the pull request
+// that a GitHub URL points to, or a nil project if it's not such a URL.
+func parsePullRequestURL(pullURL string) (*github.Project, string) {
+	url, err := github.ParseURL(pullURL)
 	if err != nil {
-		return
+		return nil, ""
 	}
+	if match := pullURLRegexp.FindStringSubmatch(url.ProjectPath()); match != nil {
+		return url.Project, match[1]
+	}
+	return nil, ""
+}
 
-	baseRemote, err := repo.RemoteForRepo(pullRequest.Base.Repo)
+// transformCheckoutArgs sets up args to fetch the head of pullRequest and
+// check it out in a branch named after its head ref unless newBranchName is
+// given. A branch that's already there is fast-forwarded instead, as long as
+// it tracks the pull request when fetching its head from refs/pull. When the
+// pull request belongs to a fork network that no git remote points to, its
+// head repository is added as a remote, or, if that repository is gone, the
+// head is fetched from the base repository directly.
+func transformCheckoutArgs(args *Args, pullRequest *github.PullRequest, newBranchName string) (newArgs []string, branchName string, err error) {
+	repo, err := github.LocalRepo()
 	if err != nil {
 		return
 	}
+	if pullRequest.Head == nil {
+		err = fmt.Errorf("Error: can't find the head of pull request #%d", pullRequest.Number)
+		return
+	}
+
+	baseRemote, baseRemoteErr := repo.RemoteForRepo(pullRequest.Base.Repo)
 
 	var headRemote *github.Remote
-	if pullRequest.IsSameRepo() {
+	if pullRequest.IsSameRepo() && baseRemoteErr == nil {
 		headRemote = baseRemote
 	} else if pullRequest.Head.Repo != nil {
 		headRemote, _ = repo.RemoteForRepo(pullRequest.Head.Repo)
 	}
 
+	headRemoteName := ""
 	if headRemote != nil {
+		headRemoteName = headRemote.Name
+	} else if baseRemoteErr != nil && pullRequest.Head.Repo != nil {
+		var headProject *github.Project
+		headProject, err = github.NewProjectFromRepo(pullRequest.Head.Repo)
+		if err != nil {
+			return
+		}
+		headRemoteName = headProject.Owner
+		if _, remoteErr := repo.RemoteByName(headRemoteName); remoteErr == nil {
+			err = fmt.Errorf("Error: can't add a git remote for '%s' since a remote named '%s' already exists", headProject, headRemoteName)
+			return
+		}
+		isSSH := pullRequest.MaintainerCanModify || pullRequest.Head.Repo.Private
+		args.Before("git", "remote", "add", headRemoteName, headProject.GitURL("", "", isSSH))
+	}
+
+	if headRemoteName != "" {
 		if newBranchName == "" {
 			newBranchName = pullRequest.Head.Ref
 		}
-		remoteBranch := fmt.Sprintf("%s/%s", headRemote.Name, pullRequest.Head.Ref)
+		remoteBranch := fmt.Sprintf("%s/%s", headRemoteName, pullRequest.Head.Ref)
 		refSpec := fmt.Sprintf("+refs/heads/%s:refs/remotes/%s", pullRequest.Head.Ref, remoteBranch)
 		if git.HasFile("refs", "heads", newBranchName) {
 			newArgs = append(newArgs, newBranchName)
 			args.After("git", "merge", "--ff-only", fmt.Sprintf("refs/remotes/%s", remoteBranch))
 		} else {
 			newArgs = append(newArgs, "-b", newBranchName, "--no-track", remoteBranch)
-			args.After("git", "config", fmt.Sprintf("branch.%s.remote", newBranchName), headRemote.Name)
+			args.After("git", "config", fmt.Sprintf("branch.%s.remote", newBranchName), headRemoteName)
 			args.After("git", "config", fmt.Sprintf("branch.%s.merge", newBranchName), "refs/heads/"+pullRequest.Head.Ref)
 		}
-		args.Before("git", "fetch", headRemote.Name, refSpec)
+		args.Before("git", "fetch", headRemoteName, refSpec)
 	} else {
 		if newBranchName == "" {
 			newBranchName = pullRequest.Head.Ref
@@ -115,10 +148,21 @@ func transformCheckoutArgs(args *Args, pullRequest *github.PullRequest, newBranc
 		}
 		newArgs = append(newArgs, newBranchName)
 
-		ref := fmt.Sprintf("refs/pull/%d/head", pullRequest.Number)
-		args.Before("git", "fetch", baseRemote.Name, fmt.Sprintf("%s:%s", ref, newBranchName))
+		baseRemoteName := ""
+		if baseRemote != nil {
+			baseRemoteName = baseRemote.Name
+		} else {
+			// the head repository was deleted
+			var baseProject *github.Project
+			baseProject, err = github.NewProjectFromRepo(pullRequest.Base.Repo)
+			if err != nil {
+				return
+			}
+			baseRemoteName = baseProject.GitURL("", "", pullRequest.Base.Repo.Private)
+		}
 
-		remote := baseRemote.Name
+		ref := fmt.Sprintf("refs/pull/%d/head", pullRequest.Number)
+		remote := baseRemoteName
 		mergeRef := ref
 		if pullRequest.MaintainerCanModify && pullRequest.Head.Repo != nil {
 			var project *github.Project
@@ -130,12 +174,34 @@ func transformCheckoutArgs(args *Args, pullRequest *github.PullRequest, newBranc
 			remote = project.GitURL("", "", true)
 			mergeRef = fmt.Sprintf("refs/heads/%s", pullRequest.Head.Ref)
 		}
+
+		if git.HasFile("refs", "heads", newBranchName) {
+			// only a branch from an earlier checkout of the same pull request
+			// is fast-forwarded; any other would be clobbered
+			if !branchTracks(newBranchName, baseRemoteName, ref) && !branchTracks(newBranchName, remote, mergeRef) {
+				err = fmt.Errorf("Error: branch '%s' already exists and doesn't track pull request #%d\n(give another branch name to check it out under)", newBranchName, pullRequest.Number)
+				return
+			}
+			args.Before("git", "fetch", baseRemoteName, ref)
+			args.After("git", "merge", "--ff-only", "FETCH_HEAD")
+		} else {
+			args.Before("git", "fetch", baseRemoteName, fmt.Sprintf("%s:%s", ref, newBranchName))
+		}
+
 		args.After("git", "config", fmt.Sprintf("branch.%s.remote", newBranchName), remote)
 		args.After("git", "config", fmt.Sprintf("branch.%s.merge", newBranchName), mergeRef)
 	}
+	branchName = newBranchName
 	return
 }
 
+// branchTracks reports whether the upstream of branch is mergeRef on remote.
+func branchTracks(branch, remote, mergeRef string) bool {
+	branchRemote, _ := git.Config(fmt.Sprintf("branch.%s.remote", branch))
+	branchMerge, _ := git.Config(fmt.Sprintf("branch.%s.merge", branch))
+	return branchRemote == remote && branchMerge == mergeRef
+}
+
 func sanitizeCheckoutFlags(args *Args) error {
 	if i := args.IndexOfParam("-b"); i != -1 {
 		return fmt.Errorf("Unsupported flag -b when checking out pull request")
@@ -1,9 +1,15 @@
 package commands
 
 import (
+	"encoding/json"
 	"fmt"
+	"io/ioutil"
 	"os"
+	"path"
+	"regexp"
 	"sort"
+	"strings"
+	"time"
 
 	"github.com/github/hub/git"
 	"github.com/github/hub/github"
@@ -12,8 +18,13 @@ import (
 )
 
 var cmdCiStatus = &Command{
-	Run:   ciStatus,
-	Usage: "ci-status [-v] [<COMMIT>]",
+	Run: ciStatus,
+	Usage: `
+ci-status [-v] [--context <PATTERN>] [--exclude-context <PATTERN>] [--required-only [--base <BRANCH>]] [--wait[=<INTERVAL>] [--notify]] [--json] [--no-fallback] [<COMMIT>]
+ci-status --rerun-failed [--context <PATTERN>] [--exclude-context <PATTERN>] [<COMMIT>]
+ci-status [--context <PATTERN>] [--exclude-context <PATTERN>] <RANGE>
+ci-status --batch [-F <FILE>] [--json]
+`,
 	Long: `Display status of GitHub checks for a commit.
 
 ## Options:
@@ -33,23 +44,132 @@ var cmdCiStatus = &Command{
 
 		%t: name of the status check
 
+		%pS: when the check started, relative (e.g. "4 minutes ago")
+
+		%pE: when the check completed, relative
+
+		%pD: how long the check took (e.g. "12m33s"), or how long it has been
+		running so far if it hasn't completed
+
+		Only check runs have these timestamps; for legacy commit statuses, the
+		%pS, %pE, and %pD placeholders expand to an empty string.
+
+	--context <PATTERN>
+		Only consider status checks whose name matches <PATTERN>. This option
+		can be repeated to select checks matching any of the patterns.
+
+	--exclude-context <PATTERN>
+		Ignore status checks whose name matches <PATTERN>, even if they were
+		selected with '--context'. This option can be repeated.
+
+		Patterns are shell globs such as "ci/*", or regular expressions when
+		enclosed in slashes such as "/^(lint|test)$/", and match names without
+		regard to case. The exit status as well as the report only reflect the
+		checks that remain.
+
+	--required-only
+		Only consider the status checks that the branch protection of the base
+		branch requires for merging. The base branch is that of the open pull
+		request for <COMMIT>. Required checks that haven't reported on the commit
+		at all are considered pending.
+
+		Reading the branch protection takes admin access to the repository.
+		Without it, hub uses the required checks that the branch lists, and only
+		if the branch can't be read either, the checks that GitHub lists as
+		required for the pull request, which leaves out the required checks that
+		haven't reported yet.
+
+	--base <BRANCH>
+		With '--required-only', use the branch protection of <BRANCH> instead of
+		looking up the pull request for <COMMIT>.
+
 	--color[=<WHEN>]
 		Enable colored output even if stdout is not a terminal. <WHEN> can be one
 		of "always" (default for '--color'), "never", or "auto" (default).
 
+	--wait[=<INTERVAL>]
+		While any check is pending, keep checking again every <INTERVAL>, a
+		number of seconds or a duration such as "5m" (default: 30), and only
+		report the status once all checks have completed.
+
+	--notify
+		When done waiting, show a desktop notification with the repository, the
+		commit, and its status. The notification is shown with osascript(1) on
+		macOS, notify-send(1) on Linux, and PowerShell on Windows. Without any of
+		them, the terminal bell rings instead.
+
+	--no-fallback
+		Report the status of the local commit even if it has none. Without this,
+		when the commit of the current branch, or of the branch named by
+		<COMMIT>, has no status checks at all, the status of the tip of the
+		remote branch that it tracks or is pushed to is reported instead, with a
+		notice on standard error. Commits given as a SHA never fall back.
+
+	--rerun-failed
+		Re-run the failed checks of the commit instead of reporting its status,
+		and print the name of each check that was re-run. Checks of GitHub Actions
+		are re-run as workflow jobs; other check runs are re-requested from their
+		app. Legacy commit statuses can't be re-run and are skipped with a warning.
+		With '--noop', list the API requests instead.
+
+	--batch
+		Display the status of many repositories at once, one aligned row per
+		repository with its ref, state, and the number of failing checks. Errors
+		such as an inaccessible repository or an unknown ref are reported in the
+		row of that repository.
+
+	-F, --file <FILE>
+		In batch mode, read the list of repositories from <FILE>, one
+		"[<HOST>/]<OWNER>/<REPO>[@<REF>]" per line. Empty lines and lines starting
+		with "#" are ignored. Without a ref, the default branch of the repository
+		is used. Pass "-" or omit this option to read from standard input.
+
+	--json
+		Print the combined state, the exit status, and all status checks with
+		their "context", "state", and "target_url" as a JSON object. Can't be
+		combined with '--format'. In batch mode, print the results as a JSON
+		array instead.
+
 	<COMMIT>
 		A commit SHA or branch name (default: "HEAD").
 
+	<RANGE>
+		A range of commits such as "main..topic" or "main...topic". The combined
+		status of every commit in the range is listed, newest first, with the
+		abbreviated SHA and subject of the commit. At most 50 commits can be
+		listed at once.
+
+Both commit statuses and the check runs of the GitHub Checks API, such as
+those of GitHub Actions, are reported. A check run that has the same name as
+a commit status replaces it. Check runs that are queued or in progress are
+"pending", and skipped or stale ones are "neutral".
+
 Possible outputs and exit statuses:
 
 - success, neutral: 0
 - failure, error, action_required, cancelled, timed_out: 1
 - pending: 2
+- no status: 3
+
+For a range of commits, the exit status is that of the most severe state
+among them. In batch mode, the exit status is that of the worst result: a failure in any
+repository, then any repository without status or whose status couldn't be
+fetched, then pending.
+
+## Examples:
+		$ hub ci-status --batch -F repos.txt
+		github/hub       master   success  0 failing
+		github/docs      main     failure  2 failing
+		github/private   main     Error fetching statuses: Not Found (HTTP 404)
 
 ## See also:
 
 hub-pull-request(1), hub(1)
 `,
+	FlagValues: map[string]flagValue{
+		"--color": colorValue,
+		"--wait":  durationValue(),
+	},
 }
 
 var severityList []string
@@ -79,6 +199,25 @@ func checkSeverity(targetState string) int {
 }
 
 func ciStatus(cmd *Command, args *Args) {
+	wait := args.Flag.HasReceived("--wait")
+	if args.Flag.Bool("--notify") && !wait {
+		utils.Check(cmd.UsageError("the '--notify' option requires '--wait'"))
+	}
+	requiredOnly := args.Flag.Bool("--required-only")
+	if args.Flag.HasReceived("--base") && !requiredOnly {
+		utils.Check(cmd.UsageError("the '--base' option requires '--required-only'"))
+	}
+	if args.Flag.Bool("--batch") {
+		if wait {
+			utils.Check(cmd.UsageError("the '--batch' and '--wait' options are mutually exclusive"))
+		}
+		if requiredOnly {
+			utils.Check(cmd.UsageError("the '--batch' and '--required-only' options are mutually exclusive"))
+		}
+		ciStatusBatch(cmd, args)
+		return
+	}
+
 	ref := "HEAD"
 	if !args.IsParamsEmpty() {
 		ref = args.RemoveParam(0)
@@ -90,44 +229,119 @@ func ciStatus(cmd *Command, args *Args) {
 	project, err := localRepo.MainProject()
 	utils.Check(err)
 
+	rerun := args.Flag.Bool("--rerun-failed")
+	if rerun {
+		for _, flag := range []string{"--wait", "--format", "--json", "--required-only"} {
+			if args.Flag.HasReceived(flag) {
+				utils.Check(cmd.UsageError(fmt.Sprintf("the '--rerun-failed' and '%s' options are mutually exclusive", flag)))
+			}
+		}
+	}
+
+	if strings.Contains(ref, "..") {
+		for _, flag := range []string{"--wait", "--format", "--json", "--rerun-failed", "--required-only"} {
+			if args.Flag.HasReceived(flag) {
+				utils.Check(cmd.UsageError(fmt.Sprintf("the '%s' option can't be used with a range of commits", flag)))
+			}
+		}
+		ciStatusRange(cmd, args, project, ref)
+		return
+	}
+
 	sha, err := git.Ref(ref)
 	if err != nil {
 		err = fmt.Errorf("Aborted: no revision could be determined from '%s'", ref)
 	}
 	utils.Check(err)
 
-	if args.Noop {
+	jsonOutput := args.Flag.Bool("--json")
+	if jsonOutput && args.Flag.HasReceived("--format") {
+		utils.Check(cmd.UsageError("the '--json' and '--format' options are mutually exclusive"))
+	}
+
+	contexts, err := parseContextFilter(args.Flag.AllValues("--context"), args.Flag.AllValues("--exclude-context"))
+	if err != nil {
+		utils.Check(cmd.UsageError(err.Error()))
+	}
+
+	var format *ui.Format
+	if args.Flag.HasReceived("--format") {
+		format, err = ui.CompileFormat(args.Flag.Value("--format"))
+		utils.Check(err)
+	}
+
+	if rerun {
+		ciRerunFailed(github.NewClient(project.Host), project, sha, contexts, args.Noop)
+	} else if args.Noop {
 		ui.Printf("Would request CI status for %s\n", sha)
 	} else {
 		gh := github.NewClient(project.Host)
-		response, err := gh.FetchCIStatus(project, sha)
-		utils.Check(err)
+		var required *ciRequiredChecks
+		if requiredOnly {
+			required, err = fetchCIRequiredChecks(gh, project, ref, sha, args.Flag.Value("--base"))
+			utils.Check(err)
+		}
+		fetched := -1
+		fetchStatuses := func() []github.CIStatus {
+			if required != nil && required.pullRequest > 0 {
+				statuses, err := gh.FetchRequiredPullRequestChecks(project, required.pullRequest)
+				utils.Check(err)
+				return contexts.filter(statuses)
+			}
+			response, err := gh.FetchCIStatus(project, sha)
+			utils.Check(err)
+			fetched = len(response.Statuses)
+			statuses := response.Statuses
+			if required != nil {
+				statuses = required.filter(statuses)
+			}
+			return contexts.filter(statuses)
+		}
 
-		state := ""
-		if len(response.Statuses) > 0 {
-			for _, status := range response.Statuses {
-				if checkSeverity(status.State) > checkSeverity(state) {
-					state = status.State
+		statuses := fetchStatuses()
+		if fetched == 0 && !args.Flag.Bool("--no-fallback") {
+			if branch, remoteProject, remoteSha := ciPushedBranch(localRepo, ref, sha); branch != nil {
+				ui.Errorf("no status for local commit %s; showing remote branch %s (%s)\n", sha[:7], branch.LongName(), remoteSha[:7])
+				project, sha = remoteProject, remoteSha
+				statuses = fetchStatuses()
+			}
+		}
+		state := ciState(statuses)
+		if wait {
+			interval := watchInterval(args, "--wait")
+			if state == "pending" && ui.IsTerminal(os.Stderr) {
+				ui.Errorf("Waiting for the pending checks of %s...\n", ref)
+			}
+			for state == "pending" {
+				emitChecksWait(ref, state, statuses)
+				time.Sleep(interval)
+				statuses = fetchStatuses()
+				state = ciState(statuses)
+			}
+			if args.Flag.Bool("--notify") {
+				notifyRef := ref
+				if ref == "HEAD" {
+					if branch, err := localRepo.CurrentBranch(); err == nil {
+						notifyRef = branch.ShortName()
+					}
 				}
+				notifyCIStatus(project, notifyRef, state)
 			}
 		}
+		exitCode := ciExitCode(state)
+		ui.Emit(ui.EventResult, ui.Fields{"command": "ci-status", "ref": ref, "sha": sha, "state": state, "exit": exitCode})
 
-		var exitCode int
-		switch state {
-		case "success", "neutral":
-			exitCode = 0
-		case "failure", "error", "action_required", "cancelled", "timed_out":
-			exitCode = 1
-		case "pending":
-			exitCode = 2
-		default:
-			exitCode = 3
+		if jsonOutput {
+			out, err := ciStatusJSON(state, exitCode, statuses)
+			utils.Check(err)
+			ui.Println(string(out))
+			os.Exit(exitCode)
 		}
 
 		verbose := args.Flag.Bool("--verbose") || args.Flag.HasReceived("--format")
-		if verbose && len(response.Statuses) > 0 {
+		if verbose && len(statuses) > 0 {
 			colorize := colorizeOutput(args.Flag.HasReceived("--color"), args.Flag.Value("--color"))
-			ciVerboseFormat(response.Statuses, args.Flag.Value("--format"), colorize)
+			ciVerboseFormat(statuses, format, colorize, hyperlinksEnabled())
 		} else {
 			if state != "" {
 				ui.Println(state)
@@ -140,7 +354,318 @@ func ciStatus(cmd *Command, args *Args) {
 	}
 }
 
-func ciVerboseFormat(statuses []github.CIStatus, formatString string, colorize bool) {
+// ciPushedBranch finds the remote branch that the local branch named by ref
+// tracks or is pushed to, along with its project and the SHA of its tip, when
+// that differs from sha. Refs other than "HEAD" and the names of local branches
+// yield nothing, so that an explicit commit is always reported as is.
+func ciPushedBranch(localRepo *github.GitHubRepo, ref, sha string) (*github.Branch, *github.Project, string) {
+	var branch *github.Branch
+	if ref == "HEAD" {
+		branch, _ = localRepo.CurrentBranch()
+	} else if _, err := git.Ref("refs/heads/" + ref); err == nil {
+		branch = &github.Branch{Repo: localRepo, Name: "refs/heads/" + ref}
+	}
+	if branch == nil {
+		return nil, nil, ""
+	}
+
+	remoteBranch, err := branch.Upstream()
+	if err != nil || !remoteBranch.IsRemote() {
+		owner := ""
+		if project, err := localRepo.MainProject(); err == nil {
+			if host := github.CurrentConfig().Find(project.Host); host != nil {
+				owner = host.User
+			}
+		}
+		remoteBranch = branch.PushTarget(owner, false)
+	}
+	if remoteBranch == nil || !remoteBranch.IsRemote() {
+		return nil, nil, ""
+	}
+
+	remoteSha, err := git.Ref(remoteBranch.Name)
+	if err != nil || remoteSha == sha {
+		return nil, nil, ""
+	}
+	remote, err := localRepo.RemoteByName(remoteBranch.RemoteName())
+	if err != nil {
+		return nil, nil, ""
+	}
+	project, err := remote.Project()
+	if err != nil {
+		return nil, nil, ""
+	}
+	return remoteBranch, project, remoteSha
+}
+
+// emitChecksWait reports for '--porcelain' how many checks are still pending.
+func emitChecksWait(ref, state string, statuses []github.CIStatus) {
+	pending := 0
+	for _, status := range statuses {
+		if status.State == "pending" {
+			pending++
+		}
+	}
+	ui.Emit(ui.EventChecksWait, ui.Fields{"ref": ref, "state": state, "pending": pending})
+}
+
+// ciState is the most severe state among the statuses of a commit, or an empty
+// string if there are none.
+func ciState(statuses []github.CIStatus) string {
+	state := ""
+	for _, status := range statuses {
+		if checkSeverity(status.State) > checkSeverity(state) {
+			state = status.State
+		}
+	}
+	return state
+}
+
+// ciRequiredChecks are the status checks that branch protection requires for
+// '--required-only'. When neither the protection settings nor the branch can be
+// read, the checks that GitHub reports as required for pullRequest are used
+// instead.
+type ciRequiredChecks struct {
+	contexts    []string
+	pullRequest int
+}
+
+// fetchCIRequiredChecks looks up the checks that the base branch requires:
+// either the one given with '--base', or that of the open pull request whose
+// head is sha.
+func fetchCIRequiredChecks(gh *github.Client, project *github.Project, ref, sha, base string) (*ciRequiredChecks, error) {
+	var pr *github.PullRequest
+	if base == "" {
+		pulls, err := gh.CommitPullRequests(project, sha)
+		if err != nil {
+			return nil, err
+		}
+		for i, pull := range pulls {
+			if pull.State != "open" || pull.Base == nil {
+				continue
+			}
+			if pr == nil || pull.Head != nil && pull.Head.Sha == sha {
+				pr = &pulls[i]
+			}
+		}
+		if pr == nil {
+			return nil, fmt.Errorf("Aborted: no open pull request found for '%s'; use '--base <BRANCH>' to name the protected branch", ref)
+		}
+		base = pr.Base.Ref
+	}
+
+	contexts, readable, err := gh.ProtectedStatusChecks(project, base)
+	if err != nil {
+		return nil, err
+	}
+	if readable {
+		return &ciRequiredChecks{contexts: contexts}, nil
+	}
+	// unlike the protection settings, the branch lists the required checks
+	// to anyone who can read the repository
+	if contexts, err = gh.RequiredStatusChecks(project, base); err == nil {
+		return &ciRequiredChecks{contexts: contexts}, nil
+	}
+	if pr == nil {
+		return nil, err
+	}
+	ui.Errorf("warning: can't read the checks that '%s' of %s requires; using the checks required for pull request #%d instead\n", base, project, pr.Number)
+	return &ciRequiredChecks{pullRequest: pr.Number}, nil
+}
+
+// filter returns the statuses of required checks, followed by a pending
+// status for each required check that hasn't reported at all.
+func (r *ciRequiredChecks) filter(statuses []github.CIStatus) []github.CIStatus {
+	required := map[string]bool{}
+	for _, context := range r.contexts {
+		required[context] = true
+	}
+
+	selected := []github.CIStatus{}
+	reported := map[string]bool{}
+	for _, status := range statuses {
+		if required[status.Context] {
+			selected = append(selected, status)
+			reported[status.Context] = true
+		}
+	}
+	for _, context := range r.contexts {
+		if !reported[context] {
+			selected = append(selected, github.CIStatus{State: "pending", Context: context})
+		}
+	}
+	return selected
+}
+
+// contextFilter selects status checks by name for '--context' and
+// '--exclude-context'.
+type contextFilter struct {
+	include []*regexp.Regexp
+	exclude []*regexp.Regexp
+}
+
+// contextPattern compiles a glob, or a regular expression enclosed in slashes,
+// into a case-insensitive regular expression matching whole check names.
+func contextPattern(pattern string) (*regexp.Regexp, error) {
+	expr := ""
+	if len(pattern) > 1 && strings.HasPrefix(pattern, "/") && strings.HasSuffix(pattern, "/") {
+		expr = pattern[1 : len(pattern)-1]
+	} else {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return nil, fmt.Errorf("invalid context pattern %q: %s", pattern, err)
+		}
+		expr = "^"
+		for i := 0; i < len(pattern); i++ {
+			switch c := pattern[i]; c {
+			case '*':
+				expr += ".*"
+			case '?':
+				expr += "."
+			case '[':
+				end := strings.IndexByte(pattern[i:], ']')
+				class := pattern[i+1 : i+end]
+				if strings.HasPrefix(class, "!") {
+					class = "^" + class[1:]
+				}
+				expr += "[" + class + "]"
+				i += end
+			case '\\':
+				if i+1 < len(pattern) {
+					i++
+				}
+				expr += regexp.QuoteMeta(pattern[i : i+1])
+			default:
+				expr += regexp.QuoteMeta(string(c))
+			}
+		}
+		expr += "$"
+	}
+
+	re, err := regexp.Compile("(?i)" + expr)
+	if err != nil {
+		return nil, fmt.Errorf("invalid context pattern %q: %s", pattern, err)
+	}
+	return re, nil
+}
+
+func parseContextFilter(include, exclude []string) (*contextFilter, error) {
+	filter := &contextFilter{}
+	for _, pattern := range include {
+		re, err := contextPattern(pattern)
+		if err != nil {
+			return nil, err
+		}
+		filter.include = append(filter.include, re)
+	}
+	for _, pattern := range exclude {
+		re, err := contextPattern(pattern)
+		if err != nil {
+			return nil, err
+		}
+		filter.exclude = append(filter.exclude, re)
+	}
+	return filter, nil
+}
+
+func matchesAny(patterns []*regexp.Regexp, name string) bool {
+	for _, re := range patterns {
+		if re.MatchString(name) {
+			return true
+		}
+	}
+	return false
+}
+
+// filter returns the statuses selected by any include pattern, or all of them
+// if there are none, minus those matching an exclude pattern.
+func (f *contextFilter) filter(statuses []github.CIStatus) []github.CIStatus {
+	if len(f.include) == 0 && len(f.exclude) == 0 {
+		return statuses
+	}
+	selected := []github.CIStatus{}
+	for _, status := range statuses {
+		if len(f.include) > 0 && !matchesAny(f.include, status.Context) {
+			continue
+		}
+		if matchesAny(f.exclude, status.Context) {
+			continue
+		}
+		selected = append(selected, status)
+	}
+	return selected
+}
+
+// ciRerunFailed re-runs the check runs of sha that failed. Commit statuses
+// that failed are reported, since the API can't re-run them.
+func ciRerunFailed(gh *github.Client, project *github.Project, sha string, contexts *contextFilter, noop bool) {
+	response, checkRuns, err := gh.FetchCIStatusAndCheckRuns(project, sha)
+	utils.Check(err)
+
+	checkRunsByName := map[string][]github.CheckRun{}
+	for _, checkRun := range checkRuns {
+		checkRunsByName[checkRun.Name] = append(checkRunsByName[checkRun.Name], checkRun)
+	}
+
+	failed := 0
+	for _, status := range contexts.filter(response.Statuses) {
+		if ciExitCode(status.State) != utils.ExitError {
+			continue
+		}
+		failed++
+		if len(checkRunsByName[status.Context]) == 0 {
+			ui.Errorf("warning: can't re-run '%s', which is a commit status\n", status.Context)
+			continue
+		}
+		for _, checkRun := range checkRunsByName[status.Context] {
+			if ciExitCode(checkRun.State()) != utils.ExitError {
+				continue
+			}
+			if noop {
+				ui.Printf("Would request POST /%s\n", checkRun.RerunPath(project))
+				continue
+			}
+			utils.Check(gh.RerunCheckRun(project, checkRun))
+			ui.Printf("Re-running %s\n", checkRun.Name)
+		}
+	}
+
+	if failed == 0 {
+		ui.Println("no failed checks")
+	}
+}
+
+// ciStatusReport is the JSON output of 'ci-status --json'.
+type ciStatusReport struct {
+	State    string            `json:"state"`
+	ExitCode int               `json:"exit_code"`
+	Statuses []github.CIStatus `json:"statuses"`
+}
+
+func ciStatusJSON(state string, exitCode int, statuses []github.CIStatus) ([]byte, error) {
+	if statuses == nil {
+		statuses = []github.CIStatus{}
+	}
+	return json.MarshalIndent(ciStatusReport{
+		State:    state,
+		ExitCode: exitCode,
+		Statuses: statuses,
+	}, "", "  ")
+}
+
+func ciExitCode(state string) int {
+	switch state {
+	case "success", "neutral":
+		return utils.ExitSuccess
+	case "failure", "error", "action_required", "cancelled", "timed_out":
+		return utils.ExitError
+	case "pending":
+		return utils.ExitPending
+	default:
+		return utils.ExitNotFound
+	}
+}
+
+func ciVerboseFormat(statuses []github.CIStatus, format *ui.Format, colorize, hyperlinks bool) {
 	contextWidth := 0
 	for _, status := range statuses {
 		if len(status.Context) > contextWidth {
@@ -152,45 +677,136 @@ func ciVerboseFormat(statuses []github.CIStatus, formatString string, colorize b
 		return stateRank(statuses[a].State) < stateRank(statuses[b].State)
 	})
 
+	defaultFormats := map[string]*ui.Format{}
 	for _, status := range statuses {
-		var color int
-		var stateMarker string
-		switch status.State {
-		case "success":
-			stateMarker = "✔︎"
-			color = 32
-		case "failure", "error", "action_required", "cancelled", "timed_out":
-			stateMarker = "✖︎"
-			color = 31
-		case "neutral":
-			stateMarker = "◦"
-			color = 30
-		case "pending":
-			stateMarker = "●"
-			color = 33
-		}
+		stateMarker, color := ciStateMarker(status.State)
 
 		placeholders := map[string]string{
 			"S":  status.State,
 			"sC": "",
 			"t":  status.Context,
 			"U":  status.TargetUrl,
+			"pS": ciTimeAgo(status.StartedAt),
+			"pE": ciTimeAgo(status.CompletedAt),
+			"pD": ciCheckDuration(status, time.Now()),
 		}
 
 		if colorize {
 			placeholders["sC"] = fmt.Sprintf("\033[%dm", color)
 		}
+		if hyperlinks {
+			linkPlaceholders(placeholders, status.TargetUrl, "t", "U")
+		}
 
-		format := formatString
-		if format == "" {
+		statusFormat := format
+		if statusFormat == nil {
+			var formatString string
 			if status.TargetUrl == "" {
-				format = fmt.Sprintf("%%sC%s%%Creset\t%%t\n", stateMarker)
+				formatString = fmt.Sprintf("%%sC%s%%Creset\t%%t\n", stateMarker)
 			} else {
-				format = fmt.Sprintf("%%sC%s%%Creset\t%%<(%d)%%t\t%%U\n", stateMarker, contextWidth)
+				formatString = fmt.Sprintf("%%sC%s%%Creset\t%%<(%d)%%t\t%%U\n", stateMarker, contextWidth)
+			}
+			if statusFormat = defaultFormats[formatString]; statusFormat == nil {
+				statusFormat, _ = ui.CompileFormat(formatString)
+				defaultFormats[formatString] = statusFormat
 			}
 		}
-		ui.Print(ui.Expand(format, placeholders, colorize))
+		ui.Print(statusFormat.Expand(placeholders, colorize))
+	}
+}
+
+func ciTimeAgo(t *time.Time) string {
+	if t == nil || t.IsZero() {
+		return ""
+	}
+	return utils.TimeAgo(*t)
+}
+
+// ciCheckDuration is how long a check took, or how long it has been running
+// as of now if it hasn't completed yet.
+func ciCheckDuration(status github.CIStatus, now time.Time) string {
+	if status.StartedAt == nil || status.StartedAt.IsZero() {
+		return ""
+	}
+	end := now
+	if status.CompletedAt != nil && !status.CompletedAt.IsZero() {
+		end = *status.CompletedAt
 	}
+	return end.Sub(*status.StartedAt).Round(time.Second).String()
+}
+
+// ciStateMarker is the symbol and the terminal color of a state.
+func ciStateMarker(state string) (marker string, color int) {
+	switch state {
+	case "success":
+		return "✔︎", 32
+	case "failure", "error", "action_required", "cancelled", "timed_out":
+		return "✖︎", 31
+	case "neutral":
+		return "◦", 30
+	case "pending":
+		return "●", 33
+	default:
+		return "-", 0
+	}
+}
+
+const ciRangeLimit = 50
+
+func ciStatusRange(cmd *Command, args *Args, project *github.Project, revRange string) {
+	contexts, err := parseContextFilter(args.Flag.AllValues("--context"), args.Flag.AllValues("--exclude-context"))
+	if err != nil {
+		utils.Check(cmd.UsageError(err.Error()))
+	}
+
+	commits, err := git.RangeCommits(revRange, ciRangeLimit+1)
+	if err != nil {
+		err = fmt.Errorf("Aborted: no commits could be determined from '%s'", revRange)
+	}
+	utils.Check(err)
+	if len(commits) > ciRangeLimit {
+		utils.Check(fmt.Errorf("Aborted: '%s' has more than %d commits; narrow down the range", revRange, ciRangeLimit))
+	}
+
+	if args.Noop {
+		ui.Printf("Would request CI status for %d commits\n", len(commits))
+		return
+	}
+
+	gh := github.NewClient(project.Host)
+	states := []string{}
+	for _, commit := range commits {
+		response, err := gh.FetchCIStatus(project, commit.Sha)
+		utils.Check(err)
+		states = append(states, ciState(contexts.filter(response.Statuses)))
+	}
+
+	colorize := colorizeOutput(args.Flag.HasReceived("--color"), args.Flag.Value("--color"))
+	ui.Print(ciRangeReport(commits, states, colorize))
+
+	os.Exit(ciExitCode(ciWorstState(states)))
+}
+
+// ciRangeReport lists one commit per line with the marker of its state.
+func ciRangeReport(commits []git.RangeCommit, states []string, colorize bool) string {
+	report := ""
+	for i, commit := range commits {
+		marker, color := ciStateMarker(states[i])
+		if colorize && color != 0 {
+			marker = fmt.Sprintf("\033[%dm%s\033[0m", color, marker)
+		}
+		report += fmt.Sprintf("%s\t%s\t%s\n", marker, commit.Sha[:7], commit.Subject)
+	}
+	return report
+}
+
+// ciWorstState is the most severe of states, like ciState ranks them.
+func ciWorstState(states []string) string {
+	statuses := make([]github.CIStatus, len(states))
+	for i, state := range states {
+		statuses[i].State = state
+	}
+	return ciState(statuses)
 }
 
 func stateRank(state string) uint32 {
@@ -203,3 +819,222 @@ func stateRank(state string) uint32 {
 		return 2
 	}
 }
+
+const ciBatchConcurrency = 8
+
+// ciBatchResult is the status of one repository listed for 'ci-status --batch'.
+type ciBatchResult struct {
+	Repo    string `json:"repo"`
+	Ref     string `json:"ref"`
+	State   string `json:"state"`
+	Failing int    `json:"failing"`
+	Total   int    `json:"total"`
+	Error   string `json:"error,omitempty"`
+
+	host  string
+	owner string
+	name  string
+}
+
+// parseCIBatchLine parses a "[HOST/]OWNER/REPO[@REF]" line of the batch list.
+func parseCIBatchLine(line string) (*ciBatchResult, error) {
+	result := &ciBatchResult{Repo: line}
+	spec := line
+	if i := strings.LastIndex(spec, "@"); i >= 0 {
+		result.Ref = spec[i+1:]
+		spec = spec[:i]
+		result.Repo = spec
+	}
+
+	parts := strings.Split(spec, "/")
+	switch len(parts) {
+	case 2:
+		result.host = github.DefaultGitHubHost()
+		result.owner, result.name = parts[0], parts[1]
+	case 3:
+		result.host, result.owner, result.name = parts[0], parts[1], parts[2]
+	default:
+		return result, fmt.Errorf("invalid repository: %q", line)
+	}
+	if result.owner == "" || result.name == "" || result.host == "" || strings.HasSuffix(line, "@") {
+		return result, fmt.Errorf("invalid repository: %q", line)
+	}
+	result.name = strings.TrimSuffix(result.name, ".git")
+
+	return result, nil
+}
+
+func readCIBatchList(args *Args) ([]*ciBatchResult, error) {
+	var content []byte
+	var err error
+	file := args.Flag.Value("--file")
+	if file == "" || file == "-" {
+		content, err = ioutil.ReadAll(os.Stdin)
+	} else {
+		content, err = ioutil.ReadFile(file)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	results := []*ciBatchResult{}
+	for _, line := range strings.Split(string(content), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		result, err := parseCIBatchLine(line)
+		if err != nil {
+			result.Error = err.Error()
+		}
+		results = append(results, result)
+	}
+
+	return results, nil
+}
+
+func (result *ciBatchResult) fetch(gh *github.Client) {
+	project := github.NewProject(result.owner, result.name, result.host)
+
+	if result.Ref == "" {
+		repo, err := gh.Repository(project)
+		if err != nil {
+			result.Error = err.Error()
+			return
+		}
+		result.Ref = repo.DefaultBranch
+	}
+
+	response, err := gh.FetchCIStatus(project, result.Ref)
+	if err != nil {
+		result.Error = err.Error()
+		return
+	}
+
+	result.State = ciState(response.Statuses)
+	result.Total = len(response.Statuses)
+	for _, status := range response.Statuses {
+		if ciExitCode(status.State) == utils.ExitError {
+			result.Failing++
+		}
+	}
+}
+
+func (result *ciBatchResult) exitCode() int {
+	if result.Error != "" {
+		return utils.ExitNotFound
+	}
+	return ciExitCode(result.State)
+}
+
+// ciBatchExitCode ranks exit codes from best to worst: a failure anywhere
+// outweighs an unknown status, which outweighs a pending one.
+func ciBatchExitCode(results []*ciBatchResult) int {
+	rank := map[int]int{
+		utils.ExitSuccess:  0,
+		utils.ExitPending:  1,
+		utils.ExitNotFound: 2,
+		utils.ExitError:    3,
+	}
+	worst := utils.ExitSuccess
+	for _, result := range results {
+		if code := result.exitCode(); rank[code] > rank[worst] {
+			worst = code
+		}
+	}
+	return worst
+}
+
+func ciStatusBatch(cmd *Command, args *Args) {
+	if !args.IsParamsEmpty() {
+		utils.Check(cmd.UsageError("a commit can't be given with '--batch'"))
+	}
+
+	results, err := readCIBatchList(args)
+	utils.Check(err)
+
+	if args.Noop {
+		ui.Printf("Would request CI status for %d repositories\n", len(results))
+		return
+	}
+
+	// set up one client per host up front, since looking up credentials might
+	// prompt for them
+	clients := map[string]*github.Client{}
+	for _, result := range results {
+		if result.Error != "" || clients[result.host] != nil {
+			continue
+		}
+		host, err := github.CurrentConfig().PromptForHost(result.host)
+		utils.Check(err)
+		clients[result.host] = github.NewClientWithHost(host)
+	}
+
+	queue := make(chan *ciBatchResult)
+	done := make(chan bool)
+	for i := 0; i < ciBatchConcurrency; i++ {
+		go func() {
+			for result := range queue {
+				result.fetch(clients[result.host])
+			}
+			done <- true
+		}()
+	}
+	for _, result := range results {
+		if result.Error == "" {
+			queue <- result
+		}
+	}
+	close(queue)
+	for i := 0; i < ciBatchConcurrency; i++ {
+		<-done
+	}
+
+	if args.Flag.Bool("--json") {
+		out, err := json.MarshalIndent(results, "", "  ")
+		utils.Check(err)
+		ui.Println(string(out))
+		os.Exit(ciBatchExitCode(results))
+	}
+
+	repoWidth, refWidth, stateWidth := 0, 0, 0
+	for _, result := range results {
+		if len(result.Repo) > repoWidth {
+			repoWidth = len(result.Repo)
+		}
+		if len(result.Ref) > refWidth {
+			refWidth = len(result.Ref)
+		}
+		if result.Error == "" && len(ciBatchState(result)) > stateWidth {
+			stateWidth = len(ciBatchState(result))
+		}
+	}
+
+	for _, result := range results {
+		if result.Error != "" {
+			message := strings.Replace(result.Error, "\n", " ", -1)
+			ui.Printf("%-*s  %-*s  %s\n", repoWidth, result.Repo, refWidth, result.Ref, message)
+		} else {
+			ui.Printf("%-*s  %-*s  %-*s  %d failing\n", repoWidth, result.Repo, refWidth, result.Ref, stateWidth, ciBatchState(result), result.Failing)
+		}
+	}
+
+	os.Exit(ciBatchExitCode(results))
+}
+
+func ciBatchState(result *ciBatchResult) string {
+	if result.State == "" {
+		return "no status"
+	}
+	return result.State
+}
+
+func notifyCIStatus(project *github.Project, ref, state string) {
+	if state == "" {
+		state = "no status"
+	}
+	message := fmt.Sprintf("%s@%s: %s", project, ref, state)
+	if err := utils.DefaultNotifier.Notify("hub ci-status", message); err != nil {
+		ui.Errorf("warning: could not show a notification: %s\n", err)
+	}
+}
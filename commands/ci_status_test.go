@@ -0,0 +1,160 @@
+package commands
+
+import (
+	"testing"
+	"time"
+
+	"github.com/bmizerany/assert"
+	"github.com/github/hub/git"
+	"github.com/github/hub/github"
+)
+
+func TestParseCIBatchLine(t *testing.T) {
+	result, err := parseCIBatchLine("github/hub")
+	assert.Equal(t, nil, err)
+	assert.Equal(t, github.DefaultGitHubHost(), result.host)
+	assert.Equal(t, "github/hub", result.Repo)
+	assert.Equal(t, "", result.Ref)
+
+	result, err = parseCIBatchLine("git.example.com/platform/api.git@release/2.0")
+	assert.Equal(t, nil, err)
+	assert.Equal(t, "git.example.com", result.host)
+	assert.Equal(t, "platform", result.owner)
+	assert.Equal(t, "api", result.name)
+	assert.Equal(t, "git.example.com/platform/api.git", result.Repo)
+	assert.Equal(t, "release/2.0", result.Ref)
+
+	for _, line := range []string{"hub", "github/", "github/hub@", "a/b/c/d"} {
+		_, err = parseCIBatchLine(line)
+		assert.NotEqual(t, nil, err)
+	}
+}
+
+func TestCIBatchExitCode(t *testing.T) {
+	results := func(states ...string) []*ciBatchResult {
+		list := []*ciBatchResult{}
+		for _, state := range states {
+			if state == "fetch error" {
+				list = append(list, &ciBatchResult{Error: "Not Found"})
+			} else {
+				list = append(list, &ciBatchResult{State: state})
+			}
+		}
+		return list
+	}
+
+	assert.Equal(t, 0, ciBatchExitCode(results("success", "neutral")))
+	assert.Equal(t, 2, ciBatchExitCode(results("success", "pending")))
+	assert.Equal(t, 3, ciBatchExitCode(results("pending", "fetch error", "success")))
+	assert.Equal(t, 3, ciBatchExitCode(results("pending", "")))
+	assert.Equal(t, 1, ciBatchExitCode(results("fetch error", "timed_out", "pending")))
+}
+
+func TestCIStatusJSON(t *testing.T) {
+	out, err := ciStatusJSON("", 3, nil)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, `{
+  "state": "",
+  "exit_code": 3,
+  "statuses": []
+}`, string(out))
+
+	out, err = ciStatusJSON("failure", 1, []github.CIStatus{
+		{State: "failure", Context: "test", TargetUrl: "https://ci.example.com/1"},
+	})
+	assert.Equal(t, nil, err)
+	assert.Equal(t, `{
+  "state": "failure",
+  "exit_code": 1,
+  "statuses": [
+    {
+      "state": "failure",
+      "context": "test",
+      "target_url": "https://ci.example.com/1"
+    }
+  ]
+}`, string(out))
+}
+
+func TestContextFilter(t *testing.T) {
+	statuses := []github.CIStatus{
+		{Context: "continuous-integration/travis-ci/push"},
+		{Context: "continuous-integration/travis-ci/pr"},
+		{Context: "Lint"},
+		{Context: "test (ubuntu)"},
+		{Context: "test (windows)"},
+	}
+	contexts := func(include, exclude []string) []string {
+		filter, err := parseContextFilter(include, exclude)
+		assert.Equal(t, nil, err)
+		names := []string{}
+		for _, status := range filter.filter(statuses) {
+			names = append(names, status.Context)
+		}
+		return names
+	}
+
+	assert.Equal(t, 5, len(contexts(nil, nil)))
+	assert.Equal(t, []string{"continuous-integration/travis-ci/push", "continuous-integration/travis-ci/pr"}, contexts([]string{"continuous-integration/*"}, nil))
+	assert.Equal(t, []string{"Lint", "test (windows)"}, contexts([]string{"lint", "TEST (WIN*)"}, nil))
+	assert.Equal(t, []string{"test (ubuntu)"}, contexts([]string{"test*", "/^lint$/"}, []string{"*windows*", "LINT"}))
+	assert.Equal(t, []string{"continuous-integration/travis-ci/pr"}, contexts(nil, []string{"/push$|^(lint|test)/"}))
+	assert.Equal(t, []string{"test (ubuntu)", "test (windows)"}, contexts([]string{"test ([uw]*)"}, nil))
+	assert.Equal(t, []string{}, contexts([]string{"build"}, nil))
+	assert.Equal(t, []string{}, contexts([]string{"lint"}, []string{"l?nt"}))
+
+	_, err := parseContextFilter([]string{"test["}, nil)
+	assert.Equal(t, `invalid context pattern "test[": syntax error in pattern`, err.Error())
+	_, err = parseContextFilter(nil, []string{"/(/"})
+	assert.NotEqual(t, nil, err)
+}
+
+func TestCIRequiredChecksFilter(t *testing.T) {
+	statuses := []github.CIStatus{
+		{State: "success", Context: "build"},
+		{State: "failure", Context: "coverage"},
+		{State: "success", Context: "lint"},
+	}
+
+	required := &ciRequiredChecks{contexts: []string{"lint", "deploy", "build"}}
+	assert.Equal(t, []github.CIStatus{
+		{State: "success", Context: "build"},
+		{State: "success", Context: "lint"},
+		{State: "pending", Context: "deploy"},
+	}, required.filter(statuses))
+	assert.Equal(t, "pending", ciState(required.filter(statuses)))
+
+	required = &ciRequiredChecks{contexts: []string{}}
+	assert.Equal(t, []github.CIStatus{}, required.filter(statuses))
+}
+
+func TestCIRangeReport(t *testing.T) {
+	commits := []git.RangeCommit{
+		{Sha: "1111111111111111111111111111111111111111", Subject: "Fix the build"},
+		{Sha: "2222222222222222222222222222222222222222", Subject: "Break the build"},
+		{Sha: "3333333333333333333333333333333333333333", Subject: "Add a README"},
+	}
+	states := []string{"pending", "failure", ""}
+
+	assert.Equal(t, "●\t1111111\tFix the build\n✖︎\t2222222\tBreak the build\n-\t3333333\tAdd a README\n", ciRangeReport(commits, states, false))
+	assert.Equal(t, "\033[33m●\033[0m\t1111111\tFix the build\n\033[31m✖︎\033[0m\t2222222\tBreak the build\n-\t3333333\tAdd a README\n", ciRangeReport(commits, states, true))
+}
+
+func TestCIWorstState(t *testing.T) {
+	assert.Equal(t, "", ciWorstState([]string{"", ""}))
+	assert.Equal(t, "success", ciWorstState([]string{"", "success", "neutral"}))
+	assert.Equal(t, "pending", ciWorstState([]string{"success", "pending", ""}))
+	assert.Equal(t, "error", ciWorstState([]string{"failure", "pending", "error", "success"}))
+}
+
+func TestCICheckDuration(t *testing.T) {
+	started := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	completed := started.Add(12*time.Minute + 33*time.Second)
+	now := started.Add(4*time.Minute + 500*time.Millisecond)
+
+	assert.Equal(t, "", ciCheckDuration(github.CIStatus{State: "success"}, now))
+	assert.Equal(t, "", ciCheckDuration(github.CIStatus{State: "success", StartedAt: &time.Time{}}, now))
+	assert.Equal(t, "12m33s", ciCheckDuration(github.CIStatus{State: "success", StartedAt: &started, CompletedAt: &completed}, now))
+	assert.Equal(t, "4m1s", ciCheckDuration(github.CIStatus{State: "pending", StartedAt: &started}, now))
+	assert.Equal(t, "", ciTimeAgo(nil))
+}
@@ -5,20 +5,32 @@ import (
 	"regexp"
 	"strings"
 
+	"github.com/github/hub/cmd"
+	"github.com/github/hub/git"
 	"github.com/github/hub/github"
+	"github.com/github/hub/ui"
 	"github.com/github/hub/utils"
 )
 
 var cmdClone = &Command{
 	Run:          clone,
 	GitExtension: true,
-	Usage:        "clone [-p] [<OPTIONS>] [<USER>/]<REPOSITORY> [<DESTINATION>]",
+	Usage:        "clone [-p] [--add-forks <USERS>] [--team-forks <ORG>/<TEAM>] [<OPTIONS>] [<USER>/]<REPOSITORY> [<DESTINATION>]",
 	Long: `Clone a repository from GitHub.
 
 ## Options:
 	-p
 		(Deprecated) Clone private repositories over SSH.
 
+	--add-forks <USERS>
+		After cloning, add a remote named after each of the users in the
+		comma-separated <USERS> list for their fork of the repository, and fetch
+		them all. Users without a fork are reported but don't fail the clone. A
+		remote name that is already taken gets a numeric suffix.
+
+	--team-forks <ORG>/<TEAM>
+		Like '--add-forks', but for the forks of all members of a team.
+
 	[<USER>/]<REPOSITORY>
 		<USER> defaults to your own GitHub username.
 
@@ -36,6 +48,12 @@ everything. See "HTTPS instead of git protocol" and "HUB_PROTOCOL" of hub(1).
 		$ hub clone rtomayko/ronn
 		> git clone git://github.com/rtomayko/ronn.git
 
+		$ hub clone --add-forks mislav,josh rtomayko/ronn
+		> git clone git://github.com/rtomayko/ronn.git
+		> git -C ronn remote add mislav git://github.com/mislav/ronn.git
+		> git -C ronn remote add josh git://github.com/josh/ronn.git
+		> git -C ronn fetch --multiple mislav josh
+
 ## See also:
 
 hub-fork(1), hub(1), git-clone(1)
@@ -47,12 +65,138 @@ func init() {
 }
 
 func clone(command *Command, args *Args) {
+	forkOwners, teams := parseCloneForksFlags(args)
+	for _, team := range teams {
+		if !strings.Contains(team, "/") {
+			utils.Check(command.UsageError(fmt.Sprintf("invalid team: %s", team)))
+		}
+	}
+
 	if !args.IsParamsEmpty() {
-		transformCloneArgs(args)
+		repository, destination := transformCloneArgs(args)
+		if len(forkOwners) == 0 && len(teams) == 0 {
+			return
+		}
+
+		var project *github.Project
+		if u, err := git.ParseURL(repository); err == nil {
+			project, _ = github.NewProjectFromURL(u)
+		}
+		if project == nil {
+			utils.Check(fmt.Errorf("Error: can't add forks of %s since it isn't a GitHub repository", repository))
+		}
+		if destination == "" {
+			destination = project.Name
+		}
+
+		args.AfterFn(func() error {
+			if args.Noop {
+				ui.Printf("Would add remotes for forks of %s\n", project)
+				return nil
+			}
+			return addForkRemotes(project, destination, forkOwners, teams)
+		})
+	}
+}
+
+// parseCloneForksFlags takes the options that pick forks to add as remotes
+// out of the arguments that are passed on to git.
+func parseCloneForksFlags(args *Args) (owners, teams []string) {
+	for i := 0; i < args.ParamsSize(); {
+		param := args.GetParam(i)
+		flag, value := param, ""
+		if eq := strings.IndexByte(param, '='); eq > 0 {
+			flag, value = param[:eq], param[eq+1:]
+		}
+		if flag != "--add-forks" && flag != "--team-forks" {
+			i++
+			continue
+		}
+
+		args.RemoveParam(i)
+		if flag == param && i < args.ParamsSize() {
+			value = args.RemoveParam(i)
+		}
+		for _, item := range strings.Split(value, ",") {
+			if item = strings.TrimSpace(item); item == "" {
+				continue
+			}
+			if flag == "--add-forks" {
+				owners = append(owners, item)
+			} else {
+				teams = append(teams, item)
+			}
+		}
+	}
+	return
+}
+
+// addForkRemotes adds a remote for the fork of project of each of owners and
+// of the members of teams to the repository in dir, then fetches them.
+func addForkRemotes(project *github.Project, dir string, owners, teams []string) error {
+	gh := github.NewClient(project.Host)
+	for _, team := range teams {
+		parts := strings.SplitN(team, "/", 2)
+		members, err := gh.FetchTeamMembers(parts[0], parts[1], github.MembersFilter{})
+		if err != nil {
+			return err
+		}
+		for _, member := range members {
+			owners = append(owners, member.Login)
+		}
+	}
+
+	forks, err := gh.FindForks(project, owners)
+	if err != nil {
+		return err
+	}
+
+	output, err := cmd.New("git").WithArgs("-C", dir, "remote").Output()
+	if err != nil {
+		return err
+	}
+	taken := map[string]bool{}
+	for _, name := range strings.Split(output, "\n") {
+		taken[name] = true
 	}
+
+	added := []string{}
+	seen := map[string]bool{}
+	for _, owner := range owners {
+		if seen[strings.ToLower(owner)] {
+			continue
+		}
+		seen[strings.ToLower(owner)] = true
+
+		fork := forks[strings.ToLower(owner)]
+		if fork == nil {
+			ui.Errorf("%s has no fork of %s\n", owner, project)
+			continue
+		}
+
+		name := fork.Owner.Login
+		for n := 2; taken[name]; n++ {
+			name = fmt.Sprintf("%s%d", fork.Owner.Login, n)
+		}
+		taken[name] = true
+
+		url := project.GitURL(fork.Name, fork.Owner.Login, false)
+		if err := git.Spawn("-C", dir, "remote", "add", name, url); err != nil {
+			return err
+		}
+		ui.Printf("new remote: %s\n", name)
+		added = append(added, name)
+	}
+
+	if len(added) == 0 {
+		return nil
+	}
+	return git.Spawn(append([]string{"-C", dir, "fetch", "--multiple"}, added...)...)
 }
 
-func transformCloneArgs(args *Args) {
+// transformCloneArgs expands the repository argument into a git URL and
+// returns it along with the destination directory, if one was given.
+func transformCloneArgs(args *Args) (repository, destination string) {
 	isSSH := parseClonePrivateFlag(args)
 
 	// git help clone | grep -e '^ \+-.\+<'
@@ -82,8 +226,13 @@ func transformCloneArgs(args *Args) {
 			url := getCloneUrl(a, isSSH, args.Command != "submodule")
 			args.ReplaceParam(i, url)
 		}
+		repository = args.Params[i]
 		break
 	}
+	if len(p.PositionalIndices) > 1 {
+		destination = args.Params[p.PositionalIndices[1]]
+	}
+	return
 }
 
 func parseClonePrivateFlag(args *Args) bool {
@@ -131,7 +280,7 @@ func getCloneUrl(nameWithOwner string, isSSH, allowSSH bool) string {
 	repo, err := gh.Repository(project)
 	if err != nil {
 		if strings.Contains(err.Error(), "HTTP 404") {
-			err = fmt.Errorf("Error: repository %s/%s doesn't exist", project.Owner, project.Name)
+			err = utils.WithExitStatus(fmt.Errorf("Error: repository %s/%s doesn't exist", project.Owner, project.Name), utils.ExitNotFound)
 		}
 		utils.Check(err)
 	}
@@ -0,0 +1,15 @@
+package commands
+
+import (
+	"testing"
+
+	"github.com/bmizerany/assert"
+)
+
+func TestParseCloneForksFlags(t *testing.T) {
+	args := NewArgs([]string{"clone", "--add-forks", "mislav,josh", "--depth", "1", "--team-forks=github/hubbers", "--add-forks=hubot", "github/hub"})
+	owners, teams := parseCloneForksFlags(args)
+	assert.Equal(t, []string{"mislav", "josh", "hubot"}, owners)
+	assert.Equal(t, []string{"github/hubbers"}, teams)
+	assert.Equal(t, []string{"--depth", "1", "github/hub"}, args.Params)
+}
@@ -23,6 +23,7 @@ type Command struct {
 	Usage        string
 	Long         string
 	KnownFlags   string
+	FlagValues   map[string]flagValue
 	GitExtension bool
 
 	subCommands   map[string]*Command
@@ -35,11 +36,15 @@ func (c *Command) Call(args *Args) (err error) {
 		return
 	}
 
-	if !c.GitExtension {
+	if !runCommand.GitExtension {
 		err = runCommand.parseArguments(args)
 		if err != nil {
 			return
 		}
+		err = runCommand.validateFlags(args)
+		if err != nil {
+			return
+		}
 	}
 
 	runCommand.Run(runCommand, args)
@@ -70,7 +75,7 @@ func (c *Command) parseArguments(args *Args) error {
 		args.Terminator = args.Flag.HasTerminated
 		return nil
 	} else {
-		return fmt.Errorf("%s\n%s", err, c.Synopsis())
+		return utils.WithExitStatus(fmt.Errorf("%s\n%s", err, c.Synopsis()), utils.ExitUsage)
 	}
 }
 
@@ -87,7 +92,7 @@ func (c *Command) UsageError(msg string) error {
 	if msg != "" {
 		nl = "\n"
 	}
-	return fmt.Errorf("%s%s%s", msg, nl, c.Synopsis())
+	return utils.WithExitStatus(fmt.Errorf("%s%s%s", msg, nl, c.Synopsis()), utils.ExitUsage)
 }
 
 func (c *Command) Synopsis() string {
@@ -151,8 +156,11 @@ func (c *Command) lookupSubCommand(args *Args) (runCommand *Command, err error)
 		if subCommand, ok := c.subCommands[subCommandName]; ok {
 			runCommand = subCommand
 			args.Params = args.Params[1:]
+		} else if c.GitExtension {
+			// anything else is left for the git command of the same name
+			runCommand = c
 		} else {
-			err = fmt.Errorf("error: Unknown subcommand: %s", subCommandName)
+			err = utils.WithExitStatus(fmt.Errorf("error: Unknown subcommand: %s", subCommandName), utils.ExitUsage)
 		}
 	} else {
 		runCommand = c
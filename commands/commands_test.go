@@ -8,6 +8,7 @@ import (
 
 	"github.com/bmizerany/assert"
 	"github.com/github/hub/ui"
+	"github.com/github/hub/utils"
 )
 
 func TestMain(m *testing.M) {
@@ -51,6 +52,20 @@ func TestCommandUseErrorWhenMissingSubcommand(t *testing.T) {
 	assert.NotEqual(t, nil, err)
 }
 
+func TestGitExtensionUseSelfWhenUnknownSubcommand(t *testing.T) {
+	c := &Command{Usage: "foo", GitExtension: true}
+	s := &Command{Usage: "bar"}
+	c.Use(s)
+
+	args := NewArgs([]string{"foo", "baz"})
+
+	run, err := c.lookupSubCommand(args)
+
+	assert.Equal(t, nil, err)
+	assert.Equal(t, c, run)
+	assert.Equal(t, []string{"baz"}, args.Params)
+}
+
 func TestArgsForCommand(t *testing.T) {
 	c := &Command{Usage: "foo"}
 
@@ -84,6 +99,27 @@ func TestFlagsAfterArguments(t *testing.T) {
 	assert.Equal(t, "bar", args.LastParam())
 }
 
+func TestPrSubcommandShortFlags(t *testing.T) {
+	args := NewArgs([]string{"list", "-f", "%I"})
+	assert.Equal(t, nil, cmdListPulls.parseArguments(args))
+	assert.Equal(t, "%I", args.Flag.Value("--format"))
+
+	args = NewArgs([]string{"checkout", "--force", "12"})
+	assert.Equal(t, nil, cmdCheckoutPr.parseArguments(args))
+	assert.T(t, args.Flag.Bool("--force"))
+
+	args = NewArgs([]string{"checkout", "-f", "12"})
+	assert.NotEqual(t, nil, cmdCheckoutPr.parseArguments(args))
+
+	args = NewArgs([]string{"list", "-o", "updated"})
+	assert.Equal(t, nil, cmdListPulls.parseArguments(args))
+	assert.Equal(t, "updated", args.Flag.Value("--sort"))
+
+	args = NewArgs([]string{"find", "-o", "abc1234"})
+	assert.Equal(t, nil, cmdFindPr.parseArguments(args))
+	assert.T(t, args.Flag.Bool("--browse"))
+}
+
 func TestCommandNameTakeKey(t *testing.T) {
 	c := &Command{Key: "bar", Usage: "foo -t -v --foo"}
 	assert.Equal(t, "bar", c.Name())
@@ -144,3 +180,26 @@ func Test_NameWithOwnerRe(t *testing.T) {
 	assert.Equal(t, false, re.MatchString("own_er/name"))
 	assert.Equal(t, false, re.MatchString("-owner/name"))
 }
+
+func TestCommandUsageErrorExitStatus(t *testing.T) {
+	c := &Command{Usage: "foo [-x]", Long: "## Options:\n\t-x\n\t\tX marks the spot."}
+
+	err := c.UsageError("the '-x' option is required")
+	assert.Equal(t, "the '-x' option is required\nUsage: hub foo [-x]", err.Error())
+	assert.Equal(t, utils.ExitUsage, utils.ExitStatus(err))
+
+	err = c.parseArguments(NewArgs([]string{"foo", "--bogus"}))
+	assert.Equal(t, utils.ExitUsage, utils.ExitStatus(err))
+
+	c.Use(&Command{Usage: "bar"})
+	_, err = c.lookupSubCommand(NewArgs([]string{"foo", "baz"}))
+	assert.Equal(t, utils.ExitUsage, utils.ExitStatus(err))
+}
+
+func TestExitCodesTable(t *testing.T) {
+	statuses := []int{}
+	for _, code := range exitCodes {
+		statuses = append(statuses, code.status)
+	}
+	assert.Equal(t, []int{0, 1, 2, 3, 4, 5, 130}, statuses)
+}
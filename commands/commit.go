@@ -0,0 +1,240 @@
+package commands
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/github/hub/github"
+	"github.com/github/hub/ui"
+	"github.com/github/hub/utils"
+)
+
+var (
+	cmdCommit = &Command{
+		Run:          commit,
+		GitExtension: true,
+		Usage: `
+commit comment [-m <MESSAGE>|-F <FILE>] [--path <PATH> [--line <LINE>]] [--idempotency-key <KEY>] <COMMIT>
+commit comments <COMMIT>
+`,
+		Long: `Comment on a commit on GitHub, or list comments on a commit.
+
+## Commands:
+
+	* _comment_:
+		Post a comment on <COMMIT>. Unless a message is given, a text editor
+		opens for writing the comment.
+
+	* _comments_:
+		List the comments on <COMMIT> with their author and position.
+
+Any other invocation is passed through to git-commit(1).
+
+## Options:
+
+	-m, --message <MESSAGE>
+		The text of the comment. Multiple '-m' values are joined by a blank line.
+
+	-F, --file <FILE>
+		Read the text of the comment from <FILE>. Pass "-" to read from standard
+		input instead.
+
+	--path <PATH>
+		Comment on the file at <PATH>. The file must be among those changed in
+		<COMMIT>.
+
+	--line <LINE>
+		Comment on the line number <LINE> of the file given with '--path'.
+
+	--idempotency-key <KEY>
+		Record the URL of the new comment under <KEY>. When the command is repeated
+		with the same <KEY> within 24 hours after succeeding, the recorded URL is
+		printed again instead of posting another comment.
+
+	<COMMIT>
+		A commit SHA, a branch or other git revision, or the URL of a commit on
+		GitHub.
+
+## Examples:
+		$ hub commit comment -m "Nice catch!" --path app.rb --line 12 HEAD
+
+		$ hub commit comments https://github.com/github/hub/commit/f0e1a2b
+
+## See also:
+
+hub(1), git-commit(1)
+`,
+	}
+
+	cmdCommitComment = &Command{
+		Key:   "comment",
+		Run:   createCommitComment,
+		Usage: "commit comment [-m <MESSAGE>|-F <FILE>] [--path <PATH> [--line <LINE>]] [--idempotency-key <KEY>] <COMMIT>",
+		KnownFlags: `
+		-m, --message MSG
+		-F, --file FILE
+		--path PATH
+		--line LINE
+		--idempotency-key KEY
+`,
+	}
+
+	cmdCommitComments = &Command{
+		Key:        "comments",
+		Run:        listCommitComments,
+		Usage:      "commit comments <COMMIT>",
+		KnownFlags: "\n",
+	}
+)
+
+func init() {
+	CmdRunner.Use(cmdCommit)
+}
+
+func commit(cmd *Command, args *Args) {
+	if args.IsParamsEmpty() {
+		return
+	}
+
+	var subCommand *Command
+	switch args.FirstParam() {
+	case cmdCommitComment.Key:
+		subCommand = cmdCommitComment
+	case cmdCommitComments.Key:
+		subCommand = cmdCommitComments
+	default:
+		return
+	}
+
+	args.RemoveParam(0)
+	utils.Check(subCommand.parseArguments(args))
+	subCommand.Run(subCommand, args)
+}
+
+func createCommitComment(cmd *Command, args *Args) {
+	if args.ParamsSize() != 1 {
+		utils.Check(cmd.UsageError(""))
+	}
+
+	localRepo, err := github.LocalRepo()
+	utils.Check(err)
+
+	project, err := localRepo.MainProject()
+	utils.Check(err)
+
+	project, sha, err := parseCommitReference(project, args.GetParam(0))
+	utils.Check(err)
+
+	path := args.Flag.Value("--path")
+	line := args.Flag.Int("--line")
+	if args.Flag.HasReceived("--line") && path == "" {
+		utils.Check(cmd.UsageError("the '--line' option requires '--path'"))
+	}
+
+	var body string
+	if messages := args.Flag.AllValues("--message"); len(messages) > 0 {
+		body = strings.Join(messages, "\n\n")
+	} else if args.Flag.HasReceived("--file") {
+		body, err = msgFromFile(args.Flag.Value("--file"))
+		utils.Check(err)
+	} else {
+		editor, err := github.NewEditor("COMMIT_COMMENT_EDITMSG", "comment", "")
+		utils.Check(err)
+		editor.AddCommentedSection(fmt.Sprintf("Commenting on commit %s in %s", sha, project))
+		body, err = editor.EditContent()
+		utils.Check(err)
+		defer editor.DeleteFile()
+	}
+
+	body = strings.TrimSpace(body)
+	if body == "" {
+		utils.Check(fmt.Errorf("Aborting due to empty comment"))
+	}
+
+	params := map[string]interface{}{
+		"body": body,
+	}
+
+	gh := github.NewClient(project.Host)
+	if path != "" {
+		params["path"] = path
+		if line > 0 {
+			params["line"] = line
+		}
+
+		if !args.Noop {
+			commit, err := gh.FetchCommit(project, sha)
+			utils.Check(err)
+
+			found := false
+			for _, file := range commit.Files {
+				if file.Filename == path {
+					found = true
+					break
+				}
+			}
+			if !found {
+				utils.Check(fmt.Errorf("Aborted: '%s' is not among the files changed in %s", path, sha))
+			}
+		}
+	}
+
+	args.NoForward()
+	if args.Noop {
+		ui.Printf("Would comment on commit %s in %s\n", sha, project)
+	} else {
+		commentURL, _, err := performIdempotently(args, project.Host, func() (string, bool, error) {
+			comment, err := gh.CreateCommitComment(project, sha, params)
+			if err != nil {
+				return "", false, err
+			}
+			return comment.HtmlUrl, true, nil
+		})
+		utils.Check(err)
+		ui.Println(commentURL)
+	}
+}
+
+func listCommitComments(cmd *Command, args *Args) {
+	if args.ParamsSize() != 1 {
+		utils.Check(cmd.UsageError(""))
+	}
+
+	localRepo, err := github.LocalRepo()
+	utils.Check(err)
+
+	project, err := localRepo.MainProject()
+	utils.Check(err)
+
+	project, sha, err := parseCommitReference(project, args.GetParam(0))
+	utils.Check(err)
+
+	args.NoForward()
+	if args.Noop {
+		ui.Printf("Would request comments on commit %s in %s\n", sha, project)
+		return
+	}
+
+	gh := github.NewClient(project.Host)
+	comments, err := gh.FetchCommitComments(project, sha)
+	utils.Check(err)
+
+	for i, comment := range comments {
+		if i > 0 {
+			ui.Println()
+		}
+
+		position := ""
+		if comment.Path != "" {
+			position = " on " + comment.Path
+			if comment.Line > 0 {
+				position = fmt.Sprintf("%s:%d", position, comment.Line)
+			}
+		}
+		ui.Printf("%s commented%s (%s):\n", comment.User.Login, position, utils.TimeAgo(comment.CreatedAt))
+
+		for _, line := range strings.Split(comment.Body, "\n") {
+			ui.Printf("    %s\n", strings.TrimRight(line, "\r"))
+		}
+	}
+}
@@ -1,12 +1,14 @@
 package commands
 
 import (
+	"encoding/json"
 	"fmt"
 	"net/url"
 	"regexp"
 	"strings"
 
 	"github.com/github/hub/github"
+	"github.com/github/hub/ui"
 	"github.com/github/hub/utils"
 )
 
@@ -15,6 +17,7 @@ var cmdCompare = &Command{
 	Usage: `
 compare [-uc] [<USER>] [[<START>...]<END>]
 compare [-uc] [-b <BASE>]
+compare --stat [--limit-files <N>] [--json] [-b <BASE>] [<USER>] [[<START>...]<END>]
 `,
 	Long: `Open a GitHub compare page in a web browser.
 
@@ -27,10 +30,27 @@ compare [-uc] [-b <BASE>]
 
 	-b, --base <BASE>
 		Base branch to compare against in case no explicit arguments were given.
+		Defaults to the base branch remembered for the repository (see
+		hub-config(1)).
+
+	--stat
+		Instead of opening the compare page, print how many commits <END> is ahead
+		and behind of <START>, followed by the additions and deletions in each
+		changed file. Without <START>, the default branch of the repository is
+		compared against.
+
+		GitHub lists at most 300 changed files per comparison.
+
+	--limit-files <N>
+		Only list the first <N> changed files with '--stat'.
+
+	--json
+		Print the result of '--stat' in JSON format.
 
 	[<START>...]<END>
 		Branch names, tag names, or commit SHAs specifying the range to compare.
-		<END> defaults to the current branch name.
+		<END> defaults to the current branch name. Either of them can be given as
+		"<OWNER>:<BRANCH>" to compare against a fork.
 
 		If a range with two dots ('A..B') is given, it will be transformed into a
 		range with three dots.
@@ -45,6 +65,8 @@ compare [-uc] [-b <BASE>]
 		$ hub compare -u jingweno feature
 		> echo https://github.com/jingweno/REPO/compare/feature
 
+		$ hub compare --stat master...jingweno:feature
+
 ## See also:
 
 hub-browse(1), hub(1)
@@ -68,17 +90,24 @@ func compare(command *Command, args *Args) {
 	flagCompareBase := args.Flag.Value("--base")
 
 	if args.IsParamsEmpty() {
+		checkCurrentBranch("give a <RANGE> to compare, such as \"master...topic\"")
 		branch, project, err = localRepo.RemoteBranchAndProject("", false)
 		utils.Check(err)
 
+		compareBase := flagCompareBase
+		if compareBase == "" {
+			if mainProject, err := localRepo.MainProject(); err == nil {
+				compareBase = github.LoadProjectDefaults(mainProject).Base
+			}
+		}
 		if branch == nil ||
-			(branch.IsMaster() && flagCompareBase == "") ||
-			(flagCompareBase == branch.ShortName()) {
+			(branch.IsMaster() && compareBase == "") ||
+			(compareBase == branch.ShortName()) {
 			utils.Check(command.UsageError(""))
 		} else {
 			r = branch.ShortName()
-			if flagCompareBase != "" {
-				r = parseCompareRange(flagCompareBase + "..." + r)
+			if compareBase != "" {
+				r = parseCompareRange(compareBase + "..." + r)
 			}
 		}
 	} else {
@@ -109,15 +138,125 @@ func compare(command *Command, args *Args) {
 		utils.Check(err)
 	}
 
+	args.NoForward()
+	if args.Flag.Bool("--stat") {
+		compareStat(args, project, r)
+		return
+	}
+
 	subpage := utils.ConcatPaths("compare", rangeQueryEscape(r))
 	url := project.WebURL("", "", subpage)
 
-	args.NoForward()
 	flagCompareURLOnly := args.Flag.Bool("--url")
 	flagCompareCopy := args.Flag.Bool("--copy")
 	printBrowseOrCopy(args, url, !flagCompareURLOnly && !flagCompareCopy, flagCompareCopy)
 }
 
+const compareMaxFiles = 300
+
+func compareStat(args *Args, project *github.Project, r string) {
+	gh := github.NewClient(project.Host)
+
+	if args.Noop {
+		ui.Printf("Would compare %s in %s\n", r, project)
+		return
+	}
+
+	// ranges that parseCompareRange couldn't normalize still need splitting
+	base, head := "", r
+	if refs := strings.SplitN(r, "...", 2); len(refs) == 2 {
+		base, head = refs[0], refs[1]
+	} else if refs := strings.SplitN(r, "..", 2); len(refs) == 2 {
+		base, head = refs[0], refs[1]
+	}
+
+	if base == "" {
+		base = github.LoadProjectDefaults(project).Base
+	}
+	if base == "" {
+		var err error
+		base, err = project.DefaultBranch()
+		utils.Check(err)
+	}
+
+	comparison, err := gh.FetchComparison(project, base, head)
+	utils.Check(err)
+
+	totalFiles := comparison.TotalFiles
+	if totalFiles < len(comparison.Files) {
+		totalFiles = len(comparison.Files)
+	}
+	files := comparison.Files
+	if limit := args.Flag.Int("--limit-files"); limit > 0 && limit < len(files) {
+		files = files[:limit]
+	}
+	truncated := len(files) < totalFiles || len(comparison.Files) >= compareMaxFiles
+
+	if args.Flag.Bool("--json") {
+		for i := range files {
+			files[i].Patch = ""
+		}
+		data, err := json.MarshalIndent(map[string]interface{}{
+			"base":          base,
+			"head":          head,
+			"status":        comparison.Status,
+			"ahead_by":      comparison.AheadBy,
+			"behind_by":     comparison.BehindBy,
+			"total_commits": comparison.TotalCommits,
+			"total_files":   totalFiles,
+			"files":         files,
+			"truncated":     truncated,
+		}, "", "  ")
+		utils.Check(err)
+		ui.Println(string(data))
+		return
+	}
+
+	ui.Printf("%s is %s ahead of and %s behind %s (%s)\n", head,
+		pluralize(comparison.AheadBy, "commit"), pluralize(comparison.BehindBy, "commit"),
+		base, comparison.Status)
+
+	statusWidth, nameWidth, additionsWidth := 0, 0, 0
+	names := make([]string, len(files))
+	for i, file := range files {
+		names[i] = file.Filename
+		if file.PreviousFilename != "" {
+			names[i] = file.PreviousFilename + " => " + file.Filename
+		}
+		if len(file.Status) > statusWidth {
+			statusWidth = len(file.Status)
+		}
+		if len(names[i]) > nameWidth {
+			nameWidth = len(names[i])
+		}
+		if w := len(fmt.Sprintf("+%d", file.Additions)); w > additionsWidth {
+			additionsWidth = w
+		}
+	}
+
+	additions, deletions := 0, 0
+	for i, file := range files {
+		ui.Printf("%-*s  %-*s  %*s  -%d\n", statusWidth, file.Status, nameWidth, names[i],
+			additionsWidth, fmt.Sprintf("+%d", file.Additions), file.Deletions)
+		additions += file.Additions
+		deletions += file.Deletions
+	}
+	ui.Printf("%s changed, %d additions(+), %d deletions(-)\n", pluralize(len(files), "file"), additions, deletions)
+
+	if len(files) < totalFiles {
+		ui.Errorf("warning: showing %d of %d changed files\n", len(files), totalFiles)
+	} else if len(comparison.Files) >= compareMaxFiles {
+		ui.Errorf("warning: GitHub lists at most %d changed files in a comparison\n", compareMaxFiles)
+	}
+}
+
+func pluralize(n int, noun string) string {
+	if n == 1 {
+		return "1 " + noun
+	}
+	return fmt.Sprintf("%d %ss", n, noun)
+}
+
 func parseCompareRange(r string) string {
 	shaOrTag := fmt.Sprintf("((?:%s:)?\\w(?:[\\w.-]*\\w)?)", OwnerRe)
 	shaOrTagRange := fmt.Sprintf("^%s\\.\\.%s$", shaOrTag, shaOrTag)
@@ -0,0 +1,244 @@
+package commands
+
+import (
+	"bytes"
+
+	"github.com/github/hub/github"
+	"github.com/github/hub/ui"
+	"github.com/github/hub/utils"
+)
+
+var (
+	cmdConfig = &Command{
+		Run:          config,
+		GitExtension: true,
+		Usage: `
+config export [--include-tokens]
+config import -F <FILE> [--merge|--replace]
+config set --project <NAME> <VALUE>
+config unset --project <NAME>
+`,
+		Long: `Export hub configuration to a file, or import it on another machine.
+
+## Commands:
+
+	* _export_:
+		Print all hub configuration as a single YAML document: the identities
+		stored for each host in the hub configuration file, and the "hub.*" and
+		"alias.*" variables of the global git configuration. Access tokens are
+		left out unless '--include-tokens' is given.
+
+	* _import_:
+		Read a document created by _export_, validate it, and store the
+		configuration that it contains. The hub configuration file is replaced
+		atomically, and hub refuses to write access tokens to it if other users
+		can read it.
+
+	* _set_ --project:
+		Remember a default for pull requests of the current repository. <NAME> is
+		"base" for the branch to open pull requests against instead of the default
+		branch of the repository, "reviewers" for a comma-separated list of users
+		or "<ORG>/<TEAM>" teams to request reviews from, or "labels" for a
+		comma-separated list of labels to add.
+
+		Defaults are stored in the global git configuration as
+		"hub.<OWNER>/<REPO>.<NAME>", and are used by hub-pull-request(1) and
+		hub-compare(1) unless overridden by their options.
+
+	* _unset_ --project:
+		Forget a default of the current repository.
+
+Any other arguments, including _set_ and _unset_ without '--project', are
+passed to git-config(1).
+
+## Options:
+
+	--include-tokens
+		Include access tokens in the exported document. Anyone who can read the
+		document can act as you on GitHub, so keep it private.
+
+	-F, --file <FILE>
+		Read the document to import from <FILE>. Use "-" to read from standard
+		input.
+
+	--merge
+		Add identities and git config variables from the document to the existing
+		configuration, overwriting the ones that exist in both (default).
+		Identities are matched by host and name.
+
+	--replace
+		Remove identities and "hub.*" and "alias.*" git config variables that
+		aren't part of the document.
+
+Identities that come without an access token in the document keep the access
+token that is already stored for them.
+
+## Examples:
+		$ hub config export > hub.yml
+		$ hub config import -F hub.yml
+
+		$ hub config export --include-tokens | ssh ci.example.com hub config import -F -
+
+		$ hub config set --project base develop
+
+## See also:
+
+hub-auth(1), hub-pull-request(1), hub(1), git-config(1)
+`,
+	}
+
+	cmdExportConfig = &Command{
+		Key: "export",
+		Run: exportConfig,
+		KnownFlags: `
+		--include-tokens
+`,
+	}
+
+	cmdImportConfig = &Command{
+		Key: "import",
+		Run: importConfig,
+		KnownFlags: `
+		-F, --file FILE
+		--merge
+		--replace
+`,
+	}
+
+	cmdSetConfig = &Command{
+		Key:          "set",
+		Run:          setConfig,
+		GitExtension: true,
+		KnownFlags: `
+		--project
+`,
+	}
+
+	cmdUnsetConfig = &Command{
+		Key:          "unset",
+		Run:          unsetConfig,
+		GitExtension: true,
+		KnownFlags: `
+		--project
+`,
+	}
+)
+
+func init() {
+	cmdConfig.Use(cmdExportConfig)
+	cmdConfig.Use(cmdImportConfig)
+	cmdConfig.Use(cmdSetConfig)
+	cmdConfig.Use(cmdUnsetConfig)
+	CmdRunner.Use(cmdConfig)
+}
+
+func config(cmd *Command, args *Args) {
+}
+
+func exportConfig(cmd *Command, args *Args) {
+	if !args.IsParamsEmpty() {
+		utils.Check(cmd.UsageError(""))
+	}
+	args.NoForward()
+
+	doc, err := github.CurrentConfigDocument()
+	utils.Check(err)
+
+	includeTokens := args.Flag.Bool("--include-tokens")
+	if includeTokens && doc.HasTokens() {
+		ui.Errorln("warning: the exported configuration includes access tokens; keep it private")
+	}
+
+	out := &bytes.Buffer{}
+	utils.Check(doc.Encode(out, includeTokens))
+	ui.Print(out.String())
+}
+
+func importConfig(cmd *Command, args *Args) {
+	if !args.IsParamsEmpty() {
+		utils.Check(cmd.UsageError(""))
+	}
+	if !args.Flag.HasReceived("--file") {
+		utils.Check(cmd.UsageError("the '--file' option is required"))
+	}
+	replace := args.Flag.Bool("--replace")
+	if replace && args.Flag.Bool("--merge") {
+		utils.Check(cmd.UsageError("'--merge' can't be combined with '--replace'"))
+	}
+	args.NoForward()
+
+	doc, err := github.ParseConfigDocument(readFile(args.Flag.Value("--file")))
+	utils.Check(err)
+
+	if args.Noop {
+		mode := "merge"
+		if replace {
+			mode = "replace"
+		}
+		ui.Printf("Would %s %d identities and %d git config variables into the configuration\n", mode, len(doc.Hosts), len(doc.GitConfig))
+		return
+	}
+
+	utils.Check(github.ImportConfigDocument(doc, replace))
+}
+
+// parseProjectConfigArgs parses the arguments of the "set" or "unset"
+// subcommand if they are about per-project defaults, which is when '--project'
+// is given. Otherwise, the subcommand is left for git-config(1), which offers
+// "set" and "unset" of its own, and the result is nil.
+func parseProjectConfigArgs(cmd *Command, args *Args) *github.Project {
+	isProject := false
+	for _, arg := range args.Params {
+		if arg == "--project" {
+			isProject = true
+		} else if arg == "--" {
+			break
+		}
+	}
+	if !isProject {
+		args.Params = append([]string{cmd.Name()}, args.Params...)
+		return nil
+	}
+	utils.Check(cmd.parseArguments(args))
+	args.NoForward()
+
+	localRepo, err := github.LocalRepo()
+	utils.Check(err)
+	project, err := localRepo.MainProject()
+	utils.Check(err)
+	return project
+}
+
+func setConfig(cmd *Command, args *Args) {
+	project := parseProjectConfigArgs(cmd, args)
+	if project == nil {
+		return
+	}
+	if args.ParamsSize() != 2 {
+		utils.Check(cmd.UsageError(""))
+	}
+	name, value := args.GetParam(0), args.GetParam(1)
+
+	if args.Noop {
+		ui.Printf("Would set %s to %q for %s\n", name, value, project)
+		return
+	}
+	utils.Check(github.SetProjectDefault(project, name, value))
+}
+
+func unsetConfig(cmd *Command, args *Args) {
+	project := parseProjectConfigArgs(cmd, args)
+	if project == nil {
+		return
+	}
+	if args.ParamsSize() != 1 {
+		utils.Check(cmd.UsageError(""))
+	}
+	name := args.GetParam(0)
+
+	if args.Noop {
+		ui.Printf("Would unset %s for %s\n", name, project)
+		return
+	}
+	utils.Check(github.UnsetProjectDefault(project, name))
+}
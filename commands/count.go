@@ -0,0 +1,180 @@
+package commands
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/github/hub/github"
+	"github.com/github/hub/ui"
+)
+
+// countIssues returns the number of issues matching the filters of the
+// `issue` command. Pull requests can only be told apart from issues by the
+// search API, so it's used unless those are included in the count. The
+// search API is also the only way to count with the filters of listingFilter.
+func countIssues(gh *github.Client, project *github.Project, filters map[string]interface{}, includePulls bool, filter *listingFilter) (int, error) {
+	if includePulls && filter.isEmpty() {
+		return gh.CountIssues(project, filters)
+	}
+
+	query, err := issueSearchQuery(gh, project, filters, includePulls, filter)
+	if err != nil {
+		return 0, err
+	}
+	return gh.CountSearchIssues(query)
+}
+
+// issueSearchQuery translates the filters of the `issue` command to a query
+// of the search API.
+func issueSearchQuery(gh *github.Client, project *github.Project, filters map[string]interface{}, includePulls bool, filter *listingFilter) (string, error) {
+	query := []string{"repo:" + project.String()}
+	if !includePulls {
+		query = append(query, "is:issue")
+	}
+	if state := searchState(filters); state != "" {
+		query = append(query, state)
+	}
+	if assignee, ok := filters["assignee"].(string); ok {
+		query = append(query, searchPresence("assignee", assignee))
+	}
+	if creator, ok := filters["creator"].(string); ok {
+		query = append(query, searchQualifier("author", creator))
+	}
+	if mentioned, ok := filters["mentioned"].(string); ok {
+		query = append(query, searchQualifier("mentions", mentioned))
+	}
+	if labels, ok := filters["labels"].(string); ok {
+		for _, label := range strings.Split(labels, ",") {
+			query = append(query, searchQualifier("label", label))
+		}
+	}
+	if milestone, ok := filters["milestone"].(string); ok {
+		// the search API filters milestones by title rather than by number
+		if _, err := strconv.Atoi(milestone); err == nil {
+			m, err := gh.FetchMilestone(project, milestone)
+			if err != nil {
+				return "", err
+			}
+			milestone = m.Title
+		}
+		query = append(query, searchPresence("milestone", milestone))
+	}
+	if since, ok := filters["since"].(string); ok {
+		query = append(query, "updated:>="+since)
+	}
+	query = append(query, filter.searchQualifiers()...)
+
+	return strings.Join(query, " "), nil
+}
+
+// countPullRequests returns the number of pull requests matching the filters
+// of `pr list`.
+func countPullRequests(gh *github.Client, project *github.Project, filters map[string]interface{}, onlyMerged bool, filter *listingFilter) (int, error) {
+	// the search API matches the head branch by name only, regardless of the
+	// repository it's in
+	if _, ok := filters["head"]; ok {
+		if !onlyMerged && filter.isEmpty() {
+			return gh.CountPullRequests(project, filters)
+		}
+		// there are few pull requests for a single head branch, so counting
+		// them one by one is cheap
+		pulls, err := gh.FetchPullRequests(project, filters, 0, func(pr *github.PullRequest) bool {
+			return !(onlyMerged && pr.MergedAt.IsZero()) && filter.matches((*github.Issue)(pr))
+		})
+		return len(pulls), err
+	}
+
+	return gh.CountSearchIssues(pullRequestSearchQuery(project, filters, onlyMerged, filter))
+}
+
+// pullRequestSearchQuery translates the filters of `pr list` to a query of
+// the search API. A head branch is matched by its name only.
+func pullRequestSearchQuery(project *github.Project, filters map[string]interface{}, onlyMerged bool, filter *listingFilter) string {
+	query := []string{"repo:" + project.String(), "is:pr"}
+	if onlyMerged {
+		query = append(query, "is:merged")
+	} else if state := searchState(filters); state != "" {
+		query = append(query, state)
+	}
+	if base, ok := filters["base"].(string); ok {
+		query = append(query, searchQualifier("base", base))
+	}
+	if head, ok := filters["head"].(string); ok {
+		query = append(query, searchQualifier("head", head[strings.Index(head, ":")+1:]))
+	}
+	query = append(query, filter.searchQualifiers()...)
+
+	return strings.Join(query, " ")
+}
+
+// searchSortQualifier orders search results by key, e.g. "sort:reactions-desc".
+func searchSortQualifier(key string, ascending bool) string {
+	if ascending {
+		return "sort:" + key + "-asc"
+	}
+	return "sort:" + key + "-desc"
+}
+
+// printListingTotal tells how many of the total matching items a listing that
+// was cut short by '--limit' shows. On a terminal, that's a dimmed footer such
+// as "Showing 25 of 312 open pull requests"; with a custom format or when the
+// output is piped, it's a note on standard error instead so that the output
+// stays parseable. The state is left out of the footer when it's empty.
+func printListingTotal(shown, total int, noun, state string, footer, colorize bool) {
+	if total <= shown {
+		return
+	}
+	if !footer {
+		ui.Errorf("showing %d of %d %s\n", shown, total, noun)
+		return
+	}
+	if state != "" {
+		noun = state + " " + noun
+	}
+	line := fmt.Sprintf("Showing %d of %d %s", shown, total, noun)
+	if colorize {
+		line = "\033[2m" + line + "\033[m"
+	}
+	ui.Println(line)
+}
+
+// listingState names the state that filters select, or is empty for all.
+func listingState(filters map[string]interface{}) string {
+	switch filters["state"] {
+	case "closed":
+		return "closed"
+	case "all":
+		return ""
+	}
+	return "open"
+}
+
+func searchState(filters map[string]interface{}) string {
+	switch filters["state"] {
+	case "closed":
+		return "is:closed"
+	case "all":
+		return ""
+	}
+	return "is:open"
+}
+
+// searchPresence translates the "none" and "*" values that the listing
+// endpoints accept for a filter.
+func searchPresence(name, value string) string {
+	switch value {
+	case "none":
+		return "no:" + name
+	case "*":
+		return "-no:" + name
+	}
+	return searchQualifier(name, value)
+}
+
+func searchQualifier(name, value string) string {
+	if strings.ContainsAny(value, " \t") {
+		value = strconv.Quote(value)
+	}
+	return name + ":" + value
+}
@@ -0,0 +1,412 @@
+package commands
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/github/hub/github"
+	"github.com/github/hub/ui"
+	"github.com/github/hub/utils"
+)
+
+var cmdDigest = &Command{
+	Run:   digest,
+	Usage: "digest [--since <DURATION>] [--org <ORG> [--limit-repos <N>]] [--output <FORMAT>]",
+	Long: `Summarize what happened in a repository over a period of time.
+
+The summary is made of these sections:
+
+	* Pull requests that were merged, along with their authors
+	* How many issues were opened and how many were closed
+	* New contributors, whose first pull request was merged in the period
+	* Releases that were published
+
+## Options:
+	--since <DURATION>
+		The period to summarize, counting back from now: a number of seconds or
+		a duration such as "36h" or "30d" (default: "7d").
+
+	--org <ORG>
+		Summarize the activity in all repositories of the organization <ORG>
+		that you can see, rather than in the current repository. Archived
+		repositories are left out.
+
+	--limit-repos <N>
+		With '--org', only include the <N> repositories that were pushed to most
+		recently (default: 30).
+
+	--output <FORMAT>
+		Print the summary as "text" (default) or as "markdown", e.g. for pasting
+		into an issue or a chat message.
+
+Summarizing a repository takes 3 requests to the search API, which only allows
+for 30 of them per minute. With '--org', hub warns upfront if the remaining
+rate limit falls short of what it will take. Whenever a rate limit runs out,
+hub pauses until it resets, which can be cut short with Ctrl-C.
+
+## Examples:
+		$ hub digest
+		$ hub digest --since 30d --output markdown
+		$ hub digest --org acme --limit-repos 10
+
+## See also:
+
+hub-todo(1), hub-pr(1), hub-release(1), hub(1)
+`,
+	FlagValues: map[string]flagValue{
+		"--since":       durationValue(),
+		"--limit-repos": intValue(1),
+		"--output":      enumValue("text", "markdown"),
+	},
+}
+
+func init() {
+	CmdRunner.Use(cmdDigest)
+}
+
+const (
+	// digestWorkers is how many repositories are summarized at the same time.
+	digestWorkers = 4
+	// digestSearchesPerRepo is how many search API requests summarizing a
+	// repository takes.
+	digestSearchesPerRepo = 3
+	// digestRequestsPerRepo is roughly how many core API requests summarizing
+	// a repository takes.
+	digestRequestsPerRepo    = 3
+	defaultDigestPeriod      = 7 * 24 * time.Hour
+	defaultDigestLimitRepos  = 30
+	digestMergedPullsPerRepo = 100
+)
+
+// digestRepo is the activity in a single repository.
+type digestRepo struct {
+	project         *github.Project
+	merged          []github.Issue
+	mergedTotal     int
+	opened          int
+	closed          int
+	newContributors []string
+	releases        []github.Release
+}
+
+type digestItem struct {
+	ref    string
+	title  string
+	author string
+	url    string
+	date   time.Time
+}
+
+// digestSummary is the activity across all summarized repositories.
+type digestSummary struct {
+	title           string
+	merged          []digestItem
+	mergedMore      int
+	opened          int
+	closed          int
+	newContributors []string
+	releases        []digestItem
+}
+
+func digest(cmd *Command, args *Args) {
+	if !args.IsParamsEmpty() {
+		utils.Check(cmd.UsageError(""))
+	}
+
+	period := defaultDigestPeriod
+	if args.Flag.HasReceived("--since") {
+		var err error
+		period, err = parseDurationValue(args.Flag.Value("--since"))
+		utils.Check(err)
+	}
+	since := time.Now().Add(-period)
+
+	org := args.Flag.Value("--org")
+	limitRepos := defaultDigestLimitRepos
+	if args.Flag.HasReceived("--limit-repos") {
+		if org == "" {
+			utils.Check(cmd.UsageError("'--limit-repos' requires '--org'"))
+		}
+		limitRepos = args.Flag.Int("--limit-repos")
+	}
+
+	var host, scope string
+	var projects []*github.Project
+	if org == "" {
+		localRepo, err := github.LocalRepo()
+		utils.Check(err)
+		project, err := localRepo.MainProject()
+		utils.Check(err)
+		host = project.Host
+		scope = project.String()
+		projects = []*github.Project{project}
+	} else {
+		host = authHost()
+		scope = org
+	}
+
+	args.NoForward()
+	if args.Noop {
+		ui.Printf("Would summarize the activity in %s since %s\n", scope, since.Format("2006-01-02 15:04"))
+		return
+	}
+
+	gh := github.NewClient(host)
+	gh.PauseOnRateLimit(waitForRateLimit)
+
+	if org != "" {
+		repos, err := gh.FetchOrgRepositories(org, limitRepos)
+		utils.Check(err)
+		for _, repo := range repos {
+			projects = append(projects, github.NewProject("", repo.FullName, host))
+		}
+		if core, search, err := gh.RateLimits(); err == nil {
+			for _, warning := range digestRateLimitWarnings(len(projects), core, search) {
+				ui.Errorln(warning)
+			}
+		}
+	}
+
+	repos, err := fetchDigest(gh, projects, since)
+	utils.Check(err)
+
+	title := fmt.Sprintf("Activity in %s since %s", scope, since.Format("2006-01-02"))
+	summary := summarizeDigest(title, repos, org != "")
+	if args.Flag.Value("--output") == "markdown" {
+		ui.Print(summary.markdown())
+	} else {
+		ui.Print(summary.text())
+	}
+}
+
+// digestRateLimitWarnings warns about the rate limits that are unlikely to
+// last for summarizing a number of repositories.
+func digestRateLimitWarnings(repos int, core, search *github.RateLimit) (warnings []string) {
+	check := func(name string, limit *github.RateLimit, needed int) {
+		if needed > limit.Remaining {
+			warnings = append(warnings, fmt.Sprintf("warning: summarizing %d repositories takes about %d requests to the %s, but only %d are left until %s; hub will pause until the rate limit resets",
+				repos, needed, name, limit.Remaining, limit.ResetAt().Format("15:04:05")))
+		}
+	}
+	check("search API", search, repos*digestSearchesPerRepo)
+	check("API", core, repos*digestRequestsPerRepo)
+	return
+}
+
+// fetchDigest looks up the activity in projects since the given time, several
+// repositories at a time.
+func fetchDigest(gh *github.Client, projects []*github.Project, since time.Time) ([]*digestRepo, error) {
+	type result struct {
+		index int
+		repo  *digestRepo
+		err   error
+	}
+
+	queue := make(chan int)
+	results := make(chan result)
+	for i := 0; i < digestWorkers; i++ {
+		go func() {
+			for index := range queue {
+				repo, err := fetchDigestRepo(gh, projects[index], since)
+				results <- result{index, repo, err}
+			}
+		}()
+	}
+	go func() {
+		for i := range projects {
+			queue <- i
+		}
+		close(queue)
+	}()
+
+	repos := make([]*digestRepo, len(projects))
+	var firstErr error
+	for range projects {
+		r := <-results
+		if r.err != nil && firstErr == nil {
+			firstErr = r.err
+		}
+		repos[r.index] = r.repo
+	}
+	return repos, firstErr
+}
+
+func fetchDigestRepo(gh *github.Client, project *github.Project, since time.Time) (*digestRepo, error) {
+	repo := &digestRepo{project: project}
+	scope := searchQualifier("repo", project.String())
+	date := since.UTC().Format(time.RFC3339)
+
+	var err error
+	repo.merged, repo.mergedTotal, err = gh.SearchIssues(nil, scope+" is:pr is:merged merged:>="+date, digestMergedPullsPerRepo)
+	if err != nil {
+		return nil, err
+	}
+	if repo.opened, err = gh.CountSearchIssues(scope + " is:issue created:>=" + date); err != nil {
+		return nil, err
+	}
+	if repo.closed, err = gh.CountSearchIssues(scope + " is:issue is:closed closed:>=" + date); err != nil {
+		return nil, err
+	}
+
+	// authors who had no commits before the period are new, no matter how
+	// many of their pull requests were merged since
+	seen := map[string]bool{}
+	for _, pr := range repo.merged {
+		if pr.User == nil || pr.User.Login == "" || strings.HasSuffix(pr.User.Login, "[bot]") || seen[pr.User.Login] {
+			continue
+		}
+		seen[pr.User.Login] = true
+		hasCommits, err := gh.HasCommitsBefore(project, pr.User.Login, since)
+		if err != nil {
+			return nil, err
+		}
+		if !hasCommits {
+			repo.newContributors = append(repo.newContributors, pr.User.Login)
+		}
+	}
+
+	if repo.releases, err = gh.FetchReleasesSince(project, since); err != nil {
+		return nil, err
+	}
+	return repo, nil
+}
+
+// summarizeDigest combines the activity in repos. References to pull requests
+// and releases include the repository if there are several.
+func summarizeDigest(title string, repos []*digestRepo, multiRepo bool) *digestSummary {
+	summary := &digestSummary{title: title}
+	for _, repo := range repos {
+		prefix := ""
+		if multiRepo {
+			prefix = repo.project.String()
+		}
+
+		for _, pr := range repo.merged {
+			item := digestItem{
+				ref:   fmt.Sprintf("%s#%d", prefix, pr.Number),
+				title: pr.Title,
+				url:   pr.HtmlUrl,
+			}
+			if pr.User != nil {
+				item.author = pr.User.Login
+			}
+			if pr.PullRequest != nil {
+				item.date = pr.PullRequest.MergedAt
+			}
+			summary.merged = append(summary.merged, item)
+		}
+		summary.mergedMore += repo.mergedTotal - len(repo.merged)
+		summary.opened += repo.opened
+		summary.closed += repo.closed
+
+		for _, login := range repo.newContributors {
+			if multiRepo {
+				summary.newContributors = append(summary.newContributors, fmt.Sprintf("@%s in %s", login, prefix))
+			} else {
+				summary.newContributors = append(summary.newContributors, "@"+login)
+			}
+		}
+
+		for _, release := range repo.releases {
+			ref := release.TagName
+			if multiRepo {
+				ref = fmt.Sprintf("%s %s", prefix, release.TagName)
+			}
+			summary.releases = append(summary.releases, digestItem{
+				ref:   ref,
+				title: release.Name,
+				url:   release.HtmlUrl,
+				date:  release.PublishedAt,
+			})
+		}
+	}
+
+	byDate := func(items []digestItem) {
+		sort.SliceStable(items, func(i, j int) bool {
+			return items[i].date.After(items[j].date)
+		})
+	}
+	byDate(summary.merged)
+	byDate(summary.releases)
+	sort.Strings(summary.newContributors)
+	return summary
+}
+
+func (s *digestSummary) text() string {
+	out := &bytes.Buffer{}
+	fmt.Fprintf(out, "%s\n\n", s.title)
+
+	fmt.Fprintf(out, "Merged pull requests: %d\n", len(s.merged)+s.mergedMore)
+	refWidth := 0
+	for _, item := range s.merged {
+		if len(item.ref) > refWidth {
+			refWidth = len(item.ref)
+		}
+	}
+	for _, item := range s.merged {
+		fmt.Fprintf(out, "  %-*s  %s (@%s)\n", refWidth, item.ref, item.title, item.author)
+	}
+	if s.mergedMore > 0 {
+		fmt.Fprintf(out, "  and %d more\n", s.mergedMore)
+	}
+
+	fmt.Fprintf(out, "Issues: %d opened, %d closed\n", s.opened, s.closed)
+
+	fmt.Fprintf(out, "New contributors: %d\n", len(s.newContributors))
+	for _, contributor := range s.newContributors {
+		fmt.Fprintf(out, "  %s\n", contributor)
+	}
+
+	fmt.Fprintf(out, "Releases: %d\n", len(s.releases))
+	for _, item := range s.releases {
+		if item.title != "" && item.title != item.ref {
+			fmt.Fprintf(out, "  %s  %s (%s)\n", item.ref, item.title, item.date.Format("2006-01-02"))
+		} else {
+			fmt.Fprintf(out, "  %s (%s)\n", item.ref, item.date.Format("2006-01-02"))
+		}
+	}
+	return out.String()
+}
+
+var markdownLinkTextEscaper = strings.NewReplacer(`\`, `\\`, "[", `\[`, "]", `\]`)
+
+func (s *digestSummary) markdown() string {
+	out := &bytes.Buffer{}
+	fmt.Fprintf(out, "## %s\n", s.title)
+
+	fmt.Fprintf(out, "\n### Merged pull requests (%d)\n\n", len(s.merged)+s.mergedMore)
+	for _, item := range s.merged {
+		fmt.Fprintf(out, "- [%s](%s) %s by @%s\n", item.ref, item.url, markdownLinkTextEscaper.Replace(item.title), item.author)
+	}
+	if s.mergedMore > 0 {
+		fmt.Fprintf(out, "- and %d more\n", s.mergedMore)
+	} else if len(s.merged) == 0 {
+		fmt.Fprintf(out, "None.\n")
+	}
+
+	fmt.Fprintf(out, "\n### Issues\n\n%d opened, %d closed\n", s.opened, s.closed)
+
+	fmt.Fprintf(out, "\n### New contributors (%d)\n\n", len(s.newContributors))
+	for _, contributor := range s.newContributors {
+		fmt.Fprintf(out, "- %s\n", contributor)
+	}
+	if len(s.newContributors) == 0 {
+		fmt.Fprintf(out, "None.\n")
+	}
+
+	fmt.Fprintf(out, "\n### Releases (%d)\n\n", len(s.releases))
+	for _, item := range s.releases {
+		line := fmt.Sprintf("- [%s](%s)", markdownLinkTextEscaper.Replace(item.ref), item.url)
+		if item.title != "" && item.title != item.ref {
+			line += " " + markdownLinkTextEscaper.Replace(item.title)
+		}
+		fmt.Fprintf(out, "%s, published %s\n", line, item.date.Format("2006-01-02"))
+	}
+	if len(s.releases) == 0 {
+		fmt.Fprintf(out, "None.\n")
+	}
+	return out.String()
+}
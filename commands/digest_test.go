@@ -0,0 +1,121 @@
+package commands
+
+import (
+	"testing"
+	"time"
+
+	"github.com/bmizerany/assert"
+	"github.com/github/hub/github"
+)
+
+func testDigestRepos() []*digestRepo {
+	day := func(d int) time.Time {
+		return time.Date(2024, 5, d, 12, 0, 0, 0, time.UTC)
+	}
+	return []*digestRepo{
+		{
+			project: &github.Project{Owner: "acme", Name: "tools"},
+			merged: []github.Issue{
+				{Number: 12, Title: "Fix [installer]", User: &github.User{Login: "mislav"}, HtmlUrl: "https://github.com/acme/tools/pull/12", PullRequest: &github.PullRequest{MergedAt: day(3)}},
+			},
+			mergedTotal:     1,
+			opened:          4,
+			closed:          2,
+			newContributors: []string{"mislav"},
+		},
+		{
+			project: &github.Project{Owner: "acme", Name: "widgets"},
+			merged: []github.Issue{
+				{Number: 7, Title: "Add gadgets", User: &github.User{Login: "hubot"}, HtmlUrl: "https://github.com/acme/widgets/pull/7", PullRequest: &github.PullRequest{MergedAt: day(5)}},
+			},
+			mergedTotal: 3,
+			opened:      1,
+			releases: []github.Release{
+				{TagName: "v1.2.0", Name: "Gadgets", HtmlUrl: "https://github.com/acme/widgets/releases/tag/v1.2.0", PublishedAt: day(6)},
+			},
+		},
+	}
+}
+
+func TestDigestSummary_Text(t *testing.T) {
+	summary := summarizeDigest("Activity in acme since 2024-05-01", testDigestRepos(), true)
+	assert.Equal(t, `Activity in acme since 2024-05-01
+
+Merged pull requests: 4
+  acme/widgets#7  Add gadgets (@hubot)
+  acme/tools#12   Fix [installer] (@mislav)
+  and 2 more
+Issues: 5 opened, 2 closed
+New contributors: 1
+  @mislav in acme/tools
+Releases: 1
+  acme/widgets v1.2.0  Gadgets (2024-05-06)
+`, summary.text())
+
+	repos := testDigestRepos()[:1]
+	summary = summarizeDigest("Activity in acme/tools since 2024-05-01", repos, false)
+	assert.Equal(t, `Activity in acme/tools since 2024-05-01
+
+Merged pull requests: 1
+  #12  Fix [installer] (@mislav)
+Issues: 4 opened, 2 closed
+New contributors: 1
+  @mislav
+Releases: 0
+`, summary.text())
+}
+
+func TestDigestSummary_Markdown(t *testing.T) {
+	summary := summarizeDigest("Activity in acme since 2024-05-01", testDigestRepos(), true)
+	assert.Equal(t, `## Activity in acme since 2024-05-01
+
+### Merged pull requests (4)
+
+- [acme/widgets#7](https://github.com/acme/widgets/pull/7) Add gadgets by @hubot
+- [acme/tools#12](https://github.com/acme/tools/pull/12) Fix \[installer\] by @mislav
+- and 2 more
+
+### Issues
+
+5 opened, 2 closed
+
+### New contributors (1)
+
+- @mislav in acme/tools
+
+### Releases (1)
+
+- [acme/widgets v1.2.0](https://github.com/acme/widgets/releases/tag/v1.2.0) Gadgets, published 2024-05-06
+`, summary.markdown())
+
+	summary = summarizeDigest("Activity in acme/empty since 2024-05-01", []*digestRepo{{project: &github.Project{Owner: "acme", Name: "empty"}}}, false)
+	assert.Equal(t, `## Activity in acme/empty since 2024-05-01
+
+### Merged pull requests (0)
+
+None.
+
+### Issues
+
+0 opened, 0 closed
+
+### New contributors (0)
+
+None.
+
+### Releases (0)
+
+None.
+`, summary.markdown())
+}
+
+func TestDigestRateLimitWarnings(t *testing.T) {
+	reset := time.Date(2024, 5, 1, 12, 30, 0, 0, time.Local)
+	core := &github.RateLimit{Limit: 5000, Remaining: 4000, Reset: reset.Unix()}
+	search := &github.RateLimit{Limit: 30, Remaining: 30, Reset: reset.Unix()}
+
+	assert.Equal(t, 0, len(digestRateLimitWarnings(10, core, search)))
+	assert.Equal(t, []string{
+		"warning: summarizing 20 repositories takes about 60 requests to the search API, but only 30 are left until 12:30:00; hub will pause until the rate limit resets",
+	}, digestRateLimitWarnings(20, core, search))
+}
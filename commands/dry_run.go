@@ -0,0 +1,149 @@
+package commands
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/github/hub/github"
+)
+
+// creationPreview is what '--dry-run' shows of an issue or pull request after
+// all of its values were resolved, instead of creating it.
+type creationPreview struct {
+	Repository string            `json:"repository"`
+	Base       string            `json:"base,omitempty"`
+	Head       string            `json:"head,omitempty"`
+	Issue      int               `json:"issue,omitempty"`
+	Title      string            `json:"title"`
+	Body       string            `json:"body"`
+	Draft      *bool             `json:"draft,omitempty"`
+	Labels     []string          `json:"labels"`
+	Assignees  []string          `json:"assignees"`
+	Milestone  *previewMilestone `json:"milestone"`
+	Reviewers  *previewReviewers `json:"reviewers,omitempty"`
+}
+
+type previewMilestone struct {
+	Number int    `json:"number"`
+	Title  string `json:"title"`
+}
+
+type previewReviewers struct {
+	Users []string `json:"users"`
+	Teams []string `json:"teams"`
+}
+
+// dryRunFormat returns the format of the '--dry-run[=<FORMAT>]' flag, or an
+// empty string if it wasn't given.
+func dryRunFormat(args *Args) string {
+	if !args.Flag.HasReceived("--dry-run") {
+		return ""
+	}
+	if format := args.Flag.Value("--dry-run"); format != "" {
+		return format
+	}
+	return "text"
+}
+
+// splitReviewers tells teams, given as "ORG/TEAM", apart from users.
+func splitReviewers(reviewers []string) *previewReviewers {
+	split := &previewReviewers{Users: []string{}, Teams: []string{}}
+	for _, reviewer := range reviewers {
+		if strings.Contains(reviewer, "/") {
+			split.Teams = append(split.Teams, reviewer)
+		} else {
+			split.Users = append(split.Users, reviewer)
+		}
+	}
+	return split
+}
+
+// lookupMilestone resolves the value of '--milestone', either a title or a
+// number, to the milestone with both.
+func lookupMilestone(client *github.Client, project *github.Project, value string) (*previewMilestone, error) {
+	milestones, err := client.FetchMilestones(project)
+	if err != nil {
+		return nil, err
+	}
+	return findPreviewMilestone(milestones, value)
+}
+
+// findPreviewMilestone picks the milestone the same way as creating an issue
+// or a pull request does: a number is taken as is, and a title is matched by
+// findMilestoneNumber.
+func findPreviewMilestone(milestones []github.Milestone, value string) (*previewMilestone, error) {
+	number, err := strconv.Atoi(value)
+	if err != nil {
+		if number, err = findMilestoneNumber(milestones, value); err != nil {
+			return nil, err
+		}
+	}
+	for _, milestone := range milestones {
+		if milestone.Number == number {
+			return &previewMilestone{Number: milestone.Number, Title: milestone.Title}, nil
+		}
+	}
+	// closed milestones aren't listed, but they can still be referred to by number
+	return &previewMilestone{Number: number}, nil
+}
+
+func (p *creationPreview) render(format string) (string, error) {
+	if p.Labels == nil {
+		p.Labels = []string{}
+	}
+	if p.Assignees == nil {
+		p.Assignees = []string{}
+	}
+	if format == "json" {
+		data, err := json.MarshalIndent(p, "", "  ")
+		return string(data) + "\n", err
+	}
+
+	list := func(values []string) string {
+		if len(values) == 0 {
+			return "(none)"
+		}
+		return strings.Join(values, ", ")
+	}
+
+	lines := []string{}
+	field := func(name, value string) {
+		lines = append(lines, fmt.Sprintf("%-11s %s", name+":", value))
+	}
+	field("Repository", p.Repository)
+	if p.Base != "" {
+		field("Base", p.Base)
+		field("Head", p.Head)
+	}
+	if p.Issue > 0 {
+		field("Issue", fmt.Sprintf("#%d", p.Issue))
+	}
+	if p.Draft != nil {
+		if *p.Draft {
+			field("Draft", "yes")
+		} else {
+			field("Draft", "no")
+		}
+	}
+	field("Title", p.Title)
+	field("Labels", list(p.Labels))
+	field("Assignees", list(p.Assignees))
+	if p.Milestone == nil {
+		field("Milestone", "(none)")
+	} else if p.Milestone.Title == "" {
+		field("Milestone", fmt.Sprintf("#%d", p.Milestone.Number))
+	} else {
+		field("Milestone", fmt.Sprintf("#%d %s", p.Milestone.Number, p.Milestone.Title))
+	}
+	if p.Reviewers != nil {
+		field("Reviewers", fmt.Sprintf("users: %s; teams: %s", list(p.Reviewers.Users), list(p.Reviewers.Teams)))
+	}
+
+	out := strings.Join(lines, "\n") + "\n"
+	if p.Body != "" {
+		out += "\n" + strings.TrimRight(p.Body, "\n") + "\n"
+	}
+	return out, nil
+}
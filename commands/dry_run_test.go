@@ -0,0 +1,103 @@
+package commands
+
+import (
+	"testing"
+
+	"github.com/bmizerany/assert"
+	"github.com/github/hub/github"
+)
+
+func TestSplitReviewers(t *testing.T) {
+	reviewers := splitReviewers([]string{"josh", "github/core", "mislav"})
+	assert.Equal(t, []string{"josh", "mislav"}, reviewers.Users)
+	assert.Equal(t, []string{"github/core"}, reviewers.Teams)
+}
+
+func TestFindPreviewMilestone(t *testing.T) {
+	milestones := []github.Milestone{
+		{Number: 1, Title: "Backlog"},
+		{Number: 2, Title: "backlog"},
+		{Number: 3, Title: "v1.0"},
+		{Number: 4, Title: "4"},
+	}
+
+	milestone, err := findPreviewMilestone(milestones, "backlog")
+	assert.Equal(t, nil, err)
+	assert.Equal(t, &previewMilestone{Number: 2, Title: "backlog"}, milestone)
+
+	milestone, err = findPreviewMilestone(milestones, "V1.0")
+	assert.Equal(t, nil, err)
+	assert.Equal(t, &previewMilestone{Number: 3, Title: "v1.0"}, milestone)
+
+	milestone, err = findPreviewMilestone(milestones, "3")
+	assert.Equal(t, nil, err)
+	assert.Equal(t, &previewMilestone{Number: 3, Title: "v1.0"}, milestone)
+
+	milestone, err = findPreviewMilestone(milestones, "12")
+	assert.Equal(t, nil, err)
+	assert.Equal(t, &previewMilestone{Number: 12}, milestone)
+
+	_, err = findPreviewMilestone(milestones, "BACKLOG")
+	assert.Equal(t, `error: milestone name 'BACKLOG' is ambiguous; use the number of one of: "Backlog" (1), "backlog" (2)`, err.Error())
+
+	_, err = findPreviewMilestone(milestones, "v2.0")
+	assert.Equal(t, "error: no milestone found with name 'v2.0'", err.Error())
+}
+
+func TestCreationPreview_Text(t *testing.T) {
+	draft := true
+	preview := &creationPreview{
+		Repository: "mislav/coral",
+		Base:       "mislav:main",
+		Head:       "hubot:feature",
+		Title:      "Add feature",
+		Body:       "Details\n\nSigned-off-by: Hubot <hubot@example.com>\n",
+		Draft:      &draft,
+		Labels:     []string{"bug", "docs"},
+		Milestone:  &previewMilestone{Number: 3, Title: "v1.0"},
+		Reviewers:  splitReviewers([]string{"github/core"}),
+	}
+
+	output, err := preview.render("text")
+	assert.Equal(t, nil, err)
+	assert.Equal(t, `Repository: mislav/coral
+Base:       mislav:main
+Head:       hubot:feature
+Draft:      yes
+Title:      Add feature
+Labels:     bug, docs
+Assignees:  (none)
+Milestone:  #3 v1.0
+Reviewers:  users: (none); teams: github/core
+
+Details
+
+Signed-off-by: Hubot <hubot@example.com>
+`, output)
+}
+
+func TestCreationPreview_JSON(t *testing.T) {
+	preview := &creationPreview{
+		Repository: "mislav/coral",
+		Title:      "Crash on startup",
+		Assignees:  []string{"mislav"},
+		Milestone:  &previewMilestone{Number: 12},
+	}
+
+	output, err := preview.render("json")
+	assert.Equal(t, nil, err)
+	assert.Equal(t, `{
+  "repository": "mislav/coral",
+  "title": "Crash on startup",
+  "body": "",
+  "labels": [],
+  "assignees": [
+    "mislav"
+  ],
+  "milestone": {
+    "number": 12,
+    "title": ""
+  }
+}
+`, output)
+}
@@ -0,0 +1,41 @@
+package commands
+
+import (
+	"github.com/github/hub/ui"
+	"github.com/github/hub/utils"
+)
+
+var cmdExitCodes = &Command{
+	Key:   "__exitcodes",
+	Run:   listExitCodes,
+	Usage: "__exitcodes",
+	Long:  "List the exit statuses that hub commands use.",
+}
+
+func init() {
+	CmdRunner.Use(cmdExitCodes)
+}
+
+type exitCode struct {
+	status      int
+	description string
+}
+
+// exitCodes documents the statuses that scripts can rely on. Keep this in sync
+// with the constants in the utils package and with hub(1).
+var exitCodes = []exitCode{
+	{utils.ExitSuccess, "success"},
+	{utils.ExitError, "failure, including API validation errors and failing CI checks"},
+	{utils.ExitPending, "pending, such as CI checks that haven't finished, or partial success"},
+	{utils.ExitNotFound, "not found, such as a missing repository or a commit without CI status"},
+	{utils.ExitAuth, "authentication failed, access was denied, or the API rate limit was exceeded"},
+	{utils.ExitUsage, "invalid command-line usage"},
+	{utils.ExitInterrupted, "interrupted with Ctrl-C"},
+}
+
+func listExitCodes(cmd *Command, args *Args) {
+	for _, code := range exitCodes {
+		ui.Printf("%3d  %s\n", code.status, code.description)
+	}
+	args.NoForward()
+}
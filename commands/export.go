@@ -0,0 +1,390 @@
+package commands
+
+import (
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"html"
+	"strconv"
+	"strings"
+
+	"github.com/github/hub/github"
+	"github.com/github/hub/utils"
+)
+
+var defaultExportColumns = []string{"number", "title", "assignee", "labels", "age"}
+
+// exportColumn is a column of an issue or pull request listing that was
+// exported to a table with '--output'.
+type exportColumn struct {
+	name   string
+	header string
+	value  func(issue github.Issue) string
+}
+
+var exportColumns = []exportColumn{
+	{"number", "#", func(issue github.Issue) string {
+		return "#" + strconv.Itoa(issue.Number)
+	}},
+	{"title", "Title", func(issue github.Issue) string {
+		return issue.Title
+	}},
+	{"state", "State", func(issue github.Issue) string {
+		if issue.Head != nil {
+			return pullRequestState(github.PullRequest(issue))
+		}
+		return issue.State
+	}},
+	{"author", "Author", func(issue github.Issue) string {
+		if issue.User == nil {
+			return ""
+		}
+		return issue.User.Login
+	}},
+	{"assignee", "Assignee", func(issue github.Issue) string {
+		logins := []string{}
+		for _, assignee := range issue.Assignees {
+			logins = append(logins, assignee.Login)
+		}
+		return strings.Join(logins, ", ")
+	}},
+	{"labels", "Labels", func(issue github.Issue) string {
+		names := []string{}
+		for _, label := range issue.Labels {
+			names = append(names, label.Name)
+		}
+		return strings.Join(names, ", ")
+	}},
+	{"milestone", "Milestone", func(issue github.Issue) string {
+		if issue.Milestone == nil {
+			return ""
+		}
+		return issue.Milestone.Title
+	}},
+	{"age", "Age", func(issue github.Issue) string {
+		if issue.CreatedAt.IsZero() {
+			return ""
+		}
+		return utils.TimeAgo(issue.CreatedAt)
+	}},
+	{"created", "Created", func(issue github.Issue) string {
+		if issue.CreatedAt.IsZero() {
+			return ""
+		}
+		return issue.CreatedAt.Format("2006-01-02")
+	}},
+	{"updated", "Updated", func(issue github.Issue) string {
+		if issue.UpdatedAt.IsZero() {
+			return ""
+		}
+		return issue.UpdatedAt.Format("2006-01-02")
+	}},
+	{"url", "URL", func(issue github.Issue) string {
+		return issue.HtmlUrl
+	}},
+}
+
+var defaultReleaseExportColumns = []string{"tag", "name", "published", "downloads"}
+
+// releaseExportColumn is a column of a release listing that was exported to a
+// table with '--output'.
+type releaseExportColumn struct {
+	name   string
+	header string
+	value  func(release github.Release) string
+}
+
+var releaseExportColumns = []releaseExportColumn{
+	{"tag", "Tag", func(release github.Release) string {
+		return release.TagName
+	}},
+	{"name", "Name", func(release github.Release) string {
+		return release.Name
+	}},
+	{"state", "State", func(release github.Release) string {
+		if release.Draft {
+			return "draft"
+		} else if release.Prerelease {
+			return "pre-release"
+		}
+		return "release"
+	}},
+	{"created", "Created", func(release github.Release) string {
+		if release.CreatedAt.IsZero() {
+			return ""
+		}
+		return release.CreatedAt.Format("2006-01-02")
+	}},
+	{"published", "Published", func(release github.Release) string {
+		if release.PublishedAt.IsZero() {
+			return ""
+		}
+		return release.PublishedAt.Format("2006-01-02")
+	}},
+	{"assets", "Assets", func(release github.Release) string {
+		names := []string{}
+		for _, asset := range release.Assets {
+			names = append(names, asset.Name)
+		}
+		return strings.Join(names, ", ")
+	}},
+	{"downloads", "Downloads", func(release github.Release) string {
+		return strconv.Itoa(releaseDownloads(release))
+	}},
+	{"url", "URL", func(release github.Release) string {
+		return release.HtmlUrl
+	}},
+}
+
+// listingExport renders a listing of issues or pull requests as a Markdown or
+// HTML table, e.g. for pasting into a wiki page.
+type listingExport struct {
+	output  string
+	columns []exportColumn
+}
+
+// parseExportFlags reads the '--output' and '--columns' flags that turn a
+// listing into a table. The output is empty unless '--output' was given.
+func parseExportFlags(cmd *Command, args *Args, exclusive, defaultColumns []string) (output string, columns []string, err error) {
+	if !args.Flag.HasReceived("--output") {
+		if args.Flag.HasReceived("--columns") {
+			err = cmd.UsageError("the '--columns' option requires '--output'")
+		}
+		return
+	}
+	for _, flag := range exclusive {
+		if args.Flag.HasReceived(flag) {
+			err = cmd.UsageError(fmt.Sprintf("the '--output' and '%s' options are mutually exclusive", flag))
+			return
+		}
+	}
+
+	output = args.Flag.Value("--output")
+	if output != "markdown" && output != "html" && output != "csv" {
+		err = fmt.Errorf("invalid output format: %q (expected \"markdown\", \"html\", or \"csv\")", output)
+		return
+	}
+
+	columns = defaultColumns
+	if args.Flag.HasReceived("--columns") {
+		columns = commaSeparated(args.Flag.AllValues("--columns"))
+	}
+	return
+}
+
+// parseListingExport reads the '--output' and '--columns' flags shared by
+// issue and pull request listings. It returns nil unless '--output' was given.
+func parseListingExport(cmd *Command, args *Args) (*listingExport, error) {
+	output, names, err := parseExportFlags(cmd, args, []string{"--format", "--watch", "--count-only"}, defaultExportColumns)
+	if output == "" || err != nil {
+		return nil, err
+	}
+	columns, err := lookupExportColumns(names)
+	if err != nil {
+		return nil, err
+	}
+
+	return &listingExport{output: output, columns: columns}, nil
+}
+
+func lookupExportColumns(names []string) ([]exportColumn, error) {
+	available := []string{}
+	for _, column := range exportColumns {
+		available = append(available, column.name)
+	}
+	indexes, err := matchExportColumns(names, available)
+	if err != nil {
+		return nil, err
+	}
+
+	columns := []exportColumn{}
+	for _, i := range indexes {
+		columns = append(columns, exportColumns[i])
+	}
+	return columns, nil
+}
+
+// matchExportColumns looks up the names given with '--columns' among the
+// available column names and returns their indexes.
+func matchExportColumns(names, available []string) ([]int, error) {
+	indexes := []int{}
+	for _, name := range names {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			continue
+		}
+		found := false
+		for i, column := range available {
+			if column == name {
+				indexes = append(indexes, i)
+				found = true
+				break
+			}
+		}
+		if !found {
+			return nil, fmt.Errorf("invalid column: %q\n(available columns: %s)", name, strings.Join(available, ", "))
+		}
+	}
+	if len(indexes) == 0 {
+		return nil, fmt.Errorf("no columns given for '--columns'")
+	}
+	return indexes, nil
+}
+
+// releaseExport renders a listing of releases as a table, like listingExport
+// does for issues.
+type releaseExport struct {
+	output  string
+	columns []releaseExportColumn
+}
+
+// parseReleaseExport reads the '--output' and '--columns' flags of the
+// release listing. It returns nil unless '--output' was given.
+func parseReleaseExport(cmd *Command, args *Args) (*releaseExport, error) {
+	output, names, err := parseExportFlags(cmd, args, []string{"--format"}, defaultReleaseExportColumns)
+	if output == "" || err != nil {
+		return nil, err
+	}
+
+	available := []string{}
+	for _, column := range releaseExportColumns {
+		available = append(available, column.name)
+	}
+	indexes, err := matchExportColumns(names, available)
+	if err != nil {
+		return nil, err
+	}
+
+	export := &releaseExport{output: output}
+	for _, i := range indexes {
+		export.columns = append(export.columns, releaseExportColumns[i])
+	}
+	return export, nil
+}
+
+func (e *releaseExport) render(releases []github.Release) string {
+	table := &exportTable{linked: -1}
+	for i, column := range e.columns {
+		table.headers = append(table.headers, column.header)
+		if column.name == "tag" {
+			table.linked = i
+		}
+	}
+	for _, release := range releases {
+		cells := []string{}
+		for _, column := range e.columns {
+			cells = append(cells, column.value(release))
+		}
+		table.rows = append(table.rows, cells)
+		table.urls = append(table.urls, release.HtmlUrl)
+	}
+	return table.render(e.output)
+}
+
+func (e *listingExport) render(issues []github.Issue) string {
+	table := &exportTable{linked: -1}
+	for i, column := range e.columns {
+		table.headers = append(table.headers, column.header)
+		if column.name == "number" {
+			table.linked = i
+		}
+	}
+	for _, issue := range issues {
+		cells := []string{}
+		for _, column := range e.columns {
+			cells = append(cells, column.value(issue))
+		}
+		table.rows = append(table.rows, cells)
+		table.urls = append(table.urls, issue.HtmlUrl)
+	}
+	return table.render(e.output)
+}
+
+// exportTable holds the cells of an exported listing. In Markdown and HTML,
+// the cells of the linked column link to the URL of their row.
+type exportTable struct {
+	headers []string
+	rows    [][]string
+	urls    []string
+	linked  int
+}
+
+func (t *exportTable) render(output string) string {
+	switch output {
+	case "html":
+		return t.renderHTML()
+	case "csv":
+		return t.renderCSV()
+	default:
+		return t.renderMarkdown()
+	}
+}
+
+func (t *exportTable) renderMarkdown() string {
+	out := &bytes.Buffer{}
+	separators := []string{}
+	for range t.headers {
+		separators = append(separators, "---")
+	}
+	fmt.Fprintf(out, "| %s |\n", strings.Join(t.headers, " | "))
+	fmt.Fprintf(out, "| %s |\n", strings.Join(separators, " | "))
+
+	for r, row := range t.rows {
+		cells := []string{}
+		for i, value := range row {
+			cell := escapeMarkdownCell(value)
+			if i == t.linked && t.urls[r] != "" {
+				cell = fmt.Sprintf("[%s](%s)", cell, t.urls[r])
+			}
+			cells = append(cells, cell)
+		}
+		fmt.Fprintf(out, "| %s |\n", strings.Join(cells, " | "))
+	}
+
+	return out.String()
+}
+
+func (t *exportTable) renderHTML() string {
+	out := &bytes.Buffer{}
+	out.WriteString("<table>\n<thead>\n<tr>")
+	for _, header := range t.headers {
+		fmt.Fprintf(out, "<th>%s</th>", html.EscapeString(header))
+	}
+	out.WriteString("</tr>\n</thead>\n<tbody>\n")
+
+	for r, row := range t.rows {
+		out.WriteString("<tr>")
+		for i, value := range row {
+			cell := html.EscapeString(value)
+			if i == t.linked && t.urls[r] != "" {
+				cell = fmt.Sprintf(`<a href="%s">%s</a>`, html.EscapeString(t.urls[r]), cell)
+			}
+			fmt.Fprintf(out, "<td>%s</td>", cell)
+		}
+		out.WriteString("</tr>\n")
+	}
+
+	out.WriteString("</tbody>\n</table>\n")
+	return out.String()
+}
+
+// renderCSV writes the table for spreadsheets, so cells aren't linked; the
+// "url" column can be included instead.
+func (t *exportTable) renderCSV() string {
+	out := &bytes.Buffer{}
+	w := csv.NewWriter(out)
+	w.Write(t.headers)
+	for _, row := range t.rows {
+		w.Write(row)
+	}
+	w.Flush()
+	return out.String()
+}
+
+// escapeMarkdownCell keeps text from breaking out of a Markdown table cell.
+func escapeMarkdownCell(text string) string {
+	text = strings.Replace(text, `\`, `\\`, -1)
+	text = strings.Replace(text, "|", `\|`, -1)
+	text = strings.Replace(text, "\r\n", " ", -1)
+	return strings.Replace(text, "\n", " ", -1)
+}
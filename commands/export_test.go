@@ -0,0 +1,85 @@
+package commands
+
+import (
+	"testing"
+	"time"
+
+	"github.com/bmizerany/assert"
+	"github.com/github/hub/github"
+)
+
+func TestEscapeMarkdownCell(t *testing.T) {
+	assert.Equal(t, `a \| b`, escapeMarkdownCell("a | b"))
+	assert.Equal(t, `C:\\dir \\\| x`, escapeMarkdownCell(`C:\dir \| x`))
+	assert.Equal(t, "one two three", escapeMarkdownCell("one\ntwo\r\nthree"))
+}
+
+func TestListingExport_Render(t *testing.T) {
+	columns, err := lookupExportColumns([]string{"number", " title", "labels"})
+	assert.Equal(t, nil, err)
+
+	issues := []github.Issue{
+		{
+			Number:  12,
+			Title:   "A | B",
+			HtmlUrl: "https://github.com/github/hub/issues/12",
+			Labels:  []github.IssueLabel{{Name: "bug"}, {Name: "help wanted"}},
+		},
+	}
+
+	export := &listingExport{output: "markdown", columns: columns}
+	assert.Equal(t, "| # | Title | Labels |\n"+
+		"| --- | --- | --- |\n"+
+		"| [#12](https://github.com/github/hub/issues/12) | A \\| B | bug, help wanted |\n", export.render(issues))
+
+	export.output = "html"
+	assert.Equal(t, "<table>\n<thead>\n<tr><th>#</th><th>Title</th><th>Labels</th></tr>\n</thead>\n<tbody>\n"+
+		"<tr><td><a href=\"https://github.com/github/hub/issues/12\">#12</a></td><td>A | B</td><td>bug, help wanted</td></tr>\n"+
+		"</tbody>\n</table>\n", export.render(issues))
+}
+
+func TestLookupExportColumns_Invalid(t *testing.T) {
+	_, err := lookupExportColumns([]string{"number", "priority"})
+	assert.NotEqual(t, nil, err)
+	assert.Equal(t, "invalid column: \"priority\"\n(available columns: number, title, state, author, assignee, labels, milestone, age, created, updated, url)", err.Error())
+
+	_, err = lookupExportColumns([]string{""})
+	assert.Equal(t, "no columns given for '--columns'", err.Error())
+}
+
+func TestListingExport_RenderCSV(t *testing.T) {
+	columns, err := lookupExportColumns([]string{"number", "title", "url"})
+	assert.Equal(t, nil, err)
+
+	issues := []github.Issue{
+		{Number: 12, Title: `Fix "quotes", commas`, HtmlUrl: "https://github.com/github/hub/issues/12"},
+	}
+
+	export := &listingExport{output: "csv", columns: columns}
+	assert.Equal(t, "#,Title,URL\n"+
+		"#12,\"Fix \"\"quotes\"\", commas\",https://github.com/github/hub/issues/12\n", export.render(issues))
+}
+
+func TestReleaseExport_Render(t *testing.T) {
+	releases := []github.Release{
+		{
+			TagName:     "v1.2.0",
+			Name:        "Version 1.2",
+			HtmlUrl:     "https://github.com/github/hub/releases/v1.2.0",
+			PublishedAt: time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC),
+			Assets:      []github.ReleaseAsset{{DownloadCount: 10}, {DownloadCount: 32}},
+		},
+	}
+
+	columns := []releaseExportColumn{}
+	indexes, err := matchExportColumns(defaultReleaseExportColumns, []string{"tag", "name", "state", "created", "published", "assets", "downloads", "url"})
+	assert.Equal(t, nil, err)
+	for _, i := range indexes {
+		columns = append(columns, releaseExportColumns[i])
+	}
+
+	export := &releaseExport{output: "markdown", columns: columns}
+	assert.Equal(t, "| Tag | Name | Published | Downloads |\n"+
+		"| --- | --- | --- | --- |\n"+
+		"| [v1.2.0](https://github.com/github/hub/releases/v1.2.0) | Version 1.2 | 2024-03-01 | 42 |\n", export.render(releases))
+}
@@ -0,0 +1,156 @@
+package commands
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// A flagValue describes what a flag accepts, so that invalid values can be
+// rejected before a command does any work.
+type flagValue struct {
+	expected string
+	valid    func(value string) bool
+}
+
+// enumValue accepts one of the given values.
+func enumValue(values ...string) flagValue {
+	return flagValue{
+		expected: strings.Join(values, ", "),
+		valid: func(value string) bool {
+			for _, v := range values {
+				if value == v {
+					return true
+				}
+			}
+			return false
+		},
+	}
+}
+
+// enumListValue accepts a comma-separated list of the given values.
+func enumListValue(values ...string) flagValue {
+	value := enumValue(values...)
+	return flagValue{
+		expected: "a comma-separated list of " + value.expected,
+		valid: func(list string) bool {
+			for _, item := range strings.Split(list, ",") {
+				if !value.valid(strings.TrimSpace(item)) {
+					return false
+				}
+			}
+			return true
+		},
+	}
+}
+
+// intValue accepts whole numbers no smaller than min.
+func intValue(min int) flagValue {
+	var expected string
+	switch min {
+	case 0:
+		expected = "a number of 0 or more"
+	case 1:
+		expected = "a positive number"
+	default:
+		expected = fmt.Sprintf("a number of at least %d", min)
+	}
+	return flagValue{
+		expected: expected,
+		valid: func(value string) bool {
+			n, err := strconv.Atoi(value)
+			return err == nil && n >= min
+		},
+	}
+}
+
+// durationValue accepts a positive number of seconds, or a duration with a
+// unit such as "90s", "5m", or "7d".
+func durationValue() flagValue {
+	return flagValue{
+		expected: `a number of seconds or a duration such as "5m"`,
+		valid: func(value string) bool {
+			_, err := parseDurationValue(value)
+			return err == nil
+		},
+	}
+}
+
+func parseDurationValue(value string) (time.Duration, error) {
+	d, err := time.ParseDuration(value)
+	if seconds, convErr := strconv.Atoi(value); convErr == nil {
+		d, err = time.Duration(seconds)*time.Second, nil
+	} else if strings.HasSuffix(value, "d") {
+		// time.ParseDuration has no unit longer than hours
+		if days, convErr := strconv.Atoi(strings.TrimSuffix(value, "d")); convErr == nil {
+			d, err = time.Duration(days)*24*time.Hour, nil
+		}
+	}
+	if err == nil && d <= 0 {
+		err = fmt.Errorf("duration must be positive: %s", value)
+	}
+	return d, err
+}
+
+// dateValue accepts dates in the "YYYY-MM-DD" format, optionally followed by
+// the time of day as in ISO 8601.
+func dateValue() flagValue {
+	return flagValue{
+		expected: `a date such as "2006-01-02" or "2006-01-02T15:04:05Z"`,
+		valid: func(value string) bool {
+			_, _, err := parseDateValue(value)
+			return err == nil
+		},
+	}
+}
+
+// parseDateValue parses a value accepted by dateValue and tells whether it
+// was only a date, without the time of day. Times without a time zone are
+// taken as UTC.
+func parseDateValue(value string) (t time.Time, dateOnly bool, err error) {
+	if t, err = time.Parse("2006-01-02", value); err == nil {
+		return t, true, nil
+	}
+	for _, layout := range []string{time.RFC3339, "2006-01-02T15:04:05"} {
+		if t, err = time.Parse(layout, value); err == nil {
+			return t, false, nil
+		}
+	}
+	return
+}
+
+// colorValue is for the '--color[=<WHEN>]' flag of listing commands.
+var colorValue = enumValue("always", "never", "auto")
+
+// limitValue is for the '-L, --limit' flag of listing commands, which list
+// everything for a limit of 0 or less.
+var limitValue = flagValue{
+	expected: "a whole number",
+	valid: func(value string) bool {
+		_, err := strconv.Atoi(value)
+		return err == nil
+	},
+}
+
+// validateFlags checks the values that were given for flags with a known set
+// of values. Flags that were given without a value, such as a bare '--color',
+// are left alone.
+func (c *Command) validateFlags(args *Args) error {
+	names := []string{}
+	for name := range c.FlagValues {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		flag := c.FlagValues[name]
+		for _, value := range args.Flag.AllValues(name) {
+			if value != "" && !flag.valid(value) {
+				return c.UsageError(fmt.Sprintf("invalid value '%s' for %s (expected: %s)", value, name, flag.expected))
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,136 @@
+package commands
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/bmizerany/assert"
+	"github.com/github/hub/utils"
+)
+
+func TestEnumValue(t *testing.T) {
+	value := enumValue("open", "closed", "all")
+
+	assert.Equal(t, "open, closed, all", value.expected)
+	assert.T(t, value.valid("open"))
+	assert.T(t, value.valid("all"))
+	assert.T(t, !value.valid("Open"))
+	assert.T(t, !value.valid("merged"))
+}
+
+func TestEnumListValue(t *testing.T) {
+	value := enumListValue("low", "high")
+
+	assert.Equal(t, "a comma-separated list of low, high", value.expected)
+	assert.T(t, value.valid("low"))
+	assert.T(t, value.valid("high, low"))
+	assert.T(t, !value.valid("low,,high"))
+	assert.T(t, !value.valid("low,urgent"))
+}
+
+func TestIntValue(t *testing.T) {
+	value := intValue(0)
+	assert.Equal(t, "a number of 0 or more", value.expected)
+	assert.T(t, value.valid("0"))
+	assert.T(t, value.valid("12"))
+	assert.T(t, !value.valid("-1"))
+	assert.T(t, !value.valid("1.5"))
+	assert.T(t, !value.valid("many"))
+
+	value = intValue(1)
+	assert.Equal(t, "a positive number", value.expected)
+	assert.T(t, !value.valid("0"))
+	assert.T(t, value.valid("1"))
+
+	value = intValue(3)
+	assert.Equal(t, "a number of at least 3", value.expected)
+	assert.T(t, !value.valid("2"))
+	assert.T(t, value.valid("3"))
+}
+
+func TestLimitValue(t *testing.T) {
+	assert.T(t, limitValue.valid("25"))
+	assert.T(t, limitValue.valid("0"))
+	assert.T(t, limitValue.valid("-1"))
+	assert.T(t, !limitValue.valid("1.5"))
+	assert.T(t, !limitValue.valid("all"))
+}
+
+func TestDurationValue(t *testing.T) {
+	value := durationValue()
+	assert.T(t, value.valid("30"))
+	assert.T(t, value.valid("5m"))
+	assert.T(t, value.valid("1m30s"))
+	assert.T(t, !value.valid("0"))
+	assert.T(t, !value.valid("-5m"))
+	assert.T(t, !value.valid("soon"))
+
+	d, err := parseDurationValue("90")
+	assert.Equal(t, nil, err)
+	assert.Equal(t, 90*time.Second, d)
+
+	d, err = parseDurationValue("5m")
+	assert.Equal(t, nil, err)
+	assert.Equal(t, 5*time.Minute, d)
+
+	d, err = parseDurationValue("7d")
+	assert.Equal(t, nil, err)
+	assert.Equal(t, 7*24*time.Hour, d)
+	assert.T(t, !value.valid("0d"))
+	assert.T(t, !value.valid("1.5d"))
+}
+
+func TestDateValue(t *testing.T) {
+	value := dateValue()
+	assert.T(t, value.valid("2020-02-29"))
+	assert.T(t, value.valid("2020-02-29T10:30:00Z"))
+	assert.T(t, value.valid("2020-02-29T10:30:00+02:00"))
+	assert.T(t, value.valid("2020-02-29T10:30:00"))
+	assert.T(t, !value.valid("2019-02-29"))
+	assert.T(t, !value.valid("29/02/2020"))
+	assert.T(t, !value.valid("yesterday"))
+
+	date, dateOnly, err := parseDateValue("2020-02-29")
+	assert.Equal(t, nil, err)
+	assert.T(t, dateOnly)
+	assert.Equal(t, time.Date(2020, 2, 29, 0, 0, 0, 0, time.UTC), date)
+
+	date, dateOnly, err = parseDateValue("2020-02-29T10:30:00")
+	assert.Equal(t, nil, err)
+	assert.T(t, !dateOnly)
+	assert.Equal(t, time.Date(2020, 2, 29, 10, 30, 0, 0, time.UTC), date)
+}
+
+func TestCommandValidateFlags(t *testing.T) {
+	ran := false
+	c := &Command{
+		Usage: "foo [-s <STATE>] [-L <LIMIT>]",
+		Run:   func(cmd *Command, args *Args) { ran = true },
+		KnownFlags: `
+		-s, --state STATE
+		-L, --limit N
+		--color
+`,
+		FlagValues: map[string]flagValue{
+			"--state": enumValue("open", "closed"),
+			"--limit": intValue(1),
+			"--color": colorValue,
+		},
+	}
+
+	err := c.Call(NewArgs([]string{"foo", "-s", "closed", "-L", "5", "--color"}))
+	assert.Equal(t, nil, err)
+	assert.T(t, ran)
+
+	ran = false
+	err = c.Call(NewArgs([]string{"foo", "-s", "merged", "-L", "0"}))
+	assert.NotEqual(t, nil, err)
+	assert.T(t, !ran)
+	assert.Equal(t, utils.ExitUsage, utils.ExitStatus(err))
+	assert.T(t, strings.HasPrefix(err.Error(), "invalid value '0' for --limit (expected: a positive number)\n"))
+
+	err = c.Call(NewArgs([]string{"foo", "--color=sometimes"}))
+	assert.NotEqual(t, nil, err)
+	assert.T(t, strings.HasPrefix(err.Error(), "invalid value 'sometimes' for --color (expected: always, never, auto)\n"))
+}
@@ -1,17 +1,40 @@
 package commands
 
 import (
+	"errors"
 	"fmt"
+	"regexp"
+	"strings"
+	"time"
 
+	"github.com/github/hub/git"
 	"github.com/github/hub/github"
 	"github.com/github/hub/ui"
 	"github.com/github/hub/utils"
 )
 
-var cmdFork = &Command{
-	Run:   fork,
-	Usage: "fork [--no-remote] [--remote-name <REMOTE>] [--org <ORGANIZATION>]",
-	Long: `Fork the current repository on GitHub and add a git remote for it.
+var (
+	cmdFork = &Command{
+		Run: fork,
+		Usage: `
+fork [--no-remote] [--remote-name <REMOTE>] [--org <ORGANIZATION>] [--no-wait]
+fork --clone [--org <ORGANIZATION>] [--no-wait] <OWNER>/<REPO> [<DESTINATION>]
+fork sync [--branch <BRANCH>] [--repo <REPO>] [--update-local]
+`,
+		Long: `Fork the current repository on GitHub and add a git remote for it.
+
+## Commands:
+
+With no arguments, fork the current repository and add a git remote for it.
+Since GitHub creates forks in the background, hub waits for up to a minute for
+a new fork to become available before adding the git remote and fetching it.
+
+	* _sync_:
+		Bring a branch of your existing fork up to date with the upstream
+		repository on GitHub, without fetching anything locally. The branch is
+		either fast-forwarded or, if it has diverged, merged with upstream. When
+		that's not possible due to merge conflicts, the steps to resolve them
+		locally are shown instead.
 
 ## Options:
 	--no-remote
@@ -23,6 +46,31 @@ var cmdFork = &Command{
 	--org <ORGANIZATION>
 		Fork the repository within this organization.
 
+	--no-wait
+		Don't wait for a new fork to become available on GitHub. Fetching from
+		the fork might fail until it does.
+
+	--clone
+		Fork the repository given as "<OWNER>/<REPO>" instead of the current one,
+		and clone the fork into <DESTINATION> (default: the name of the fork) with
+		an "upstream" git remote for the original repository. This works outside
+		of a git repository.
+
+	-b, --branch <BRANCH>
+		(sync only) The branch of the fork to sync (default: the default branch of
+		the fork).
+
+	--repo <REPO>
+		(sync only) The fork to sync, given as "<OWNER>/<REPO>" or just "<REPO>"
+		for a repository of the authenticated user. By default, the fork is the
+		one that a git remote points to among the repositories owned by the
+		authenticated user.
+
+	--update-local
+		(sync only) After syncing, fetch the branch from the git remote for the
+		fork and fast-forward the local branch of the same name. The currently
+		checked out branch is only updated if the working tree is clean.
+
 ## Examples:
 		$ hub fork
 		[ repo forked on GitHub ]
@@ -32,17 +80,44 @@ var cmdFork = &Command{
 		[ repo forked on GitHub into the ORGANIZATION organization]
 		> git remote add -f ORGANIZATION git@github.com:ORGANIZATION/REPO.git
 
+		$ hub fork --clone octocat/Spoon-Knife
+		[ repo forked on GitHub ]
+		> git clone git@github.com:USER/Spoon-Knife.git Spoon-Knife
+		> git -C Spoon-Knife remote add -f upstream git://github.com/octocat/Spoon-Knife.git
+
+		$ hub fork sync --update-local
+		Fast-forwarded USER/REPO:main to OWNER:main.
+		Updated branch main (was 1a2b3c4).
+
 ## See also:
 
-hub-clone(1), hub(1)
+hub-clone(1), hub-sync(1), hub(1)
 `,
-}
+	}
+
+	cmdForkSync = &Command{
+		Key:   "sync",
+		Run:   forkSync,
+		Usage: "fork sync [--branch <BRANCH>] [--repo <REPO>] [--update-local]",
+		KnownFlags: `
+		-b, --branch BRANCH
+		--repo REPO
+		--update-local
+`,
+	}
+)
 
 func init() {
+	cmdFork.Use(cmdForkSync)
 	CmdRunner.Use(cmdFork)
 }
 
 func fork(cmd *Command, args *Args) {
+	if args.Flag.Bool("--clone") {
+		forkAndClone(cmd, args)
+		return
+	}
+
 	localRepo, err := github.LocalRepo()
 	utils.Check(err)
 
@@ -72,6 +147,77 @@ func fork(cmd *Command, args *Args) {
 	}
 
 	client := github.NewClient(project.Host)
+	forkProject = createFork(client, project, forkProject, params, args)
+
+	args.NoForward()
+	if !args.Flag.Bool("--no-remote") {
+
+		originURL := originRemote.URL.String()
+		url := forkProject.GitURL("", "", true)
+
+		// a remote for the fork might exist under another name
+		if !args.Flag.HasReceived("--remote-name") {
+			if forkRemote, err := localRepo.ForkRemote(forkProject.Owner); err == nil {
+				if p, err := forkRemote.Project(); err == nil && p.SameAs(forkProject) {
+					ui.Printf("existing remote: %s\n", forkRemote.Name)
+					emitForkResult(forkProject, forkRemote.Name, "")
+					return
+				}
+			}
+		}
+
+		// Check to see if the remote already exists.
+		currentRemote, err := localRepo.RemoteByName(newRemoteName)
+		if err == nil {
+			currentProject, err := currentRemote.Project()
+			if err == nil {
+				if currentProject.SameAs(forkProject) {
+					ui.Printf("existing remote: %s\n", newRemoteName)
+					emitForkResult(forkProject, newRemoteName, "")
+					return
+				}
+				if newRemoteName == "origin" {
+					// Assume user wants to follow github guides for collaboration
+					ui.Printf("renaming existing \"origin\" remote to \"upstream\"\n")
+					args.Before("git", "remote", "rename", "origin", "upstream")
+				}
+			}
+		}
+
+		args.Before("git", "remote", "add", "-f", newRemoteName, originURL)
+		args.Before("git", "remote", "set-url", newRemoteName, url)
+
+		args.AfterFn(func() error {
+			ui.Printf("new remote: %s\n", newRemoteName)
+			emitForkResult(forkProject, newRemoteName, "")
+			return nil
+		})
+	} else {
+		emitForkResult(forkProject, "", "")
+	}
+}
+
+// emitForkResult reports the fork, the git remote for it, and the directory it
+// was cloned into, for '--porcelain'.
+func emitForkResult(forkProject *github.Project, remote, dir string) {
+	fields := ui.Fields{"command": "fork", "repo": forkProject.String(), "url": forkProject.WebURL("", "", "")}
+	if remote != "" {
+		fields["remote"] = remote
+	}
+	if dir != "" {
+		fields["dir"] = dir
+	}
+	ui.Emit(ui.EventResult, fields)
+}
+
+// forkTimeout is how long to wait for a new fork to become available.
+const forkTimeout = time.Minute
+
+// createFork forks project into forkProject unless that already is a fork of
+// it, and returns the fork, whose name GitHub may have changed. GitHub creates
+// forks in the background, so unless '--no-wait' was given, this waits for a
+// new fork to become available to git.
+func createFork(client *github.Client, project, forkProject *github.Project, params map[string]interface{}, args *Args) *github.Project {
 	existingRepo, err := client.Repository(forkProject)
 	if err == nil {
 		existingProject, err := github.NewProjectFromRepo(existingRepo)
@@ -95,38 +241,221 @@ func fork(cmd *Command, args *Args) {
 			utils.Check(err)
 			forkProject.Owner = newRepo.Owner.Login
 			forkProject.Name = newRepo.Name
+
+			if !args.Flag.Bool("--no-wait") {
+				_, err = client.WaitForRepository(forkProject, forkTimeout, func() {
+					ui.Errorf("Waiting for %s to become available...\n", forkProject)
+					ui.Emit(ui.EventForkWait, ui.Fields{"repo": forkProject.String()})
+				})
+				utils.Check(err)
+			}
 		}
 	}
+	return forkProject
+}
+
+// forkAndClone forks the repository given as an argument and clones the fork,
+// with an "upstream" remote for the original repository.
+func forkAndClone(cmd *Command, args *Args) {
+	if args.Flag.Bool("--no-remote") || args.Flag.HasReceived("--remote-name") {
+		utils.Check(cmd.UsageError("the '--clone' option can't be combined with '--no-remote' or '--remote-name'"))
+	}
+	if args.IsParamsEmpty() || args.ParamsSize() > 2 {
+		utils.Check(cmd.UsageError(""))
+	}
+	name := args.FirstParam()
+	if !strings.Contains(name, "/") || !regexp.MustCompile(NameWithOwnerRe).MatchString(name) {
+		utils.Check(cmd.UsageError("invalid repository: " + name))
+	}
+	split := strings.SplitN(name, "/", 2)
+	project := github.NewProject(split[0], split[1], authHost())
+
+	host, err := github.CurrentConfig().PromptForHost(project.Host)
+	utils.Check(github.FormatError("forking repository", err))
+	client := github.NewClientWithHost(host)
+
+	repo, err := client.Repository(project)
+	if err != nil && strings.Contains(err.Error(), "HTTP 404") {
+		err = utils.WithExitStatus(fmt.Errorf("Error: repository %s doesn't exist", project), utils.ExitNotFound)
+	}
+	utils.Check(err)
+	if canonical, err := github.NewProjectFromRepo(repo); err == nil {
+		project = canonical
+	}
+
+	params := map[string]interface{}{}
+	forkOwner := host.User
+	if flagForkOrganization := args.Flag.Value("--org"); flagForkOrganization != "" {
+		forkOwner = flagForkOrganization
+		params["organization"] = forkOwner
+	}
+	forkProject := github.NewProject(forkOwner, project.Name, project.Host)
+	forkProject = createFork(client, project, forkProject, params, args)
+
+	destination := forkProject.Name
+	if args.ParamsSize() > 1 {
+		destination = args.GetParam(1)
+	}
+	upstreamURL := project.GitURL("", "", repo.Private || repo.Permissions.Push)
 
 	args.NoForward()
-	if !args.Flag.Bool("--no-remote") {
+	args.Before("git", "clone", forkProject.GitURL("", "", true), destination)
+	args.Before("git", "-C", destination, "remote", "add", "-f", "upstream", upstreamURL)
+	args.AfterFn(func() error {
+		ui.Printf("new remote: upstream\n")
+		emitForkResult(forkProject, "origin", destination)
+		return nil
+	})
+}
 
-		originURL := originRemote.URL.String()
-		url := forkProject.GitURL("", "", true)
+func forkSync(cmd *Command, args *Args) {
+	args.NoForward()
 
-		// Check to see if the remote already exists.
-		currentRemote, err := localRepo.RemoteByName(newRemoteName)
-		if err == nil {
-			currentProject, err := currentRemote.Project()
-			if err == nil {
-				if currentProject.SameAs(forkProject) {
-					ui.Printf("existing remote: %s\n", newRemoteName)
-					return
-				}
-				if newRemoteName == "origin" {
-					// Assume user wants to follow github guides for collaboration
-					ui.Printf("renaming existing \"origin\" remote to \"upstream\"\n")
-					args.Before("git", "remote", "rename", "origin", "upstream")
-				}
+	localRepo, localRepoErr := github.LocalRepo()
+	var mainProject *github.Project
+	if localRepoErr == nil {
+		mainProject, localRepoErr = localRepo.MainProject()
+	}
+
+	flagRepo := args.Flag.Value("--repo")
+	if flagRepo == "" {
+		utils.Check(localRepoErr)
+	}
+
+	hostName := github.DefaultGitHubHost()
+	if mainProject != nil {
+		hostName = mainProject.Host
+	}
+	host, err := github.CurrentConfig().PromptForHost(hostName)
+	utils.Check(github.FormatError("syncing fork", err))
+
+	var forkProject *github.Project
+	var forkRemote *github.Remote
+	if flagRepo != "" {
+		owner, name := host.User, flagRepo
+		if parts := strings.SplitN(flagRepo, "/", 2); len(parts) == 2 {
+			owner, name = parts[0], parts[1]
+		}
+		forkProject = github.NewProject(owner, name, hostName)
+		if localRepoErr == nil {
+			forkRemote, _ = localRepo.RemoteForProject(forkProject)
+		}
+	} else if remote, err := localRepo.RemoteForOwner(host.User); err == nil {
+		forkRemote = remote
+		forkProject, err = remote.Project()
+		utils.Check(err)
+	} else {
+		forkProject = github.NewProject(host.User, mainProject.Name, hostName)
+	}
+
+	client := github.NewClientWithHost(host)
+	repo, err := client.Repository(forkProject)
+	utils.Check(err)
+	if repo.Parent == nil {
+		utils.Check(fmt.Errorf("Aborted: %s is not a fork", forkProject))
+	}
+	if project, err := github.NewProjectFromRepo(repo); err == nil {
+		forkProject = project
+	}
+	upstreamProject, err := github.NewProjectFromRepo(repo.Parent)
+	utils.Check(err)
+
+	branch := args.Flag.Value("--branch")
+	if branch == "" {
+		branch = repo.DefaultBranch
+	}
+	forkBranch := fmt.Sprintf("%s:%s", forkProject, branch)
+
+	if args.Noop {
+		ui.Printf("Would sync %s with %s\n", forkBranch, upstreamProject)
+		return
+	}
+
+	result, err := client.MergeUpstream(forkProject, branch)
+	utils.Check(err)
+
+	upstreamBranch := result.BaseBranch
+	if upstreamBranch == "" {
+		upstreamBranch = fmt.Sprintf("%s:%s", upstreamProject, branch)
+	}
+
+	switch result.MergeType {
+	case "conflict":
+		upstreamName := upstreamProject.GitURL("", "", true)
+		if localRepoErr == nil {
+			if remote, err := localRepo.RemoteForProject(upstreamProject); err == nil {
+				upstreamName = remote.Name
 			}
 		}
+		forkName := forkProject.GitURL("", "", true)
+		if forkRemote != nil {
+			forkName = forkRemote.Name
+		}
 
-		args.Before("git", "remote", "add", "-f", newRemoteName, originURL)
-		args.Before("git", "remote", "set-url", newRemoteName, url)
+		message := fmt.Sprintf("Aborted: %s could not be synced with %s because of merge conflicts.\n", forkBranch, upstreamBranch)
+		message += "To merge the upstream changes and resolve the conflicts locally, run:\n\n"
+		message += fmt.Sprintf("    git checkout %s\n", branch)
+		message += fmt.Sprintf("    git pull %s %s\n", upstreamName, branch)
+		message += "    [ resolve the conflicts and commit the merge ]\n"
+		message += fmt.Sprintf("    git push %s %s", forkName, branch)
+		utils.Check(errors.New(message))
+	case "fast-forward":
+		ui.Printf("Fast-forwarded %s to %s.\n", forkBranch, upstreamBranch)
+	case "merge":
+		ui.Printf("Merged %s into %s.\n", upstreamBranch, forkBranch)
+	default:
+		ui.Printf("%s is already up to date with %s.\n", forkBranch, upstreamBranch)
+	}
 
-		args.AfterFn(func() error {
-			ui.Printf("new remote: %s\n", newRemoteName)
-			return nil
-		})
+	if args.Flag.Bool("--update-local") {
+		if forkRemote == nil {
+			ui.Errorf("warning: not updating local branch `%s' because no git remote was found for %s\n", branch, forkProject)
+			return
+		}
+		updateLocalForkBranch(localRepo, forkRemote, branch)
+	}
+}
+
+// updateLocalForkBranch fast-forwards a local branch to the same-named branch
+// of the git remote for the fork, similar to what hub-sync(1) does.
+func updateLocalForkBranch(localRepo *github.GitHubRepo, remote *github.Remote, branch string) {
+	err := git.Spawn("fetch", "--quiet", remote.Name, branch)
+	utils.Check(err)
+
+	fullBranch := fmt.Sprintf("refs/heads/%s", branch)
+	remoteBranch := fmt.Sprintf("refs/remotes/%s/%s", remote.Name, branch)
+	if _, err := git.Ref(fullBranch); err != nil {
+		return
+	}
+
+	diff, err := git.NewRange(fullBranch, remoteBranch)
+	utils.Check(err)
+
+	if diff.IsIdentical() {
+		return
+	} else if !diff.IsAncestor() {
+		ui.Errorf("warning: `%s' seems to contain unpushed commits\n", branch)
+		return
+	}
+
+	currentBranch := ""
+	if curBranch, err := localRepo.CurrentBranch(); err == nil {
+		currentBranch = curBranch.ShortName()
+	}
+
+	if branch == currentBranch {
+		git.Quiet("update-index", "-q", "--refresh")
+		if !git.Quiet("diff-index", "--quiet", "HEAD", "--") {
+			ui.Errorf("warning: not updating `%s' because the working tree has uncommitted changes\n", branch)
+			return
+		}
+		if !git.Quiet("merge", "--ff-only", "--quiet", remoteBranch) {
+			ui.Errorf("warning: `%s' could not be fast-forwarded to %s/%s\n", branch, remote.Name, branch)
+			return
+		}
+	} else if !git.Quiet("update-ref", fullBranch, remoteBranch) {
+		ui.Errorf("warning: `%s' could not be updated to %s/%s\n", branch, remote.Name, branch)
+		return
 	}
+	ui.Printf("Updated branch %s (was %s).\n", branch, diff.A[0:7])
 }
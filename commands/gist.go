@@ -0,0 +1,248 @@
+package commands
+
+import (
+	"bufio"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/github/hub/github"
+	"github.com/github/hub/ui"
+	"github.com/github/hub/utils"
+)
+
+var (
+	cmdGist = &Command{
+		Run: gist,
+		Usage: `
+gist edit [--add <FILE>] [--remove <FILENAME>] <ID> [<FILENAME>]
+gist delete [-y] <ID>
+`,
+		Long: `Edit or delete a GitHub gist.
+
+## Commands:
+
+	* _edit_:
+		Open the file <FILENAME> of the gist in a text editor and save the changes
+		back to GitHub. <FILENAME> can be omitted for a gist that consists of a
+		single file, or when only adding or removing files.
+
+		All changes, including added and removed files, are saved in a single
+		update of the gist. Only gists that belong to the authenticated user can be
+		edited.
+
+	* _delete_:
+		Delete the gist after asking for confirmation.
+
+## Options:
+
+	--add <FILE>
+		Add the local file <FILE> to the gist, or replace the gist file with the
+		same name. This option can be repeated.
+
+	--remove <FILENAME>
+		Remove <FILENAME> from the gist. This option can be repeated.
+
+	-y, --yes
+		Skip the confirmation prompt and immediately delete the gist. This is
+		required when standard input isn't a terminal.
+
+	<ID>
+		The ID of the gist, as seen in its URL.
+
+## Examples:
+		$ hub gist edit 5d1b6f1a notes.md
+
+		$ hub gist edit --add screenshot.svg --remove draft.txt 5d1b6f1a
+
+		$ hub gist delete 5d1b6f1a
+
+## See also:
+
+hub(1)
+`,
+	}
+
+	cmdEditGist = &Command{
+		Key: "edit",
+		Run: editGist,
+		KnownFlags: `
+		--add FILE
+		--remove FILENAME
+`,
+	}
+
+	cmdDeleteGist = &Command{
+		Key: "delete",
+		Run: deleteGist,
+		KnownFlags: `
+		-y, --yes
+`,
+	}
+)
+
+func init() {
+	cmdGist.Use(cmdEditGist)
+	cmdGist.Use(cmdDeleteGist)
+	CmdRunner.Use(cmdGist)
+}
+
+func gist(cmd *Command, args *Args) {
+	utils.Check(cmd.UsageError(""))
+}
+
+// fetchOwnGist looks up a gist and makes sure that it belongs to the
+// authenticated user before it gets modified.
+func fetchOwnGist(gh *github.Client, id string) *github.Gist {
+	gist, err := gh.FetchGist(id)
+	utils.Check(err)
+
+	user, err := gh.CurrentUser()
+	utils.Check(err)
+
+	if gist.Owner == nil {
+		utils.Check(fmt.Errorf("Error: you don't own gist %s", id))
+	} else if !strings.EqualFold(gist.Owner.Login, user.Login) {
+		utils.Check(fmt.Errorf("Error: you don't own gist %s; it belongs to %s", id, gist.Owner.Login))
+	}
+	return gist
+}
+
+func gistFilenames(gist *github.Gist) []string {
+	names := []string{}
+	for name := range gist.Files {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func editGist(cmd *Command, args *Args) {
+	if args.ParamsSize() < 1 || args.ParamsSize() > 2 {
+		utils.Check(cmd.UsageError(""))
+	}
+	id := args.GetParam(0)
+	filename := ""
+	if args.ParamsSize() > 1 {
+		filename = args.GetParam(1)
+	}
+	added := args.Flag.AllValues("--add")
+	removed := args.Flag.AllValues("--remove")
+
+	gh := github.NewClient(authHost())
+	gist := fetchOwnGist(gh, id)
+
+	files := map[string]*string{}
+	for _, name := range removed {
+		if _, ok := gist.Files[name]; !ok {
+			utils.Check(fmt.Errorf("Error: gist %s has no file '%s'", id, name))
+		}
+		files[name] = nil
+	}
+	for _, path := range added {
+		content, err := ioutil.ReadFile(path)
+		utils.Check(err)
+		name := filepath.Base(path)
+		if _, ok := files[name]; ok {
+			utils.Check(cmd.UsageError(fmt.Sprintf("'%s' can't be both added and removed", name)))
+		}
+		text := string(content)
+		files[name] = &text
+	}
+
+	if filename == "" && len(files) == 0 {
+		names := gistFilenames(gist)
+		if len(names) != 1 {
+			utils.Check(fmt.Errorf("Error: gist %s has multiple files; specify one of: %s", id, strings.Join(names, ", ")))
+		}
+		filename = names[0]
+	}
+
+	if filename != "" {
+		file, ok := gist.Files[filename]
+		if !ok {
+			utils.Check(fmt.Errorf("Error: gist %s has no file '%s'", id, filename))
+		}
+		if _, ok := files[filename]; ok {
+			utils.Check(cmd.UsageError(fmt.Sprintf("'%s' can't be edited while also being added or removed", filename)))
+		}
+
+		original, err := gh.GistFileContent(file)
+		utils.Check(err)
+		content := editGistFile(filename, original)
+		if content == "" {
+			utils.Check(fmt.Errorf("Aborting due to empty %s", filename))
+		}
+		if content != original {
+			files[filename] = &content
+		}
+	}
+
+	args.NoForward()
+	if len(files) == 0 {
+		ui.Errorf("No changes made to gist %s\n", id)
+		return
+	}
+	if args.Noop {
+		ui.Printf("Would update gist %s\n", id)
+		return
+	}
+
+	gist, err := gh.EditGist(id, files)
+	utils.Check(err)
+	ui.Println(gist.HtmlUrl)
+}
+
+// editGistFile opens the content of a gist file in the text editor from a
+// temporary file of the same name, so that the editor can tell its type.
+func editGistFile(filename, content string) string {
+	dir, err := ioutil.TempDir("", "hub-gist-")
+	utils.Check(err)
+	defer os.RemoveAll(dir)
+
+	file := filepath.Join(dir, filepath.Base(filename))
+	utils.Check(ioutil.WriteFile(file, []byte(content), 0600))
+	utils.Check(github.EditFile(file))
+
+	edited, err := ioutil.ReadFile(file)
+	utils.Check(err)
+	return string(edited)
+}
+
+func deleteGist(cmd *Command, args *Args) {
+	if args.ParamsSize() != 1 {
+		utils.Check(cmd.UsageError(""))
+	}
+	id := args.GetParam(0)
+
+	gh := github.NewClient(authHost())
+	fetchOwnGist(gh, id)
+
+	args.NoForward()
+	if args.Noop {
+		ui.Printf("Would delete gist '%s'.\n", id)
+		return
+	}
+
+	if !args.Flag.Bool("--yes") {
+		if !ui.IsTerminal(os.Stdin) {
+			utils.Check(fmt.Errorf("Aborted: can't confirm deleting gist '%s' without a terminal\n(use `--yes` to delete it anyway)", id))
+		}
+		ui.Printf("Really delete gist '%s' (yes/N)? ", id)
+		answer := ""
+		scanner := bufio.NewScanner(os.Stdin)
+		if scanner.Scan() {
+			answer = strings.TrimSpace(scanner.Text())
+		}
+		utils.Check(scanner.Err())
+		if answer != "yes" {
+			utils.Check(fmt.Errorf("Please type 'yes' for confirmation."))
+		}
+	}
+
+	utils.Check(gh.DeleteGist(id))
+	ui.Printf("Deleted gist '%s'.\n", id)
+}
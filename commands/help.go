@@ -8,6 +8,7 @@ import (
 	"strings"
 
 	"github.com/github/hub/cmd"
+	"github.com/github/hub/git"
 	"github.com/github/hub/ui"
 	"github.com/github/hub/utils"
 )
@@ -60,6 +61,9 @@ func runHelp(helpCmd *Command, args *Args) {
 	if args.IsParamsEmpty() {
 		args.AfterFn(func() error {
 			ui.Println(helpText)
+			if plugins := discoverPlugins(); len(plugins) > 0 {
+				ui.Printf("These commands are provided by plugins:\n\n   %s\n", strings.Join(plugins, "\n   "))
+			}
 			return nil
 		})
 		return
@@ -87,6 +91,14 @@ func runHelp(helpCmd *Command, args *Args) {
 		}
 	}
 
+	if lookupCmd(command) == nil && !isBuiltInHubCommand(command) {
+		if plugin := findPlugin(command); plugin != "" && !git.IsBuiltInGitCommand(command) {
+			setPluginEnv()
+			args.Replace(plugin, "", "--help")
+			return
+		}
+	}
+
 	if c := lookupCmd(command); c != nil {
 		if !p.Bool("--plain-text") {
 			manPage := fmt.Sprintf("hub-%s.1", c.Name())
@@ -182,7 +194,7 @@ func lookupCmd(name string) *Command {
 func customCommands() []string {
 	cmds := []string{}
 	for n, c := range CmdRunner.All() {
-		if !c.GitExtension && !strings.HasPrefix(n, "--") {
+		if !c.GitExtension && !strings.HasPrefix(n, "--") && !strings.HasPrefix(n, "__") {
 			cmds = append(cmds, n)
 		}
 	}
@@ -196,15 +208,29 @@ var helpText = `
 These GitHub commands are provided by hub:
 
    api            Low-level GitHub API request interface
+   auth           List or switch between stored GitHub identities
    browse         Open a GitHub page in the default browser
    ci-status      Show the status of GitHub checks for a commit
    compare        Open a compare page on GitHub
    create         Create this repository on GitHub and add GitHub as origin
    delete         Delete a repository on GitHub
+   digest         Summarize the activity in a repository over a period
    fork           Make a fork of a remote repository on GitHub and add as remote
+   gist           Edit or delete a GitHub gist
    issue          List or create GitHub issues
+   org            Inspect the membership of a GitHub organization
    pr             List or checkout GitHub pull requests
    pull-request   Open a pull request on GitHub
    release        List or create GitHub releases
+   repo           Transfer or archive the GitHub repository
+   secret         Manage GitHub Actions secrets
+   security       List Dependabot alerts and security advisories
+   star           Star a repository or list starred repositories
    sync           Fetch git objects from upstream and update branches
+   team           Inspect the membership of an organization team
+   todo           Summarize issues and pull requests that need your attention
+   unstar         Remove the star from a repository
+   unwatch        Stop watching a repository
+   variable       Manage GitHub Actions variables
+   watch          Watch a repository to get notified of its activity
 `
@@ -1,18 +1,28 @@
 package commands
 
 import (
+	"fmt"
+	"io/ioutil"
+	"os"
 	"path/filepath"
 	"regexp"
+	"strconv"
 	"strings"
+	"time"
 
+	"github.com/github/hub/git"
 	"github.com/github/hub/github"
+	"github.com/github/hub/ui"
 	"github.com/github/hub/utils"
 )
 
 var cmdInit = &Command{
 	Run:          gitInit,
 	GitExtension: true,
-	Usage:        "init -g",
+	Usage: `
+init -g
+init [--gitignore <TEMPLATES>] [--license <LICENSE> [--name <HOLDER>]] [--force] [--create]
+`,
 	Long: `Initialize a git repository and add a remote pointing to GitHub.
 
 ## Options:
@@ -23,11 +33,32 @@ var cmdInit = &Command{
 		<USER> is your GitHub username, while <REPO> is the name of the current
 		working directory.
 
+	--gitignore <TEMPLATES>
+		Write a ".gitignore" file from the comma-separated list of gitignore
+		<TEMPLATES> available on GitHub, such as "Go" or "Node". Multiple templates
+		are concatenated under a header with their name.
+
+	--license <LICENSE>
+		Write a "LICENSE" file with the text of the license with the key <LICENSE>,
+		such as "mit" or "apache-2.0".
+
+	--name <HOLDER>
+		The copyright holder to fill into the license (default: the "user.name" git
+		setting).
+
+	--force
+		Replace existing ".gitignore" or "LICENSE" files.
+
+	--create
+		After initializing the repository, create it on GitHub using hub-create(1).
+
 ## Examples:
 		$ hub init -g
 		> git init
 		> git remote add origin git@github.com:USER/REPO.git
 
+		$ hub init --gitignore go,node --license mit --create
+
 ## See also:
 
 hub-create(1), hub(1), git-init(1)
@@ -44,7 +75,9 @@ func gitInit(command *Command, args *Args) {
 }
 
 func transformInitArgs(args *Args) error {
-	if !parseInitFlag(args) {
+	addRemote := parseInitFlag(args)
+	bootstrap := parseBootstrapFlags(args)
+	if !addRemote && bootstrap == nil {
 		return nil
 	}
 
@@ -69,6 +102,19 @@ func transformInitArgs(args *Args) error {
 		return err
 	}
 
+	if bootstrap != nil {
+		if err = bootstrap.prepare(dirToInit); err != nil {
+			return err
+		}
+		args.AfterFn(func() error {
+			return bootstrap.run(dirToInit, args)
+		})
+	}
+	// hub-create(1) adds the remote itself
+	if !addRemote || (bootstrap != nil && bootstrap.create) {
+		return nil
+	}
+
 	config := github.CurrentConfig()
 	host, err := config.DefaultHost()
 	if err != nil {
@@ -81,11 +127,11 @@ func transformInitArgs(args *Args) error {
 	project := github.NewProject(host.User, projectName, host.Host)
 	url := project.GitURL("", "", true)
 
-	addRemote := []string{
+	remoteAdd := []string{
 		"git", "--git-dir", filepath.Join(dirToInit, ".git"),
 		"remote", "add", "origin", url,
 	}
-	args.After(addRemote...)
+	args.After(remoteAdd...)
 
 	return nil
 }
@@ -98,3 +144,278 @@ func parseInitFlag(args *Args) bool {
 
 	return false
 }
+
+type initBootstrap struct {
+	gitignore []string
+	license   string
+	holder    string
+	force     bool
+	create    bool
+	files     []initFile
+}
+
+type initFile struct {
+	name    string
+	content string
+}
+
+// parseBootstrapFlags removes the flags for bootstrapping a new project from
+// the arguments that are passed on to git-init(1).
+func parseBootstrapFlags(args *Args) *initBootstrap {
+	b := &initBootstrap{}
+	found := false
+
+	if value, ok := removeInitFlagValue(args, "--gitignore"); ok {
+		for _, name := range strings.Split(value, ",") {
+			if name = strings.TrimSpace(name); name != "" {
+				b.gitignore = append(b.gitignore, name)
+			}
+		}
+		found = true
+	}
+	if value, ok := removeInitFlagValue(args, "--license"); ok {
+		b.license = value
+		found = true
+	}
+	if value, ok := removeInitFlagValue(args, "--name"); ok {
+		b.holder = value
+		found = true
+	}
+	if i := args.IndexOfParam("--force"); i != -1 {
+		args.RemoveParam(i)
+		b.force = true
+		found = true
+	}
+	if i := args.IndexOfParam("--create"); i != -1 {
+		args.RemoveParam(i)
+		b.create = true
+		found = true
+	}
+
+	if !found {
+		return nil
+	}
+	return b
+}
+
+func removeInitFlagValue(args *Args, name string) (string, bool) {
+	for i := 0; i < args.ParamsSize(); i++ {
+		param := args.Params[i]
+		if param == name && i+1 < args.ParamsSize() {
+			args.RemoveParam(i)
+			return args.RemoveParam(i), true
+		} else if strings.HasPrefix(param, name+"=") {
+			args.RemoveParam(i)
+			return strings.TrimPrefix(param, name+"="), true
+		}
+	}
+	return "", false
+}
+
+// prepare fetches the templates before anything is initialized, so that
+// unknown templates or existing files abort the command early. The templates
+// are public, so nobody is asked to log in just for them.
+func (b *initBootstrap) prepare(dir string) error {
+	hostName := github.DefaultGitHubHost()
+	gh := github.NewAnonymousClient(hostName)
+	if host := github.CurrentConfig().Find(hostName); host != nil {
+		gh = github.NewClientWithHost(host)
+	}
+
+	if len(b.gitignore) > 0 {
+		content, err := b.gitignoreContent(gh)
+		if err != nil {
+			return err
+		}
+		b.files = append(b.files, initFile{".gitignore", content})
+	}
+
+	if b.license != "" {
+		content, err := b.licenseContent(gh)
+		if err != nil {
+			return err
+		}
+		b.files = append(b.files, initFile{"LICENSE", content})
+	}
+
+	if b.force {
+		return nil
+	}
+	for _, file := range b.files {
+		if _, err := os.Stat(filepath.Join(dir, file.name)); err == nil {
+			return fmt.Errorf("Aborted: %s already exists in %s\n(use `--force` to replace it)", file.name, dir)
+		}
+	}
+	return nil
+}
+
+func (b *initBootstrap) gitignoreContent(gh *github.Client) (string, error) {
+	available, err := gh.FetchGitignoreTemplateNames()
+	if err != nil {
+		return "", err
+	}
+
+	sections := []string{}
+	for _, name := range b.gitignore {
+		match := ""
+		for _, candidate := range available {
+			if strings.EqualFold(candidate, name) {
+				match = candidate
+				break
+			}
+		}
+		if match == "" {
+			return "", unknownTemplateError("gitignore template", name, available)
+		}
+
+		template, err := gh.FetchGitignoreTemplate(match)
+		if err != nil {
+			return "", err
+		}
+
+		source := strings.TrimRight(template.Source, "\n") + "\n"
+		if len(b.gitignore) > 1 {
+			source = fmt.Sprintf("### %s ###\n%s", template.Name, source)
+		}
+		sections = append(sections, source)
+	}
+
+	return strings.Join(sections, "\n"), nil
+}
+
+func (b *initBootstrap) licenseContent(gh *github.Client) (string, error) {
+	license, err := gh.FetchLicense(strings.ToLower(b.license))
+	if err != nil {
+		return "", err
+	}
+	if license == nil {
+		licenses, err := gh.FetchLicenses()
+		if err != nil {
+			return "", err
+		}
+		keys := []string{}
+		for _, l := range licenses {
+			keys = append(keys, l.Key)
+		}
+		return "", unknownTemplateError("license", b.license, keys)
+	}
+
+	holder := b.holder
+	if holder == "" {
+		holder, _ = git.Config("user.name")
+	}
+	if holder == "" && strings.Contains(license.Body, "[fullname]") {
+		return "", fmt.Errorf("Aborted: could not determine the copyright holder for the license\n(use `--name` to specify it)")
+	}
+
+	body := strings.Replace(license.Body, "[year]", strconv.Itoa(time.Now().Year()), -1)
+	body = strings.Replace(body, "[fullname]", holder, -1)
+	return body, nil
+}
+
+func (b *initBootstrap) run(dir string, args *Args) error {
+	for _, file := range b.files {
+		if args.Noop {
+			ui.Printf("Would write %s\n", filepath.Join(dir, file.name))
+			continue
+		}
+		if err := ioutil.WriteFile(filepath.Join(dir, file.name), []byte(file.content), 0644); err != nil {
+			return err
+		}
+	}
+
+	if !b.create {
+		return nil
+	}
+	if err := os.Chdir(dir); err != nil {
+		return err
+	}
+	createArgs := initCreateArgs(args)
+	if args.Noop {
+		// git-init(1) didn't run, so there's no repository to create yet
+		ui.Println(strings.Join(append([]string{"hub"}, createArgs...), " "))
+		return nil
+	}
+	return CmdRunner.Execute(append([]string{args.ProgramPath}, createArgs...))
+}
+
+// initCreateArgs are the arguments for running hub-create(1) with the global
+// flags that hub was given, except for "-C", since create runs within the new
+// repository, and "--noop".
+func initCreateArgs(args *Args) []string {
+	createArgs := []string{}
+	if args.FixRemote {
+		createArgs = append(createArgs, fixRemoteFlag)
+	}
+	if args.Porcelain {
+		createArgs = append(createArgs, porcelainFlag)
+	}
+	if args.Identity != "" {
+		createArgs = append(createArgs, identityFlag, args.Identity)
+	}
+	for i := 0; i < len(args.GlobalFlags); i++ {
+		if args.GlobalFlags[i] == chdirFlag && i+1 < len(args.GlobalFlags) {
+			i++
+			continue
+		}
+		createArgs = append(createArgs, args.GlobalFlags[i])
+	}
+	return append(createArgs, "create")
+}
+
+func unknownTemplateError(kind, name string, available []string) error {
+	if matches := closeMatches(name, available); len(matches) > 0 {
+		return fmt.Errorf("Unknown %s '%s'\nDid you mean: %s?", kind, name, strings.Join(matches, ", "))
+	}
+	return fmt.Errorf("Unknown %s '%s'", kind, name)
+}
+
+// closeMatches returns those candidates that contain name or are within a few
+// typos of it, ignoring case.
+func closeMatches(name string, candidates []string) []string {
+	const maxMatches = 5
+
+	name = strings.ToLower(name)
+	maxDistance := len(name) / 3
+	if maxDistance < 1 {
+		maxDistance = 1
+	}
+
+	matches := []string{}
+	for _, candidate := range candidates {
+		lower := strings.ToLower(candidate)
+		if strings.Contains(lower, name) || editDistance(name, lower) <= maxDistance {
+			matches = append(matches, candidate)
+			if len(matches) == maxMatches {
+				break
+			}
+		}
+	}
+	return matches
+}
+
+func editDistance(a, b string) int {
+	prev := make([]int, len(b)+1)
+	curr := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		curr[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			curr[j] = prev[j-1] + cost
+			if prev[j]+1 < curr[j] {
+				curr[j] = prev[j] + 1
+			}
+			if curr[j-1]+1 < curr[j] {
+				curr[j] = curr[j-1] + 1
+			}
+		}
+		prev, curr = curr, prev
+	}
+	return prev[len(b)]
+}
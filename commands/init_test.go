@@ -91,3 +91,35 @@ func TestSeparateGitDir(t *testing.T) {
 	)
 	assert.Equal(t, expected, commands[1].String())
 }
+
+func TestParseBootstrapFlags(t *testing.T) {
+	args := NewArgs([]string{"init", "--gitignore", "go, node", "--quiet", "--license=mit", "--name", "Mislav", "--force", "my/playground"})
+	bootstrap := parseBootstrapFlags(args)
+
+	assert.Equal(t, []string{"go", "node"}, bootstrap.gitignore)
+	assert.Equal(t, "mit", bootstrap.license)
+	assert.Equal(t, "Mislav", bootstrap.holder)
+	assert.Equal(t, true, bootstrap.force)
+	assert.Equal(t, false, bootstrap.create)
+	assert.Equal(t, []string{"--quiet", "my/playground"}, args.Params)
+
+	args = NewArgs([]string{"init", "--quiet"})
+	assert.Equal(t, (*initBootstrap)(nil), parseBootstrapFlags(args))
+}
+
+func TestInitCreateArgs(t *testing.T) {
+	args := NewArgs([]string{"--noop", "--as", "work", "-C", "dir", "-c", "core.editor=vi", "init", "--create"})
+	assert.Equal(t, []string{"--as", "work", "-c", "core.editor=vi", "create"}, initCreateArgs(args))
+
+	args = NewArgs([]string{"init", "--create"})
+	assert.Equal(t, []string{"create"}, initCreateArgs(args))
+}
+
+func TestCloseMatches(t *testing.T) {
+	available := []string{"Go", "Godot", "Node", "Nodejs", "Python", "Ruby"}
+
+	assert.Equal(t, []string{"Go", "Godot"}, closeMatches("go", available))
+	assert.Equal(t, []string{"Node", "Nodejs"}, closeMatches("nod", available))
+	assert.Equal(t, []string{"Python"}, closeMatches("pyhton", available))
+	assert.Equal(t, []string{}, closeMatches("haskell", available))
+}
@@ -2,7 +2,9 @@ package commands
 
 import (
 	"fmt"
+	"html"
 	"os"
+	"regexp"
 	"strconv"
 	"strings"
 	"time"
@@ -17,9 +19,11 @@ var (
 	cmdIssue = &Command{
 		Run: listIssues,
 		Usage: `
-issue [-a <ASSIGNEE>] [-c <CREATOR>] [-@ <USER>] [-s <STATE>] [-f <FORMAT>] [-M <MILESTONE>] [-l <LABELS>] [-d <DATE>] [-o <SORT_KEY> [-^]] [-L <LIMIT>]
-issue show [-f <FORMAT>] <NUMBER>
-issue create [-oc] [-m <MESSAGE>|-F <FILE>] [--edit] [-a <USERS>] [-M <MILESTONE>] [-l <LABELS>]
+issue [-a <ASSIGNEE>] [-c <CREATOR>] [-@ <USER>] [-s <STATE>] [-f <FORMAT>] [-M <MILESTONE>] [-l <LABELS>] [--exclude-author <USER>] [--exclude-assignee <USER>] [-d <DATE>] [-o <SORT_KEY> [-^|--direction <DIRECTION>]] [-L <LIMIT>] [--search <QUERY>] [--watch[=<INTERVAL>]] [--count-only] [--output <FORMAT> [--columns <COLUMNS>]] [--filter <NAME>] [--save-filter <NAME>]
+issue --list-filters
+issue --delete-filter <NAME>
+issue show [-f <FORMAT>|--json[=<FIELDS>]|--web] [-q <PATH>] <NUMBER>
+issue create [-oc] [-m <MESSAGE>|-F <FILE>|--from-commit <COMMIT>|--from-line <FILE>:<LINE>] [--edit] [-a <USERS>] [-M <MILESTONE>] [-l <LABELS>] [--project <PROJECT>:<COLUMN>] [--force] [--dry-run[=<FORMAT>]]
 issue labels [--color]
 `,
 		Long: `Manage GitHub Issues for the current repository.
@@ -29,7 +33,9 @@ issue labels [--color]
 With no arguments, show a list of open issues.
 
 	* _show_:
-		Show an existing issue specified by <NUMBER>.
+		Show an existing issue specified by <NUMBER>. Images in the description
+		and comments are shown as "[image: <ALT-TEXT>]" placeholders, followed by
+		their URL on an indented line.
 
 	* _create_:
 		Open an issue in the current repository.
@@ -50,6 +56,14 @@ With no arguments, show a list of open issues.
 	-@, --mentioned <USER>
 		Display only issues mentioning <USER>.
 
+	--exclude-author <USER>
+		Leave out issues created by <USER>. Can be given multiple times or as a
+		comma-separated list.
+
+	--exclude-assignee <USER>
+		Leave out issues assigned to <USER>. Can be given multiple times or as a
+		comma-separated list.
+
 	-s, --state <STATE>
 		Display issues with state <STATE> (default: "open").
 
@@ -126,19 +140,52 @@ With no arguments, show a list of open issues.
 	-e, --edit
 		Further edit the contents of <FILE> in a text editor before submitting.
 
+	--from-commit <COMMIT>
+		Pre-fill the issue with the message of <COMMIT>, a permalink to it, and
+		the list of files that it changed, then edit it in a text editor. Useful
+		for following up on a revert.
+
+	--from-line <FILE>:<LINE>
+		Pre-fill the issue with the text of line <LINE> of <FILE> as the title, a
+		permalink to that line, and the 5 lines around it as a code block, then
+		edit it in a text editor. Useful for filing a TODO or FIXME comment.
+
+		The permalinks of both options point to the commit SHA rather than to a
+		branch, so they keep working after the branch changes. The commit should
+		be pushed to GitHub for them to resolve.
+
 	-o, --browse
 		Open the new issue in a web browser.
 
+	--force
+		Try to open the issue even if the repository is disabled. Issues can't be
+		opened in archived repositories either way.
+
 	-c, --copy
 		Put the URL of the new issue to clipboard instead of printing it.
 
-	-M, --milestone <ID>
-		Display only issues for a GitHub milestone with id <ID>.
+	--dry-run[=<FORMAT>]
+		Show the issue that would be created instead of creating it: the title,
+		the body as it is after applying the template, the labels, assignees, and
+		the number and title of the milestone. With <FORMAT> "json", print the
+		same in JSON format.
+
+	-M, --milestone <MILESTONE>
+		Display only issues for a GitHub milestone with number <MILESTONE>.
+
+		When opening an issue, add this issue to the GitHub milestone with the
+		number or the title <MILESTONE>. Titles are matched without regard to
+		case.
 
-		When opening an issue, add this issue to a GitHub milestone with id <ID>.
+	--project <PROJECT>:<COLUMN>
+		After opening an issue, add it to the column named <COLUMN> of the
+		classic project board named <PROJECT>, which belongs to either the
+		repository or its organization. If that fails, the issue is still
+		created, and a warning is shown.
 
 	-l, --labels <LABELS>
-		Display only issues with certain labels.
+		Display only issues with certain labels. Labels prefixed with "!" are
+		excluded instead, e.g. '--labels bug,!wontfix'.
 
 		When opening an issue, add a comma-separated list of labels to this issue.
 
@@ -146,20 +193,102 @@ With no arguments, show a list of open issues.
 		Display only issues updated on or after <DATE> in ISO 8601 format.
 
 	-o, --sort <KEY>
-		Sort displayed issues by "created" (default), "updated" or "comments".
+		Sort displayed issues by "created" (default), "updated", "comments",
+		"reactions", or "number". Issues that are equal by <KEY> are ordered by
+		descending number. Sorting by "reactions" goes through the search API,
+		which can't tell the order of issues with the same number of reactions.
 
 	-^ --sort-ascending
-		Sort by ascending dates instead of descending.
+		Sort in ascending order instead of descending.
+
+	--direction <DIRECTION>
+		Sort in "asc" (ascending) or "desc" (descending, default) order.
 
 	-L, --limit <LIMIT>
-		Display only the first <LIMIT> issues.
+		Display only the first <LIMIT> issues. When this leaves out some of the
+		matching issues, a dimmed "Showing X of Y open issues" footer follows the
+		list on a terminal. With '--format', '--output', or when the output is
+		piped, a "showing X of Y" note is printed to standard error instead.
 
 	--include-pulls
 		Include pull requests as well as issues.
 
+	--search <QUERY>
+		Display only issues matching <QUERY> in GitHub's issue search syntax, e.g.
+		"label:bug -label:wontfix comments:>5 sort:reactions". The search is
+		limited to the current repository, and to issues unless '--include-pulls'
+		is given. Results are ordered by most recently updated unless <QUERY> has
+		a "sort:" qualifier. This can't be combined with the other filtering and
+		sorting options.
+
+	--watch[=<INTERVAL>]
+		Keep refreshing the list every <INTERVAL> until "q" or
+		Ctrl-C is pressed. <INTERVAL> is a number of seconds or a duration such
+		as "5m" (default: 30). Issues that are new since the previous refresh
+		are marked with "+", and those whose state has changed are marked with
+		"~".
+
+	--count-only
+		Print only the number of matching issues.
+
+	--output <FORMAT>
+		Print the list of issues as a table in <FORMAT>, either "markdown",
+		"html", or "csv", e.g. for pasting into a status report. Issue numbers
+		link to the issues on GitHub except in CSV.
+
+	--columns <COLUMNS>
+		A comma-separated list of the columns to include with '--output'
+		(default: "number,title,assignee,labels,age"). The available columns are:
+		"number", "title", "state", "author", "assignee", "labels", "milestone",
+		"age", "created", "updated", and "url".
+
+	--save-filter <NAME>
+		Save the filtering, sorting, and format options of the listing under
+		<NAME> in the global git config, to be reused with '--filter'. The saved
+		options are '--state', '--assignee', '--creator', '--mentioned',
+		'--milestone', '--labels', '--exclude-author', '--exclude-assignee',
+		'--since', '--search', '--include-pulls', '--sort', '--sort-ascending',
+		'--direction', and '--format'. Filters are shared with 'hub pr list'.
+
+	--filter <NAME>
+		List issues with the options saved as <NAME>. Options given on the
+		command line take precedence over the saved ones. Saved options that
+		only apply to pull requests, such as '--base', are ignored with a note.
+
+	--list-filters
+		List the names of the saved filters with their options.
+
+	--delete-filter <NAME>
+		Delete the saved filter <NAME>.
+
+	--json[=<FIELDS>]
+		When showing an issue, print it as a JSON object instead. <FIELDS> is a
+		comma-separated list of the fields to include, written in camel case or
+		snake case (default: all fields). The available fields are: "number",
+		"title", "state", "body", "url", "author", "assignees", "labels",
+		"milestone", "comments", "createdAt", and "updatedAt".
+
+	-q, --query <PATH>
+		When showing an issue, print only the value of a field at the
+		dot-separated <PATH> of its JSON object, e.g. "labels.0". Strings are
+		printed without quotes.
+
+	--web
+		When showing an issue, open it in a web browser instead.
+
 	--color
 		Enable colored output for labels list.
 
+## Configuration:
+
+	* 'hub.editorHints':
+		When "true", the message template that _create_ opens in the text editor
+		lists the users who can be @mentioned and the 10 most recently updated
+		issues and pull requests below the scissors line, so that they can be
+		referenced without looking them up. This costs two additional API
+		requests. The lines are prefixed with the comment character and are
+		removed from the final message.
+
 ## See also:
 
 hub-pr(1), hub(1)
@@ -172,13 +301,34 @@ hub-pr(1), hub(1)
 		-c, --creator USER
 		-@, --mentioned USER
 		-l, --labels LIST
+		--exclude-author USER
+		--exclude-assignee USER
 		-d, --since DATE
 		-o, --sort KEY
 		-^, --sort-ascending
+		--direction DIRECTION
 		--include-pulls
 		-L, --limit N
+		--search QUERY
 		--color
+		--watch
+		--count-only
+		--output FORMAT
+		--columns LIST
+		--filter NAME
+		--save-filter NAME
+		--list-filters
+		--delete-filter NAME
 `,
+		FlagValues: map[string]flagValue{
+			"--state":     enumValue("open", "closed", "all"),
+			"--sort":      enumValue("created", "updated", "comments", "reactions", "number"),
+			"--direction": enumValue("asc", "desc"),
+			"--since":     dateValue(),
+			"--limit":     limitValue,
+			"--color":     colorValue,
+			"--watch":     durationValue(),
+		},
 	}
 
 	cmdCreateIssue = &Command{
@@ -190,10 +340,18 @@ hub-pr(1), hub(1)
 		-M, --milestone M
 		-l, --labels LIST
 		-a, --assign USER
+		--project P
 		-o, --browse
 		-c, --copy
 		-e, --edit
+		--force
+		--from-commit COMMIT
+		--from-line LOCATION
+		--dry-run
 `,
+		FlagValues: map[string]flagValue{
+			"--dry-run": enumValue("text", "json"),
+		},
 	}
 
 	cmdShowIssue = &Command{
@@ -201,8 +359,14 @@ hub-pr(1), hub(1)
 		Run: showIssue,
 		KnownFlags: `
 		-f, --format FMT
+		--json
+		-q, --query PATH
 		--color
+		--web
 `,
+		FlagValues: map[string]flagValue{
+			"--color": colorValue,
+		},
 	}
 
 	cmdLabel = &Command{
@@ -211,6 +375,9 @@ hub-pr(1), hub(1)
 		KnownFlags: `
 		--color
 `,
+		FlagValues: map[string]flagValue{
+			"--color": colorValue,
+		},
 	}
 )
 
@@ -221,7 +388,19 @@ func init() {
 	CmdRunner.Use(cmdIssue)
 }
 
+// issueSearchConflicts are the listing options that '--search' takes the
+// place of, since its query can express all of them.
+var issueSearchConflicts = []string{
+	"--assignee", "--creator", "--mentioned", "--state", "--milestone",
+	"--labels", "--exclude-author", "--exclude-assignee", "--since",
+	"--sort", "--sort-ascending", "--direction",
+}
+
 func listIssues(cmd *Command, args *Args) {
+	if handleSavedFilters(cmd, args, "issue") {
+		return
+	}
+
 	localRepo, err := github.LocalRepo()
 	utils.Check(err)
 
@@ -230,6 +409,18 @@ func listIssues(cmd *Command, args *Args) {
 
 	gh := github.NewClient(project.Host)
 
+	export, err := parseListingExport(cmd, args)
+	utils.Check(err)
+
+	searching := args.Flag.HasReceived("--search")
+	if searching {
+		for _, flag := range issueSearchConflicts {
+			if args.Flag.HasReceived(flag) {
+				utils.Check(cmd.UsageError(fmt.Sprintf("can't use `%s` together with `--search`", flag)))
+			}
+		}
+	}
+
 	if args.Noop {
 		ui.Printf("Would request list of issues for %s\n", project)
 	} else {
@@ -249,15 +440,29 @@ func listIssues(cmd *Command, args *Args) {
 		if args.Flag.HasReceived("--mentioned") {
 			filters["mentioned"] = args.Flag.Value("--mentioned")
 		}
-		if args.Flag.HasReceived("--labels") {
-			labels := commaSeparated(args.Flag.AllValues("--labels"))
+		filter, labels, err := parseListingFilter(args, args.Flag.Value("--creator"), args.Flag.Value("--assignee"))
+		if err != nil {
+			utils.Check(cmd.UsageError(err.Error()))
+		}
+		if len(labels) > 0 {
 			filters["labels"] = strings.Join(labels, ",")
 		}
+		sortKey := "created"
 		if args.Flag.HasReceived("--sort") {
-			filters["sort"] = args.Flag.Value("--sort")
+			sortKey = args.Flag.Value("--sort")
+		}
+		// the API can't sort by number, but its default order by creation
+		// matches it closely enough for '--limit' to pick the right issues;
+		// only the search API can sort by reactions
+		if args.Flag.HasReceived("--sort") && sortKey != "number" && sortKey != "reactions" {
+			filters["sort"] = sortKey
 		}
 
-		if args.Flag.Bool("--sort-ascending") {
+		sortAscending, err := listingSortAscending(args)
+		if err != nil {
+			utils.Check(cmd.UsageError(err.Error()))
+		}
+		if sortAscending {
 			filters["direction"] = "asc"
 		} else {
 			filters["direction"] = "desc"
@@ -274,26 +479,111 @@ func listIssues(cmd *Command, args *Args) {
 
 		flagIssueLimit := args.Flag.Int("--limit")
 		flagIssueIncludePulls := args.Flag.Bool("--include-pulls")
+
+		searchQuery := args.Flag.Value("--search")
+		if !flagIssueIncludePulls {
+			searchQuery = "is:issue " + searchQuery
+		}
+		searchTotal := 0
+
+		if args.Flag.Bool("--count-only") {
+			var count int
+			if searching {
+				count, err = gh.CountSearchIssues(fmt.Sprintf("repo:%s %s", project, searchQuery))
+			} else {
+				count, err = countIssues(gh, project, filters, flagIssueIncludePulls, filter)
+			}
+			utils.Check(err)
+			ui.Println(count)
+			args.NoForward()
+			return
+		}
+
 		flagIssueFormat := "%sC%>(8)%i%Creset  %t%  l%n"
 		if args.Flag.HasReceived("--format") {
 			flagIssueFormat = args.Flag.Value("--format")
 		}
-
-		issues, err := gh.FetchIssues(project, filters, flagIssueLimit, func(issue *github.Issue) bool {
-			return issue.PullRequest == nil || flagIssueIncludePulls
-		})
+		format, err := ui.CompileFormat(flagIssueFormat)
 		utils.Check(err)
 
-		maxNumWidth := 0
-		for _, issue := range issues {
-			if numWidth := len(strconv.Itoa(issue.Number)); numWidth > maxNumWidth {
-				maxNumWidth = numWidth
+		colorize := colorizeOutput(args.Flag.HasReceived("--color"), args.Flag.Value("--color"))
+		hyperlinks := hyperlinksEnabled()
+
+		fetchIssues := func() ([]github.Issue, error) {
+			if searching {
+				issues, total, err := gh.SearchIssues(project, searchQuery, flagIssueLimit)
+				searchTotal = total
+				return issues, err
+			}
+			if sortKey == "reactions" {
+				query, err := issueSearchQuery(gh, project, filters, flagIssueIncludePulls, filter)
+				if err != nil {
+					return nil, err
+				}
+				issues, total, err := gh.SearchIssues(nil, query+" "+searchSortQualifier(sortKey, sortAscending), flagIssueLimit)
+				searchTotal = total
+				return issues, err
+			}
+			issues, err := gh.FetchIssues(project, filters, flagIssueLimit, func(issue *github.Issue) bool {
+				return (issue.PullRequest == nil || flagIssueIncludePulls) && filter.matches(issue)
+			})
+			if err != nil {
+				return nil, err
 			}
+			sortIssues(issues, sortKey, sortAscending)
+			return issues, nil
 		}
 
-		colorize := colorizeOutput(args.Flag.HasReceived("--color"), args.Flag.Value("--color"))
-		for _, issue := range issues {
-			ui.Print(formatIssue(issue, flagIssueFormat, colorize))
+		fetchRows := func() ([]watchRow, error) {
+			issues, err := fetchIssues()
+			if err != nil {
+				return nil, err
+			}
+
+			rows := []watchRow{}
+			for _, issue := range issues {
+				rows = append(rows, watchRow{
+					key:         strconv.Itoa(issue.Number),
+					fingerprint: issue.State,
+					text:        formatIssue(issue, format, colorize, hyperlinks),
+				})
+			}
+			return rows, nil
+		}
+
+		if args.Flag.HasReceived("--watch") {
+			gh.UseConditionalRequests()
+			utils.Check(watchListing(watchInterval(args, "--watch"), colorize, fetchRows))
+		} else {
+			shown := 0
+			if export != nil {
+				issues, err := fetchIssues()
+				utils.Check(err)
+				ui.Print(export.render(issues))
+				shown = len(issues)
+			} else {
+				rows, err := fetchRows()
+				utils.Check(err)
+				for _, row := range rows {
+					ui.Print(row.text)
+				}
+				shown = len(rows)
+			}
+
+			if flagIssueLimit > 0 && shown == flagIssueLimit {
+				count := searchTotal
+				if !searching && sortKey != "reactions" {
+					count, err = countIssues(gh, project, filters, flagIssueIncludePulls, filter)
+				}
+				if err == nil {
+					footer := ui.IsTerminal(os.Stdout) && !args.Flag.HasReceived("--format") && export == nil
+					state := ""
+					if !searching {
+						state = listingState(filters)
+					}
+					printListingTotal(shown, count, "issues", state, footer, colorize)
+				}
+			}
 		}
 	}
 
@@ -382,13 +672,17 @@ func formatIssuePlaceholders(issue github.Issue, colorize bool) map[string]strin
 	}
 }
 
-func formatPullRequestPlaceholders(pr github.PullRequest, colorize bool) map[string]string {
-	prState := pr.State
-	if prState == "open" && pr.Draft {
-		prState = "draft"
+func pullRequestState(pr github.PullRequest) string {
+	if pr.State == "open" && pr.Draft {
+		return "draft"
 	} else if !pr.MergedAt.IsZero() {
-		prState = "merged"
+		return "merged"
 	}
+	return pr.State
+}
+
+func formatPullRequestPlaceholders(pr github.PullRequest, colorize bool) map[string]string {
+	prState := pullRequestState(pr)
 
 	var stateColorSwitch string
 	var prColor int
@@ -445,9 +739,12 @@ func formatPullRequestPlaceholders(pr github.PullRequest, colorize bool) map[str
 	}
 }
 
-func formatIssue(issue github.Issue, format string, colorize bool) string {
+func formatIssue(issue github.Issue, format *ui.Format, colorize, hyperlinks bool) string {
 	placeholders := formatIssuePlaceholders(issue, colorize)
-	return ui.Expand(format, placeholders, colorize)
+	if hyperlinks {
+		linkPlaceholders(placeholders, issue.HtmlUrl, "I", "i", "U")
+	}
+	return format.Expand(placeholders, colorize)
 }
 
 func showIssue(cmd *Command, args *Args) {
@@ -465,6 +762,20 @@ func showIssue(cmd *Command, args *Args) {
 	project, err := localRepo.MainProject()
 	utils.Check(err)
 
+	if args.Flag.Bool("--web") {
+		showInBrowser(cmd, args, project.WebURL("", "", "issues/"+issueNumber))
+		return
+	}
+
+	var format *ui.Format
+	if args.Flag.HasReceived("--format") {
+		format, err = ui.CompileFormat(args.Flag.Value("--format"))
+		utils.Check(err)
+	}
+
+	jsonOut, err := parseJSONOutput(cmd, args, issueJSONFields)
+	utils.Check(err)
+
 	gh := github.NewClient(project.Host)
 
 	var issue = &github.Issue{}
@@ -473,10 +784,14 @@ func showIssue(cmd *Command, args *Args) {
 
 	args.NoForward()
 
+	if jsonOut != nil {
+		utils.Check(jsonOut.print(*issue))
+		return
+	}
+
 	colorize := colorizeOutput(args.Flag.HasReceived("--color"), args.Flag.Value("--color"))
-	if args.Flag.HasReceived("--format") {
-		flagShowIssueFormat := args.Flag.Value("--format")
-		ui.Print(formatIssue(*issue, flagShowIssueFormat, colorize))
+	if format != nil {
+		ui.Print(formatIssue(*issue, format, colorize, hyperlinksEnabled()))
 		return
 	}
 
@@ -484,10 +799,17 @@ func showIssue(cmd *Command, args *Args) {
 	if issue.State != "open" {
 		closed = "[CLOSED] "
 	}
+	showIssueText(gh, project, *issue, closed, nil)
+}
+
+// showIssueText prints an issue or pull request along with its comments. The
+// details are listed after the author and assignees.
+func showIssueText(gh *github.Client, project *github.Project, issue github.Issue, titlePrefix string, details []string) {
+	issueNumber := strconv.Itoa(issue.Number)
 	commentsList, err := gh.FetchComments(project, issueNumber)
 	utils.Check(err)
 
-	ui.Printf("# %s%s\n\n", closed, issue.Title)
+	ui.Printf("# %s%s\n\n", titlePrefix, issue.Title)
 	ui.Printf("* created by @%s on %s\n", issue.User.Login, issue.CreatedAt.String())
 
 	if len(issue.Assignees) > 0 {
@@ -497,19 +819,94 @@ func showIssue(cmd *Command, args *Args) {
 		}
 		ui.Printf("* assignees: %s\n", strings.Join(assignees, ", "))
 	}
+	for _, detail := range details {
+		ui.Printf("* %s\n", detail)
+	}
 
-	ui.Printf("\n%s\n", issue.Body)
+	ui.Printf("\n%s\n", collapseImages(issue.Body))
 
 	if issue.Comments > 0 {
 		ui.Printf("\n## Comments:\n")
 		for _, comment := range commentsList {
-			ui.Printf("\n### comment by @%s on %s\n\n%s\n", comment.User.Login, comment.CreatedAt.String(), comment.Body)
+			ui.Printf("\n### comment by @%s on %s\n\n%s\n", comment.User.Login, comment.CreatedAt.String(), collapseImages(comment.Body))
 		}
 	}
 
 	return
 }
 
+// showInBrowser opens the web page of the issue or pull request that would be
+// shown, which is where images and other rich content is best looked at.
+func showInBrowser(cmd *Command, args *Args, url string) {
+	for _, flag := range []string{"--format", "--json", "--query"} {
+		if args.Flag.HasReceived(flag) {
+			utils.Check(cmd.UsageError(fmt.Sprintf("the '--web' and '%s' options are mutually exclusive", flag)))
+		}
+	}
+	args.NoForward()
+	printBrowseOrCopy(args, url, true, false)
+}
+
+var (
+	linkedImageRe   = regexp.MustCompile(`\[(!\[[^\]]*\]\([^)]*\))\]\([^)]*\)`)
+	markdownImageRe = regexp.MustCompile(`!\[([^\]]*)\]\(\s*<?([^)\s>]*)>?(?:\s+(?:"[^"]*"|'[^']*'))?\s*\)`)
+	htmlImageRe     = regexp.MustCompile(`(?i)<img\b[^>]*>`)
+	htmlImageAttrRe = regexp.MustCompile(`(?i)\b(src|alt)\s*=\s*(?:"([^"]*)"|'([^']*)'|([^\s"'>]+))`)
+	codeFenceRe     = regexp.MustCompile("^\\s*(```|~~~)")
+)
+
+// collapseImages replaces the Markdown and HTML images in text with
+// "[image: <ALT-TEXT>]" placeholders, and lists their URLs on indented lines
+// after the line they were on. Images in fenced code blocks are left alone.
+func collapseImages(text string) string {
+	lines := strings.Split(text, "\n")
+	inCode := false
+	for i, line := range lines {
+		if codeFenceRe.MatchString(line) {
+			inCode = !inCode
+			continue
+		}
+		if inCode {
+			continue
+		}
+
+		urls := []string{}
+		placeholder := func(alt, url string) string {
+			if url != "" {
+				urls = append(urls, url)
+			}
+			if alt = strings.TrimSpace(alt); alt != "" {
+				return fmt.Sprintf("[image: %s]", alt)
+			}
+			return "[image]"
+		}
+
+		// a linked image is collapsed to just the image
+		line = linkedImageRe.ReplaceAllString(line, "$1")
+		line = markdownImageRe.ReplaceAllStringFunc(line, func(m string) string {
+			match := markdownImageRe.FindStringSubmatch(m)
+			return placeholder(match[1], match[2])
+		})
+		line = htmlImageRe.ReplaceAllStringFunc(line, func(m string) string {
+			attrs := map[string]string{}
+			for _, attr := range htmlImageAttrRe.FindAllStringSubmatch(m, -1) {
+				attrs[strings.ToLower(attr[1])] = html.UnescapeString(attr[2] + attr[3] + attr[4])
+			}
+			return placeholder(attrs["alt"], attrs["src"])
+		})
+
+		if len(urls) > 0 {
+			line = strings.TrimSuffix(line, "\r")
+			indent := line[:len(line)-len(strings.TrimLeft(line, " \t"))] + "    "
+			for _, url := range urls {
+				line += "\n" + indent + url
+			}
+		}
+		lines[i] = line
+	}
+	return strings.Join(lines, "\n")
+}
+
 func createIssue(cmd *Command, args *Args) {
 	localRepo, err := github.LocalRepo()
 	utils.Check(err)
@@ -518,6 +915,7 @@ func createIssue(cmd *Command, args *Args) {
 	utils.Check(err)
 
 	gh := github.NewClient(project.Host)
+	utils.Check(gh.EnsureWritable(project, args.Flag.Bool("--force")))
 
 	messageBuilder := &github.MessageBuilder{
 		Filename: "ISSUE_EDITMSG",
@@ -531,7 +929,29 @@ text is the title and the rest is the description.`, project))
 
 	flagIssueEdit := args.Flag.Bool("--edit")
 	flagIssueMessage := args.Flag.AllValues("--message")
-	if len(flagIssueMessage) > 0 {
+	prefill := ""
+	if args.Flag.HasReceived("--from-commit") && args.Flag.HasReceived("--from-line") {
+		utils.Check(cmd.UsageError("the '--from-commit' and '--from-line' options are mutually exclusive"))
+	}
+	for _, flag := range []string{"--from-commit", "--from-line"} {
+		if !args.Flag.HasReceived(flag) {
+			continue
+		}
+		if len(flagIssueMessage) > 0 || args.Flag.HasReceived("--file") {
+			utils.Check(cmd.UsageError(fmt.Sprintf("the '%s' option can't be combined with '--message' or '--file'", flag)))
+		}
+		if flag == "--from-commit" {
+			prefill, err = issueFromCommit(project, args.Flag.Value(flag))
+		} else {
+			prefill, err = issueFromLine(project, args.Flag.Value(flag))
+		}
+		utils.Check(err)
+	}
+
+	if prefill != "" {
+		messageBuilder.Message = prefill
+		messageBuilder.Edit = true
+	} else if len(flagIssueMessage) > 0 {
 		messageBuilder.Message = strings.Join(flagIssueMessage, "\n\n")
 		messageBuilder.Edit = flagIssueEdit
 	} else if args.Flag.HasReceived("--file") {
@@ -552,6 +972,12 @@ text is the title and the rest is the description.`, project))
 
 	}
 
+	if messageBuilder.Edit && editorHintsEnabled() {
+		if hints := editorHints(gh, project); hints != "" {
+			messageBuilder.AddCommentedLines(hints)
+		}
+	}
+
 	title, body, err := messageBuilder.Extract()
 	utils.Check(err)
 
@@ -574,17 +1000,72 @@ text is the title and the rest is the description.`, project))
 		params["assignees"] = flagIssueAssignees
 	}
 
-	if flagIssueMilestone := args.Flag.Int("--milestone"); flagIssueMilestone > 0 {
-		params["milestone"] = flagIssueMilestone
+	flagIssueProject := args.Flag.Value("--project")
+	projectBoard, projectColumn := "", ""
+	if flagIssueProject != "" {
+		if i := strings.LastIndex(flagIssueProject, ":"); i > 0 && i < len(flagIssueProject)-1 {
+			projectBoard, projectColumn = flagIssueProject[:i], flagIssueProject[i+1:]
+		} else {
+			utils.Check(cmd.UsageError(fmt.Sprintf("invalid '--project' value %q; expected <PROJECT>:<COLUMN>", flagIssueProject)))
+		}
 	}
 
 	args.NoForward()
-	if args.Noop {
+	dryRun := dryRunFormat(args)
+	if flagIssueMilestone := args.Flag.Value("--milestone"); flagIssueMilestone != "" && dryRun == "" {
+		// BC: Don't try to resolve milestone name if it's an integer
+		milestoneNumber, err := strconv.Atoi(flagIssueMilestone)
+		if err != nil && !args.Noop {
+			milestones, err := gh.FetchMilestones(project)
+			utils.Check(err)
+			milestoneNumber, err = findMilestoneNumber(milestones, flagIssueMilestone)
+			utils.Check(err)
+		}
+		if milestoneNumber > 0 {
+			params["milestone"] = milestoneNumber
+		}
+	}
+
+	if flagIssueDryRun := dryRun; flagIssueDryRun != "" {
+		preview := &creationPreview{
+			Repository: project.String(),
+			Title:      title,
+			Body:       body,
+			Labels:     flagIssueLabels,
+			Assignees:  flagIssueAssignees,
+		}
+		if args.Flag.HasReceived("--milestone") {
+			preview.Milestone, err = lookupMilestone(gh, project, args.Flag.Value("--milestone"))
+			utils.Check(err)
+		}
+
+		output, err := preview.render(flagIssueDryRun)
+		utils.Check(err)
+		ui.Print(output)
+	} else if args.Noop {
 		ui.Printf("Would create issue `%s' for %s\n", params["title"], project)
+		if flagIssueMilestone := args.Flag.Value("--milestone"); flagIssueMilestone != "" && params["milestone"] == nil {
+			ui.Printf("Would add it to milestone `%s'\n", flagIssueMilestone)
+		}
+		if projectBoard != "" {
+			ui.Printf("Would add it to column `%s' of project `%s'\n", projectColumn, projectBoard)
+		}
 	} else {
 		issue, err := gh.CreateIssue(project, params)
 		utils.Check(err)
 
+		if projectBoard != "" {
+			// the issue exists by now, so failing to add it to the project
+			// board mustn't look like the issue wasn't created
+			column, err := gh.FindProjectColumn(project, projectBoard, projectColumn)
+			if err == nil {
+				err = gh.AddProjectCard(column, issue.Id, "Issue")
+			}
+			if err != nil {
+				ui.Errorf("warning: the issue was created, but couldn't be added to project '%s': %s\n", projectBoard, err)
+			}
+		}
+
 		flagIssueBrowse := args.Flag.Bool("--browse")
 		flagIssueCopy := args.Flag.Bool("--copy")
 		printBrowseOrCopy(args, issue.HtmlUrl, flagIssueBrowse, flagIssueCopy)
@@ -593,6 +1074,62 @@ text is the title and the rest is the description.`, project))
 	messageBuilder.Cleanup()
 }
 
+func editorHintsEnabled() bool {
+	enabled, _ := git.Config("hub.editorHints")
+	return enabled == "true"
+}
+
+// editorHints lists the users that can be @mentioned and the issues and pull
+// requests that were updated most recently, as a reminder of what to reference
+// when writing in the editor. Lists that can't be fetched are left out.
+func editorHints(gh *github.Client, project *github.Project) string {
+	sections := []string{}
+
+	if users, err := gh.FetchAssignees(project); err == nil && len(users) > 0 {
+		mentions := []string{}
+		for _, user := range users {
+			mentions = append(mentions, "@"+user.Login)
+		}
+		sections = append(sections, "Collaborators:\n"+wrapWords(mentions, "  ", 72))
+	}
+
+	filters := map[string]interface{}{
+		"state":     "all",
+		"sort":      "updated",
+		"direction": "desc",
+	}
+	if issues, err := gh.FetchIssues(project, filters, 10, nil); err == nil && len(issues) > 0 {
+		lines := []string{"Recently updated issues and pull requests:"}
+		for _, issue := range issues {
+			lines = append(lines, fmt.Sprintf("  #%d %s", issue.Number, issue.Title))
+		}
+		sections = append(sections, strings.Join(lines, "\n"))
+	}
+
+	return strings.Join(sections, "\n\n")
+}
+
+// wrapWords joins words with spaces into indented lines of at most width
+// characters, unless a single word is longer than that.
+func wrapWords(words []string, indent string, width int) string {
+	lines := []string{}
+	line := ""
+	for _, word := range words {
+		if line != "" && len(indent)+len(line)+1+len(word) > width {
+			lines = append(lines, indent+line)
+			line = ""
+		}
+		if line != "" {
+			line += " "
+		}
+		line += word
+	}
+	if line != "" {
+		lines = append(lines, indent+line)
+	}
+	return strings.Join(lines, "\n")
+}
+
 func listLabels(cmd *Command, args *Args) {
 	localRepo, err := github.LocalRepo()
 	utils.Check(err)
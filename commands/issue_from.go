@@ -0,0 +1,113 @@
+package commands
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/github/hub/git"
+	"github.com/github/hub/github"
+)
+
+// issueContextLines is how many lines around the one given with '--from-line'
+// are quoted in the issue, including that line.
+const issueContextLines = 5
+
+// issueFromCommit pre-fills an issue with the message of a commit, a permalink
+// to it and a summary of the files that it changed.
+func issueFromCommit(project *github.Project, ref string) (string, error) {
+	sha, err := git.Ref(ref + "^{commit}")
+	if err != nil {
+		return "", fmt.Errorf("Aborted: no commit could be determined from '%s'", ref)
+	}
+	message, err := git.Show(sha)
+	if err != nil {
+		return "", fmt.Errorf("Can't load commit message of %s", sha)
+	}
+	stat, err := git.DiffStat(sha)
+	if err != nil {
+		return "", err
+	}
+	return formatIssueFromCommit(message, project.WebURL("", "", "commit/"+sha), stat), nil
+}
+
+func formatIssueFromCommit(message, permalink, stat string) string {
+	parts := []string{message, permalink}
+	if stat != "" {
+		parts = append(parts, codeFence(stat))
+	}
+	return strings.Join(parts, "\n\n")
+}
+
+// issueFromLine pre-fills an issue with a permalink to a "FILE:LINE" location
+// as of HEAD and the lines around it.
+func issueFromLine(project *github.Project, location string) (string, error) {
+	file, line, err := parseFileLine(location)
+	if err != nil {
+		return "", err
+	}
+	sha, err := git.Ref("HEAD^{commit}")
+	if err != nil {
+		return "", fmt.Errorf("Aborted: '--from-line' requires a commit to link to")
+	}
+	repoPath, err := git.RepoPath(file)
+	if err != nil {
+		return "", err
+	}
+	contents, err := git.FileAt(sha, repoPath)
+	if err != nil {
+		return "", err
+	}
+	permalink := project.WebURL("", "", fmt.Sprintf("blob/%s/%s#L%d", sha, repoPath, line))
+	return formatIssueFromLine(contents, line, permalink)
+}
+
+func parseFileLine(location string) (file string, line int, err error) {
+	i := strings.LastIndex(location, ":")
+	if i > 0 {
+		file = location[:i]
+		line, err = strconv.Atoi(location[i+1:])
+	}
+	if i <= 0 || err != nil || line < 1 {
+		return "", 0, fmt.Errorf("invalid location %q; expected <FILE>:<LINE>", location)
+	}
+	return file, line, nil
+}
+
+// formatIssueFromLine uses the text of the line as the title, with comment
+// markers stripped off, and quotes the lines around it in the body.
+func formatIssueFromLine(contents string, line int, permalink string) (string, error) {
+	lines := strings.Split(strings.TrimSuffix(contents, "\n"), "\n")
+	if line > len(lines) {
+		return "", fmt.Errorf("line %d is past the end of the file", line)
+	}
+
+	start := line - issueContextLines/2
+	if start < 1 {
+		start = 1
+	}
+	end := start + issueContextLines - 1
+	if end > len(lines) {
+		end = len(lines)
+	}
+
+	title := strings.TrimSpace(lines[line-1])
+	title = strings.TrimLeft(title, "/#*;-<! \t")
+	title = strings.TrimSuffix(strings.TrimSuffix(title, "*/"), "-->")
+	title = strings.TrimSpace(title)
+	if title == "" {
+		title = fmt.Sprintf("Line %d", line)
+	}
+
+	return fmt.Sprintf("%s\n\n%s\n\n%s", title, permalink, codeFence(strings.Join(lines[start-1:end], "\n"))), nil
+}
+
+// codeFence wraps text in a fenced code block whose fence is longer than any
+// run of backticks in the text.
+func codeFence(text string) string {
+	fence := "```"
+	for strings.Contains(text, fence) {
+		fence += "`"
+	}
+	return fmt.Sprintf("%s\n%s\n%s", fence, text, fence)
+}
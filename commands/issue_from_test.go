@@ -0,0 +1,38 @@
+package commands
+
+import (
+	"testing"
+
+	"github.com/bmizerany/assert"
+)
+
+func TestParseFileLine(t *testing.T) {
+	file, line, err := parseFileLine("lib/c:d.go:12")
+	assert.Equal(t, nil, err)
+	assert.Equal(t, "lib/c:d.go", file)
+	assert.Equal(t, 12, line)
+
+	for _, location := range []string{"main.go", ":3", "main.go:", "main.go:0", "main.go:x"} {
+		_, _, err = parseFileLine(location)
+		assert.Equal(t, `invalid location "`+location+`"; expected <FILE>:<LINE>`, err.Error())
+	}
+}
+
+func TestFormatIssueFromCommit(t *testing.T) {
+	message := formatIssueFromCommit("Revert \"Add cache\"\n\nIt broke the build.", "https://github.com/acme/tools/commit/abc123", " cache.go | 4 ----\n 1 file changed, 4 deletions(-)")
+	assert.Equal(t, "Revert \"Add cache\"\n\nIt broke the build.\n\nhttps://github.com/acme/tools/commit/abc123\n\n```\n cache.go | 4 ----\n 1 file changed, 4 deletions(-)\n```", message)
+}
+
+func TestFormatIssueFromLine(t *testing.T) {
+	contents := "package main\n\nfunc main() {\n\t// FIXME: handle errors\n\trun()\n}\n"
+	message, err := formatIssueFromLine(contents, 4, "https://github.com/acme/tools/blob/abc123/main.go#L4")
+	assert.Equal(t, nil, err)
+	assert.Equal(t, "FIXME: handle errors\n\nhttps://github.com/acme/tools/blob/abc123/main.go#L4\n\n```\n\nfunc main() {\n\t// FIXME: handle errors\n\trun()\n}\n```", message)
+
+	message, err = formatIssueFromLine("# TODO\n```\n", 1, "URL")
+	assert.Equal(t, nil, err)
+	assert.Equal(t, "TODO\n\nURL\n\n````\n# TODO\n```\n````", message)
+
+	_, err = formatIssueFromLine(contents, 7, "URL")
+	assert.Equal(t, "line 7 is past the end of the file", err.Error())
+}
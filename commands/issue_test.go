@@ -5,6 +5,7 @@ import (
 	"time"
 
 	"github.com/github/hub/github"
+	"github.com/github/hub/ui"
 )
 
 type formatIssueTest struct {
@@ -17,7 +18,11 @@ type formatIssueTest struct {
 
 func testFormatIssue(t *testing.T, tests []formatIssueTest) {
 	for _, test := range tests {
-		if got := formatIssue(test.issue, test.format, test.colorize); got != test.expect {
+		format, err := ui.CompileFormat(test.format)
+		if err != nil {
+			t.Fatalf("%s: CompileFormat(%q) failed: %s", test.name, test.format, err)
+		}
+		if got := formatIssue(test.issue, format, test.colorize, false); got != test.expect {
 			t.Errorf("%s: formatIssue(..., %q, %t) = %q, want %q", test.name, test.format, test.colorize, got, test.expect)
 		}
 	}
@@ -307,3 +312,91 @@ func TestFormatIssue_customFormatString(t *testing.T) {
 		},
 	})
 }
+
+func TestFormatPullRequest_statusPlaceholders(t *testing.T) {
+	pr := github.PullRequest{
+		Number: 12,
+		Title:  "Add feature",
+		State:  "open",
+		User:   &github.User{Login: "mislav"},
+		Base:   &github.PullRequestSpec{Ref: "main"},
+		Head:   &github.PullRequestSpec{Ref: "feature", Label: "mislav:feature"},
+	}
+	format, err := ui.CompileFormat("%cC%cs%Creset %rC%rd%Creset %t")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	statuses := map[int]*github.PullRequestStatus{
+		12: {
+			Number:         12,
+			ReviewDecision: "CHANGES_REQUESTED",
+			Checks: []github.CIStatus{
+				{State: "success", Context: "build"},
+				{State: "failure", Context: "lint"},
+			},
+		},
+	}
+	got := formatPullRequest(pr, format, true, false, lazyPullRequestPlaceholders(nil, nil, pr, format, statuses, nil, true))
+	expect := "\033[31mfailure\033[m \033[31mCHANGES_REQUESTED\033[m Add feature"
+	if got != expect {
+		t.Errorf("formatPullRequest() = %q, want %q", got, expect)
+	}
+
+	got = formatPullRequest(pr, format, false, false, lazyPullRequestPlaceholders(nil, nil, pr, format, map[int]*github.PullRequestStatus{}, nil, false))
+	if got != "  Add feature" {
+		t.Errorf("formatPullRequest() = %q, want %q", got, "  Add feature")
+	}
+
+	format, err = ui.CompileFormat("%<(16)%req%t")
+	if err != nil {
+		t.Fatal(err)
+	}
+	got = formatPullRequest(pr, format, false, false, lazyPullRequestPlaceholders(nil, nil, pr, format, nil, map[int]string{12: "blocked: checks"}, false))
+	if got != "blocked: checks Add feature" {
+		t.Errorf("formatPullRequest() = %q, want %q", got, "blocked: checks Add feature")
+	}
+}
+
+func TestWrapWords(t *testing.T) {
+	words := []string{"@mislav", "@josh", "@defunkt", "@a-very-long-login-name"}
+	expect := "  @mislav @josh\n  @defunkt\n  @a-very-long-login-name"
+	if got := wrapWords(words, "  ", 20); got != expect {
+		t.Errorf("wrapWords() = %q, want %q", got, expect)
+	}
+	if got := wrapWords([]string{}, "  ", 20); got != "" {
+		t.Errorf("wrapWords() = %q, want empty string", got)
+	}
+}
+
+func TestCollapseImages(t *testing.T) {
+	tests := []struct {
+		body, expect string
+	}{
+		{
+			"Broken:\r\n![screenshot of the error](https://user-images.githubusercontent.com/1/a.png \"Error\")\r\nThanks",
+			"Broken:\r\n[image: screenshot of the error]\n    https://user-images.githubusercontent.com/1/a.png\nThanks",
+		},
+		{
+			"  * before ![](https://x.test/1.png) and <img width=\"200\" alt=\"R&amp;D\" src='https://x.test/2.png'> after",
+			"  * before [image] and [image: R&D] after\n      https://x.test/1.png\n      https://x.test/2.png",
+		},
+		{
+			"[![build status](https://ci.test/badge.svg)](https://ci.test/builds)",
+			"[image: build status]\n    https://ci.test/badge.svg",
+		},
+		{
+			"no URL: ![diagram]() <img alt=\"logo\">",
+			"no URL: [image: diagram] [image: logo]",
+		},
+		{
+			"```md\n![code](https://x.test/code.png)\n```\n[link](https://x.test) stays",
+			"```md\n![code](https://x.test/code.png)\n```\n[link](https://x.test) stays",
+		},
+	}
+	for _, test := range tests {
+		if got := collapseImages(test.body); got != test.expect {
+			t.Errorf("collapseImages(%q) = %q, want %q", test.body, got, test.expect)
+		}
+	}
+}
@@ -0,0 +1,202 @@
+package commands
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/github/hub/github"
+	"github.com/github/hub/ui"
+	"github.com/github/hub/utils"
+)
+
+// jsonField is a field of an issue or pull request that the show commands
+// can print with '--json'.
+type jsonField struct {
+	name  string
+	value func(issue github.Issue) interface{}
+}
+
+func jsonTime(t time.Time) interface{} {
+	if t.IsZero() {
+		return nil
+	}
+	return t.Format(time.RFC3339)
+}
+
+func userLogins(users []github.User) []string {
+	logins := []string{}
+	for _, user := range users {
+		logins = append(logins, user.Login)
+	}
+	return logins
+}
+
+var issueJSONFields = []jsonField{
+	{"number", func(issue github.Issue) interface{} { return issue.Number }},
+	{"title", func(issue github.Issue) interface{} { return issue.Title }},
+	{"state", func(issue github.Issue) interface{} { return issue.State }},
+	{"body", func(issue github.Issue) interface{} { return issue.Body }},
+	{"url", func(issue github.Issue) interface{} { return issue.HtmlUrl }},
+	{"author", func(issue github.Issue) interface{} {
+		if issue.User == nil {
+			return nil
+		}
+		return issue.User.Login
+	}},
+	{"assignees", func(issue github.Issue) interface{} { return userLogins(issue.Assignees) }},
+	{"labels", func(issue github.Issue) interface{} {
+		names := []string{}
+		for _, label := range issue.Labels {
+			names = append(names, label.Name)
+		}
+		return names
+	}},
+	{"milestone", func(issue github.Issue) interface{} {
+		if issue.Milestone == nil {
+			return nil
+		}
+		return issue.Milestone.Title
+	}},
+	{"comments", func(issue github.Issue) interface{} { return issue.Comments }},
+	{"createdAt", func(issue github.Issue) interface{} { return jsonTime(issue.CreatedAt) }},
+	{"updatedAt", func(issue github.Issue) interface{} { return jsonTime(issue.UpdatedAt) }},
+}
+
+var pullRequestJSONFields = append(append([]jsonField{}, issueJSONFields...), []jsonField{
+	{"isDraft", func(issue github.Issue) interface{} { return issue.Draft }},
+	{"headRefName", func(issue github.Issue) interface{} { return issue.Head.Ref }},
+	{"headRefOid", func(issue github.Issue) interface{} { return issue.Head.Sha }},
+	{"headRepository", func(issue github.Issue) interface{} {
+		if issue.Head.Repo == nil {
+			return nil
+		}
+		return issue.Head.Repo.FullName
+	}},
+	{"baseRefName", func(issue github.Issue) interface{} { return issue.Base.Ref }},
+	{"baseRefOid", func(issue github.Issue) interface{} { return issue.Base.Sha }},
+	{"mergedAt", func(issue github.Issue) interface{} { return jsonTime(issue.MergedAt) }},
+	{"mergeCommitOid", func(issue github.Issue) interface{} { return issue.MergeCommitSha }},
+	{"maintainerCanModify", func(issue github.Issue) interface{} { return issue.MaintainerCanModify }},
+	{"reviewRequests", func(issue github.Issue) interface{} {
+		requests := userLogins(issue.RequestedReviewers)
+		for _, team := range issue.RequestedTeams {
+			requests = append(requests, team.Slug)
+		}
+		return requests
+	}},
+}...)
+
+// normalizeJSONFieldName makes "headRefName", "head_ref_name" and
+// "HeadRefName" all refer to the same field.
+func normalizeJSONFieldName(name string) string {
+	return strings.ToLower(strings.Replace(strings.TrimSpace(name), "_", "", -1))
+}
+
+func lookupJSONField(fields []jsonField, name string) (jsonField, error) {
+	for _, field := range fields {
+		if normalizeJSONFieldName(field.name) == normalizeJSONFieldName(name) {
+			return field, nil
+		}
+	}
+
+	available := []string{}
+	for _, field := range fields {
+		available = append(available, field.name)
+	}
+	return jsonField{}, fmt.Errorf("unknown JSON field: %q\navailable fields: %s", name, strings.Join(available, ", "))
+}
+
+// jsonOutput holds the fields selected with '--json' and the path given with
+// '-q', if any, for the issue and pull request show commands.
+type jsonOutput struct {
+	fields []jsonField
+	query  string
+}
+
+// parseJSONOutput reads the '--json[=<FIELDS>]' and '-q' flags. Without a list
+// of fields, all available fields are included. It returns nil unless either
+// flag was given.
+func parseJSONOutput(cmd *Command, args *Args, available []jsonField) (*jsonOutput, error) {
+	if !args.Flag.HasReceived("--json") && !args.Flag.HasReceived("--query") {
+		return nil, nil
+	}
+	if args.Flag.HasReceived("--format") {
+		return nil, cmd.UsageError("the '--json' and '--format' options are mutually exclusive")
+	}
+
+	output := &jsonOutput{
+		fields: available,
+		query:  args.Flag.Value("--query"),
+	}
+
+	if names := commaSeparated(args.Flag.AllValues("--json")); strings.Join(names, "") != "" {
+		output.fields = []jsonField{}
+		for _, name := range names {
+			if strings.TrimSpace(name) == "" {
+				continue
+			}
+			field, err := lookupJSONField(available, name)
+			if err != nil {
+				return nil, err
+			}
+			output.fields = append(output.fields, field)
+		}
+	}
+
+	if output.query != "" {
+		// the first key of the query names a field, which can also be given in
+		// snake case
+		keys := strings.SplitN(strings.TrimPrefix(output.query, "."), ".", 2)
+		field, err := lookupJSONField(output.fields, keys[0])
+		if err != nil {
+			return nil, err
+		}
+		keys[0] = field.name
+		output.query = strings.Join(keys, ".")
+	}
+
+	return output, nil
+}
+
+func (o *jsonOutput) print(issue github.Issue) error {
+	object := map[string]interface{}{}
+	for _, field := range o.fields {
+		object[field.name] = field.value(issue)
+	}
+
+	if o.query == "" {
+		out, err := json.MarshalIndent(object, "", "  ")
+		if err != nil {
+			return err
+		}
+		ui.Println(string(out))
+		return nil
+	}
+
+	// round-trip through JSON so that the query sees the same values that
+	// '--json' would print
+	encoded, err := json.Marshal(object)
+	if err != nil {
+		return err
+	}
+	var data interface{}
+	dec := json.NewDecoder(bytes.NewReader(encoded))
+	dec.UseNumber()
+	if err := dec.Decode(&data); err != nil {
+		return err
+	}
+
+	value, err := utils.JSONQuery(data, o.query)
+	if err != nil {
+		return err
+	}
+	out, err := utils.JSONQueryValue(value)
+	if err != nil {
+		return err
+	}
+	ui.Println(out)
+	return nil
+}
@@ -0,0 +1,116 @@
+package commands
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/github/hub/github"
+)
+
+// listingFilter holds the filters of `issue list` and `pr list` that the
+// listing endpoints of the API don't support. Those are applied to fetched
+// items before '--limit' takes effect, while counts translate them to search
+// qualifiers such as "-label:wontfix".
+type listingFilter struct {
+	labels            []string
+	excludedLabels    []string
+	excludedAuthors   []string
+	excludedAssignees []string
+}
+
+// splitLabels separates labels prefixed with "!", which are to be excluded,
+// from the required ones.
+func splitLabels(values []string) (required, excluded []string) {
+	for _, label := range commaSeparated(values) {
+		label = strings.TrimSpace(label)
+		if strings.HasPrefix(label, "!") {
+			excluded = append(excluded, strings.TrimPrefix(label, "!"))
+		} else if label != "" {
+			required = append(required, label)
+		}
+	}
+	return
+}
+
+// parseListingFilter reads the "!" labels from '--labels' as well as
+// '--exclude-author' and '--exclude-assignee'. It returns the required labels
+// separately for commands that can pass them on to the API. Nothing may be
+// both required and excluded.
+func parseListingFilter(args *Args, author, assignee string) (filter *listingFilter, requiredLabels []string, err error) {
+	requiredLabels, excludedLabels := splitLabels(args.Flag.AllValues("--labels"))
+	filter = &listingFilter{
+		excludedLabels:    excludedLabels,
+		excludedAuthors:   commaSeparated(args.Flag.AllValues("--exclude-author")),
+		excludedAssignees: commaSeparated(args.Flag.AllValues("--exclude-assignee")),
+	}
+
+	for _, label := range requiredLabels {
+		if containsFold(filter.excludedLabels, label) {
+			return nil, nil, fmt.Errorf("label '%s' can't be both required and excluded", label)
+		}
+	}
+	if author != "" && containsFold(filter.excludedAuthors, author) {
+		return nil, nil, fmt.Errorf("author '%s' can't be both required and excluded", author)
+	}
+	if assignee != "" && containsFold(filter.excludedAssignees, assignee) {
+		return nil, nil, fmt.Errorf("assignee '%s' can't be both required and excluded", assignee)
+	}
+
+	return filter, requiredLabels, nil
+}
+
+func (f *listingFilter) isEmpty() bool {
+	return len(f.labels) == 0 && len(f.excludedLabels) == 0 && len(f.excludedAuthors) == 0 && len(f.excludedAssignees) == 0
+}
+
+func (f *listingFilter) matches(issue *github.Issue) bool {
+	labels := []string{}
+	for _, label := range issue.Labels {
+		labels = append(labels, label.Name)
+	}
+	for _, label := range f.labels {
+		if !containsFold(labels, label) {
+			return false
+		}
+	}
+	for _, label := range f.excludedLabels {
+		if containsFold(labels, label) {
+			return false
+		}
+	}
+	if issue.User != nil && containsFold(f.excludedAuthors, issue.User.Login) {
+		return false
+	}
+	for _, assignee := range issue.Assignees {
+		if containsFold(f.excludedAssignees, assignee.Login) {
+			return false
+		}
+	}
+	return true
+}
+
+func (f *listingFilter) searchQualifiers() []string {
+	qualifiers := []string{}
+	for _, label := range f.labels {
+		qualifiers = append(qualifiers, searchQualifier("label", label))
+	}
+	for _, label := range f.excludedLabels {
+		qualifiers = append(qualifiers, "-"+searchQualifier("label", label))
+	}
+	for _, author := range f.excludedAuthors {
+		qualifiers = append(qualifiers, "-"+searchQualifier("author", author))
+	}
+	for _, assignee := range f.excludedAssignees {
+		qualifiers = append(qualifiers, "-"+searchQualifier("assignee", assignee))
+	}
+	return qualifiers
+}
+
+func containsFold(values []string, value string) bool {
+	for _, v := range values {
+		if strings.EqualFold(v, value) {
+			return true
+		}
+	}
+	return false
+}
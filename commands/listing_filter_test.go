@@ -0,0 +1,68 @@
+package commands
+
+import (
+	"testing"
+
+	"github.com/bmizerany/assert"
+	"github.com/github/hub/github"
+	"github.com/github/hub/utils"
+)
+
+func parseTestListingFilter(params []string, author, assignee string) (*listingFilter, []string, error) {
+	args := NewArgs(append([]string{"issue"}, params...))
+	args.Flag = utils.NewArgsParserWithUsage("-l, --labels LIST\n--exclude-author USER\n--exclude-assignee USER\n")
+	args.Flag.Parse(params)
+	return parseListingFilter(args, author, assignee)
+}
+
+func TestParseListingFilter(t *testing.T) {
+	filter, labels, err := parseTestListingFilter([]string{"-l", "bug,!wontfix", "--labels", "!duplicate", "--exclude-author", "dependabot,renovate"}, "", "")
+	assert.Equal(t, nil, err)
+	assert.Equal(t, []string{"bug"}, labels)
+	assert.Equal(t, []string{"wontfix", "duplicate"}, filter.excludedLabels)
+	assert.Equal(t, []string{"dependabot", "renovate"}, filter.excludedAuthors)
+
+	_, _, err = parseTestListingFilter([]string{"-l", "Bug,!bug"}, "", "")
+	assert.Equal(t, "label 'Bug' can't be both required and excluded", err.Error())
+
+	_, _, err = parseTestListingFilter([]string{"--exclude-author", "mislav"}, "Mislav", "")
+	assert.Equal(t, "author 'Mislav' can't be both required and excluded", err.Error())
+
+	_, _, err = parseTestListingFilter([]string{"--exclude-assignee", "hubot"}, "", "hubot")
+	assert.Equal(t, "assignee 'hubot' can't be both required and excluded", err.Error())
+}
+
+func TestListingFilter_matches(t *testing.T) {
+	filter := &listingFilter{
+		labels:            []string{"bug"},
+		excludedLabels:    []string{"wontfix"},
+		excludedAuthors:   []string{"dependabot"},
+		excludedAssignees: []string{"hubot"},
+	}
+	issue := func(author string, labels []string, assignees ...string) *github.Issue {
+		i := &github.Issue{User: &github.User{Login: author}}
+		for _, label := range labels {
+			i.Labels = append(i.Labels, github.IssueLabel{Name: label})
+		}
+		for _, assignee := range assignees {
+			i.Assignees = append(i.Assignees, github.User{Login: assignee})
+		}
+		return i
+	}
+
+	assert.T(t, filter.matches(issue("mislav", []string{"Bug"})))
+	assert.T(t, !filter.matches(issue("mislav", []string{"feature"})))
+	assert.T(t, !filter.matches(issue("mislav", []string{"bug", "WontFix"})))
+	assert.T(t, !filter.matches(issue("Dependabot", []string{"bug"})))
+	assert.T(t, !filter.matches(issue("mislav", []string{"bug"}, "octocat", "hubot")))
+	assert.T(t, (&listingFilter{}).matches(issue("mislav", nil)))
+}
+
+func TestListingFilter_searchQualifiers(t *testing.T) {
+	filter := &listingFilter{
+		excludedLabels:    []string{"won't fix", "duplicate"},
+		excludedAuthors:   []string{"app/dependabot"},
+		excludedAssignees: []string{"hubot"},
+	}
+	assert.Equal(t, []string{`-label:"won't fix"`, "-label:duplicate", "-author:app/dependabot", "-assignee:hubot"}, filter.searchQualifiers())
+}
@@ -0,0 +1,78 @@
+package commands
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/github/hub/github"
+)
+
+// listingSortKeys are the '--sort' keys that `issue list` and `pr list` can
+// order fetched items by. Other keys, such as "popularity", can only be
+// evaluated by the API, so items sorted by them keep the order they were
+// fetched in.
+var listingSortKeys = map[string]func(a, b *github.Issue) int{
+	"created": func(a, b *github.Issue) int {
+		return compareInts(a.CreatedAt.Unix(), b.CreatedAt.Unix())
+	},
+	"updated": func(a, b *github.Issue) int {
+		return compareInts(a.UpdatedAt.Unix(), b.UpdatedAt.Unix())
+	},
+	"comments": func(a, b *github.Issue) int {
+		return compareInts(int64(a.Comments), int64(b.Comments))
+	},
+	"number": func(a, b *github.Issue) int {
+		return compareInts(int64(a.Number), int64(b.Number))
+	},
+}
+
+// listingSortAscending tells the direction of '--sort' from '--direction', or
+// else from '--sort-ascending', which is short for '--direction asc'.
+func listingSortAscending(args *Args) (bool, error) {
+	if !args.Flag.HasReceived("--direction") {
+		return args.Flag.Bool("--sort-ascending"), nil
+	}
+	direction := args.Flag.Value("--direction")
+	if args.Flag.Bool("--sort-ascending") && direction != "asc" {
+		return false, fmt.Errorf("the '--sort-ascending' and '--direction %s' options are mutually exclusive", direction)
+	}
+	return direction == "asc", nil
+}
+
+func compareInts(a, b int64) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	default:
+		return 0
+	}
+}
+
+// sortListing orders the items of a listing by key, descending unless
+// ascending is set, and breaks ties by descending number. It is applied after
+// all pages have been fetched and filtered so that items sharing a timestamp
+// always come out in the same order. The issue function returns the item at
+// an index of items.
+func sortListing(items interface{}, issue func(i int) *github.Issue, key string, ascending bool) {
+	compare := listingSortKeys[key]
+	if compare == nil {
+		return
+	}
+	sort.SliceStable(items, func(i, j int) bool {
+		a, b := issue(i), issue(j)
+		if c := compare(a, b); c != 0 {
+			return (c < 0) == ascending
+		}
+		return a.Number > b.Number
+	})
+}
+
+func sortIssues(issues []github.Issue, key string, ascending bool) {
+	sortListing(issues, func(i int) *github.Issue { return &issues[i] }, key, ascending)
+}
+
+func sortPullRequests(pulls []github.PullRequest, key string, ascending bool) {
+	sortListing(pulls, func(i int) *github.Issue { return (*github.Issue)(&pulls[i]) }, key, ascending)
+}
@@ -0,0 +1,82 @@
+package commands
+
+import (
+	"math/rand"
+	"testing"
+	"time"
+
+	"github.com/bmizerany/assert"
+	"github.com/github/hub/github"
+	"github.com/github/hub/utils"
+)
+
+func sortedNumbers(issues []github.Issue, key string, ascending bool) []int {
+	shuffled := append([]github.Issue{}, issues...)
+	rand.Shuffle(len(shuffled), func(i, j int) {
+		shuffled[i], shuffled[j] = shuffled[j], shuffled[i]
+	})
+	sortIssues(shuffled, key, ascending)
+
+	numbers := []int{}
+	for _, issue := range shuffled {
+		numbers = append(numbers, issue.Number)
+	}
+	return numbers
+}
+
+func TestSortIssues(t *testing.T) {
+	day := func(d int) time.Time {
+		return time.Date(2020, 1, d, 12, 0, 0, 0, time.UTC)
+	}
+	issues := []github.Issue{
+		{Number: 3, CreatedAt: day(1), UpdatedAt: day(9), Comments: 2},
+		{Number: 7, CreatedAt: day(2), UpdatedAt: day(9), Comments: 0},
+		{Number: 5, CreatedAt: day(2), UpdatedAt: day(8), Comments: 2},
+		{Number: 12, CreatedAt: day(4), UpdatedAt: day(9), Comments: 1},
+	}
+
+	for i := 0; i < 20; i++ {
+		assert.Equal(t, []int{12, 7, 5, 3}, sortedNumbers(issues, "created", false))
+		assert.Equal(t, []int{3, 7, 5, 12}, sortedNumbers(issues, "created", true))
+		assert.Equal(t, []int{12, 7, 3, 5}, sortedNumbers(issues, "updated", false))
+		assert.Equal(t, []int{5, 12, 7, 3}, sortedNumbers(issues, "updated", true))
+		assert.Equal(t, []int{5, 3, 12, 7}, sortedNumbers(issues, "comments", false))
+		assert.Equal(t, []int{12, 7, 5, 3}, sortedNumbers(issues, "number", false))
+		assert.Equal(t, []int{3, 5, 7, 12}, sortedNumbers(issues, "number", true))
+	}
+}
+
+func TestSortPullRequestsKeepsOrderOfUnknownKeys(t *testing.T) {
+	pulls := []github.PullRequest{{Number: 2}, {Number: 9}, {Number: 4}}
+	sortPullRequests(pulls, "popularity", false)
+	assert.Equal(t, 2, pulls[0].Number)
+	assert.Equal(t, 9, pulls[1].Number)
+	assert.Equal(t, 4, pulls[2].Number)
+}
+
+func TestListingSortAscending(t *testing.T) {
+	ascending := func(flags ...string) (bool, error) {
+		p := utils.NewArgsParserWithUsage(cmdIssue.KnownFlags)
+		_, err := p.Parse(flags)
+		assert.Equal(t, nil, err)
+		return listingSortAscending(&Args{Flag: p})
+	}
+
+	for _, c := range []struct {
+		flags []string
+		want  bool
+	}{
+		{nil, false},
+		{[]string{"-^"}, true},
+		{[]string{"--direction", "asc"}, true},
+		{[]string{"--direction", "desc"}, false},
+		{[]string{"-^", "--direction", "asc"}, true},
+	} {
+		got, err := ascending(c.flags...)
+		assert.Equal(t, nil, err)
+		assert.Equal(t, c.want, got)
+	}
+
+	_, err := ascending("-^", "--direction", "desc")
+	assert.Equal(t, "the '--sort-ascending' and '--direction desc' options are mutually exclusive", err.Error())
+}
@@ -0,0 +1,147 @@
+package commands
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/github/hub/github"
+	"github.com/github/hub/ui"
+	"github.com/github/hub/utils"
+)
+
+var (
+	cmdOrg = &Command{
+		Run: org,
+		Usage: `
+org members [--role <ROLE>] [--2fa-disabled] [--detailed] <ORG>
+org members --check <LOGIN> [--role <ROLE>] <ORG>
+`,
+		Long: `Inspect the membership of a GitHub organization.
+
+## Commands:
+
+	* _members_:
+		List the logins of members of <ORG>, one per line.
+
+## Options:
+
+	--role <ROLE>
+		Only list members with <ROLE> in the organization: "admin" or "member".
+
+	--2fa-disabled
+		Only list members that haven't enabled two-factor authentication. This is
+		only available to owners of <ORG>.
+
+	--detailed
+		Look up each member to list their name next to their login.
+
+	--check <LOGIN>
+		Instead of listing members, exit with status 0 if <LOGIN> is a member of
+		<ORG> and with status 1 otherwise.
+
+	<ORG>
+		The login of the organization.
+
+Private members are only visible to members of <ORG> whose access token has
+the "read:org" scope. Rather than silently leaving them out, hub aborts when
+GitHub would only disclose the public members.
+
+## Examples:
+		$ hub org members --role admin acme
+
+		$ hub org members --check octocat acme && echo "octocat is in acme"
+
+## See also:
+
+hub-team(1), hub(1)
+`,
+	}
+
+	cmdOrgMembers = &Command{
+		Key: "members",
+		Run: listOrgMembers,
+		KnownFlags: `
+		--role ROLE
+		--2fa-disabled
+		--detailed
+		--check LOGIN
+`,
+	}
+)
+
+func init() {
+	cmdOrg.Use(cmdOrgMembers)
+	CmdRunner.Use(cmdOrg)
+}
+
+func org(cmd *Command, args *Args) {
+	utils.Check(cmd.UsageError(""))
+}
+
+func listOrgMembers(cmd *Command, args *Args) {
+	if args.ParamsSize() != 1 {
+		utils.Check(cmd.UsageError(""))
+	}
+	orgName := args.GetParam(0)
+
+	filter := github.MembersFilter{
+		Role:              args.Flag.Value("--role"),
+		TwoFactorDisabled: args.Flag.Bool("--2fa-disabled"),
+	}
+	if filter.Role != "" && filter.Role != "admin" && filter.Role != "member" {
+		utils.Check(cmd.UsageError(fmt.Sprintf("invalid role %q", filter.Role)))
+	}
+	login := args.Flag.Value("--check")
+	if login != "" && (filter.TwoFactorDisabled || args.Flag.Bool("--detailed")) {
+		utils.Check(cmd.UsageError("'--check' can only be combined with '--role'"))
+	}
+
+	args.NoForward()
+	if args.Noop {
+		ui.Printf("Would request the list of members of %s\n", orgName)
+		return
+	}
+
+	gh := github.NewClient(authHost())
+	if login != "" {
+		isMember, err := gh.IsOrgMember(orgName, login, filter.Role)
+		utils.Check(err)
+		exitMembershipCheck(isMember)
+	}
+
+	members, err := gh.FetchOrgMembers(orgName, filter)
+	utils.Check(err)
+	printMembers(gh, members, args.Flag.Bool("--detailed"))
+}
+
+// exitMembershipCheck ends the command with the outcome of '--check'.
+func exitMembershipCheck(isMember bool) {
+	if isMember {
+		os.Exit(utils.ExitSuccess)
+	}
+	os.Exit(utils.ExitError)
+}
+
+func printMembers(gh *github.Client, members []github.Member, detailed bool) {
+	if !detailed {
+		for _, member := range members {
+			ui.Println(member.Login)
+		}
+		return
+	}
+
+	logins := make([]string, len(members))
+	loginWidth := 0
+	for i, member := range members {
+		logins[i] = member.Login
+		if len(member.Login) > loginWidth {
+			loginWidth = len(member.Login)
+		}
+	}
+
+	users, err := gh.FetchUsers(logins)
+	utils.Check(err)
+	for _, user := range users {
+		ui.Printf("%-*s  %s\n", loginWidth, user.Login, user.Name)
+	}
+}
@@ -0,0 +1,110 @@
+package commands
+
+import (
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+
+	"github.com/github/hub/cmd"
+	"github.com/github/hub/git"
+	"github.com/github/hub/github"
+	"github.com/github/hub/ui"
+)
+
+// pluginPrefix starts the names of executables on PATH that hub runs as its
+// own commands, e.g. "hub-triage" for 'hub triage'.
+const pluginPrefix = "hub-"
+
+// findPlugin returns the path of the executable that provides the command
+// name, or an empty string if there's none.
+func findPlugin(name string) string {
+	if name == "" || strings.HasPrefix(name, "-") || strings.ContainsAny(name, `/\=`) {
+		return ""
+	}
+	path, err := exec.LookPath(pluginPrefix + name)
+	if err != nil {
+		return ""
+	}
+	return path
+}
+
+// discoverPlugins lists the names of the commands that executables on PATH
+// provide, except those that built-in commands of hub or git take precedence
+// over.
+func discoverPlugins() []string {
+	seen := map[string]bool{}
+	names := []string{}
+	for _, dir := range filepath.SplitList(os.Getenv("PATH")) {
+		entries, err := ioutil.ReadDir(dir)
+		if err != nil {
+			continue
+		}
+		for _, entry := range entries {
+			name := entry.Name()
+			if !strings.HasPrefix(name, pluginPrefix) || entry.IsDir() {
+				continue
+			}
+			if runtime.GOOS == "windows" {
+				name = strings.TrimSuffix(name, filepath.Ext(name))
+			} else if entry.Mode()&0111 == 0 {
+				continue
+			}
+			name = strings.TrimPrefix(name, pluginPrefix)
+			if name == "" || seen[name] {
+				continue
+			}
+			seen[name] = true
+			if !isBuiltInHubCommand(name) && !git.IsBuiltInGitCommand(name) {
+				names = append(names, name)
+			}
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// warnShadowedPlugin points out the plugin at path, if any, that can't be run
+// because a built-in command has the same name.
+func warnShadowedPlugin(path, name string) {
+	if path != "" {
+		ui.Errorf("warning: ignoring %s since it has the same name as the built-in `%s' command\n", path, name)
+	}
+}
+
+// runPlugin replaces hub with the plugin executable at path, passing on the
+// arguments that followed the command name.
+func runPlugin(path string, args *Args) error {
+	setPluginEnv()
+	return cmd.NewWithArray(append([]string{path}, args.Params...)).Run()
+}
+
+func setPluginEnv() {
+	for name, value := range pluginEnv() {
+		os.Setenv(name, value)
+	}
+}
+
+// pluginEnv describes the current repository to plugins. The access token is
+// only shared if the "hub.exposeTokenToPlugins" git config is true.
+func pluginEnv() map[string]string {
+	env := map[string]string{
+		"HUB_HOST": github.DefaultGitHubHost(),
+	}
+	if localRepo, err := github.LocalRepo(); err == nil {
+		if project, err := localRepo.MainProject(); err == nil {
+			env["HUB_REPO"] = project.Owner + "/" + project.Name
+			env["HUB_HOST"] = project.Host
+			env["HUB_DEFAULT_BRANCH"] = localRepo.MasterBranch().ShortName()
+		}
+	}
+	if expose, _ := git.Config("hub.exposeTokenToPlugins"); expose == "true" {
+		if host := github.CurrentConfig().Find(env["HUB_HOST"]); host != nil {
+			env["HUB_TOKEN"] = host.AccessToken
+		}
+	}
+	return env
+}
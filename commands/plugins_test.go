@@ -0,0 +1,36 @@
+package commands
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/bmizerany/assert"
+)
+
+func TestPlugins(t *testing.T) {
+	dir, err := ioutil.TempDir("", "hub-plugins")
+	assert.Equal(t, nil, err)
+	defer os.RemoveAll(dir)
+
+	for name, mode := range map[string]os.FileMode{
+		"hub-triage": 0755,
+		"hub-pr":     0755,
+		"hub-log":    0755,
+		"hub-notes":  0644,
+	} {
+		err := ioutil.WriteFile(filepath.Join(dir, name), []byte("#!/bin/sh\n"), mode)
+		assert.Equal(t, nil, err)
+	}
+
+	defer os.Setenv("PATH", os.Getenv("PATH"))
+	os.Setenv("PATH", dir+string(os.PathListSeparator)+os.Getenv("PATH"))
+
+	assert.Equal(t, filepath.Join(dir, "hub-triage"), findPlugin("triage"))
+	assert.Equal(t, "", findPlugin("notes"))
+	assert.Equal(t, "", findPlugin("../hub-triage"))
+	assert.Equal(t, "", findPlugin("--triage"))
+
+	assert.Equal(t, []string{"triage"}, discoverPlugins())
+}
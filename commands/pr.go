@@ -2,9 +2,13 @@ package commands
 
 import (
 	"fmt"
+	"os"
 	"strconv"
 	"strings"
+	"time"
 
+	"github.com/github/hub/diff"
+	"github.com/github/hub/git"
 	"github.com/github/hub/github"
 	"github.com/github/hub/ui"
 	"github.com/github/hub/utils"
@@ -14,8 +18,23 @@ var (
 	cmdPr = &Command{
 		Run: printHelp,
 		Usage: `
-pr list [-s <STATE>] [-h <HEAD>] [-b <BASE>] [-o <SORT_KEY> [-^]] [-f <FORMAT>] [-L <LIMIT>]
-pr checkout <PR-NUMBER> [<BRANCH>]
+pr list [-s <STATE>] [-h <HEAD>] [-b <BASE>] [-l <LABELS>] [--exclude-author <USER>] [--exclude-assignee <USER>] [--ready-to-merge] [-o <SORT_KEY> [-^|--direction <DIRECTION>]] [-f <FORMAT>] [-L <LIMIT>] [--watch[=<INTERVAL>]] [--count-only] [--output <FORMAT> [--columns <COLUMNS>]] [--filter <NAME>] [--save-filter <NAME>]
+pr list --list-filters
+pr list --delete-filter <NAME>
+pr checkout [--notes] [--force] [--protect] <PR-NUMBER>|<PR-URL> [<BRANCH>]
+pr checkout --unprotect <BRANCH>
+pr show [-f <FORMAT>|--json[=<FIELDS>]|--web] [-q <PATH>] <PR-NUMBER>
+pr show --threads [--unresolved-only] [--fail-unresolved] <PR-NUMBER>
+pr diff [-w] [--stat] <PR-NUMBER> [-- <PATH>...]
+pr review-comment <PR-NUMBER> --path <FILE> --line <LINE> [--start-line <LINE>] [--side <SIDE>] [-m <MESSAGE>|-F <FILE>]
+pr review-comment <PR-NUMBER> --in-reply-to <COMMENT-ID> [-m <MESSAGE>|-F <FILE>]
+pr merge [--merge|--squash|--rebase] [-m <MESSAGE>] [--delete-branch] [--watch[=<INTERVAL>] [--notify]] <PR-NUMBER>|<PR-URL>|<BRANCH>
+pr merge --dequeue <PR-NUMBER>|<PR-URL>|<BRANCH>
+pr merge --auto [--merge|--squash|--rebase] <PR-NUMBER>|<PR-URL>|<BRANCH>
+pr merge --disable-auto <PR-NUMBER>|<PR-URL>|<BRANCH>
+pr ready <PR-NUMBER>
+pr number [<BRANCH>]
+pr find [-o] <SHA>
 `,
 		Long: `Manage GitHub Pull Requests for the current repository.
 
@@ -25,7 +44,74 @@ pr checkout <PR-NUMBER> [<BRANCH>]
 		List pull requests in the current repository.
 
 	* _checkout_:
-		Check out the head of a pull request in a new branch.
+		Check out the head of a pull request in a new branch. The number, title,
+		URL and labels of the pull request are recorded as the description of
+		the branch, which is how 'hub pull-request' recognizes branches that
+		already have a pull request.
+
+		The branch is named after the head branch of the pull request unless
+		<BRANCH> is given. Checking out the same pull request again fast-forwards
+		the branch. Given the URL of a pull request to a repository that no git
+		remote points to, the fork it comes from is added as a remote named
+		after its owner. The branch is set up to push back to that fork if the
+		author allows maintainers to modify the pull request.
+
+	* _show_:
+		Show the title, description and comments of a pull request, or print its
+		fields with '--format' or '--json'. For an open pull request, also show
+		how many commits its head is ahead of and behind the base branch. Images
+		are shown as "[image: <ALT-TEXT>]" placeholders followed by their URL.
+
+		With '--threads', also show the review threads of the pull request. Each
+		thread is listed under the "<PATH>:<LINE>" it's about, with replies
+		indented below the comment that started it, followed by a summary of how
+		many threads are unresolved.
+
+	* _diff_:
+		Print the changes of a pull request as a unified diff, or summarize them
+		with '--stat'. Given <PATH> arguments, only the files at those paths, in
+		those directories, or matching those shell globs are included, whether
+		before or after a rename. The diff is fetched whole from GitHub and
+		filtered locally.
+
+	* _review-comment_:
+		Comment on lines of a file changed in a pull request, or reply to an
+		existing review comment. The lines must be part of the diff of the pull
+		request. Unless a message is given, a text editor opens for writing the
+		comment.
+
+	* _merge_:
+		Merge a pull request on GitHub. A draft or a pull request that conflicts
+		with its base branch is refused. The pull request may be given as a
+		number, a URL, or the name of the branch that it was opened from.
+
+		With '--auto', enable auto-merge for the pull request instead, so that
+		GitHub merges it once the required reviews and checks of its base branch
+		pass, or cancel it with '--disable-auto'. A pull request that already
+		meets the requirements gets merged right away. Auto-merge has to be
+		allowed in the settings of the repository.
+
+		When the rules of the base branch require a merge queue, the pull
+		request is added to the queue instead, which merges it with the method
+		that the queue is set up with. Remove it from the queue with
+		'--dequeue'.
+
+	* _ready_:
+		Mark a draft pull request as ready for review. Drafts are opened with
+		'hub pull-request --draft'.
+
+	* _number_:
+		Print the number of the pull request opened from <BRANCH> (default:
+		the current branch), or exit with status 1 if there is none. The pull
+		request is looked up in the fork that the branch is pushed to according
+		to its push remote or upstream, or else in the fork of the authenticated
+		user. Open pull requests are preferred, then the most recent one.
+
+	* _find_:
+		List the pull requests that introduced a commit, such as one reported by
+		git-blame(1), with their number, state, title and URL. A commit that was
+		cherry-picked can be part of several pull requests. <SHA> may be
+		abbreviated.
 
 ## Options:
 
@@ -40,6 +126,134 @@ pr checkout <PR-NUMBER> [<BRANCH>]
 	-b, --base <BRANCH>
 		Show pull requests based off the specified <BRANCH>.
 
+	-l, --labels <LABELS>
+		Show only pull requests with all of the comma-separated <LABELS>. Labels
+		prefixed with "!" are excluded instead, e.g. '--labels !wontfix'.
+
+	--exclude-author <USER>
+		Leave out pull requests opened by <USER>. Can be given multiple times or
+		as a comma-separated list.
+
+	--exclude-assignee <USER>
+		Leave out pull requests assigned to <USER>. Can be given multiple times or
+		as a comma-separated list.
+
+	--ready-to-merge
+		Show only open pull requests that are ready to be merged; see "%req".
+		Several pull requests are evaluated at a time.
+
+	--notes
+		When checking out, also add the description of the pull request as a git
+		note to its head commit. See git-notes(1).
+
+	--force
+		When checking out, replace an existing description of the branch or note
+		on the head commit.
+
+	--protect
+		When checking out a pull request from a fork, push the branch to the fork
+		by default and refuse to push it to the remote of the base repository.
+		The refusal is enforced by a pre-push hook that hub installs in the
+		repository. Pushes of the branch from the current HEAD are covered, e.g.
+		'git push origin HEAD:main'.
+
+	--unprotect <BRANCH>
+		Allow pushing <BRANCH> to the remote of the base repository again. The
+		pre-push hook is deleted once no branches are protected. hub-sync(1)
+		also deletes it after protected branches were deleted.
+
+	--json[=<FIELDS>]
+		When showing a pull request, print it as a JSON object instead. <FIELDS>
+		is a comma-separated list of the fields to include, written in camel case
+		or snake case (default: all fields). In addition to the fields of
+		hub-issue(1), the available fields are: "isDraft", "headRefName",
+		"headRefOid", "headRepository", "baseRefName", "baseRefOid", "mergedAt",
+		"mergeCommitOid", "maintainerCanModify", and "reviewRequests".
+
+	-q, --query <PATH>
+		When showing a pull request, print only the value of a field at the
+		dot-separated <PATH> of its JSON object, e.g. "headRefName". Strings are
+		printed without quotes.
+
+	--web
+		When showing a pull request, open it in a web browser instead.
+
+	--threads
+		When showing a pull request, include its review threads. Resolved
+		threads are tagged "[resolved]".
+
+	--unresolved-only
+		Leave resolved review threads out of '--threads'. They are still
+		counted in the summary.
+
+	--fail-unresolved
+		Exit with status 1 if any review thread of the pull request is
+		unresolved, e.g. to keep CI from passing until all are resolved.
+
+	--path <FILE>
+		The file to comment on, relative to the root of the repository.
+
+	--line <LINE>
+		The line number to comment on, or the last line of a multi-line comment.
+
+	--start-line <LINE>
+		The first line of a multi-line comment. It must be in the same hunk of the
+		diff as '--line'.
+
+	--side <SIDE>
+		Comment on the "RIGHT" (default) side of the diff, which is the new version
+		of the file, or on the "LEFT" side with the lines that were deleted.
+
+	--in-reply-to <COMMENT-ID>
+		Reply to the review comment with the ID <COMMENT-ID> instead of starting a
+		new thread.
+
+	-m, --message <MESSAGE>
+		The text of the review comment. Multiple '-m' values are joined by a blank
+		line.
+
+		With _merge_, the message of the merge commit, where the first block of
+		text is the title and the rest is the description.
+
+	-F, --file <FILE>
+		Read the text of the review comment from <FILE>. Pass "-" to read from
+		standard input instead.
+
+	--auto
+		Enable auto-merge for the pull request.
+
+	--disable-auto
+		Cancel auto-merge of the pull request.
+
+	--merge, --squash, --rebase
+		The method to merge the pull request with (default: '--merge'). The
+		method has to be allowed in the settings of the repository.
+
+	--delete-branch
+		After merging, delete the head branch of the pull request, unless it
+		belongs to another repository. For a pull request in a merge queue, this
+		requires '--watch'.
+
+	--dequeue
+		Remove the pull request from the merge queue of its base branch.
+
+	--notify
+		With _merge_ and '--watch', show a desktop notification once the pull
+		request has left the merge queue, like 'hub ci-status --notify' does.
+
+	-w, --ignore-whitespace
+		With _diff_, leave out the hunks that only change whitespace, and the
+		files that are left without changes.
+
+	--stat
+		With _diff_, print the number of changed lines of each file and a graph
+		of the added and deleted ones, like 'git diff --stat', instead of the
+		diff itself.
+
+	-o, --browse
+		With _find_, open the first merged pull request in a web browser, or the
+		first pull request if none of them was merged.
+
 	-f, --format <FORMAT>
 		Pretty print the list of pull requests using format <FORMAT> (default:
 		"%pC%>(8)%i%Creset  %t%  l%n"). See the "PRETTY FORMATS" section of
@@ -78,12 +292,39 @@ pr checkout <PR-NUMBER> [<BRANCH>]
 
 		%sm: merge commit SHA
 
+		%ab: number of commits ahead of and behind the base branch, such as
+		"+3 -12". Commits are counted locally when the base branch and the head
+		commit have been fetched, or with an additional API request for each
+		pull request otherwise.
+
 		%au: login name of author
 
 		%as: comma-separated list of assignees
 
 		%rs: comma-separated list of requested reviewers
 
+		%cs: combined state of the checks of the head commit ("success",
+		"pending", "failure" and so on, as reported by hub-ci-status(1))
+
+		%cC: set color according to the state of the checks
+
+		%rd: review decision ("APPROVED", "CHANGES_REQUESTED" or
+		"REVIEW_REQUIRED"), or blank string if no review is required
+
+		%rC: set color according to the review decision
+
+		The checks and review decisions of up to 50 pull requests are looked up
+		with a single additional API request.
+
+		%req: whether an open pull request is ready to be merged: "ready",
+		"blocked: checks" if a status check that the protection of the base
+		branch requires hasn't passed, "blocked: reviews" if the review decision
+		is anything but "APPROVED", or otherwise what keeps GitHub from merging
+		it: "conflicts" with the base branch, "behind" the base branch, "draft",
+		"blocked" by other rules, or "pending" while GitHub is still working it
+		out. The signals are checked in that order with additional API requests
+		for each pull request, stopping at the first one that blocks it.
+
 		%Mn: milestone number
 
 		%Mt: milestone title
@@ -125,13 +366,79 @@ pr checkout <PR-NUMBER> [<BRANCH>]
 		of "always" (default for '--color'), "never", or "auto" (default).
 
 	-o, --sort <KEY>
-		Sort displayed issues by "created" (default), "updated", "popularity", or "long-running".
+		Sort displayed pull requests by "created" (default), "updated",
+		"popularity", "long-running", "comments", "reactions", or "number". Pull
+		requests that are equal by <KEY> are ordered by descending number.
+		"comments" is the same as "popularity". Sorting by "reactions" goes
+		through the search API, which can't tell the order of pull requests with
+		the same number of reactions, and lists them without their head and base
+		branches.
 
 	-^, --sort-ascending
-		Sort by ascending dates instead of descending.
+		Sort in ascending order instead of descending.
+
+	--direction <DIRECTION>
+		Sort in "asc" (ascending) or "desc" (descending, default) order.
 
 	-L, --limit <LIMIT>
-		Display only the first <LIMIT> issues.
+		Display only the first <LIMIT> pull requests. When this leaves out some
+		of the matching pull requests, a dimmed "Showing X of Y open pull
+		requests" footer follows the list on a terminal. With '--format',
+		'--output', or when the output is piped, a "showing X of Y" note is
+		printed to standard error instead.
+
+	--count-only
+		Print only the number of matching pull requests.
+
+	--output <FORMAT>
+		Print the list of pull requests as a table in <FORMAT>, either
+		"markdown", "html", or "csv", e.g. for pasting into a status report. Pull
+		request numbers link to the pull requests on GitHub except in CSV.
+
+	--columns <COLUMNS>
+		A comma-separated list of the columns to include with '--output'
+		(default: "number,title,assignee,labels,age"). The available columns are:
+		"number", "title", "state", "author", "assignee", "labels", "milestone",
+		"age", "created", "updated", and "url".
+
+	--save-filter <NAME>
+		Save the filtering, sorting, and format options of the listing under
+		<NAME> in the global git config, to be reused with '--filter'. The saved
+		options are '--state', '--head', '--base', '--labels',
+		'--exclude-author', '--exclude-assignee', '--ready-to-merge', '--sort',
+		'--sort-ascending', '--direction', and '--format'. Filters are shared
+		with 'hub issue'.
+
+	--filter <NAME>
+		List pull requests with the options saved as <NAME>. Options given on
+		the command line take precedence over the saved ones. Saved options that
+		only apply to issues, such as '--assignee', are ignored with a note.
+
+	--list-filters
+		List the names of the saved filters with their options.
+
+	--delete-filter <NAME>
+		Delete the saved filter <NAME>.
+
+	--watch[=<INTERVAL>]
+		Keep refreshing the list every <INTERVAL> until "q" or Ctrl-C is
+		pressed. <INTERVAL> is a number of seconds or a duration such as "5m"
+		(default: 30). Pull requests that are new since the previous refresh are
+		marked with "+", and those whose state, head commit, checks or review
+		decision has changed are marked with "~".
+
+		With _merge_, after adding the pull request to a merge queue, check its
+		position in the queue every <INTERVAL> until it leaves the queue. The
+		exit status is 0 if it was merged and 1 if it was removed from the queue
+		without being merged. It's an error when the base branch has no merge
+		queue.
+
+## Configuration:
+
+	* 'hub.protectPrCheckouts':
+		When "true", _checkout_ behaves as if '--protect' was given. Checkouts
+		go ahead unprotected with a warning if the repository already has a
+		pre-push hook that hub didn't install.
 
 ## See also:
 
@@ -140,21 +447,143 @@ hub-issue(1), hub-pull-request(1), hub(1)
 	}
 
 	cmdCheckoutPr = &Command{
-		Key:        "checkout",
-		Run:        checkoutPr,
-		KnownFlags: "\n",
+		Key: "checkout",
+		Run: checkoutPr,
+		KnownFlags: `
+		--notes
+		--force
+		--protect
+		--unprotect BRANCH
+`,
+	}
+
+	cmdShowPr = &Command{
+		Key: "show",
+		Run: showPr,
+		KnownFlags: `
+		-f, --format FMT
+		--json
+		-q, --query PATH
+		--color
+		--web
+		--threads
+		--unresolved-only
+		--fail-unresolved
+`,
+		FlagValues: map[string]flagValue{
+			"--color": colorValue,
+		},
+	}
+
+	cmdDiffPr = &Command{
+		Key: "diff",
+		Run: diffPr,
+		KnownFlags: `
+		-w, --ignore-whitespace
+		--stat
+`,
+	}
+
+	cmdReviewComment = &Command{
+		Key: "review-comment",
+		Run: createReviewComment,
+		KnownFlags: `
+		--path FILE
+		--line LINE
+		--start-line LINE
+		--side SIDE
+		--in-reply-to ID
+		-m, --message MSG
+		-F, --file FILE
+`,
+	}
+
+	cmdMergePr = &Command{
+		Key: "merge",
+		Run: mergePr,
+		KnownFlags: `
+		--auto
+		--disable-auto
+		--merge
+		--squash
+		--rebase
+		-m, --message MSG
+		--delete-branch
+		--dequeue
+		--watch
+		--notify
+`,
+		FlagValues: map[string]flagValue{
+			"--watch": durationValue(),
+		},
+	}
+
+	cmdReadyPr = &Command{
+		Key: "ready",
+		Run: readyPr,
+	}
+
+	cmdNumberPr = &Command{
+		Key: "number",
+		Run: numberPr,
+	}
+
+	cmdFindPr = &Command{
+		Key: "find",
+		Run: findPr,
+		KnownFlags: `
+		-o, --browse
+`,
 	}
 
 	cmdListPulls = &Command{
 		Key:  "list",
 		Run:  listPulls,
 		Long: cmdPr.Long,
+		KnownFlags: `
+		-s, --state STATE
+		-h, --head BRANCH
+		-b, --base BRANCH
+		-l, --labels LIST
+		--exclude-author USER
+		--exclude-assignee USER
+		--ready-to-merge
+		-f, --format FMT
+		-o, --sort KEY
+		-^, --sort-ascending
+		--direction DIRECTION
+		-L, --limit N
+		--color
+		--watch
+		--count-only
+		--output FORMAT
+		--columns LIST
+		--filter NAME
+		--save-filter NAME
+		--list-filters
+		--delete-filter NAME
+`,
+		FlagValues: map[string]flagValue{
+			"--state":     enumValue("open", "closed", "merged", "all"),
+			"--sort":      enumValue("created", "updated", "popularity", "long-running", "comments", "reactions", "number"),
+			"--direction": enumValue("asc", "desc"),
+			"--limit":     limitValue,
+			"--color":     colorValue,
+			"--watch":     durationValue(),
+		},
 	}
 )
 
 func init() {
 	cmdPr.Use(cmdListPulls)
 	cmdPr.Use(cmdCheckoutPr)
+	cmdPr.Use(cmdShowPr)
+	cmdPr.Use(cmdDiffPr)
+	cmdPr.Use(cmdReviewComment)
+	cmdPr.Use(cmdMergePr)
+	cmdPr.Use(cmdReadyPr)
+	cmdPr.Use(cmdNumberPr)
+	cmdPr.Use(cmdFindPr)
 	CmdRunner.Use(cmdPr)
 }
 
@@ -162,7 +591,105 @@ func printHelp(command *Command, args *Args) {
 	utils.Check(command.UsageError(""))
 }
 
+func showPr(cmd *Command, args *Args) {
+	if args.ParamsSize() != 1 {
+		utils.Check(cmd.UsageError(""))
+	}
+	prNumber := args.GetParam(0)
+
+	localRepo, err := github.LocalRepo()
+	utils.Check(err)
+
+	project, err := localRepo.MainProject()
+	utils.Check(err)
+
+	showThreads := args.Flag.Bool("--threads")
+	for _, flag := range []string{"--unresolved-only", "--fail-unresolved"} {
+		if args.Flag.Bool(flag) && !showThreads {
+			utils.Check(cmd.UsageError(fmt.Sprintf("the '%s' option requires '--threads'", flag)))
+		}
+	}
+	if showThreads {
+		for _, flag := range []string{"--format", "--json", "--query", "--web"} {
+			if args.Flag.HasReceived(flag) {
+				utils.Check(cmd.UsageError(fmt.Sprintf("the '--threads' and '%s' options are mutually exclusive", flag)))
+			}
+		}
+	}
+
+	if args.Flag.Bool("--web") {
+		showInBrowser(cmd, args, project.WebURL("", "", "pull/"+prNumber))
+		return
+	}
+
+	var format *ui.Format
+	if args.Flag.HasReceived("--format") {
+		format, err = ui.CompileFormat(args.Flag.Value("--format"))
+		utils.Check(err)
+	}
+
+	jsonOut, err := parseJSONOutput(cmd, args, pullRequestJSONFields)
+	utils.Check(err)
+
+	gh := github.NewClient(project.Host)
+	pr, err := gh.PullRequest(project, prNumber)
+	utils.Check(err)
+
+	args.NoForward()
+
+	if jsonOut != nil {
+		utils.Check(jsonOut.print(github.Issue(*pr)))
+		return
+	}
+
+	colorize := colorizeOutput(args.Flag.HasReceived("--color"), args.Flag.Value("--color"))
+	if format != nil {
+		pulls := []github.PullRequest{*pr}
+		var statuses map[int]*github.PullRequestStatus
+		if usesPullRequestStatus(format) {
+			statuses = fetchPullRequestStatuses(gh, project, pulls)
+		}
+		var readiness map[int]string
+		if format.Uses("req") {
+			readiness = fetchPullRequestReadiness(gh, project, pulls)
+		}
+		ui.Print(formatPullRequest(*pr, format, colorize, hyperlinksEnabled(), lazyPullRequestPlaceholders(gh, localRepo, *pr, format, statuses, readiness, colorize)))
+		return
+	}
+
+	var titlePrefix string
+	if state := pullRequestState(*pr); state != "open" {
+		titlePrefix = fmt.Sprintf("[%s] ", strings.ToUpper(state))
+	}
+	details := []string{fmt.Sprintf("merges %s into %s", pr.Head.Label, pr.Base.Ref)}
+	if pr.State == "open" {
+		if ab, err := pullRequestAheadBehind(gh, localRepo, *pr); err == nil {
+			details = append(details, fmt.Sprintf("%d commits ahead, %d behind %s", ab.ahead, ab.behind, pr.Base.Ref))
+		}
+	}
+	if pr.ChangedFiles > 0 {
+		details = append(details, fmt.Sprintf("%s changed, +%s -%s lines", pluralize(pr.ChangedFiles, "file"), utils.HumanCount(pr.Additions), utils.HumanCount(pr.Deletions)))
+	}
+	if reviewers := userLogins(pr.RequestedReviewers); len(reviewers) > 0 {
+		details = append(details, "requested reviewers: "+strings.Join(reviewers, ", "))
+	}
+	showIssueText(gh, project, github.Issue(*pr), titlePrefix, details)
+
+	if showThreads {
+		threads, err := gh.FetchReviewThreads(project, pr.Number)
+		utils.Check(err)
+		ui.Print(formatReviewThreads(threads, args.Flag.Bool("--unresolved-only"), colorize))
+		if args.Flag.Bool("--fail-unresolved") && countUnresolved(threads) > 0 {
+			os.Exit(utils.ExitError)
+		}
+	}
+}
+
 func listPulls(cmd *Command, args *Args) {
+	if handleSavedFilters(cmd, args, "pr list") {
+		return
+	}
+
 	localRepo, err := github.LocalRepo()
 	utils.Check(err)
 
@@ -171,6 +698,9 @@ func listPulls(cmd *Command, args *Args) {
 
 	gh := github.NewClient(project.Host)
 
+	export, err := parseListingExport(cmd, args)
+	utils.Check(err)
+
 	args.NoForward()
 	if args.Noop {
 		ui.Printf("Would request list of pull requests for %s\n", project)
@@ -181,8 +711,17 @@ func listPulls(cmd *Command, args *Args) {
 	if args.Flag.HasReceived("--state") {
 		filters["state"] = args.Flag.Value("--state")
 	}
+	sortKey := "created"
 	if args.Flag.HasReceived("--sort") {
-		filters["sort"] = args.Flag.Value("--sort")
+		sortKey = args.Flag.Value("--sort")
+	}
+	// the pull requests endpoint sorts by comments as "popularity", and only
+	// the search API can sort by reactions
+	if sortKey == "comments" {
+		sortKey = "popularity"
+	}
+	if args.Flag.HasReceived("--sort") && sortKey != "number" && sortKey != "reactions" {
+		filters["sort"] = sortKey
 	}
 	if args.Flag.HasReceived("--base") {
 		filters["base"] = args.Flag.Value("--base")
@@ -195,36 +734,198 @@ func listPulls(cmd *Command, args *Args) {
 		filters["head"] = head
 	}
 
-	if args.Flag.Bool("--sort-ascending") {
+	sortAscending, err := listingSortAscending(args)
+	if err != nil {
+		utils.Check(cmd.UsageError(err.Error()))
+	}
+	if sortAscending {
 		filters["direction"] = "asc"
 	} else {
 		filters["direction"] = "desc"
 	}
 
+	filter, labels, err := parseListingFilter(args, "", "")
+	if err != nil {
+		utils.Check(cmd.UsageError(err.Error()))
+	}
+	// the pull requests endpoint can't filter by label
+	filter.labels = labels
+
 	onlyMerged := false
 	if filters["state"] == "merged" {
 		filters["state"] = "closed"
 		onlyMerged = true
 	}
 
+	if args.Flag.Bool("--count-only") {
+		count, err := countPullRequests(gh, project, filters, onlyMerged, filter)
+		utils.Check(err)
+		ui.Println(count)
+		return
+	}
+
 	flagPullRequestLimit := args.Flag.Int("--limit")
 	flagPullRequestFormat := args.Flag.Value("--format")
 	if !args.Flag.HasReceived("--format") {
 		flagPullRequestFormat = "%pC%>(8)%i%Creset  %t%  l%n"
 	}
-
-	pulls, err := gh.FetchPullRequests(project, filters, flagPullRequestLimit, func(pr *github.PullRequest) bool {
-		return !(onlyMerged && pr.MergedAt.IsZero())
-	})
+	format, err := ui.CompileFormat(flagPullRequestFormat)
 	utils.Check(err)
 
 	colorize := colorizeOutput(args.Flag.HasReceived("--color"), args.Flag.Value("--color"))
-	for _, pr := range pulls {
-		ui.Print(formatPullRequest(pr, flagPullRequestFormat, colorize))
+	hyperlinks := hyperlinksEnabled()
+
+	readyToMerge := args.Flag.Bool("--ready-to-merge")
+	var readiness map[int]string
+	fetchLimit := flagPullRequestLimit
+	if readyToMerge {
+		// which pull requests are ready is only known after fetching them
+		fetchLimit = 0
+	}
+	readyCount := 0
+	searchTotal := 0
+
+	fetchPulls := func() ([]github.PullRequest, error) {
+		var pulls []github.PullRequest
+		var err error
+		if sortKey == "reactions" {
+			pulls, searchTotal, err = searchPullRequests(gh, pullRequestSearchQuery(project, filters, onlyMerged, filter)+" "+searchSortQualifier(sortKey, sortAscending), fetchLimit)
+		} else {
+			pulls, err = gh.FetchPullRequests(project, filters, fetchLimit, func(pr *github.PullRequest) bool {
+				return !(onlyMerged && pr.MergedAt.IsZero()) && filter.matches((*github.Issue)(pr))
+			})
+		}
+		if err != nil {
+			return nil, err
+		}
+		readiness = nil
+		if readyToMerge || format.Uses("req") {
+			readiness = fetchPullRequestReadiness(gh, project, pulls)
+		}
+		if readyToMerge {
+			ready := []github.PullRequest{}
+			for _, pr := range pulls {
+				if readiness[pr.Number] == github.PullRequestReady {
+					ready = append(ready, pr)
+				}
+			}
+			pulls = ready
+			readyCount = len(pulls)
+		}
+		sortPullRequests(pulls, sortKey, sortAscending)
+		if readyToMerge && flagPullRequestLimit > 0 && len(pulls) > flagPullRequestLimit {
+			pulls = pulls[:flagPullRequestLimit]
+		}
+		return pulls, nil
+	}
+
+	watching := args.Flag.HasReceived("--watch")
+	fetchRows := func() ([]watchRow, error) {
+		pulls, err := fetchPulls()
+		if err != nil {
+			return nil, err
+		}
+
+		// a change in checks marks a pull request when watching, even if the
+		// format doesn't show them
+		var statuses map[int]*github.PullRequestStatus
+		if watching || usesPullRequestStatus(format) {
+			statuses = fetchPullRequestStatuses(gh, project, pulls)
+		}
+		rows := []watchRow{}
+		for _, pr := range pulls {
+			fingerprint := pullRequestState(pr) + " " + pr.Head.Sha
+			if status := statuses[pr.Number]; status != nil {
+				fingerprint += " " + ciState(status.Checks) + " " + status.ReviewDecision
+			}
+			rows = append(rows, watchRow{
+				key:         strconv.Itoa(pr.Number),
+				fingerprint: fingerprint,
+				text:        formatPullRequest(pr, format, colorize, hyperlinks, lazyPullRequestPlaceholders(gh, localRepo, pr, format, statuses, readiness, colorize)),
+			})
+		}
+		return rows, nil
+	}
+
+	if watching {
+		gh.UseConditionalRequests()
+		utils.Check(watchListing(watchInterval(args, "--watch"), colorize, fetchRows))
+		return
+	}
+
+	shown := 0
+	if export != nil {
+		pulls, err := fetchPulls()
+		utils.Check(err)
+		issues := []github.Issue{}
+		for _, pr := range pulls {
+			issues = append(issues, github.Issue(pr))
+		}
+		ui.Print(export.render(issues))
+		shown = len(pulls)
+	} else {
+		rows, err := fetchRows()
+		utils.Check(err)
+		for _, row := range rows {
+			ui.Print(row.text)
+		}
+		shown = len(rows)
+	}
+
+	footer := ui.IsTerminal(os.Stdout) && !args.Flag.HasReceived("--format") && export == nil
+	if readyToMerge {
+		printListingTotal(shown, readyCount, "pull requests", "", footer, colorize)
+	} else if flagPullRequestLimit > 0 && shown == flagPullRequestLimit {
+		count := searchTotal
+		if sortKey != "reactions" {
+			count, err = countPullRequests(gh, project, filters, onlyMerged, filter)
+		}
+		state := listingState(filters)
+		if onlyMerged {
+			state = "merged"
+		}
+		if err == nil {
+			printListingTotal(shown, count, "pull requests", state, footer, colorize)
+		}
+	}
+}
+
+// searchPullRequests lists the pull requests that the search API finds for
+// query, along with their total number. Search results lack the head and base
+// of pull requests, which are left blank.
+func searchPullRequests(gh *github.Client, query string, limit int) ([]github.PullRequest, int, error) {
+	issues, total, err := gh.SearchIssues(nil, query, limit)
+	pulls := []github.PullRequest{}
+	for _, issue := range issues {
+		pr := github.PullRequest(issue)
+		if pr.PullRequest != nil && pr.MergedAt.IsZero() {
+			pr.MergedAt = pr.PullRequest.MergedAt
+		}
+		if pr.Head == nil {
+			pr.Head = &github.PullRequestSpec{}
+		}
+		if pr.Base == nil {
+			pr.Base = &github.PullRequestSpec{}
+		}
+		pulls = append(pulls, pr)
 	}
+	return pulls, total, err
 }
 
 func checkoutPr(command *Command, args *Args) {
+	if args.Flag.HasReceived("--unprotect") {
+		if len(args.Words()) > 0 || args.Flag.HasReceived("--protect") {
+			utils.Check(command.UsageError("'--unprotect' is used on its own"))
+		}
+		args.NoForward()
+		if args.Noop {
+			ui.Printf("Would remove push protection from branch '%s'\n", args.Flag.Value("--unprotect"))
+			return
+		}
+		utils.Check(unprotectBranch(args.Flag.Value("--unprotect")))
+		return
+	}
+
 	words := args.Words()
 	var newBranchName string
 
@@ -234,31 +935,818 @@ func checkoutPr(command *Command, args *Args) {
 		newBranchName = words[1]
 	}
 
-	prNumberString := words[0]
-	_, err := strconv.Atoi(prNumberString)
-	utils.Check(err)
-
-	// Figure out the PR URL
 	localRepo, err := github.LocalRepo()
 	utils.Check(err)
-	baseProject, err := localRepo.MainProject()
-	utils.Check(err)
+
+	baseProject, prNumberString := parsePullRequestURL(words[0])
+	if baseProject == nil {
+		prNumberString = words[0]
+		_, err = strconv.Atoi(prNumberString)
+		utils.Check(err)
+		baseProject, err = localRepo.MainProject()
+		utils.Check(err)
+	}
 	host, err := github.CurrentConfig().PromptForHost(baseProject.Host)
 	utils.Check(err)
 	client := github.NewClientWithHost(host)
 	pr, err := client.PullRequest(baseProject, prNumberString)
 	utils.Check(err)
 
-	newArgs, err := transformCheckoutArgs(args, pr, newBranchName)
+	newArgs, branchName, err := transformCheckoutArgs(args, pr, newBranchName)
 	utils.Check(err)
 
+	force := args.Flag.Bool("--force")
+	description := pullRequestDescription(pr)
+
+	descriptionKey := fmt.Sprintf("branch.%s.description", branchName)
+	if existing, _ := git.Config(descriptionKey); existing == "" || existing == description || force {
+		args.After("git", "config", descriptionKey, description)
+	} else {
+		ui.Errorf("warning: not replacing the existing description of branch '%s' (use `--force` to replace it)\n", branchName)
+	}
+
+	if args.Flag.Bool("--notes") {
+		if !git.Quiet("notes", "show", pr.Head.Sha) || force {
+			args.After("git", "notes", "add", "-f", "-m", description, pr.Head.Sha)
+		} else {
+			ui.Errorf("warning: not replacing the existing note on %s (use `--force` to replace it)\n", pr.Head.Sha)
+		}
+	}
+
+	if args.Flag.Bool("--protect") {
+		protectCheckout(args, localRepo, pr, branchName, true)
+	} else if enabled, _ := git.Config("hub.protectPrCheckouts"); enabled == "true" {
+		protectCheckout(args, localRepo, pr, branchName, false)
+	}
+
 	args.Replace(args.Executable, "checkout", newArgs...)
 }
 
-func formatPullRequest(pr github.PullRequest, format string, colorize bool) string {
+// protectCheckout configures branchName to be pushed to the head repository
+// of pr and guards it against being pushed to the base repository. Problems
+// abort the checkout when protection was explicitly requested, and are only
+// warned about otherwise.
+func protectCheckout(args *Args, localRepo *github.GitHubRepo, pr *github.PullRequest, branchName string, explicit bool) {
+	abort := func(err error) {
+		if explicit {
+			utils.Check(fmt.Errorf("Aborted: can't protect branch '%s': %s", branchName, err))
+		}
+		ui.Errorf("warning: not protecting branch '%s': %s\n", branchName, err)
+	}
+
+	if pr.IsSameRepo() {
+		abort(fmt.Errorf("pull request #%d comes from the base repository", pr.Number))
+		return
+	}
+	baseRemote, err := localRepo.RemoteForRepo(pr.Base.Repo)
+	if err != nil {
+		abort(err)
+		return
+	}
+	if err := checkPushGuardHook(); err != nil {
+		abort(err)
+		return
+	}
+
+	if pushRemote := pullRequestPushRemote(localRepo, pr); pushRemote != "" {
+		args.After("git", "config", fmt.Sprintf("branch.%s.pushRemote", branchName), pushRemote)
+	}
+	args.After("git", "config", pushGuardConfigKey(branchName), baseRemote.Name)
+	if !args.Noop {
+		args.AfterFn(installPushGuardHook)
+	}
+}
+
+// pullRequestDescription summarizes a pull request for the description of
+// the branch that it's checked out in.
+func pullRequestDescription(pr *github.PullRequest) string {
+	lines := []string{
+		fmt.Sprintf("#%d: %s", pr.Number, pr.Title),
+		pr.HtmlUrl,
+	}
+	if len(pr.Labels) > 0 {
+		labels := []string{}
+		for _, label := range pr.Labels {
+			labels = append(labels, label.Name)
+		}
+		lines = append(lines, "Labels: "+strings.Join(labels, ", "))
+	}
+	return strings.Join(lines, "\n")
+}
+
+func diffPr(cmd *Command, args *Args) {
+	if args.ParamsSize() < 1 {
+		utils.Check(cmd.UsageError(""))
+	}
+	number, err := strconv.Atoi(args.GetParam(0))
+	if err != nil {
+		utils.Check(cmd.UsageError(fmt.Sprintf("invalid pull request number: %q", args.GetParam(0))))
+	}
+	paths := args.Params[1:]
+
+	localRepo, err := github.LocalRepo()
+	utils.Check(err)
+
+	project, err := localRepo.MainProject()
+	utils.Check(err)
+
+	gh := github.NewClient(project.Host)
+	text, err := gh.PullRequestDiff(project, number)
+	utils.Check(err)
+
+	files, err := diff.Parse(text)
+	utils.Check(err)
+	if len(paths) > 0 {
+		files = diff.FilterPaths(files, paths...)
+	}
+	if args.Flag.Bool("--ignore-whitespace") {
+		files = diff.IgnoreWhitespace(files)
+	}
+
+	args.NoForward()
+	if args.Flag.Bool("--stat") {
+		ui.Print(diff.Stat(files))
+	} else {
+		ui.Print(diff.Format(files))
+	}
+}
+
+func createReviewComment(cmd *Command, args *Args) {
+	if args.ParamsSize() != 1 {
+		utils.Check(cmd.UsageError(""))
+	}
+	number, err := strconv.Atoi(args.GetParam(0))
+	if err != nil {
+		utils.Check(cmd.UsageError(fmt.Sprintf("invalid pull request number: %q", args.GetParam(0))))
+	}
+
+	path := args.Flag.Value("--path")
+	line := args.Flag.Int("--line")
+	startLine := args.Flag.Int("--start-line")
+	side := strings.ToUpper(args.Flag.Value("--side"))
+	replyTo := args.Flag.Int("--in-reply-to")
+
+	if replyTo > 0 {
+		if path != "" || line > 0 || startLine > 0 || side != "" {
+			utils.Check(cmd.UsageError("the '--in-reply-to' option can't be combined with the position of a comment"))
+		}
+	} else {
+		if path == "" || line <= 0 {
+			utils.Check(cmd.UsageError("the '--path' and '--line' options are required"))
+		}
+		if side == "" {
+			side = "RIGHT"
+		} else if side != "RIGHT" && side != "LEFT" {
+			utils.Check(cmd.UsageError(fmt.Sprintf("invalid side: %q", side)))
+		}
+		if startLine > 0 && startLine >= line {
+			utils.Check(cmd.UsageError("the '--start-line' option must be lower than '--line'"))
+		}
+	}
+
+	localRepo, err := github.LocalRepo()
+	utils.Check(err)
+
+	project, err := localRepo.MainProject()
+	utils.Check(err)
+
+	var body string
+	if messages := args.Flag.AllValues("--message"); len(messages) > 0 {
+		body = strings.Join(messages, "\n\n")
+	} else if args.Flag.HasReceived("--file") {
+		body, err = msgFromFile(args.Flag.Value("--file"))
+		utils.Check(err)
+	} else {
+		editor, err := github.NewEditor("REVIEW_COMMENT_EDITMSG", "comment", "")
+		utils.Check(err)
+		editor.AddCommentedSection(fmt.Sprintf("Commenting on pull request #%d in %s", number, project))
+		body, err = editor.EditContent()
+		utils.Check(err)
+		defer editor.DeleteFile()
+	}
+
+	body = strings.TrimSpace(body)
+	if body == "" {
+		utils.Check(fmt.Errorf("Aborting due to empty comment"))
+	}
+
+	gh := github.NewClient(project.Host)
+	args.NoForward()
+
+	if replyTo > 0 {
+		if args.Noop {
+			ui.Printf("Would reply to review comment %d on pull request #%d in %s\n", replyTo, number, project)
+			return
+		}
+		comment, err := gh.ReplyToReviewComment(project, number, replyTo, body)
+		utils.Check(err)
+		ui.Println(comment.HtmlUrl)
+		return
+	}
+
+	pr, err := gh.PullRequest(project, strconv.Itoa(number))
+	utils.Check(err)
+
+	files, err := gh.FetchPullRequestFiles(project, number)
+	utils.Check(err)
+
+	var file *github.CommitFile
+	for i := range files {
+		if files[i].Filename == path {
+			file = &files[i]
+			break
+		}
+	}
+	if file == nil {
+		utils.Check(fmt.Errorf("Aborted: '%s' is not among the files changed in pull request #%d", path, number))
+	}
+	utils.Check(checkReviewCommentLines(file, side, startLine, line))
+
+	params := map[string]interface{}{
+		"body":      body,
+		"commit_id": pr.Head.Sha,
+		"path":      path,
+		"line":      line,
+		"side":      side,
+	}
+	if startLine > 0 {
+		params["start_line"] = startLine
+		params["start_side"] = side
+	}
+
+	if args.Noop {
+		ui.Printf("Would comment on %s:%d in pull request #%d in %s\n", path, line, number, project)
+		return
+	}
+
+	comment, err := gh.CreateReviewComment(project, number, params)
+	utils.Check(err)
+	ui.Println(comment.HtmlUrl)
+}
+
+// mergePr merges a pull request. Instead, '--auto' and '--disable-auto' turn
+// auto-merge on and off, and '--dequeue' removes it from the merge queue.
+func mergePr(cmd *Command, args *Args) {
+	if args.ParamsSize() != 1 {
+		utils.Check(cmd.UsageError(""))
+	}
+
+	enable, disable := args.Flag.Bool("--auto"), args.Flag.Bool("--disable-auto")
+	dequeue := args.Flag.Bool("--dequeue")
+	if enable && disable {
+		utils.Check(cmd.UsageError("the '--auto' and '--disable-auto' options are mutually exclusive"))
+	}
+	if args.Flag.Bool("--notify") && !args.Flag.HasReceived("--watch") {
+		utils.Check(cmd.UsageError("the '--notify' option requires '--watch'"))
+	}
+	if dequeue {
+		for _, flag := range []string{"--auto", "--disable-auto", "--merge", "--squash", "--rebase", "--message", "--delete-branch", "--watch"} {
+			if args.Flag.HasReceived(flag) {
+				utils.Check(cmd.UsageError(fmt.Sprintf("'%s' can't be combined with '--dequeue'", flag)))
+			}
+		}
+	}
+	if enable || disable {
+		for _, flag := range []string{"--message", "--delete-branch", "--watch"} {
+			if args.Flag.HasReceived(flag) {
+				utils.Check(cmd.UsageError(fmt.Sprintf("'%s' can't be combined with '--auto' or '--disable-auto'", flag)))
+			}
+		}
+	}
+
+	method := ""
+	for _, m := range []string{"merge", "squash", "rebase"} {
+		if args.Flag.Bool("--" + m) {
+			if method != "" {
+				utils.Check(cmd.UsageError("only one of '--merge', '--squash' and '--rebase' can be given"))
+			}
+			method = m
+		}
+	}
+	if disable && method != "" {
+		utils.Check(cmd.UsageError(fmt.Sprintf("'--%s' can't be combined with '--disable-auto'", method)))
+	}
+	if method == "" {
+		method = "merge"
+	}
+
+	localRepo, err := github.LocalRepo()
+	utils.Check(err)
+
+	project, err := localRepo.MainProject()
+	utils.Check(err)
+
+	ref := args.GetParam(0)
+	number, branch := 0, ""
+	if urlProject, id := parsePullRequestURL(ref); urlProject != nil {
+		project = urlProject
+		number, _ = strconv.Atoi(id)
+	} else if number, err = strconv.Atoi(ref); err != nil {
+		branch = ref
+	}
+
+	args.NoForward()
+	if args.Noop {
+		subject := fmt.Sprintf("pull request #%d", number)
+		if branch != "" {
+			subject = fmt.Sprintf("the pull request for branch %s", branch)
+		}
+		if dequeue {
+			ui.Printf("Would remove %s from the merge queue\n", subject)
+		} else if disable {
+			ui.Printf("Would disable auto-merge for %s\n", subject)
+		} else if enable {
+			ui.Printf("Would enable auto-merge with the %s method for %s\n", method, subject)
+		} else {
+			ui.Printf("Would merge %s with the %s method\n", subject, method)
+			if args.Flag.Bool("--delete-branch") {
+				ui.Printf("Would delete the head branch of %s\n", subject)
+			}
+		}
+		return
+	}
+
+	gh := github.NewClient(project.Host)
+	if enable || disable {
+		utils.Check(gh.RequireFeature(github.FeatureAutoMerge))
+	}
+
+	if branch != "" {
+		pr, err := pullRequestForBranch(gh, project, &github.Branch{Repo: localRepo, Name: "refs/heads/" + branch})
+		utils.Check(err)
+		if pr == nil {
+			utils.Check(fmt.Errorf("no pull request found for branch '%s'", branch))
+		}
+		number = pr.Number
+	}
+	// pull requests found by branch lack the mergeable state
+	pr, err := gh.PullRequest(project, strconv.Itoa(number))
+	utils.Check(err)
+
+	if dequeue {
+		utils.Check(gh.DequeuePullRequest(pr))
+		ui.Printf("Removed pull request #%d from the merge queue\n", number)
+		return
+	}
+
+	if disable {
+		utils.Check(gh.DisableAutoMerge(pr))
+		ui.Printf("Disabled auto-merge for pull request #%d\n", number)
+		return
+	}
+
+	if enable {
+		merged, err := gh.EnableAutoMerge(project, pr, method)
+		if _, ok := err.(*github.AutoMergeNotAllowedError); ok {
+			err = fmt.Errorf("%s; allow it in the settings of the repository, e.g. with:\n  hub api -X PATCH repos/%s -F allow_auto_merge=true", err, project)
+		}
+		utils.Check(err)
+
+		if merged {
+			ui.Printf("Merged pull request #%d right away since it meets all requirements\n", number)
+		} else {
+			ui.Printf("Enabled auto-merge for pull request #%d; it will be merged with the %s method once all requirements are met\n", number, method)
+		}
+		return
+	}
+
+	utils.Check(checkMergeable(pr))
+
+	queued, err := gh.HasMergeQueue(project, pr.Base.Ref)
+	utils.Check(err)
+	if queued {
+		enqueuePr(args, gh, project, pr)
+		return
+	}
+	if args.Flag.HasReceived("--watch") {
+		utils.Check(cmd.UsageError(fmt.Sprintf("the '--watch' option only applies to pull requests merged through a merge queue, and %s has none", pr.Base.Ref)))
+	}
+
+	title, message := "", ""
+	if flagMessage := args.Flag.AllValues("--message"); len(flagMessage) > 0 {
+		parts := strings.SplitN(strings.Join(flagMessage, "\n\n"), "\n\n", 2)
+		title = strings.TrimSpace(parts[0])
+		if len(parts) == 2 {
+			message = strings.TrimSpace(parts[1])
+		}
+	}
+	utils.Check(gh.MergePullRequest(project, number, method, title, message))
+	ui.Printf("Merged pull request #%d with the %s method\n", number, method)
+
+	if args.Flag.Bool("--delete-branch") {
+		deletePrHeadBranch(gh, project, pr)
+	}
+}
+
+// enqueuePr adds a pull request to the merge queue of its base branch and,
+// with '--watch', follows it through the queue. Leaving the queue without
+// being merged is an error.
+func enqueuePr(args *Args, gh *github.Client, project *github.Project, pr *github.PullRequest) {
+	if args.Flag.HasReceived("--message") {
+		utils.Check(fmt.Errorf("Aborted: the merge queue of %s doesn't take a commit message", pr.Base.Ref))
+	}
+	watch := args.Flag.HasReceived("--watch")
+	deleteBranch := args.Flag.Bool("--delete-branch")
+	if deleteBranch && !watch {
+		utils.Check(fmt.Errorf("Aborted: pull request #%d would be merged later by the merge queue of %s\n(use `--watch` to delete the head branch once it's merged)", pr.Number, pr.Base.Ref))
+	}
+
+	entry, err := gh.EnqueuePullRequest(pr)
+	utils.Check(err)
+	ui.Printf("Pull request #%d added to merge queue at position %d\n", pr.Number, entry.Position)
+	if !watch {
+		return
+	}
+
+	interval := watchInterval(args, "--watch")
+	position := entry.Position
+	var status *github.MergeQueueStatus
+	for {
+		time.Sleep(interval)
+		status, err = gh.FetchMergeQueueStatus(project, pr.Number)
+		utils.Check(err)
+		if status.Entry == nil {
+			break
+		}
+		if status.Entry.Position != position {
+			position = status.Entry.Position
+			ui.Printf("Pull request #%d is at position %d in the merge queue\n", pr.Number, position)
+		}
+	}
+
+	// A pull request can leave the queue a moment before it shows up as
+	// merged, so look once more before calling it removed.
+	if !status.Merged {
+		time.Sleep(interval)
+		status, err = gh.FetchMergeQueueStatus(project, pr.Number)
+		utils.Check(err)
+	}
+
+	result := "merged"
+	if !status.Merged {
+		result = "removed from the merge queue without being merged"
+	}
+	if args.Flag.Bool("--notify") {
+		message := fmt.Sprintf("%s#%d: %s", project, pr.Number, result)
+		if err := utils.DefaultNotifier.Notify("hub pr merge", message); err != nil {
+			ui.Errorf("warning: could not show a notification: %s\n", err)
+		}
+	}
+	if !status.Merged {
+		utils.Check(fmt.Errorf("Pull request #%d was %s", pr.Number, result))
+	}
+	ui.Printf("Merged pull request #%d\n", pr.Number)
+
+	if deleteBranch {
+		deletePrHeadBranch(gh, project, pr)
+	}
+}
+
+// deletePrHeadBranch deletes the head branch of a merged pull request unless
+// it belongs to another repository.
+func deletePrHeadBranch(gh *github.Client, project *github.Project, pr *github.PullRequest) {
+	if !pr.IsSameRepo() {
+		ui.Errorf("Skipped deleting the head branch of pull request #%d, which belongs to another repository\n", pr.Number)
+		return
+	}
+	utils.Check(gh.DeleteBranch(project, pr.Head.Ref))
+	ui.Printf("Deleted branch %s\n", pr.Head.Ref)
+}
+
+// checkMergeable explains why a pull request can't be merged as it is.
+func checkMergeable(pr *github.PullRequest) error {
+	switch {
+	case !pr.MergedAt.IsZero():
+		return fmt.Errorf("Aborted: pull request #%d is already merged", pr.Number)
+	case pr.State == "closed":
+		return fmt.Errorf("Aborted: pull request #%d is closed", pr.Number)
+	case pr.Draft:
+		return fmt.Errorf("Aborted: pull request #%d is a draft\n(use `hub pr ready %d` to mark it as ready for review)", pr.Number, pr.Number)
+	case pr.MergeableState == "dirty":
+		return fmt.Errorf("Aborted: pull request #%d has conflicts with its base branch that have to be resolved first", pr.Number)
+	}
+	return nil
+}
+
+func readyPr(cmd *Command, args *Args) {
+	if args.ParamsSize() != 1 {
+		utils.Check(cmd.UsageError(""))
+	}
+	number, err := strconv.Atoi(args.GetParam(0))
+	if err != nil {
+		utils.Check(cmd.UsageError(fmt.Sprintf("invalid pull request number: %q", args.GetParam(0))))
+	}
+
+	localRepo, err := github.LocalRepo()
+	utils.Check(err)
+
+	project, err := localRepo.MainProject()
+	utils.Check(err)
+
+	args.NoForward()
+	if args.Noop {
+		ui.Printf("Would mark pull request #%d as ready for review\n", number)
+		return
+	}
+
+	gh := github.NewClient(project.Host)
+	utils.Check(gh.RequireFeature(github.FeatureDraftPullRequests))
+	pr, err := gh.PullRequest(project, strconv.Itoa(number))
+	utils.Check(err)
+
+	if !pr.Draft {
+		ui.Printf("Pull request #%d is already ready for review\n", number)
+		return
+	}
+
+	utils.Check(gh.MarkPullRequestReady(pr))
+	ui.Printf("Marked pull request #%d as ready for review\n", number)
+}
+
+func numberPr(cmd *Command, args *Args) {
+	if args.ParamsSize() > 1 {
+		utils.Check(cmd.UsageError(""))
+	}
+
+	localRepo, err := github.LocalRepo()
+	utils.Check(err)
+
+	project, err := localRepo.MainProject()
+	utils.Check(err)
+
+	var branch *github.Branch
+	if args.ParamsSize() == 1 {
+		branch = &github.Branch{Repo: localRepo, Name: "refs/heads/" + args.GetParam(0)}
+	} else {
+		branch, err = localRepo.CurrentBranch()
+		utils.Check(err)
+	}
+
+	args.NoForward()
+	if args.Noop {
+		ui.Printf("Would look up the pull request for branch %s\n", branch.ShortName())
+		return
+	}
+
+	gh := github.NewClient(project.Host)
+	pr, err := pullRequestForBranch(gh, project, branch)
+	utils.Check(err)
+	if pr == nil {
+		ui.Errorf("no pull request found for branch '%s'\n", branch.ShortName())
+		os.Exit(utils.ExitError)
+	}
+	ui.Println(pr.Number)
+}
+
+// pullRequestForBranch finds the pull request that was opened from a local
+// branch, either the one whose head the branch was checked out from, or the one
+// from the branch that it's pushed to.
+func pullRequestForBranch(gh *github.Client, project *github.Project, branch *github.Branch) (*github.PullRequest, error) {
+	if number := branch.PullRequestRef(); number > 0 {
+		return gh.PullRequest(project, strconv.Itoa(number))
+	}
+	owner, name := branch.PushOwner()
+	return gh.FindPullRequestForBranch(project, name, owner)
+}
+
+func findPr(cmd *Command, args *Args) {
+	if args.ParamsSize() != 1 {
+		utils.Check(cmd.UsageError(""))
+	}
+	ref := args.GetParam(0)
+
+	localRepo, err := github.LocalRepo()
+	utils.Check(err)
+
+	project, err := localRepo.MainProject()
+	utils.Check(err)
+
+	gh := github.NewClient(project.Host)
+
+	// abbreviated SHAs are expanded locally if possible, since the API only
+	// knows about pushed commits
+	sha, err := git.Ref(ref + "^{commit}")
+	if err != nil {
+		commit, fetchErr := gh.FetchCommit(project, ref)
+		utils.Check(fetchErr)
+		sha = commit.Sha
+	}
+
+	pulls, err := gh.CommitPullRequests(project, sha)
+	utils.Check(err)
+	if len(pulls) == 0 {
+		utils.Check(utils.WithExitStatus(fmt.Errorf("no pull requests found for commit %s", ref), utils.ExitNotFound))
+	}
+
+	numberWidth, stateWidth := 0, 0
+	for _, pr := range pulls {
+		if n := len(strconv.Itoa(pr.Number)) + 1; n > numberWidth {
+			numberWidth = n
+		}
+		if n := len(pullRequestState(pr)); n > stateWidth {
+			stateWidth = n
+		}
+	}
+	for _, pr := range pulls {
+		ui.Printf("%-*s  %-*s  %s  %s\n", numberWidth, fmt.Sprintf("#%d", pr.Number), stateWidth, pullRequestState(pr), pr.Title, pr.HtmlUrl)
+	}
+
+	if args.Flag.Bool("--browse") {
+		browsed := pulls[0]
+		for _, pr := range pulls {
+			if !pr.MergedAt.IsZero() {
+				browsed = pr
+				break
+			}
+		}
+		printBrowseOrCopy(args, browsed.HtmlUrl, true, false)
+	} else {
+		args.NoForward()
+	}
+}
+
+// checkReviewCommentLines verifies that the lines of a review comment are part
+// of the diff, since the API only reports a generic validation error.
+func checkReviewCommentLines(file *github.CommitFile, side string, startLine, line int) error {
+	if file.Patch == "" {
+		return fmt.Errorf("Aborted: the diff of '%s' is not available for commenting", file.Filename)
+	}
+	hunks, err := github.ParseDiffHunks(file.Patch)
+	if err != nil {
+		return err
+	}
+
+	for _, hunk := range hunks {
+		if !hunk.Contains(side, line) {
+			continue
+		}
+		if startLine > 0 && !hunk.Contains(side, startLine) {
+			return fmt.Errorf("Aborted: lines %d to %d of '%s' are not in the same hunk of the diff on the %s side\n(lines in the hunk: %s)",
+				startLine, line, file.Filename, side, lineRanges(hunk.Lines(side)))
+		}
+		return nil
+	}
+
+	covered := []string{}
+	for _, hunk := range hunks {
+		if lines := hunk.Lines(side); len(lines) > 0 {
+			covered = append(covered, lineRanges(lines))
+		}
+	}
+	if len(covered) == 0 {
+		return fmt.Errorf("Aborted: the diff of '%s' has no lines on the %s side", file.Filename, side)
+	}
+	return fmt.Errorf("Aborted: line %d of '%s' is not part of the diff on the %s side\n(lines in the diff: %s)",
+		line, file.Filename, side, strings.Join(covered, ", "))
+}
+
+// lineRanges formats sorted line numbers like "1-4, 7".
+func lineRanges(lines []int) string {
+	ranges := []string{}
+	for i := 0; i < len(lines); {
+		j := i
+		for j+1 < len(lines) && lines[j+1] == lines[j]+1 {
+			j++
+		}
+		if j > i {
+			ranges = append(ranges, fmt.Sprintf("%d-%d", lines[i], lines[j]))
+		} else {
+			ranges = append(ranges, strconv.Itoa(lines[i]))
+		}
+		i = j + 1
+	}
+	return strings.Join(ranges, ", ")
+}
+
+// lazyPullRequestPlaceholders computes the placeholders that need additional
+// requests or git commands, but only the ones that format references. The
+// checks and review decision come from statuses, which fetchPullRequestStatuses
+// looks up for all listed pull requests at once, and the readiness to merge
+// comes from fetchPullRequestReadiness.
+func lazyPullRequestPlaceholders(gh *github.Client, localRepo *github.GitHubRepo, pr github.PullRequest, format *ui.Format, statuses map[int]*github.PullRequestStatus, readiness map[int]string, colorize bool) map[string]string {
+	placeholders := map[string]string{}
+	if format.Uses("ab") {
+		placeholders["ab"] = ""
+		if ab, err := pullRequestAheadBehind(gh, localRepo, pr); err == nil {
+			placeholders["ab"] = ab.String()
+		}
+	}
+	if statuses != nil {
+		for key, value := range pullRequestStatusPlaceholders(statuses[pr.Number], colorize) {
+			placeholders[key] = value
+		}
+	}
+	if readiness != nil {
+		placeholders["req"] = readiness[pr.Number]
+	}
+	return placeholders
+}
+
+// pullRequestReadinessWorkers is how many pull requests are evaluated for
+// '--ready-to-merge' and "%req" at the same time.
+const pullRequestReadinessWorkers = 4
+
+// fetchPullRequestReadiness evaluates whether pulls are ready to be merged,
+// several at a time. Pull requests that couldn't be evaluated are left out with
+// a warning rather than failing the whole listing.
+func fetchPullRequestReadiness(gh *github.Client, project *github.Project, pulls []github.PullRequest) map[int]string {
+	type evaluation struct {
+		number    int
+		readiness string
+		err       error
+	}
+
+	queue := make(chan *github.PullRequest)
+	results := make(chan evaluation)
+	for i := 0; i < pullRequestReadinessWorkers; i++ {
+		go func() {
+			for pr := range queue {
+				readiness, err := gh.PullRequestReadiness(project, pr)
+				results <- evaluation{pr.Number, readiness, err}
+			}
+		}()
+	}
+	go func() {
+		for i := range pulls {
+			queue <- &pulls[i]
+		}
+		close(queue)
+	}()
+
+	readiness := map[int]string{}
+	for range pulls {
+		result := <-results
+		if result.err != nil {
+			ui.Errorf("warning: pull request #%d: %s\n", result.number, result.err)
+			continue
+		}
+		readiness[result.number] = result.readiness
+	}
+	return readiness
+}
+
+func usesPullRequestStatus(format *ui.Format) bool {
+	for _, name := range []string{"cs", "cC", "rd", "rC"} {
+		if format.Uses(name) {
+			return true
+		}
+	}
+	return false
+}
+
+// fetchPullRequestStatuses looks up the checks and review decisions of pulls.
+// When that fails, the placeholders are left blank rather than failing the
+// whole listing.
+func fetchPullRequestStatuses(gh *github.Client, project *github.Project, pulls []github.PullRequest) map[int]*github.PullRequestStatus {
+	numbers := []int{}
+	for _, pr := range pulls {
+		numbers = append(numbers, pr.Number)
+	}
+	statuses, err := gh.FetchPullRequestStatuses(project, numbers)
+	if err != nil {
+		ui.Errorf("warning: %s\n", err)
+		return map[int]*github.PullRequestStatus{}
+	}
+	return statuses
+}
+
+func pullRequestStatusPlaceholders(status *github.PullRequestStatus, colorize bool) map[string]string {
+	placeholders := map[string]string{"cs": "", "cC": "", "rd": "", "rC": ""}
+	if status == nil {
+		return placeholders
+	}
+
+	state := ciState(status.Checks)
+	placeholders["cs"] = state
+	placeholders["rd"] = status.ReviewDecision
+	if colorize {
+		if _, color := ciStateMarker(state); color > 0 {
+			placeholders["cC"] = fmt.Sprintf("\033[%dm", color)
+		}
+		switch status.ReviewDecision {
+		case "APPROVED":
+			placeholders["rC"] = "\033[32m"
+		case "CHANGES_REQUESTED":
+			placeholders["rC"] = "\033[31m"
+		case "REVIEW_REQUIRED":
+			placeholders["rC"] = "\033[33m"
+		}
+	}
+	return placeholders
+}
+
+// formatPullRequest expands format for pr. Placeholders that are costly to
+// compute, such as "%ab", are only filled in when extra provides them.
+func formatPullRequest(pr github.PullRequest, format *ui.Format, colorize, hyperlinks bool, extra map[string]string) string {
 	placeholders := formatIssuePlaceholders(github.Issue(pr), colorize)
 	for key, value := range formatPullRequestPlaceholders(pr, colorize) {
 		placeholders[key] = value
 	}
-	return ui.Expand(format, placeholders, colorize)
+	for key, value := range extra {
+		placeholders[key] = value
+	}
+	if hyperlinks {
+		linkPlaceholders(placeholders, pr.HtmlUrl, "I", "i", "U")
+	}
+	return format.Expand(placeholders, colorize)
 }
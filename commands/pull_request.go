@@ -1,6 +1,7 @@
 package commands
 
 import (
+	"encoding/json"
 	"fmt"
 	"os"
 	"regexp"
@@ -10,13 +11,14 @@ import (
 
 	"github.com/github/hub/git"
 	"github.com/github/hub/github"
+	"github.com/github/hub/ui"
 	"github.com/github/hub/utils"
 )
 
 var cmdPullRequest = &Command{
 	Run: pullRequest,
 	Usage: `
-pull-request [-focpd] [-b <BASE>] [-h <HEAD>] [-r <REVIEWERS> ] [-a <ASSIGNEES>] [-M <MILESTONE>] [-l <LABELS>]
+pull-request [-focpd] [-b <BASE>] [-h <HEAD>] [-r <REVIEWERS> ] [-a <ASSIGNEES>] [-M <MILESTONE>] [-l <LABELS>] [--template <NAME>] [--signoff[=<MODE>]] [--idempotency-key <KEY>] [--dry-run[=<FORMAT>]]
 pull-request -m <MESSAGE> [--edit]
 pull-request -F <FILE> [--edit]
 pull-request -i <ISSUE>
@@ -25,7 +27,9 @@ pull-request -i <ISSUE>
 
 ## Options:
 	-f, --force
-		Skip the check for unpushed commits.
+		Skip the check for unpushed commits, and for the current branch being
+		already associated with a pull request by 'hub pr checkout'. Also try to
+		open the pull request if the base repository is disabled.
 
 	-m, --message <MESSAGE>
 		The text up to the first blank line in <MESSAGE> is treated as the pull
@@ -37,7 +41,8 @@ pull-request -i <ISSUE>
 
 	--no-edit
 		Use the message from the first commit on the branch as pull request title
-		and description without opening a text editor.
+		and description without opening a text editor. With '--template', only the
+		title is taken from the commit, and the template is the description.
 
 	-F, --file <FILE>
 		Read the pull request title and description from <FILE>.
@@ -45,6 +50,21 @@ pull-request -i <ISSUE>
 	-e, --edit
 		Further edit the contents of <FILE> in a text editor before submitting.
 
+	--template <NAME>
+		Start the message from the template <NAME> in the "PULL_REQUEST_TEMPLATE"
+		directory instead of from the default pull request template. The directory
+		is looked up within ".github", "docs", and the root of the repository, in
+		this order. The name may be given without the ".md" extension.
+
+		These placeholders are expanded in templates before the editor opens:
+
+		{{branch}}: the name of the head branch
+
+		{{commits}}: a list of the subjects of the commits in the pull request
+
+		{{issue}}: the issue number given with '--issue'. With '--template', the
+		issue is referenced in the message instead of being converted.
+
 	-i, --issue <ISSUE>
 		Convert <ISSUE> (referenced by its number) to a pull request.
 
@@ -63,8 +83,12 @@ pull-request -i <ISSUE>
 		Push the current branch to <HEAD> before creating the pull request.
 
 	-b, --base <BASE>
-		The base branch in the "[<OWNER>:]<BRANCH>" format. Defaults to the default
-		branch of the upstream repository (usually "master").
+		The base branch in the "[<OWNER>:]<BRANCH>" format. Defaults to the base
+		branch remembered for the upstream repository, or else its default branch.
+
+		When a base branch other than the default branch is given on a terminal,
+		hub offers to remember it for the repository. See hub-config(1) for
+		setting the defaults of a repository explicitly.
 
 		See the "CONVENTIONS" section of hub(1) for more information on how hub
 		selects the defaults in case of multiple git remotes.
@@ -74,7 +98,8 @@ pull-request -i <ISSUE>
 		checked out branch.
 
 	-r, --reviewer <USERS>
-		A comma-separated list of GitHub handles to request a review from.
+		A comma-separated list of GitHub handles to request a review from. Defaults
+		to the reviewers remembered for the upstream repository.
 
 	-a, --assign <USERS>
 		A comma-separated list of GitHub handles to assign to this pull request.
@@ -85,10 +110,37 @@ pull-request -i <ISSUE>
 
 	-l, --labels <LABELS>
 		Add a comma-separated list of labels to this pull request. Labels will be
-		created if they do not already exist.
+		created if they do not already exist. Defaults to the labels remembered for
+		the upstream repository.
 	
 	-d, --draft
-		Create the pull request as a draft.
+		Create the pull request as a draft. Mark it as ready for review later with
+		'hub pr ready'.
+
+	--signoff[=<MODE>]
+		Check that every commit in the pull request carries a "Signed-off-by"
+		trailer with the email of the git user, as required by projects that
+		follow the Developer Certificate of Origin. The pull request is not
+		created if any commit lacks a sign-off.
+
+		With <MODE> "append-body", add a "Signed-off-by" statement for the git
+		user to the pull request description instead, for projects that accept
+		that.
+
+	--idempotency-key <KEY>
+		Record the URL of the new pull request under <KEY>. When the command is
+		repeated with the same <KEY> within 24 hours after succeeding, the recorded
+		URL is printed again instead of trying to open another pull request. A
+		pull request whose labels, assignees, milestone or reviewers couldn't be
+		set doesn't count as a success.
+
+	--dry-run[=<FORMAT>]
+		Show the pull request that would be opened instead of opening it: the
+		base and head with their owners, the title and description as they are
+		after applying the template and '--signoff=append-body', the labels,
+		assignees and milestone, the reviewers split into users and teams, and
+		whether it's a draft. Nothing is pushed with '--push'. With <FORMAT>
+		"json", print the same in JSON format.
 
 ## Examples:
 		$ hub pull-request
@@ -111,20 +163,45 @@ pull-request -i <ISSUE>
 
 ## See also:
 
-hub(1), hub-merge(1), hub-checkout(1)
+hub(1), hub-merge(1), hub-checkout(1), hub-config(1)
 `,
+	FlagValues: map[string]flagValue{
+		"--dry-run": enumValue("text", "json"),
+	},
 }
 
 func init() {
 	CmdRunner.Use(cmdPullRequest)
 }
 
+// pullRequestURLRe matches the URL of the pull request that `hub pr checkout`
+// records in the description of a branch.
+var pullRequestURLRe = regexp.MustCompile(`https?://\S+/pull/\d+`)
+
 func pullRequest(cmd *Command, args *Args) {
 	localRepo, err := github.LocalRepo()
 	utils.Check(err)
 
+	flagPullRequestHead := args.Flag.Value("--head")
+
+	flagPullRequestSignoff := ""
+	if args.Flag.HasReceived("--signoff") {
+		flagPullRequestSignoff = args.Flag.Value("--signoff")
+		if flagPullRequestSignoff == "" || flagPullRequestSignoff == "true" {
+			flagPullRequestSignoff = "check"
+		}
+		if flagPullRequestSignoff != "check" && flagPullRequestSignoff != "append-body" {
+			utils.Check(cmd.UsageError(fmt.Sprintf(`invalid value for '--signoff': %q (expected "check" or "append-body")`, flagPullRequestSignoff)))
+		}
+	}
+
+	if flagPullRequestHead == "" {
+		checkCurrentBranch("pass an explicit head with '--head'")
+	}
 	currentBranch, err := localRepo.CurrentBranch()
-	utils.Check(err)
+	if flagPullRequestHead == "" {
+		utils.Check(err)
+	}
 
 	baseProject, err := localRepo.MainProject()
 	utils.Check(err)
@@ -134,9 +211,28 @@ func pullRequest(cmd *Command, args *Args) {
 		utils.Check(github.FormatError("creating pull request", err))
 	}
 	client := github.NewClientWithHost(host)
+	if args.Flag.Bool("--draft") && !args.Noop {
+		utils.Check(client.RequireFeature(github.FeatureDraftPullRequests))
+	}
 
-	trackedBranch, headProject, err := localRepo.RemoteBranchAndProject(host.User, false)
-	utils.Check(err)
+	var trackedBranch *github.Branch
+	var headProject *github.Project
+	if currentBranch != nil {
+		trackedBranch, headProject, err = localRepo.RemoteBranchAndProject(host.User, false)
+		utils.Check(err)
+		if trackedBranch == nil || !trackedBranch.IsRemote() {
+			// a branch that wasn't pushed yet is headed for the fork
+			if forkRemote, err := localRepo.ForkRemote(host.User); err == nil {
+				if forkProject, err := forkRemote.PushProject(); err == nil {
+					headProject = forkProject
+				}
+			}
+		}
+	} else {
+		// not on any branch, but the head was given explicitly
+		project := *baseProject
+		headProject = &project
+	}
 
 	var (
 		base, head string
@@ -146,13 +242,24 @@ func pullRequest(cmd *Command, args *Args) {
 		baseProject, base = parsePullRequestProject(baseProject, flagPullRequestBase)
 	}
 
-	if flagPullRequestHead := args.Flag.Value("--head"); flagPullRequestHead != "" {
+	if flagPullRequestHead != "" {
 		headProject, head = parsePullRequestProject(headProject, flagPullRequestHead)
 	}
 
+	projectDefaults := github.LoadProjectDefaults(baseProject)
+	rememberBase := ""
+	if base != "" && projectDefaults.Base == "" {
+		rememberBase = base
+	} else if base == "" {
+		base = projectDefaults.Base
+	}
+
 	baseRemote, _ := localRepo.RemoteForProject(baseProject)
 	if base == "" && baseRemote != nil {
 		base = localRepo.DefaultBranch(baseRemote).ShortName()
+	} else if base == "" {
+		base, err = baseProject.DefaultBranch()
+		utils.Check(err)
 	}
 
 	if head == "" && trackedBranch != nil {
@@ -186,6 +293,8 @@ func pullRequest(cmd *Command, args *Args) {
 	fullHead := fmt.Sprintf("%s:%s", headProject.Owner, head)
 
 	force := args.Flag.Bool("--force")
+	utils.Check(client.EnsureWritable(baseProject, force))
+
 	if !force && trackedBranch != nil {
 		remoteCommits, err := git.RefList(trackedBranch.LongName(), "")
 		if err == nil && len(remoteCommits) > 0 {
@@ -195,6 +304,15 @@ func pullRequest(cmd *Command, args *Args) {
 		}
 	}
 
+	if !force && currentBranch != nil {
+		description, _ := git.Config(fmt.Sprintf("branch.%s.description", currentBranch.ShortName()))
+		if prURL := pullRequestURLRe.FindString(description); prURL != "" {
+			err = fmt.Errorf("Aborted: branch '%s' is already associated with pull request %s", currentBranch.ShortName(), prURL)
+			err = fmt.Errorf("%s\n(use `-f` to force submit a pull request anyway)", err)
+			utils.Check(err)
+		}
+	}
+
 	messageBuilder := &github.MessageBuilder{
 		Filename: "PULLREQ_EDITMSG",
 		Title:    "pull request",
@@ -224,6 +342,19 @@ func pullRequest(cmd *Command, args *Args) {
 Write a message for this pull request. The first block
 of text is the title and the rest is the description.`, fullBase, fullHead))
 
+	signoff := ""
+	if flagPullRequestSignoff != "" {
+		signoff, err = signoffIdentity()
+		utils.Check(err)
+	}
+	if flagPullRequestSignoff == "check" {
+		signoffHead := headTracking
+		if _, err := git.Ref(headTracking); err != nil || flagPullRequestPush {
+			signoffHead = head
+		}
+		utils.Check(checkSignoffs(baseTracking, signoffHead, signoff))
+	}
+
 	flagPullRequestMessage := args.Flag.AllValues("--message")
 	flagPullRequestEdit := args.Flag.Bool("--edit")
 	flagPullRequestIssue := args.Flag.Value("--issue")
@@ -231,7 +362,43 @@ of text is the title and the rest is the description.`, fullBase, fullHead))
 		flagPullRequestIssue = parsePullRequestIssueNumber(args.GetParam(0))
 	}
 
-	if len(flagPullRequestMessage) > 0 {
+	headForMessage := headTracking
+	if flagPullRequestPush {
+		headForMessage = head
+	}
+
+	flagPullRequestTemplate := args.Flag.Value("--template")
+	if flagPullRequestTemplate != "" && (len(flagPullRequestMessage) > 0 || args.Flag.HasReceived("--file")) {
+		utils.Check(cmd.UsageError("the '--template' option can't be combined with '--message' or '--file'"))
+	}
+	readTemplate := func() string {
+		workdir, _ := git.WorkdirName()
+		if workdir == "" {
+			return ""
+		}
+		var template string
+		if flagPullRequestTemplate != "" {
+			template, err = github.ReadNamedTemplate(github.PullRequestTemplate, flagPullRequestTemplate, workdir)
+			utils.Check(err)
+		} else {
+			template, _ = github.ReadTemplate(github.PullRequestTemplate, workdir)
+		}
+		if template == "" {
+			return ""
+		}
+		commits, _ := git.RangeCommits(fmt.Sprintf("%s..%s", baseTracking, headForMessage), pullRequestTemplateCommits)
+		return expandPullRequestTemplate(template, head, commits, flagPullRequestIssue)
+	}
+
+	if flagPullRequestTemplate != "" && args.Flag.Bool("--no-edit") {
+		commits, _ := git.RefList(baseTracking, head)
+		if len(commits) == 0 {
+			utils.Check(fmt.Errorf("Aborted: no commits detected between %s and %s", baseTracking, head))
+		}
+		message, err := git.Show(commits[len(commits)-1])
+		utils.Check(err)
+		messageBuilder.Message = strings.SplitN(message, "\n", 2)[0] + "\n\n" + readTemplate()
+	} else if len(flagPullRequestMessage) > 0 {
 		messageBuilder.Message = strings.Join(flagPullRequestMessage, "\n\n")
 		messageBuilder.Edit = flagPullRequestEdit
 	} else if args.Flag.HasReceived("--file") {
@@ -246,14 +413,9 @@ of text is the title and the rest is the description.`, fullBase, fullHead))
 		message, err := git.Show(commits[len(commits)-1])
 		utils.Check(err)
 		messageBuilder.Message = message
-	} else if flagPullRequestIssue == "" {
+	} else if flagPullRequestIssue == "" || flagPullRequestTemplate != "" {
 		messageBuilder.Edit = true
 
-		headForMessage := headTracking
-		if flagPullRequestPush {
-			headForMessage = head
-		}
-
 		message := ""
 		commitLogs := ""
 
@@ -273,12 +435,8 @@ of text is the title and the rest is the description.`, fullBase, fullHead))
 			messageBuilder.AddCommentedSection("\nChanges:\n\n" + strings.TrimSpace(commitLogs))
 		}
 
-		workdir, _ := git.WorkdirName()
-		if workdir != "" {
-			template, _ := github.ReadTemplate(github.PullRequestTemplate, workdir)
-			if template != "" {
-				message = message + "\n\n\n" + template
-			}
+		if template := readTemplate(); template != "" {
+			message = message + "\n\n\n" + template
 		}
 
 		messageBuilder.Message = message
@@ -291,6 +449,40 @@ of text is the title and the rest is the description.`, fullBase, fullHead))
 		utils.Check(fmt.Errorf("Aborting due to empty pull request title"))
 	}
 
+	if flagPullRequestSignoff == "append-body" {
+		body = appendSignoff(body, signoff)
+	}
+
+	if flagPullRequestDryRun := dryRunFormat(args); flagPullRequestDryRun != "" {
+		flagPullRequestDraft := args.Flag.Bool("--draft")
+		preview := &creationPreview{
+			Repository: baseProject.String(),
+			Base:       fullBase,
+			Head:       fullHead,
+			Title:      title,
+			Body:       body,
+			Draft:      &flagPullRequestDraft,
+			Labels:     flagOrDefaults(commaSeparated(args.Flag.AllValues("--labels")), projectDefaults.Labels),
+			Assignees:  commaSeparated(args.Flag.AllValues("--assign")),
+			Reviewers:  splitReviewers(flagOrDefaults(commaSeparated(args.Flag.AllValues("--reviewer")), projectDefaults.Reviewers)),
+		}
+		if title == "" {
+			preview.Issue, _ = strconv.Atoi(flagPullRequestIssue)
+		}
+		if flagPullRequestMilestone := args.Flag.Value("--milestone"); flagPullRequestMilestone != "" {
+			preview.Milestone, err = lookupMilestone(client, baseProject, flagPullRequestMilestone)
+			utils.Check(err)
+		}
+
+		output, err := preview.render(flagPullRequestDryRun)
+		utils.Check(err)
+		ui.Print(output)
+
+		messageBuilder.Cleanup()
+		args.NoForward()
+		return
+	}
+
 	if flagPullRequestPush {
 		if args.Noop {
 			args.Before(fmt.Sprintf("Would push to %s/%s", remote.Name, head), "")
@@ -312,30 +504,32 @@ of text is the title and the rest is the description.`, fullBase, fullHead))
 		}
 	}
 
-	var pullRequestURL string
-	if args.Noop {
-		args.Before(fmt.Sprintf("Would request a pull request to %s from %s", fullBase, fullHead), "")
-		pullRequestURL = "PULL_REQUEST_URL"
-	} else {
-		params := map[string]interface{}{
-			"base": base,
-			"head": fullHead,
-		}
+	params := map[string]interface{}{
+		"base": base,
+		"head": fullHead,
+	}
 
-		if args.Flag.Bool("--draft") {
-			params["draft"] = true
-		}
+	if args.Flag.Bool("--draft") {
+		params["draft"] = true
+	}
 
-		if title != "" {
-			params["title"] = title
-			if body != "" {
-				params["body"] = body
-			}
-		} else {
-			issueNum, _ := strconv.Atoi(flagPullRequestIssue)
-			params["issue"] = issueNum
+	if title != "" {
+		params["title"] = title
+		if body != "" {
+			params["body"] = body
 		}
+	} else {
+		issueNum, _ := strconv.Atoi(flagPullRequestIssue)
+		params["issue"] = issueNum
+	}
 
+	var pullRequestURL string
+	if args.Noop {
+		payload, err := json.MarshalIndent(params, "", "  ")
+		utils.Check(err)
+		args.Before(fmt.Sprintf("Would request a pull request to %s from %s with:\n%s", fullBase, fullHead, payload), "")
+		pullRequestURL = "PULL_REQUEST_URL"
+	} else {
 		startedAt := time.Now()
 		numRetries := 0
 		retryDelay := 2
@@ -349,79 +543,132 @@ of text is the title and the rest is the description.`, fullBase, fullHead))
 			}
 		}
 
-		var pr *github.PullRequest
-		for {
-			pr, err = client.CreatePullRequest(baseProject, params)
-			if err != nil && strings.Contains(err.Error(), `Invalid value for "head"`) {
-				if retryAllowance > 0 {
-					retryAllowance -= retryDelay
-					time.Sleep(time.Duration(retryDelay) * time.Second)
-					retryDelay += 1
-					numRetries += 1
-				} else {
-					if numRetries > 0 {
-						duration := time.Now().Sub(startedAt)
-						err = fmt.Errorf("%s\nGiven up after retrying for %.1f seconds.", err, duration.Seconds())
+		var replayed bool
+		pullRequestURL, replayed, err = performIdempotently(args, baseProject.Host, func() (string, bool, error) {
+			var pr *github.PullRequest
+			var err error
+			for {
+				pr, err = client.CreatePullRequest(baseProject, params)
+				if err != nil && strings.Contains(err.Error(), `Invalid value for "head"`) {
+					if retryAllowance > 0 {
+						retryAllowance -= retryDelay
+						time.Sleep(time.Duration(retryDelay) * time.Second)
+						retryDelay += 1
+						numRetries += 1
+					} else {
+						if numRetries > 0 {
+							duration := time.Now().Sub(startedAt)
+							err = fmt.Errorf("%s\nGiven up after retrying for %.1f seconds.", err, duration.Seconds())
+						}
+						break
 					}
+				} else {
 					break
 				}
-			} else {
-				break
 			}
-		}
+			if err != nil {
+				return "", false, err
+			}
+			messageBuilder.Cleanup()
 
-		if err == nil {
-			defer messageBuilder.Cleanup()
+			// the key is only recorded once the pull request is complete, so
+			// that retrying after a failed update doesn't skip it
+			if err = updateCreatedPullRequest(client, baseProject, pr, args, projectDefaults, milestoneNumber); err != nil {
+				return pr.HtmlUrl, false, err
+			}
+			return pr.HtmlUrl, true, nil
+		})
+		utils.Check(err)
+
+		if replayed {
+			messageBuilder.Cleanup()
+			args.NoForward()
+			printBrowseOrCopy(args, pullRequestURL, args.Flag.Bool("--browse"), args.Flag.Bool("--copy"))
+			return
 		}
+	}
 
-		utils.Check(err)
+	args.NoForward()
+	printBrowseOrCopy(args, pullRequestURL, args.Flag.Bool("--browse"), args.Flag.Bool("--copy"))
 
-		pullRequestURL = pr.HtmlUrl
+	if rememberBase != "" && !args.Noop && ui.IsTerminal(os.Stdin) && ui.IsTerminal(os.Stdout) {
+		offerToRememberBase(localRepo, baseProject, baseRemote, rememberBase)
+	}
+}
 
-		params = map[string]interface{}{}
-		flagPullRequestLabels := commaSeparated(args.Flag.AllValues("--labels"))
-		if len(flagPullRequestLabels) > 0 {
-			params["labels"] = flagPullRequestLabels
-		}
-		flagPullRequestAssignees := commaSeparated(args.Flag.AllValues("--assign"))
-		if len(flagPullRequestAssignees) > 0 {
-			params["assignees"] = flagPullRequestAssignees
-		}
-		if milestoneNumber > 0 {
-			params["milestone"] = milestoneNumber
-		}
+// updateCreatedPullRequest applies the labels, assignees, milestone and
+// reviewers that couldn't be set when creating the pull request.
+func updateCreatedPullRequest(client *github.Client, project *github.Project, pr *github.PullRequest, args *Args, defaults *github.ProjectDefaults, milestoneNumber int) error {
+	params := map[string]interface{}{}
+	flagPullRequestLabels := flagOrDefaults(commaSeparated(args.Flag.AllValues("--labels")), defaults.Labels)
+	if len(flagPullRequestLabels) > 0 {
+		params["labels"] = flagPullRequestLabels
+	}
+	flagPullRequestAssignees := commaSeparated(args.Flag.AllValues("--assign"))
+	if len(flagPullRequestAssignees) > 0 {
+		params["assignees"] = flagPullRequestAssignees
+	}
+	if milestoneNumber > 0 {
+		params["milestone"] = milestoneNumber
+	}
 
-		if len(params) > 0 {
-			err = client.UpdateIssue(baseProject, pr.Number, params)
-			utils.Check(err)
+	if len(params) > 0 {
+		if err := client.UpdateIssue(project, pr.Number, params); err != nil {
+			return err
 		}
+	}
 
-		flagPullRequestReviewers := commaSeparated(args.Flag.AllValues("--reviewer"))
-		if len(flagPullRequestReviewers) > 0 {
-			userReviewers := []string{}
-			teamReviewers := []string{}
-			for _, reviewer := range flagPullRequestReviewers {
-				if strings.Contains(reviewer, "/") {
-					teamName := strings.SplitN(reviewer, "/", 2)[1]
-					if !pr.HasRequestedTeam(teamName) {
-						teamReviewers = append(teamReviewers, teamName)
-					}
-				} else if !pr.HasRequestedReviewer(reviewer) {
-					userReviewers = append(userReviewers, reviewer)
-				}
-			}
-			if len(userReviewers) > 0 || len(teamReviewers) > 0 {
-				err = client.RequestReview(baseProject, pr.Number, map[string]interface{}{
-					"reviewers":      userReviewers,
-					"team_reviewers": teamReviewers,
-				})
-				utils.Check(err)
+	flagPullRequestReviewers := flagOrDefaults(commaSeparated(args.Flag.AllValues("--reviewer")), defaults.Reviewers)
+	if len(flagPullRequestReviewers) == 0 {
+		return nil
+	}
+	userReviewers := []string{}
+	teamReviewers := []string{}
+	for _, reviewer := range flagPullRequestReviewers {
+		if strings.Contains(reviewer, "/") {
+			teamName := strings.SplitN(reviewer, "/", 2)[1]
+			if !pr.HasRequestedTeam(teamName) {
+				teamReviewers = append(teamReviewers, teamName)
 			}
+		} else if !pr.HasRequestedReviewer(reviewer) {
+			userReviewers = append(userReviewers, reviewer)
 		}
 	}
+	if len(userReviewers) == 0 && len(teamReviewers) == 0 {
+		return nil
+	}
+	return client.RequestReview(project, pr.Number, map[string]interface{}{
+		"reviewers":      userReviewers,
+		"team_reviewers": teamReviewers,
+	})
+}
 
-	args.NoForward()
-	printBrowseOrCopy(args, pullRequestURL, args.Flag.Bool("--browse"), args.Flag.Bool("--copy"))
+// offerToRememberBase asks whether a base branch given explicitly should be
+// the default for pull requests to the repository from now on, unless it's
+// the default branch already.
+func offerToRememberBase(localRepo *github.GitHubRepo, project *github.Project, remote *github.Remote, base string) {
+	var defaultBranch string
+	if remote != nil {
+		defaultBranch = localRepo.DefaultBranch(remote).ShortName()
+	} else if branch, err := project.DefaultBranch(); err == nil {
+		defaultBranch = branch
+	}
+	if defaultBranch == "" || base == defaultBranch {
+		return
+	}
+
+	if confirm(fmt.Sprintf("Remember %s as your default base for %s (y/N)? ", base, project)) {
+		utils.Check(github.SetProjectDefault(project, "base", base))
+	}
+}
+
+// flagOrDefaults returns the values given with a flag, falling back to the
+// defaults remembered for the project when there are none.
+func flagOrDefaults(values, defaults []string) []string {
+	if len(values) > 0 {
+		return values
+	}
+	return append([]string{}, defaults...)
 }
 
 func parsePullRequestProject(context *github.Project, s string) (p *github.Project, ref string) {
@@ -441,6 +688,30 @@ func parsePullRequestProject(context *github.Project, s string) (p *github.Proje
 	return
 }
 
+// pullRequestTemplateCommits is the most commits that the "{{commits}}"
+// placeholder of templates lists.
+const pullRequestTemplateCommits = 250
+
+// expandPullRequestTemplate fills in the placeholders of a pull request
+// template. Commits are listed newest first, as returned by git.RangeCommits,
+// and end up oldest first in the template. Without an issue, "{{issue}}" is
+// left for the author to fill in.
+func expandPullRequestTemplate(template, branch string, commits []git.RangeCommit, issue string) string {
+	subjects := []string{}
+	for i := len(commits) - 1; i >= 0; i-- {
+		subjects = append(subjects, "- "+commits[i].Subject)
+	}
+
+	replacements := []string{
+		"{{branch}}", branch,
+		"{{commits}}", strings.Join(subjects, "\n"),
+	}
+	if issue != "" {
+		replacements = append(replacements, "{{issue}}", issue)
+	}
+	return strings.NewReplacer(replacements...).Replace(template)
+}
+
 func parsePullRequestIssueNumber(url string) string {
 	u, e := github.ParseURL(url)
 	if e != nil {
@@ -456,14 +727,31 @@ func parsePullRequestIssueNumber(url string) string {
 	return ""
 }
 
+// findMilestoneNumber finds the milestone titled name. A title that matches
+// exactly wins over those that only differ in case; if there are several of
+// those, it's not clear which one was meant.
 func findMilestoneNumber(milestones []github.Milestone, name string) (int, error) {
+	matches := []github.Milestone{}
 	for _, milestone := range milestones {
-		if strings.EqualFold(milestone.Title, name) {
+		if milestone.Title == name {
 			return milestone.Number, nil
+		} else if strings.EqualFold(milestone.Title, name) {
+			matches = append(matches, milestone)
 		}
 	}
 
-	return 0, fmt.Errorf("error: no milestone found with name '%s'", name)
+	switch len(matches) {
+	case 0:
+		return 0, fmt.Errorf("error: no milestone found with name '%s'", name)
+	case 1:
+		return matches[0].Number, nil
+	default:
+		candidates := []string{}
+		for _, milestone := range matches {
+			candidates = append(candidates, fmt.Sprintf("%q (%d)", milestone.Title, milestone.Number))
+		}
+		return 0, fmt.Errorf("error: milestone name '%s' is ambiguous; use the number of one of: %s", name, strings.Join(candidates, ", "))
+	}
 }
 
 func commaSeparated(l []string) []string {
@@ -473,3 +761,73 @@ func commaSeparated(l []string) []string {
 	}
 	return res
 }
+
+// signoffIdentity returns the "Name <email>" that sign-offs by the current
+// git user carry.
+func signoffIdentity() (string, error) {
+	identity, err := git.CommitterIdent()
+	if err != nil {
+		return "", fmt.Errorf("Aborted: can't sign off: %s", err)
+	}
+	return identity, nil
+}
+
+// hasSignoff reports whether any of the commit trailers is a sign-off by the
+// same email address as in identity.
+func hasSignoff(trailers []string, identity string) bool {
+	email := identity
+	if i := strings.LastIndex(identity, "<"); i >= 0 {
+		email = identity[i:]
+	}
+	email = strings.ToLower(email)
+
+	for _, trailer := range trailers {
+		parts := strings.SplitN(trailer, ":", 2)
+		if len(parts) != 2 || !strings.EqualFold(strings.TrimSpace(parts[0]), "Signed-off-by") {
+			continue
+		}
+		if strings.Contains(strings.ToLower(parts[1]), email) {
+			return true
+		}
+	}
+	return false
+}
+
+func checkSignoffs(base, head, identity string) error {
+	commits, err := git.RefList(base, head)
+	if err != nil {
+		return fmt.Errorf("Aborted: can't check sign-offs: %s", err)
+	}
+
+	missing := []string{}
+	for _, sha := range commits {
+		trailers, err := git.Trailers(sha)
+		if err != nil {
+			return err
+		}
+		if !hasSignoff(trailers, identity) {
+			message, _ := git.Show(sha)
+			subject := strings.SplitN(message, "\n", 2)[0]
+			missing = append(missing, fmt.Sprintf("  %s %s", sha[0:7], subject))
+		}
+	}
+
+	if len(missing) == 0 {
+		return nil
+	}
+	err = fmt.Errorf("Aborted: %d of %d commits are not signed off by %s:\n%s", len(missing), len(commits), identity, strings.Join(missing, "\n"))
+	return fmt.Errorf("%s\n(use `git rebase --signoff %s` to sign them off, or `--signoff=append-body` to sign off in the description)", err, base)
+}
+
+func appendSignoff(body, identity string) string {
+	signoff := "Signed-off-by: " + identity
+	for _, line := range strings.Split(body, "\n") {
+		if strings.TrimSpace(line) == signoff {
+			return body
+		}
+	}
+	if body == "" {
+		return signoff
+	}
+	return body + "\n\n" + signoff
+}
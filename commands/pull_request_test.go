@@ -4,6 +4,7 @@ import (
 	"testing"
 
 	"github.com/bmizerany/assert"
+	"github.com/github/hub/git"
 	"github.com/github/hub/github"
 )
 
@@ -31,3 +32,62 @@ func TestPullRequest_ParsePullRequestProject(t *testing.T) {
 	assert.Equal(t, "mojombo", p.Owner)
 	assert.Equal(t, "jekyll", p.Name)
 }
+
+func TestPullRequest_HasSignoff(t *testing.T) {
+	trailers := []string{
+		"Co-authored-by: Josh <josh@example.com>",
+		"Signed-off-by: Josh <josh@example.com>",
+		"signed-off-by: Mislav <Mislav@Example.com>",
+	}
+	assert.T(t, hasSignoff(trailers, "Mislav Marohnić <mislav@example.com>"))
+	assert.T(t, hasSignoff(trailers, "Josh <josh@example.com>"))
+	assert.T(t, !hasSignoff(trailers, "Jingwen <jingweno@example.com>"))
+	assert.T(t, !hasSignoff([]string{"Co-authored-by: Jingwen <jingweno@example.com>"}, "Jingwen <jingweno@example.com>"))
+}
+
+func TestPullRequest_AppendSignoff(t *testing.T) {
+	assert.Equal(t, "Signed-off-by: Josh <josh@example.com>", appendSignoff("", "Josh <josh@example.com>"))
+	assert.Equal(t, "Fixes #1\n\nSigned-off-by: Josh <josh@example.com>", appendSignoff("Fixes #1", "Josh <josh@example.com>"))
+	assert.Equal(t, "Fixes #1\n\nSigned-off-by: Josh <josh@example.com>", appendSignoff("Fixes #1\n\nSigned-off-by: Josh <josh@example.com>", "Josh <josh@example.com>"))
+}
+
+func TestPullRequest_FlagOrDefaults(t *testing.T) {
+	defaults := []string{"josh", "github/core"}
+	assert.Equal(t, []string{"mislav"}, flagOrDefaults([]string{"mislav"}, defaults))
+	assert.Equal(t, defaults, flagOrDefaults([]string{}, defaults))
+	assert.Equal(t, []string{}, flagOrDefaults([]string{}, nil))
+}
+
+func TestExpandPullRequestTemplate(t *testing.T) {
+	commits := []git.RangeCommit{
+		{Sha: "b2", Subject: "Document the cache"},
+		{Sha: "a1", Subject: "Add a cache"},
+	}
+	template := "Branch: {{branch}}\n\n{{commits}}\n\nCloses #{{issue}} {{unknown}}"
+
+	assert.Equal(t, "Branch: feature\n\n- Add a cache\n- Document the cache\n\nCloses #12 {{unknown}}", expandPullRequestTemplate(template, "feature", commits, "12"))
+	assert.Equal(t, "Branch: feature\n\n\n\nCloses #{{issue}} {{unknown}}", expandPullRequestTemplate(template, "feature", nil, ""))
+}
+
+func TestFindMilestoneNumber(t *testing.T) {
+	milestones := []github.Milestone{
+		{Number: 1, Title: "v1.0"},
+		{Number: 2, Title: "Backlog"},
+		{Number: 3, Title: "backlog"},
+		{Number: 4, Title: "BACKLOG"},
+	}
+
+	number, err := findMilestoneNumber(milestones, "V1.0")
+	assert.Equal(t, nil, err)
+	assert.Equal(t, 1, number)
+
+	number, err = findMilestoneNumber(milestones, "backlog")
+	assert.Equal(t, nil, err)
+	assert.Equal(t, 3, number)
+
+	_, err = findMilestoneNumber(milestones, "Backlog ")
+	assert.Equal(t, "error: no milestone found with name 'Backlog '", err.Error())
+
+	_, err = findMilestoneNumber(milestones, "bAcklog")
+	assert.Equal(t, `error: milestone name 'bAcklog' is ambiguous; use the number of one of: "Backlog" (2), "backlog" (3), "BACKLOG" (4)`, err.Error())
+}
@@ -0,0 +1,136 @@
+package commands
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/github/hub/git"
+	"github.com/github/hub/github"
+)
+
+// pushGuardMarker identifies the pre-push hook that hub installs, so that it's
+// never mistaken for one written by the user.
+const pushGuardMarker = "# hub push guard for pull request checkouts"
+
+// pushGuardHook refuses to push a branch to the remote recorded in its
+// "branch.<name>.hubPushGuard" config. Pushing HEAD counts as pushing the
+// current branch, which covers `git push origin HEAD:main`.
+const pushGuardHook = `#!/bin/sh
+` + pushGuardMarker + `
+# Installed by 'hub pr checkout --protect'. Remove the guard for a branch with
+# 'hub pr checkout --unprotect <BRANCH>'; hub deletes this hook once no
+# branches are guarded.
+remote="$1"
+while read -r local_ref local_sha remote_ref remote_sha; do
+	case "$local_ref" in
+	refs/heads/*) branch="${local_ref#refs/heads/}" ;;
+	HEAD) branch="$(git symbolic-ref -q --short HEAD)" ;;
+	*) continue ;;
+	esac
+	[ -n "$branch" ] || continue
+	if [ "$(git config "branch.$branch.hubPushGuard")" = "$remote" ]; then
+		echo "hub: refusing to push '$branch' to '$remote', the base repository of its pull request" >&2
+		echo "(run 'hub pr checkout --unprotect $branch' to allow it)" >&2
+		exit 1
+	fi
+done
+exit 0
+`
+
+func pushGuardConfigKey(branch string) string {
+	return fmt.Sprintf("branch.%s.hubPushGuard", branch)
+}
+
+func pushGuardHookPath() (string, error) {
+	return git.GitPath("hooks", "pre-push")
+}
+
+// checkPushGuardHook returns an error if a pre-push hook that hub didn't
+// install is in the way of the push guard.
+func checkPushGuardHook() error {
+	hookPath, err := pushGuardHookPath()
+	if err != nil {
+		return err
+	}
+	content, err := ioutil.ReadFile(hookPath)
+	if os.IsNotExist(err) {
+		return nil
+	} else if err != nil {
+		return err
+	}
+	if !strings.Contains(string(content), pushGuardMarker) {
+		return fmt.Errorf("a pre-push hook that wasn't installed by hub already exists at %s", hookPath)
+	}
+	return nil
+}
+
+func installPushGuardHook() error {
+	if err := checkPushGuardHook(); err != nil {
+		return err
+	}
+	hookPath, err := pushGuardHookPath()
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(hookPath), 0755); err != nil {
+		return err
+	}
+	return ioutil.WriteFile(hookPath, []byte(pushGuardHook), 0755)
+}
+
+// removeUnusedPushGuardHook deletes the pre-push hook installed by hub once no
+// branch is guarded anymore. A hook in a 'core.hooksPath' outside of the git
+// directory may be shared with other repositories, so it's left alone.
+func removeUnusedPushGuardHook() error {
+	if guarded, err := git.ConfigAll(`^branch\..*\.hubpushguard$`); err == nil && len(guarded) > 0 {
+		return nil
+	}
+	hookPath, err := pushGuardHookPath()
+	if err != nil {
+		return err
+	}
+	if hooksPath, _ := git.Config("core.hooksPath"); hooksPath != "" {
+		gitDir, err := git.Dir()
+		if err != nil {
+			return err
+		}
+		if rel, err := filepath.Rel(gitDir, hookPath); err != nil || strings.HasPrefix(rel, "..") {
+			return nil
+		}
+	}
+	content, err := ioutil.ReadFile(hookPath)
+	if err != nil || !strings.Contains(string(content), pushGuardMarker) {
+		return nil
+	}
+	return os.Remove(hookPath)
+}
+
+// pullRequestPushRemote returns where new commits to the checkout of pr should
+// be pushed: the git remote for its head repository, or the URL of that
+// repository if there's no such remote.
+func pullRequestPushRemote(localRepo *github.GitHubRepo, pr *github.PullRequest) string {
+	if pr.Head.Repo == nil {
+		return ""
+	}
+	if remote, err := localRepo.RemoteForRepo(pr.Head.Repo); err == nil {
+		return remote.Name
+	}
+	if project, err := github.NewProjectFromRepo(pr.Head.Repo); err == nil {
+		return project.GitURL("", "", true)
+	}
+	return ""
+}
+
+func unprotectBranch(branch string) error {
+	key := pushGuardConfigKey(branch)
+	if remote, _ := git.Config(key); remote == "" {
+		return fmt.Errorf("Aborted: branch '%s' is not protected", branch)
+	}
+	if err := git.Spawn("config", "--unset", key); err != nil {
+		return err
+	}
+	return removeUnusedPushGuardHook()
+}
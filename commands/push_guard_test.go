@@ -0,0 +1,77 @@
+package commands
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/bmizerany/assert"
+	"github.com/github/hub/fixtures"
+	"github.com/github/hub/git"
+)
+
+func TestPushGuardHook(t *testing.T) {
+	repo := fixtures.SetupTestRepo()
+	defer repo.TearDown()
+
+	hookPath, err := pushGuardHookPath()
+	assert.Equal(t, nil, err)
+
+	assert.Equal(t, nil, installPushGuardHook())
+	info, err := os.Stat(hookPath)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, os.FileMode(0755), info.Mode().Perm())
+
+	// the hook stays while a branch is guarded
+	assert.T(t, git.Quiet("config", pushGuardConfigKey("feature"), "origin"))
+	assert.Equal(t, nil, removeUnusedPushGuardHook())
+	_, err = os.Stat(hookPath)
+	assert.Equal(t, nil, err)
+
+	assert.Equal(t, nil, unprotectBranch("feature"))
+	_, err = os.Stat(hookPath)
+	assert.T(t, os.IsNotExist(err))
+
+	err = unprotectBranch("feature")
+	assert.Equal(t, "Aborted: branch 'feature' is not protected", err.Error())
+}
+
+func TestPushGuardHook_existingHook(t *testing.T) {
+	repo := fixtures.SetupTestRepo()
+	defer repo.TearDown()
+
+	hookPath, err := pushGuardHookPath()
+	assert.Equal(t, nil, err)
+	assert.Equal(t, nil, os.MkdirAll(filepath.Dir(hookPath), 0755))
+	assert.Equal(t, nil, ioutil.WriteFile(hookPath, []byte("#!/bin/sh\nexit 0\n"), 0755))
+
+	err = installPushGuardHook()
+	assert.Equal(t, "a pre-push hook that wasn't installed by hub already exists at "+hookPath, err.Error())
+
+	// a hook that hub didn't install is never removed
+	assert.Equal(t, nil, removeUnusedPushGuardHook())
+	content, err := ioutil.ReadFile(hookPath)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, "#!/bin/sh\nexit 0\n", string(content))
+}
+
+func TestPushGuardHook_sharedHooksPath(t *testing.T) {
+	repo := fixtures.SetupTestRepo()
+	defer repo.TearDown()
+
+	hooksDir, err := ioutil.TempDir("", "hooks")
+	assert.Equal(t, nil, err)
+	defer os.RemoveAll(hooksDir)
+	assert.T(t, git.Quiet("config", "core.hooksPath", hooksDir))
+
+	assert.Equal(t, nil, installPushGuardHook())
+	hookPath := filepath.Join(hooksDir, "pre-push")
+	_, err = os.Stat(hookPath)
+	assert.Equal(t, nil, err)
+
+	// other repositories may rely on a hook outside of the git directory
+	assert.Equal(t, nil, removeUnusedPushGuardHook())
+	_, err = os.Stat(hookPath)
+	assert.Equal(t, nil, err)
+}
@@ -1,13 +1,20 @@
 package commands
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
 	"fmt"
 	"io"
+	"io/ioutil"
 	"os"
 	"path/filepath"
+	"sort"
+	"strconv"
 	"strings"
 	"time"
 
+	"github.com/github/hub/git"
 	"github.com/github/hub/github"
 	"github.com/github/hub/ui"
 	"github.com/github/hub/utils"
@@ -17,11 +24,12 @@ var (
 	cmdRelease = &Command{
 		Run: listReleases,
 		Usage: `
-release [--include-drafts] [--exclude-prereleases] [-L <LIMIT>] [-f <FORMAT>]
-release show [-f <FORMAT>] <TAG>
-release create [-dpoc] [-a <FILE>] [-m <MESSAGE>|-F <FILE>] [-t <TARGET>] <TAG>
+release [--include-drafts] [--exclude-prereleases] [--since <DATE>] [--until <DATE>] [--sort <KEY>] [-L <LIMIT>] [--exact] [-f <FORMAT>|--output <FORMAT> [--columns <COLUMNS>]]
+release show [-d] [--exact] [-f <FORMAT>] <TAG>
+release create [-dpoc] [-a <FILE> [--parallel <N>]] [-m <MESSAGE>|-F <FILE>] [--changelog[=<FILE>]] [-t <TARGET>] [--idempotency-key <KEY>] [--force] <TAG>
 release edit [<options>] <TAG>
-release download <TAG>
+release diff [--assets-only|--body-only] <TAG> <TAG>
+release download [-i <GLOB>] [--output-dir <DIR>] [--clobber] [--manifest <FILE>] [--unpack [--strip-components <N>]] <TAG>
 release delete <TAG>
 `,
 		Long: `Manage GitHub Releases for the current repository.
@@ -32,11 +40,14 @@ With no arguments, shows a list of existing releases.
 
 With '--include-drafts', include draft releases in the listing.
 With '--exclude-prereleases', exclude non-stable releases from the listing.
+With '--since' and '--until', list only the releases published in that
+period.
 
 	* _show_:
 		Show GitHub release notes for <TAG>.
 
-		With '--show-downloads', include the "Downloads" section.
+		With '--show-downloads', include the "Downloads" section, which lists the
+		size and download count of each asset.
 
 	* _create_:
 		Create a GitHub release for the specified <TAG> name. If git tag <TAG>
@@ -50,8 +61,29 @@ With '--exclude-prereleases', exclude non-stable releases from the listing.
 		pre-populated with current release title and body. To re-use existing title
 		and body unchanged, pass '-m ""'.
 
+		Replacing the body of a published release with <MESSAGE> or <FILE>
+		requires '--force'. With '--append-body', the text of <MESSAGE> or <FILE>
+		is instead added to the end of the existing body, below a horizontal
+		rule, and the title is left unchanged.
+
+	* _diff_:
+		Compare two releases: the difference of their bodies in unified diff
+		format, the assets that were added, removed, or changed in size, and the
+		number of commits between both tags. Either <TAG> may be given as
+		"OWNER/REPO@TAG" to compare with a release of another repository, in
+		which case commits aren't compared.
+
+		Exits with status 0 when the bodies and assets of both releases are the
+		same, and with 1 otherwise.
+
 	* _download_:
-		Download the assets attached to release for the specified <TAG>.
+		Download the assets attached to release for the specified <TAG> into the
+		current directory, or the one given with '--output-dir'. Files that are
+		already there are left alone unless '--clobber' is given.
+
+		With '--manifest', also write a JSON file describing the downloaded
+		assets. With '--unpack', extract the assets that are tar, gzipped tar or
+		zip archives into the current directory.
 
 	* _delete_:
 		Delete the release and associated assets for the specified <TAG>. Note that
@@ -61,6 +93,29 @@ With '--exclude-prereleases', exclude non-stable releases from the listing.
 	-L, --limit
 		Display only the first <LIMIT> releases.
 
+	--since <DATE>
+		List only releases published at or after <DATE>, given as "YYYY-MM-DD" or
+		in ISO 8601 format. Draft releases count by their creation date.
+
+	--until <DATE>
+		List only releases published before the end of <DATE>, or before the
+		exact time if one is given.
+
+	--sort <KEY>
+		Order the listed releases by <KEY>, either "published" (newest first) or
+		"downloads" (the most downloaded first). Releases are sorted after
+		filtering and before '--limit' takes effect.
+
+	--output <FORMAT>
+		Print the list of releases as a table in <FORMAT>, either "markdown",
+		"html", or "csv". Tags link to the releases on GitHub except in CSV.
+
+	--columns <COLUMNS>
+		A comma-separated list of the columns to include with '--output'
+		(default: "tag,name,published,downloads"). The available columns are:
+		"tag", "name", "state", "created", "published", "assets", "downloads",
+		and "url".
+
 	-d, --draft
 		Create a draft release.
 
@@ -73,6 +128,16 @@ With '--exclude-prereleases', exclude non-stable releases from the listing.
 		If <FILE> is in the "<filename>#<text>" format, the text after the '#'
 		character is taken as asset label.
 
+	--parallel <N>
+		Upload up to <N> assets at the same time (default: 3). An upload that
+		fails because of the network or the server is retried a couple of times,
+		and doesn't stop the others; the assets that couldn't be attached are
+		listed at the end. While uploading to a terminal, the progress of each
+		asset is reported on standard error.
+
+		With '--parallel 1', assets are uploaded one by one without retrying, and
+		the first failure aborts the rest.
+
 	-m, --message <MESSAGE>
 		The text up to the first blank line in <MESSAGE> is treated as the release
 		title, and the rest is used as release description in Markdown format.
@@ -83,6 +148,14 @@ With '--exclude-prereleases', exclude non-stable releases from the listing.
 	-F, --file <FILE>
 		Read the release title and description from <FILE>.
 
+	--changelog[=<FILE>]
+		Use the section for <TAG> in a changelog that follows the "Keep a
+		Changelog" format as release description (default: "CHANGELOG.md").
+		Headings such as "## [1.2.3] - 2024-01-01" or "## v1.2.3" match <TAG>
+		with or without its leading "v". Unless '--message' or '--file' give the
+		title, the release is titled after <TAG>; the rest of their text is put
+		above the changelog section.
+
 	-e, --edit
 		Further edit the contents of <FILE> in a text editor before submitting.
 
@@ -96,6 +169,49 @@ With '--exclude-prereleases', exclude non-stable releases from the listing.
 		A commit SHA or branch name to attach the release to, only used if <TAG>
 		does not already exist (default: main branch).
 
+	--idempotency-key <KEY>
+		Record the URL of the new release under <KEY>. When the command is repeated
+		with the same <KEY> within 24 hours after succeeding, the recorded URL is
+		printed again instead of creating another release. A release whose assets
+		failed to attach doesn't count as a success.
+
+	--force
+		With _create_, try to create the release even if the repository is
+		disabled. Releases can't be created in archived repositories either way.
+
+	--assets-only
+		Only compare the assets of releases with _diff_.
+
+	--body-only
+		Only compare the bodies of releases with _diff_.
+
+	-i, --include <GLOB>
+		Download only the assets whose names match <GLOB>, e.g. "*.tar.gz". Can
+		be given multiple times. It's an error if some <GLOB> matches no asset.
+
+	--output-dir <DIR>
+		Save downloaded assets to <DIR> instead of the current directory. The
+		directory is created if needed.
+
+	--clobber
+		Overwrite files that have the same name as a downloaded asset instead of
+		refusing to download.
+
+	--manifest <FILE>
+		Write a JSON list of the downloaded assets to <FILE>, with the "name",
+		"size", "sha256" checksum, and source "url" of each asset.
+
+	--unpack
+		Extract downloaded assets that are ".tar.gz", ".tgz", ".tar" or ".zip"
+		archives after downloading them. File modes are preserved, and entries
+		that would be written outside of the current directory abort the
+		extraction.
+
+	--strip-components <N>
+		Remove <N> leading path components from the names of archive entries
+		when extracting them with '--unpack', like tar(1) does. Entries that are
+		left without a name are skipped.
+
 	-f, --format <FORMAT>
 		Pretty print releases using <FORMAT> (default: "%T%n"). See the "PRETTY
 		FORMATS" section of git-log(1) for some additional details on how
@@ -121,6 +237,9 @@ With '--exclude-prereleases', exclude non-stable releases from the listing.
 
 		%as: the list of assets attached to this release
 
+		%dl: the number of downloads of all assets of this release, abbreviated
+		as e.g. "1.2k" unless '--exact' is given
+
 		%cD: created date-only (no time of day)
 
 		%cr: created date, relative
@@ -141,6 +260,10 @@ With '--exclude-prereleases', exclude non-stable releases from the listing.
 
 		%%: a literal %
 
+	--exact
+		Print download counts and sizes as plain numbers, e.g. for scripts,
+		instead of abbreviating them as "1.2k" or "3.4 MiB".
+
 	--color[=<WHEN>]
 		Enable colored output even if stdout is not a terminal. <WHEN> can be one
 		of "always" (default for '--color'), "never", or "auto" (default).
@@ -156,9 +279,22 @@ hub(1), git-tag(1)
 		-d, --include-drafts
 		-p, --exclude-prereleases
 		-L, --limit N
+		--since DATE
+		--until DATE
+		--sort KEY
 		-f, --format FMT
+		--output FORMAT
+		--columns COLUMNS
+		--exact
 		--color
 `,
+		FlagValues: map[string]flagValue{
+			"--limit": limitValue,
+			"--since": dateValue(),
+			"--until": dateValue(),
+			"--sort":  enumValue("published", "downloads"),
+			"--color": colorValue,
+		},
 	}
 
 	cmdShowRelease = &Command{
@@ -167,8 +303,12 @@ hub(1), git-tag(1)
 		KnownFlags: `
 		-d, --show-downloads
 		-f, --format FMT
+		--exact
 		--color
 `,
+		FlagValues: map[string]flagValue{
+			"--color": colorValue,
+		},
 	}
 
 	cmdCreateRelease = &Command{
@@ -181,10 +321,17 @@ hub(1), git-tag(1)
 		-o, --browse
 		-c, --copy
 		-a, --attach FILE
+		--parallel N
 		-m, --message MSG
 		-F, --file FILE
+		--changelog
 		-t, --commitish C
+		--idempotency-key KEY
+		--force
 `,
+		FlagValues: map[string]flagValue{
+			"--parallel": intValue(1),
+		},
 	}
 
 	cmdEditRelease = &Command{
@@ -195,15 +342,41 @@ hub(1), git-tag(1)
 		-d, --draft
 		-p, --prerelease
 		-a, --attach FILE
+		--parallel N
 		-m, --message MSG
 		-F, --file FILE
 		-t, --commitish C
+		--append-body
+		--force
+`,
+		FlagValues: map[string]flagValue{
+			"--parallel": intValue(1),
+		},
+	}
+
+	cmdDiffRelease = &Command{
+		Key: "diff",
+		Run: diffRelease,
+		KnownFlags: `
+		--assets-only
+		--body-only
 `,
 	}
 
 	cmdDownloadRelease = &Command{
 		Key: "download",
 		Run: downloadRelease,
+		KnownFlags: `
+		-i, --include GLOB
+		--output-dir DIR
+		--clobber
+		--manifest FILE
+		--unpack
+		--strip-components N
+`,
+		FlagValues: map[string]flagValue{
+			"--strip-components": intValue(0),
+		},
 	}
 
 	cmdDeleteRelease = &Command{
@@ -216,6 +389,7 @@ func init() {
 	cmdRelease.Use(cmdShowRelease)
 	cmdRelease.Use(cmdCreateRelease)
 	cmdRelease.Use(cmdEditRelease)
+	cmdRelease.Use(cmdDiffRelease)
 	cmdRelease.Use(cmdDownloadRelease)
 	cmdRelease.Use(cmdDeleteRelease)
 	CmdRunner.Use(cmdRelease)
@@ -233,30 +407,120 @@ func listReleases(cmd *Command, args *Args) {
 	flagReleaseLimit := args.Flag.Int("--limit")
 	flagReleaseIncludeDrafts := args.Flag.Bool("--include-drafts")
 	flagReleaseExcludePrereleases := args.Flag.Bool("--exclude-prereleases")
+	flagReleaseSort := args.Flag.Value("--sort")
+	flagReleaseFormat := "%T%n"
+	if args.Flag.HasReceived("--format") {
+		flagReleaseFormat = args.Flag.Value("--format")
+	}
+	format, err := ui.CompileFormat(flagReleaseFormat)
+	utils.Check(err)
+
+	since, until := releaseDateRange(args.Flag.Value("--since"), args.Flag.Value("--until"))
+	if !since.IsZero() && !until.IsZero() && !since.Before(until) {
+		utils.Check(cmd.UsageError("the '--since' date must be before the '--until' date"))
+	}
+
+	export, err := parseReleaseExport(cmd, args)
+	utils.Check(err)
 
 	if args.Noop {
 		ui.Printf("Would request list of releases for %s\n", project)
 	} else {
-		releases, err := gh.FetchReleases(project, flagReleaseLimit, func(release *github.Release) bool {
+		fetchLimit := flagReleaseLimit
+		if flagReleaseSort != "" {
+			fetchLimit = -1
+		}
+		releases, err := gh.FetchReleases(project, fetchLimit, func(release *github.Release) bool {
+			date := releaseDate(release)
 			return (!release.Draft || flagReleaseIncludeDrafts) &&
-				(!release.Prerelease || !flagReleaseExcludePrereleases)
+				(!release.Prerelease || !flagReleaseExcludePrereleases) &&
+				(since.IsZero() || !date.Before(since)) &&
+				(until.IsZero() || date.Before(until))
 		})
 		utils.Check(err)
 
-		colorize := colorizeOutput(args.Flag.HasReceived("--color"), args.Flag.Value("--color"))
-		for _, release := range releases {
-			flagReleaseFormat := "%T%n"
-			if args.Flag.HasReceived("--format") {
-				flagReleaseFormat = args.Flag.Value("--format")
+		sortReleases(releases, flagReleaseSort)
+		if flagReleaseLimit > 0 && len(releases) > flagReleaseLimit {
+			releases = releases[:flagReleaseLimit]
+		}
+
+		if export != nil {
+			ui.Print(export.render(releases))
+		} else {
+			colorize := colorizeOutput(args.Flag.HasReceived("--color"), args.Flag.Value("--color"))
+			exact := args.Flag.Bool("--exact")
+			for _, release := range releases {
+				ui.Print(formatRelease(release, format, colorize, exact))
 			}
-			ui.Print(formatRelease(release, flagReleaseFormat, colorize))
 		}
 	}
 
 	args.NoForward()
 }
 
-func formatRelease(release github.Release, format string, colorize bool) string {
+// releaseDate is when a release was published, or created if it's a draft.
+func releaseDate(release *github.Release) time.Time {
+	if release.PublishedAt.IsZero() {
+		return release.CreatedAt
+	}
+	return release.PublishedAt
+}
+
+// releaseDateRange turns the '--since' and '--until' values into the start
+// and the end of the listed period. An '--until' date without a time of day
+// includes that whole day.
+func releaseDateRange(sinceValue, untilValue string) (since, until time.Time) {
+	if sinceValue != "" {
+		since, _, _ = parseDateValue(sinceValue)
+	}
+	if untilValue != "" {
+		var dateOnly bool
+		until, dateOnly, _ = parseDateValue(untilValue)
+		if dateOnly {
+			until = until.AddDate(0, 0, 1)
+		}
+	}
+	return
+}
+
+// formatCount abbreviates n unless exact numbers were asked for.
+func formatCount(n int, exact bool) string {
+	if exact {
+		return strconv.Itoa(n)
+	}
+	return utils.HumanCount(n)
+}
+
+// formatSize abbreviates a number of bytes unless exact numbers were asked for.
+func formatSize(bytes int64, exact bool) string {
+	if exact {
+		return fmt.Sprintf("%d bytes", bytes)
+	}
+	return utils.HumanSize(bytes)
+}
+
+func releaseDownloads(release github.Release) int {
+	count := 0
+	for _, asset := range release.Assets {
+		count += asset.DownloadCount
+	}
+	return count
+}
+
+func sortReleases(releases []github.Release, key string) {
+	switch key {
+	case "published":
+		sort.SliceStable(releases, func(i, j int) bool {
+			return releaseDate(&releases[i]).After(releaseDate(&releases[j]))
+		})
+	case "downloads":
+		sort.SliceStable(releases, func(i, j int) bool {
+			return releaseDownloads(releases[i]) > releaseDownloads(releases[j])
+		})
+	}
+}
+
+func formatRelease(release github.Release, format *ui.Format, colorize, exact bool) string {
 	state := ""
 	stateColorSwitch := ""
 	if release.Draft {
@@ -298,6 +562,7 @@ func formatRelease(release github.Release, format string, colorize bool) string
 		"T":  release.TagName,
 		"b":  release.Body,
 		"as": strings.Join(assets, "\n"),
+		"dl": formatCount(releaseDownloads(release), exact),
 		"cD": createdDate,
 		"cI": createdAtISO8601,
 		"ct": createdAtUnix,
@@ -308,7 +573,7 @@ func formatRelease(release github.Release, format string, colorize bool) string
 		"pr": publishedAtRelative,
 	}
 
-	return ui.Expand(format, placeholders, colorize)
+	return format.Expand(placeholders, colorize)
 }
 
 func showRelease(cmd *Command, args *Args) {
@@ -326,6 +591,12 @@ func showRelease(cmd *Command, args *Args) {
 	project, err := localRepo.MainProject()
 	utils.Check(err)
 
+	var format *ui.Format
+	if flagShowReleaseFormat := args.Flag.Value("--format"); flagShowReleaseFormat != "" {
+		format, err = ui.CompileFormat(flagShowReleaseFormat)
+		utils.Check(err)
+	}
+
 	gh := github.NewClient(project.Host)
 
 	args.NoForward()
@@ -339,8 +610,8 @@ func showRelease(cmd *Command, args *Args) {
 		body := strings.TrimSpace(release.Body)
 
 		colorize := colorizeOutput(args.Flag.HasReceived("--color"), args.Flag.Value("--color"))
-		if flagShowReleaseFormat := args.Flag.Value("--format"); flagShowReleaseFormat != "" {
-			ui.Print(formatRelease(*release, flagShowReleaseFormat, colorize))
+		if format != nil {
+			ui.Print(formatRelease(*release, format, colorize, args.Flag.Bool("--exact")))
 			return
 		}
 
@@ -350,8 +621,17 @@ func showRelease(cmd *Command, args *Args) {
 		}
 		if args.Flag.Bool("--show-downloads") {
 			ui.Printf("\n## Downloads\n\n")
+			exact := args.Flag.Bool("--exact")
 			for _, asset := range release.Assets {
-				ui.Println(asset.DownloadUrl)
+				if asset.Size > 0 {
+					downloads := "downloads"
+					if asset.DownloadCount == 1 {
+						downloads = "download"
+					}
+					ui.Printf("%s (%s, %s %s)\n", asset.DownloadUrl, formatSize(asset.Size, exact), formatCount(asset.DownloadCount, exact), downloads)
+				} else {
+					ui.Println(asset.DownloadUrl)
+				}
 			}
 			if release.ZipballUrl != "" {
 				ui.Println(release.ZipballUrl)
@@ -361,6 +641,15 @@ func showRelease(cmd *Command, args *Args) {
 	}
 }
 
+// releaseManifestEntry describes a downloaded asset in the file written by
+// 'release download --manifest'.
+type releaseManifestEntry struct {
+	Name   string `json:"name"`
+	Size   int64  `json:"size"`
+	Sha256 string `json:"sha256"`
+	URL    string `json:"url"`
+}
+
 func downloadRelease(cmd *Command, args *Args) {
 	tagName := ""
 	if args.ParamsSize() > 0 {
@@ -370,6 +659,15 @@ func downloadRelease(cmd *Command, args *Args) {
 		utils.Check(cmd.UsageError(""))
 	}
 
+	flagUnpack := args.Flag.Bool("--unpack")
+	strip := 0
+	if args.Flag.HasReceived("--strip-components") {
+		if !flagUnpack {
+			utils.Check(cmd.UsageError("the '--strip-components' option requires '--unpack'"))
+		}
+		strip = args.Flag.Int("--strip-components")
+	}
+
 	localRepo, err := github.LocalRepo()
 	utils.Check(err)
 
@@ -381,32 +679,120 @@ func downloadRelease(cmd *Command, args *Args) {
 	release, err := gh.FetchRelease(project, tagName)
 	utils.Check(err)
 
-	for _, asset := range release.Assets {
-		ui.Printf("Downloading %s ...\n", asset.Name)
-		err := downloadReleaseAsset(asset, gh)
+	assets, err := matchingReleaseAssets(release.Assets, args.Flag.AllValues("--include"))
+	utils.Check(err)
+
+	outputDir := "."
+	if args.Flag.HasReceived("--output-dir") {
+		outputDir = args.Flag.Value("--output-dir")
+		utils.Check(os.MkdirAll(outputDir, 0755))
+	}
+
+	flagClobber := args.Flag.Bool("--clobber")
+	if !flagClobber {
+		for _, asset := range assets {
+			if _, err := os.Stat(filepath.Join(outputDir, asset.Name)); err == nil {
+				utils.Check(fmt.Errorf("Error: %s already exists; use '--clobber' to overwrite it", filepath.Join(outputDir, asset.Name)))
+			}
+		}
+	}
+
+	manifest := []releaseManifestEntry{}
+	for _, asset := range assets {
+		if asset.Size > 0 {
+			ui.Printf("Downloading %s (%s) ...\n", asset.Name, utils.HumanSize(asset.Size))
+		} else {
+			ui.Printf("Downloading %s ...\n", asset.Name)
+		}
+		entry, err := downloadReleaseAsset(asset, gh, outputDir, flagClobber)
 		utils.Check(err)
+		manifest = append(manifest, entry)
+
+		if flagUnpack && isUnpackableArchive(asset.Name) {
+			ui.Printf("Unpacking %s ...\n", asset.Name)
+			err = unpackArchive(filepath.Join(outputDir, asset.Name), outputDir, strip)
+			utils.Check(err)
+		}
 	}
 
+	if manifestFile := args.Flag.Value("--manifest"); manifestFile != "" {
+		data, err := json.MarshalIndent(manifest, "", "  ")
+		utils.Check(err)
+		err = ioutil.WriteFile(manifestFile, append(data, '\n'), 0644)
+		utils.Check(err)
+	}
+
+	ui.Emit(ui.EventResult, ui.Fields{"command": "release download", "tag": tagName, "assets": manifest})
 	args.NoForward()
 }
 
-func downloadReleaseAsset(asset github.ReleaseAsset, gh *github.Client) (err error) {
+// matchingReleaseAssets picks the assets whose names match any of globs, or
+// all of them when no globs are given. Each glob has to match some asset.
+func matchingReleaseAssets(assets []github.ReleaseAsset, globs []string) ([]github.ReleaseAsset, error) {
+	if len(globs) == 0 {
+		return assets, nil
+	}
+
+	matched := []github.ReleaseAsset{}
+	matchedGlobs := map[string]bool{}
+	for _, asset := range assets {
+		isMatch := false
+		for _, glob := range globs {
+			ok, err := filepath.Match(glob, asset.Name)
+			if err != nil {
+				return nil, fmt.Errorf("Error: invalid pattern `%s'", glob)
+			}
+			if ok {
+				matchedGlobs[glob] = true
+				isMatch = true
+			}
+		}
+		if isMatch {
+			matched = append(matched, asset)
+		}
+	}
+
+	for _, glob := range globs {
+		if !matchedGlobs[glob] {
+			return nil, fmt.Errorf("Error: no assets match `%s'", glob)
+		}
+	}
+	return matched, nil
+}
+
+func downloadReleaseAsset(asset github.ReleaseAsset, gh *github.Client, dir string, clobber bool) (entry releaseManifestEntry, err error) {
 	assetReader, err := gh.DownloadReleaseAsset(asset.ApiUrl)
 	if err != nil {
 		return
 	}
 	defer assetReader.Close()
 
-	assetFile, err := os.OpenFile(asset.Name, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0644)
+	mode := os.O_WRONLY | os.O_CREATE | os.O_EXCL
+	if clobber {
+		mode = os.O_WRONLY | os.O_CREATE | os.O_TRUNC
+	}
+	assetFile, err := os.OpenFile(filepath.Join(dir, asset.Name), mode, 0644)
 	if err != nil {
 		return
 	}
 	defer assetFile.Close()
 
-	_, err = io.Copy(assetFile, assetReader)
+	hash := sha256.New()
+	progress := ui.NewProgress("Downloading "+asset.Name, asset.Size)
+	progress.Event = ui.EventAssetDownload
+	progress.Name = asset.Name
+	size, err := io.Copy(progress.Writer(io.MultiWriter(assetFile, hash)), assetReader)
+	progress.Done()
 	if err != nil {
 		return
 	}
+
+	entry = releaseManifestEntry{
+		Name:   asset.Name,
+		Size:   size,
+		Sha256: hex.EncodeToString(hash.Sum(nil)),
+		URL:    asset.DownloadUrl,
+	}
 	return
 }
 
@@ -427,6 +813,7 @@ func createRelease(cmd *Command, args *Args) {
 	utils.Check(err)
 
 	gh := github.NewClient(project.Host)
+	utils.Check(gh.EnsureWritable(project, args.Flag.Bool("--force")))
 
 	messageBuilder := &github.MessageBuilder{
 		Filename: "RELEASE_EDITMSG",
@@ -446,10 +833,26 @@ text is the title and the rest is the description.`, tagName, project))
 		messageBuilder.Message, err = msgFromFile(args.Flag.Value("--file"))
 		utils.Check(err)
 		messageBuilder.Edit = args.Flag.Bool("--edit")
-	} else {
+	} else if !args.Flag.HasReceived("--changelog") {
 		messageBuilder.Edit = true
 	}
 
+	if args.Flag.HasReceived("--changelog") {
+		changelogFile := args.Flag.Value("--changelog")
+		if changelogFile == "" {
+			changelogFile = defaultChangelogFile
+		}
+		section, err := changelogSection(changelogFile, tagName)
+		utils.Check(err)
+		if messageBuilder.Message == "" {
+			messageBuilder.Message = tagName
+		}
+		if section != "" {
+			messageBuilder.Message = strings.TrimRight(messageBuilder.Message, "\n") + "\n\n" + section
+		}
+		messageBuilder.Edit = args.Flag.Bool("--edit")
+	}
+
 	title, body, err := messageBuilder.Extract()
 	utils.Check(err)
 
@@ -457,33 +860,64 @@ text is the title and the rest is the description.`, tagName, project))
 		utils.Check(fmt.Errorf("Aborting release due to empty release title"))
 	}
 
+	flagReleaseCommitish := args.Flag.Value("--commitish")
+	if flagReleaseCommitish != "" {
+		utils.Check(verifyCommitish(gh, project, flagReleaseCommitish))
+	}
+
 	params := &github.Release{
 		TagName:         tagName,
-		TargetCommitish: args.Flag.Value("--commitish"),
+		TargetCommitish: flagReleaseCommitish,
 		Name:            title,
 		Body:            body,
 		Draft:           args.Flag.Bool("--draft"),
 		Prerelease:      args.Flag.Bool("--prerelease"),
 	}
 
-	var release *github.Release
+	flagReleaseAssets := args.Flag.AllValues("--attach")
+	flagReleaseBrowse := args.Flag.Bool("--browse")
+	flagReleaseCopy := args.Flag.Bool("--copy")
 
 	args.NoForward()
 	if args.Noop {
 		ui.Printf("Would create release `%s' for %s with tag name `%s'\n", title, project, tagName)
-	} else {
-		release, err = gh.CreateRelease(project, params)
-		utils.Check(err)
-
-		flagReleaseBrowse := args.Flag.Bool("--browse")
-		flagReleaseCopy := args.Flag.Bool("--copy")
-		printBrowseOrCopy(args, release.HtmlUrl, flagReleaseBrowse, flagReleaseCopy)
+		messageBuilder.Cleanup()
+		utils.Check(uploadAssets(gh, nil, flagReleaseAssets, args))
+		emitReleaseResult("release create", tagName, "", flagReleaseAssets)
+		return
 	}
 
-	messageBuilder.Cleanup()
+	// the key is only recorded once the assets are attached, so that retrying
+	// after a failed upload doesn't skip them
+	releaseURL, replayed, err := performIdempotently(args, project.Host, func() (string, bool, error) {
+		release, err := gh.CreateRelease(project, params)
+		if err != nil {
+			return "", false, err
+		}
+		messageBuilder.Cleanup()
+		if err := uploadAssets(gh, release, flagReleaseAssets, args); err != nil {
+			return release.HtmlUrl, false, err
+		}
+		return release.HtmlUrl, true, nil
+	})
+	utils.Check(err)
 
-	flagReleaseAssets := args.Flag.AllValues("--attach")
-	uploadAssets(gh, release, flagReleaseAssets, args)
+	printBrowseOrCopy(args, releaseURL, flagReleaseBrowse, flagReleaseCopy)
+	if replayed {
+		messageBuilder.Cleanup()
+		flagReleaseAssets = nil
+	}
+	emitReleaseResult("release create", tagName, releaseURL, flagReleaseAssets)
+}
+
+// emitReleaseResult reports the release that was created or edited, and the
+// files that were attached to it, for '--porcelain'.
+func emitReleaseResult(command, tagName, url string, assets []string) {
+	names := []string{}
+	for _, asset := range assets {
+		names = append(names, filepath.Base(strings.SplitN(asset, "#", 2)[0]))
+	}
+	ui.Emit(ui.EventResult, ui.Fields{"command": command, "tag": tagName, "url": url, "assets": names})
 }
 
 func editRelease(cmd *Command, args *Args) {
@@ -509,7 +943,9 @@ func editRelease(cmd *Command, args *Args) {
 
 	params := map[string]interface{}{}
 	if args.Flag.HasReceived("--commitish") {
-		params["target_commitish"] = args.Flag.Value("--commitish")
+		flagReleaseCommitish := args.Flag.Value("--commitish")
+		utils.Check(verifyCommitish(gh, project, flagReleaseCommitish))
+		params["target_commitish"] = flagReleaseCommitish
 	}
 	if args.Flag.HasReceived("--draft") {
 		params["draft"] = args.Flag.Bool("--draft")
@@ -523,10 +959,17 @@ func editRelease(cmd *Command, args *Args) {
 		Title:    "release",
 	}
 
-	messageBuilder.AddCommentedSection(fmt.Sprintf(`Editing release %s for %s
+	appendBody := args.Flag.Bool("--append-body")
+	if appendBody {
+		messageBuilder.AddCommentedSection(fmt.Sprintf(`Appending to release %s for %s
+
+Write the text to add to the end of the release notes.`, tagName, project))
+	} else {
+		messageBuilder.AddCommentedSection(fmt.Sprintf(`Editing release %s for %s
 
 Write a message for this release. The first block of
 text is the title and the rest is the description.`, tagName, project))
+	}
 
 	flagReleaseMessage := args.Flag.AllValues("--message")
 	if len(flagReleaseMessage) > 0 {
@@ -536,23 +979,43 @@ text is the title and the rest is the description.`, tagName, project))
 		messageBuilder.Message, err = msgFromFile(args.Flag.Value("--file"))
 		utils.Check(err)
 		messageBuilder.Edit = args.Flag.Bool("--edit")
+	} else if appendBody {
+		messageBuilder.Edit = true
 	} else {
 		messageBuilder.Edit = true
 		messageBuilder.Message = fmt.Sprintf("%s\n\n%s", release.Name, release.Body)
 	}
 
-	title, body, err := messageBuilder.Extract()
-	utils.Check(err)
+	if appendBody {
+		text, err := messageBuilder.ExtractText()
+		utils.Check(err)
+		text = strings.TrimSpace(text)
+		if text == "" {
+			messageBuilder.Cleanup()
+			utils.Check(fmt.Errorf("Aborting editing due to empty text to append"))
+		}
+		params["body"] = appendReleaseBody(release.Body, text)
+	} else {
+		title, body, err := messageBuilder.Extract()
+		utils.Check(err)
 
-	if title == "" && len(flagReleaseMessage) == 0 {
-		utils.Check(fmt.Errorf("Aborting editing due to empty release title"))
-	}
+		if title == "" && len(flagReleaseMessage) == 0 {
+			utils.Check(fmt.Errorf("Aborting editing due to empty release title"))
+		}
 
-	if title != "" {
-		params["name"] = title
-	}
-	if body != "" {
-		params["body"] = body
+		messageGiven := len(flagReleaseMessage) > 0 || args.Flag.HasReceived("--file")
+		if messageGiven && body != "" && !release.Draft && !args.Flag.Bool("--force") &&
+			strings.TrimSpace(release.Body) != "" && body != strings.TrimSpace(release.Body) {
+			utils.Check(fmt.Errorf("Aborted: this would replace the notes of published release %s\n"+
+				"(use `--append-body` to add to them, `--force` to replace them, or leave out `-m` and `-F` to edit them in a text editor)", tagName))
+		}
+
+		if title != "" {
+			params["name"] = title
+		}
+		if body != "" {
+			params["body"] = body
+		}
 	}
 
 	if len(params) > 0 {
@@ -567,10 +1030,21 @@ text is the title and the rest is the description.`, tagName, project))
 	}
 
 	flagReleaseAssets := args.Flag.AllValues("--attach")
-	uploadAssets(gh, release, flagReleaseAssets, args)
+	utils.Check(uploadAssets(gh, release, flagReleaseAssets, args))
+	emitReleaseResult("release edit", tagName, release.HtmlUrl, flagReleaseAssets)
 	args.NoForward()
 }
 
+// appendReleaseBody adds text to the end of the notes of a release, below a
+// horizontal rule.
+func appendReleaseBody(body, text string) string {
+	body = strings.TrimSpace(body)
+	if body == "" {
+		return text
+	}
+	return body + "\n\n---\n\n" + text
+}
+
 func deleteRelease(cmd *Command, args *Args) {
 	tagName := ""
 	if args.ParamsSize() > 0 {
@@ -603,32 +1077,172 @@ func deleteRelease(cmd *Command, args *Args) {
 	args.NoForward()
 }
 
-func uploadAssets(gh *github.Client, release *github.Release, assets []string, args *Args) {
+// verifyCommitish checks that ref can be resolved either in the local clone or
+// in the remote repository. The ref is passed to the API unchanged since it
+// accepts branch names as well as commit SHAs.
+func verifyCommitish(gh *github.Client, project *github.Project, ref string) error {
+	if _, err := git.Ref(ref); err == nil {
+		return nil
+	}
+
+	exists, err := gh.CommitExists(project, ref)
+	if err != nil {
+		return err
+	} else if !exists {
+		return fmt.Errorf("Aborted: no commit could be found for `%s' locally or in %s", ref, project)
+	}
+	return nil
+}
+
+// assetUploadWorkers is how many assets are uploaded at the same time unless
+// '--parallel' says otherwise.
+const assetUploadWorkers = 3
+
+// assetUploadAttempts is how many times an asset is tried when uploading
+// several at the same time.
+const assetUploadAttempts = 3
+
+type assetUpload struct {
+	filename string
+	label    string
+	err      error
+}
+
+func uploadAssets(gh *github.Client, release *github.Release, assets []string, args *Args) error {
+	uploads := []*assetUpload{}
 	for _, asset := range assets {
-		var label string
 		parts := strings.SplitN(asset, "#", 2)
-		asset = parts[0]
+		upload := &assetUpload{filename: parts[0]}
 		if len(parts) > 1 {
-			label = parts[1]
+			upload.label = parts[1]
 		}
+		uploads = append(uploads, upload)
+	}
 
-		if args.Noop {
-			if label == "" {
-				ui.Errorf("Would attach release asset `%s'\n", asset)
+	workers := assetUploadWorkers
+	if args.Flag.HasReceived("--parallel") {
+		workers = args.Flag.Int("--parallel")
+	}
+
+	if args.Noop || workers == 1 {
+		for _, upload := range uploads {
+			if args.Noop {
+				if upload.label == "" {
+					ui.Errorf("Would attach release asset `%s'\n", upload.filename)
+				} else {
+					ui.Errorf("Would attach release asset `%s' with label `%s'\n", upload.filename, upload.label)
+				}
 			} else {
-				ui.Errorf("Would attach release asset `%s' with label `%s'\n", asset, label)
+				if err := deleteExistingAsset(gh, release, upload.filename); err != nil {
+					return err
+				}
+				ui.Errorf("Attaching release asset `%s'...\n", upload.filename)
+				if _, err := gh.UploadReleaseAsset(release, upload.filename, upload.label, 1, assetUploadEvents(upload.filename)); err != nil {
+					return err
+				}
 			}
-		} else {
-			for _, existingAsset := range release.Assets {
-				if existingAsset.Name == filepath.Base(asset) {
-					err := gh.DeleteReleaseAsset(&existingAsset)
-					utils.Check(err)
-					break
+		}
+		return nil
+	}
+
+	showProgress := ui.IsTerminal(os.Stderr)
+	queue := make(chan *assetUpload)
+	messages := make(chan string)
+	done := make(chan bool)
+	if workers > len(uploads) {
+		workers = len(uploads)
+	}
+	for i := 0; i < workers; i++ {
+		go func() {
+			for upload := range queue {
+				if upload.err = deleteExistingAsset(gh, release, upload.filename); upload.err != nil {
+					continue
 				}
+				messages <- fmt.Sprintf("Attaching release asset `%s'...", upload.filename)
+				progress := assetUploadEvents(upload.filename)
+				if showProgress {
+					progress = assetUploadProgress(upload.filename, messages)
+				}
+				_, upload.err = gh.UploadReleaseAsset(release, upload.filename, upload.label, assetUploadAttempts, progress)
 			}
-			ui.Errorf("Attaching release asset `%s'...\n", asset)
-			_, err := gh.UploadReleaseAsset(release, asset, label)
-			utils.Check(err)
+			done <- true
+		}()
+	}
+	go func() {
+		for _, upload := range uploads {
+			queue <- upload
+		}
+		close(queue)
+	}()
+	// workers report through messages so that their lines of output don't mix
+	for running := workers; running > 0; {
+		select {
+		case message := <-messages:
+			ui.Errorln(message)
+		case <-done:
+			running--
+		}
+	}
+
+	failures := []string{}
+	for _, upload := range uploads {
+		if upload.err != nil {
+			message := strings.Replace(upload.err.Error(), "\n", "\n    ", -1)
+			failures = append(failures, fmt.Sprintf("  %s: %s", upload.filename, message))
+		}
+	}
+	if len(failures) > 0 {
+		return fmt.Errorf("Error: failed to attach %d of %d release assets:\n%s", len(failures), len(uploads), strings.Join(failures, "\n"))
+	}
+	return nil
+}
+
+// deleteExistingAsset makes room for an asset with the same name as filename.
+func deleteExistingAsset(gh *github.Client, release *github.Release, filename string) error {
+	for _, existingAsset := range release.Assets {
+		if existingAsset.Name == filepath.Base(filename) {
+			return gh.DeleteReleaseAsset(&existingAsset)
+		}
+	}
+	return nil
+}
+
+// assetUploadProgress returns a callback that reports every tenth of an upload
+// that was sent.
+func assetUploadProgress(filename string, messages chan<- string) func(sent, total int64) {
+	emitUpload := assetUploadEvents(filename)
+	if emitUpload == nil {
+		emitUpload = func(sent, total int64) {}
+	}
+	reported := int64(0)
+	return func(sent, total int64) {
+		if total == 0 {
+			return
+		}
+		if percent := sent * 100 / total; percent/10 > reported/10 {
+			reported = percent
+			messages <- fmt.Sprintf("`%s': %d%% uploaded", filename, percent)
+		}
+		emitUpload(sent, total)
+	}
+}
+
+// assetUploadEvents returns a callback that emits every tenth of an upload
+// that was sent for '--porcelain', or nil if events are off.
+func assetUploadEvents(filename string) func(sent, total int64) {
+	if !ui.Porcelain() {
+		return nil
+	}
+	name := filepath.Base(filename)
+	reported := int64(-1)
+	return func(sent, total int64) {
+		tenth := int64(10)
+		if total > 0 {
+			tenth = sent * 10 / total
+		}
+		if tenth > reported {
+			reported = tenth
+			ui.Emit(ui.EventAssetUpload, ui.Fields{"name": name, "done": sent, "total": total})
 		}
 	}
 }
@@ -0,0 +1,143 @@
+package commands
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/github/hub/github"
+	"github.com/github/hub/ui"
+	"github.com/github/hub/utils"
+)
+
+// releaseRef is one side of `release diff`, given as "[OWNER/REPO@]TAG".
+type releaseRef struct {
+	project *github.Project
+	tag     string
+}
+
+func parseReleaseRef(ref string, project *github.Project) (releaseRef, error) {
+	if i := strings.LastIndex(ref, "@"); i > 0 {
+		parts := strings.Split(ref[:i], "/")
+		if len(parts) != 2 || parts[0] == "" || parts[1] == "" || i == len(ref)-1 {
+			return releaseRef{}, fmt.Errorf("invalid release %q; expected \"[OWNER/REPO@]TAG\"", ref)
+		}
+		return releaseRef{github.NewProject(parts[0], parts[1], project.Host), ref[i+1:]}, nil
+	}
+	return releaseRef{project, ref}, nil
+}
+
+func diffRelease(cmd *Command, args *Args) {
+	if args.ParamsSize() != 2 {
+		utils.Check(cmd.UsageError(""))
+	}
+
+	assetsOnly := args.Flag.Bool("--assets-only")
+	bodyOnly := args.Flag.Bool("--body-only")
+	if assetsOnly && bodyOnly {
+		utils.Check(cmd.UsageError("the '--assets-only' and '--body-only' flags are mutually exclusive"))
+	}
+
+	localRepo, err := github.LocalRepo()
+	utils.Check(err)
+
+	project, err := localRepo.MainProject()
+	utils.Check(err)
+
+	from, err := parseReleaseRef(args.GetParam(0), project)
+	if err != nil {
+		utils.Check(cmd.UsageError(err.Error()))
+	}
+	to, err := parseReleaseRef(args.GetParam(1), project)
+	if err != nil {
+		utils.Check(cmd.UsageError(err.Error()))
+	}
+
+	args.NoForward()
+
+	if args.Noop {
+		ui.Printf("Would compare releases `%s' and `%s'\n", args.GetParam(0), args.GetParam(1))
+		return
+	}
+
+	gh := github.NewClient(project.Host)
+	fromRelease, err := gh.FetchRelease(from.project, from.tag)
+	utils.Check(err)
+	toRelease, err := gh.FetchRelease(to.project, to.tag)
+	utils.Check(err)
+
+	fromName, toName := args.GetParam(0), args.GetParam(1)
+	different := false
+
+	if !assetsOnly {
+		bodyDiff := utils.UnifiedDiff(fromName, toName, strings.TrimSpace(fromRelease.Body), strings.TrimSpace(toRelease.Body), 3)
+		if bodyDiff != "" {
+			different = true
+			ui.Printf("## Body\n\n%s\n", bodyDiff)
+		}
+	}
+
+	if !bodyOnly {
+		assetsDiff := releaseAssetsDiff(fromRelease.Assets, toRelease.Assets)
+		if assetsDiff != "" {
+			different = true
+			ui.Printf("## Assets\n\n%s\n", assetsDiff)
+		}
+	}
+
+	// commits are only compared within a single repository, and they don't
+	// count as a difference since two tags rarely point to the same commit
+	if !assetsOnly && !bodyOnly && from.project.SameAs(to.project) {
+		comparison, err := gh.FetchComparison(from.project, from.tag, to.tag)
+		utils.Check(err)
+		ui.Printf("## Commits\n\n%s...%s: %s ahead, %s behind\n%s\n",
+			from.tag, to.tag,
+			pluralize(comparison.AheadBy, "commit"),
+			pluralize(comparison.BehindBy, "commit"),
+			from.project.WebURL("", "", fmt.Sprintf("compare/%s...%s", from.tag, to.tag)))
+	}
+
+	if different {
+		os.Exit(utils.ExitError)
+	}
+}
+
+// releaseAssetsDiff lists the assets that were added or removed between two
+// releases, and the ones whose size changed, matching assets by name.
+func releaseAssetsDiff(from, to []github.ReleaseAsset) string {
+	fromSizes := map[string]int64{}
+	for _, asset := range from {
+		fromSizes[asset.Name] = asset.Size
+	}
+	toSizes := map[string]int64{}
+	for _, asset := range to {
+		toSizes[asset.Name] = asset.Size
+	}
+
+	names := []string{}
+	for name := range fromSizes {
+		names = append(names, name)
+	}
+	for name := range toSizes {
+		if _, found := fromSizes[name]; !found {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	out := ""
+	for _, name := range names {
+		fromSize, inFrom := fromSizes[name]
+		toSize, inTo := toSizes[name]
+		switch {
+		case !inFrom:
+			out += fmt.Sprintf("+ %s (%d bytes)\n", name, toSize)
+		case !inTo:
+			out += fmt.Sprintf("- %s (%d bytes)\n", name, fromSize)
+		case fromSize != toSize:
+			out += fmt.Sprintf("~ %s (%d -> %d bytes, %+d)\n", name, fromSize, toSize, toSize-fromSize)
+		}
+	}
+	return out
+}
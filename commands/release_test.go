@@ -0,0 +1,36 @@
+package commands
+
+import (
+	"testing"
+
+	"github.com/bmizerany/assert"
+	"github.com/github/hub/github"
+)
+
+func TestMatchingReleaseAssets(t *testing.T) {
+	assets := []github.ReleaseAsset{
+		{Name: "hub-linux-amd64.tgz"},
+		{Name: "hub-darwin-amd64.tgz"},
+		{Name: "hub-windows-amd64.zip"},
+		{Name: "checksums.txt"},
+	}
+
+	matched, err := matchingReleaseAssets(assets, nil)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, assets, matched)
+
+	matched, err = matchingReleaseAssets(assets, []string{"*-linux-*", "*.txt"})
+	assert.Equal(t, nil, err)
+	assert.Equal(t, []github.ReleaseAsset{assets[0], assets[3]}, matched)
+
+	_, err = matchingReleaseAssets(assets, []string{"*.tgz", "*.deb"})
+	assert.Equal(t, "Error: no assets match `*.deb'", err.Error())
+
+	_, err = matchingReleaseAssets(assets, []string{"[a-"})
+	assert.Equal(t, "Error: invalid pattern `[a-'", err.Error())
+}
+
+func TestAppendReleaseBody(t *testing.T) {
+	assert.Equal(t, "Fixes a crash.\n\n---\n\nErrata: requires Go 1.11.", appendReleaseBody("Fixes a crash.\n", "Errata: requires Go 1.11."))
+	assert.Equal(t, "Errata: requires Go 1.11.", appendReleaseBody("  \n", "Errata: requires Go 1.11."))
+}
@@ -0,0 +1,211 @@
+package commands
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/github/hub/github"
+	"github.com/github/hub/ui"
+	"github.com/github/hub/utils"
+)
+
+var (
+	cmdRepo = &Command{
+		Run: printHelp,
+		Usage: `
+repo transfer [--team-id <ID>] <NEW-OWNER>
+repo archive [-y]
+repo unarchive [-y]
+`,
+		Long: `Manage the GitHub repository of the current project.
+
+## Commands:
+
+	* _transfer_:
+		Request a transfer of the repository to <NEW-OWNER>, a user or an
+		organization. Transfers to a user need to be accepted by that user before
+		they take effect. Once the transfer is complete, the old repository URL
+		will redirect to the new location.
+
+		After a successful transfer, hub offers to update the git remote pointing
+		to the repository so that it uses the new owner.
+
+	* _archive_:
+		Mark the repository as archived, making it read-only.
+
+	* _unarchive_:
+		Make an archived repository writable again.
+
+## Options:
+
+	--team-id <ID>
+		The ID of a team in the new organization to give access to the
+		repository. Multiple team IDs may be given as a comma-separated list or by
+		repeating this option.
+
+	-y, --yes
+		Skip the confirmation prompt, which otherwise asks to type the name of the
+		repository.
+
+## Examples:
+		$ hub repo transfer my-org
+		[ transfer of the current repository to "my-org" requested ]
+
+		$ hub repo archive
+		Type the name of the repository to confirm archiving 'mislav/dotfiles': dotfiles
+
+## See also:
+
+hub-delete(1), hub(1)
+`,
+	}
+
+	cmdTransferRepo = &Command{
+		Key: "transfer",
+		Run: transferRepo,
+		KnownFlags: `
+		--team-id ID
+`,
+	}
+
+	cmdArchiveRepo = &Command{
+		Key: "archive",
+		Run: archiveRepo,
+		KnownFlags: `
+		-y, --yes
+`,
+	}
+
+	cmdUnarchiveRepo = &Command{
+		Key: "unarchive",
+		Run: unarchiveRepo,
+		KnownFlags: `
+		-y, --yes
+`,
+	}
+)
+
+func init() {
+	cmdRepo.Use(cmdTransferRepo)
+	cmdRepo.Use(cmdArchiveRepo)
+	cmdRepo.Use(cmdUnarchiveRepo)
+	CmdRunner.Use(cmdRepo)
+}
+
+func transferRepo(cmd *Command, args *Args) {
+	if args.ParamsSize() != 1 {
+		utils.Check(cmd.UsageError(""))
+	}
+	newOwner := args.GetParam(0)
+
+	localRepo, err := github.LocalRepo()
+	utils.Check(err)
+
+	project, err := localRepo.MainProject()
+	utils.Check(err)
+
+	teamIDs := []int{}
+	for _, value := range args.Flag.AllValues("--team-id") {
+		for _, id := range commaSeparated([]string{value}) {
+			teamID, err := strconv.Atoi(id)
+			if err != nil {
+				utils.Check(fmt.Errorf("invalid team ID: %s", id))
+			}
+			teamIDs = append(teamIDs, teamID)
+		}
+	}
+
+	args.NoForward()
+	newProject := github.NewProject(newOwner, project.Name, project.Host)
+
+	if args.Noop {
+		ui.Printf("Would request transfer of repository '%s' to '%s'\n", project, newOwner)
+	} else {
+		gh := github.NewClient(project.Host)
+		repo, err := gh.TransferRepository(project, newOwner, teamIDs)
+		utils.Check(err)
+
+		if repo.Owner != nil && strings.EqualFold(repo.Owner.Login, newOwner) {
+			ui.Printf("Transferred repository '%s' to '%s'.\n", project, newOwner)
+		} else {
+			ui.Printf("Transfer of repository '%s' to '%s' is pending.\n", project, newOwner)
+		}
+		ui.Printf("Once the transfer is complete, %s will redirect to %s\n",
+			project.WebURL("", "", ""), newProject.WebURL("", "", ""))
+	}
+
+	remote, err := localRepo.RemoteForProject(project)
+	if err != nil {
+		return
+	}
+
+	newURL := newProject.GitURL("", "", remote.URL.Scheme == "ssh")
+	if args.Noop {
+		ui.Printf("Would update remote '%s' to point to '%s'\n", remote.Name, newURL)
+	} else if confirm(fmt.Sprintf("Update remote '%s' to point to '%s' (y/N)? ", remote.Name, newProject)) {
+		args.Before("git", "remote", "set-url", remote.Name, newURL)
+	}
+}
+
+func archiveRepo(cmd *Command, args *Args) {
+	setRepoArchived(cmd, args, true)
+}
+
+func unarchiveRepo(cmd *Command, args *Args) {
+	setRepoArchived(cmd, args, false)
+}
+
+func setRepoArchived(cmd *Command, args *Args, archived bool) {
+	if !args.IsParamsEmpty() {
+		utils.Check(cmd.UsageError(""))
+	}
+
+	localRepo, err := github.LocalRepo()
+	utils.Check(err)
+
+	project, err := localRepo.MainProject()
+	utils.Check(err)
+
+	action := "archive"
+	if !archived {
+		action = "unarchive"
+	}
+
+	if !args.Flag.Bool("--yes") {
+		ui.Printf("Type the name of the repository to confirm %sing '%s': ", strings.TrimSuffix(action, "e"), project)
+		answer := ""
+		scanner := bufio.NewScanner(os.Stdin)
+		if scanner.Scan() {
+			answer = strings.TrimSpace(scanner.Text())
+		}
+		utils.Check(scanner.Err())
+		if answer != project.Name && answer != project.String() {
+			utils.Check(fmt.Errorf("Please type '%s' for confirmation.", project.Name))
+		}
+	}
+
+	args.NoForward()
+	if args.Noop {
+		ui.Printf("Would %s repository '%s'.\n", action, project)
+		return
+	}
+
+	gh := github.NewClient(project.Host)
+	_, err = gh.SetRepositoryArchived(project, archived)
+	utils.Check(err)
+
+	ui.Printf("%sd repository '%s'.\n", strings.Title(action), project)
+}
+
+func confirm(prompt string) bool {
+	ui.Printf("%s", prompt)
+	answer := ""
+	scanner := bufio.NewScanner(os.Stdin)
+	if scanner.Scan() {
+		answer = strings.TrimSpace(scanner.Text())
+	}
+	return strings.EqualFold(answer, "y") || strings.EqualFold(answer, "yes")
+}
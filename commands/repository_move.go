@@ -0,0 +1,74 @@
+package commands
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+
+	"github.com/github/hub/git"
+	"github.com/github/hub/github"
+	"github.com/github/hub/ui"
+)
+
+// reportMovedRepositories tells about repositories that API requests were
+// redirected away from, and offers to update the git remotes that still point
+// to their old location. Remotes are updated without asking with the global
+// '--fix-remote' flag; otherwise the offer is only made on a terminal.
+func reportMovedRepositories(args *Args) {
+	moves := github.MovedRepositories()
+	if len(moves) == 0 {
+		return
+	}
+
+	localRepo, err := github.LocalRepo()
+	if err != nil {
+		localRepo = nil
+	}
+	interactive := ui.IsTerminal(os.Stdin) && ui.IsTerminal(os.Stdout)
+
+	for _, move := range moves {
+		ui.Errorf("%s was moved to %s\n", move.From, move.To)
+		if localRepo == nil {
+			continue
+		}
+		remote, err := localRepo.RemoteForProject(move.From)
+		if err != nil {
+			continue
+		}
+
+		oldURL, _ := git.Config(fmt.Sprintf("remote.%s.url", remote.Name))
+		newURL := movedRemoteURL(oldURL, move)
+		if newURL == "" {
+			newURL = move.To.GitURL("", "", remote.URL.Scheme == "ssh")
+		}
+		if args.FixRemote || (interactive && confirm(fmt.Sprintf("Update remote '%s' to point to '%s' (y/N)? ", remote.Name, move.To))) {
+			if args.Noop {
+				ui.Printf("Would update remote '%s' to point to '%s'\n", remote.Name, newURL)
+				continue
+			}
+			if err := git.Spawn("remote", "set-url", remote.Name, newURL); err != nil {
+				ui.Errorf("Error updating remote '%s': %s\n", remote.Name, err)
+			}
+			if pushURL, err := git.Config(fmt.Sprintf("remote.%s.pushurl", remote.Name)); err == nil {
+				if newPushURL := movedRemoteURL(pushURL, move); newPushURL != "" {
+					if err := git.Spawn("remote", "set-url", "--push", remote.Name, newPushURL); err != nil {
+						ui.Errorf("Error updating remote '%s': %s\n", remote.Name, err)
+					}
+				}
+			}
+		} else if !interactive {
+			ui.Errorf("To update remote '%s', run `git remote set-url %s %s` or use `hub --fix-remote`\n", remote.Name, remote.Name, newURL)
+		}
+	}
+}
+
+// movedRemoteURL returns remoteURL with the repository path of move.From
+// replaced by that of move.To, keeping the protocol of the URL. It is blank if
+// remoteURL doesn't end with the old repository path.
+func movedRemoteURL(remoteURL string, move github.RepositoryMove) string {
+	pathRe := regexp.MustCompile(fmt.Sprintf(`(?i)([:/])%s/%s(\.git)?(/?)$`, regexp.QuoteMeta(move.From.Owner), regexp.QuoteMeta(move.From.Name)))
+	if !pathRe.MatchString(remoteURL) {
+		return ""
+	}
+	return pathRe.ReplaceAllString(remoteURL, fmt.Sprintf("${1}%s/%s${2}${3}", move.To.Owner, move.To.Name))
+}
@@ -0,0 +1,21 @@
+package commands
+
+import (
+	"testing"
+
+	"github.com/bmizerany/assert"
+	"github.com/github/hub/github"
+)
+
+func TestMovedRemoteURL(t *testing.T) {
+	move := github.RepositoryMove{
+		From: &github.Project{Owner: "mislav", Name: "dotfiles", Host: "github.com"},
+		To:   &github.Project{Owner: "acme", Name: "settings", Host: "github.com"},
+	}
+
+	assert.Equal(t, "git@github.com:acme/settings.git", movedRemoteURL("git@github.com:mislav/dotfiles.git", move))
+	assert.Equal(t, "https://github.com/acme/settings", movedRemoteURL("https://github.com/mislav/dotfiles", move))
+	assert.Equal(t, "ssh://git@github.com/acme/settings.git/", movedRemoteURL("ssh://git@github.com/Mislav/Dotfiles.git/", move))
+	assert.Equal(t, "", movedRemoteURL("https://github.com/mislav/dotfiles-old.git", move))
+	assert.Equal(t, "", movedRemoteURL("no_push", move))
+}
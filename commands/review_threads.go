@@ -0,0 +1,76 @@
+package commands
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+
+	"github.com/github/hub/github"
+)
+
+func countUnresolved(threads []github.ReviewThread) int {
+	count := 0
+	for _, thread := range threads {
+		if !thread.IsResolved {
+			count++
+		}
+	}
+	return count
+}
+
+// formatReviewThreads renders review threads for 'pr show --threads'. The
+// first comment of a thread is shown under the "<PATH>:<LINE>" header of the
+// thread, and the replies to it are indented.
+func formatReviewThreads(threads []github.ReviewThread, unresolvedOnly, colorize bool) string {
+	out := &bytes.Buffer{}
+	out.WriteString("\n## Review threads:\n")
+
+	resolvedTag := "[resolved]"
+	if colorize {
+		resolvedTag = "\033[2m[resolved]\033[0m"
+	}
+
+	for _, thread := range threads {
+		if thread.IsResolved && unresolvedOnly {
+			continue
+		}
+		header := thread.Path
+		if thread.Line > 0 {
+			header = fmt.Sprintf("%s:%d", thread.Path, thread.Line)
+		}
+		if thread.IsResolved {
+			header += " " + resolvedTag
+		}
+		fmt.Fprintf(out, "\n### %s\n", header)
+
+		for i, comment := range thread.Comments {
+			indent := ""
+			if i > 0 {
+				indent = "    "
+			}
+			author := comment.Author
+			if author == "" {
+				author = "ghost"
+			}
+			text := fmt.Sprintf("@%s on %s:\n%s", author, comment.CreatedAt.String(), collapseImages(comment.Body))
+			fmt.Fprintf(out, "\n%s\n", indentLines(text, indent))
+		}
+	}
+
+	noun := "threads"
+	if len(threads) == 1 {
+		noun = "thread"
+	}
+	fmt.Fprintf(out, "\n%d unresolved of %d %s\n", countUnresolved(threads), len(threads), noun)
+	return out.String()
+}
+
+func indentLines(text, indent string) string {
+	lines := strings.Split(strings.TrimRight(text, "\n"), "\n")
+	for i, line := range lines {
+		if line != "" {
+			lines[i] = indent + line
+		}
+	}
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,67 @@
+package commands
+
+import (
+	"testing"
+	"time"
+
+	"github.com/bmizerany/assert"
+	"github.com/github/hub/github"
+)
+
+func TestFormatReviewThreads(t *testing.T) {
+	createdAt := time.Date(2024, 3, 1, 10, 0, 0, 0, time.UTC)
+	threads := []github.ReviewThread{
+		{
+			Path: "main.go",
+			Line: 12,
+			Comments: []github.ReviewThreadComment{
+				{Author: "mislav", Body: "Should this be a constant?\n", CreatedAt: createdAt},
+				{Author: "hubot", Body: "Good idea.\n\nDone.", CreatedAt: createdAt},
+			},
+		},
+		{
+			Path:       "README.md",
+			IsResolved: true,
+			Comments: []github.ReviewThreadComment{
+				{Body: "Typo", CreatedAt: createdAt},
+			},
+		},
+	}
+
+	assert.Equal(t, `
+## Review threads:
+
+### main.go:12
+
+@mislav on 2024-03-01 10:00:00 +0000 UTC:
+Should this be a constant?
+
+    @hubot on 2024-03-01 10:00:00 +0000 UTC:
+    Good idea.
+
+    Done.
+
+### README.md [resolved]
+
+@ghost on 2024-03-01 10:00:00 +0000 UTC:
+Typo
+
+1 unresolved of 2 threads
+`, formatReviewThreads(threads, false, false))
+
+	assert.Equal(t, `
+## Review threads:
+
+### main.go:12
+
+@mislav on 2024-03-01 10:00:00 +0000 UTC:
+Should this be a constant?
+
+    @hubot on 2024-03-01 10:00:00 +0000 UTC:
+    Good idea.
+
+    Done.
+
+1 unresolved of 2 threads
+`, formatReviewThreads(threads, true, false))
+}
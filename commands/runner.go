@@ -2,10 +2,12 @@ package commands
 
 import (
 	"fmt"
+	"os"
 	"strings"
 
 	"github.com/github/hub/cmd"
 	"github.com/github/hub/git"
+	"github.com/github/hub/github"
 	"github.com/github/hub/ui"
 	"github.com/kballard/go-shellquote"
 )
@@ -51,13 +53,27 @@ func (r *Runner) Execute(cliArgs []string) error {
 	}
 
 	git.GlobalFlags = args.GlobalFlags // preserve git global flags
+	github.SelectedIdentity = args.Identity
+	github.DisableRetries = args.Noop
+	if args.Porcelain {
+		ui.EnablePorcelain()
+	}
 	if !isBuiltInHubCommand(cmdName) {
 		expandAlias(args)
+		if args.Command == cmdName {
+			if plugin := findPlugin(cmdName); plugin != "" {
+				if !git.IsBuiltInGitCommand(cmdName) {
+					return runPlugin(plugin, args)
+				}
+				warnShadowedPlugin(plugin, cmdName)
+			}
+		}
 		cmdName = args.Command
 	}
 
 	cmd := r.Lookup(cmdName)
 	if cmd != nil && cmd.Runnable() {
+		warnShadowedPlugin(findPlugin(cmdName), cmdName)
 		err := callRunnableCommand(cmd, args)
 		if err == nil && forceFail {
 			err = fmt.Errorf("")
@@ -79,6 +95,7 @@ func callRunnableCommand(cmd *Command, args *Args) error {
 	if err != nil {
 		return err
 	}
+	reportMovedRepositories(args)
 
 	cmds := args.Commands()
 	if args.Noop {
@@ -105,6 +122,12 @@ func printCommands(cmds []*cmd.Cmd) {
 func executeCommands(cmds []*cmd.Cmd, execFinal bool) error {
 	for i, c := range cmds {
 		var err error
+		// the output of git is for humans, while standard output is for events,
+		// which rules out replacing hub with git
+		if ui.Porcelain() {
+			c.Stdout = os.Stderr
+			execFinal = false
+		}
 		// Run with `Exec` for the last command in chain
 		if execFinal && i == len(cmds)-1 {
 			err = c.Run()
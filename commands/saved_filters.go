@@ -0,0 +1,150 @@
+package commands
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/github/hub/git"
+	"github.com/github/hub/ui"
+	"github.com/github/hub/utils"
+	"github.com/kballard/go-shellquote"
+)
+
+// savedFilterFlags are the flags of `issue` and `pr list` that '--save-filter'
+// remembers. Options that control how the listing is run rather than what it
+// shows, such as '--limit' and '--watch', are left out.
+var savedFilterFlags = []string{
+	"--state",
+	"--head",
+	"--base",
+	"--assignee",
+	"--creator",
+	"--mentioned",
+	"--milestone",
+	"--labels",
+	"--exclude-author",
+	"--exclude-assignee",
+	"--since",
+	"--search",
+	"--include-pulls",
+	"--ready-to-merge",
+	"--sort",
+	"--sort-ascending",
+	"--direction",
+	"--format",
+}
+
+// savedFilterConfigPrefix is the global git config section that saved filters
+// are stored in, as "hub.filter.<NAME>".
+const savedFilterConfigPrefix = "hub.filter."
+
+var savedFilterNameRe = regexp.MustCompile(`^[a-zA-Z][a-zA-Z0-9-]*$`)
+
+// savedFilterKey is the git config variable for the filter called name. git
+// compares variable names without regard to case.
+func savedFilterKey(name string) (string, error) {
+	if !savedFilterNameRe.MatchString(name) {
+		return "", fmt.Errorf("invalid filter name '%s': use letters, digits, and dashes, starting with a letter", name)
+	}
+	return savedFilterConfigPrefix + strings.ToLower(name), nil
+}
+
+// handleSavedFilters performs '--list-filters' and '--delete-filter', which
+// replace listing, and reports whether it did. Otherwise, it applies the filter
+// named by '--filter' to args and then saves the result as '--save-filter'.
+// listing names the command for notes about saved flags that don't apply to it.
+func handleSavedFilters(cmd *Command, args *Args, listing string) bool {
+	if args.Flag.Bool("--list-filters") {
+		args.NoForward()
+		entries, err := git.GlobalConfigEntries(`^` + regexp.QuoteMeta(savedFilterConfigPrefix))
+		utils.Check(err)
+		for _, entry := range entries {
+			ui.Printf("%s\t%s\n", strings.TrimPrefix(entry.Name, savedFilterConfigPrefix), entry.Value)
+		}
+		return true
+	}
+
+	if args.Flag.HasReceived("--delete-filter") {
+		name := args.Flag.Value("--delete-filter")
+		key, err := savedFilterKey(name)
+		utils.Check(err)
+		if _, err := git.GlobalConfig(key); err != nil {
+			utils.Check(fmt.Errorf("Error: no saved filter named '%s'", name))
+		}
+		utils.Check(git.UnsetGlobalConfig(key))
+		ui.Errorf("Deleted filter '%s'\n", name)
+		args.NoForward()
+		return true
+	}
+
+	if args.Flag.HasReceived("--filter") {
+		name := args.Flag.Value("--filter")
+		key, err := savedFilterKey(name)
+		utils.Check(err)
+		value, err := git.GlobalConfig(key)
+		if err != nil {
+			utils.Check(fmt.Errorf("Error: no saved filter named '%s'", name))
+		}
+		flags, err := shellquote.Split(value)
+		utils.Check(err)
+		for _, ignored := range applySavedFilter(args.Flag, flags) {
+			ui.Errorf("Note: ignoring '%s' of filter '%s', which doesn't apply to `hub %s`\n", ignored, name, listing)
+		}
+		utils.Check(cmd.validateFlags(args))
+	}
+
+	if args.Flag.HasReceived("--save-filter") {
+		name := args.Flag.Value("--save-filter")
+		key, err := savedFilterKey(name)
+		utils.Check(err)
+		flags := savedFilterArgs(args.Flag)
+		if len(flags) == 0 {
+			utils.Check(fmt.Errorf("Error: no filtering options to save as '%s'", name))
+		}
+		utils.Check(git.SetGlobalConfig(key, shellquote.Join(flags...)))
+		ui.Errorf("Saved filter '%s'\n", name)
+	}
+
+	return false
+}
+
+// savedFilterArgs lists the values of the savedFilterFlags that p received as
+// "--FLAG=VALUE", or as "--FLAG" for flags that don't take a value.
+func savedFilterArgs(p *utils.ArgsParser) []string {
+	flags := []string{}
+	for _, name := range savedFilterFlags {
+		for _, value := range p.AllValues(name) {
+			if value == "" {
+				flags = append(flags, name)
+			} else {
+				flags = append(flags, name+"="+value)
+			}
+		}
+	}
+	return flags
+}
+
+// applySavedFilter adds the flags of a saved filter to p, except for flags that
+// p received from the command line, which override the saved values. It
+// returns the flags that p doesn't know about.
+func applySavedFilter(p *utils.ArgsParser, flags []string) (ignored []string) {
+	given := map[string]bool{}
+	for _, name := range savedFilterFlags {
+		given[name] = p.HasReceived(name)
+	}
+
+	for _, flag := range flags {
+		parts := strings.SplitN(flag, "=", 2)
+		if !p.HasFlag(parts[0]) {
+			ignored = append(ignored, flag)
+		} else if !given[parts[0]] {
+			value := ""
+			if len(parts) > 1 {
+				value = parts[1]
+			}
+			p.AddValue(parts[0], value)
+		}
+	}
+	return
+}
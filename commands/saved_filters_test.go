@@ -0,0 +1,48 @@
+package commands
+
+import (
+	"testing"
+
+	"github.com/bmizerany/assert"
+	"github.com/github/hub/utils"
+	"github.com/kballard/go-shellquote"
+)
+
+func TestSavedFilter_roundTrip(t *testing.T) {
+	saving := utils.NewArgsParserWithUsage(cmdIssue.KnownFlags)
+	_, err := saving.Parse([]string{"-s", "closed", "-l", "bug,!wontfix", "--labels", "ui", "-o", "updated", "-^", "-f", "%i %t%n", "-L", "5"})
+	assert.Equal(t, nil, err)
+	saved := shellquote.Join(savedFilterArgs(saving)...)
+	assert.Equal(t, `--state=closed --labels=bug,\!wontfix --labels=ui --sort=updated --sort-ascending '--format=%i %t%n'`, saved)
+
+	flags, err := shellquote.Split(saved)
+	assert.Equal(t, nil, err)
+	listing := utils.NewArgsParserWithUsage(cmdIssue.KnownFlags)
+	listing.Parse([]string{})
+	assert.Equal(t, []string(nil), applySavedFilter(listing, flags))
+	assert.Equal(t, "closed", listing.Value("--state"))
+	assert.Equal(t, []string{"bug,!wontfix", "ui"}, listing.AllValues("--labels"))
+	assert.Equal(t, "updated", listing.Value("--sort"))
+	assert.Equal(t, true, listing.Bool("--sort-ascending"))
+	assert.Equal(t, "%i %t%n", listing.Value("--format"))
+	assert.Equal(t, false, listing.HasReceived("--limit"))
+}
+
+func TestApplySavedFilter_overrides(t *testing.T) {
+	p := utils.NewArgsParserWithUsage(cmdPr.Long)
+	p.Parse([]string{"-s", "merged", "-l", "docs"})
+	ignored := applySavedFilter(p, []string{"--state=open", "--labels=bug", "--labels=ui", "--assignee=mislav", "--base=main"})
+	assert.Equal(t, []string{"--assignee=mislav"}, ignored)
+	assert.Equal(t, []string{"merged"}, p.AllValues("--state"))
+	assert.Equal(t, []string{"docs"}, p.AllValues("--labels"))
+	assert.Equal(t, "main", p.Value("--base"))
+}
+
+func TestSavedFilterKey(t *testing.T) {
+	key, err := savedFilterKey("My-Bugs2")
+	assert.Equal(t, nil, err)
+	assert.Equal(t, "hub.filter.my-bugs2", key)
+
+	_, err = savedFilterKey("my.bugs")
+	assert.Equal(t, "invalid filter name 'my.bugs': use letters, digits, and dashes, starting with a letter", err.Error())
+}
@@ -0,0 +1,226 @@
+package commands
+
+import (
+	"io/ioutil"
+	"os"
+	"strings"
+
+	"github.com/github/hub/github"
+	"github.com/github/hub/ui"
+	"github.com/github/hub/utils"
+)
+
+var (
+	cmdSecret = &Command{
+		Run: secret,
+		Usage: `
+secret set [--env <ENV>|--org <ORG> [--visibility <VISIBILITY>]] [-b <VALUE>] <NAME>
+secret list [--env <ENV>|--org <ORG>]
+secret remove [--env <ENV>|--org <ORG>] <NAME>
+`,
+		Long: `Manage GitHub Actions secrets of a repository, environment, or organization.
+
+## Commands:
+
+	* _set_:
+		Create or update the secret <NAME>. Unless a value is given with '--body',
+		it is read from standard input; when that is a terminal, the value is
+		prompted for without being echoed. A single trailing newline is removed
+		from values read from standard input.
+
+		The value is encrypted with the public key of the repository, environment,
+		or organization before being sent to GitHub.
+
+	* _list_:
+		List the names of secrets and when they were last updated. Values of
+		secrets can never be retrieved.
+
+	* _remove_:
+		Delete the secret <NAME>.
+
+## Options:
+
+	-b, --body <VALUE>
+		Use <VALUE> as the value of the secret.
+
+	--env <ENV>
+		Manage secrets of the deployment environment <ENV> of the current
+		repository.
+
+	--org <ORG>
+		Manage secrets of the organization <ORG> instead of the current repository.
+
+	--visibility <VISIBILITY>
+		Which repositories of the organization can use the secret: "private"
+		(default) or "all".
+
+	<NAME>
+		The name of the secret.
+
+## Examples:
+		$ hub secret set -b "$DEPLOY_TOKEN" DEPLOY_TOKEN
+
+		$ hub secret set --env production SSH_KEY < ~/.ssh/deploy_key
+
+		$ hub secret list --org acme
+
+## See also:
+
+hub-variable(1), hub(1)
+`,
+	}
+
+	cmdSetSecret = &Command{
+		Key: "set",
+		Run: setSecret,
+		KnownFlags: `
+		-b, --body VALUE
+		--env ENV
+		--org ORG
+		--visibility VIS
+`,
+	}
+
+	cmdListSecrets = &Command{
+		Key: "list",
+		Run: listSecrets,
+		KnownFlags: `
+		--env ENV
+		--org ORG
+`,
+	}
+
+	cmdRemoveSecret = &Command{
+		Key: "remove",
+		Run: removeSecret,
+		KnownFlags: `
+		--env ENV
+		--org ORG
+`,
+	}
+)
+
+func init() {
+	cmdSecret.Use(cmdSetSecret)
+	cmdSecret.Use(cmdListSecrets)
+	cmdSecret.Use(cmdRemoveSecret)
+	CmdRunner.Use(cmdSecret)
+}
+
+func secret(cmd *Command, args *Args) {
+	utils.Check(cmd.UsageError(""))
+}
+
+// actionsScope determines whose Actions secrets or variables a command
+// operates on from its '--env' and '--org' flags.
+func actionsScope(cmd *Command, args *Args) (scope *github.ActionsScope, host string) {
+	env := args.Flag.Value("--env")
+	org := args.Flag.Value("--org")
+	if env != "" && org != "" {
+		utils.Check(cmd.UsageError("the '--env' and '--org' options are mutually exclusive"))
+	}
+
+	if org != "" {
+		return &github.ActionsScope{Org: org}, authHost()
+	}
+
+	localRepo, err := github.LocalRepo()
+	utils.Check(err)
+
+	project, err := localRepo.MainProject()
+	utils.Check(err)
+
+	return &github.ActionsScope{Project: project, Environment: env}, project.Host
+}
+
+func setSecret(cmd *Command, args *Args) {
+	if args.ParamsSize() != 1 {
+		utils.Check(cmd.UsageError(""))
+	}
+	name := args.GetParam(0)
+
+	visibility := args.Flag.Value("--visibility")
+	if visibility != "" && !args.Flag.HasReceived("--org") {
+		utils.Check(cmd.UsageError("the '--visibility' option requires '--org'"))
+	}
+
+	scope, host := actionsScope(cmd, args)
+
+	var value string
+	if args.Flag.HasReceived("--body") {
+		value = args.Flag.Value("--body")
+	} else if ui.IsTerminal(os.Stdin) {
+		ui.Errorf("Value for %s: ", name)
+		input, err := github.ReadHiddenInput()
+		utils.Check(err)
+		value = input
+	} else {
+		input, err := ioutil.ReadAll(os.Stdin)
+		utils.Check(err)
+		value = strings.TrimSuffix(strings.TrimSuffix(string(input), "\n"), "\r")
+	}
+
+	args.NoForward()
+	if args.Noop {
+		ui.Printf("Would set secret %s in %s\n", name, scope)
+		return
+	}
+
+	gh := github.NewClient(host)
+	key, err := gh.FetchSecretPublicKey(scope)
+	utils.Check(err)
+
+	encrypted, err := github.EncryptSecret(key, []byte(value))
+	utils.Check(err)
+
+	err = gh.SetSecret(scope, name, encrypted, key.KeyId, visibility)
+	utils.Check(err)
+}
+
+func listSecrets(cmd *Command, args *Args) {
+	if !args.IsParamsEmpty() {
+		utils.Check(cmd.UsageError(""))
+	}
+
+	scope, host := actionsScope(cmd, args)
+
+	args.NoForward()
+	if args.Noop {
+		ui.Printf("Would request the list of secrets in %s\n", scope)
+		return
+	}
+
+	gh := github.NewClient(host)
+	secrets, err := gh.FetchSecrets(scope)
+	utils.Check(err)
+
+	nameWidth := 0
+	for _, secret := range secrets {
+		if len(secret.Name) > nameWidth {
+			nameWidth = len(secret.Name)
+		}
+	}
+
+	for _, secret := range secrets {
+		ui.Printf("%-*s  Updated %s\n", nameWidth, secret.Name, secret.UpdatedAt.Format("2006-01-02"))
+	}
+}
+
+func removeSecret(cmd *Command, args *Args) {
+	if args.ParamsSize() != 1 {
+		utils.Check(cmd.UsageError(""))
+	}
+	name := args.GetParam(0)
+
+	scope, host := actionsScope(cmd, args)
+
+	args.NoForward()
+	if args.Noop {
+		ui.Printf("Would remove secret %s from %s\n", name, scope)
+		return
+	}
+
+	gh := github.NewClient(host)
+	err := gh.DeleteSecret(scope, name)
+	utils.Check(err)
+}
@@ -0,0 +1,263 @@
+package commands
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/github/hub/github"
+	"github.com/github/hub/ui"
+	"github.com/github/hub/utils"
+)
+
+var (
+	cmdSecurity = &Command{
+		Run: security,
+		Usage: `
+security alerts [--state <STATE>] [--severity <SEVERITIES>] [--fail-on <SEVERITY>] [<OWNER>/<REPO>]
+security advisories [--state <STATE>] [<OWNER>/<REPO>]
+`,
+		Long: `Inspect the security of a GitHub repository.
+
+## Commands:
+
+	* _alerts_:
+		List the Dependabot alerts of the repository, one per line: the alert
+		number, its severity, the affected package, the vulnerable version range,
+		the manifest that the dependency was found in, and the CVE identifier of
+		the vulnerability, or its GHSA identifier if it has no CVE.
+
+		Reading Dependabot alerts requires an access token with the
+		"security_events" or "repo" scope, or "public_repo" for a public
+		repository.
+
+	* _advisories_:
+		List the security advisories that the maintainers of the repository
+		published: the GHSA identifier, the CVE identifier, the severity, the date
+		the advisory was published, and its summary.
+
+Without <OWNER>/<REPO>, the repository of the current project is inspected.
+
+## Options:
+
+	--state <STATE>
+		For _alerts_, only list alerts in <STATE>: "open" (default), "fixed",
+		"dismissed", "auto_dismissed", or "all".
+
+		For _advisories_, only list advisories in <STATE>: "published" (default),
+		"draft", "triage", "closed", or "all". Advisories that aren't published
+		are only visible to maintainers of the repository.
+
+	--severity <SEVERITIES>
+		Only list alerts of the given comma-separated severities: "low",
+		"medium", "high", or "critical".
+
+	--fail-on <SEVERITY>
+		Exit with status 1 if any of the listed alerts is of <SEVERITY> or higher.
+
+## Examples:
+		$ hub security alerts --severity high,critical
+		#12  critical  npm/minimist  < 1.2.6    package-lock.json  CVE-2021-44906
+		#9   high      npm/lodash    < 4.17.19  package-lock.json  CVE-2020-8203
+
+		$ hub security alerts --fail-on high >/dev/null || echo "vulnerable"
+
+## See also:
+
+hub(1)
+`,
+	}
+
+	cmdSecurityAlerts = &Command{
+		Key: "alerts",
+		Run: listSecurityAlerts,
+		KnownFlags: `
+		--state STATE
+		--severity SEVERITIES
+		--fail-on SEVERITY
+`,
+		FlagValues: map[string]flagValue{
+			"--state":    enumValue("open", "fixed", "dismissed", "auto_dismissed", "all"),
+			"--severity": enumListValue(alertSeverities...),
+			"--fail-on":  enumValue(alertSeverities...),
+		},
+	}
+
+	cmdSecurityAdvisories = &Command{
+		Key: "advisories",
+		Run: listSecurityAdvisories,
+		KnownFlags: `
+		--state STATE
+`,
+		FlagValues: map[string]flagValue{
+			"--state": enumValue("published", "draft", "triage", "closed", "all"),
+		},
+	}
+)
+
+// alertSeverities are ordered from the least to the most severe.
+var alertSeverities = []string{"low", "medium", "high", "critical"}
+
+func init() {
+	cmdSecurity.Use(cmdSecurityAlerts)
+	cmdSecurity.Use(cmdSecurityAdvisories)
+	CmdRunner.Use(cmdSecurity)
+}
+
+func security(cmd *Command, args *Args) {
+	utils.Check(cmd.UsageError(""))
+}
+
+func severityRank(severity string) int {
+	for i, s := range alertSeverities {
+		if s == severity {
+			return i
+		}
+	}
+	return -1
+}
+
+func alertSeverity(alert github.DependabotAlert) string {
+	if alert.SecurityVulnerability.Severity != "" {
+		return alert.SecurityVulnerability.Severity
+	}
+	return alert.SecurityAdvisory.Severity
+}
+
+func listSecurityAlerts(cmd *Command, args *Args) {
+	state := "open"
+	if args.Flag.HasReceived("--state") {
+		state = args.Flag.Value("--state")
+	}
+	severities := []string{}
+	if args.Flag.HasReceived("--severity") {
+		for _, severity := range strings.Split(args.Flag.Value("--severity"), ",") {
+			severities = append(severities, strings.TrimSpace(severity))
+		}
+	}
+	failOn := args.Flag.Value("--fail-on")
+
+	project := repositoryArgument(cmd, args)
+	args.NoForward()
+	if args.Noop {
+		ui.Printf("Would request the list of Dependabot alerts of %s\n", project)
+		return
+	}
+
+	apiState := state
+	if state == "all" {
+		apiState = ""
+	}
+	gh := github.NewClient(project.Host)
+	alerts, err := gh.FetchDependabotAlerts(project, apiState, severities)
+	utils.Check(err)
+
+	ui.Print(formatSecurityAlerts(alerts, state == "all"))
+
+	if failOn == "" {
+		return
+	}
+	failing := 0
+	for _, alert := range alerts {
+		if severityRank(alertSeverity(alert)) >= severityRank(failOn) {
+			failing++
+		}
+	}
+	if failing > 0 {
+		ui.Errorf("%s of %s severity or higher\n", pluralize(failing, "alert"), failOn)
+		os.Exit(utils.ExitError)
+	}
+}
+
+// formatSecurityAlerts lines up alerts in columns. The state of each alert is
+// only shown when alerts of all states are listed.
+func formatSecurityAlerts(alerts []github.DependabotAlert, showState bool) string {
+	rows := [][]string{}
+	for _, alert := range alerts {
+		pkg := alert.Dependency.Package.Name
+		if ecosystem := alert.Dependency.Package.Ecosystem; ecosystem != "" {
+			pkg = ecosystem + "/" + pkg
+		}
+		id := alert.SecurityAdvisory.CveID
+		if id == "" {
+			id = alert.SecurityAdvisory.GhsaID
+		}
+		row := []string{fmt.Sprintf("#%d", alert.Number)}
+		if showState {
+			row = append(row, alert.State)
+		}
+		row = append(row, alertSeverity(alert), pkg, alert.SecurityVulnerability.VulnerableVersionRange, alert.Dependency.ManifestPath, id)
+		rows = append(rows, row)
+	}
+	return alignRows(rows)
+}
+
+func listSecurityAdvisories(cmd *Command, args *Args) {
+	state := "published"
+	if args.Flag.HasReceived("--state") {
+		state = args.Flag.Value("--state")
+	}
+
+	project := repositoryArgument(cmd, args)
+	args.NoForward()
+	if args.Noop {
+		ui.Printf("Would request the list of security advisories of %s\n", project)
+		return
+	}
+
+	if state == "all" {
+		state = ""
+	}
+	gh := github.NewClient(project.Host)
+	advisories, err := gh.FetchSecurityAdvisories(project, state)
+	utils.Check(err)
+
+	ui.Print(formatSecurityAdvisories(advisories))
+}
+
+// formatSecurityAdvisories lines up advisories in columns. Advisories that
+// weren't published show their state in place of the publishing date.
+func formatSecurityAdvisories(advisories []github.SecurityAdvisory) string {
+	rows := [][]string{}
+	for _, advisory := range advisories {
+		cve := advisory.CveID
+		if cve == "" {
+			cve = "-"
+		}
+		published := advisory.State
+		if advisory.PublishedAt != nil {
+			published = advisory.PublishedAt.Format("2006-01-02")
+		}
+		rows = append(rows, []string{advisory.GhsaID, cve, advisory.Severity, published, advisory.Summary})
+	}
+	return alignRows(rows)
+}
+
+// alignRows pads all columns but the last one to the width of their widest
+// cell, separating them with two spaces.
+func alignRows(rows [][]string) string {
+	widths := []int{}
+	for _, row := range rows {
+		for i, cell := range row {
+			if i == len(widths) {
+				widths = append(widths, 0)
+			}
+			if len(cell) > widths[i] {
+				widths[i] = len(cell)
+			}
+		}
+	}
+
+	lines := ""
+	for _, row := range rows {
+		cells := make([]string, len(row))
+		for i, cell := range row {
+			if i < len(row)-1 {
+				cell = fmt.Sprintf("%-*s", widths[i], cell)
+			}
+			cells[i] = cell
+		}
+		lines += strings.TrimRight(strings.Join(cells, "  "), " ") + "\n"
+	}
+	return lines
+}
@@ -0,0 +1,38 @@
+package commands
+
+import (
+	"testing"
+
+	"github.com/bmizerany/assert"
+	"github.com/github/hub/github"
+)
+
+func TestFormatSecurityAlerts(t *testing.T) {
+	alert := github.DependabotAlert{Number: 12, State: "dismissed"}
+	alert.Dependency.Package.Ecosystem = "npm"
+	alert.Dependency.Package.Name = "minimist"
+	alert.Dependency.ManifestPath = "package-lock.json"
+	alert.SecurityAdvisory.GhsaID = "GHSA-xvch-5gv4-984h"
+	alert.SecurityAdvisory.Severity = "critical"
+	alert.SecurityVulnerability.VulnerableVersionRange = "< 1.2.6"
+
+	other := github.DependabotAlert{Number: 9, State: "open"}
+	other.Dependency.Package.Name = "jinja2"
+	other.Dependency.ManifestPath = "requirements.txt"
+	other.SecurityAdvisory.CveID = "CVE-2024-22195"
+	other.SecurityAdvisory.Severity = "high"
+	other.SecurityVulnerability.Severity = "medium"
+	other.SecurityVulnerability.VulnerableVersionRange = ">= 2.0, < 3.1.3"
+
+	alerts := []github.DependabotAlert{alert, other}
+	assert.Equal(t, "#12  critical  npm/minimist  < 1.2.6          package-lock.json  GHSA-xvch-5gv4-984h\n"+
+		"#9   medium    jinja2        >= 2.0, < 3.1.3  requirements.txt   CVE-2024-22195\n", formatSecurityAlerts(alerts, false))
+	assert.Equal(t, "#12  dismissed  critical  npm/minimist  < 1.2.6          package-lock.json  GHSA-xvch-5gv4-984h\n"+
+		"#9   open       medium    jinja2        >= 2.0, < 3.1.3  requirements.txt   CVE-2024-22195\n", formatSecurityAlerts(alerts, true))
+}
+
+func TestSeverityRank(t *testing.T) {
+	assert.T(t, severityRank("critical") > severityRank("high"))
+	assert.T(t, severityRank("medium") > severityRank("low"))
+	assert.Equal(t, -1, severityRank("unknown"))
+}
@@ -0,0 +1,225 @@
+package commands
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/github/hub/github"
+	"github.com/github/hub/ui"
+	"github.com/github/hub/utils"
+)
+
+var (
+	cmdStar = &Command{
+		Run: star,
+		Usage: `
+star [<OWNER>/<REPO>]
+star --list [-L <LIMIT>] [-f <FORMAT>] [<USER>]
+`,
+		Long: `Star a GitHub repository, or list starred repositories.
+
+Without <OWNER>/<REPO>, the repository of the current project is starred.
+Starring a repository that is already starred does nothing.
+
+## Options:
+
+	--list
+		List the repositories starred by <USER> (default: the authenticated user),
+		the most recently starred first.
+
+	-L, --limit <LIMIT>
+		Display only the first <LIMIT> starred repositories.
+
+	-f, --format <FORMAT>
+		Pretty print the list of starred repositories using <FORMAT> (default:
+		"%R%n"). See the "PRETTY FORMATS" section of git-log(1) for some
+		additional details on how placeholders are used in format. The available
+		placeholders are:
+
+		%R: repository name with owner, "<OWNER>/<REPO>"
+
+		%o: owner login
+
+		%N: repository name
+
+		%d: description
+
+		%S: visibility, "public" or "private"
+
+		%U: the URL of the repository
+
+		%n: newline
+
+		%%: a literal %
+
+	--color[=<WHEN>]
+		Enable colored output even if stdout is not a terminal. <WHEN> can be one
+		of "always" (default for '--color'), "never", or "auto" (default).
+
+## Examples:
+		$ hub star
+		Starred mislav/dotfiles.
+
+		$ hub star --list -L 10 -f "%R	%d%n"
+
+## See also:
+
+hub-unstar(1), hub-watch(1), hub(1)
+`,
+		KnownFlags: `
+		--list
+		-L, --limit N
+		-f, --format FMT
+		--color
+`,
+		FlagValues: map[string]flagValue{
+			"--limit": intValue(1),
+			"--color": colorValue,
+		},
+	}
+
+	cmdUnstar = &Command{
+		Run:   unstar,
+		Usage: "unstar [<OWNER>/<REPO>]",
+		Long: `Remove the star from a GitHub repository.
+
+Without <OWNER>/<REPO>, the repository of the current project is unstarred.
+Unstarring a repository that isn't starred does nothing.
+
+## See also:
+
+hub-star(1), hub(1)
+`,
+	}
+)
+
+func init() {
+	CmdRunner.Use(cmdStar)
+	CmdRunner.Use(cmdUnstar)
+}
+
+// repositoryArgument is the project given as "<OWNER>/<REPO>" or, if there
+// is none, the project of the current repository.
+func repositoryArgument(cmd *Command, args *Args) *github.Project {
+	if args.ParamsSize() > 1 {
+		utils.Check(cmd.UsageError(""))
+	}
+	if args.IsParamsEmpty() {
+		localRepo, err := github.LocalRepo()
+		utils.Check(err)
+		project, err := localRepo.MainProject()
+		utils.Check(err)
+		return project
+	}
+
+	name := args.FirstParam()
+	if !strings.Contains(name, "/") || !regexp.MustCompile(NameWithOwnerRe).MatchString(name) {
+		utils.Check(cmd.UsageError("invalid repository: " + name))
+	}
+	split := strings.SplitN(name, "/", 2)
+	return github.NewProject(split[0], split[1], authHost())
+}
+
+func star(cmd *Command, args *Args) {
+	if args.Flag.Bool("--list") {
+		listStarred(cmd, args)
+		return
+	}
+	for _, flag := range []string{"--limit", "--format"} {
+		if args.Flag.HasReceived(flag) {
+			utils.Check(cmd.UsageError("the '" + flag + "' option requires '--list'"))
+		}
+	}
+
+	project := repositoryArgument(cmd, args)
+	args.NoForward()
+	if args.Noop {
+		ui.Printf("Would star %s\n", project)
+		return
+	}
+
+	gh := github.NewClient(project.Host)
+	starred, err := gh.IsStarred(project)
+	utils.Check(err)
+	if starred {
+		return
+	}
+	utils.Check(gh.StarRepository(project))
+	ui.Printf("Starred %s.\n", project)
+}
+
+func listStarred(cmd *Command, args *Args) {
+	if args.ParamsSize() > 1 {
+		utils.Check(cmd.UsageError(""))
+	}
+	user := ""
+	if !args.IsParamsEmpty() {
+		user = args.FirstParam()
+	}
+
+	flagFormat := "%R%n"
+	if args.Flag.HasReceived("--format") {
+		flagFormat = args.Flag.Value("--format")
+	}
+	format, err := ui.CompileFormat(flagFormat)
+	utils.Check(err)
+
+	args.NoForward()
+	if args.Noop {
+		if user == "" {
+			ui.Printf("Would request the list of your starred repositories\n")
+		} else {
+			ui.Printf("Would request the list of repositories starred by %s\n", user)
+		}
+		return
+	}
+
+	gh := github.NewClient(authHost())
+	repos, err := gh.FetchStarredRepositories(user, args.Flag.Int("--limit"))
+	utils.Check(err)
+
+	colorize := colorizeOutput(args.Flag.HasReceived("--color"), args.Flag.Value("--color"))
+	for _, repo := range repos {
+		ui.Print(formatRepository(repo, format, colorize))
+	}
+}
+
+func formatRepository(repo github.Repository, format *ui.Format, colorize bool) string {
+	owner := ""
+	if repo.Owner != nil {
+		owner = repo.Owner.Login
+	}
+	visibility := "public"
+	if repo.Private {
+		visibility = "private"
+	}
+
+	placeholders := map[string]string{
+		"R": repo.FullName,
+		"o": owner,
+		"N": repo.Name,
+		"d": repo.Description,
+		"S": visibility,
+		"U": repo.HtmlUrl,
+	}
+
+	return format.Expand(placeholders, colorize)
+}
+
+func unstar(cmd *Command, args *Args) {
+	project := repositoryArgument(cmd, args)
+	args.NoForward()
+	if args.Noop {
+		ui.Printf("Would unstar %s\n", project)
+		return
+	}
+
+	gh := github.NewClient(project.Host)
+	starred, err := gh.IsStarred(project)
+	utils.Check(err)
+	if !starred {
+		return
+	}
+	utils.Check(gh.UnstarRepository(project))
+	ui.Printf("Unstarred %s.\n", project)
+}
@@ -0,0 +1,110 @@
+package commands
+
+import (
+	"github.com/github/hub/github"
+	"github.com/github/hub/ui"
+	"github.com/github/hub/utils"
+)
+
+var (
+	cmdWatchRepo = &Command{
+		Run:   watchRepo,
+		Usage: "watch [--ignore|--releases-only] [<OWNER>/<REPO>]",
+		Long: `Watch a GitHub repository to get notified of its activity.
+
+Without <OWNER>/<REPO>, the repository of the current project is watched.
+Watching a repository the same way it's already watched does nothing.
+
+## Options:
+
+	--ignore
+		Never get notified of activity in the repository, not even when
+		participating or mentioned.
+
+	--releases-only
+		Only get notified of new releases, besides participating and mentions.
+		The API sets this up as a subscription that is neither "subscribed" nor
+		"ignored"; GitHub shows it as watching "Custom" events.
+
+## See also:
+
+hub-unwatch(1), hub-star(1), hub(1)
+`,
+		KnownFlags: `
+		--ignore
+		--releases-only
+`,
+	}
+
+	cmdUnwatchRepo = &Command{
+		Run:   unwatchRepo,
+		Usage: "unwatch [<OWNER>/<REPO>]",
+		Long: `Stop watching a GitHub repository.
+
+Without <OWNER>/<REPO>, the repository of the current project is unwatched.
+Notifications of threads that you participate in are still delivered.
+Unwatching a repository that isn't watched does nothing.
+
+## See also:
+
+hub-watch(1), hub(1)
+`,
+	}
+)
+
+func init() {
+	CmdRunner.Use(cmdWatchRepo)
+	CmdRunner.Use(cmdUnwatchRepo)
+}
+
+func watchRepo(cmd *Command, args *Args) {
+	ignore := args.Flag.Bool("--ignore")
+	releasesOnly := args.Flag.Bool("--releases-only")
+	if ignore && releasesOnly {
+		utils.Check(cmd.UsageError("the '--ignore' and '--releases-only' options are mutually exclusive"))
+	}
+
+	wanted := github.Subscription{Subscribed: true}
+	done := "Watching %s.\n"
+	if ignore {
+		wanted = github.Subscription{Ignored: true}
+		done = "Ignoring %s.\n"
+	} else if releasesOnly {
+		wanted = github.Subscription{}
+		done = "Watching releases of %s.\n"
+	}
+
+	project := repositoryArgument(cmd, args)
+	args.NoForward()
+	if args.Noop {
+		ui.Printf("Would update the subscription to %s\n", project)
+		return
+	}
+
+	gh := github.NewClient(project.Host)
+	subscription, err := gh.FetchSubscription(project)
+	utils.Check(err)
+	if subscription != nil && *subscription == wanted {
+		return
+	}
+	utils.Check(gh.SetSubscription(project, wanted))
+	ui.Printf(done, project)
+}
+
+func unwatchRepo(cmd *Command, args *Args) {
+	project := repositoryArgument(cmd, args)
+	args.NoForward()
+	if args.Noop {
+		ui.Printf("Would unwatch %s\n", project)
+		return
+	}
+
+	gh := github.NewClient(project.Host)
+	subscription, err := gh.FetchSubscription(project)
+	utils.Check(err)
+	if subscription == nil {
+		return
+	}
+	utils.Check(gh.DeleteSubscription(project))
+	ui.Printf("Stopped watching %s.\n", project)
+}
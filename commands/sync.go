@@ -13,24 +13,65 @@ import (
 
 var cmdSync = &Command{
 	Run:   sync,
-	Usage: "sync [--color]",
+	Usage: "sync [--autostash] [--prune] [--dry-run] [--format <FORMAT>] [--color]",
 	Long: `Fetch git objects from upstream and update local branches.
 
 - If the local branch is outdated, fast-forward it;
 - If the local branch contains unpushed work, warn about it;
-- If the branch seems merged and its upstream branch was deleted, delete it.
+- If the branch seems merged and its upstream branch was deleted, delete it;
+- If no branch is protected by 'hub pr checkout --protect' anymore, delete
+  the pre-push hook that enforced the protection.
 
 If a local branch does not have any upstream configuration, but has a
 same-named branch on the remote, treat that as its upstream branch.
 
+When the working tree has uncommitted changes that would be overwritten by
+fast-forwarding the current branch, that branch is skipped and all other
+branches are still updated. At the end, the branches that were updated,
+skipped, or deleted are listed in separate sections. Branches that are checked
+out in other worktrees are skipped.
+
 ## Options:
+	--autostash
+		Stash uncommitted changes before fast-forwarding the current branch and
+		apply them again afterwards. If applying them results in conflicts, the
+		working tree is left as it was after the fast-forward and the changes are
+		kept in the stash.
+
+	--prune
+		Fetch without pruning, then delete the remote-tracking branches whose
+		branches no longer exist on the remote like 'git remote prune' does, and
+		list them.
+
+	-n, --dry-run
+		List the branches that would be fast-forwarded, deleted or pruned without
+		changing anything. This compares local branches to the remote-tracking
+		branches as of the last fetch, since fetching would update those.
+
+	-f, --format <FORMAT>
+		Print a line for every branch using <FORMAT> instead of listing them in
+		sections. See the "PRETTY FORMATS" section of git-log(1) for some
+		additional details on how placeholders are used in format. The available
+		placeholders are:
+
+		%a: action: "fast-forwarded", "deleted (merged)", "pruned remote ref",
+		"skipped (diverged)", "skipped (not merged)", "skipped (uncommitted
+		changes)", or "skipped (worktree)"
+
+		%b: name of the branch, or of the remote-tracking branch for "pruned
+		remote ref"
+
+		%s: abbreviated SHA that the branch pointed to before
+
+		%m: why the branch was skipped
+
 	--color[=<WHEN>]
 		Enable colored output even if stdout is not a terminal. <WHEN> can be one
 		of "always" (default for '--color'), "never", or "auto" (default).
 
 ## See also:
 
-hub(1), git-fetch(1)
+hub(1), git-fetch(1), git-remote(1)
 `,
 }
 
@@ -38,6 +79,31 @@ func init() {
 	CmdRunner.Use(cmdSync)
 }
 
+// The actions that 'hub sync' takes, or would take with '--dry-run', as
+// reported by the %a placeholder of '--format'.
+const (
+	syncFastForwarded      = "fast-forwarded"
+	syncDeleted            = "deleted (merged)"
+	syncPruned             = "pruned remote ref"
+	syncSkippedDiverged    = "skipped (diverged)"
+	syncSkippedNotMerged   = "skipped (not merged)"
+	syncSkippedUncommitted = "skipped (uncommitted changes)"
+	syncSkippedWorktree    = "skipped (worktree)"
+)
+
+// syncResult is what happened to a single local branch or remote-tracking
+// branch during 'hub sync'.
+type syncResult struct {
+	Action  string
+	Branch  string
+	SHA     string
+	Message string
+}
+
+func (r syncResult) skipped() bool {
+	return strings.HasPrefix(r.Action, "skipped ")
+}
+
 func sync(cmd *Command, args *Args) {
 	localRepo, err := github.LocalRepo()
 	utils.Check(err)
@@ -45,6 +111,12 @@ func sync(cmd *Command, args *Args) {
 	remote, err := localRepo.MainRemote()
 	utils.Check(err)
 
+	var format *ui.Format
+	if args.Flag.HasReceived("--format") {
+		format, err = ui.CompileFormat(args.Flag.Value("--format"))
+		utils.Check(err)
+	}
+
 	defaultBranch := localRepo.DefaultBranch(remote).ShortName()
 	fullDefaultBranch := fmt.Sprintf("refs/remotes/%s/%s", remote.Name, defaultBranch)
 	currentBranch := ""
@@ -52,8 +124,27 @@ func sync(cmd *Command, args *Args) {
 		currentBranch = curBranch.ShortName()
 	}
 
-	err = git.Spawn("fetch", "--prune", "--quiet", "--progress", remote.Name)
-	utils.Check(err)
+	dryRun := args.Flag.Bool("--dry-run")
+	prune := args.Flag.Bool("--prune")
+	results := []syncResult{}
+
+	// a dry run compares against the remote-tracking branches as they were
+	// last fetched, since fetching would update them
+	if !dryRun {
+		if prune {
+			err = git.Spawn("fetch", "--quiet", "--progress", remote.Name)
+		} else {
+			err = git.Spawn("fetch", "--prune", "--quiet", "--progress", remote.Name)
+		}
+		utils.Check(err)
+	}
+	if prune {
+		pruned, err := git.RemotePrune(remote.Name, dryRun)
+		utils.Check(err)
+		for _, ref := range pruned {
+			results = append(results, syncResult{Action: syncPruned, Branch: ref})
+		}
+	}
 
 	branchToRemote := map[string]string{}
 	if lines, err := git.ConfigAll("branch.*.remote"); err == nil {
@@ -69,20 +160,11 @@ func sync(cmd *Command, args *Args) {
 	branches, err := git.LocalBranches()
 	utils.Check(err)
 
-	var green,
-		lightGreen,
-		red,
-		lightRed,
-		resetColor string
+	// branches checked out in other worktrees must not be moved from under them
+	worktreeBranches, _ := git.WorktreeBranches()
+	workdir, _ := git.WorkdirName()
 
-	colorize := colorizeOutput(args.Flag.HasReceived("--color"), args.Flag.Value("--color"))
-	if colorize {
-		green = "\033[32m"
-		lightGreen = "\033[32;1m"
-		red = "\033[31m"
-		lightRed = "\033[31;1m"
-		resetColor = "\033[0m"
-	}
+	autostash := args.Flag.Bool("--autostash")
 
 	for _, branch := range branches {
 		fullBranch := fmt.Sprintf("refs/heads/%s", branch)
@@ -100,6 +182,7 @@ func sync(cmd *Command, args *Args) {
 			remoteBranch = ""
 		}
 
+		var result *syncResult
 		if remoteBranch != "" {
 			diff, err := git.NewRange(fullBranch, remoteBranch)
 			utils.Check(err)
@@ -107,31 +190,177 @@ func sync(cmd *Command, args *Args) {
 			if diff.IsIdentical() {
 				continue
 			} else if diff.IsAncestor() {
-				if branch == currentBranch {
-					git.Quiet("merge", "--ff-only", "--quiet", remoteBranch)
-				} else {
-					git.Quiet("update-ref", fullBranch, remoteBranch)
-				}
-				ui.Printf("%sUpdated branch %s%s%s (was %s).\n", green, lightGreen, branch, resetColor, diff.A[0:7])
+				result = &syncResult{Action: syncFastForwarded, Branch: branch, SHA: diff.A[0:7]}
 			} else {
-				ui.Errorf("warning: `%s' seems to contain unpushed commits\n", branch)
+				result = &syncResult{Action: syncSkippedDiverged, Branch: branch, SHA: diff.A[0:7], Message: "seems to contain unpushed commits"}
 			}
 		} else if gone {
 			diff, err := git.NewRange(fullBranch, fullDefaultBranch)
 			utils.Check(err)
 
 			if diff.IsAncestor() {
-				if branch == currentBranch {
-					git.Quiet("checkout", "--quiet", defaultBranch)
-					currentBranch = defaultBranch
+				result = &syncResult{Action: syncDeleted, Branch: branch, SHA: diff.A[0:7]}
+			} else {
+				result = &syncResult{Action: syncSkippedNotMerged, Branch: branch, SHA: diff.A[0:7], Message: fmt.Sprintf("was deleted on %s, but appears not merged into %s", remote.Name, defaultBranch)}
+			}
+		}
+		if result == nil {
+			continue
+		}
+
+		if dir, ok := worktreeBranches[branch]; ok && !result.skipped() && branch != currentBranch && dir != workdir {
+			result.Action = syncSkippedWorktree
+			result.Message = fmt.Sprintf("is checked out in %s", dir)
+		} else if dryRun {
+			// report what would be done without doing it
+		} else if result.Action == syncFastForwarded {
+			if branch == currentBranch {
+				if action, reason := fastForwardCurrentBranch(remoteBranch, autostash); reason != "" {
+					result.Action = action
+					result.Message = reason
 				}
-				git.Quiet("branch", "-D", branch)
-				ui.Printf("%sDeleted branch %s%s%s (was %s).\n", red, lightRed, branch, resetColor, diff.A[0:7])
 			} else {
-				ui.Errorf("warning: `%s' was deleted on %s, but appears not merged into %s\n", branch, remote.Name, defaultBranch)
+				git.Quiet("update-ref", fullBranch, remoteBranch)
+			}
+		} else if result.Action == syncDeleted {
+			if branch == currentBranch {
+				git.Quiet("checkout", "--quiet", defaultBranch)
+				currentBranch = defaultBranch
 			}
+			git.Quiet("branch", "-D", branch)
 		}
+		results = append(results, *result)
+	}
+
+	colorize := colorizeOutput(args.Flag.HasReceived("--color"), args.Flag.Value("--color"))
+	if format != nil {
+		for _, result := range results {
+			ui.Print(format.Expand(map[string]string{
+				"a": result.Action,
+				"b": result.Branch,
+				"s": result.SHA,
+				"m": result.Message,
+			}, colorize))
+		}
+	} else {
+		printSyncResults(results, dryRun, colorize)
+	}
+
+	// deleting a branch also drops its push guard config, which may have left
+	// the hook installed by 'pr checkout --protect' unused
+	if !dryRun {
+		utils.Check(removeUnusedPushGuardHook())
 	}
 
 	args.NoForward()
 }
+
+// printSyncResults lists the branches that were updated, skipped, deleted or
+// pruned in separate sections, with skipped branches going to stderr.
+func printSyncResults(results []syncResult, dryRun, colorize bool) {
+	var green,
+		lightGreen,
+		red,
+		lightRed,
+		resetColor string
+
+	if colorize {
+		green = "\033[32m"
+		lightGreen = "\033[32;1m"
+		red = "\033[31m"
+		lightRed = "\033[31;1m"
+		resetColor = "\033[0m"
+	}
+
+	sections := []struct {
+		action      string
+		title       string
+		dryRunTitle string
+		color       string
+		branchColor string
+	}{
+		{syncFastForwarded, "Updated branches:", "Would update branches:", green, lightGreen},
+		{"skipped", "Skipped branches:", "Skipped branches:", "", ""},
+		{syncDeleted, "Deleted branches:", "Would delete branches:", red, lightRed},
+		{syncPruned, "Pruned remote refs:", "Would prune remote refs:", red, lightRed},
+	}
+
+	for _, section := range sections {
+		lines := []string{}
+		for _, result := range results {
+			if result.skipped() {
+				if section.action == "skipped" {
+					lines = append(lines, fmt.Sprintf("%s: %s", result.Branch, result.Message))
+				}
+			} else if result.Action == section.action {
+				line := fmt.Sprintf("%s%s%s", section.branchColor, result.Branch, resetColor)
+				if result.SHA != "" {
+					line += fmt.Sprintf(" (was %s)", result.SHA)
+				}
+				lines = append(lines, line)
+			}
+		}
+		if len(lines) == 0 {
+			continue
+		}
+
+		title := section.title
+		if dryRun {
+			title = section.dryRunTitle
+		}
+		if section.action == "skipped" {
+			ui.Errorln(title)
+			for _, line := range lines {
+				ui.Errorf("  %s\n", line)
+			}
+		} else {
+			ui.Printf("%s%s%s\n", section.color, title, resetColor)
+			for _, line := range lines {
+				ui.Printf("  %s\n", line)
+			}
+		}
+	}
+}
+
+// fastForwardCurrentBranch merges remoteBranch into the branch that is checked
+// out and returns how the branch was skipped and why, if it was. With autostash,
+// uncommitted changes are set aside during the merge and applied again
+// afterwards, like 'git pull --autostash' does.
+func fastForwardCurrentBranch(remoteBranch string, autostash bool) (action, reason string) {
+	dirty := git.HasUncommittedChanges()
+	stash := ""
+	if dirty && autostash {
+		var err error
+		stash, err = git.StashCreate("autostash")
+		utils.Check(err)
+		git.Quiet("reset", "--hard", "--quiet")
+	}
+
+	merged := git.Quiet("merge", "--ff-only", "--quiet", remoteBranch)
+	if stash != "" {
+		applyAutostash(stash)
+	}
+
+	if merged {
+		return "", ""
+	} else if dirty && !autostash {
+		return syncSkippedUncommitted, "the working tree has uncommitted changes that would be overwritten; commit or stash them, or use '--autostash'"
+	}
+	return syncSkippedDiverged, fmt.Sprintf("could not be fast-forwarded to %s", strings.TrimPrefix(remoteBranch, "refs/remotes/"))
+}
+
+// applyAutostash brings back changes stashed by fastForwardCurrentBranch. If
+// they conflict with the updated branch, the working tree is left clean and
+// the changes are kept in the stash list for the user to deal with.
+func applyAutostash(stash string) {
+	if git.Quiet("stash", "apply", "--quiet", stash) {
+		return
+	}
+	git.Quiet("reset", "--hard", "--quiet")
+	if !git.Quiet("stash", "store", "--quiet", "-m", "autostash", stash) {
+		utils.Check(fmt.Errorf("Error: could not store the autostash; your changes are in commit %s", stash))
+	}
+	ui.Errorln("Applying autostash resulted in conflicts.")
+	ui.Errorln("Your changes are safe in the stash.")
+	ui.Errorln("You can run \"git stash pop\" or \"git stash drop\" at any time.")
+}
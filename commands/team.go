@@ -0,0 +1,109 @@
+package commands
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/github/hub/github"
+	"github.com/github/hub/ui"
+	"github.com/github/hub/utils"
+)
+
+var (
+	cmdTeam = &Command{
+		Run: team,
+		Usage: `
+team members [--role <ROLE>] [--detailed] <ORG>/<TEAM>
+team members --check <LOGIN> [--role <ROLE>] <ORG>/<TEAM>
+`,
+		Long: `Inspect the membership of a team in a GitHub organization.
+
+## Commands:
+
+	* _members_:
+		List the logins of members of the team, one per line. Members of child
+		teams are included.
+
+## Options:
+
+	--role <ROLE>
+		Only list members with <ROLE> in the team: "maintainer" or "member".
+
+	--detailed
+		Look up each member to list their name next to their login.
+
+	--check <LOGIN>
+		Instead of listing members, exit with status 0 if <LOGIN> is an active
+		member of the team and with status 1 otherwise. Pending invitations don't
+		count as membership.
+
+	<ORG>/<TEAM>
+		The login of the organization and the slug of its team.
+
+## Examples:
+		$ hub team members acme/security
+
+		$ hub team members --check octocat --role maintainer acme/security
+
+## See also:
+
+hub-org(1), hub(1)
+`,
+	}
+
+	cmdTeamMembers = &Command{
+		Key: "members",
+		Run: listTeamMembers,
+		KnownFlags: `
+		--role ROLE
+		--detailed
+		--check LOGIN
+`,
+	}
+)
+
+func init() {
+	cmdTeam.Use(cmdTeamMembers)
+	CmdRunner.Use(cmdTeam)
+}
+
+func team(cmd *Command, args *Args) {
+	utils.Check(cmd.UsageError(""))
+}
+
+func listTeamMembers(cmd *Command, args *Args) {
+	if args.ParamsSize() != 1 {
+		utils.Check(cmd.UsageError(""))
+	}
+	parts := strings.SplitN(args.GetParam(0), "/", 2)
+	if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+		utils.Check(cmd.UsageError(fmt.Sprintf("expected <ORG>/<TEAM>, got %q", args.GetParam(0))))
+	}
+	orgName, teamSlug := parts[0], parts[1]
+
+	filter := github.MembersFilter{Role: args.Flag.Value("--role")}
+	if filter.Role != "" && filter.Role != "maintainer" && filter.Role != "member" {
+		utils.Check(cmd.UsageError(fmt.Sprintf("invalid role %q", filter.Role)))
+	}
+	login := args.Flag.Value("--check")
+	if login != "" && args.Flag.Bool("--detailed") {
+		utils.Check(cmd.UsageError("'--check' can only be combined with '--role'"))
+	}
+
+	args.NoForward()
+	if args.Noop {
+		ui.Printf("Would request the list of members of %s/%s\n", orgName, teamSlug)
+		return
+	}
+
+	gh := github.NewClient(authHost())
+	if login != "" {
+		isMember, err := gh.IsTeamMember(orgName, teamSlug, login, filter.Role)
+		utils.Check(err)
+		exitMembershipCheck(isMember)
+	}
+
+	members, err := gh.FetchTeamMembers(orgName, teamSlug, filter)
+	utils.Check(err)
+	printMembers(gh, members, args.Flag.Bool("--detailed"))
+}
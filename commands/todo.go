@@ -0,0 +1,345 @@
+package commands
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/github/hub/github"
+	"github.com/github/hub/ui"
+	"github.com/github/hub/utils"
+)
+
+var cmdTodo = &Command{
+	Run:   todo,
+	Usage: "todo [--org <ORG>] [-L <LIMIT>] [-d <DATE>] [--json]",
+	Long: `Summarize what needs your attention across repositories on GitHub.
+
+The summary is made of these sections, with the most recently updated items
+first:
+
+	* Issues assigned to you
+	* Pull requests awaiting your review
+	* Your pull requests with failing checks
+	* Your pull requests with new comments by others
+
+Only open issues and pull requests in repositories that aren't archived are
+included.
+
+## Options:
+	--org <ORG>
+		Only include repositories of the organization <ORG>. Can be repeated or
+		given as a comma-separated list.
+
+	-L, --limit <LIMIT>
+		Show at most <LIMIT> items in each section (default: 10).
+
+	-d, --since <DATE>
+		Count comments made after <DATE> as new (default: 24 hours ago). <DATE>
+		can be in the "YYYY-MM-DD" or ISO 8601 format. Only the 300 most recently
+		updated of your pull requests are checked for new comments.
+
+	--json
+		Print the sections as a JSON object instead, keyed by "assigned_issues",
+		"review_requests", "failing_checks" and "new_comments".
+
+## Examples:
+		$ hub todo
+		$ hub todo --org acme --limit 5
+
+## See also:
+
+hub-issue(1), hub-pr(1), hub(1)
+`,
+	KnownFlags: `
+		--org ORG
+		-L, --limit N
+		-d, --since DATE
+		--json
+`,
+}
+
+func init() {
+	CmdRunner.Use(cmdTodo)
+}
+
+type todoItem struct {
+	Repo      string    `json:"repo"`
+	Number    int       `json:"number"`
+	Title     string    `json:"title"`
+	URL       string    `json:"url"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+type todoSection struct {
+	key   string
+	title string
+	query string
+	// newCommentsSince, when set, keeps only pull requests with comments by
+	// others made after that time
+	newCommentsSince time.Time
+
+	Total int        `json:"total_count"`
+	Items []todoItem `json:"items"`
+}
+
+func todo(cmd *Command, args *Args) {
+	if !args.IsParamsEmpty() {
+		utils.Check(cmd.UsageError(""))
+	}
+
+	limit := 10
+	if args.Flag.HasReceived("--limit") {
+		var err error
+		limit, err = strconv.Atoi(args.Flag.Value("--limit"))
+		if err != nil || limit < 1 || limit > 100 {
+			utils.Check(cmd.UsageError("--limit must be a number between 1 and 100"))
+		}
+	}
+
+	since := time.Now().Add(-24 * time.Hour)
+	if args.Flag.HasReceived("--since") {
+		var err error
+		since, err = parseTodoSince(args.Flag.Value("--since"))
+		if err != nil {
+			utils.Check(cmd.UsageError(err.Error()))
+		}
+	}
+
+	args.NoForward()
+	if args.Noop {
+		ui.Println("Would search for issues and pull requests that need your attention")
+		return
+	}
+
+	gh := github.NewClient(authHost())
+	user, err := gh.CurrentUser()
+	utils.Check(err)
+
+	scope := []string{"is:open", "archived:false"}
+	for _, orgName := range commaSeparated(args.Flag.AllValues("--org")) {
+		if orgName = strings.TrimSpace(orgName); orgName != "" {
+			scope = append(scope, searchQualifier("org", orgName))
+		}
+	}
+	sections := todoSections(user.Login, scope, since)
+	utils.Check(fetchTodoSections(gh, sections, user.Login, limit))
+
+	if args.Flag.Bool("--json") {
+		summary := map[string]*todoSection{}
+		for _, section := range sections {
+			summary[section.key] = section
+		}
+		out, err := json.MarshalIndent(summary, "", "  ")
+		utils.Check(err)
+		ui.Println(string(out))
+		return
+	}
+
+	for i, section := range sections {
+		if i > 0 {
+			ui.Println()
+		}
+		ui.Println(section.title)
+		printTodoItems(section)
+	}
+}
+
+func parseTodoSince(value string) (time.Time, error) {
+	if since, err := time.ParseInLocation("2006-01-02", value, time.Local); err == nil {
+		return since, nil
+	}
+	if since, err := time.Parse(time.RFC3339, value); err == nil {
+		return since, nil
+	}
+	return time.Time{}, fmt.Errorf("invalid date: %q", value)
+}
+
+func todoSections(login string, scope []string, since time.Time) []*todoSection {
+	qualifiers := func(q ...string) string {
+		return strings.Join(append(q, scope...), " ")
+	}
+	return []*todoSection{
+		{
+			key:   "assigned_issues",
+			title: "Issues assigned to you",
+			query: qualifiers("is:issue", searchQualifier("assignee", login)),
+		},
+		{
+			key:   "review_requests",
+			title: "Pull requests awaiting your review",
+			query: qualifiers("is:pr", searchQualifier("review-requested", login)),
+		},
+		{
+			key:   "failing_checks",
+			title: "Your pull requests with failing checks",
+			query: qualifiers("is:pr", searchQualifier("author", login), "status:failure"),
+		},
+		{
+			key:              "new_comments",
+			title:            "Your pull requests with new comments",
+			query:            qualifiers("is:pr", searchQualifier("author", login), "comments:>0", "updated:>="+since.UTC().Format(time.RFC3339)),
+			newCommentsSince: since,
+		},
+	}
+}
+
+// fetchTodoSections runs the searches of all sections at the same time and
+// fills in their items.
+func fetchTodoSections(gh *github.Client, sections []*todoSection, login string, limit int) error {
+	errs := make(chan error)
+	for _, section := range sections {
+		go func(section *todoSection) {
+			errs <- section.fetch(gh, login, limit)
+		}(section)
+	}
+
+	var firstErr error
+	for range sections {
+		if err := <-errs; err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	return firstErr
+}
+
+// newCommentsSearchLimit is how many of your most recently updated pull
+// requests are checked for new comments.
+const newCommentsSearchLimit = 300
+
+func (section *todoSection) fetch(gh *github.Client, login string, limit int) error {
+	searchLimit := limit
+	if !section.newCommentsSince.IsZero() {
+		// the total has to count what's left after filtering, so filter more
+		// than what's shown, up to a bound on the requests that takes
+		searchLimit = newCommentsSearchLimit
+	}
+	issues, total, err := gh.SearchIssues(nil, section.query, searchLimit)
+	if err != nil {
+		return err
+	}
+	section.Total = total
+
+	if !section.newCommentsSince.IsZero() {
+		// pushes and label changes make a pull request count as updated, too
+		if issues, err = withNewComments(gh, issues, login, section.newCommentsSince); err != nil {
+			return err
+		}
+		section.Total = len(issues)
+		if len(issues) > limit {
+			issues = issues[:limit]
+		}
+	}
+
+	section.Items = []todoItem{}
+	for _, issue := range issues {
+		section.Items = append(section.Items, todoItem{
+			Repo:      repoFromApiURL(issue.RepositoryUrl),
+			Number:    issue.Number,
+			Title:     issue.Title,
+			URL:       issue.HtmlUrl,
+			UpdatedAt: issue.UpdatedAt,
+		})
+	}
+	return nil
+}
+
+// newCommentsWorkers is how many pull requests have their comments looked up
+// at the same time.
+const newCommentsWorkers = 4
+
+// withNewComments keeps only the issues that someone other than login
+// commented on after since.
+func withNewComments(gh *github.Client, issues []github.Issue, login string, since time.Time) ([]github.Issue, error) {
+	type result struct {
+		index  int
+		hasNew bool
+		err    error
+	}
+
+	queue := make(chan int)
+	results := make(chan result)
+	for i := 0; i < newCommentsWorkers; i++ {
+		go func() {
+			for index := range queue {
+				hasNew, err := hasNewComments(gh, issues[index], login, since)
+				results <- result{index, hasNew, err}
+			}
+		}()
+	}
+	go func() {
+		for i := range issues {
+			queue <- i
+		}
+		close(queue)
+	}()
+
+	hasNew := make([]bool, len(issues))
+	var firstErr error
+	for range issues {
+		r := <-results
+		if r.err != nil && firstErr == nil {
+			firstErr = r.err
+		}
+		hasNew[r.index] = r.hasNew
+	}
+	if firstErr != nil {
+		return nil, firstErr
+	}
+
+	filtered := []github.Issue{}
+	for i, issue := range issues {
+		if hasNew[i] {
+			filtered = append(filtered, issue)
+		}
+	}
+	return filtered, nil
+}
+
+func hasNewComments(gh *github.Client, issue github.Issue, login string, since time.Time) (bool, error) {
+	project := github.NewProject("", repoFromApiURL(issue.RepositoryUrl), gh.Host.Host)
+	comments, err := gh.FetchCommentsSince(project, strconv.Itoa(issue.Number), since)
+	if err != nil {
+		return false, err
+	}
+	for _, comment := range comments {
+		if comment.CreatedAt.After(since) && comment.User != nil && !strings.EqualFold(comment.User.Login, login) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// repoFromApiURL returns "OWNER/REPO" for an API URL such as
+// "https://api.github.com/repos/OWNER/REPO".
+func repoFromApiURL(apiURL string) string {
+	parts := strings.Split(strings.TrimSuffix(apiURL, "/"), "/")
+	if len(parts) < 2 {
+		return apiURL
+	}
+	return strings.Join(parts[len(parts)-2:], "/")
+}
+
+func printTodoItems(section *todoSection) {
+	if len(section.Items) == 0 {
+		ui.Println("  nothing")
+		return
+	}
+
+	refs := make([]string, len(section.Items))
+	refWidth := 0
+	for i, item := range section.Items {
+		refs[i] = fmt.Sprintf("%s#%d", item.Repo, item.Number)
+		if len(refs[i]) > refWidth {
+			refWidth = len(refs[i])
+		}
+	}
+	for i, item := range section.Items {
+		ui.Printf("  %-*s  %s\n", refWidth, refs[i], item.Title)
+	}
+	if more := section.Total - len(section.Items); more > 0 {
+		ui.Printf("  and %d more\n", more)
+	}
+}
@@ -6,10 +6,12 @@ import (
 	"io/ioutil"
 	"os"
 	"path/filepath"
+	"regexp"
 	"strings"
 
 	"github.com/atotto/clipboard"
 	"github.com/github/hub/git"
+	"github.com/github/hub/github"
 	"github.com/github/hub/ui"
 	"github.com/github/hub/utils"
 )
@@ -49,6 +51,19 @@ func (m *messageBlocks) Set(value string) error {
 	return nil
 }
 
+// checkCurrentBranch aborts with an explanation when HEAD is detached or the
+// current branch has no commits yet. The hint tells how to run the command
+// without relying on the current branch.
+func checkCurrentBranch(hint string) {
+	_, err := git.SymbolicRef("HEAD")
+	switch err.(type) {
+	case *git.DetachedHeadError:
+		utils.Check(fmt.Errorf("Aborted: %s; check out a branch or %s", err, hint))
+	case *git.UnbornBranchError:
+		utils.Check(fmt.Errorf("Aborted: %s; make a commit first or %s", err, hint))
+	}
+}
+
 func isCloneable(file string) bool {
 	f, err := os.Open(file)
 	if err != nil {
@@ -102,6 +117,32 @@ func msgFromFile(filename string) (string, error) {
 	return strings.Replace(string(content), "\r\n", "\n", -1), nil
 }
 
+// hyperlinksEnabled reports whether listings should include OSC 8 hyperlinks.
+// They are never output unless stdout is a terminal; `hub.hyperlinks` can be
+// used to override the detection of capable terminals.
+func hyperlinksEnabled() bool {
+	if !ui.IsTerminal(os.Stdout) {
+		return false
+	}
+	if enabled, err := git.Config("hub.hyperlinks"); err == nil {
+		return enabled == "true"
+	}
+	return ui.TerminalSupportsHyperlinks()
+}
+
+// linkPlaceholders turns the values of the given placeholders into hyperlinks
+// pointing at url.
+func linkPlaceholders(placeholders map[string]string, url string, keys ...string) {
+	if url == "" {
+		return
+	}
+	for _, key := range keys {
+		if value := placeholders[key]; value != "" {
+			placeholders[key] = ui.Hyperlink(url, value)
+		}
+	}
+}
+
 func printBrowseOrCopy(args *Args, msg string, openBrowser bool, performCopy bool) {
 	if performCopy {
 		if err := clipboard.WriteAll(msg); err != nil {
@@ -123,3 +164,41 @@ func printBrowseOrCopy(args *Args, msg string, openBrowser bool, performCopy boo
 		})
 	}
 }
+
+var (
+	commitURLRe = regexp.MustCompile(`^(?:pull/\d+/)?commits?/([0-9a-fA-F]{7,40})\b`)
+	commitShaRe = regexp.MustCompile(`^[0-9a-fA-F]{7,40}$`)
+)
+
+// parseCommitReference resolves ref to a commit SHA. The ref can be anything
+// that the local git repository can resolve, such as a branch name, a bare
+// SHA, or the URL of a commit on GitHub. For URLs, the project that the commit
+// belongs to is returned in place of the given one.
+func parseCommitReference(project *github.Project, ref string) (*github.Project, string, error) {
+	if url, err := github.ParseURL(ref); err == nil {
+		if m := commitURLRe.FindStringSubmatch(url.ProjectPath()); m != nil {
+			return url.Project, m[1], nil
+		}
+		return nil, "", fmt.Errorf("Aborted: '%s' is not a URL of a commit", ref)
+	}
+
+	if sha, err := git.Ref(ref); err == nil {
+		return project, sha, nil
+	} else if commitShaRe.MatchString(ref) {
+		return project, ref, nil
+	}
+
+	return nil, "", fmt.Errorf("Aborted: no revision could be determined from '%s'", ref)
+}
+
+// performIdempotently runs fn through the idempotency ledger if the
+// '--idempotency-key' flag was given. In that case, the output of an earlier
+// successful run with the same key is returned instead of running fn again.
+func performIdempotently(args *Args, host string, fn func() (output string, success bool, err error)) (output string, replayed bool, err error) {
+	key := args.Flag.Value("--idempotency-key")
+	if key == "" {
+		output, _, err = fn()
+		return
+	}
+	return github.NewIdempotencyLedger().Do(host, key, fn)
+}
@@ -6,6 +6,7 @@ import (
 	"testing"
 
 	"github.com/bmizerany/assert"
+	"github.com/github/hub/github"
 )
 
 func TestDirIsNotEmpty(t *testing.T) {
@@ -30,3 +31,20 @@ func createTempDir(t *testing.T) string {
 	}
 	return dir
 }
+
+func TestParseCommitReference_URL(t *testing.T) {
+	project := github.NewProject("mislav", "dotfiles", "github.com")
+
+	commitProject, sha, err := parseCommitReference(project, "https://github.com/github/hub/commit/f0e1a2b3")
+	assert.Equal(t, nil, err)
+	assert.Equal(t, "github/hub", commitProject.String())
+	assert.Equal(t, "f0e1a2b3", sha)
+
+	commitProject, sha, err = parseCommitReference(project, "https://github.com/github/hub/pull/12/commits/f0e1a2b3")
+	assert.Equal(t, nil, err)
+	assert.Equal(t, "github/hub", commitProject.String())
+	assert.Equal(t, "f0e1a2b3", sha)
+
+	_, _, err = parseCommitReference(project, "https://github.com/github/hub/issues/12")
+	assert.Equal(t, "Aborted: 'https://github.com/github/hub/issues/12' is not a URL of a commit", err.Error())
+}
@@ -0,0 +1,260 @@
+package commands
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/github/hub/github"
+	"github.com/github/hub/ui"
+	"github.com/github/hub/utils"
+)
+
+var (
+	cmdVariable = &Command{
+		Run: variable,
+		Usage: `
+variable set [--env <ENV>|--org <ORG> [--visibility <VISIBILITY>]] <NAME> <VALUE>
+variable list [--env <ENV>|--org <ORG>] [-f <FORMAT>]
+variable remove [--env <ENV>|--org <ORG>] <NAME>
+`,
+		Long: `Manage GitHub Actions configuration variables of a repository, environment,
+or organization.
+
+## Commands:
+
+	* _set_:
+		Create the variable <NAME>, or update its value if it already exists.
+
+	* _list_:
+		List variables with their values and when they were last updated.
+
+	* _remove_:
+		Delete the variable <NAME>.
+
+## Options:
+
+	--env <ENV>
+		Manage variables of the deployment environment <ENV> of the current
+		repository.
+
+	--org <ORG>
+		Manage variables of the organization <ORG> instead of the current
+		repository.
+
+	--visibility <VISIBILITY>
+		Which repositories of the organization can use the variable: "private" or
+		"all". New variables are private by default, while updating a variable
+		keeps its visibility unless this option is given.
+
+	-f, --format <FORMAT>
+		Pretty print variables using <FORMAT>. The available placeholders are:
+
+		%N: name
+
+		%v: value
+
+		%S: visibility of an organization variable
+
+		%cD: created date-only (no time of day)
+
+		%cr: created date, relative
+
+		%ct: created date, UNIX timestamp
+
+		%cI: created date, ISO 8601 format
+
+		%uD: updated date-only (no time of day)
+
+		%ur: updated date, relative
+
+		%ut: updated date, UNIX timestamp
+
+		%uI: updated date, ISO 8601 format
+
+		%n: newline
+
+		%%: a literal %
+
+	--color[=<WHEN>]
+		Enable colored output even if stdout is not a terminal. <WHEN> can be one
+		of "always" (default for '--color'), "never", or "auto" (default).
+
+	<NAME>
+		The name of the variable.
+
+## Examples:
+		$ hub variable set --env production API_URL https://api.example.com
+
+		$ hub variable list -f '%N=%v%n'
+
+## See also:
+
+hub-secret(1), hub(1)
+`,
+	}
+
+	cmdSetVariable = &Command{
+		Key: "set",
+		Run: setVariable,
+		KnownFlags: `
+		--env ENV
+		--org ORG
+		--visibility VIS
+`,
+	}
+
+	cmdListVariables = &Command{
+		Key: "list",
+		Run: listVariables,
+		KnownFlags: `
+		--env ENV
+		--org ORG
+		-f, --format FMT
+		--color
+`,
+	}
+
+	cmdRemoveVariable = &Command{
+		Key: "remove",
+		Run: removeVariable,
+		KnownFlags: `
+		--env ENV
+		--org ORG
+`,
+	}
+)
+
+func init() {
+	cmdVariable.Use(cmdSetVariable)
+	cmdVariable.Use(cmdListVariables)
+	cmdVariable.Use(cmdRemoveVariable)
+	CmdRunner.Use(cmdVariable)
+}
+
+func variable(cmd *Command, args *Args) {
+	utils.Check(cmd.UsageError(""))
+}
+
+func setVariable(cmd *Command, args *Args) {
+	if args.ParamsSize() != 2 {
+		utils.Check(cmd.UsageError(""))
+	}
+	name := args.GetParam(0)
+	value := args.GetParam(1)
+
+	visibility := args.Flag.Value("--visibility")
+	if visibility != "" && !args.Flag.HasReceived("--org") {
+		utils.Check(cmd.UsageError("the '--visibility' option requires '--org'"))
+	}
+
+	scope, host := actionsScope(cmd, args)
+
+	args.NoForward()
+	if args.Noop {
+		ui.Printf("Would set variable %s in %s\n", name, scope)
+		return
+	}
+
+	gh := github.NewClient(host)
+	err := gh.SetVariable(scope, name, value, visibility)
+	utils.Check(err)
+}
+
+func listVariables(cmd *Command, args *Args) {
+	if !args.IsParamsEmpty() {
+		utils.Check(cmd.UsageError(""))
+	}
+
+	var format *ui.Format
+	if args.Flag.HasReceived("--format") {
+		var err error
+		format, err = ui.CompileFormat(args.Flag.Value("--format"))
+		utils.Check(err)
+	}
+
+	scope, host := actionsScope(cmd, args)
+
+	args.NoForward()
+	if args.Noop {
+		ui.Printf("Would request the list of variables in %s\n", scope)
+		return
+	}
+
+	gh := github.NewClient(host)
+	variables, err := gh.FetchVariables(scope)
+	utils.Check(err)
+
+	if format != nil {
+		colorize := colorizeOutput(args.Flag.HasReceived("--color"), args.Flag.Value("--color"))
+		for _, variable := range variables {
+			ui.Print(formatVariable(variable, format, colorize))
+		}
+		return
+	}
+
+	nameWidth, valueWidth := 0, 0
+	for _, variable := range variables {
+		if len(variable.Name) > nameWidth {
+			nameWidth = len(variable.Name)
+		}
+		if len(variable.Value) > valueWidth {
+			valueWidth = len(variable.Value)
+		}
+	}
+
+	for _, variable := range variables {
+		ui.Printf("%-*s  %-*s  Updated %s\n", nameWidth, variable.Name, valueWidth, variable.Value, variable.UpdatedAt.Format("2006-01-02"))
+	}
+}
+
+func formatVariable(variable github.Variable, format *ui.Format, colorize bool) string {
+	var createdDate, createdAtISO8601, createdAtUnix, createdAtRelative,
+		updatedDate, updatedAtISO8601, updatedAtUnix, updatedAtRelative string
+	if !variable.CreatedAt.IsZero() {
+		createdDate = variable.CreatedAt.Format("02 Jan 2006")
+		createdAtISO8601 = variable.CreatedAt.Format(time.RFC3339)
+		createdAtUnix = fmt.Sprintf("%d", variable.CreatedAt.Unix())
+		createdAtRelative = utils.TimeAgo(variable.CreatedAt)
+	}
+	if !variable.UpdatedAt.IsZero() {
+		updatedDate = variable.UpdatedAt.Format("02 Jan 2006")
+		updatedAtISO8601 = variable.UpdatedAt.Format(time.RFC3339)
+		updatedAtUnix = fmt.Sprintf("%d", variable.UpdatedAt.Unix())
+		updatedAtRelative = utils.TimeAgo(variable.UpdatedAt)
+	}
+
+	placeholders := map[string]string{
+		"N":  variable.Name,
+		"v":  variable.Value,
+		"S":  variable.Visibility,
+		"cD": createdDate,
+		"cI": createdAtISO8601,
+		"ct": createdAtUnix,
+		"cr": createdAtRelative,
+		"uD": updatedDate,
+		"uI": updatedAtISO8601,
+		"ut": updatedAtUnix,
+		"ur": updatedAtRelative,
+	}
+
+	return format.Expand(placeholders, colorize)
+}
+
+func removeVariable(cmd *Command, args *Args) {
+	if args.ParamsSize() != 1 {
+		utils.Check(cmd.UsageError(""))
+	}
+	name := args.GetParam(0)
+
+	scope, host := actionsScope(cmd, args)
+
+	args.NoForward()
+	if args.Noop {
+		ui.Printf("Would remove variable %s from %s\n", name, scope)
+		return
+	}
+
+	gh := github.NewClient(host)
+	err := gh.DeleteVariable(scope, name)
+	utils.Check(err)
+}
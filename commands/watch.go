@@ -0,0 +1,133 @@
+package commands
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"os/signal"
+	"strings"
+	"time"
+
+	"github.com/github/hub/ui"
+	"github.com/github/hub/utils"
+	"golang.org/x/crypto/ssh/terminal"
+)
+
+const defaultWatchInterval = 30 * time.Second
+
+// watchRow is a single entry of a listing that is refreshed periodically. Rows
+// are matched between refreshes by their key, and the row is highlighted when
+// its fingerprint differs from the previous one.
+type watchRow struct {
+	key         string
+	fingerprint string
+	text        string
+}
+
+// watchInterval is how often to check again for a flag such as '--watch' that
+// takes an optional interval.
+func watchInterval(args *Args, flag string) time.Duration {
+	value := args.Flag.Value(flag)
+	if value == "" {
+		return defaultWatchInterval
+	}
+	interval, err := parseDurationValue(value)
+	if err != nil {
+		utils.Check(fmt.Errorf("invalid interval for '%s': %s", flag, value))
+	}
+	return interval
+}
+
+func terminalSupportsCursor() bool {
+	term := os.Getenv("TERM")
+	return ui.IsTerminal(os.Stdout) && term != "" && term != "dumb"
+}
+
+// watchListing prints the rows returned by fetch and keeps refreshing them
+// every interval until the user quits. In terminals that don't support cursor
+// addressing, each refresh gets printed below the previous one.
+func watchListing(interval time.Duration, colorize bool, fetch func() ([]watchRow, error)) error {
+	redraw := terminalSupportsCursor()
+	quit := make(chan bool, 1)
+
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, os.Interrupt)
+	defer signal.Stop(signals)
+	go func() {
+		<-signals
+		quit <- true
+	}()
+
+	newline := "\n"
+	quitHint := "Ctrl-C"
+	if redraw && ui.IsTerminal(os.Stdin) {
+		fd := int(os.Stdin.Fd())
+		if state, err := terminal.MakeRaw(fd); err == nil {
+			defer terminal.Restore(fd, state)
+			// raw mode disables output processing, so lines need an explicit
+			// carriage return
+			newline = "\r\n"
+			quitHint = "q"
+			go func() {
+				b := make([]byte, 1)
+				for {
+					n, err := os.Stdin.Read(b)
+					if err != nil {
+						return
+					}
+					// Ctrl-C and Ctrl-D don't raise signals in raw mode
+					if n > 0 && (b[0] == 'q' || b[0] == 3 || b[0] == 4) {
+						quit <- true
+						return
+					}
+				}
+			}()
+		}
+	}
+
+	var previous map[string]string
+	for {
+		rows, err := fetch()
+		if err != nil {
+			return err
+		}
+
+		out := &bytes.Buffer{}
+		if redraw {
+			out.WriteString("\033[H\033[2J")
+		} else if previous != nil {
+			out.WriteString(newline)
+		}
+		fmt.Fprintf(out, "Every %s, last refreshed at %s. Press %s to quit.%s%s",
+			interval, time.Now().Format("15:04:05"), quitHint, newline, newline)
+
+		current := map[string]string{}
+		for _, row := range rows {
+			current[row.key] = row.fingerprint
+			marker := " "
+			if previous != nil {
+				if fingerprint, found := previous[row.key]; !found {
+					marker = watchMarker("+", 32, colorize)
+				} else if fingerprint != row.fingerprint {
+					marker = watchMarker("~", 33, colorize)
+				}
+			}
+			out.WriteString(marker + " " + strings.Replace(row.text, "\n", newline, -1))
+		}
+		ui.Print(out.String())
+		previous = current
+
+		select {
+		case <-quit:
+			return nil
+		case <-time.After(interval):
+		}
+	}
+}
+
+func watchMarker(marker string, color int, colorize bool) string {
+	if !colorize {
+		return marker
+	}
+	return fmt.Sprintf("\033[%dm%s\033[m", color, marker)
+}
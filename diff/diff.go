@@ -0,0 +1,454 @@
+// Package diff parses the unified diffs that git-diff(1) produces, such as the
+// diffs of pull requests, so that they can be narrowed down to some paths or
+// to changes other than whitespace, and summarized like 'git diff --stat'.
+package diff
+
+import (
+	"fmt"
+	"path"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+var hunkHeaderRe = regexp.MustCompile(`^@@ -(\d+)(?:,(\d+))? \+(\d+)(?:,(\d+))? @@`)
+
+// File is the section of a diff about one file.
+type File struct {
+	// OldPath is empty for an added file.
+	OldPath string
+	// NewPath is empty for a deleted file.
+	NewPath string
+	// Header holds the lines from "diff --git" up to the first hunk, such as
+	// the "index", "rename from" or "---" and "+++" lines.
+	Header []string
+	Hunks  []Hunk
+	Binary bool
+}
+
+// Hunk is a "@@" section of the diff of a file.
+type Hunk struct {
+	OldStart, OldLines int
+	NewStart, NewLines int
+	// Header is the "@@ -1,2 +1,3 @@" line as is, including the text after it.
+	Header string
+	// Lines are prefixed with " ", "-" or "+", or are "\ No newline at end of
+	// file" markers.
+	Lines []string
+}
+
+// Parse splits a diff into its files.
+func Parse(text string) ([]File, error) {
+	lines := splitLines(text)
+	files := []File{}
+	var file *File
+	for i := 0; i < len(lines); i++ {
+		line := lines[i]
+		if strings.HasPrefix(line, "diff --git ") {
+			files = append(files, newFile(line))
+			file = &files[len(files)-1]
+			continue
+		} else if file == nil {
+			return nil, fmt.Errorf("invalid diff: expected \"diff --git\", got %q", line)
+		}
+
+		if hunk, next, ok := parseHunk(lines, i); ok {
+			i = next - 1
+			file.Hunks = append(file.Hunks, hunk)
+		} else if len(file.Hunks) > 0 {
+			return nil, fmt.Errorf("invalid diff: unexpected line after the hunks of %s: %q", file.Path(), line)
+		} else {
+			file.Header = append(file.Header, line)
+			file.readHeader(line)
+		}
+	}
+
+	return files, nil
+}
+
+// ParseHunks parses the hunks of a single file that come without a "diff
+// --git" header, such as the patch the API returns for a changed file.
+func ParseHunks(patch string) ([]Hunk, error) {
+	lines := splitLines(patch)
+	hunks := []Hunk{}
+	for i := 0; i < len(lines); i++ {
+		hunk, next, ok := parseHunk(lines, i)
+		if !ok {
+			return nil, fmt.Errorf("invalid diff: expected a hunk header, got %q", lines[i])
+		}
+		i = next - 1
+		hunks = append(hunks, hunk)
+	}
+	return hunks, nil
+}
+
+func splitLines(text string) []string {
+	lines := strings.Split(text, "\n")
+	if len(lines) > 0 && lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	return lines
+}
+
+// parseHunk reads the hunk whose header is at index i. It returns the index of
+// the first line after the hunk, and false if there's no hunk header at i.
+func parseHunk(lines []string, i int) (Hunk, int, bool) {
+	m := hunkHeaderRe.FindStringSubmatch(lines[i])
+	if m == nil {
+		return Hunk{}, i, false
+	}
+	hunk := Hunk{Header: lines[i]}
+	hunk.OldStart, hunk.OldLines = hunkRange(m[1], m[2])
+	hunk.NewStart, hunk.NewLines = hunkRange(m[3], m[4])
+	return hunk, hunk.readLines(lines, i+1), true
+}
+
+func hunkRange(start, count string) (int, int) {
+	s, _ := strconv.Atoi(start)
+	if count == "" {
+		return s, 1
+	}
+	c, _ := strconv.Atoi(count)
+	return s, c
+}
+
+// readLines consumes the lines of the hunk starting at index i until the
+// counts of its header are used up, so that a removed line reading "--- a" or
+// an added one reading "+++ b" isn't taken for a header. It returns the index
+// of the first line after the hunk.
+func (h *Hunk) readLines(lines []string, i int) int {
+	oldLeft, newLeft := h.OldLines, h.NewLines
+	for ; i < len(lines); i++ {
+		line := lines[i]
+		if strings.HasPrefix(line, "\\") {
+			// "\ No newline at end of file" belongs to the line before it
+			h.Lines = append(h.Lines, line)
+			continue
+		}
+		if oldLeft <= 0 && newLeft <= 0 {
+			break
+		}
+		switch {
+		case strings.HasPrefix(line, "-"):
+			oldLeft--
+		case strings.HasPrefix(line, "+"):
+			newLeft--
+		case strings.HasPrefix(line, " "), line == "":
+			// context lines whose trailing space was stripped are empty
+			oldLeft--
+			newLeft--
+		default:
+			return i
+		}
+		h.Lines = append(h.Lines, line)
+	}
+	return i
+}
+
+// LineNumbers returns the numbers of the lines of the old and the new version
+// of the file that the hunk covers, context lines included.
+func (h Hunk) LineNumbers() (oldNumbers, newNumbers []int) {
+	oldLine, newLine := h.OldStart, h.NewStart
+	for _, line := range h.Lines {
+		switch {
+		case strings.HasPrefix(line, "\\"):
+			// "\ No newline at end of file"
+		case strings.HasPrefix(line, "-"):
+			oldNumbers = append(oldNumbers, oldLine)
+			oldLine++
+		case strings.HasPrefix(line, "+"):
+			newNumbers = append(newNumbers, newLine)
+			newLine++
+		default:
+			oldNumbers = append(oldNumbers, oldLine)
+			newNumbers = append(newNumbers, newLine)
+			oldLine++
+			newLine++
+		}
+	}
+	return
+}
+
+// newFile takes the paths from a "diff --git a/OLD b/NEW" line, which is only
+// unambiguous when they are quoted or the same. The header lines that follow
+// correct them otherwise.
+func newFile(line string) File {
+	rest := strings.TrimPrefix(line, "diff --git ")
+	var oldPath, newPath string
+	if strings.HasPrefix(rest, `"`) {
+		if end := closingQuote(rest); end > 0 {
+			oldPath = unquotePath(rest[:end+1])
+			newPath = unquotePath(strings.TrimSpace(rest[end+1:]))
+		}
+	} else if n := len(rest) / 2; len(rest)%2 == 1 && rest[n] == ' ' {
+		oldPath, newPath = rest[:n], rest[n+1:]
+	} else if i := strings.Index(rest, " b/"); i > 0 {
+		oldPath, newPath = rest[:i], rest[i+1:]
+	}
+	return File{
+		OldPath: strings.TrimPrefix(oldPath, "a/"),
+		NewPath: strings.TrimPrefix(newPath, "b/"),
+		Header:  []string{line},
+	}
+}
+
+func (f *File) readHeader(line string) {
+	switch {
+	case strings.HasPrefix(line, "new file mode "):
+		f.OldPath = ""
+	case strings.HasPrefix(line, "deleted file mode "):
+		f.NewPath = ""
+	case strings.HasPrefix(line, "rename from "), strings.HasPrefix(line, "copy from "):
+		f.OldPath = unquotePath(line[strings.Index(line, " from ")+6:])
+	case strings.HasPrefix(line, "rename to "), strings.HasPrefix(line, "copy to "):
+		f.NewPath = unquotePath(line[strings.Index(line, " to ")+4:])
+	case strings.HasPrefix(line, "--- "):
+		f.OldPath = headerPath(line[4:], "a/")
+	case strings.HasPrefix(line, "+++ "):
+		f.NewPath = headerPath(line[4:], "b/")
+	case strings.HasPrefix(line, "Binary files "), line == "GIT binary patch":
+		f.Binary = true
+	}
+}
+
+func headerPath(p, prefix string) string {
+	p = unquotePath(strings.TrimSuffix(p, "\t"))
+	if p == "/dev/null" {
+		return ""
+	}
+	return strings.TrimPrefix(p, prefix)
+}
+
+func closingQuote(s string) int {
+	for i := 1; i < len(s); i++ {
+		if s[i] == '\\' {
+			i++
+		} else if s[i] == '"' {
+			return i
+		}
+	}
+	return -1
+}
+
+// unquotePath undoes the C-style quoting that git applies to paths with
+// special characters, which Go string literals share.
+func unquotePath(p string) string {
+	if strings.HasPrefix(p, `"`) {
+		if unquoted, err := strconv.Unquote(p); err == nil {
+			return unquoted
+		}
+	}
+	return p
+}
+
+// Path is the path of the file after the change, or before it for a deleted
+// file.
+func (f File) Path() string {
+	if f.NewPath == "" {
+		return f.OldPath
+	}
+	return f.NewPath
+}
+
+// String formats the file as in the diff that it was parsed from.
+func (f File) String() string {
+	lines := append([]string{}, f.Header...)
+	for _, hunk := range f.Hunks {
+		lines = append(lines, hunk.Header)
+		lines = append(lines, hunk.Lines...)
+	}
+	return strings.Join(lines, "\n") + "\n"
+}
+
+// Format joins files into a diff.
+func Format(files []File) string {
+	out := ""
+	for _, file := range files {
+		out += file.String()
+	}
+	return out
+}
+
+// Stats counts the added and deleted lines of the file.
+func (f File) Stats() (added, deleted int) {
+	for _, hunk := range f.Hunks {
+		for _, line := range hunk.Lines {
+			if strings.HasPrefix(line, "+") {
+				added++
+			} else if strings.HasPrefix(line, "-") {
+				deleted++
+			}
+		}
+	}
+	return
+}
+
+// MatchesPath reports whether the old or the new path of the file is one of
+// paths, is within one of them as a directory, or matches one of them as a
+// shell glob.
+func (f File) MatchesPath(paths ...string) bool {
+	for _, p := range paths {
+		p = strings.TrimSuffix(path.Clean(strings.TrimPrefix(p, "./")), "/")
+		for _, name := range []string{f.OldPath, f.NewPath} {
+			if name == "" {
+				continue
+			}
+			if p == "." || name == p || strings.HasPrefix(name, p+"/") {
+				return true
+			}
+			if ok, _ := path.Match(p, name); ok {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// FilterPaths keeps the files that match one of paths.
+func FilterPaths(files []File, paths ...string) []File {
+	filtered := []File{}
+	for _, file := range files {
+		if file.MatchesPath(paths...) {
+			filtered = append(filtered, file)
+		}
+	}
+	return filtered
+}
+
+// WhitespaceOnly reports whether the removed and added lines of the hunk are
+// the same once all whitespace within them is ignored.
+func (h Hunk) WhitespaceOnly() bool {
+	removed, added := []string{}, []string{}
+	for _, line := range h.Lines {
+		if strings.HasPrefix(line, "-") {
+			removed = append(removed, withoutSpace(line[1:]))
+		} else if strings.HasPrefix(line, "+") {
+			added = append(added, withoutSpace(line[1:]))
+		}
+	}
+	return len(removed) == len(added) && strings.Join(removed, "\n") == strings.Join(added, "\n")
+}
+
+func withoutSpace(s string) string {
+	return strings.Map(func(r rune) rune {
+		if unicode.IsSpace(r) {
+			return -1
+		}
+		return r
+	}, s)
+}
+
+// IgnoreWhitespace drops the hunks that only change whitespace, and the files
+// that are left without any change. Files that were added, deleted, renamed,
+// copied, or whose mode changed are kept even without hunks, as are binary
+// files.
+func IgnoreWhitespace(files []File) []File {
+	filtered := []File{}
+	for _, file := range files {
+		hunks := []Hunk{}
+		for _, hunk := range file.Hunks {
+			if !hunk.WhitespaceOnly() {
+				hunks = append(hunks, hunk)
+			}
+		}
+		if len(hunks) == 0 && len(file.Hunks) > 0 && !file.changesMetadata() {
+			continue
+		}
+		file.Hunks = hunks
+		filtered = append(filtered, file)
+	}
+	return filtered
+}
+
+func (f File) changesMetadata() bool {
+	if f.Binary || f.OldPath == "" || f.NewPath == "" || f.OldPath != f.NewPath {
+		return true
+	}
+	for _, line := range f.Header {
+		if strings.HasPrefix(line, "old mode ") {
+			return true
+		}
+	}
+	return false
+}
+
+// statWidth is the width of the output of Stat, as for 'git diff --stat'.
+const statWidth = 80
+
+// Stat summarizes files like 'git diff --stat' does, with the number of
+// changed lines and a graph of additions and deletions for each file, and the
+// totals on the last line. Like git, it's empty without any files.
+func Stat(files []File) string {
+	if len(files) == 0 {
+		return ""
+	}
+	type stat struct {
+		name           string
+		added, deleted int
+		binary         bool
+	}
+	stats := []stat{}
+	nameWidth, maxChanges, totalAdded, totalDeleted := 0, 0, 0, 0
+	for _, file := range files {
+		s := stat{name: file.Path(), binary: file.Binary}
+		if file.OldPath != "" && file.NewPath != "" && file.OldPath != file.NewPath {
+			s.name = file.OldPath + " => " + file.NewPath
+		}
+		s.added, s.deleted = file.Stats()
+		stats = append(stats, s)
+		if n := utf8.RuneCountInString(s.name); n > nameWidth {
+			nameWidth = n
+		}
+		if s.added+s.deleted > maxChanges {
+			maxChanges = s.added + s.deleted
+		}
+		totalAdded += s.added
+		totalDeleted += s.deleted
+	}
+
+	numberWidth := len(strconv.Itoa(maxChanges))
+	for _, s := range stats {
+		if s.binary && numberWidth < len("Bin") {
+			numberWidth = len("Bin")
+		}
+	}
+	graphWidth := statWidth - nameWidth - numberWidth - 6
+	if graphWidth < 10 {
+		graphWidth = 10
+	}
+	scale := func(n int) int {
+		if maxChanges <= graphWidth || n == 0 {
+			return n
+		}
+		return 1 + n*(graphWidth-1)/maxChanges
+	}
+
+	out := ""
+	for _, s := range stats {
+		name := s.name + strings.Repeat(" ", nameWidth-utf8.RuneCountInString(s.name))
+		if s.binary {
+			out += fmt.Sprintf(" %s | %*s\n", name, numberWidth, "Bin")
+			continue
+		}
+		graph := strings.Repeat("+", scale(s.added)) + strings.Repeat("-", scale(s.deleted))
+		out += strings.TrimRight(fmt.Sprintf(" %s | %*d %s", name, numberWidth, s.added+s.deleted, graph), " ") + "\n"
+	}
+
+	summary := fmt.Sprintf(" %d %s changed", len(stats), plural(len(stats), "file", "files"))
+	if totalAdded > 0 || totalDeleted == 0 {
+		summary += fmt.Sprintf(", %d %s(+)", totalAdded, plural(totalAdded, "insertion", "insertions"))
+	}
+	if totalDeleted > 0 || totalAdded == 0 {
+		summary += fmt.Sprintf(", %d %s(-)", totalDeleted, plural(totalDeleted, "deletion", "deletions"))
+	}
+	return out + summary + "\n"
+}
+
+func plural(n int, one, many string) string {
+	if n == 1 {
+		return one
+	}
+	return many
+}
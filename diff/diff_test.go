@@ -0,0 +1,247 @@
+package diff
+
+import (
+	"testing"
+
+	"github.com/bmizerany/assert"
+)
+
+const trickyDiff = `diff --git a/main.go b/main.go
+index 83db48f..bf269f4 100644
+--- a/main.go
++++ b/main.go
+@@ -1,4 +1,5 @@ package main
+ import (
+-	"os"
++	"fmt"
++	"strings"
+ )
+
+@@ -20 +21 @@ func main() {
+-	os.Exit(1)
++	fmt.Println("hello")
+\ No newline at end of file
+diff --git a/old name.txt b/new name.txt
+similarity index 90%
+rename from old name.txt
+rename to new name.txt
+index 1111111..2222222 100644
+--- a/old name.txt
++++ b/new name.txt
+@@ -1 +1 @@
+-hello
++hello, world
+diff --git a/logo.png b/logo.png
+new file mode 100644
+index 0000000..3333333
+Binary files /dev/null and b/logo.png differ
+diff --git a/script/build b/script/build
+old mode 100644
+new mode 100755
+diff --git a/docs/notes.md b/docs/notes.md
+deleted file mode 100644
+index 4444444..0000000
+--- a/docs/notes.md
++++ /dev/null
+@@ -1,3 +0,0 @@
+--- not a header
+-+++ b/neither
+-notes
+diff --git "a/caf\303\251.txt" "b/caf\303\251.txt"
+index 5555555..6666666 100644
+--- "a/caf\303\251.txt"
++++ "b/caf\303\251.txt"
+@@ -1 +1,2 @@
+-latte
+\ No newline at end of file
++latte
++espresso
+`
+
+func TestParse(t *testing.T) {
+	files, err := Parse(trickyDiff)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, 6, len(files))
+
+	main := files[0]
+	assert.Equal(t, "main.go", main.OldPath)
+	assert.Equal(t, "main.go", main.NewPath)
+	assert.Equal(t, 4, len(main.Header))
+	assert.Equal(t, 2, len(main.Hunks))
+	assert.Equal(t, 1, main.Hunks[0].OldStart)
+	assert.Equal(t, 4, main.Hunks[0].OldLines)
+	assert.Equal(t, 5, main.Hunks[0].NewLines)
+	assert.Equal(t, []string{`-	os.Exit(1)`, `+	fmt.Println("hello")`, `\ No newline at end of file`}, main.Hunks[1].Lines)
+
+	renamed := files[1]
+	assert.Equal(t, "old name.txt", renamed.OldPath)
+	assert.Equal(t, "new name.txt", renamed.NewPath)
+	assert.Equal(t, 1, renamed.Hunks[0].OldLines)
+
+	binary := files[2]
+	assert.Equal(t, "", binary.OldPath)
+	assert.Equal(t, "logo.png", binary.Path())
+	assert.T(t, binary.Binary)
+	assert.Equal(t, 0, len(binary.Hunks))
+
+	mode := files[3]
+	assert.Equal(t, "script/build", mode.Path())
+	assert.Equal(t, 3, len(mode.Header))
+	assert.Equal(t, 0, len(mode.Hunks))
+
+	deleted := files[4]
+	assert.Equal(t, "", deleted.NewPath)
+	assert.Equal(t, "docs/notes.md", deleted.Path())
+	assert.Equal(t, []string{"--- not a header", "-+++ b/neither", "-notes"}, deleted.Hunks[0].Lines)
+
+	quoted := files[5]
+	assert.Equal(t, "café.txt", quoted.OldPath)
+	assert.Equal(t, "café.txt", quoted.NewPath)
+	assert.Equal(t, 4, len(quoted.Hunks[0].Lines))
+
+	assert.Equal(t, trickyDiff, Format(files))
+}
+
+func TestParse_Invalid(t *testing.T) {
+	_, err := Parse("Binary files differ\n")
+	assert.Equal(t, `invalid diff: expected "diff --git", got "Binary files differ"`, err.Error())
+
+	_, err = Parse("diff --git a/a b/a\n@@ -1 +1 @@\n-a\n+b\nextra\n")
+	assert.Equal(t, `invalid diff: unexpected line after the hunks of a: "extra"`, err.Error())
+}
+
+func TestParse_Empty(t *testing.T) {
+	files, err := Parse("")
+	assert.Equal(t, nil, err)
+	assert.Equal(t, 0, len(files))
+	assert.Equal(t, "", Format(files))
+}
+
+func TestFilterPaths(t *testing.T) {
+	files, _ := Parse(trickyDiff)
+	paths := func(files []File) []string {
+		names := []string{}
+		for _, file := range files {
+			names = append(names, file.Path())
+		}
+		return names
+	}
+
+	assert.Equal(t, []string{"main.go"}, paths(FilterPaths(files, "main.go")))
+	assert.Equal(t, []string{"script/build", "docs/notes.md"}, paths(FilterPaths(files, "./script/", "docs")))
+	assert.Equal(t, []string{"new name.txt", "café.txt"}, paths(FilterPaths(files, "*.txt")))
+	assert.Equal(t, []string{"new name.txt"}, paths(FilterPaths(files, "old name.txt")))
+	assert.Equal(t, 0, len(FilterPaths(files, "scr")))
+	assert.Equal(t, 6, len(FilterPaths(files, ".")))
+}
+
+func TestIgnoreWhitespace(t *testing.T) {
+	files, err := Parse(`diff --git a/a.go b/a.go
+index 1111111..2222222 100644
+--- a/a.go
++++ b/a.go
+@@ -1,3 +1,3 @@
+ func a() {
+-return  1
++	return 1
+ }
+@@ -10,2 +10,2 @@
+-	x := 1
++	x := 2
+ 	y := 2
+diff --git a/b.go b/b.go
+index 3333333..4444444 100644
+--- a/b.go
++++ b/b.go
+@@ -1,2 +1,2 @@
+-if x {
++if x  {
+ }
+@@ -5 +5,2 @@
+ }
++
+diff --git a/c.go b/c.go
+index 5555555..6666666 100644
+--- a/c.go
++++ b/c.go
+@@ -1 +1 @@
+-package c
++package c
+diff --git a/d.go b/e.go
+similarity index 99%
+rename from d.go
+rename to e.go
+index 7777777..8888888 100644
+--- a/d.go
++++ b/e.go
+@@ -1 +1 @@
+-package d
++package d
+`)
+	assert.Equal(t, nil, err)
+
+	filtered := IgnoreWhitespace(files)
+	assert.Equal(t, 3, len(filtered))
+	assert.Equal(t, "a.go", filtered[0].Path())
+	assert.Equal(t, 1, len(filtered[0].Hunks))
+	assert.Equal(t, 10, filtered[0].Hunks[0].OldStart)
+	assert.Equal(t, "b.go", filtered[1].Path())
+	assert.Equal(t, 1, len(filtered[1].Hunks))
+	assert.Equal(t, "@@ -5 +5,2 @@", filtered[1].Hunks[0].Header)
+	assert.Equal(t, "e.go", filtered[2].Path())
+	assert.Equal(t, 0, len(filtered[2].Hunks))
+
+	assert.Equal(t, 2, len(files[0].Hunks))
+}
+
+func TestStat(t *testing.T) {
+	files, _ := Parse(trickyDiff)
+	assert.Equal(t, ` main.go                      |   5 +++--
+ old name.txt => new name.txt |   2 +-
+ logo.png                     | Bin
+ script/build                 |   0
+ docs/notes.md                |   3 ---
+ café.txt                     |   3 ++-
+ 6 files changed, 6 insertions(+), 7 deletions(-)
+`, Stat(files))
+
+	assert.Equal(t, "", Stat(nil))
+
+	files, _ = Parse("diff --git a/a b/a\n@@ -1,3 +0,0 @@\n-a\n-b\n-c\n")
+	assert.Equal(t, " a | 3 ---\n 1 file changed, 3 deletions(-)\n", Stat(files))
+}
+
+func TestStat_Scaled(t *testing.T) {
+	text := "diff --git a/big.txt b/big.txt\n@@ -0,0 +1,200 @@\n"
+	for i := 0; i < 200; i++ {
+		text += "+line\n"
+	}
+	text += "diff --git a/small.txt b/small.txt\n@@ -1 +1 @@\n-a\n+b\n"
+	files, err := Parse(text)
+	assert.Equal(t, nil, err)
+
+	stat := Stat(files)
+	graphWidth := statWidth - len("small.txt") - len("200") - 6
+	big := " big.txt   | 200 "
+	for i := 0; i < graphWidth; i++ {
+		big += "+"
+	}
+	assert.Equal(t, big+"\n small.txt |   2 +-\n 2 files changed, 201 insertions(+), 1 deletion(-)\n", stat)
+}
+
+func TestParseHunks(t *testing.T) {
+	hunks, err := ParseHunks("@@ -1,3 +1,3 @@\n--- a\n-+++ b\n+c\n \n@@ -10 +10,2 @@\n+new\n x\n\\ No newline at end of file")
+	assert.Equal(t, nil, err)
+	assert.Equal(t, 2, len(hunks))
+	assert.Equal(t, []string{"--- a", "-+++ b", "+c", " "}, hunks[0].Lines)
+
+	oldNumbers, newNumbers := hunks[0].LineNumbers()
+	assert.Equal(t, []int{1, 2, 3}, oldNumbers)
+	assert.Equal(t, []int{1, 2}, newNumbers)
+	oldNumbers, newNumbers = hunks[1].LineNumbers()
+	assert.Equal(t, []int{10}, oldNumbers)
+	assert.Equal(t, []int{10, 11}, newNumbers)
+
+	_, err = ParseHunks("Binary files differ")
+	assert.Equal(t, `invalid diff: expected a hunk header, got "Binary files differ"`, err.Error())
+}
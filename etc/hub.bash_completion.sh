@@ -16,10 +16,14 @@ if declare -F _git > /dev/null && ! declare -F __git_list_all_commands_without_h
   __git_list_all_commands() {
     cat <<-EOF
 alias
+auth
 pull-request
 pr
 issue
 release
+repo
+secret
+gist
 fork
 create
 delete
@@ -27,6 +31,16 @@ browse
 compare
 ci-status
 sync
+variable
+org
+team
+todo
+digest
+star
+unstar
+watch
+unwatch
+security
 EOF
     __git_list_all_commands_without_hub
   }
@@ -84,3 +84,33 @@ Feature: hub am
       """
     When I successfully run `hub am -q https://gist.github.com/8da7fb575debd88c54cf`
     Then the latest commit message should be "Create a README"
+
+  Scenario: Exclude a commit that isn't part of the pull request
+    Given the GitHub API server:
+      """
+      get('/repos/mislav/dotfiles/pulls/387') {
+        generate_patch "Create a README"
+      }
+      """
+    When I run `hub am --exclude 1234567 https://github.com/mislav/dotfiles/pull/387`
+    Then the exit status should be 1
+    And the stderr should contain exactly "Error: commit 1234567 is not part of pull request #387\n"
+
+  Scenario: Apply commits from pull request onto a new branch
+    Given the GitHub API server:
+      """
+      get('/repos/mislav/dotfiles/pulls/387') {
+        if request.env['HTTP_ACCEPT'] == 'application/vnd.github.v3.patch;charset=utf-8'
+          generate_patch "Create a README"
+        else
+          json :number => 387, :base => { :ref => "main" }
+        end
+      }
+      """
+    When I successfully run `hub --noop am -3 --onto readme https://github.com/mislav/dotfiles/pull/387`
+    Then the output should contain:
+      """
+      git fetch origin +refs/heads/main:refs/remotes/origin/main
+      git checkout -b readme --no-track refs/remotes/origin/main
+      git am -3 
+      """
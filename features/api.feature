@@ -123,6 +123,40 @@ Feature: hub api
       {"name":"Jet"}
       """
 
+  Scenario: Download response to a file
+    Given the GitHub API server:
+      """
+      get('/repos/octocat/hello/tarball/main') {
+        halt 401 unless request.env['HTTP_AUTHORIZATION'] == 'token OTOKEN'
+        redirect 'https://objects.example.com/octocat-hello-main.tgz'
+      }
+      get('/octocat-hello-main.tgz', :host_name => 'objects.example.com') {
+        halt 401 unless request.env['HTTP_AUTHORIZATION'].nil?
+        content_type 'application/x-gzip'
+        "tarball contents"
+      }
+      """
+    When I successfully run `hub api repos/octocat/hello/tarball/main -o main.tgz`
+    Then the output should contain exactly ""
+    And the file "main.tgz" should contain exactly:
+      """
+      tarball contents
+      """
+
+  Scenario: Flat output passes non-JSON responses through
+    Given the GitHub API server:
+      """
+      get('/repos/octocat/hello/readme') {
+        content_type 'application/vnd.github.raw'
+        "# Hello"
+      }
+      """
+    When I successfully run `hub api --flat repos/octocat/hello/readme`
+    Then the output should contain exactly:
+      """
+      # Hello
+      """
+
   Scenario: Request headers
     Given the GitHub API server:
       """
@@ -240,6 +274,60 @@ Feature: hub api
       {"name":"Jet","size":2}
       """
 
+  Scenario: Pass GraphQL variables as a JSON object
+    Given the GitHub API server:
+      """
+      post('/graphql') {
+        json(params[:variables])
+      }
+      """
+    When I run `hub api graphql -F query='query {}' -F variables=@- -F first=10` interactively
+    And I pass in:
+      """
+      {"first": 100, "filter": {"labels": ["bug"], "open": true}, "after": null}
+      """
+    Then the output should contain exactly:
+      """
+      {"after":null,"filter":{"labels":["bug"],"open":true},"first":10}
+      """
+    And the stderr should contain exactly "warning: the 'first' field overrides the variable of the same name in 'variables'\n"
+
+  Scenario: Saved GraphQL query with typed variables
+    Given a file named "home/.config/hub-queries/review-queue.graphql" with:
+      """
+      # Pull requests awaiting my review
+      query($login: String!) { user(login: $login) { login } }
+      """
+    Given the GitHub API server:
+      """
+      post('/graphql') {
+        halt 400 unless params[:query].include?('user(login: $login)')
+        json(params[:variables])
+      }
+      """
+    When I successfully run `hub api graphql --saved review-queue -F login=@me -F 'labels:=["bug", {"name": "docs"}]'`
+    Then the output should contain exactly:
+      """
+      {"labels":["bug",{"name":"docs"}],"login":"mislav"}
+      """
+
+  Scenario: List saved GraphQL queries
+    Given a file named "home/.config/hub-queries/review-queue.graphql" with:
+      """
+      # Pull requests awaiting my review
+      query { viewer { login } }
+      """
+    Given a file named "home/.config/hub-queries/me.graphql" with:
+      """
+      query { viewer { login } }
+      """
+    When I successfully run `hub api --list-saved`
+    Then the output should contain exactly:
+      """
+      me
+      review-queue  Pull requests awaiting my review\n
+      """
+
   Scenario: Repo context
     Given I am in "git://github.com/octocat/Hello-World.git" git repo
     Given the GitHub API server:
@@ -348,6 +436,26 @@ Feature: hub api
       .count	2\n
       """
 
+  Scenario: Retry after a server error
+    Given the GitHub API server:
+      """
+      count = 0
+      get('/count') {
+        count += 1
+        if count == 1
+          response['Retry-After'] = '0'
+          halt 502
+        end
+        json :count => count
+      }
+      """
+    And $HUB_RETRIES is "2"
+    When I successfully run `hub api -t count`
+    Then the output should contain exactly:
+      """
+      .count	2\n
+      """
+
   Scenario: Avoid caching response if the OAuth token changes
     Given the GitHub API server:
       """
@@ -365,3 +473,100 @@ Feature: hub api
       .count	1
       .count	2\n
       """
+
+  Scenario: Paginate REST
+    Given the GitHub API server:
+      """
+      get('/comments') {
+        assert :per_page => "6"
+        page = (params[:page] || 1).to_i
+        response.headers["Link"] = %(<#{request.url}&page=#{page+1}>; rel="next") if page < 3
+        json [{:page => page}]
+      }
+      """
+    When I successfully run `hub api --paginate -XGET -F per_page=6 comments`
+    Then the output should contain exactly:
+      """
+      [{"page":1}]
+      [{"page":2}]
+      [{"page":3}]
+      """
+
+  Scenario: Paginate GraphQL
+    Given the GitHub API server:
+      """
+      post('/graphql') {
+        variables = params[:variables] || {}
+        page = (variables["endCursor"] || 1).to_i
+        json :data => {
+          :pageInfo => {
+            :hasNextPage => page < 3,
+            :endCursor => (page+1).to_s
+          }
+        }
+      }
+      """
+    When I successfully run `hub api --paginate graphql -f query=QUERY`
+    Then the output should contain exactly:
+      """
+      {"data":{"pageInfo":{"hasNextPage":true,"endCursor":"2"}}}
+      {"data":{"pageInfo":{"hasNextPage":true,"endCursor":"3"}}}
+      {"data":{"pageInfo":{"hasNextPage":false,"endCursor":"4"}}}
+      """
+
+  Scenario: Pause when a secondary rate limit is hit
+    Given the GitHub API server:
+      """
+      count = 0
+      get('/count') {
+        count += 1
+        if count == 1
+          response.headers["Retry-After"] = "1"
+          halt 403, json(:message => "You have exceeded a secondary rate limit.")
+        end
+        json :count => count
+      }
+      """
+    When I successfully run `hub api count`
+    Then the stdout should contain exactly:
+      """
+      {"count":2}
+      """
+    And the stderr should contain "API rate limit exceeded; pausing until "
+
+  Scenario: Don't retry when the rate limit reset is already past
+    Given the GitHub API server:
+      """
+      count = 0
+      get('/count') {
+        count += 1
+        halt 500 if count > 1
+        response.headers["X-RateLimit-Remaining"] = "0"
+        response.headers["X-RateLimit-Reset"] = (Time.now.to_i - 60).to_s
+        halt 403, json(:message => "API rate limit exceeded")
+      }
+      """
+    When I run `hub api count`
+    Then the exit status should be 22
+    And the stdout should contain exactly:
+      """
+      {"message":"API rate limit exceeded"}
+      """
+    And the stderr should contain exactly ""
+
+  Scenario: Fail when the rate limit is hit if asked to
+    Given the GitHub API server:
+      """
+      get('/count') {
+        response.headers["X-RateLimit-Remaining"] = "0"
+        response.headers["X-RateLimit-Reset"] = (Time.now.to_i + 3600).to_s
+        halt 403, json(:message => "API rate limit exceeded")
+      }
+      """
+    When I run `hub api --obey-rate-limit=false count`
+    Then the exit status should be 22
+    And the stdout should contain exactly:
+      """
+      {"message":"API rate limit exceeded"}
+      """
+    And the stderr should contain exactly ""
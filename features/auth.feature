@@ -0,0 +1,61 @@
+Feature: hub auth
+  Background:
+    Given I am "mislav" on github.com with OAuth token "OTOKEN"
+
+  Scenario: Show a token that doesn't expire
+    Given the GitHub API server:
+      """
+      get('/user') {
+        json :login => "mislav"
+      }
+      """
+    When I successfully run `hub auth status`
+    Then the output should contain exactly:
+      """
+      Host: github.com
+      User: mislav
+      Token expiration: never\n
+      """
+
+  Scenario: Warn about a token that expires soon
+    Given the GitHub API server:
+      """
+      get('/user') {
+        response.headers['GitHub-Authentication-Token-Expiration'] = '2023-03-10 16:22:17 UTC'
+        json :login => "mislav"
+      }
+      """
+    When I successfully run `hub auth status`
+    Then the output should contain exactly:
+      """
+      Host: github.com
+      User: mislav
+      Token expiration: 2023-03-10 16:22:17 UTC\n
+      """
+    And the stderr should contain exactly:
+      """
+      warning: the access token for github.com expires on 2023-03-10\n
+      """
+    When I successfully run `hub auth status`
+    Then the stderr should contain exactly ""
+
+  Scenario: Expired token
+    Given I am in "git://github.com/mislav/dotfiles.git" git repo
+    And the GitHub API server:
+      """
+      get('/user') {
+        response.headers['GitHub-Authentication-Token-Expiration'] = '2023-03-10 16:22:17 UTC'
+        json :login => "mislav"
+      }
+      get('/repos/mislav/dotfiles/issues') {
+        status 401
+        json :message => "Bad credentials"
+      }
+      """
+    When I successfully run `hub auth status`
+    And I run `hub issue`
+    Then the exit status should be 4
+    And the stderr should contain exactly:
+      """
+      Error fetching issues: token expired on 2023-03-10 (HTTP 401)\n
+      """
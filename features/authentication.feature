@@ -278,7 +278,7 @@ Feature: OAuth authentication
       Bad credentials
 
       """
-    And the exit status should be 1
+    And the exit status should be 4
     And the file "../home/.config/hub" should not exist
 
   Scenario: Two-factor authentication, create authorization
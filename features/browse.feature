@@ -1,11 +1,46 @@
 Feature: hub browse
   Background:
     Given I am "mislav" on github.com with OAuth token "OTOKEN"
+    And the GitHub API server:
+      """
+      get('/repos/mislav/dotfiles') {
+        json :name => "dotfiles", :full_name => "mislav/dotfiles"
+      }
+      get('/user/orgs') {
+        json [{ :login => "acme" }, { :login => "umbrella" }]
+      }
+      get('/repos/acme/tools') {
+        json :name => "tools", :full_name => "acme/tools"
+      }
+      get('/repos/acme/widgets') {
+        json :name => "widgets", :full_name => "acme/widgets"
+      }
+      get('/repos/umbrella/widgets') {
+        json :name => "widgets", :full_name => "umbrella/widgets"
+      }
+      get('/search/repositories') {
+        case params[:q]
+        when "gadgets in:name"
+          json :items => [{ :name => "Gadgets", :full_name => "inspector/Gadgets" }]
+        when "gizmo in:name"
+          json :items => [
+            { :name => "gizmo-cli", :full_name => "octo/gizmo-cli", :description => "Gizmo on the command line" },
+          ]
+        else
+          json :items => []
+        end
+      }
+      """
 
   Scenario: No repo
     When I run `hub browse`
-    Then the exit status should be 1
-    Then the output should contain exactly "Usage: hub browse [-uc] [[<USER>/]<REPOSITORY>|--] [<SUBPAGE>]\n"
+    Then the exit status should be 5
+    Then the output should contain exactly:
+      """
+      Usage: hub browse [-uc] [[<USER>/]<REPOSITORY>|--] [<SUBPAGE>]
+             hub browse [-uc] --settings[=<PAGE>] [[<USER>/]<REPOSITORY>]
+             hub browse [-uc] --org <ORG> [--settings[=<PAGE>]]\n
+      """
 
   Scenario: Project with owner
     When I successfully run `hub browse mislav/dotfiles`
@@ -13,10 +48,38 @@ Feature: hub browse
     And "open https://github.com/mislav/dotfiles" should be run
 
   Scenario: Project without owner
-    Given I am "mislav" on github.com
     When I successfully run `hub browse dotfiles`
     Then "open https://github.com/mislav/dotfiles" should be run
 
+  Scenario: Project of an organization
+    When I successfully run `hub browse -u tools`
+    Then the output should contain exactly "https://github.com/acme/tools\n"
+
+  Scenario: Project found by searching
+    When I successfully run `hub browse -u inspector/gadgets issues`
+    Then the output should contain exactly "https://github.com/inspector/Gadgets/issues\n"
+
+  Scenario: Ambiguous project outside of a terminal
+    When I run `hub browse widgets`
+    Then the exit status should be 1
+    And the stderr should contain exactly:
+      """
+      Aborted: 'widgets' is ambiguous. Matching repositories:
+        acme/widgets
+        umbrella/widgets\n
+      """
+    And "open https://github.com/acme/widgets" should not be run
+
+  Scenario: Fuzzy search match outside of a terminal
+    When I run `hub browse gizmo`
+    Then the exit status should be 1
+    And the stderr should contain "  octo/gizmo-cli"
+
+  Scenario: Project not found
+    When I run `hub browse nonexistent`
+    Then the exit status should be 3
+    And the stderr should contain exactly "Error: no repository found for 'nonexistent'\n"
+
   Scenario: Explicit project overrides current
     Given I am in "git://github.com/josh/rails-behaviors.git" git repo
     And I am "mislav" on github.com
@@ -217,3 +280,114 @@ Feature: hub browse
     Given I am in "git@github.com:suan/git-sanity.git" git repo
     When I successfully run `hub browse`
     Then "open https://github.com/suan/git-sanity" should be run
+
+  Scenario: File at a line
+    Given I am in "git://github.com/mislav/dotfiles.git" git repo
+    And a file named "lib/main.go" with:
+      """
+      package main
+      """
+    And I cd to "lib"
+    When I successfully run `hub browse -u main.go:4`
+    Then the output should contain exactly "https://github.com/mislav/dotfiles/blob/master/lib/main.go#L4\n"
+
+  Scenario: File at a range of lines on the upstream branch
+    Given I am in "git://github.com/mislav/dotfiles.git" git repo
+    And the "mislav" remote has url "git@github.com:mislav/dotfiles.git"
+    And I am on the "feature" branch with upstream "mislav/experimental"
+    And a file named "README.md" with:
+      """
+      # dotfiles
+      """
+    When I successfully run `hub browse README.md:4-10`
+    Then "open https://github.com/mislav/dotfiles/blob/experimental/README.md#L4-L10" should be run
+
+  Scenario: Directory
+    Given I am in "git://github.com/mislav/dotfiles.git" git repo
+    And a directory named "lib/vim"
+    When I successfully run `hub browse lib/vim`
+    Then "open https://github.com/mislav/dotfiles/tree/master/lib/vim" should be run
+
+  Scenario: Name of a directory in the working tree
+    Given I am in "git://github.com/mislav/dotfiles.git" git repo
+    And I am "mislav" on github.com
+    And a directory named "vim"
+    When I successfully run `hub browse vim`
+    Then "open https://github.com/mislav/vim" should be run
+
+  Scenario: Directory in the current directory
+    Given I am in "git://github.com/mislav/dotfiles.git" git repo
+    And a directory named "vim"
+    When I successfully run `hub browse ./vim`
+    Then "open https://github.com/mislav/dotfiles/tree/master/vim" should be run
+
+  Scenario: Pull request
+    Given I am in "git://github.com/mislav/dotfiles.git" git repo
+    When I successfully run `hub browse '#12'`
+    Then "open https://github.com/mislav/dotfiles/pull/12" should be run
+
+  Scenario: Pull request given as PR<NUMBER>
+    Given I am in "git://github.com/mislav/dotfiles.git" git repo
+    When I successfully run `hub browse pr12`
+    Then "open https://github.com/mislav/dotfiles/pull/12" should be run
+
+  Scenario: Commit
+    Given I am in "git://github.com/mislav/dotfiles.git" git repo
+    And $GIT_AUTHOR_DATE is "2020-01-01T00:00:00Z"
+    And $GIT_COMMITTER_DATE is "2020-01-01T00:00:00Z"
+    And I make a commit with message "Initial commit"
+    When I successfully run `hub browse 082de75`
+    Then "open https://github.com/mislav/dotfiles/commit/082de755679dd838ed317ab88bffc09e73d78049" should be run
+
+  Scenario: Name that's neither a file nor a commit
+    Given I am in "git://github.com/josh/rails-behaviors.git" git repo
+    And I am "mislav" on github.com
+    When I successfully run `hub browse deadbeef`
+    Then "open https://github.com/mislav/deadbeef" should be run
+
+  Scenario: Repository settings
+    Given I am in "git://github.com/mislav/dotfiles.git" git repo
+    When I successfully run `hub browse --settings`
+    Then "open https://github.com/mislav/dotfiles/settings" should be run
+
+  Scenario: Repository settings page
+    Given I am in "git://github.com/mislav/dotfiles.git" git repo
+    When I successfully run `hub browse -u --settings=webhooks`
+    Then the output should contain exactly "https://github.com/mislav/dotfiles/settings/hooks\n"
+
+  Scenario: Settings page of another repository
+    When I successfully run `hub browse -u --settings=collaborators mislav/dotfiles`
+    Then the output should contain exactly "https://github.com/mislav/dotfiles/settings/access\n"
+
+  Scenario: Unknown settings page
+    Given I am in "git://github.com/mislav/dotfiles.git" git repo
+    When I run `hub browse --settings=robots`
+    Then the exit status should be 1
+    And the stderr should contain exactly "Error: unknown settings page 'robots' (supported: branches, collaborators, pages, secrets, webhooks)\n"
+
+  Scenario: Settings without admin rights according to a cached response
+    Given I am in "git://github.com/mislav/dotfiles.git" git repo
+    And the GitHub API server:
+      """
+      get('/repos/mislav/dotfiles') {
+        json :name => "dotfiles", :full_name => "mislav/dotfiles",
+          :permissions => { :admin => false, :push => true, :pull => true }
+      }
+      """
+    When I successfully run `hub api --cache 60 repos/mislav/dotfiles`
+    And I successfully run `hub browse -u --settings=branches`
+    Then the output should contain "https://github.com/mislav/dotfiles/settings/branches\n"
+    And the stderr should contain exactly "Note: you don't have admin rights to mislav/dotfiles, so GitHub may deny access to its settings\n"
+
+  Scenario: Organization
+    When I successfully run `hub browse --org acme`
+    Then "open https://github.com/acme" should be run
+
+  Scenario: Organization settings page
+    When I successfully run `hub browse -u --org acme --settings=member-privileges`
+    Then the output should contain exactly "https://github.com/organizations/acme/settings/member_privileges\n"
+
+  Scenario: Unknown organization settings page
+    When I run `hub browse --org acme --settings=pages`
+    Then the exit status should be 1
+    And the stderr should contain exactly "Error: unknown settings page 'pages' (supported: billing, member-privileges, secrets, webhooks)\n"
@@ -59,25 +59,108 @@ Feature: hub checkout <PULLREQ-URL>
     And "git checkout mislav-master" should be run
     And "mislav-master" should merge "refs/pull/77/head" from remote "origin"
 
-  Scenario: No matching remotes for pull request base
+  Scenario: Pull request from another fork network
     Given the GitHub API server:
       """
       get('/repos/mislav/jekyll/pulls/77') {
-        json :number => 77, :base => {
+        json :number => 77, :head => {
+          :ref => "fixes",
+          :repo => {
+            :owner => { :login => "hubot" },
+            :name => "jekyll",
+            :html_url => "https://github.com/hubot/jekyll",
+            :private => false
+          }
+        }, :base => {
           :repo => {
             :name => 'jekyll',
             :html_url => 'https://github.com/mislav/jekyll',
             :owner => { :login => "mislav" },
           }
-        }
+        }, :maintainer_can_modify => true
+      }
+      """
+    When I successfully run `hub checkout -f https://github.com/mislav/jekyll/pull/77 -q`
+    Then "git remote add hubot git@github.com:hubot/jekyll.git" should be run
+    And "git fetch hubot +refs/heads/fixes:refs/remotes/hubot/fixes" should be run
+    And "git checkout -f -b fixes --no-track hubot/fixes -q" should be run
+    And "fixes" should merge "refs/heads/fixes" from remote "hubot"
+
+  Scenario: Pull request from a deleted fork in another fork network
+    Given the GitHub API server:
+      """
+      get('/repos/mislav/jekyll/pulls/77') {
+        json :number => 77, :head => {
+          :ref => "fixes",
+          :repo => nil
+        }, :base => {
+          :repo => {
+            :name => 'jekyll',
+            :html_url => 'https://github.com/mislav/jekyll',
+            :owner => { :login => "mislav" },
+            :private => false
+          }
+        }, :maintainer_can_modify => false
+      }
+      """
+    When I successfully run `hub checkout https://github.com/mislav/jekyll/pull/77 hubot-fixes`
+    Then "git fetch git://github.com/mislav/jekyll.git refs/pull/77/head:hubot-fixes" should be run
+    And "git checkout hubot-fixes" should be run
+    And "hubot-fixes" should merge "refs/pull/77/head" from remote "git://github.com/mislav/jekyll.git"
+
+  Scenario: Fast-forward an existing checkout of a pull request
+    Given the GitHub API server:
+      """
+      get('/repos/mojombo/jekyll/pulls/77') {
+        json :number => 77, :head => {
+          :ref => "fixes",
+          :repo => {
+            :owner => { :login => "mislav" },
+            :name => "jekyll",
+            :private => false
+          }
+        }, :base => {
+          :repo => {
+            :name => 'jekyll',
+            :html_url => 'https://github.com/mojombo/jekyll',
+            :owner => { :login => "mojombo" },
+          }
+        }, :maintainer_can_modify => false
+      }
+      """
+    And I am on the "fixes" branch
+    And git "branch.fixes.remote" is set to "origin"
+    And git "branch.fixes.merge" is set to "refs/pull/77/head"
+    When I successfully run `hub checkout https://github.com/mojombo/jekyll/pull/77`
+    Then "git fetch origin refs/pull/77/head" should be run
+    And "git checkout fixes" should be run
+    And "git merge --ff-only FETCH_HEAD" should be run
+
+  Scenario: Existing branch that doesn't track the pull request
+    Given the GitHub API server:
+      """
+      get('/repos/mojombo/jekyll/pulls/77') {
+        json :number => 77, :head => {
+          :ref => "master",
+          :repo => nil
+        }, :base => {
+          :repo => {
+            :name => 'jekyll',
+            :html_url => 'https://github.com/mojombo/jekyll',
+            :owner => { :login => "mojombo" },
+          }
+        }, :maintainer_can_modify => false
       }
       """
-    When I run `hub checkout -f https://github.com/mislav/jekyll/pull/77 -q`
+    And I am on the "master" branch
+    When I run `hub checkout https://github.com/mojombo/jekyll/pull/77`
     Then the exit status should be 1
     And the stderr should contain exactly:
       """
-      could not find a git remote for 'mislav/jekyll'\n
+      Error: branch 'master' already exists and doesn't track pull request #77
+      (give another branch name to check it out under)\n
       """
+    And "git fetch origin refs/pull/77/head" should not be run
 
   Scenario: Custom name for new branch
     Given the GitHub API server:
@@ -89,6 +89,175 @@ Feature: hub ci-status
       """
     And the exit status should be 1
 
+  Scenario: Check durations with format string
+    Given there is a commit named "the_sha"
+    Given the GitHub API server:
+      """
+      get('/repos/michiels/pencilbox/commits/:sha/status') {
+        json :state => "success",
+             :statuses => [{ :state => "success", :context => "GitHub CLA" }]
+      }
+      get('/repos/michiels/pencilbox/commits/:sha/check-runs') {
+        json :check_runs => [
+          { :name => "test", :status => "completed", :conclusion => "success",
+            :started_at => "2024-05-01T12:00:00Z", :completed_at => "2024-05-01T12:12:33Z" },
+        ]
+      }
+      """
+    When I successfully run `hub ci-status the_sha --format '%t [%pD]%n'`
+    Then the output should contain exactly:
+      """
+      GitHub CLA []
+      test [12m33s]\n
+      """
+
+  Scenario: Statuses as JSON
+    Given there is a commit named "the_sha"
+    Given the remote commit states of "michiels/pencilbox" "the_sha" are:
+      """
+      { :state => "failure",
+        :statuses => [
+          { :state => "failure",
+            :context => "GitHub CLA",
+            :target_url => "https://cla.github.com/michiels/pencilbox/accept/mislav" },
+        ]
+      }
+      """
+    When I run `hub ci-status --json --color=always the_sha`
+    Then the output should contain exactly:
+      """
+      {
+        "state": "failure",
+        "exit_code": 1,
+        "statuses": [
+          {
+            "state": "failure",
+            "context": "GitHub CLA",
+            "target_url": "https://cla.github.com/michiels/pencilbox/accept/mislav"
+          }
+        ]
+      }\n
+      """
+    And the exit status should be 1
+
+  Scenario: No statuses as JSON
+    Given there is a commit named "the_sha"
+    Given the remote commit states of "michiels/pencilbox" "the_sha" are:
+      """
+      { :state => "pending",
+        :statuses => []
+      }
+      """
+    When I run `hub ci-status --json the_sha`
+    Then the output should contain exactly:
+      """
+      {
+        "state": "",
+        "exit_code": 3,
+        "statuses": []
+      }\n
+      """
+    And the exit status should be 3
+
+  Scenario: JSON and format are mutually exclusive
+    When I run `hub ci-status --json --format=%t`
+    Then the exit status should be 5
+    And the stderr should contain "the '--json' and '--format' options are mutually exclusive"
+
+  Scenario: Filter statuses by context
+    Given there is a commit named "the_sha"
+    Given the remote commit states of "michiels/pencilbox" "the_sha" are:
+      """
+      { :state => "failure",
+        :statuses => [
+          { :state => "success",
+            :context => "continuous-integration/travis-ci/push" },
+          { :state => "pending",
+            :context => "continuous-integration/travis-ci/merge" },
+          { :state => "failure",
+            :context => "GitHub CLA" },
+        ]
+      }
+      """
+    When I run `hub ci-status -v --context 'Continuous-Integration/*' --exclude-context '/merge$/' the_sha`
+    Then the output should contain exactly "✔︎	continuous-integration/travis-ci/push\n"
+    And the exit status should be 0
+
+  Scenario: No statuses match the context filter
+    Given there is a commit named "the_sha"
+    Given the remote commit state of "michiels/pencilbox" "the_sha" is "success"
+    When I run `hub ci-status -v --context deploy the_sha`
+    Then the output should contain exactly "no status\n"
+    And the exit status should be 3
+
+  Scenario: Statuses of a range of commits
+    Given there is a commit named "the_sha"
+    Given the remote commit state of "michiels/pencilbox" "the_sha" is "failure"
+    When I run `hub ci-status HEAD..the_sha`
+    Then the output should contain "✖︎\t"
+    And the output should contain "\tempty "
+    And the exit status should be 1
+
+  Scenario: Range of commits can't be waited for
+    When I run `hub ci-status --wait HEAD~1..HEAD`
+    Then the exit status should be 5
+    And the stderr should contain "the '--wait' option can't be used with a range of commits"
+
+  Scenario: Re-run failed checks
+    Given there is a commit named "the_sha"
+    Given the GitHub API server:
+      """
+      get('/repos/michiels/pencilbox/commits/:sha/status') {
+        json :state => "failure",
+             :statuses => [{ :state => "failure", :context => "GitHub CLA" }]
+      }
+      get('/repos/michiels/pencilbox/commits/:sha/check-runs') {
+        json :check_runs => [
+          { :id => 11, :name => "test", :status => "completed", :conclusion => "failure",
+            :app => { :slug => "github-actions" } },
+          { :id => 12, :name => "lint", :status => "completed", :conclusion => "success",
+            :app => { :slug => "github-actions" } },
+          { :id => 13, :name => "coverage", :status => "completed", :conclusion => "timed_out",
+            :app => { :slug => "codecov" } },
+        ]
+      }
+      post('/repos/michiels/pencilbox/actions/jobs/11/rerun') {
+        status 201
+        json({})
+      }
+      post('/repos/michiels/pencilbox/check-runs/13/rerequest') {
+        status 201
+        json({})
+      }
+      """
+    When I successfully run `hub ci-status --rerun-failed the_sha`
+    Then the output should contain exactly:
+      """
+      Re-running coverage
+      Re-running test\n
+      """
+    And the stderr should contain exactly "warning: can't re-run 'GitHub CLA', which is a commit status\n"
+
+  Scenario: List the requests to re-run failed checks
+    Given there is a commit named "the_sha"
+    Given the GitHub API server:
+      """
+      get('/repos/michiels/pencilbox/commits/:sha/status') {
+        json :state => "pending", :statuses => []
+      }
+      get('/repos/michiels/pencilbox/commits/:sha/check-runs') {
+        json :check_runs => [
+          { :id => 11, :name => "test", :status => "completed", :conclusion => "failure",
+            :app => { :slug => "github-actions" } },
+          { :id => 13, :name => "coverage", :status => "completed", :conclusion => "cancelled",
+            :app => { :slug => "codecov" } },
+        ]
+      }
+      """
+    When I successfully run `hub --noop ci-status --rerun-failed --exclude-context coverage the_sha`
+    Then the output should contain "Would request POST /repos/michiels/pencilbox/actions/jobs/11/rerun\n"
+    And the output should not contain "check-runs/13"
+
   Scenario: Exit status 1 for 'error' and 'failure'
     Given the remote commit state of "michiels/pencilbox" "HEAD" is "error"
     When I run `hub ci-status`
@@ -101,6 +270,12 @@ Feature: hub ci-status
     Then the exit status should be 2
     And the output should contain exactly "pending\n"
 
+  Scenario: Use HEAD when not on any branch
+    Given I am in detached HEAD
+    And the remote commit state of "michiels/pencilbox" "HEAD" is "success"
+    When I successfully run `hub ci-status`
+    Then the output should contain exactly "success\n"
+
   Scenario: Exit status 3 for no statuses available
     Given there is a commit named "the_sha"
     Given the remote commit state of "michiels/pencilbox" "the_sha" is nil
@@ -108,6 +283,32 @@ Feature: hub ci-status
     Then the output should contain exactly "no status\n"
     And the exit status should be 3
 
+  Scenario: Fall back to the remote branch of an unpushed commit
+    Given I am on the "feature" branch with upstream "origin/feature"
+    And the remote commit state of "michiels/pencilbox" "origin/feature" is "success"
+    And the remote commit state of "michiels/pencilbox" "HEAD" is nil
+    When I successfully run `hub ci-status`
+    Then the stdout should contain exactly "success\n"
+    And the stderr should contain "no status for local commit "
+    And the stderr should contain "; showing remote branch origin/feature ("
+
+  Scenario: No fallback to the remote branch with --no-fallback
+    Given I am on the "feature" branch with upstream "origin/feature"
+    And the remote commit state of "michiels/pencilbox" "origin/feature" is "success"
+    And the remote commit state of "michiels/pencilbox" "HEAD" is nil
+    When I run `hub ci-status --no-fallback`
+    Then the output should contain exactly "no status\n"
+    And the exit status should be 3
+
+  Scenario: No fallback to the remote branch for an explicit commit
+    Given I am on the "feature" branch with upstream "origin/feature"
+    And the remote commit state of "michiels/pencilbox" "origin/feature" is "success"
+    And there is a commit named "the_sha"
+    And the remote commit state of "michiels/pencilbox" "the_sha" is nil
+    When I run `hub ci-status the_sha`
+    Then the output should contain exactly "no status\n"
+    And the exit status should be 3
+
   Scenario: Exit status 3 for no statuses available without URL
     Given there is a commit named "the_sha"
     Given the remote commit state of "michiels/pencilbox" "the_sha" is nil
@@ -176,6 +377,45 @@ Feature: hub ci-status
     Then the output should contain exactly "action_required\n"
     And the exit status should be 1
 
+  Scenario: Check runs replace commit statuses of the same name
+    Given there is a commit named "the_sha"
+    And the GitHub API server:
+      """
+      get('/repos/michiels/pencilbox/commits/:sha/status') {
+        json({ :state => "pending",
+               :statuses => [
+                 { :state => "pending",
+                   :context => "build",
+                   :target_url => "https://ci.example.com/1" },
+                 { :state => "success",
+                   :context => "travis-ci",
+                   :target_url => "https://travis-ci.org/2" },
+               ]
+        })
+      }
+      get('/repos/michiels/pencilbox/commits/:sha/check-runs') {
+        assert :per_page => "100"
+        json({ :check_runs => [
+                 { :status => "completed",
+                   :conclusion => "success",
+                   :name => "build",
+                   :html_url => "https://github.com/runs/3" },
+                 { :status => "completed",
+                   :conclusion => "skipped",
+                   :name => "deploy",
+                   :html_url => "https://github.com/runs/4" },
+               ]
+        })
+      }
+      """
+    When I successfully run `hub ci-status -f "%S %t %U%n" the_sha`
+    Then the output should contain exactly:
+      """
+      success build https://github.com/runs/3
+      neutral deploy https://github.com/runs/4
+      success travis-ci https://travis-ci.org/2\n
+      """
+
   Scenario: Older Enterprise version doesn't have Checks
     Given the "origin" remote has url "git@git.my.org:michiels/pencilbox.git"
     And I am "michiels" on git.my.org with OAuth token "FITOKEN"
@@ -200,3 +440,226 @@ Feature: hub ci-status
       """
     When I successfully run `hub ci-status the_sha`
     Then the output should contain exactly "success\n"
+
+  Scenario: Batch status of multiple repositories
+    Given the GitHub API server:
+      """
+      get('/repos/github/hub') {
+        json :name => "hub", :default_branch => "master"
+      }
+      get('/repos/github/hub/commits/master/status') {
+        json :state => "success",
+             :statuses => [{ :state => "success", :context => "ci" }]
+      }
+      get('/repos/github/hub/commits/master/check-runs') {
+        status 404
+      }
+      get('/repos/github/docs/commits/wip/status') {
+        json :state => "failure",
+             :statuses => [
+               { :state => "failure", :context => "lint" },
+               { :state => "error", :context => "test" },
+               { :state => "success", :context => "build" },
+             ]
+      }
+      get('/repos/github/docs/commits/wip/check-runs') {
+        status 404
+      }
+      get('/repos/github/private') {
+        status 404
+        json :message => "Not Found"
+      }
+      """
+    Given a file named "repos.txt" with:
+      """
+      # platform
+      github/hub
+      github/docs@wip
+
+      github/private
+      """
+    When I run `hub ci-status --batch -F repos.txt`
+    Then the output should contain exactly:
+      """
+      github/hub      master  success  0 failing
+      github/docs     wip     error    2 failing
+      github/private          Error getting repository info: Not Found (HTTP 404) Not Found\n
+      """
+    And the exit status should be 1
+
+  Scenario: Batch status as JSON from standard input
+    Given the GitHub API server:
+      """
+      get('/repos/github/docs/commits/wip/status') {
+        json :state => "pending",
+             :statuses => [{ :state => "pending", :context => "test" }]
+      }
+      get('/repos/github/docs/commits/wip/check-runs') {
+        status 404
+      }
+      """
+    When I run `hub ci-status --batch --json` interactively
+    And I pass in:
+      """
+      github/docs@wip
+      """
+    Then the output should contain exactly:
+      """
+      [
+        {
+          "repo": "github/docs",
+          "ref": "wip",
+          "state": "pending",
+          "failing": 0,
+          "total": 1
+        }
+      ]\n
+      """
+    And the exit status should be 2
+
+  Scenario: Invalid color setting
+    When I run `hub ci-status --color=sometimes`
+    Then the exit status should be 5
+    And the stderr should contain "invalid value 'sometimes' for --color (expected: always, never, auto)"
+
+  Scenario: Wait for pending checks
+    Given there is a commit named "the_sha"
+    And the GitHub API server:
+      """
+      requests = 0
+      get('/repos/michiels/pencilbox/commits/:sha/status') {
+        requests += 1
+        state = requests < 3 ? "pending" : "success"
+        json :state => state, :statuses => [{ :state => state, :context => "travis-ci" }]
+      }
+      get('/repos/michiels/pencilbox/commits/:sha/check-runs') {
+        status 422
+      }
+      """
+    When I run `hub ci-status --wait=1 the_sha`
+    Then the output should contain exactly "success\n"
+    And the exit status should be 0
+
+  Scenario: Notifying requires waiting
+    When I run `hub ci-status --notify`
+    Then the exit status should be 5
+    And the stderr should contain "the '--notify' option requires '--wait'"
+
+  Scenario: Only consider the checks required by branch protection
+    Given there is a commit named "the_sha"
+    And the GitHub API server:
+      """
+      get('/repos/michiels/pencilbox/commits/:sha/pulls') {
+        json [{ :number => 12, :state => "open", :base => { :ref => "main" }, :head => { :sha => params[:sha] } }]
+      }
+      get('/repos/michiels/pencilbox/branches/main/protection/required_status_checks') {
+        json :contexts => ["build", "deploy"], :checks => [{ :context => "build" }]
+      }
+      get('/repos/michiels/pencilbox/commits/:sha/status') {
+        json :state => "failure", :statuses => [
+          { :state => "success", :context => "build" },
+          { :state => "failure", :context => "coverage" },
+        ]
+      }
+      get('/repos/michiels/pencilbox/commits/:sha/check-runs') {
+        status 422
+      }
+      """
+    When I run `hub ci-status -v --required-only the_sha`
+    Then the output should contain exactly "●\tdeploy\n✔︎\tbuild\n"
+    And the exit status should be 2
+
+  Scenario: Required checks of a branch without admin access
+    Given there is a commit named "the_sha"
+    And the GitHub API server:
+      """
+      get('/repos/michiels/pencilbox/commits/:sha/pulls') {
+        json [{ :number => 12, :state => "open", :base => { :ref => "main" }, :head => { :sha => params[:sha] } }]
+      }
+      get('/repos/michiels/pencilbox/branches/main/protection/required_status_checks') {
+        status 404
+        json :message => "Not Found"
+      }
+      get('/repos/michiels/pencilbox/branches/main') {
+        json :protection => { :required_status_checks => { :contexts => ["build", "deploy"] } }
+      }
+      get('/repos/michiels/pencilbox/commits/:sha/status') {
+        json :state => "success", :statuses => [
+          { :state => "success", :context => "build" },
+        ]
+      }
+      get('/repos/michiels/pencilbox/commits/:sha/check-runs') {
+        status 422
+      }
+      """
+    When I run `hub ci-status --required-only the_sha`
+    Then the output should contain exactly "pending\n"
+    And the exit status should be 2
+
+  Scenario: Required checks of the pull request when the branch can't be read
+    Given there is a commit named "the_sha"
+    And the GitHub API server:
+      """
+      get('/repos/michiels/pencilbox/commits/:sha/pulls') {
+        json [{ :number => 12, :state => "open", :base => { :ref => "main" }, :head => { :sha => params[:sha] } }]
+      }
+      get('/repos/michiels/pencilbox/branches/main/protection/required_status_checks') {
+        status 404
+        json :message => "Not Found"
+      }
+      get('/repos/michiels/pencilbox/branches/main') {
+        status 403
+        json :message => "Resource not accessible by integration"
+      }
+      post('/graphql') {
+        assert :variables => { "owner" => "michiels", "name" => "pencilbox", "number" => 12 }
+        json :data => { :repository => { :pullRequest => { :commits => { :nodes => [{ :commit => {
+          :statusCheckRollup => { :contexts => { :nodes => [
+            { :__typename => "CheckRun", :name => "build", :status => "COMPLETED", :conclusion => "SUCCESS", :isRequired => true },
+            { :__typename => "CheckRun", :name => "coverage", :status => "COMPLETED", :conclusion => "FAILURE", :isRequired => false },
+          ] } }
+        } }] } } } }
+      }
+      """
+    When I run `hub ci-status --required-only the_sha`
+    Then the stdout should contain exactly "success\n"
+    And the stderr should contain exactly "warning: can't read the checks that 'main' of michiels/pencilbox requires; using the checks required for pull request #12 instead\n"
+    And the exit status should be 0
+
+  Scenario: Required checks of a base branch given for a commit
+    Given there is a commit named "the_sha"
+    And the GitHub API server:
+      """
+      get('/repos/michiels/pencilbox/branches/release/protection/required_status_checks') {
+        json :contexts => ["build"], :checks => []
+      }
+      get('/repos/michiels/pencilbox/commits/:sha/status') {
+        json :state => "failure", :statuses => [
+          { :state => "success", :context => "build" },
+          { :state => "failure", :context => "coverage" },
+        ]
+      }
+      get('/repos/michiels/pencilbox/commits/:sha/check-runs') {
+        status 422
+      }
+      """
+    When I run `hub ci-status --required-only --base release the_sha`
+    Then the output should contain exactly "success\n"
+    And the exit status should be 0
+
+  Scenario: Required checks of a commit without a pull request
+    Given there is a commit named "the_sha"
+    And the GitHub API server:
+      """
+      get('/repos/michiels/pencilbox/commits/:sha/pulls') {
+        json []
+      }
+      """
+    When I run `hub ci-status --required-only the_sha`
+    Then the stderr should contain exactly "Aborted: no open pull request found for 'the_sha'; use '--base <BRANCH>' to name the protected branch\n"
+    And the exit status should be 1
+
+  Scenario: Base branch requires required-only
+    When I run `hub ci-status --base main`
+    Then the exit status should be 5
+    And the stderr should contain "the '--base' option requires '--required-only'"
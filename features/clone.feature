@@ -169,7 +169,7 @@ Feature: hub clone
       get('/repos/mislav/dotfiles') { status 404 }
       """
     When I run `hub clone dotfiles`
-    Then the exit status should be 1
+    Then the exit status should be 3
     And the stdout should contain exactly ""
     And the stderr should contain exactly "Error: repository mislav/dotfiles doesn't exist\n"
     And it should not clone anything
@@ -285,4 +285,34 @@ Feature: hub clone
       """
     When I successfully run `hub clone rtomayko/ronn`
     Then it should clone "git://github.com/RTomayko/ronin.git"
-    And there should be no output
+    And the stdout should contain exactly ""
+    And the stderr should contain exactly "rtomayko/ronn was moved to RTomayko/ronin\n"
+
+  Scenario: Clone a repo along with forks of teammates
+    Given the GitHub API server:
+      """
+      get('/repos/rtomayko/ronn') {
+        json :private => false, :full_name => 'rtomayko/ronn', :forks_count => 3,
+             :name => 'ronn', :owner => { :login => 'rtomayko' },
+             :permissions => { :push => false }
+      }
+      get('/repos/rtomayko/ronn/forks') {
+        json [
+          { :name => 'ronn', :owner => { :login => 'mislav' } },
+          { :name => 'ronn', :owner => { :login => 'origin' } },
+        ]
+      }
+      """
+    And a git repo in "ronn"
+    And I successfully run `git -C ronn remote add origin git://github.com/rtomayko/ronn.git`
+    When I successfully run `hub clone --add-forks mislav,origin,josh rtomayko/ronn`
+    Then it should clone "git://github.com/rtomayko/ronn.git"
+    And "git -C ronn remote add mislav git://github.com/mislav/ronn.git" should be run
+    And "git -C ronn remote add origin2 git://github.com/origin/ronn.git" should be run
+    And "git -C ronn fetch --multiple mislav origin2" should be run
+    And the output should contain exactly:
+      """
+      new remote: mislav
+      new remote: origin2\n
+      """
+    And the stderr should contain exactly "josh has no fork of rtomayko/ronn\n"
@@ -0,0 +1,82 @@
+Feature: hub commit
+  Background:
+    Given I am in "git://github.com/mislav/dotfiles.git" git repo
+    And I am "mislav" on github.com with OAuth token "OTOKEN"
+
+  Scenario: Comment on a commit by URL
+    Given the GitHub API server:
+      """
+      post('/repos/github/hub/commits/abcdef1/comments') {
+        assert :body => "Nice catch!"
+        status 201
+        json :html_url => "https://github.com/github/hub/commit/abcdef1#commitcomment-1"
+      }
+      """
+    When I successfully run `hub commit comment -m "Nice catch!" https://github.com/github/hub/commit/abcdef1`
+    Then the output should contain exactly:
+      """
+      https://github.com/github/hub/commit/abcdef1#commitcomment-1\n
+      """
+
+  Scenario: Positioned comment on a file in the commit
+    Given the GitHub API server:
+      """
+      get('/repos/mislav/dotfiles/commits/abcdef1') {
+        json :sha => "abcdef1",
+             :files => [{ :filename => "app.rb" }]
+      }
+      post('/repos/mislav/dotfiles/commits/abcdef1/comments') {
+        assert :body => "Typo",
+               :path => "app.rb",
+               :line => 12
+        status 201
+        json :html_url => "https://github.com/mislav/dotfiles/commit/abcdef1#commitcomment-2"
+      }
+      """
+    When I successfully run `hub commit comment -m Typo --path app.rb --line 12 abcdef1`
+    Then the output should contain exactly:
+      """
+      https://github.com/mislav/dotfiles/commit/abcdef1#commitcomment-2\n
+      """
+
+  Scenario: Positioned comment on a file outside of the commit
+    Given the GitHub API server:
+      """
+      get('/repos/mislav/dotfiles/commits/abcdef1') {
+        json :sha => "abcdef1",
+             :files => [{ :filename => "app.rb" }]
+      }
+      """
+    When I run `hub commit comment -m Typo --path README.md abcdef1`
+    Then the exit status should be 1
+    And the stderr should contain exactly:
+      """
+      Aborted: 'README.md' is not among the files changed in abcdef1\n
+      """
+
+  Scenario: List comments on a commit
+    Given the GitHub API server:
+      """
+      get('/repos/mislav/dotfiles/commits/abcdef1/comments') {
+        json [
+          { :body => "Looks good",
+            :user => { :login => "josh" },
+            :created_at => (Time.now - 60*60).utc.iso8601 },
+          { :body => "Typo\nhere",
+            :path => "app.rb",
+            :line => 12,
+            :user => { :login => "mislav" },
+            :created_at => (Time.now - 60*60*24).utc.iso8601 },
+        ]
+      }
+      """
+    When I successfully run `hub commit comments abcdef1`
+    Then the output should contain exactly:
+      """
+      josh commented (1 hour ago):
+          Looks good
+
+      mislav commented on app.rb:12 (1 day ago):
+          Typo
+          here\n
+      """
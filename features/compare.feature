@@ -20,7 +20,7 @@ Feature: hub compare
 
   Scenario: No args, no upstream
     When I run `hub compare`
-    Then the exit status should be 1
+    Then the exit status should be 5
     And the stderr should contain:
       """
       Usage: hub compare [-uc] [<USER>] [[<START>...]<END>]
@@ -31,7 +31,7 @@ Feature: hub compare
     Given the default branch for "origin" is "develop"
     And I am on the "develop" branch with upstream "origin/develop"
     When I run `hub compare`
-    Then the exit status should be 1
+    Then the exit status should be 5
     And the stderr should contain "Usage: hub compare"
 
   Scenario: No args, has upstream branch
@@ -75,19 +75,35 @@ Feature: hub compare
     Then there should be no output
     And "open https://github.com/mislav/dotfiles/compare/experimental...master" should be run
 
+  Scenario: Compare against the base branch remembered for the repo
+    Given I am on the "master" branch with upstream "origin/master"
+    And git "push.default" is set to "upstream"
+    And git "--global hub.mislav/dotfiles.base" is set to "develop"
+    When I successfully run `hub compare`
+    Then there should be no output
+    And "open https://github.com/mislav/dotfiles/compare/develop...master" should be run
+
+  Scenario: Explicit compare base takes precedence over the remembered one
+    Given I am on the "feature" branch with upstream "origin/experimental"
+    And git "push.default" is set to "upstream"
+    And git "--global hub.mislav/dotfiles.base" is set to "develop"
+    When I successfully run `hub compare -b master`
+    Then there should be no output
+    And "open https://github.com/mislav/dotfiles/compare/master...experimental" should be run
+
   Scenario: Compare base with same branch as the current branch
     Given I am on the "feature" branch with upstream "origin/experimental"
     And git "push.default" is set to "upstream"
     When I run `hub compare -b experimental`
     Then "open https://github.com/mislav/dotfiles/compare/experimental...experimental" should not be run
-    And the exit status should be 1
+    And the exit status should be 5
     And the stderr should contain "Usage: hub compare"
 
   Scenario: Compare base with parameters
     Given I am on the "master" branch with upstream "origin/master"
     When I run `hub compare -b master experimental..master`
     Then "open https://github.com/mislav/dotfiles/compare/experimental...master" should not be run
-    And the exit status should be 1
+    And the exit status should be 5
     And the stderr should contain "Usage: hub compare"
 
   Scenario: Compare 2-dots range for tags
@@ -148,9 +164,105 @@ Feature: hub compare
       """
     And the exit status should be 1
 
+  Scenario: Comparing the current branch while not on a local branch
+    Given I am in detached HEAD
+    When I run `hub compare`
+    Then the exit status should be 1
+    And the stderr should match /^Aborted: you are in detached HEAD state at [0-9a-f]{7}; check out a branch or give a <RANGE> to compare, such as "master...topic"$/
+
   Scenario: Comparing two branches while not on a local branch
     Given I am in detached HEAD
     And I run `hub compare refactor...master`
     Then the exit status should be 0
     And there should be no output
     And "open https://github.com/mislav/dotfiles/compare/refactor...master" should be run
+
+  Scenario: Compare statistics
+    Given the GitHub API server:
+      """
+      get(%r{^/repos/mislav/dotfiles/compare/master\.\.\.jingweno:feature/foo$}) {
+        json :status => "diverged",
+             :ahead_by => 3,
+             :behind_by => 1,
+             :total_commits => 3,
+             :files => [
+               { :filename => "README.md", :status => "modified", :additions => 10, :deletions => 2 },
+               { :filename => "lib/new.rb", :previous_filename => "lib/old.rb", :status => "renamed", :additions => 0, :deletions => 0 },
+               { :filename => "bin/setup", :status => "added", :additions => 140, :deletions => 0 },
+             ]
+      }
+      """
+    When I successfully run `hub compare --stat master..jingweno:feature/foo`
+    Then the output should contain exactly:
+      """
+      jingweno:feature/foo is 3 commits ahead of and 1 commit behind master (diverged)
+      modified  README.md                  +10  -2
+      renamed   lib/old.rb => lib/new.rb    +0  -0
+      added     bin/setup                 +140  -0
+      3 files changed, 150 additions(+), 2 deletions(-)\n
+      """
+
+  Scenario: Compare statistics against the default branch with file limit
+    Given the GitHub API server:
+      """
+      get('/repos/mislav/dotfiles') {
+        json :default_branch => "main"
+      }
+      get('/repos/mislav/dotfiles/compare/main...refactor') {
+        json :status => "ahead",
+             :ahead_by => 1,
+             :behind_by => 0,
+             :total_commits => 1,
+             :files => [
+               { :filename => "README.md", :status => "modified", :additions => 1, :deletions => 1 },
+               { :filename => "Makefile", :status => "modified", :additions => 2, :deletions => 0 },
+             ]
+      }
+      """
+    When I successfully run `hub compare --stat --limit-files 1 refactor`
+    Then the stdout should contain exactly:
+      """
+      refactor is 1 commit ahead of and 0 commits behind main (ahead)
+      modified  README.md  +1  -1
+      1 file changed, 1 additions(+), 1 deletions(-)\n
+      """
+    And the stderr should contain exactly:
+      """
+      warning: showing 1 of 2 changed files\n
+      """
+
+  Scenario: Compare statistics in JSON format
+    Given the GitHub API server:
+      """
+      get('/repos/mislav/dotfiles/compare/v1.0...v1.1') {
+        json :status => "ahead",
+             :ahead_by => 1,
+             :behind_by => 0,
+             :total_commits => 1,
+             :files => [
+               { :filename => "README.md", :status => "modified", :additions => 1, :deletions => 1, :patch => "@@ -1 +1 @@" },
+             ]
+      }
+      """
+    When I successfully run `hub compare --stat --json v1.0..v1.1`
+    Then the output should contain exactly:
+      """
+      {
+        "ahead_by": 1,
+        "base": "v1.0",
+        "behind_by": 0,
+        "files": [
+          {
+            "filename": "README.md",
+            "status": "modified",
+            "additions": 1,
+            "deletions": 1
+          }
+        ],
+        "head": "v1.1",
+        "status": "ahead",
+        "total_commits": 1,
+        "total_files": 1,
+        "truncated": false
+      }\n
+      """
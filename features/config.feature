@@ -0,0 +1,110 @@
+Feature: hub config
+  Background:
+    Given I am in "dotfiles" git repo
+
+  Scenario: Export configuration without tokens
+    Given I am "mislav" on github.com with OAuth token "OTOKEN"
+    And git "--global hub.protocol" is set to "https"
+    And git "--global alias.co" is set to "checkout"
+    And git "--global user.name" is set to "Mislav"
+    When I successfully run `hub config export`
+    Then the output should contain exactly:
+      """
+      version: 1
+      hosts:
+        github.com:
+        - user: mislav
+      git_config:
+        hub.protocol: https
+        alias.co: checkout\n
+      """
+
+  Scenario: Export configuration with tokens
+    Given I am "mislav" on github.com with OAuth token "OTOKEN"
+    When I successfully run `hub config export --include-tokens`
+    Then the stdout should contain "oauth_token: OTOKEN"
+    And the stderr should contain exactly:
+      """
+      warning: the exported configuration includes access tokens; keep it private\n
+      """
+
+  Scenario: Import configuration
+    Given a file named "hub.yml" with:
+      """
+      version: 1
+      hosts:
+        github.com:
+        - user: mislav
+          oauth_token: OTOKEN
+      git_config:
+        hub.host:
+        - git.example.com
+        - gh.example.org
+      """
+    When I successfully run `hub config import -F hub.yml`
+    Then there should be no output
+    And the file "../home/.config/hub" should contain "oauth_token: OTOKEN"
+    And the file "../home/.config/hub" should have mode "0600"
+    When I successfully run `git config --global --get-all hub.host`
+    Then the output should contain exactly "git.example.com\ngh.example.org\n"
+
+  Scenario: Refuse to write tokens to a file that others can read
+    Given I am "mislav" on github.com
+    And I successfully run `chmod 644 ../home/.config/hub`
+    And a file named "hub.yml" with:
+      """
+      version: 1
+      hosts:
+        github.com:
+        - user: mislav
+          oauth_token: OTOKEN
+      """
+    When I run `hub config import -F hub.yml`
+    Then the exit status should be 1
+    And the stderr should contain "refusing to write access tokens to "
+    And the file "../home/.config/hub" should not contain "OTOKEN"
+
+  Scenario: Invalid document
+    Given a file named "hub.yml" with:
+      """
+      version: 1
+      git_config:
+        user.name: Mislav
+      """
+    When I run `hub config import -F hub.yml`
+    Then the exit status should be 1
+    And the stderr should contain exactly:
+      """
+      invalid config document: git_config.user.name: only "hub.*" and "alias.*" variables can be imported\n
+      """
+
+  Scenario: Import requires a file
+    When I run `hub config import`
+    Then the exit status should be 5
+    And the stderr should contain "the '--file' option is required"
+
+  Scenario: Other arguments are passed to git
+    When I successfully run `hub config --global hub.test yes`
+    And I successfully run `git config --global hub.test`
+    Then the output should contain exactly "yes\n"
+
+  Scenario: Remember defaults for the current repository
+    When I successfully run `hub config set --project base develop`
+    And I successfully run `hub config set --project reviewers "josh, github/robots"`
+    And I successfully run `git config --global --get-regexp ^hub\.`
+    Then the output should contain exactly:
+      """
+      hub.mislav/dotfiles.base develop
+      hub.mislav/dotfiles.reviewers josh,github/robots\n
+      """
+    When I successfully run `hub config unset --project base`
+    And I successfully run `git config --global --get-regexp ^hub\.`
+    Then the output should contain exactly "hub.mislav/dotfiles.reviewers josh,github/robots\n"
+
+  Scenario: Unknown project default
+    When I run `hub config set --project head feature`
+    Then the exit status should be 1
+    And the stderr should contain exactly:
+      """
+      unknown project setting "head"; expected one of: base, reviewers, labels\n
+      """
@@ -5,7 +5,7 @@ Feature: hub delete
   Scenario: No argument in current repo
     Given I am in "git://github.com/github/hub.git" git repo
     When I run `hub delete`
-    Then the exit status should be 1
+    Then the exit status should be 5
     And the stderr should contain exactly:
       """
       Usage: hub delete [-y] [<ORGANIZATION>/]<NAME>\n
@@ -70,7 +70,7 @@ Feature: hub delete
       }
       """
     When I run `hub delete -y my-repo`
-    Then the exit status should be 1
+    Then the exit status should be 4
     And the stderr should contain:
       """
       Please edit the token used for hub at https://github.com/settings/tokens
@@ -87,7 +87,7 @@ Feature: hub delete
       }
       """
     When I run `hub delete -y my-repo`
-    Then the exit status should be 1
+    Then the exit status should be 4
     And the stderr should contain:
       """
       Please edit the token used for hub at https://git.my.org/settings/tokens
@@ -0,0 +1,97 @@
+Feature: hub digest
+  Background:
+    Given I am "mislav" on github.com with OAuth token "OTOKEN"
+
+  Scenario: Summarize the activity in the current repository
+    Given I am in "git://github.com/acme/tools.git" git repo
+    And the GitHub API server:
+      """
+      get('/search/issues') {
+        case params[:q]
+        when /\Arepo:acme\/tools is:pr is:merged merged:>=\S+\z/
+          json :total_count => 2, :items => [
+            { :number => 12, :title => "Fix installer", :user => { :login => "mislav" },
+              :pull_request => { :merged_at => (Time.now - 3600).utc.strftime("%Y-%m-%dT%H:%M:%SZ") } },
+            { :number => 14, :title => "Add gadgets", :user => { :login => "hubot" },
+              :pull_request => { :merged_at => (Time.now - 60).utc.strftime("%Y-%m-%dT%H:%M:%SZ") } },
+          ]
+        when /\Arepo:acme\/tools is:issue created:>=\S+\z/
+          json :total_count => 4, :items => []
+        when /\Arepo:acme\/tools is:issue is:closed closed:>=\S+\z/
+          json :total_count => 2, :items => []
+        else
+          status 422
+        end
+      }
+      get('/repos/acme/tools/commits') {
+        if params[:author] == "mislav"
+          json [{ :sha => "abc123" }]
+        else
+          json []
+        end
+      }
+      get('/repos/acme/tools/releases') {
+        json [
+          { :tag_name => "v1.2.0", :name => "Gadgets",
+            :created_at => (Time.now - 60).utc.strftime("%Y-%m-%dT%H:%M:%SZ"),
+            :published_at => (Time.now - 60).utc.strftime("%Y-%m-%dT%H:%M:%SZ") },
+          { :tag_name => "v1.1.0",
+            :created_at => (Time.now - 30 * 86400).utc.strftime("%Y-%m-%dT%H:%M:%SZ"),
+            :published_at => (Time.now - 30 * 86400).utc.strftime("%Y-%m-%dT%H:%M:%SZ") },
+        ]
+      }
+      """
+    When I successfully run `hub digest`
+    Then the output should contain "Activity in acme/tools since "
+    And the output should contain:
+      """
+      Merged pull requests: 2
+        #14  Add gadgets (@hubot)
+        #12  Fix installer (@mislav)
+      Issues: 4 opened, 2 closed
+      New contributors: 1
+        @hubot
+      Releases: 1
+        v1.2.0  Gadgets
+      """
+
+  Scenario: Warn about the search rate limit for large organizations
+    Given the GitHub API server:
+      """
+      get('/orgs/acme/repos') {
+        assert :sort => "pushed", :per_page => "2"
+        json [
+          { :full_name => "acme/tools" },
+          { :full_name => "acme/widgets" },
+        ]
+      }
+      get('/rate_limit') {
+        json :resources => {
+          :core => { :limit => 5000, :remaining => 4000, :reset => Time.now.to_i + 60 },
+          :search => { :limit => 30, :remaining => 5, :reset => Time.now.to_i + 60 },
+        }
+      }
+      get('/search/issues') {
+        json :total_count => 0, :items => []
+      }
+      get('/repos/:owner/:repo/releases') { json [] }
+      """
+    When I successfully run `hub digest --org acme --limit-repos 2 --output markdown`
+    Then the stderr should contain "warning: summarizing 2 repositories takes about 6 requests to the search API, but only 5 are left until "
+    And the output should contain "## Activity in acme since "
+    And the output should contain:
+      """
+      ### Merged pull requests (0)
+
+      None.
+
+      ### Issues
+
+      0 opened, 0 closed
+      """
+
+  Scenario: Limiting repositories requires an organization
+    Given I am in "git://github.com/acme/tools.git" git repo
+    When I run `hub digest --limit-repos 5`
+    Then the exit status should be 5
+    And the output should contain "'--limit-repos' requires '--org'"
@@ -7,12 +7,17 @@ Feature: hub fork
   Scenario: Fork the repository
     Given the GitHub API server:
       """
+      forked = false
       before {
         halt 400 unless request.env['HTTP_X_ORIGINAL_SCHEME'] == 'https'
         halt 401 unless request.env['HTTP_AUTHORIZATION'] == 'token OTOKEN'
       }
-      get('/repos/mislav/dotfiles', :host_name => 'api.github.com') { 404 }
+      get('/repos/mislav/dotfiles', :host_name => 'api.github.com') {
+        halt 404 unless forked
+        json :name => 'dotfiles', :owner => { :login => 'mislav' }
+      }
       post('/repos/evilchelu/dotfiles/forks', :host_name => 'api.github.com') {
+        forked = true
         assert :organization => nil
         status 202
         json :name => 'dotfiles', :owner => { :login => 'mislav' }
@@ -27,8 +32,13 @@ Feature: hub fork
   Scenario: Fork the repository with new remote name specified
     Given the GitHub API server:
       """
-      get('/repos/mislav/dotfiles') { 404 }
+      forked = false
+      get('/repos/mislav/dotfiles') {
+        halt 404 unless forked
+        json :name => 'dotfiles', :owner => { :login => 'mislav' }
+      }
       post('/repos/evilchelu/dotfiles/forks') {
+        forked = true
         assert :organization => nil
         status 202
         json :name => 'dotfiles', :owner => { :login => 'mislav' }
@@ -48,15 +58,21 @@ Feature: hub fork
   Scenario: Fork the repository with redirect
     Given the GitHub API server:
       """
+      forked = false
       before {
         halt 400 unless request.env['HTTP_X_ORIGINAL_SCHEME'] == 'https'
         halt 401 unless request.env['HTTP_AUTHORIZATION'] == 'token OTOKEN'
       }
       get('/repos/mislav/dotfiles', :host_name => 'api.github.com') { 404 }
+      get('/repos/MiSlAv/my-dotfiles', :host_name => 'api.github.com') {
+        halt 404 unless forked
+        json :name => 'my-dotfiles', :owner => { :login => 'MiSlAv' }
+      }
       post('/repos/evilchelu/dotfiles/forks', :host_name => 'api.github.com') {
         redirect 'https://api.github.com/repositories/1234/forks', 307
       }
       post('/repositories/1234/forks', :host_name => 'api.github.com') {
+        forked = true
         status 202
         json :name => 'my-dotfiles', :owner => { :login => 'MiSlAv' }
       }
@@ -71,9 +87,14 @@ Feature: hub fork
     Given the "origin" remote has url "git@github.com:evilchelu/dotfiles.git"
     Given the GitHub API server:
       """
+      forked = false
       before { halt 401 unless request.env['HTTP_AUTHORIZATION'] == 'token OTOKEN' }
-      get('/repos/mislav/dotfiles', :host_name => 'api.github.com') { 404 }
+      get('/repos/mislav/dotfiles', :host_name => 'api.github.com') {
+        halt 404 unless forked
+        json :name => 'dotfiles', :owner => { :login => 'mislav' }
+      }
       post('/repos/evilchelu/dotfiles/forks', :host_name => 'api.github.com') {
+        forked = true
         status 202
         json :name => 'dotfiles', :owner => { :login => 'mislav' }
       }
@@ -87,7 +108,13 @@ Feature: hub fork
   Scenario: --no-remote
     Given the GitHub API server:
       """
+      forked = false
+      get('/repos/mislav/dotfiles') {
+        halt 404 unless forked
+        json :name => 'dotfiles', :owner => { :login => 'mislav' }
+      }
       post('/repos/evilchelu/dotfiles/forks') {
+        forked = true
         status 202
         json :name => 'dotfiles', :owner => { :login => 'mislav' }
       }
@@ -163,8 +190,13 @@ Feature: hub fork
     Given the "mislav" remote has url "git@github.com:mislav/unrelated.git"
     Given the GitHub API server:
       """
-      get('/repos/mislav/dotfiles', :host_name => 'api.github.com') { 404 }
+      forked = false
+      get('/repos/mislav/dotfiles', :host_name => 'api.github.com') {
+        halt 404 unless forked
+        json :name => 'dotfiles', :owner => { :login => 'mislav' }
+      }
       post('/repos/evilchelu/dotfiles/forks', :host_name => 'api.github.com') {
+        forked = true
         assert :organization => nil
         status 202
         json :name => 'dotfiles', :owner => { :login => 'mislav' }
@@ -219,7 +251,7 @@ Feature: hub fork
       """
     And I am "mislav" on github.com with OAuth token "WRONGTOKEN"
     When I run `hub fork`
-    Then the exit status should be 1
+    Then the exit status should be 4
     And the stderr should contain exactly:
       """
       Error creating fork: Unauthorized (HTTP 401)\n
@@ -228,7 +260,13 @@ Feature: hub fork
   Scenario: HTTPS is preferred
     Given the GitHub API server:
       """
+      forked = false
+      get('/repos/mislav/dotfiles') {
+        halt 404 unless forked
+        json :name => 'dotfiles', :owner => { :login => 'mislav' }
+      }
       post('/repos/evilchelu/dotfiles/forks') {
+        forked = true
         status 202
         json :name => 'dotfiles', :owner => { :login => 'mislav' }
       }
@@ -266,11 +304,17 @@ Feature: hub fork
   Scenario: Enterprise fork
     Given the GitHub API server:
       """
+      forked = false
       before {
         halt 400 unless request.env['HTTP_X_ORIGINAL_SCHEME'] == 'https'
         halt 401 unless request.env['HTTP_AUTHORIZATION'] == 'token FITOKEN'
       }
+      get('/api/v3/repos/mislav/dotfiles', :host_name => 'git.my.org') {
+        halt 404 unless forked
+        json :name => 'dotfiles', :owner => { :login => 'mislav' }
+      }
       post('/api/v3/repos/evilchelu/dotfiles/forks', :host_name => 'git.my.org') {
+        forked = true
         status 202
         json :name => 'dotfiles', :owner => { :login => 'mislav' }
       }
@@ -284,12 +328,18 @@ Feature: hub fork
   Scenario: Enterprise fork using regular HTTP
     Given the GitHub API server:
       """
+      forked = false
       before {
         halt 400 unless request.env['HTTP_X_ORIGINAL_SCHEME'] == 'http'
         halt 400 unless request.env['HTTP_X_ORIGINAL_PORT'] == '80'
         halt 401 unless request.env['HTTP_AUTHORIZATION'] == 'token FITOKEN'
       }
+      get('/api/v3/repos/mislav/dotfiles', :host_name => 'git.my.org') {
+        halt 404 unless forked
+        json :name => 'dotfiles', :owner => { :login => 'mislav' }
+      }
       post('/api/v3/repos/evilchelu/dotfiles/forks', :host_name => 'git.my.org') {
+        forked = true
         status 202
         json :name => 'dotfiles', :owner => { :login => 'mislav' }
       }
@@ -303,8 +353,13 @@ Feature: hub fork
   Scenario: Fork a repo to a specific organization
     Given the GitHub API server:
       """
-      get('/repos/acme/dotfiles') { 404 }
+      forked = false
+      get('/repos/acme/dotfiles') {
+        halt 404 unless forked
+        json :name => 'dotfiles', :owner => { :login => 'acme' }
+      }
       post('/repos/evilchelu/dotfiles/forks') {
+        forked = true
         assert :organization => "acme"
         status 202
         json :name => 'dotfiles', :owner => { :login => 'acme' }
@@ -313,3 +368,170 @@ Feature: hub fork
     When I successfully run `hub fork --org=acme`
     Then the output should contain exactly "new remote: acme\n"
     Then the url for "acme" should be "git@github.com:acme/dotfiles.git"
+
+  Scenario: Wait for the new fork to become available
+    Given the GitHub API server:
+      """
+      lookups = 0
+      get('/repos/mislav/dotfiles') {
+        lookups += 1
+        halt 404 if lookups < 3
+        json :name => 'dotfiles', :owner => { :login => 'mislav' }
+      }
+      post('/repos/evilchelu/dotfiles/forks') {
+        status 202
+        json :name => 'dotfiles', :owner => { :login => 'mislav' }
+      }
+      """
+    When I successfully run `hub fork`
+    Then the stdout should contain exactly "new remote: mislav\n"
+    And the stderr should contain exactly "Waiting for mislav/dotfiles to become available...\n"
+    And the url for "mislav" should be "git@github.com:mislav/dotfiles.git"
+
+  Scenario: Don't wait for the new fork
+    Given the GitHub API server:
+      """
+      get('/repos/mislav/dotfiles') { 404 }
+      post('/repos/evilchelu/dotfiles/forks') {
+        status 202
+        json :name => 'dotfiles', :owner => { :login => 'mislav' }
+      }
+      """
+    When I successfully run `hub fork --no-wait --no-remote`
+    Then there should be no output
+
+  Scenario: Fork and clone a repository
+    Given the current dir is not a repo
+    And the GitHub API server:
+      """
+      forked = false
+      get('/repos/evilchelu/dotfiles') {
+        json :name => 'dotfiles', :owner => { :login => 'evilchelu' },
+             :private => false, :permissions => { :push => false }
+      }
+      get('/repos/mislav/dotfiles') {
+        halt 404 unless forked
+        json :name => 'dotfiles', :owner => { :login => 'mislav' }
+      }
+      post('/repos/evilchelu/dotfiles/forks') {
+        forked = true
+        status 202
+        json :name => 'dotfiles', :owner => { :login => 'mislav' }
+      }
+      """
+    And a git repo in "dotfiles"
+    When I successfully run `hub fork --clone evilchelu/dotfiles`
+    Then it should clone "git@github.com:mislav/dotfiles.git dotfiles"
+    And "git -C dotfiles remote add -f upstream git://github.com/evilchelu/dotfiles.git" should be run
+    And the output should contain exactly "new remote: upstream\n"
+
+  Scenario: Fork and clone a repository into a directory
+    Given the current dir is not a repo
+    And the GitHub API server:
+      """
+      get('/repos/evilchelu/dotfiles') {
+        json :name => 'dotfiles', :owner => { :login => 'evilchelu' },
+             :private => true, :permissions => { :push => true }
+      }
+      get('/repos/mislav/dotfiles') {
+        json :name => 'dotfiles', :owner => { :login => 'mislav' },
+             :html_url => 'https://github.com/mislav/dotfiles',
+             :parent => { :html_url => 'https://github.com/evilchelu/dotfiles' }
+      }
+      """
+    And a git repo in "dots"
+    When I successfully run `hub fork --clone evilchelu/dotfiles dots`
+    Then it should clone "git@github.com:mislav/dotfiles.git dots"
+    And "git -C dots remote add -f upstream git@github.com:evilchelu/dotfiles.git" should be run
+
+  Scenario: Fork and clone needs a repository
+    When I run `hub fork --clone`
+    Then the exit status should be 5
+    And the stderr should contain "Usage: hub fork"
+
+  Scenario: Sync a fork with upstream
+    Given the "mislav" remote has url "git@github.com:mislav/dotfiles.git"
+    Given the GitHub API server:
+      """
+      get('/repos/mislav/dotfiles') {
+        json :name => 'dotfiles', :owner => { :login => 'mislav' },
+             :html_url => 'https://github.com/mislav/dotfiles',
+             :default_branch => 'main',
+             :parent => {
+               :name => 'dotfiles', :owner => { :login => 'evilchelu' },
+               :html_url => 'https://github.com/evilchelu/dotfiles'
+             }
+      }
+      post('/repos/mislav/dotfiles/merge-upstream') {
+        assert :branch => 'main'
+        json :message => 'Successfully fetched and fast-forwarded from upstream evilchelu:main.',
+             :merge_type => 'fast-forward', :base_branch => 'evilchelu:main'
+      }
+      """
+    When I successfully run `hub fork sync`
+    Then the output should contain exactly "Fast-forwarded mislav/dotfiles:main to evilchelu:main.\n"
+
+  Scenario: Sync a branch of a fork that is up to date
+    Given the GitHub API server:
+      """
+      get('/repos/mislav/dotfiles') {
+        json :name => 'dotfiles', :owner => { :login => 'mislav' },
+             :html_url => 'https://github.com/mislav/dotfiles',
+             :default_branch => 'main',
+             :parent => {
+               :name => 'dotfiles', :owner => { :login => 'evilchelu' },
+               :html_url => 'https://github.com/evilchelu/dotfiles'
+             }
+      }
+      post('/repos/mislav/dotfiles/merge-upstream') {
+        assert :branch => 'dev'
+        json :message => 'This branch is not behind the upstream evilchelu:dev.',
+             :merge_type => 'none', :base_branch => 'evilchelu:dev'
+      }
+      """
+    When I successfully run `hub fork sync --repo dotfiles --branch dev`
+    Then the output should contain exactly "mislav/dotfiles:dev is already up to date with evilchelu:dev.\n"
+
+  Scenario: Sync a fork with merge conflicts
+    Given the "mislav" remote has url "git@github.com:mislav/dotfiles.git"
+    Given the GitHub API server:
+      """
+      get('/repos/mislav/dotfiles') {
+        json :name => 'dotfiles', :owner => { :login => 'mislav' },
+             :html_url => 'https://github.com/mislav/dotfiles',
+             :default_branch => 'main',
+             :parent => {
+               :name => 'dotfiles', :owner => { :login => 'evilchelu' },
+               :html_url => 'https://github.com/evilchelu/dotfiles'
+             }
+      }
+      post('/repos/mislav/dotfiles/merge-upstream') {
+        status 409
+        json :message => 'There are merge conflicts'
+      }
+      """
+    When I run `hub fork sync`
+    Then the exit status should be 1
+    And the stderr should contain exactly:
+      """
+      Aborted: mislav/dotfiles:main could not be synced with evilchelu/dotfiles:main because of merge conflicts.
+      To merge the upstream changes and resolve the conflicts locally, run:
+
+          git checkout main
+          git pull origin main
+          [ resolve the conflicts and commit the merge ]
+          git push mislav main\n
+      """
+
+  Scenario: Sync a repository that is not a fork
+    Given the GitHub API server:
+      """
+      get('/repos/mislav/dotfiles') {
+        json :name => 'dotfiles', :owner => { :login => 'mislav' },
+             :html_url => 'https://github.com/mislav/dotfiles',
+             :default_branch => 'main'
+      }
+      """
+    When I run `hub fork sync --repo mislav/dotfiles`
+    Then the exit status should be 1
+    And the stderr should contain exactly "Aborted: mislav/dotfiles is not a fork\n"
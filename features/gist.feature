@@ -0,0 +1,127 @@
+Feature: hub gist
+  Background:
+    Given I am "mislav" on github.com with OAuth token "OTOKEN"
+
+  Scenario: Edit the only file of a gist
+    Given the GitHub API server:
+      """
+      get('/gists/abc123') {
+        json :id => 'abc123',
+             :owner => { :login => 'mislav' },
+             :files => { 'notes.md' => { :content => "Hello\n" } }
+      }
+      get('/user') {
+        json :login => 'mislav'
+      }
+      patch('/gists/abc123') {
+        assert :files => { 'notes.md' => { 'content' => "Goodbye\n" } }
+        json :html_url => 'https://gist.github.com/abc123'
+      }
+      """
+    Given $GIT_EDITOR is "sed -i.bak s/Hello/Goodbye/"
+    When I successfully run `hub gist edit abc123`
+    Then the output should contain exactly "https://gist.github.com/abc123\n"
+
+  Scenario: Add and remove files
+    Given the GitHub API server:
+      """
+      get('/gists/abc123') {
+        json :id => 'abc123',
+             :owner => { :login => 'mislav' },
+             :files => { 'notes.md' => { :content => "Hello\n" }, 'draft.txt' => { :content => "TODO\n" } }
+      }
+      get('/user') {
+        json :login => 'mislav'
+      }
+      patch('/gists/abc123') {
+        assert :files => { 'draft.txt' => nil, 'todo.txt' => { 'content' => "milk\n" } }
+        json :html_url => 'https://gist.github.com/abc123'
+      }
+      """
+    Given a file named "todo.txt" with:
+      """
+      milk
+
+      """
+    When I successfully run `hub gist edit --add todo.txt --remove draft.txt abc123`
+    Then the output should contain exactly "https://gist.github.com/abc123\n"
+
+  Scenario: Gist with multiple files
+    Given the GitHub API server:
+      """
+      get('/gists/abc123') {
+        json :id => 'abc123',
+             :owner => { :login => 'mislav' },
+             :files => { 'notes.md' => { :content => "Hello\n" }, 'draft.txt' => { :content => "TODO\n" } }
+      }
+      get('/user') {
+        json :login => 'mislav'
+      }
+      """
+    When I run `hub gist edit abc123`
+    Then the exit status should be 1
+    And the stderr should contain exactly "Error: gist abc123 has multiple files; specify one of: draft.txt, notes.md\n"
+
+  Scenario: Gist owned by someone else
+    Given the GitHub API server:
+      """
+      get('/gists/abc123') {
+        json :id => 'abc123',
+             :owner => { :login => 'octocat' },
+             :files => { 'notes.md' => { :content => "Hello\n" } }
+      }
+      get('/user') {
+        json :login => 'mislav'
+      }
+      """
+    When I run `hub gist edit abc123`
+    Then the exit status should be 1
+    And the stderr should contain exactly "Error: you don't own gist abc123; it belongs to octocat\n"
+
+  Scenario: Delete a gist
+    Given the GitHub API server:
+      """
+      get('/gists/abc123') {
+        json :id => 'abc123', :owner => { :login => 'mislav' }, :files => {}
+      }
+      get('/user') {
+        json :login => 'mislav'
+      }
+      delete('/gists/abc123') {
+        status 204
+      }
+      """
+    When I successfully run `hub gist delete --yes abc123`
+    Then the output should contain exactly "Deleted gist 'abc123'.\n"
+
+  Scenario: Delete a gist without a terminal to confirm it
+    Given the GitHub API server:
+      """
+      get('/gists/abc123') {
+        json :id => 'abc123', :owner => { :login => 'mislav' }, :files => {}
+      }
+      get('/user') {
+        json :login => 'mislav'
+      }
+      """
+    When I run `hub gist delete abc123` interactively
+    And I type "yes"
+    Then the exit status should be 1
+    And the stderr should contain exactly:
+      """
+      Aborted: can't confirm deleting gist 'abc123' without a terminal
+      (use `--yes` to delete it anyway)\n
+      """
+
+  Scenario: Preview deleting a gist
+    Given the GitHub API server:
+      """
+      get('/gists/abc123') {
+        json :id => 'abc123', :owner => { :login => 'mislav' }, :files => {}
+      }
+      get('/user') {
+        json :login => 'mislav'
+      }
+      """
+    When I successfully run `hub --noop gist delete abc123`
+    Then the output should contain exactly "Would delete gist 'abc123'.\n"
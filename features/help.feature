@@ -19,6 +19,13 @@ Feature: hub help
     When I successfully run `hub help -a`
     Then the output should contain "pull-request"
 
+  Scenario: Lists exit statuses
+    When I successfully run `hub __exitcodes`
+    Then the output should contain "  5  invalid command-line usage\n"
+    And the output should contain "130  interrupted with Ctrl-C\n"
+    When I successfully run `hub help -a`
+    Then the output should not contain "__exitcodes"
+
   Scenario: Shows help for a subcommand
     When I successfully run `hub help hub-help`
     Then the output should contain "`hub help` hub-<COMMAND>"
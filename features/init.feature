@@ -19,3 +19,94 @@ Feature: hub init
     And "git.my.org" is a whitelisted Enterprise host
     When I successfully run `hub init -g`
     Then the url for "origin" should be "git@git.my.org:mislav/dotfiles.git"
+
+  Scenario: Bootstrap gitignore and license
+    Given the GitHub API server:
+      """
+      get('/gitignore/templates') {
+        json ["Go", "Godot", "Node", "Ruby"]
+      }
+      get('/gitignore/templates/Go') {
+        json :name => "Go", :source => "*.exe\n"
+      }
+      get('/gitignore/templates/Node') {
+        json :name => "Node", :source => "node_modules/\n"
+      }
+      get('/licenses/mit') {
+        json :key => "mit", :body => "Copyright (c) [year] [fullname]\n"
+      }
+      """
+    When I successfully run `hub init --gitignore go,node --license MIT --name "Mislav M"`
+    Then the output should not contain "Would"
+    And the file ".gitignore" should contain exactly:
+      """
+      ### Go ###
+      *.exe
+
+      ### Node ###
+      node_modules/\n
+      """
+    And the file "LICENSE" should contain " Mislav M"
+    And the file "LICENSE" should not contain "[year]"
+
+  Scenario: Refuse to replace an existing gitignore
+    Given the GitHub API server:
+      """
+      get('/gitignore/templates') {
+        json ["Go"]
+      }
+      get('/gitignore/templates/Go') {
+        json :name => "Go", :source => "*.exe\n"
+      }
+      """
+    And a file named ".gitignore" with:
+      """
+      tmp/
+      """
+    When I run `hub init --gitignore Go`
+    Then the exit status should be 1
+    And the stderr should contain "already exists"
+    And the stderr should contain "(use `--force` to replace it)"
+    And the file ".gitignore" should contain exactly "tmp/"
+
+  Scenario: Unknown gitignore template
+    Given the GitHub API server:
+      """
+      get('/gitignore/templates') {
+        json ["Go", "Godot", "Node", "Ruby"]
+      }
+      """
+    When I run `hub init --gitignore nod`
+    Then the exit status should be 1
+    And the stderr should contain exactly:
+      """
+      Unknown gitignore template 'nod'
+      Did you mean: Node?\n
+      """
+
+  Scenario: Unknown license
+    Given the GitHub API server:
+      """
+      get('/licenses/mi') {
+        status 404
+      }
+      get('/licenses') {
+        json [{ :key => "apache-2.0" }, { :key => "mit" }]
+      }
+      """
+    When I run `hub init --license mi`
+    Then the exit status should be 1
+    And the stderr should contain exactly:
+      """
+      Unknown license 'mi'
+      Did you mean: mit?\n
+      """
+
+  Scenario: Create the repository in noop mode
+    When I successfully run `hub --noop --as mislav init --create`
+    Then the output should contain exactly:
+      """
+      git init
+      hub --as mislav create\n
+      """
+    And a directory named ".git" should not exist
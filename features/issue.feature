@@ -46,22 +46,30 @@ Feature: hub issue
       assert :per_page => "3"
       json [
         { :number => 102,
+          :created_at => "2018-04-28T10:00:00Z",
           :title => "First issue",
           :state => "open",
           :user => { :login => "octocat" },
         },
         { :number => 13,
+          :created_at => "2018-04-27T10:00:00Z",
           :title => "Second issue",
           :state => "open",
           :user => { :login => "octocat" },
         },
         { :number => 999,
+          :created_at => "2018-04-26T10:00:00Z",
           :title => "Third issue",
           :state => "open",
           :user => { :login => "octocat" },
         },
       ]
     }
+    get('/search/issues') {
+      assert :q => "repo:github/hub is:issue is:open",
+             :per_page => "1"
+      json :total_count => 25, :items => []
+    }
     """
     When I successfully run `hub issue -L 2`
     Then the output should contain exactly:
@@ -69,6 +77,103 @@ Feature: hub issue
           #102  First issue
            #13  Second issue\n
       """
+    And the stderr should contain exactly "showing 2 of 25 issues\n"
+
+  Scenario: Count issues
+    Given the GitHub API server:
+    """
+    get('/repos/github/hub/milestones/3') {
+      json :number => 3, :title => "Hello World!"
+    }
+    get('/search/issues') {
+      assert :q => "repo:github/hub is:issue is:closed no:assignee label:bug milestone:\"Hello World!\"",
+             :per_page => "1"
+      json :total_count => 8, :items => []
+    }
+    """
+    When I successfully run `hub issue --count-only -s closed -a none -l bug -M 3`
+    Then the output should contain exactly "8\n"
+
+  Scenario: Sort issues by reactions
+    Given the GitHub API server:
+    """
+    get('/search/issues') {
+      assert :q => "repo:github/hub is:issue is:closed label:bug sort:reactions-asc",
+             :per_page => "100"
+      json :total_count => 2, :items => [
+        { :number => 13, :title => "Second issue", :state => "closed", :user => { :login => "octocat" } },
+        { :number => 102, :title => "First issue", :state => "closed", :user => { :login => "octocat" } },
+      ]
+    }
+    """
+    When I successfully run `hub issue -s closed -l bug -o reactions --direction asc`
+    Then the output should contain exactly:
+      """
+           #13  Second issue
+          #102  First issue\n
+      """
+
+  Scenario: Sort issues in the given direction
+    Given the GitHub API server:
+    """
+    get('/repos/github/hub/issues') {
+      assert :sort => "updated",
+             :direction => "asc"
+      json []
+    }
+    """
+    When I successfully run `hub issue -o updated --direction asc`
+    Then the output should contain exactly ""
+
+  Scenario: Search issues
+    Given the GitHub API server:
+    """
+    get('/search/issues') {
+      assert :q => "repo:github/hub is:issue label:bug -label:wontfix sort:reactions",
+             :per_page => "2"
+      json :total_count => 9, :items => [
+        { :number => 102, :title => "First issue", :state => "open", :user => { :login => "octocat" } },
+        { :number => 13, :title => "Second issue", :state => "open", :user => { :login => "octocat" } },
+      ]
+    }
+    """
+    When I successfully run `hub issue --search "label:bug -label:wontfix sort:reactions" -L 2`
+    Then the output should contain exactly:
+      """
+          #102  First issue
+           #13  Second issue\n
+      """
+    And the stderr should contain exactly "showing 2 of 9 issues\n"
+
+  Scenario: Count issues and pull requests matching a search
+    Given the GitHub API server:
+    """
+    get('/search/issues') {
+      assert :q => "repo:github/hub comments:>5",
+             :per_page => "1"
+      json :total_count => 4, :items => []
+    }
+    """
+    When I successfully run `hub issue --search comments:>5 --include-pulls --count-only`
+    Then the output should contain exactly "4\n"
+
+  Scenario: Search together with other filters
+    When I run `hub issue --search label:bug -s closed`
+    Then the exit status should be 5
+    And the stderr should contain "can't use `--state` together with `--search`"
+
+  Scenario: Count issues and pull requests
+    Given the GitHub API server:
+    """
+    get('/repos/github/hub/issues') {
+      assert :assignee => "Cornwe19",
+             :per_page => "1"
+      response.headers["Link"] = %(<https://api.github.com/repositories/12345/issues?per_page=1&page=2>; rel="next", <https://api.github.com/repositories/12345/issues?per_page=1&page=14>; rel="last")
+      json [{ :number => 102 }]
+    }
+    """
+    When I successfully run `hub issue --count-only -a Cornwe19 --include-pulls`
+    Then the output should contain exactly "14\n"
 
   Scenario: Fetch issues and pull requests
     Given the GitHub API server:
@@ -146,6 +251,112 @@ Feature: hub issue
     """
     When I successfully run `hub issue -l foo,bar`
 
+  Scenario: Exclude issues by label and author
+    Given the GitHub API server:
+    """
+    get('/repos/github/hub/issues') {
+      assert :labels => :no
+      json [
+        { :number => 102,
+          :title => "Not happening",
+          :state => "open",
+          :user => { :login => "octocat" },
+          :labels => [{ :name => "wontfix", :color => "ffffff" }],
+        },
+        { :number => 13,
+          :title => "Bump dependency",
+          :state => "open",
+          :user => { :login => "dependabot" },
+        },
+        { :number => 12,
+          :title => "Real bug",
+          :state => "open",
+          :user => { :login => "octocat" },
+        },
+        { :number => 11,
+          :title => "Another bug",
+          :state => "open",
+          :user => { :login => "mislav" },
+        },
+      ]
+    }
+    get('/search/issues') {
+      assert :q => "repo:github/hub is:issue is:open -label:wontfix -author:dependabot"
+      json :total_count => 5, :items => []
+    }
+    """
+    When I successfully run `hub issue -l '!wontfix' --exclude-author dependabot -L 2`
+    Then the output should contain exactly:
+      """
+           #12  Real bug
+           #11  Another bug\n
+      """
+    And the stderr should contain exactly "showing 2 of 5 issues\n"
+
+  Scenario: Count issues excluding an assignee
+    Given the GitHub API server:
+    """
+    get('/search/issues') {
+      assert :q => "repo:github/hub is:issue is:open label:bug -assignee:hubot"
+      json :total_count => 3, :items => []
+    }
+    """
+    When I successfully run `hub issue --count-only -l bug --exclude-assignee hubot`
+    Then the output should contain exactly "3\n"
+
+  Scenario: Requiring and excluding the same label
+    When I run `hub issue -l 'bug,!bug'`
+    Then the exit status should be 5
+    And the stderr should contain "label 'bug' can't be both required and excluded"
+
+  Scenario: Save a filter and reuse it
+    Given the GitHub API server:
+    """
+    get('/repos/github/hub/issues') {
+      assert :labels => "bug"
+      json [
+        { :number => 102,
+          :title => "First issue",
+          :state => params[:state],
+          :user => { :login => "octocat" },
+        },
+      ]
+    }
+    """
+    When I successfully run `hub issue -s closed -l bug -f '%i %S%n' --save-filter closed-bugs`
+    Then the stderr should contain exactly "Saved filter 'closed-bugs'\n"
+    When I successfully run `hub issue --list-filters`
+    Then the output should contain "closed-bugs\t--state=closed --labels=bug '--format=%i %S%n'\n"
+    When I successfully run `hub issue --filter closed-bugs -s all`
+    Then the output should contain exactly:
+      """
+      #102 closed
+      #102 all\n
+      """
+
+  Scenario: Saved filter with options for pull requests only
+    Given I successfully run `git config --global hub.filter.release "--base=release --labels=bug"`
+    And the GitHub API server:
+    """
+    get('/repos/github/hub/issues') {
+      assert :labels => "bug"
+      json []
+    }
+    """
+    When I successfully run `hub issue --filter release`
+    Then the stderr should contain exactly:
+      """
+      Note: ignoring '--base=release' of filter 'release', which doesn't apply to `hub issue`\n
+      """
+
+  Scenario: Delete a saved filter
+    Given I successfully run `git config --global hub.filter.mine --assignee=cornwe19`
+    When I successfully run `hub issue --delete-filter mine`
+    Then the stderr should contain exactly "Deleted filter 'mine'\n"
+    When I run `hub issue --filter mine`
+    Then the exit status should be 1
+    And the stderr should contain exactly "Error: no saved filter named 'mine'\n"
+
   Scenario: Fetch issues updated after a certain date and time
     Given the GitHub API server:
     """
@@ -176,6 +387,7 @@ Feature: hub issue
       response.headers["Link"] = %(<https://api.github.com/repositories/12345?per_page=100&page=2>; rel="next")
       json [
         { :number => 102,
+          :created_at => "2018-04-28T10:00:00Z",
           :title => "First issue",
           :state => "open",
           :user => { :login => "octocat" },
@@ -189,11 +401,13 @@ Feature: hub issue
         response.headers["Link"] = %(<https://api.github.com/repositories/12345?per_page=100&page=3>; rel="next")
         json [
           { :number => 13,
+            :created_at => "2018-04-27T10:00:00Z",
             :title => "Second issue",
             :state => "open",
             :user => { :login => "octocat" },
           },
           { :number => 103,
+            :created_at => "2018-04-26T10:00:00Z",
             :title => "Issue from 2nd page",
             :state => "open",
             :user => { :login => "octocat" },
@@ -202,6 +416,7 @@ Feature: hub issue
       elsif params[:page] == "3"
         json [
           { :number => 21,
+            :created_at => "2018-04-25T10:00:00Z",
             :title => "Even more issuez",
             :state => "open",
             :user => { :login => "octocat" },
@@ -253,6 +468,7 @@ Feature: hub issue
     get('/repos/github/hub/issues') {
       json [
         { :number => 102,
+          :created_at => "2018-04-28T10:00:00Z",
           :title => "First issue",
           :state => "open",
           :user => { :login => "morganwahl" },
@@ -264,6 +480,7 @@ Feature: hub issue
           ]
         },
         { :number => 201,
+          :created_at => "2018-04-27T10:00:00Z",
           :title => "No labels",
           :state => "open",
           :user => { :login => "octocat" },
@@ -279,6 +496,87 @@ Feature: hub issue
       201: \n
       """
 
+  Scenario: Export issues as a Markdown table
+    Given the GitHub API server:
+    """
+    get('/repos/github/hub/issues') {
+      json [
+        { :number => 102,
+          :title => "Support a | b in titles",
+          :state => "open",
+          :user => { :login => "lascap" },
+          :html_url => "https://github.com/github/hub/issues/102",
+          :assignees => [{ :login => "mislav" }, { :login => "josh" }],
+          :labels => [
+            { :name => "bug", :color => "cfcfcf" },
+            { :name => "help wanted", :color => "888888" },
+          ],
+          :created_at => "2020-02-10T14:59:59Z",
+        },
+        { :number => 13,
+          :title => "Second issue",
+          :state => "open",
+          :user => { :login => "mislav" },
+          :html_url => "https://github.com/github/hub/issues/13",
+          :created_at => "2020-01-10T14:59:59Z",
+        },
+      ]
+    }
+    """
+    When I successfully run `hub issue --output markdown --columns number,title,assignee,labels,created`
+    Then the output should contain exactly:
+      """
+      | # | Title | Assignee | Labels | Created |
+      | --- | --- | --- | --- | --- |
+      | [#102](https://github.com/github/hub/issues/102) | Support a \| b in titles | mislav, josh | bug, help wanted | 2020-02-10 |
+      | [#13](https://github.com/github/hub/issues/13) | Second issue |  |  | 2020-01-10 |\n
+      """
+
+  Scenario: Export issues as an HTML table
+    Given the GitHub API server:
+    """
+    get('/repos/github/hub/issues') {
+      json [
+        { :number => 102,
+          :title => "Escape <b>tags</b>",
+          :state => "open",
+          :user => { :login => "lascap" },
+          :html_url => "https://github.com/github/hub/issues/102",
+          :labels => [
+            { :name => "bug", :color => "cfcfcf" },
+            { :name => "ui", :color => "888888" },
+          ],
+        },
+      ]
+    }
+    """
+    When I successfully run `hub issue --output html --columns number,title,author,labels`
+    Then the output should contain exactly:
+      """
+      <table>
+      <thead>
+      <tr><th>#</th><th>Title</th><th>Author</th><th>Labels</th></tr>
+      </thead>
+      <tbody>
+      <tr><td><a href="https://github.com/github/hub/issues/102">#102</a></td><td>Escape &lt;b&gt;tags&lt;/b&gt;</td><td>lascap</td><td>bug, ui</td></tr>
+      </tbody>
+      </table>\n
+      """
+
+  Scenario: Invalid export column
+    When I run `hub issue --output markdown --columns number,priority`
+    Then the exit status should be 1
+    And the stderr should contain exactly:
+      """
+      invalid column: "priority"
+      (available columns: number, title, state, author, assignee, labels, milestone, age, created, updated, url)\n
+      """
+
+  Scenario: Export columns require an output format
+    When I run `hub issue --columns number,title`
+    Then the exit status should be 5
+    And the stderr should contain "the '--columns' option requires '--output'"
+
   Scenario: List all assignees
     Given the GitHub API server:
     """
@@ -381,6 +679,225 @@ Feature: hub issue
       https://github.com/github/hub/issues/1337\n
       """
 
+  Scenario: Create an issue with milestone by name
+    Given the GitHub API server:
+      """
+      get('/repos/github/hub/milestones') {
+        json [{ :number => 11, :title => "v1.0" }, { :number => 12, :title => "Next Release" }]
+      }
+      post('/repos/github/hub/issues') {
+        assert :title => "hello",
+               :milestone => 12
+
+        status 201
+        json :html_url => "https://github.com/github/hub/issues/1337"
+      }
+      """
+    When I successfully run `hub issue create -m "hello" -M "next release"`
+    Then the output should contain exactly:
+      """
+      https://github.com/github/hub/issues/1337\n
+      """
+
+  Scenario: Create an issue in a project column
+    Given the GitHub API server:
+      """
+      post('/repos/github/hub/issues') {
+        status 201
+        json :id => 4242, :html_url => "https://github.com/github/hub/issues/1337"
+      }
+      get('/repos/github/hub/projects') {
+        json [{ :id => 7, :name => "Roadmap" }]
+      }
+      get('/projects/7/columns') {
+        json [{ :id => 70, :name => "Backlog" }, { :id => 71, :name => "In progress" }]
+      }
+      post('/projects/columns/71/cards') {
+        assert :content_id => 4242, :content_type => "Issue"
+        status 201
+        json({})
+      }
+      """
+    When I successfully run `hub issue create -m "hello" --project "Roadmap:in progress"`
+    Then the output should contain exactly:
+      """
+      https://github.com/github/hub/issues/1337\n
+      """
+
+  Scenario: Issue is created even if the project column is missing
+    Given the GitHub API server:
+      """
+      post('/repos/github/hub/issues') {
+        status 201
+        json :id => 4242, :html_url => "https://github.com/github/hub/issues/1337"
+      }
+      get('/repos/github/hub/projects') {
+        json [{ :id => 7, :name => "Roadmap" }]
+      }
+      get('/projects/7/columns') {
+        json [{ :id => 70, :name => "Backlog" }]
+      }
+      """
+    When I successfully run `hub issue create -m "hello" --project "Roadmap:Done"`
+    Then the output should contain exactly:
+      """
+      https://github.com/github/hub/issues/1337\n
+      """
+    And the stderr should contain exactly "warning: the issue was created, but couldn't be added to project 'Roadmap': no column named 'Done' in project 'Roadmap'; its columns are: Backlog\n"
+
+  Scenario: Create an issue in a project with noop
+    When I successfully run `hub --noop issue create -m "hello" -M "next release" --project "Roadmap:Backlog"`
+    Then the output should contain exactly:
+      """
+      Would create issue `hello' for github/hub
+      Would add it to milestone `next release'
+      Would add it to column `Backlog' of project `Roadmap'\n
+      """
+
+  Scenario: Dry run of an issue
+    Given the GitHub API server:
+      """
+      get('/repos/github/hub/milestones') {
+        json [{ :number => 12, :title => "v2.0" }]
+      }
+      """
+    When I successfully run `hub issue create --dry-run=json -m "hello" -M 12 -l docs -a mislav`
+    Then the output should contain exactly:
+      """
+      {
+        "repository": "github/hub",
+        "title": "hello",
+        "body": "",
+        "labels": [
+          "docs"
+        ],
+        "assignees": [
+          "mislav"
+        ],
+        "milestone": {
+          "number": 12,
+          "title": "v2.0"
+        }
+      }\n
+      """
+
+  Scenario: Issue from a commit
+    Given the git commit editor is "vim"
+    And the text editor adds:
+      """
+      Follow up on the revert
+      """
+    And a file named "cache.go" with:
+      """
+      package cache
+      """
+    And I successfully run `git add cache.go`
+    And I successfully run `git commit -qm "Revert caching" -m "It broke the build."`
+    When I successfully run `hub issue create --from-commit HEAD --dry-run`
+    Then the output should contain "Title:      Follow up on the revert\n"
+    And the output should contain:
+      """
+      Revert caching
+
+      It broke the build.
+
+      https://github.com/github/hub/commit/
+      """
+    And the output should contain:
+      """
+      ```
+       cache.go | 1 +
+       1 file changed, 1 insertion(+)
+      ```
+      """
+
+  Scenario: Issue from a line of a file
+    Given the git commit editor is "vim"
+    And the text editor adds:
+      """
+      Handle errors
+      """
+    And a file named "lib/main.go" with:
+      """
+      package main
+
+      func main() {
+      	// FIXME: handle errors
+      	run()
+      }
+      """
+    And I successfully run `git add lib/main.go`
+    And I successfully run `git commit -qm main`
+    And I cd to "lib"
+    When I successfully run `hub issue create --from-line main.go:4 --dry-run`
+    Then the output should contain:
+      """
+      FIXME: handle errors
+
+      https://github.com/github/hub/blob/
+      """
+    And the output should contain:
+      """
+      /lib/main.go#L4
+
+      ```
+
+      func main() {
+      	// FIXME: handle errors
+      	run()
+      }
+      ```
+      """
+
+  Scenario: Issue from a line past the end of a file
+    Given a file named "notes.txt" with:
+      """
+      TODO
+      """
+    And I successfully run `git add notes.txt`
+    And I successfully run `git commit -qm notes`
+    When I run `hub issue create --from-line notes.txt:3`
+    Then the exit status should be 1
+    And the stderr should contain exactly "line 3 is past the end of the file\n"
+
+  Scenario: Issue from a commit can't be combined with a message
+    When I run `hub issue create --from-commit HEAD -m hello`
+    Then the exit status should be 5
+    And the stderr should contain "the '--from-commit' option can't be combined with '--message' or '--file'"
+
+  Scenario: Create an issue in an archived repository
+    Given the git commit editor is "vim"
+    And the text editor exits with error status
+    Given the GitHub API server:
+      """
+      get('/repos/github/hub') {
+        json :name => "hub", :archived => true
+      }
+      post('/repos/github/hub/issues') {
+        halt 400
+      }
+      """
+    When I run `hub issue create`
+    Then the stderr should contain exactly "Aborted: repository github/hub is archived (read-only)\n"
+    And the exit status should be 1
+
+  Scenario: Create an issue in a disabled repository
+    Given the GitHub API server:
+      """
+      get('/repos/github/hub') {
+        json :name => "hub", :disabled => true
+      }
+      post('/repos/github/hub/issues') {
+        status 201
+        json :html_url => "https://github.com/github/hub/issues/1337"
+      }
+      """
+    When I run `hub issue create -m hello`
+    Then the stderr should contain exactly "Aborted: repository github/hub is disabled\n(use `--force` to try anyway)\n"
+    And the exit status should be 1
+    When I successfully run `hub issue create -m hello --force`
+    Then the output should contain exactly "https://github.com/github/hub/issues/1337\n"
+
   Scenario: Editing empty issue message
     Given the git commit editor is "vim"
     And the text editor adds:
@@ -405,6 +922,41 @@ Feature: hub issue
       https://github.com/github/hub/issues/1337\n
       """
 
+  Scenario: Editor hints with a custom comment character
+    Given the git commit editor is "vim"
+    And git "hub.editorHints" is set to "true"
+    And git "core.commentChar" is set to ";"
+    And the text editor adds:
+      """
+      hello
+
+      cc @mislav
+      """
+    Given the GitHub API server:
+      """
+      get('/repos/github/hub/assignees') {
+        json [{ :login => "mislav" }, { :login => "josh" }]
+      }
+      get('/repos/github/hub/issues') {
+        assert :state => "all",
+               :sort => "updated",
+               :direction => "desc"
+        json [{ :number => 12, :title => "Fix the build" }]
+      }
+      post('/repos/github/hub/issues') {
+        assert :title => "hello",
+               :body => "cc @mislav"
+
+        status 201
+        json :html_url => "https://github.com/github/hub/issues/1337"
+      }
+      """
+    When I successfully run `hub issue create`
+    Then the output should contain exactly:
+      """
+      https://github.com/github/hub/issues/1337\n
+      """
+
   Scenario: Issue template
     Given the git commit editor is "vim"
     And the text editor adds:
@@ -626,6 +1178,58 @@ Feature: hub issue
       I did the thing\n
       """
 
+  Scenario: Show issue with images
+    Given the GitHub API server:
+      """
+      get('/repos/github/hub/issues/102') {
+        json \
+          :number => 102,
+          :state => "open",
+          :body => "It crashes:\n\n![stack trace](https://user-images.githubusercontent.com/1/trace.png)",
+          :title => "Crash on start",
+          :created_at => "2017-04-14T16:00:49Z",
+          :user => { :login => "royels" },
+          :comments => 1
+      }
+      get('/repos/github/hub/issues/102/comments') {
+        json [
+          { :body => "Same here <img alt=\"error dialog\" src=\"https://x.test/dialog.png\">",
+            :created_at => "2017-04-15T16:00:49Z",
+            :user => { :login => "octocat" }
+          },
+        ]
+      }
+      """
+    When I successfully run `hub issue show 102`
+    Then the output should contain exactly:
+      """
+      # Crash on start
+
+      * created by @royels on 2017-04-14 16:00:49 +0000 UTC
+
+      It crashes:
+
+      [image: stack trace]
+          https://user-images.githubusercontent.com/1/trace.png
+
+      ## Comments:
+
+      ### comment by @octocat on 2017-04-15 16:00:49 +0000 UTC
+
+      Same here [image: error dialog]
+          https://x.test/dialog.png\n
+      """
+
+  Scenario: Open issue in the browser
+    When I successfully run `hub issue show 102 --web`
+    Then there should be no output
+    And "open https://github.com/github/hub/issues/102" should be run
+
+  Scenario: Open issue in the browser conflicts with JSON output
+    When I run `hub issue show 102 --web --json`
+    Then the exit status should be 5
+    And the stderr should contain "the '--web' and '--json' options are mutually exclusive"
+
   Scenario: Format single issue
     Given the GitHub API server:
       """
@@ -681,9 +1285,69 @@ Feature: hub issue
       Feature request % hub%t%n\n
       """
 
+  Scenario: Show issue as JSON
+    Given the GitHub API server:
+      """
+      get('/repos/github/hub/issues/102') {
+        json \
+          :number => 102,
+          :state => "open",
+          :title => "Feature request",
+          :user => { :login => "royels" },
+          :labels => [{ :name => "feature", :color => "cfcfcf" }],
+          :assignees => [{:login => "royels"}],
+          :created_at => "2017-04-14T16:00:49Z"
+      }
+      """
+    When I successfully run `hub issue show 102 --json=number,created_at,labels,assignees,milestone`
+    Then the output should contain exactly:
+      """
+      {
+        "assignees": [
+          "royels"
+        ],
+        "createdAt": "2017-04-14T16:00:49Z",
+        "labels": [
+          "feature"
+        ],
+        "milestone": null,
+        "number": 102
+      }\n
+      """
+
+  Scenario: Query a field of an issue
+    Given the GitHub API server:
+      """
+      get('/repos/github/hub/issues/102') {
+        json \
+          :number => 102,
+          :state => "open",
+          :title => "Feature request",
+          :user => { :login => "royels" },
+          :labels => [{ :name => "feature", :color => "cfcfcf" }]
+      }
+      """
+    When I successfully run `hub issue show 102 -q labels.0`
+    Then the output should contain exactly "feature\n"
+
+  Scenario: Unknown JSON field
+    Given the GitHub API server:
+      """
+      get('/repos/github/hub/issues/102') {
+        json :number => 102
+      }
+      """
+    When I run `hub issue show 102 --json=number,headRefName`
+    Then the exit status should be 1
+    And the stderr should contain exactly:
+      """
+      unknown JSON field: "headRefName"
+      available fields: number, title, state, body, url, author, assignees, labels, milestone, comments, createdAt, updatedAt\n
+      """
+
   Scenario: Did not supply an issue number
     When I run `hub issue show`
-    Then the exit status should be 1
+    Then the exit status should be 5
     Then the stderr should contain "Usage: hub issue"
 
   Scenario: Show error message if http code is not 200 for issues endpoint
@@ -719,3 +1383,13 @@ Feature: hub issue
       """
       Error fetching comments for issue: Not Found (HTTP 404)\n
       """
+
+  Scenario: Invalid sort key is rejected before any request
+    When I run `hub issue -o bogus`
+    Then the exit status should be 5
+    And the stderr should contain "invalid value 'bogus' for --sort (expected: created, updated, comments, reactions, number)"
+
+  Scenario: Invalid limit
+    When I run `hub issue -L all`
+    Then the exit status should be 5
+    And the stderr should contain "invalid value 'all' for --limit (expected: a whole number)"
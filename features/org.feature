@@ -0,0 +1,96 @@
+Feature: hub org and hub team
+  Background:
+    Given I am "mislav" on github.com with OAuth token "OTOKEN"
+
+  Scenario: List organization members
+    Given the GitHub API server:
+      """
+      get('/orgs/acme/members') {
+        assert :role => "admin", :per_page => "100"
+        response.headers['X-OAuth-Scopes'] = 'repo, read:org'
+        if params[:page] == "2"
+          json [{ :login => "hubot" }]
+        else
+          response.headers['Link'] = %(<https://api.github.com/orgs/acme/members?role=admin&per_page=100&page=2>; rel="next")
+          json [{ :login => "mislav" }]
+        end
+      }
+      """
+    When I successfully run `hub org members --role admin acme`
+    Then the output should contain exactly:
+      """
+      mislav
+      hubot\n
+      """
+
+  Scenario: List organization members with names
+    Given the GitHub API server:
+      """
+      get('/orgs/acme/members') {
+        json [{ :login => "mislav" }, { :login => "hubot" }]
+      }
+      get('/users/mislav') { json :login => "mislav", :name => "Mislav Marohnić" }
+      get('/users/hubot') { json :login => "hubot", :name => "Hubot" }
+      """
+    When I successfully run `hub org members --detailed acme`
+    Then the output should contain exactly:
+      """
+      mislav  Mislav Marohnić
+      hubot   Hubot\n
+      """
+
+  Scenario: Token without the scope for private members
+    Given the GitHub API server:
+      """
+      get('/orgs/acme/members') {
+        response.headers['X-OAuth-Scopes'] = 'repo'
+        json [{ :login => "mislav" }]
+      }
+      """
+    When I run `hub org members acme`
+    Then the exit status should be 1
+    And the stderr should contain exactly:
+      """
+      can't see private members of acme: the access token lacks the 'read:org' scope\n
+      """
+
+  Scenario: Check organization membership
+    Given the GitHub API server:
+      """
+      get('/orgs/acme/members/mislav') { status 204 }
+      get('/orgs/acme/members/octocat') { status 404 }
+      """
+    When I run `hub org members --check mislav acme`
+    Then the exit status should be 0
+    When I run `hub org members --check octocat acme`
+    Then the exit status should be 1
+    And the output should contain exactly ""
+
+  Scenario: List team members
+    Given the GitHub API server:
+      """
+      get('/orgs/acme/teams/security/members') {
+        assert :role => "maintainer"
+        json [{ :login => "mislav" }]
+      }
+      """
+    When I successfully run `hub team members --role maintainer acme/security`
+    Then the output should contain exactly:
+      """
+      mislav\n
+      """
+
+  Scenario: Pending team invitations don't count as membership
+    Given the GitHub API server:
+      """
+      get('/orgs/acme/teams/security/memberships/hubot') {
+        json :state => "pending", :role => "member"
+      }
+      """
+    When I run `hub team members --check hubot acme/security`
+    Then the exit status should be 1
+
+  Scenario: Invalid team
+    When I run `hub team members acme`
+    Then the exit status should be 5
+    And the stderr should contain "expected <ORG>/<TEAM>, got \"acme\""
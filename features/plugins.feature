@@ -0,0 +1,49 @@
+Feature: hub plugins
+  Background:
+    Given I am in "git://github.com/mislav/dotfiles.git" git repo
+    And I am "mislav" on github.com with OAuth token "OTOKEN"
+    And an executable named "hub-triage" on PATH with:
+      """
+      echo "triage $*"
+      echo "repo=$HUB_REPO host=$HUB_HOST branch=$HUB_DEFAULT_BRANCH token=${HUB_TOKEN:-none}"
+      """
+
+  Scenario: Runs a plugin
+    When I successfully run `hub triage --label bug`
+    Then the output should contain exactly:
+      """
+      triage --label bug
+      repo=mislav/dotfiles host=github.com branch=master token=none\n
+      """
+
+  Scenario: Exposes the access token when allowed
+    Given git "hub.exposeTokenToPlugins" is set to "true"
+    When I successfully run `hub triage`
+    Then the output should contain "token=OTOKEN"
+
+  Scenario: Help for a plugin
+    When I successfully run `hub help triage`
+    Then the output should contain "triage --help"
+    When I successfully run `hub triage --help`
+    Then the output should contain "triage --help"
+
+  Scenario: Lists plugins in help
+    When I successfully run `hub help`
+    Then the output should contain:
+      """
+      These commands are provided by plugins:
+
+         triage
+      """
+
+  Scenario: Built-in commands take precedence
+    Given an executable named "hub-ci-status" on PATH with:
+      """
+      echo "plugin"
+      """
+    When I run `hub ci-status --help`
+    Then the stderr should contain "warning: ignoring "
+    And the stderr should contain "same name as the built-in `ci-status' command"
+    And the output should not contain "plugin"
+    When I successfully run `hub help`
+    Then the output should not contain "   ci-status\n"
@@ -30,6 +30,35 @@ Feature: hub pr checkout <PULLREQ-NUMBER>
     And "git checkout fixes" should be run
     And "fixes" should merge "refs/pull/77/head" from remote "origin"
 
+  Scenario: Checkout a pull request by URL
+    Given the GitHub API server:
+      """
+      get('/repos/mislav/jekyll/pulls/77') {
+        json :number => 77, :head => {
+          :ref => "fixes",
+          :repo => {
+            :owner => { :login => "hubot" },
+            :name => "jekyll",
+            :html_url => "https://github.com/hubot/jekyll",
+            :private => false
+          }
+        }, :base => {
+          :repo => {
+            :name => 'jekyll',
+            :html_url => 'https://github.com/mislav/jekyll',
+            :owner => { :login => "mislav" },
+          }
+        },
+        :maintainer_can_modify => false,
+        :html_url => 'https://github.com/mislav/jekyll/pull/77'
+      }
+      """
+    When I successfully run `hub pr checkout https://github.com/mislav/jekyll/pull/77 hubot-fixes`
+    Then "git remote add hubot git://github.com/hubot/jekyll.git" should be run
+    And "git fetch hubot +refs/heads/fixes:refs/remotes/hubot/fixes" should be run
+    And "git checkout -b hubot-fixes --no-track hubot/fixes" should be run
+    And "hubot-fixes" should merge "refs/heads/fixes" from remote "hubot"
+
   Scenario: Custom name for new branch
     Given the GitHub API server:
       """
@@ -80,3 +109,114 @@ Feature: hub pr checkout <PULLREQ-NUMBER>
     Then "git fetch origin +refs/heads/fixes:refs/remotes/origin/fixes" should be run
     And "git checkout -b fixes --no-track origin/fixes" should be run
     And "fixes" should merge "refs/heads/fixes" from remote "origin"
+
+  Scenario: Record the pull request in the branch description
+    Given the GitHub API server:
+      """
+      get('/repos/mojombo/jekyll/pulls/77') {
+        json :number => 77, :title => "Fix the fixes", :head => {
+          :ref => "fixes",
+          :repo => {
+            :name => "jekyll",
+            :owner => { :login => "mojombo" },
+          }
+        }, :base => {
+          :repo => {
+            :name => "jekyll",
+            :html_url => "https://github.com/mojombo/jekyll",
+            :owner => { :login => "mojombo" },
+          }
+        },
+        :labels => [{ :name => "bug" }, { :name => "docs" }],
+        :html_url => 'https://github.com/mojombo/jekyll/pull/77'
+      }
+      """
+    When I successfully run `hub pr checkout 77`
+    And I successfully run `git config branch.fixes.description`
+    Then the output should contain exactly:
+      """
+      #77: Fix the fixes
+      https://github.com/mojombo/jekyll/pull/77
+      Labels: bug, docs\n
+      """
+
+  Scenario: Keep an existing branch description
+    Given git "branch.fixes.description" is set to "my notes"
+    Given the GitHub API server:
+      """
+      get('/repos/mojombo/jekyll/pulls/77') {
+        json :number => 77, :title => "Fix the fixes", :head => {
+          :ref => "fixes",
+          :repo => {
+            :name => "jekyll",
+            :owner => { :login => "mojombo" },
+          }
+        }, :base => {
+          :repo => {
+            :name => "jekyll",
+            :html_url => "https://github.com/mojombo/jekyll",
+            :owner => { :login => "mojombo" },
+          }
+        },
+        :html_url => 'https://github.com/mojombo/jekyll/pull/77'
+      }
+      """
+    When I successfully run `hub pr checkout 77`
+    Then the stderr should contain "not replacing the existing description of branch 'fixes' (use `--force` to replace it)"
+    And "git config branch.fixes.description" should not be run
+
+  Scenario: Protect a checkout against pushing to the base repository
+    Given the GitHub API server:
+      """
+      get('/repos/mojombo/jekyll/pulls/77') {
+        json :number => 77, :head => {
+          :ref => "fixes",
+          :repo => {
+            :owner => { :login => "mislav" },
+            :name => "jekyll",
+            :private => false
+          }
+        }, :base => {
+          :repo => {
+            :name => 'jekyll',
+            :html_url => 'https://github.com/mojombo/jekyll',
+            :owner => { :login => "mojombo" },
+          }
+        },
+        :maintainer_can_modify => true,
+        :html_url => 'https://github.com/mojombo/jekyll/pull/77'
+      }
+      """
+    When I successfully run `hub pr checkout --protect 77`
+    Then "git config branch.fixes.pushRemote git@github.com:mislav/jekyll.git" should be run
+    And "git config branch.fixes.hubPushGuard origin" should be run
+    And the file ".git/hooks/pre-push" should contain "hub push guard for pull request checkouts"
+    When I successfully run `hub pr checkout --unprotect fixes`
+    Then the file ".git/hooks/pre-push" should not exist
+
+  Scenario: Same-repo pull requests can't be protected
+    Given the GitHub API server:
+      """
+      get('/repos/mojombo/jekyll/pulls/77') {
+        json :number => 77, :head => {
+          :ref => "fixes",
+          :repo => {
+            :name => "jekyll",
+            :owner => { :login => "mojombo" },
+          }
+        }, :base => {
+          :repo => {
+            :name => "jekyll",
+            :html_url => "https://github.com/mojombo/jekyll",
+            :owner => { :login => "mojombo" },
+          }
+        },
+        :html_url => 'https://github.com/mojombo/jekyll/pull/77'
+      }
+      """
+    When I run `hub pr checkout --protect 77`
+    Then the exit status should be 1
+    And the stderr should contain exactly:
+      """
+      Aborted: can't protect branch 'fixes': pull request #77 comes from the base repository\n
+      """
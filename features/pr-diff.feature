@@ -0,0 +1,74 @@
+Feature: hub pr diff
+  Background:
+    Given I am in "git://github.com/mojombo/jekyll.git" git repo
+    And I am "mojombo" on github.com with OAuth token "OTOKEN"
+    And the GitHub API server:
+      """
+      get('/repos/mojombo/jekyll/pulls/12') {
+        halt 400 unless request.env['HTTP_ACCEPT'] == 'application/vnd.github.v3.diff;charset=utf-8'
+        [
+          "diff --git a/lib/a.rb b/lib/a.rb",
+          "index f133d39..07c818d 100644",
+          "--- a/lib/a.rb",
+          "+++ b/lib/a.rb",
+          "@@ -1,3 +1,3 @@",
+          " def a",
+          "-return  1",
+          "+  return 1",
+          " end",
+          "diff --git a/logo.png b/logo.png",
+          "new file mode 100644",
+          "index 0000000..0a7e2a1",
+          "Binary files /dev/null and b/logo.png differ",
+          "diff --git a/old.txt b/new.txt",
+          "similarity index 50%",
+          "rename from old.txt",
+          "rename to new.txt",
+          "index 5626abf..814f4a4 100644",
+          "--- a/old.txt",
+          "+++ b/new.txt",
+          "@@ -1 +1,2 @@",
+          " one",
+          "+two",
+          "",
+        ].join("\n")
+      }
+      """
+
+  Scenario: Show the diff of a pull request
+    When I successfully run `hub pr diff 12 -- lib`
+    Then the output should contain exactly:
+      """
+      diff --git a/lib/a.rb b/lib/a.rb
+      index f133d39..07c818d 100644
+      --- a/lib/a.rb
+      +++ b/lib/a.rb
+      @@ -1,3 +1,3 @@
+       def a
+      -return  1
+      +  return 1
+       end\n
+      """
+
+  Scenario: Summarize the diff of a pull request
+    When I successfully run `hub pr diff --stat 12`
+    Then the output should contain exactly:
+      """
+       lib/a.rb           |   2 +-
+       logo.png           | Bin
+       old.txt => new.txt |   1 +
+       3 files changed, 2 insertions(+), 1 deletion(-)\n
+      """
+
+  Scenario: Ignore whitespace changes
+    When I successfully run `hub pr diff -w --stat 12 -- lib '*.txt'`
+    Then the output should contain exactly:
+      """
+       old.txt => new.txt | 1 +
+       1 file changed, 1 insertion(+)\n
+      """
+
+  Scenario: Invalid pull request number
+    When I run `hub pr diff jekyll`
+    Then the exit status should be 5
+    And the stderr should contain "invalid pull request number: \"jekyll\""
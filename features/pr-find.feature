@@ -0,0 +1,102 @@
+Feature: hub pr find
+  Background:
+    Given I am in "git://github.com/mojombo/jekyll.git" git repo
+    And I am "mojombo" on github.com with OAuth token "OTOKEN"
+
+  Scenario: Find the pull requests of a local commit
+    Given there is a commit named "the_sha"
+    Given the GitHub API server:
+      """
+      get(%r{/repos/mojombo/jekyll/commits/([0-9a-f]{40})/pulls}) {
+        halt 400 unless request.env['HTTP_ACCEPT'].include?("groot-preview")
+        json [
+          { :number => 7, :state => "open", :title => "Backport the fix",
+            :html_url => "https://github.com/mojombo/jekyll/pull/7" },
+          { :number => 123, :state => "closed", :title => "Fix the build",
+            :merged_at => "2020-01-01T00:00:00Z",
+            :html_url => "https://github.com/mojombo/jekyll/pull/123" },
+        ]
+      }
+      """
+    When I successfully run `hub pr find the_sha`
+    Then the output should contain exactly:
+      """
+      #7    open    Backport the fix  https://github.com/mojombo/jekyll/pull/7
+      #123  merged  Fix the build  https://github.com/mojombo/jekyll/pull/123\n
+      """
+
+  Scenario: Open the merged pull request
+    Given there is a commit named "the_sha"
+    Given the GitHub API server:
+      """
+      get(%r{/repos/mojombo/jekyll/commits/([0-9a-f]{40})/pulls}) {
+        json [
+          { :number => 7, :state => "open", :title => "Backport the fix",
+            :html_url => "https://github.com/mojombo/jekyll/pull/7" },
+          { :number => 123, :state => "closed", :title => "Fix the build",
+            :merged_at => "2020-01-01T00:00:00Z",
+            :html_url => "https://github.com/mojombo/jekyll/pull/123" },
+        ]
+      }
+      """
+    When I successfully run `hub pr find -o the_sha`
+    Then "open https://github.com/mojombo/jekyll/pull/123" should be run
+
+  Scenario: Abbreviated SHA of a commit that isn't available locally
+    Given the GitHub API server:
+      """
+      get('/repos/mojombo/jekyll/commits/abc1234') {
+        json :sha => "abc1234abc1234abc1234abc1234abc1234abc1"
+      }
+      get('/repos/mojombo/jekyll/commits/abc1234abc1234abc1234abc1234abc1234abc1/pulls') {
+        json [
+          { :number => 123, :state => "closed", :title => "Fix the build",
+            :merged_at => "2020-01-01T00:00:00Z",
+            :html_url => "https://github.com/mojombo/jekyll/pull/123" },
+        ]
+      }
+      """
+    When I successfully run `hub pr find abc1234`
+    Then the output should contain exactly:
+      """
+      #123  merged  Fix the build  https://github.com/mojombo/jekyll/pull/123\n
+      """
+
+  Scenario: Search on hosts without the endpoint
+    Given the GitHub API server:
+      """
+      get('/repos/mojombo/jekyll/commits/abc1234') {
+        json :sha => "abc1234abc1234abc1234abc1234abc1234abc1"
+      }
+      get('/repos/mojombo/jekyll/commits/abc1234abc1234abc1234abc1234abc1234abc1/pulls') {
+        status 415
+        json :message => "Unsupported Media Type"
+      }
+      get('/search/issues') {
+        assert :q => "repo:mojombo/jekyll is:pr abc1234abc1234abc1234abc1234abc1234abc1"
+        json :total_count => 1, :items => [
+          { :number => 123, :state => "closed", :title => "Fix the build",
+            :pull_request => { :merged_at => "2020-01-01T00:00:00Z" },
+            :html_url => "https://github.com/mojombo/jekyll/pull/123" },
+        ]
+      }
+      """
+    When I successfully run `hub pr find abc1234`
+    Then the output should contain exactly:
+      """
+      #123  merged  Fix the build  https://github.com/mojombo/jekyll/pull/123\n
+      """
+
+  Scenario: Commit without pull requests
+    Given the GitHub API server:
+      """
+      get('/repos/mojombo/jekyll/commits/abc1234') {
+        json :sha => "abc1234abc1234abc1234abc1234abc1234abc1"
+      }
+      get('/repos/mojombo/jekyll/commits/abc1234abc1234abc1234abc1234abc1234abc1/pulls') {
+        json []
+      }
+      """
+    When I run `hub pr find abc1234`
+    Then the exit status should be 3
+    And the stderr should contain exactly "no pull requests found for commit abc1234\n"
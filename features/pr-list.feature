@@ -63,6 +63,22 @@ Feature: hub pr list
             #7  Fourth\n
       """
 
+  Scenario: Saved filter shared with issues
+    Given I successfully run `git config --global hub.filter.triage "--state=closed --assignee=mislav --base=main"`
+    And the GitHub API server:
+    """
+    get('/repos/github/hub/pulls') {
+      assert :state => "open",
+             :base => "main"
+      json []
+    }
+    """
+    When I successfully run `hub pr list --filter triage -s open`
+    Then the stderr should contain exactly:
+      """
+      Note: ignoring '--assignee=mislav' of filter 'triage', which doesn't apply to `hub pr list`\n
+      """
+
   Scenario: List pull requests with requested reviewers
     Given the GitHub API server:
     """
@@ -163,11 +179,130 @@ Feature: hub pr list
       8 \e[31m closed \e[m\n
       """
 
+  Scenario: Show commits ahead and behind the base
+    Given the GitHub API server:
+    """
+    get('/repos/github/hub/pulls') {
+      json [
+        { :number => 102,
+          :title => "Second",
+          :state => "open",
+          :base => { :ref => "master", :label => "github:master",
+                     :repo => { :name => "hub", :owner => { :login => "github" },
+                                :html_url => "https://github.com/github/hub" } },
+          :head => { :ref => "patch-2", :label => "octocat:patch-2", :sha => "def456" },
+          :user => { :login => "octocat" },
+        },
+      ]
+    }
+    get('/repos/github/hub/compare/master...def456') {
+      json :ahead_by => 2, :behind_by => 5, :total_commits => 2
+    }
+    """
+    When I successfully run `hub pr list -f "%I %ab%n"`
+    Then the output should contain exactly "102 +2 -5\n"
+
+  Scenario: Show checks and review decision
+    Given the GitHub API server:
+    """
+    get('/repos/github/hub/pulls') {
+      json [
+        { :number => 102,
+          :title => "Second",
+          :state => "open",
+          :base => { :ref => "master", :label => "github:master" },
+          :head => { :ref => "patch-2", :label => "octocat:patch-2" },
+          :user => { :login => "octocat" },
+        },
+        { :number => 13,
+          :title => "Third",
+          :state => "open",
+          :base => { :ref => "master", :label => "github:master" },
+          :head => { :ref => "patch-3", :label => "octocat:patch-3" },
+          :user => { :login => "octocat" },
+        },
+      ]
+    }
+    post('/graphql') {
+      halt 400 unless params[:query].include?("pr102: pullRequest(number: 102)") &&
+        params[:query].include?("pr13: pullRequest(number: 13)")
+      assert :variables => { "owner" => "github", "name" => "hub" }
+      json :data => { :repository => {
+        :pr102 => { :number => 102, :reviewDecision => "APPROVED",
+          :commits => { :nodes => [{ :commit => { :statusCheckRollup => { :contexts => { :nodes => [
+            { :__typename => "CheckRun", :name => "build", :status => "COMPLETED", :conclusion => "SUCCESS" },
+          ] } } } }] } },
+        :pr13 => { :number => 13, :reviewDecision => "REVIEW_REQUIRED",
+          :commits => { :nodes => [{ :commit => { :statusCheckRollup => { :contexts => { :nodes => [
+            { :__typename => "CheckRun", :name => "build", :status => "COMPLETED", :conclusion => "FAILURE" },
+            { :__typename => "StatusContext", :context => "ci/legacy", :state => "PENDING" },
+          ] } } } }] } },
+      } }
+    }
+    """
+    When I successfully run `hub pr list -f "%I %cs %rd%n"`
+    Then the output should contain exactly:
+      """
+      102 success APPROVED
+      13 failure REVIEW_REQUIRED\n
+      """
+
+  Scenario: List pull requests that are ready to merge
+    Given the GitHub API server:
+    """
+    get('/repos/github/hub/pulls') {
+      halt 400 if params[:per_page] != "100"
+      json [1, 2, 3, 4].map { |number|
+        { :number => number,
+          :title => "PR #{number}",
+          :state => "open",
+          :base => { :ref => "master", :label => "github:master" },
+          :head => { :ref => "patch-#{number}", :label => "octocat:patch-#{number}", :sha => "sha#{number}" },
+          :user => { :login => "octocat" },
+        }
+      }
+    }
+    get('/repos/github/hub/branches/master') {
+      json :protection => { :required_status_checks => { :contexts => ["build"] } }
+    }
+    get('/repos/github/hub/commits/:sha/status') {
+      state = params[:sha] == "sha1" ? "failure" : "success"
+      json :statuses => [{ :context => "build", :state => state }]
+    }
+    get('/repos/github/hub/commits/:sha/check-runs') {
+      json :check_runs => []
+    }
+    post('/graphql') {
+      number = params[:query][/pullRequest\(number: (\d+)\)/, 1].to_i
+      decision = number == 2 ? "CHANGES_REQUESTED" : "APPROVED"
+      json :data => { :repository => {
+        "pr#{number}" => { :number => number, :reviewDecision => decision, :commits => { :nodes => [] } },
+      } }
+    }
+    get('/repos/github/hub/pulls/:number') {
+      state = params[:number] == "3" ? "dirty" : "clean"
+      json :number => params[:number].to_i, :mergeable_state => state
+    }
+    """
+    When I successfully run `hub pr list -f "%I %req%n"`
+    Then the output should contain exactly:
+      """
+      4 ready
+      3 conflicts
+      2 blocked: reviews
+      1 blocked: checks\n
+      """
+    When I successfully run `hub pr list --ready-to-merge -f "%I %t%n"`
+    Then the output should contain exactly:
+      """
+      4 PR 4\n
+      """
+
   Scenario: Sort by number of comments ascending
     Given the GitHub API server:
     """
     get('/repos/github/hub/pulls') {
-      assert :sort => "comments",
+      assert :sort => "popularity",
              :direction => "asc"
 
       json []
@@ -176,6 +311,38 @@ Feature: hub pr list
     When I successfully run `hub pr list -o comments -^`
     Then the output should contain exactly ""
 
+  Scenario: Sort by reactions
+    Given the GitHub API server:
+    """
+    get('/search/issues') {
+      assert :q => "repo:github/hub is:pr is:open base:develop sort:reactions-desc",
+             :per_page => "2"
+      json :total_count => 5, :items => [
+        { :number => 7, :title => "Popular", :state => "open", :user => { :login => "octocat" },
+          :pull_request => { :merged_at => nil } },
+        { :number => 4, :title => "Liked", :state => "open", :user => { :login => "octocat" },
+          :pull_request => { :merged_at => nil } },
+      ]
+    }
+    """
+    When I successfully run `hub pr list -o reactions -b develop -L 2`
+    Then the stdout should contain exactly:
+      """
+            #7  Popular
+            #4  Liked\n
+      """
+    And the stderr should contain exactly "showing 2 of 5 pull requests\n"
+
+  Scenario: Invalid sort key
+    When I run `hub pr list -o stars`
+    Then the exit status should be 5
+    And the stderr should contain "invalid value 'stars' for --sort (expected: created, updated, popularity, long-running, comments, reactions, number)"
+
+  Scenario: Conflicting sort directions
+    When I run `hub pr list -^ --direction desc`
+    Then the exit status should be 5
+    And the stderr should contain "the '--sort-ascending' and '--direction desc' options are mutually exclusive"
+
   Scenario: Filter by base and head
     Given the GitHub API server:
     """
@@ -241,3 +408,149 @@ Feature: hub pr list
           #999  First
            #13  Third\n
       """
+
+  Scenario: Filter by labels and exclude assignees
+    Given the GitHub API server:
+    """
+    get('/repos/github/hub/pulls') {
+      json [
+        { :number => 999,
+          :title => "Unlabeled",
+          :state => "open",
+          :user => { :login => "octocat" },
+        },
+        { :number => 102,
+          :title => "Taken",
+          :state => "open",
+          :user => { :login => "octocat" },
+          :labels => [{ :name => "bug", :color => "ff0000" }],
+          :assignees => [{ :login => "hubot" }],
+        },
+        { :number => 42,
+          :title => "Up for grabs",
+          :state => "open",
+          :user => { :login => "octocat" },
+          :labels => [{ :name => "Bug", :color => "ff0000" }],
+        },
+      ]
+    }
+    """
+    When I successfully run `hub pr list -l bug --exclude-assignee hubot -f "%I %t%n"`
+    Then the output should contain exactly:
+      """
+      42 Up for grabs\n
+      """
+
+  Scenario: Count pull requests
+    Given the GitHub API server:
+    """
+    get('/search/issues') {
+      assert :q => "repo:github/hub is:pr is:merged base:develop",
+             :per_page => "1"
+
+      json :total_count => 37, :items => []
+    }
+    """
+    When I successfully run `hub pr list --count-only -s merged -b develop`
+    Then the output should contain exactly "37\n"
+
+  Scenario: Count pull requests by head
+    Given the GitHub API server:
+    """
+    get('/repos/github/hub/pulls') {
+      assert :head => "mislav:patch-1",
+             :per_page => "1"
+
+      response.headers["Link"] = %(<https://api.github.com/repositories/12345/pulls?per_page=1&page=2>; rel="next", <https://api.github.com/repositories/12345/pulls?per_page=1&page=3>; rel="last")
+      json [{ :number => 102 }]
+    }
+    """
+    When I successfully run `hub pr list --count-only -h mislav:patch-1`
+    Then the output should contain exactly "3\n"
+
+  Scenario: Note truncated listing
+    Given the GitHub API server:
+    """
+    get('/repos/github/hub/pulls') {
+      assert :per_page => "3"
+
+      json [
+        { :number => 999,
+          :title => "First",
+          :state => "open",
+          :base => { :ref => "master", :label => "github:master" },
+          :head => { :ref => "patch-1", :label => "octocat:patch-1" },
+          :user => { :login => "octocat" },
+        },
+        { :number => 102,
+          :title => "Second",
+          :state => "open",
+          :base => { :ref => "master", :label => "github:master" },
+          :head => { :ref => "patch-2", :label => "octocat:patch-2" },
+          :user => { :login => "octocat" },
+        },
+      ]
+    }
+    get('/search/issues') {
+      assert :q => "repo:github/hub is:pr is:open"
+      json :total_count => 12, :items => []
+    }
+    """
+    When I successfully run `hub pr list -L 2`
+    Then the output should contain exactly:
+      """
+          #999  First
+          #102  Second\n
+      """
+    And the stderr should contain exactly "showing 2 of 12 pull requests\n"
+
+  Scenario: Export pull requests as a Markdown table
+    Given the GitHub API server:
+    """
+    get('/repos/github/hub/pulls') {
+      json [
+        { :number => 999,
+          :title => "Draft | WIP",
+          :state => "open",
+          :draft => true,
+          :base => { :ref => "master", :label => "github:master" },
+          :head => { :ref => "patch-1", :label => "octocat:patch-1" },
+          :user => { :login => "octocat" },
+          :html_url => "https://github.com/github/hub/pull/999",
+        },
+        { :number => 102,
+          :title => "Second",
+          :state => "closed",
+          :merged_at => "2020-02-10T14:59:59Z",
+          :base => { :ref => "master", :label => "github:master" },
+          :head => { :ref => "patch-2", :label => "octocat:patch-2" },
+          :user => { :login => "octocat" },
+          :html_url => "https://github.com/github/hub/pull/102",
+          :labels => [{ :name => "docs", :color => "cfcfcf" }],
+        },
+      ]
+    }
+    """
+    When I successfully run `hub pr list --output markdown --columns number,title,state,labels`
+    Then the output should contain exactly:
+      """
+      | # | Title | State | Labels |
+      | --- | --- | --- | --- |
+      | [#999](https://github.com/github/hub/pull/999) | Draft \| WIP | draft |  |
+      | [#102](https://github.com/github/hub/pull/102) | Second | merged | docs |\n
+      """
+
+  Scenario: Export is incompatible with a custom format
+    When I run `hub pr list --output html -f "%I%n"`
+    Then the exit status should be 5
+    And the stderr should contain "the '--output' and '--format' options are mutually exclusive"
+
+  Scenario: Invalid state is rejected before any request
+    When I run `hub pr list --state=draft`
+    Then the exit status should be 5
+    And the stderr should contain "invalid value 'draft' for --state (expected: open, closed, merged, all)"
+
+  Scenario: Invalid watch interval
+    When I run `hub pr list --watch=often`
+    Then the exit status should be 5
+    And the stderr should contain "invalid value 'often' for --watch (expected: a number of seconds or a duration such as \"5m\")"
@@ -0,0 +1,434 @@
+Feature: hub pr merge
+  Background:
+    Given I am in "git://github.com/mojombo/jekyll.git" git repo
+    And I am "mojombo" on github.com with OAuth token "OTOKEN"
+
+  Scenario: Enable auto-merge
+    Given the GitHub API server:
+      """
+      get('/repos/mojombo/jekyll/pulls/77') {
+        json :number => 77, :node_id => "PR_77"
+      }
+      post('/graphql') {
+        halt 400 unless params[:query].include?("enablePullRequestAutoMerge")
+        assert :variables => { "id" => "PR_77", "method" => "SQUASH" }
+        json :data => {
+          :enablePullRequestAutoMerge => { :pullRequest => { :merged => false } }
+        }
+      }
+      """
+    When I successfully run `hub pr merge --auto --squash 77`
+    Then the output should contain exactly:
+      """
+      Enabled auto-merge for pull request #77; it will be merged with the squash method once all requirements are met\n
+      """
+
+  Scenario: Pull request that can be merged right away
+    Given the GitHub API server:
+      """
+      get('/repos/mojombo/jekyll/pulls/77') {
+        json :number => 77, :node_id => "PR_77"
+      }
+      post('/graphql') {
+        json :data => { :enablePullRequestAutoMerge => nil },
+             :errors => [{ :message => "Pull request Pull request is in clean status" }]
+      }
+      put('/repos/mojombo/jekyll/pulls/77/merge') {
+        assert :merge_method => "merge"
+        json :merged => true
+      }
+      """
+    When I successfully run `hub pr merge --auto 77`
+    Then the output should contain exactly:
+      """
+      Merged pull request #77 right away since it meets all requirements\n
+      """
+
+  Scenario: Auto-merge not allowed in the repository
+    Given the GitHub API server:
+      """
+      get('/repos/mojombo/jekyll/pulls/77') {
+        json :number => 77, :node_id => "PR_77"
+      }
+      post('/graphql') {
+        json :data => { :enablePullRequestAutoMerge => nil },
+             :errors => [{ :message => "Pull request Auto merge is not allowed for this repository" }]
+      }
+      """
+    When I run `hub pr merge --auto 77`
+    Then the exit status should be 1
+    And the stderr should contain exactly:
+      """
+      auto-merge isn't allowed in mojombo/jekyll; allow it in the settings of the repository, e.g. with:
+        hub api -X PATCH repos/mojombo/jekyll -F allow_auto_merge=true\n
+      """
+
+  Scenario: Disable auto-merge
+    Given the GitHub API server:
+      """
+      get('/repos/mojombo/jekyll/pulls/77') {
+        json :number => 77, :node_id => "PR_77"
+      }
+      post('/graphql') {
+        halt 400 unless params[:query].include?("disablePullRequestAutoMerge")
+        assert :variables => { "id" => "PR_77" }
+        json :data => {
+          :disablePullRequestAutoMerge => { :pullRequest => { :number => 77 } }
+        }
+      }
+      """
+    When I successfully run `hub pr merge --disable-auto 77`
+    Then the output should contain exactly:
+      """
+      Disabled auto-merge for pull request #77\n
+      """
+
+  Scenario: Merge method with disabling
+    When I run `hub pr merge --disable-auto --rebase 77`
+    Then the exit status should be 5
+    And the stderr should contain "'--rebase' can't be combined with '--disable-auto'"
+
+  Scenario: Mark a draft as ready for review
+    Given the GitHub API server:
+      """
+      get('/repos/mojombo/jekyll/pulls/77') {
+        json :number => 77, :node_id => "PR_77", :draft => true
+      }
+      post('/graphql') {
+        halt 400 unless params[:query].include?("markPullRequestReadyForReview")
+        assert :variables => { "id" => "PR_77" }
+        json :data => {
+          :markPullRequestReadyForReview => { :pullRequest => { :isDraft => false } }
+        }
+      }
+      """
+    When I successfully run `hub pr ready 77`
+    Then the output should contain exactly:
+      """
+      Marked pull request #77 as ready for review\n
+      """
+
+  Scenario: Pull request that isn't a draft
+    Given the GitHub API server:
+      """
+      get('/repos/mojombo/jekyll/pulls/77') {
+        json :number => 77, :node_id => "PR_77", :draft => false
+      }
+      """
+    When I successfully run `hub pr ready 77`
+    Then the output should contain exactly:
+      """
+      Pull request #77 is already ready for review\n
+      """
+
+  Scenario: Merge a pull request and delete its branch
+    Given the GitHub API server:
+      """
+      get('/repos/mojombo/jekyll/pulls/77') {
+        json :number => 77, :state => "open", :mergeable_state => "clean",
+             :head => { :ref => "feature", :label => "mojombo:feature",
+                        :repo => { :name => "jekyll", :owner => { :login => "mojombo" } } },
+             :base => { :ref => "master", :label => "mojombo:master",
+                        :repo => { :name => "jekyll", :owner => { :login => "mojombo" } } }
+      }
+      put('/repos/mojombo/jekyll/pulls/77/merge') {
+        assert :merge_method => "squash",
+               :commit_title => "Add feature (#77)",
+               :commit_message => "With tests."
+        json :merged => true
+      }
+      delete('/repos/mojombo/jekyll/git/refs/heads/feature') {
+        status 204
+      }
+      """
+    When I successfully run `hub pr merge --squash -m "Add feature (#77)" -m "With tests." --delete-branch 77`
+    Then the output should contain exactly:
+      """
+      Merged pull request #77 with the squash method
+      Deleted branch feature\n
+      """
+
+  Scenario: Merge the pull request for a branch
+    Given the GitHub API server:
+      """
+      get('/repos/mojombo/jekyll/pulls') {
+        assert :head => "mojombo:topic", :state => "all"
+        json [
+          { :number => 7, :state => "open", :created_at => "2020-01-01T00:00:00Z" },
+        ]
+      }
+      get('/repos/mojombo/jekyll/pulls/7') {
+        json :number => 7, :state => "open", :mergeable_state => "clean"
+      }
+      put('/repos/mojombo/jekyll/pulls/7/merge') {
+        assert :merge_method => "merge", :commit_title => nil
+        json :merged => true
+      }
+      """
+    When I successfully run `hub pr merge topic`
+    Then the output should contain exactly:
+      """
+      Merged pull request #7 with the merge method\n
+      """
+
+  Scenario: Skip deleting the branch of a fork
+    Given the GitHub API server:
+      """
+      get('/repos/mojombo/jekyll/pulls/77') {
+        json :number => 77, :state => "open", :mergeable_state => "clean",
+             :head => { :ref => "feature", :label => "hubot:feature",
+                        :repo => { :name => "jekyll", :owner => { :login => "hubot" } } },
+             :base => { :ref => "master", :label => "mojombo:master",
+                        :repo => { :name => "jekyll", :owner => { :login => "mojombo" } } }
+      }
+      put('/repos/mojombo/jekyll/pulls/77/merge') {
+        json :merged => true
+      }
+      """
+    When I successfully run `hub pr merge --delete-branch 77`
+    Then the output should contain exactly:
+      """
+      Merged pull request #77 with the merge method\n
+      """
+    And the stderr should contain exactly "Skipped deleting the head branch of pull request #77, which belongs to another repository\n"
+
+  Scenario: Refuse to merge a draft
+    Given the GitHub API server:
+      """
+      get('/repos/mojombo/jekyll/pulls/77') {
+        json :number => 77, :state => "open", :draft => true
+      }
+      """
+    When I run `hub pr merge 77`
+    Then the exit status should be 1
+    And the stderr should contain exactly:
+      """
+      Aborted: pull request #77 is a draft
+      (use `hub pr ready 77` to mark it as ready for review)\n
+      """
+
+  Scenario: Refuse to merge a pull request with conflicts
+    Given the GitHub API server:
+      """
+      get('/repos/mojombo/jekyll/pulls/77') {
+        json :number => 77, :state => "open", :mergeable_state => "dirty"
+      }
+      """
+    When I run `hub pr merge 77`
+    Then the exit status should be 1
+    And the stderr should contain exactly "Aborted: pull request #77 has conflicts with its base branch that have to be resolved first\n"
+
+  Scenario: Merge method not allowed in the repository
+    Given the GitHub API server:
+      """
+      get('/repos/mojombo/jekyll/pulls/77') {
+        json :number => 77, :state => "open", :mergeable_state => "clean"
+      }
+      put('/repos/mojombo/jekyll/pulls/77/merge') {
+        status 405
+        json :message => "Rebase merges are not allowed on this repository."
+      }
+      """
+    When I run `hub pr merge --rebase 77`
+    Then the exit status should be 1
+    And the stderr should contain exactly:
+      """
+      Error merging pull request: Method Not Allowed (HTTP 405)
+      Rebase merges are not allowed on this repository.\n
+      """
+
+  Scenario: Merge with noop
+    When I successfully run `hub --noop pr merge --squash --delete-branch 77`
+    Then the output should contain exactly:
+      """
+      Would merge pull request #77 with the squash method
+      Would delete the head branch of pull request #77\n
+      """
+
+  Scenario: Add a pull request to a merge queue
+    Given the GitHub API server:
+      """
+      get('/repos/mojombo/jekyll/pulls/77') {
+        json :number => 77, :node_id => "PR_77", :state => "open", :mergeable_state => "clean",
+             :base => { :ref => "master", :label => "mojombo:master",
+                        :repo => { :name => "jekyll", :owner => { :login => "mojombo" } } }
+      }
+      get('/repos/mojombo/jekyll/rules/branches/master') {
+        json [{ :type => "merge_queue", :parameters => { :merge_method => "SQUASH" } }]
+      }
+      post('/graphql') {
+        halt 400 unless params[:query].include?("enqueuePullRequest")
+        assert :variables => { "id" => "PR_77" }
+        json :data => {
+          :enqueuePullRequest => { :mergeQueueEntry => { :position => 2, :state => "QUEUED" } }
+        }
+      }
+      """
+    When I successfully run `hub pr merge 77`
+    Then the output should contain exactly:
+      """
+      Pull request #77 added to merge queue at position 2\n
+      """
+
+  Scenario: Watch a pull request through the merge queue
+    Given the GitHub API server:
+      """
+      lookups = 0
+      get('/repos/mojombo/jekyll/pulls/77') {
+        json :number => 77, :node_id => "PR_77", :state => "open", :mergeable_state => "clean",
+             :head => { :ref => "feature", :label => "mojombo:feature",
+                        :repo => { :name => "jekyll", :owner => { :login => "mojombo" } } },
+             :base => { :ref => "master", :label => "mojombo:master",
+                        :repo => { :name => "jekyll", :owner => { :login => "mojombo" } } }
+      }
+      get('/repos/mojombo/jekyll/rules/branches/master') {
+        json [{ :type => "merge_queue" }]
+      }
+      post('/graphql') {
+        if params[:query].include?("enqueuePullRequest")
+          json :data => {
+            :enqueuePullRequest => { :mergeQueueEntry => { :position => 2, :state => "QUEUED" } }
+          }
+        else
+          assert :variables => { "owner" => "mojombo", "name" => "jekyll", "number" => 77 }
+          lookups += 1
+          entry = lookups < 3 ? { :position => 1, :state => "AWAITING_CHECKS" } : nil
+          json :data => { :repository => { :pullRequest => {
+            :merged => entry.nil?, :state => entry ? "OPEN" : "MERGED", :mergeQueueEntry => entry
+          } } }
+        end
+      }
+      delete('/repos/mojombo/jekyll/git/refs/heads/feature') {
+        status 204
+      }
+      """
+    When I successfully run `hub pr merge --watch=10ms --delete-branch 77`
+    Then the output should contain exactly:
+      """
+      Pull request #77 added to merge queue at position 2
+      Pull request #77 is at position 1 in the merge queue
+      Merged pull request #77
+      Deleted branch feature\n
+      """
+
+  Scenario: Pull request removed from the merge queue while watching
+    Given the GitHub API server:
+      """
+      get('/repos/mojombo/jekyll/pulls/77') {
+        json :number => 77, :node_id => "PR_77", :state => "open", :mergeable_state => "clean",
+             :base => { :ref => "master", :label => "mojombo:master",
+                        :repo => { :name => "jekyll", :owner => { :login => "mojombo" } } }
+      }
+      get('/repos/mojombo/jekyll/rules/branches/master') {
+        json [{ :type => "merge_queue" }]
+      }
+      post('/graphql') {
+        if params[:query].include?("enqueuePullRequest")
+          json :data => {
+            :enqueuePullRequest => { :mergeQueueEntry => { :position => 1, :state => "QUEUED" } }
+          }
+        else
+          json :data => { :repository => { :pullRequest => {
+            :merged => false, :state => "OPEN", :mergeQueueEntry => nil
+          } } }
+        end
+      }
+      """
+    When I run `hub pr merge --watch=10ms 77`
+    Then the exit status should be 1
+    And the output should contain exactly:
+      """
+      Pull request #77 added to merge queue at position 1
+      Pull request #77 was removed from the merge queue without being merged\n
+      """
+
+  Scenario: Pull request shows up as merged a moment after leaving the queue
+    Given the GitHub API server:
+      """
+      lookups = 0
+      get('/repos/mojombo/jekyll/pulls/77') {
+        json :number => 77, :node_id => "PR_77", :state => "open", :mergeable_state => "clean",
+             :base => { :ref => "master", :label => "mojombo:master",
+                        :repo => { :name => "jekyll", :owner => { :login => "mojombo" } } }
+      }
+      get('/repos/mojombo/jekyll/rules/branches/master') {
+        json [{ :type => "merge_queue" }]
+      }
+      post('/graphql') {
+        if params[:query].include?("enqueuePullRequest")
+          json :data => {
+            :enqueuePullRequest => { :mergeQueueEntry => { :position => 1, :state => "QUEUED" } }
+          }
+        else
+          lookups += 1
+          json :data => { :repository => { :pullRequest => {
+            :merged => lookups > 1, :state => lookups > 1 ? "MERGED" : "OPEN", :mergeQueueEntry => nil
+          } } }
+        end
+      }
+      """
+    When I successfully run `hub pr merge --watch=10ms 77`
+    Then the output should contain exactly:
+      """
+      Pull request #77 added to merge queue at position 1
+      Merged pull request #77\n
+      """
+
+  Scenario: Watching requires a merge queue
+    Given the GitHub API server:
+      """
+      get('/repos/mojombo/jekyll/pulls/77') {
+        json :number => 77, :node_id => "PR_77", :state => "open", :mergeable_state => "clean",
+             :base => { :ref => "master", :label => "mojombo:master",
+                        :repo => { :name => "jekyll", :owner => { :login => "mojombo" } } }
+      }
+      get('/repos/mojombo/jekyll/rules/branches/master') {
+        json []
+      }
+      """
+    When I run `hub pr merge --watch --notify 77`
+    Then the exit status should be 5
+    And the stderr should contain "the '--watch' option only applies to pull requests merged through a merge queue, and master has none"
+
+  Scenario: Deleting the branch of a queued pull request requires watching
+    Given the GitHub API server:
+      """
+      get('/repos/mojombo/jekyll/pulls/77') {
+        json :number => 77, :node_id => "PR_77", :state => "open", :mergeable_state => "clean",
+             :base => { :ref => "master", :label => "mojombo:master",
+                        :repo => { :name => "jekyll", :owner => { :login => "mojombo" } } }
+      }
+      get('/repos/mojombo/jekyll/rules/branches/master') {
+        json [{ :type => "merge_queue" }]
+      }
+      """
+    When I run `hub pr merge --delete-branch 77`
+    Then the exit status should be 1
+    And the stderr should contain exactly:
+      """
+      Aborted: pull request #77 would be merged later by the merge queue of master
+      (use `--watch` to delete the head branch once it's merged)\n
+      """
+
+  Scenario: Remove a pull request from the merge queue
+    Given the GitHub API server:
+      """
+      get('/repos/mojombo/jekyll/pulls/77') {
+        json :number => 77, :node_id => "PR_77"
+      }
+      post('/graphql') {
+        halt 400 unless params[:query].include?("dequeuePullRequest")
+        assert :variables => { "id" => "PR_77" }
+        json :data => { :dequeuePullRequest => { :mergeQueueEntry => { :state => "QUEUED" } } }
+      }
+      """
+    When I successfully run `hub pr merge --dequeue 77`
+    Then the output should contain exactly:
+      """
+      Removed pull request #77 from the merge queue\n
+      """
+
+  Scenario: Dequeue with a merge method
+    When I run `hub pr merge --dequeue --squash 77`
+    Then the exit status should be 5
+    And the stderr should contain "'--squash' can't be combined with '--dequeue'"
@@ -0,0 +1,56 @@
+Feature: hub pr number [<BRANCH>]
+  Background:
+    Given I am in "git://github.com/mojombo/jekyll.git" git repo
+    And I am "mojombo" on github.com with OAuth token "OTOKEN"
+
+  Scenario: Number of the pull request for the current branch
+    Given I am on the "feature" branch pushed to "origin/feature"
+    Given the GitHub API server:
+      """
+      get('/repos/mojombo/jekyll/pulls') {
+        assert :head => "mojombo:feature", :state => "all"
+        json [
+          { :number => 10, :state => "closed", :created_at => "2020-03-01T00:00:00Z" },
+          { :number => 12, :state => "open", :created_at => "2020-01-01T00:00:00Z" },
+        ]
+      }
+      """
+    When I successfully run `hub pr number`
+    Then the output should contain exactly "12\n"
+
+  Scenario: Number of the pull request for another branch
+    Given the GitHub API server:
+      """
+      get('/repos/mojombo/jekyll/pulls') {
+        assert :head => "mojombo:topic", :state => "all"
+        json [
+          { :number => 7, :state => "open", :created_at => "2020-01-01T00:00:00Z" },
+        ]
+      }
+      """
+    When I successfully run `hub pr number topic`
+    Then the output should contain exactly "7\n"
+
+  Scenario: Branch checked out from a pull request
+    Given I am on the "fixes" branch
+    When I successfully run `git config branch.fixes.remote origin`
+    When I successfully run `git config branch.fixes.merge refs/pull/77/head`
+    Given the GitHub API server:
+      """
+      get('/repos/mojombo/jekyll/pulls/77') {
+        json :number => 77, :state => "open"
+      }
+      """
+    When I successfully run `hub pr number`
+    Then the output should contain exactly "77\n"
+
+  Scenario: No pull request for the branch
+    Given the GitHub API server:
+      """
+      get('/repos/mojombo/jekyll/pulls') {
+        json []
+      }
+      """
+    When I run `hub pr number topic`
+    Then the exit status should be 1
+    And the stderr should contain exactly "no pull request found for branch 'topic'\n"
@@ -0,0 +1,96 @@
+Feature: hub pr review-comment
+  Background:
+    Given I am in "git://github.com/mislav/dotfiles.git" git repo
+    And I am "mislav" on github.com with OAuth token "OTOKEN"
+    Given the GitHub API server:
+      """
+      get('/repos/mislav/dotfiles/pulls/12') {
+        json :number => 12,
+             :head => { :sha => "abc123", :ref => "feature" },
+             :base => { :ref => "master" }
+      }
+      get('/repos/mislav/dotfiles/pulls/12/files') {
+        assert :per_page => "100"
+        json [
+          { :filename => "lib/app.rb",
+            :status => "modified",
+            :patch => "@@ -10,4 +10,5 @@ class App\n   def call\n-    old\n+    new\n+    newer\n   end\n@@ -40,1 +41,1 @@\n-  x\n+  y" },
+          { :filename => "logo.png",
+            :status => "added" },
+        ]
+      }
+      post('/repos/mislav/dotfiles/pulls/12/comments') {
+        assert :body => "Needs a test",
+               :commit_id => "abc123",
+               :path => "lib/app.rb",
+               :line => 13,
+               :side => "RIGHT",
+               :start_line => 11,
+               :start_side => "RIGHT",
+               :position => :no
+        status 201
+        json :html_url => "https://github.com/mislav/dotfiles/pull/12#discussion_r1"
+      }
+      post('/repos/mislav/dotfiles/pulls/12/comments/99/replies') {
+        assert :body => "Done"
+        status 201
+        json :html_url => "https://github.com/mislav/dotfiles/pull/12#discussion_r2"
+      }
+      """
+
+  Scenario: Comment on multiple lines
+    When I successfully run `hub pr review-comment 12 --path lib/app.rb --start-line 11 --line 13 -m "Needs a test"`
+    Then the output should contain exactly:
+      """
+      https://github.com/mislav/dotfiles/pull/12#discussion_r1\n
+      """
+
+  Scenario: Reply to a review comment
+    When I successfully run `hub pr review-comment 12 --in-reply-to 99 -m Done`
+    Then the output should contain exactly:
+      """
+      https://github.com/mislav/dotfiles/pull/12#discussion_r2\n
+      """
+
+  Scenario: Line outside of the diff
+    When I run `hub pr review-comment 12 --path lib/app.rb --line 20 -m "Needs a test"`
+    Then the exit status should be 1
+    And the stderr should contain exactly:
+      """
+      Aborted: line 20 of 'lib/app.rb' is not part of the diff on the RIGHT side
+      (lines in the diff: 10-13, 41)\n
+      """
+
+  Scenario: Deleted line on the left side
+    When I run `hub pr review-comment 12 --path lib/app.rb --line 13 --side left --start-line 10 -m "Why?"`
+    Then the exit status should be 1
+    And the stderr should contain exactly:
+      """
+      Aborted: line 13 of 'lib/app.rb' is not part of the diff on the LEFT side
+      (lines in the diff: 10-12, 40)\n
+      """
+
+  Scenario: Lines in different hunks
+    When I run `hub pr review-comment 12 --path lib/app.rb --start-line 12 --line 41 -m "Hmm"`
+    Then the exit status should be 1
+    And the stderr should contain exactly:
+      """
+      Aborted: lines 12 to 41 of 'lib/app.rb' are not in the same hunk of the diff on the RIGHT side
+      (lines in the hunk: 41)\n
+      """
+
+  Scenario: File not in the pull request
+    When I run `hub pr review-comment 12 --path README.md --line 1 -m "Hmm"`
+    Then the exit status should be 1
+    And the stderr should contain exactly:
+      """
+      Aborted: 'README.md' is not among the files changed in pull request #12\n
+      """
+
+  Scenario: Binary file
+    When I run `hub pr review-comment 12 --path logo.png --line 1 -m "Hmm"`
+    Then the exit status should be 1
+    And the stderr should contain exactly:
+      """
+      Aborted: the diff of 'logo.png' is not available for commenting\n
+      """
@@ -0,0 +1,178 @@
+Feature: hub pr show
+  Background:
+    Given I am in "git://github.com/mojombo/jekyll.git" git repo
+    And I am "mojombo" on github.com with OAuth token "OTOKEN"
+
+  Scenario: Show pull request
+    Given the GitHub API server:
+      """
+      get('/repos/mojombo/jekyll/pulls/77') {
+        json :number => 77,
+             :state => "closed",
+             :title => "Fix the build",
+             :body => "Pin the dependencies.",
+             :merged_at => "2018-05-01T10:00:00Z",
+             :created_at => "2018-04-30T16:00:49Z",
+             :user => { :login => "defunkt" },
+             :head => { :ref => "fixes", :label => "defunkt:fixes" },
+             :base => { :ref => "master", :label => "mojombo:master" },
+             :requested_reviewers => [{ :login => "mislav" }]
+      }
+      get('/repos/mojombo/jekyll/issues/77/comments') {
+        json []
+      }
+      """
+    When I successfully run `hub pr show 77`
+    Then the output should contain exactly:
+      """
+      # [MERGED] Fix the build
+
+      * created by @defunkt on 2018-04-30 16:00:49 +0000 UTC
+      * merges defunkt:fixes into master
+      * requested reviewers: mislav
+
+      Pin the dependencies.\n
+      """
+
+  Scenario: Open pull request in the browser
+    When I successfully run `hub pr show 77 --web`
+    Then there should be no output
+    And "open https://github.com/mojombo/jekyll/pull/77" should be run
+
+  Scenario: Query the head branch
+    Given the GitHub API server:
+      """
+      get('/repos/mojombo/jekyll/pulls/77') {
+        json :number => 77,
+             :head => { :ref => "fixes", :label => "defunkt:fixes", :sha => "abc123" },
+             :base => { :ref => "master", :label => "mojombo:master" }
+      }
+      """
+    When I successfully run `hub pr show 77 -q headRefName`
+    Then the output should contain exactly "fixes\n"
+
+  Scenario: Selected fields in snake case
+    Given the GitHub API server:
+      """
+      get('/repos/mojombo/jekyll/pulls/77') {
+        json :number => 77,
+             :draft => true,
+             :head => { :ref => "fixes", :label => "defunkt:fixes", :sha => "abc123" },
+             :base => { :ref => "master", :label => "mojombo:master" }
+      }
+      """
+    When I successfully run `hub pr show 77 --json=number,is_draft,head_ref_oid`
+    Then the output should contain exactly:
+      """
+      {
+        "headRefOid": "abc123",
+        "isDraft": true,
+        "number": 77
+      }\n
+      """
+
+  Scenario: JSON and format are mutually exclusive
+    When I run `hub pr show 77 --json -f "%I"`
+    Then the exit status should be 5
+    And the stderr should contain "the '--json' and '--format' options are mutually exclusive"
+
+  Scenario: Show how far an open pull request is ahead of and behind its base
+    Given the GitHub API server:
+      """
+      get('/repos/mojombo/jekyll/pulls/78') {
+        json :number => 78,
+             :state => "open",
+             :title => "Add feature",
+             :body => "Adds the feature.",
+             :created_at => "2018-04-30T16:00:49Z",
+             :additions => 1234, :deletions => 56, :changed_files => 7,
+             :user => { :login => "defunkt" },
+             :head => { :ref => "feature", :label => "defunkt:feature", :sha => "abc123" },
+             :base => { :ref => "master", :label => "mojombo:master",
+                        :repo => { :name => "jekyll", :owner => { :login => "mojombo" },
+                                   :html_url => "https://github.com/mojombo/jekyll" } }
+      }
+      get('/repos/mojombo/jekyll/compare/master...abc123') {
+        json :ahead_by => 3, :behind_by => 12, :total_commits => 3
+      }
+      get('/repos/mojombo/jekyll/issues/78/comments') {
+        json []
+      }
+      """
+    When I successfully run `hub pr show 78`
+    Then the output should contain exactly:
+      """
+      # Add feature
+
+      * created by @defunkt on 2018-04-30 16:00:49 +0000 UTC
+      * merges defunkt:feature into master
+      * 3 commits ahead, 12 behind master
+      * 7 files changed, +1.2k -56 lines
+
+      Adds the feature.\n
+      """
+    When I successfully run `hub pr show 78 -f "%i %ab%n"`
+    Then the output should contain exactly "#78 +3 -12\n"
+
+  Scenario: Show review threads
+    Given the GitHub API server:
+      """
+      get('/repos/mojombo/jekyll/pulls/77') {
+        json :number => 77,
+             :state => "closed",
+             :title => "Fix the build",
+             :body => "Pin the dependencies.",
+             :created_at => "2018-04-30T16:00:49Z",
+             :user => { :login => "defunkt" },
+             :head => { :ref => "fixes", :label => "defunkt:fixes" },
+             :base => { :ref => "master", :label => "mojombo:master" }
+      }
+      get('/repos/mojombo/jekyll/issues/77/comments') {
+        json []
+      }
+      post('/graphql') {
+        assert :variables => { :owner => "mojombo", :name => "jekyll", :number => 77 }
+        json :data => { :repository => { :pullRequest => { :reviewThreads => {
+          :pageInfo => { :hasNextPage => false },
+          :nodes => [
+            { :path => "Gemfile", :line => 3, :isResolved => false,
+              :comments => { :nodes => [
+                { :author => { :login => "mojombo" }, :body => "Why this version?", :createdAt => "2018-05-01T09:00:00Z" },
+                { :author => { :login => "defunkt" }, :body => "It's the last one that builds.", :createdAt => "2018-05-01T09:30:00Z" },
+              ] } },
+            { :path => "README.md", :line => 10, :isResolved => true,
+              :comments => { :nodes => [
+                { :author => { :login => "mojombo" }, :body => "Typo", :createdAt => "2018-05-01T09:10:00Z" },
+              ] } },
+          ]
+        } } } }
+      }
+      """
+    When I run `hub pr show 77 --threads --unresolved-only --fail-unresolved`
+    Then the exit status should be 1
+    And the output should contain exactly:
+      """
+      # [CLOSED] Fix the build
+
+      * created by @defunkt on 2018-04-30 16:00:49 +0000 UTC
+      * merges defunkt:fixes into master
+
+      Pin the dependencies.
+
+      ## Review threads:
+
+      ### Gemfile:3
+
+      @mojombo on 2018-05-01 09:00:00 +0000 UTC:
+      Why this version?
+
+          @defunkt on 2018-05-01 09:30:00 +0000 UTC:
+          It's the last one that builds.
+
+      1 unresolved of 2 threads\n
+      """
+
+  Scenario: Failing on unresolved threads requires showing them
+    When I run `hub pr show 77 --fail-unresolved`
+    Then the exit status should be 5
+    And the stderr should contain "the '--fail-unresolved' option requires '--threads'"
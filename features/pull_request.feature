@@ -26,9 +26,55 @@ Feature: hub pull-request
   Scenario: Detached HEAD
     Given I am in detached HEAD
     When I run `hub pull-request`
-    Then the stderr should contain "Aborted: not currently on any branch.\n"
+    Then the stderr should match /^Aborted: you are in detached HEAD state at [0-9a-f]{7}; check out a branch or pass an explicit head with '--head'$/
     And the exit status should be 1
 
+  Scenario: Detached HEAD with explicit head
+    Given I am in detached HEAD
+    Given the GitHub API server:
+      """
+      post('/repos/mislav/coral/pulls') {
+        assert :head => 'mislav:feature'
+        status 201
+        json :html_url => "the://url"
+      }
+      """
+    When I successfully run `hub pull-request -m hello --head feature`
+    Then the output should contain exactly "the://url\n"
+
+  Scenario: Branch without commits
+    Given I successfully run `git checkout --orphan fresh`
+    When I run `hub pull-request -m hello`
+    Then the stderr should contain exactly:
+      """
+      Aborted: branch 'fresh' has no commits yet; make a commit first or pass an explicit head with '--head'\n
+      """
+    And the exit status should be 1
+
+  Scenario: Sign off in the pull request description
+    Given I am on the "feature" branch pushed to "origin/feature"
+    Given the GitHub API server:
+      """
+      post('/repos/mislav/coral/pulls') {
+        assert :title => 'hello',
+               :body => "Details\n\nSigned-off-by: Hub <hub@test.local>"
+        status 201
+        json :html_url => "the://url"
+      }
+      """
+    When I successfully run `hub pull-request -m hello -m Details --signoff=append-body`
+    Then the output should contain exactly "the://url\n"
+
+  Scenario: Commits without sign-off
+    Given I am on the "feature" branch
+    And I successfully run `git commit --allow-empty --signoff -m signed`
+    And I successfully run `git commit --allow-empty -m unsigned`
+    And the "feature" branch is pushed to "origin/feature"
+    When I run `hub pull-request -m hello --signoff`
+    Then the exit status should be 1
+    And the stderr should contain "Aborted: 2 of 3 commits are not signed off by Hub <hub@test.local>:"
+    And the stderr should contain "(use `git rebase --signoff origin/master` to sign them off"
+
   Scenario: Non-GitHub repo
     Given the "origin" remote has url "mygh:Manganeez/repo.git"
     When I run `hub pull-request`
@@ -70,7 +116,7 @@ Feature: hub pull-request
   Scenario: Invalid flag
     When I run `hub pull-request -yelp`
     Then the stderr should contain "unknown shorthand flag: 'y' in -yelp\n"
-    And the exit status should be 1
+    And the exit status should be 5
 
   Scenario: With Unicode characters in the changelog
     Given the text editor adds:
@@ -121,6 +167,22 @@ Feature: hub pull-request
     When I successfully run `hub pull-request`
     Then the output should contain exactly "the://url\n"
 
+  Scenario: Pull request to an archived repository
+    Given the text editor exits with error status
+    Given the GitHub API server:
+      """
+      get('/repos/mislav/coral') {
+        json :name => "coral", :owner => { :login => "mislav" }, :archived => true
+      }
+      """
+    Given I am on the "master" branch pushed to "origin/master"
+    When I successfully run `git checkout --quiet -b topic`
+    And I make a commit
+    And the "topic" branch is pushed to "origin/topic"
+    When I run `hub pull-request -f`
+    Then the stderr should contain exactly "Aborted: repository mislav/coral is archived (read-only)\n"
+    And the exit status should be 1
+
   Scenario: Single-commit with pull request template
     Given the git commit editor is "true"
     Given the GitHub API server:
@@ -182,6 +244,89 @@ Feature: hub pull-request
     When I successfully run `hub pull-request`
     Then the output should contain exactly "the://url\n"
 
+  Scenario: Named pull request template with placeholders
+    Given the git commit editor is "true"
+    Given the GitHub API server:
+      """
+      post('/repos/mislav/coral/pulls') {
+        assert :title => 'Commit title',
+               :body => <<BODY.chomp
+      Commit body
+
+
+      Fixes a bug on topic
+
+      - Commit title
+
+      Closes #12
+      BODY
+        status 201
+        json :html_url => "the://url"
+      }
+      """
+    Given I am on the "master" branch pushed to "origin/master"
+    When I successfully run `git checkout --quiet -b topic`
+    And I make a commit with message:
+      """
+      Commit title
+
+      Commit body
+      """
+    And the "topic" branch is pushed to "origin/topic"
+    And a file named ".github/PULL_REQUEST_TEMPLATE/bugfix.md" with:
+      """
+      Fixes a bug on {{branch}}
+
+      {{commits}}
+
+      Closes #{{issue}}
+      """
+    When I successfully run `hub pull-request --template bugfix -i 12`
+    Then the output should contain exactly "the://url\n"
+
+  Scenario: Named pull request template without editing
+    Given the GitHub API server:
+      """
+      post('/repos/mislav/coral/pulls') {
+        assert :title => 'Commit title',
+               :body => "Release notes for topic"
+        status 201
+        json :html_url => "the://url"
+      }
+      """
+    Given I am on the "master" branch pushed to "origin/master"
+    When I successfully run `git checkout --quiet -b topic`
+    And I make a commit with message:
+      """
+      Commit title
+
+      Commit body
+      """
+    And the "topic" branch is pushed to "origin/topic"
+    And a file named "docs/PULL_REQUEST_TEMPLATE/release.md" with:
+      """
+      Release notes for {{branch}}
+      """
+    When I successfully run `hub pull-request --template release --no-edit`
+    Then the output should contain exactly "the://url\n"
+
+  Scenario: Unknown pull request template
+    Given I am on the "master" branch pushed to "origin/master"
+    When I successfully run `git checkout --quiet -b topic`
+    And I make a commit with message "Commit title"
+    And the "topic" branch is pushed to "origin/topic"
+    And a file named ".github/PULL_REQUEST_TEMPLATE/bugfix.md" with:
+      """
+      Fixes a bug
+      """
+    And a file named ".github/PULL_REQUEST_TEMPLATE/feature.md" with:
+      """
+      Adds a feature
+      """
+    When I run `hub pull-request --template docs`
+    Then the exit status should be 1
+    And the stderr should contain exactly "template 'docs' not found; available templates: bugfix, feature\n"
+
   Scenario: Single-commit pull request with "--no-edit"
     Given the GitHub API server:
       """
@@ -316,7 +461,7 @@ Feature: hub pull-request
       post('/repos/origin/coral/pulls') { 404 }
       """
     When I run `hub pull-request -b origin:master -m here`
-    Then the exit status should be 1
+    Then the exit status should be 3
     Then the stderr should contain:
       """
       Error creating pull request: Not Found (HTTP 404)
@@ -633,6 +778,16 @@ Feature: hub pull-request
       (use `-f` to force submit a pull request anyway)\n
       """
 
+  Scenario: Error when the branch was checked out from a pull request
+    Given I am on the "feature" branch
+    And git "branch.feature.description" is set to "#12: Feature\nhttps://github.com/mislav/coral/pull/12"
+    When I run `hub pull-request`
+    Then the stderr should contain exactly:
+      """
+      Aborted: branch 'feature' is already associated with pull request https://github.com/mislav/coral/pull/12
+      (use `-f` to force submit a pull request anyway)\n
+      """
+
   Scenario: Ignore unpushed commits with `-f`
     Given I am on the "feature" branch with upstream "origin/feature"
     Given the GitHub API server:
@@ -956,6 +1111,62 @@ Feature: hub pull-request
     When I successfully run `hub pull-request -m hereyougo -r mislav,josh -rgithub/robots -rpcorpet -r github/js`
     Then the output should contain exactly "the://url\n"
 
+  Scenario: Pull request to the base branch remembered for the repo
+    Given git "--global hub.mislav/coral.base" is set to "develop"
+    Given the GitHub API server:
+      """
+      post('/repos/mislav/coral/pulls') {
+        assert :base => 'develop',
+               :head => 'mislav:master'
+        status 201
+        json :html_url => "the://url"
+      }
+      """
+    When I successfully run `hub pull-request -m hereyougo`
+    Then the output should contain exactly "the://url\n"
+
+  Scenario: Explicit base branch takes precedence over the remembered one
+    Given git "--global hub.mislav/coral.base" is set to "develop"
+    Given the GitHub API server:
+      """
+      post('/repos/mislav/coral/pulls') {
+        assert :base => 'stable',
+               :head => 'mislav:master'
+        status 201
+        json :html_url => "the://url"
+      }
+      """
+    When I successfully run `hub pull-request -m hereyougo -b stable`
+    Then the output should contain exactly "the://url\n"
+    When I successfully run `git config --global hub.mislav/coral.base`
+    Then the output should contain exactly "develop\n"
+
+  Scenario: Pull request with reviewers and labels remembered for the repo
+    Given I am on the "feature" branch with upstream "origin/feature"
+    And git "--global hub.mislav/coral.reviewers" is set to "josh,github/robots"
+    And git "--global hub.mislav/coral.labels" is set to "needs-review"
+    Given the GitHub API server:
+      """
+      post('/repos/mislav/coral/pulls') {
+        assert :base => 'master',
+               :head => 'mislav:feature'
+        status 201
+        json :html_url => "the://url", :number => 1234
+      }
+      patch('/repos/mislav/coral/issues/1234') {
+        assert :labels => ["needs-review"]
+        json :html_url => "the://url"
+      }
+      post('/repos/mislav/coral/pulls/1234/requested_reviewers') {
+        assert :reviewers => ["pcorpet"]
+        assert :team_reviewers => []
+        status 201
+        json :html_url => "the://url"
+      }
+      """
+    When I successfully run `hub pull-request -m hereyougo -r pcorpet`
+    Then the output should contain exactly "the://url\n"
+
   Scenario: Pull request avoids re-requesting reviewers
     Given I am on the "feature" branch with upstream "origin/feature"
     Given the GitHub API server:
@@ -1019,6 +1230,56 @@ Feature: hub pull-request
     When I successfully run `hub pull-request -m hereyougo -M "Hello World!"`
     Then the output should contain exactly "the://url\n"
 
+  Scenario: Dry run of a pull request
+    Given I am on the "feature" branch with upstream "origin/feature"
+    Given the GitHub API server:
+      """
+      get('/repos/mislav/coral/milestones') {
+        json [
+          { :number => 237, :title => "prerelease" },
+          { :number => 1337, :title => "v1" }
+        ]
+      }
+      """
+    When I successfully run `hub pull-request --dry-run -m "Add feature" -m "It does things." -l bug -r josh,github/core -M 1337 --push`
+    Then the output should contain exactly:
+      """
+      Repository: mislav/coral
+      Base:       mislav:master
+      Head:       mislav:feature
+      Draft:      no
+      Title:      Add feature
+      Labels:     bug
+      Assignees:  (none)
+      Milestone:  #1337 v1
+      Reviewers:  users: josh; teams: github/core
+
+      It does things.\n
+      """
+    And "git push --set-upstream origin HEAD:feature" should not be run
+
+  Scenario: Dry run of a pull request in JSON format
+    Given I am on the "feature" branch with upstream "origin/feature"
+    When I successfully run `hub pull-request --dry-run=json -d -m "Add feature"`
+    Then the output should contain exactly:
+      """
+      {
+        "repository": "mislav/coral",
+        "base": "mislav:master",
+        "head": "mislav:feature",
+        "title": "Add feature",
+        "body": "",
+        "draft": true,
+        "labels": [],
+        "assignees": [],
+        "milestone": null,
+        "reviewers": {
+          "users": [],
+          "teams": []
+        }
+      }\n
+      """
+
   Scenario: Pull request with case-insensitive milestone
     Given I am on the "feature" branch with upstream "origin/feature"
     Given the GitHub API server:
@@ -1123,10 +1384,7 @@ Feature: hub pull-request
       get('/repositories/12345') {
         json :name => 'coralify', :owner => { :login => 'coral-org' }
       }
-      post('/repos/mislav/coral/pulls') {
-        redirect 'https://api.github.com/repositories/12345', 307
-      }
-      post('/repositories/12345', :host_name => 'api.github.com') {
+      post('/repos/coral-org/coralify/pulls') {
         assert :base  => 'master',
                :head  => 'coral-org:feature',
                :title => 'hereyougo'
@@ -1135,7 +1393,39 @@ Feature: hub pull-request
       }
       """
     When I successfully run `hub pull-request -m hereyougo`
-    Then the output should contain exactly "the://url\n"
+    Then the stdout should contain exactly "the://url\n"
+    And the stderr should contain exactly:
+      """
+      mislav/coral was moved to coral-org/coralify
+      To update remote 'origin', run `git remote set-url origin https://github.com/coral-org/coralify.git` or use `hub --fix-remote`\n
+      """
+
+  Scenario: Pull request with redirect of the request itself
+    Given the "origin" remote has url "https://github.com/mislav/coral.git"
+    And I am on the "feature" branch pushed to "origin/feature"
+    Given the GitHub API server:
+      """
+      get('/repos/mislav/coral') {
+        json :name => 'coral', :owner => { :login => 'mislav' }
+      }
+      post('/repos/mislav/coral/pulls') {
+        redirect 'https://api.github.com/repositories/12345/pulls', 307
+      }
+      post('/repositories/12345/pulls', :host_name => 'api.github.com') {
+        assert :base  => 'master',
+               :head  => 'mislav:feature',
+               :title => 'hereyougo'
+        status 201
+        json :html_url => "the://url"
+      }
+      get('/repositories/12345') {
+        json :name => 'coralify', :owner => { :login => 'coral-org' }
+      }
+      """
+    When I successfully run `hub --fix-remote pull-request -m hereyougo`
+    Then the stdout should contain exactly "the://url\n"
+    And the stderr should contain exactly "mislav/coral was moved to coral-org/coralify\n"
+    And the url for "origin" should be "https://github.com/coral-org/coralify.git"
 
   Scenario: Default message with --push
     Given the git commit editor is "true"
@@ -1170,12 +1460,58 @@ Feature: hub pull-request
   Scenario: Triangular workflow with --push
     Given the "upstream" remote has url "git://github.com/github/coral.git"
     And I am on the "master" branch pushed to "upstream/master"
-    # TODO: head should be "mislav:topic"
     Given the GitHub API server:
       """
       post('/repos/github/coral/pulls') {
         assert :base  => 'master',
-               :head  => 'github:topic',
+               :head  => 'mislav:topic',
+               :title => 'hereyougo'
+        status 201
+        json :html_url => "the://url"
+      }
+      """
+    When I successfully run `git checkout --quiet -b topic`
+    Given I make a commit with message "Fork commit"
+    When I successfully run `hub pull-request -p -m hereyougo`
+    Then the output should contain exactly "the://url\n"
+    And "git push --set-upstream origin HEAD:topic" should be run
+
+  Scenario: Triangular workflow with unconventionally named remotes
+    Given I successfully run `git remote rename origin fork`
+    And the "gh-resolved" remote has url "git://github.com/github/coral.git"
+    And I am on the "master" branch pushed to "gh-resolved/master"
+    Given the GitHub API server:
+      """
+      get('/repos/mislav/coral') {
+        json :name => 'coral', :owner => { :login => 'mislav' },
+             :parent => { :name => 'coral', :owner => { :login => 'github' },
+                          :html_url => 'https://github.com/github/coral' }
+      }
+      post('/repos/github/coral/pulls') {
+        assert :base  => 'master',
+               :head  => 'mislav:topic',
+               :title => 'hereyougo'
+        status 201
+        json :html_url => "the://url"
+      }
+      """
+    When I successfully run `git checkout --quiet -b topic`
+    Given I make a commit with message "Fork commit"
+    When I successfully run `hub pull-request -p -m hereyougo`
+    Then the output should contain exactly "the://url\n"
+    And "git push --set-upstream fork HEAD:topic" should be run
+
+  Scenario: Remotes named in the configuration
+    Given the "github" remote has url "git://github.com/github/coral.git"
+    And the "mine" remote has url "git@github.com:mislav/coral-fork.git"
+    And I successfully run `git config hub.upstreamRemote github`
+    And I successfully run `git config hub.forkRemote mine`
+    And I am on the "master" branch pushed to "github/master"
+    Given the GitHub API server:
+      """
+      post('/repos/github/coral/pulls') {
+        assert :base  => 'master',
+               :head  => 'mislav:topic',
                :title => 'hereyougo'
         status 201
         json :html_url => "the://url"
@@ -1185,8 +1521,7 @@ Feature: hub pull-request
     Given I make a commit with message "Fork commit"
     When I successfully run `hub pull-request -p -m hereyougo`
     Then the output should contain exactly "the://url\n"
-    # TODO: the push should be to the "origin" remote instead
-    And "git push --set-upstream upstream HEAD:topic" should be run
+    And "git push --set-upstream mine HEAD:topic" should be run
 
   Scenario: Automatically retry when --push resulted in 422
     Given The default aruba timeout is 7 seconds
@@ -1264,3 +1599,33 @@ Feature: hub pull-request
       """
     When I successfully run `hub pull-request -d -m wip`
     Then the output should contain exactly "the://url\n"
+
+  Scenario: Draft pull request on an Enterprise version without drafts
+    Given the "origin" remote has url "git@git.my.org:mislav/coral.git"
+    And I am "mislav" on git.my.org with OAuth token "FITOKEN"
+    And "git.my.org" is a whitelisted Enterprise host
+    Given the GitHub API server:
+      """
+      get('/api/v3/meta', :host_name => 'git.my.org') {
+        json :installed_version => "2.16.4"
+      }
+      """
+    When I run `hub pull-request -d -m wip`
+    Then the stderr should contain exactly:
+      """
+      Error: draft pull requests requires GitHub Enterprise 2.17 or later, but git.my.org runs 2.16.4\n
+      """
+    And the exit status should be 1
+
+  Scenario: Draft pull request in noop mode
+    When I successfully run `hub --noop pull-request -d -m wip`
+    Then the output should contain:
+      """
+      Would request a pull request to mislav:master from mislav:master with:
+      {
+        "base": "master",
+        "draft": true,
+        "head": "mislav:master",
+        "title": "wip"
+      }
+      """
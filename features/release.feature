@@ -63,6 +63,104 @@ Feature: hub release
       v1.0.2\n
       """
 
+  Scenario: List releases published in a date range with their downloads
+    Given the GitHub API server:
+      """
+      get('/repos/mislav/will_paginate/releases') {
+        json [
+          { tag_name: 'v1.3.0',
+            published_at: '2024-04-02T10:00:00Z',
+            assets: [ { name: 'a.tgz', download_count: 5 } ],
+          },
+          { tag_name: 'v1.2.0',
+            published_at: '2024-03-31T23:00:00Z',
+            assets: [ { name: 'a.tgz', download_count: 10 }, { name: 'b.zip', download_count: 32 } ],
+          },
+          { tag_name: 'v1.1.0',
+            published_at: '2024-02-15T12:00:00Z',
+            assets: [ { name: 'a.tgz', download_count: 100 } ],
+          },
+          { tag_name: 'v1.0.0',
+            published_at: '2023-12-31T12:00:00Z',
+            assets: [ { name: 'a.tgz', download_count: 900 } ],
+          },
+        ]
+      }
+      """
+    When I successfully run `hub release --since 2024-01-01 --until 2024-03-31 -f "%T %dl%n"`
+    Then the output should contain exactly:
+      """
+      v1.2.0 42
+      v1.1.0 100\n
+      """
+
+  Scenario: Abbreviated download counts
+    Given the GitHub API server:
+      """
+      get('/repos/mislav/will_paginate/releases') {
+        json [
+          { tag_name: 'v1.2.0',
+            assets: [ { name: 'a.tgz', download_count: 1200 }, { name: 'b.zip', download_count: 34 } ],
+          },
+        ]
+      }
+      """
+    When I successfully run `hub release -f "%T %dl%n"`
+    Then the output should contain exactly "v1.2.0 1.2k\n"
+    When I successfully run `hub release --exact -f "%T %dl%n"`
+    Then the output should contain exactly "v1.2.0 1234\n"
+
+  Scenario: Sort releases by downloads before limiting them
+    Given the GitHub API server:
+      """
+      get('/repos/mislav/will_paginate/releases') {
+        assert :per_page => '100'
+        json [
+          { tag_name: 'v1.2.0', published_at: '2024-03-01T00:00:00Z',
+            assets: [ { name: 'a.tgz', download_count: 10 }, { name: 'b.zip', download_count: 32 } ],
+          },
+          { tag_name: 'v1.1.0', published_at: '2024-02-01T00:00:00Z',
+            assets: [ { name: 'a.tgz', download_count: 100 } ],
+          },
+          { tag_name: 'v1.0.0', published_at: '2024-01-01T00:00:00Z',
+            assets: [],
+          },
+        ]
+      }
+      """
+    When I successfully run `hub release --sort downloads -L 2 -f "%T %dl%n"`
+    Then the output should contain exactly:
+      """
+      v1.1.0 100
+      v1.2.0 42\n
+      """
+
+  Scenario: Export releases as CSV
+    Given the GitHub API server:
+      """
+      get('/repos/mislav/will_paginate/releases') {
+        json [
+          { tag_name: 'v1.2.0',
+            name: 'will_paginate 1.2.0, "final"',
+            html_url: 'https://github.com/mislav/will_paginate/releases/v1.2.0',
+            published_at: '2024-03-01T00:00:00Z',
+            assets: [ { name: 'a.tgz', download_count: 10 }, { name: 'b.zip', download_count: 32 } ],
+          },
+        ]
+      }
+      """
+    When I successfully run `hub release --output csv`
+    Then the output should contain exactly:
+      """
+      Tag,Name,Published,Downloads
+      v1.2.0,"will_paginate 1.2.0, ""final""",2024-03-01,42\n
+      """
+
+  Scenario: Invalid release date range
+    When I run `hub release --since 2024-04-01 --until 2024-03-31`
+    Then the exit status should be 5
+    And the stderr should contain "the '--since' date must be before the '--until' date"
+
   Scenario: List all releases
     Given the GitHub API server:
       """
@@ -230,7 +328,7 @@ Feature: hub release
       Error fetching releases: Not Found (HTTP 404)
       Not Found\n
       """
-    And the exit status should be 1
+    And the exit status should be 3
 
   Scenario: Server error when listing releases
     Given the GitHub API server:
@@ -247,6 +345,80 @@ Feature: hub release
       """
     And the exit status should be 1
 
+  Scenario: Diff two releases
+    Given the GitHub API server:
+      """
+      get('/repos/mislav/will_paginate/releases') {
+        json [
+          { tag_name: 'v1.3.0',
+            body: "### Fixes\n\n- everything\n- nothing",
+            assets: [
+              { name: "hub.tgz", size: 1200 },
+              { name: "hub.zip", size: 900 },
+            ],
+          },
+          { tag_name: 'v1.2.0',
+            body: "### Fixes\n\n- everything",
+            assets: [
+              { name: "hub.tgz", size: 1000 },
+              { name: "hub.exe", size: 500 },
+            ],
+          },
+        ]
+      }
+      get('/repos/mislav/will_paginate/compare/v1.2.0...v1.3.0') {
+        json ahead_by: 3, behind_by: 0, total_commits: 3
+      }
+      """
+    When I run `hub release diff v1.2.0 v1.3.0`
+    Then the output should contain exactly:
+      """
+      ## Body
+
+      --- v1.2.0
+      +++ v1.3.0
+      @@ -1,3 +1,4 @@
+       ### Fixes
+       
+       - everything
+      +- nothing
+
+      ## Assets
+
+      - hub.exe (500 bytes)
+      ~ hub.tgz (1000 -> 1200 bytes, +200)
+      + hub.zip (900 bytes)
+
+      ## Commits
+
+      v1.2.0...v1.3.0: 3 commits ahead, 0 commits behind
+      https://github.com/mislav/will_paginate/compare/v1.2.0...v1.3.0\n
+      """
+    And the exit status should be 1
+
+  Scenario: Diff identical release assets across repositories
+    Given the GitHub API server:
+      """
+      get('/repos/mislav/will_paginate/releases') {
+        json [
+          { tag_name: 'v1.3.0',
+            body: "Staging notes",
+            assets: [{ name: "hub.tgz", size: 1200 }],
+          },
+        ]
+      }
+      get('/repos/mislav/will_paginate-prod/releases') {
+        json [
+          { tag_name: 'v1.3.0',
+            body: "Production notes",
+            assets: [{ name: "hub.tgz", size: 1200 }],
+          },
+        ]
+      }
+      """
+    When I successfully run `hub release diff --assets-only v1.3.0 mislav/will_paginate-prod@v1.3.0`
+    Then the output should contain exactly ""
+
   Scenario: Show specific release
     Given the GitHub API server:
       """
@@ -260,6 +432,7 @@ Feature: hub release
             zipball_url: "https://github.com/mislav/will_paginate/archive/v1.2.0.zip",
             assets: [
               { browser_download_url: "https://github.com/mislav/will_paginate/releases/download/v1.2.0/example.zip",
+                size: 1572864, download_count: 1,
               },
             ],
             body: <<MARKDOWN
@@ -320,7 +493,7 @@ MARKDOWN
 
       ## Downloads
 
-      https://github.com/mislav/will_paginate/releases/download/v1.2.0/example.zip
+      https://github.com/mislav/will_paginate/releases/download/v1.2.0/example.zip (1.5 MiB, 1 download)
       https://github.com/mislav/will_paginate/archive/v1.2.0.zip
       https://github.com/mislav/will_paginate/archive/v1.2.0.tar.gz\n
       """
@@ -364,7 +537,7 @@ MARKDOWN
 
   Scenario: Show release no tag
     When I run `hub release show`
-    Then the exit status should be 1
+    Then the exit status should be 5
     Then the stderr should contain "hub release show"
 
   Scenario: Create a release
@@ -387,9 +560,26 @@ MARKDOWN
       https://github.com/mislav/will_paginate/releases/v1.2.0\n
       """
 
+  Scenario: Create a release in an archived repository
+    Given the GitHub API server:
+      """
+      get('/repos/mislav/will_paginate') {
+        json :name => "will_paginate", :archived => true
+      }
+      post('/repos/mislav/will_paginate/releases') {
+        halt 400
+      }
+      """
+    When I run `hub release create -m "will_paginate 1.2.0" v1.2.0`
+    Then the stderr should contain exactly "Aborted: repository mislav/will_paginate is archived (read-only)\n"
+    And the exit status should be 1
+
   Scenario: Create a release with target commitish
     Given the GitHub API server:
       """
+      get('/repos/mislav/will_paginate/commits/my-branch') {
+        json :sha => "deadbeef"
+      }
       post('/repos/mislav/will_paginate/releases') {
         assert :tag_name => "v1.2.0",
                :target_commitish => "my-branch"
@@ -404,6 +594,71 @@ MARKDOWN
       https://github.com/mislav/will_paginate/releases/v1.2.0\n
       """
 
+  Scenario: Create a release from a changelog section
+    Given a file named "CHANGELOG.md" with:
+      """
+      # Changelog
+
+      ## [1.2.0] - 2024-01-01
+
+      ### Added
+
+      - Instant gratification
+
+      ## [1.1.0] - 2023-06-01
+
+      - Older things
+      """
+    Given the GitHub API server:
+      """
+      post('/repos/mislav/will_paginate/releases') {
+        assert :tag_name => "v1.2.0",
+               :name => "Monkey",
+               :body => "Highlights first.\n\n### Added\n\n- Instant gratification"
+
+        status 201
+        json :html_url => "https://github.com/mislav/will_paginate/releases/v1.2.0"
+      }
+      """
+    When I successfully run `hub release create -m Monkey -m "Highlights first." --changelog v1.2.0`
+    Then the output should contain exactly:
+      """
+      https://github.com/mislav/will_paginate/releases/v1.2.0\n
+      """
+
+  Scenario: Create a release from a changelog without a matching section
+    Given a file named "docs/CHANGES.md" with:
+      """
+      ## v1.1.0
+
+      - Older things
+
+      ## v1.0.0
+
+      - First release
+      """
+    When I run `hub release create --changelog=docs/CHANGES.md v1.2.0`
+    Then the exit status should be 1
+    And the stderr should contain exactly:
+      """
+      no section for v1.2.0 found in docs/CHANGES.md (found versions: v1.1.0, v1.0.0)\n
+      """
+
+  Scenario: Create a release with nonexistent target commitish
+    Given the GitHub API server:
+      """
+      get('/repos/mislav/will_paginate/commits/no-such-branch') {
+        status 404
+        json :message => "No commit found for SHA: no-such-branch"
+      }
+      """
+    When I run `hub release create -m hello v1.2.0 -t no-such-branch`
+    Then the stderr should contain exactly:
+      """
+      Aborted: no commit could be found for `no-such-branch' locally or in mislav/will_paginate\n
+      """
+    And the exit status should be 1
+
   Scenario: Create a release with assets
     Given the GitHub API server:
       """
@@ -430,6 +685,41 @@ MARKDOWN
       Attaching release asset `./hello-1.2.0.tar.gz'...\n
       """
 
+  Scenario: Attaching some of the assets fails
+    Given the GitHub API server:
+      """
+      post('/repos/mislav/will_paginate/releases') {
+        status 201
+        json :html_url => "https://github.com/mislav/will_paginate/releases/v1.2.0",
+             :upload_url => "https://uploads.github.com/uploads/assets{?name,label}"
+      }
+      post('/uploads/assets', :host_name => 'uploads.github.com') {
+        if params[:name] == 'broken.zip'
+          status 422
+          json :message => "Validation Failed"
+        else
+          status 201
+        end
+      }
+      """
+    And a file named "hello-1.2.0.tar.gz" with:
+      """
+      TARBALL
+      """
+    And a file named "broken.zip" with:
+      """
+      ZIP
+      """
+    When I run `hub release create -m "hello" v1.2.0 -a broken.zip -a hello-1.2.0.tar.gz --parallel 2`
+    Then the exit status should be 1
+    And the output should contain "Attaching release asset `hello-1.2.0.tar.gz'..."
+    And the stderr should contain:
+      """
+      Error: failed to attach 1 of 2 release assets:
+        broken.zip: Error uploading release asset: Unprocessable Entity (HTTP 422)
+          Validation Failed
+      """
+
   Scenario: Open new release in web browser
     Given the GitHub API server:
       """
@@ -444,7 +734,7 @@ MARKDOWN
 
   Scenario: Create release no tag
     When I run `hub release create -m hello`
-    Then the exit status should be 1
+    Then the exit status should be 5
     Then the stderr should contain "hub release create"
 
   Scenario: Edit existing release
@@ -521,6 +811,71 @@ MARKDOWN
       Aborting editing due to empty release title\n
       """
 
+  Scenario: Refuse to replace the notes of a published release
+    Given the GitHub API server:
+      """
+      get('/repos/mislav/will_paginate/releases') {
+        json [
+          { tag_name: 'v1.2.0',
+            name: 'will_paginate 1.2.0',
+            draft: false,
+            body: "Carefully written notes",
+          },
+        ]
+      }
+      """
+    When I run `hub release edit -m "will_paginate 1.2.0" -m "oops" v1.2.0`
+    Then the exit status should be 1
+    And the stderr should contain exactly:
+      """
+      Aborted: this would replace the notes of published release v1.2.0
+      (use `--append-body` to add to them, `--force` to replace them, or leave out `-m` and `-F` to edit them in a text editor)\n
+      """
+
+  Scenario: Replace the notes of a published release with force
+    Given the GitHub API server:
+      """
+      get('/repos/mislav/will_paginate/releases') {
+        json [
+          { url: 'https://api.github.com/repos/mislav/will_paginate/releases/123',
+            tag_name: 'v1.2.0',
+            name: 'will_paginate 1.2.0',
+            draft: false,
+            body: "Carefully written notes",
+          },
+        ]
+      }
+      patch('/repos/mislav/will_paginate/releases/123') {
+        assert :name => 'will_paginate 1.2.0',
+               :body => 'New notes'
+        json({})
+      }
+      """
+    When I successfully run `hub release edit --force -m "will_paginate 1.2.0" -m "New notes" v1.2.0`
+    Then there should be no output
+
+  Scenario: Append to the notes of a published release
+    Given the GitHub API server:
+      """
+      get('/repos/mislav/will_paginate/releases') {
+        json [
+          { url: 'https://api.github.com/repos/mislav/will_paginate/releases/123',
+            tag_name: 'v1.2.0',
+            name: 'will_paginate 1.2.0',
+            draft: false,
+            body: "Carefully written notes\n",
+          },
+        ]
+      }
+      patch('/repos/mislav/will_paginate/releases/123') {
+        assert :name => nil,
+               :body => "Carefully written notes\n\n---\n\nErrata: requires Ruby 2.5"
+        json({})
+      }
+      """
+    When I successfully run `hub release edit --append-body -m "Errata: requires Ruby 2.5" v1.2.0`
+    Then there should be no output
+
   Scenario: Edit existing release by uploading assets
     Given the GitHub API server:
       """
@@ -565,7 +920,7 @@ MARKDOWN
 
   Scenario: Edit release no tag
     When I run `hub release edit -m hello`
-    Then the exit status should be 1
+    Then the exit status should be 5
     Then the stderr should contain "hub release edit"
 
     Scenario: Download a release asset.
@@ -611,9 +966,158 @@ MARKDOWN
           ASSET_TARBALL
           """
 
+  Scenario: Download release assets with a manifest
+    Given the GitHub API server:
+      """
+      get('/repos/mislav/will_paginate/releases') {
+        json [
+          { url: 'https://api.github.com/repos/mislav/will_paginate/releases/123',
+            tag_name: 'v1.2.0',
+            assets: [
+              { url: 'https://api.github.com/repos/mislav/will_paginate/assets/9876',
+                browser_download_url: 'https://github.com/mislav/will_paginate/releases/download/v1.2.0/hello-1.2.0.tar.gz',
+                name: 'hello-1.2.0.tar.gz',
+              },
+            ],
+          },
+        ]
+      }
+      get('/repos/mislav/will_paginate/assets/9876') {
+        headers['Content-Type'] = 'application/octet-stream'
+        "ASSET_TARBALL"
+      }
+      """
+    When I successfully run `hub release download --manifest manifest.json v1.2.0`
+    Then the file "manifest.json" should contain exactly:
+      """
+      [
+        {
+          "name": "hello-1.2.0.tar.gz",
+          "size": 13,
+          "sha256": "b192fd7c7d437c134c4dfcd88615abc3925efd7e5a5fd998af644aab15e71c87",
+          "url": "https://github.com/mislav/will_paginate/releases/download/v1.2.0/hello-1.2.0.tar.gz"
+        }
+      ]\n
+      """
+
+  Scenario: Download release assets with JSON events
+    Given the GitHub API server:
+      """
+      get('/repos/mislav/will_paginate/releases') {
+        json [
+          { url: 'https://api.github.com/repos/mislav/will_paginate/releases/123',
+            tag_name: 'v1.2.0',
+            assets: [
+              { url: 'https://api.github.com/repos/mislav/will_paginate/assets/9876',
+                browser_download_url: 'https://github.com/mislav/will_paginate/releases/download/v1.2.0/hello-1.2.0.tar.gz',
+                name: 'hello-1.2.0.tar.gz',
+              },
+            ],
+          },
+        ]
+      }
+      get('/repos/mislav/will_paginate/assets/9876') {
+        headers['Content-Type'] = 'application/octet-stream'
+        "ASSET_TARBALL"
+      }
+      """
+    When I successfully run `hub --porcelain release download v1.2.0`
+    Then the stdout should contain exactly:
+      """
+      {"done":13,"event":"asset-download","name":"hello-1.2.0.tar.gz","total":0,"v":1}
+      {"assets":[{"name":"hello-1.2.0.tar.gz","size":13,"sha256":"b192fd7c7d437c134c4dfcd88615abc3925efd7e5a5fd998af644aab15e71c87","url":"https://github.com/mislav/will_paginate/releases/download/v1.2.0/hello-1.2.0.tar.gz"}],"command":"release download","event":"result","tag":"v1.2.0","v":1}\n
+      """
+    And the stderr should contain "Downloading hello-1.2.0.tar.gz"
+
+  Scenario: Download matching release assets into a directory
+    Given the GitHub API server:
+      """
+      get('/repos/mislav/will_paginate/releases') {
+        json [
+          { url: 'https://api.github.com/repos/mislav/will_paginate/releases/123',
+            tag_name: 'v1.2.0',
+            assets: [
+              { url: 'https://api.github.com/repos/mislav/will_paginate/assets/9876',
+                name: 'hello-1.2.0.tar.gz',
+              },
+              { url: 'https://api.github.com/repos/mislav/will_paginate/assets/9877',
+                name: 'hello-1.2.0.zip',
+              },
+            ],
+          },
+        ]
+      }
+      get('/repos/mislav/will_paginate/assets/9876') {
+        halt 415 unless request.accept?('application/octet-stream')
+        headers['Content-Type'] = 'application/octet-stream'
+        "ASSET_TARBALL"
+      }
+      """
+    And a file named "dist/hello-1.2.0.tar.gz" with:
+      """
+      OLD
+      """
+    When I run `hub release download -i "*.tar.gz" --output-dir dist v1.2.0`
+    Then the exit status should be 1
+    And the stderr should contain exactly "Error: dist/hello-1.2.0.tar.gz already exists; use '--clobber' to overwrite it\n"
+    When I successfully run `hub release download -i "*.tar.gz" --output-dir dist --clobber v1.2.0`
+    Then the output should contain exactly:
+      """
+      Downloading hello-1.2.0.tar.gz ...\n
+      """
+    And the file "dist/hello-1.2.0.tar.gz" should contain exactly:
+      """
+      ASSET_TARBALL
+      """
+    And the file "dist/hello-1.2.0.zip" should not exist
+
+  Scenario: Download assets matching nothing
+    Given the GitHub API server:
+      """
+      get('/repos/mislav/will_paginate/releases') {
+        json [
+          { url: 'https://api.github.com/repos/mislav/will_paginate/releases/123',
+            tag_name: 'v1.2.0',
+            assets: [
+              { url: 'https://api.github.com/repos/mislav/will_paginate/assets/9876',
+                name: 'hello-1.2.0.tar.gz',
+              },
+            ],
+          },
+        ]
+      }
+      """
+    When I run `hub release download -i "*.deb" v1.2.0`
+    Then the exit status should be 1
+    And the stderr should contain exactly "Error: no assets match `*.deb'\n"
+
+  Scenario: Failed download with JSON events
+    Given the GitHub API server:
+      """
+      get('/repos/mislav/will_paginate/releases') {
+        json [
+          { url: 'https://api.github.com/repos/mislav/will_paginate/releases/123',
+            tag_name: 'v1.2.0',
+            assets: [],
+          },
+        ]
+      }
+      """
+    When I run `hub --porcelain release download -i "*.deb" v1.2.0`
+    Then the exit status should be 1
+    And the stdout should contain exactly:
+      """
+      {"error":"Error: no assets match `*.deb'","event":"result","exit":1,"v":1}\n
+      """
+
+  Scenario: Strip components without unpacking
+    When I run `hub release download --strip-components 1 v1.2.0`
+    Then the exit status should be 5
+    And the stderr should contain "the '--strip-components' option requires '--unpack'"
+
   Scenario: Download release no tag
     When I run `hub release download`
-    Then the exit status should be 1
+    Then the exit status should be 5
     Then the stderr should contain "hub release download"
 
   Scenario: Delete a release
@@ -651,7 +1155,7 @@ MARKDOWN
       }
       """
     When I run `hub release delete v2.0`
-    Then the exit status should be 1
+    Then the exit status should be 3
     And the stderr should contain exactly:
       """
       Unable to find release with tag name `v2.0'\n
@@ -678,3 +1182,8 @@ MARKDOWN
       """
       v1.2.0\n
       """
+
+  Scenario: Invalid number of stripped components
+    When I run `hub release download --unpack --strip-components=-1 v1.2.0`
+    Then the exit status should be 5
+    And the stderr should contain "invalid value '-1' for --strip-components (expected: a number of 0 or more)"
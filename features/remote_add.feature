@@ -97,7 +97,7 @@ Feature: hub remote add
       }
       """
     When I run `hub remote add mislav`
-    Then the exit status should be 1
+    Then the exit status should be 3
     And the output should contain exactly:
       """
       Error: repository mislav/dotfiles doesn't exist\n
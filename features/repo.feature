@@ -0,0 +1,97 @@
+Feature: hub repo
+  Background:
+    Given I am in "git://github.com/mislav/dotfiles.git" git repo
+    And I am "mislav" on github.com with OAuth token "OTOKEN"
+
+  Scenario: Transfer repository
+    Given the GitHub API server:
+      """
+      post('/repos/mislav/dotfiles/transfer') {
+        assert :new_owner => "my-org",
+               :team_ids => [12, 34]
+        status 202
+        json :name => "dotfiles",
+             :owner => { :login => "mislav" }
+      }
+      """
+    When I run `hub repo transfer --team-id 12,34 my-org` interactively
+    And I type "n"
+    Then the exit status should be 0
+    And the output should contain:
+      """
+      Transfer of repository 'mislav/dotfiles' to 'my-org' is pending.
+      Once the transfer is complete, https://github.com/mislav/dotfiles will redirect to https://github.com/my-org/dotfiles
+      """
+    And the url for "origin" should be "git://github.com/mislav/dotfiles.git"
+
+  Scenario: Transfer repository and update remote
+    Given the GitHub API server:
+      """
+      post('/repos/mislav/dotfiles/transfer') {
+        assert :new_owner => "my-org"
+        status 202
+        json :name => "dotfiles",
+             :owner => { :login => "my-org" }
+      }
+      """
+    When I run `hub repo transfer my-org` interactively
+    And I type "y"
+    Then the exit status should be 0
+    And the output should contain:
+      """
+      Transferred repository 'mislav/dotfiles' to 'my-org'.
+      """
+    And the url for "origin" should be "git://github.com/my-org/dotfiles.git"
+
+  Scenario: Transfer repository in noop mode
+    When I successfully run `hub --noop repo transfer my-org`
+    Then the output should contain exactly:
+      """
+      Would request transfer of repository 'mislav/dotfiles' to 'my-org'
+      Would update remote 'origin' to point to 'git://github.com/my-org/dotfiles.git'\n
+      """
+    And the url for "origin" should be "git://github.com/mislav/dotfiles.git"
+
+  Scenario: Archive repository
+    Given the GitHub API server:
+      """
+      patch('/repos/mislav/dotfiles') {
+        assert :archived => true
+        json :name => "dotfiles", :archived => true
+      }
+      """
+    When I run `hub repo archive` interactively
+    And I type "dotfiles"
+    Then the exit status should be 0
+    And the output should contain:
+      """
+      Type the name of the repository to confirm archiving 'mislav/dotfiles':
+      """
+    And the output should contain:
+      """
+      Archived repository 'mislav/dotfiles'.
+      """
+
+  Scenario: Unarchive repository without confirmation
+    Given the GitHub API server:
+      """
+      patch('/repos/mislav/dotfiles') {
+        assert :archived => false
+        json :name => "dotfiles", :archived => false
+      }
+      """
+    When I run `hub repo unarchive -y`
+    Then the exit status should be 0
+    And the output should contain exactly:
+      """
+      Unarchived repository 'mislav/dotfiles'.\n
+      """
+
+  Scenario: Invalid archive confirmation
+    When I run `hub repo archive` interactively
+    And I type "y"
+    Then the exit status should be 1
+    And the stderr should contain exactly:
+      """
+      Please type 'dotfiles' for confirmation.\n
+      """
@@ -0,0 +1,113 @@
+Feature: hub secret
+  Background:
+    Given I am in "git://github.com/mislav/dotfiles.git" git repo
+    And I am "mislav" on github.com with OAuth token "OTOKEN"
+
+  Scenario: Set repository secret
+    Given the GitHub API server:
+      """
+      get('/repos/mislav/dotfiles/actions/secrets/public-key') {
+        json :key_id => "568250167242549743",
+             :key => "E75P6uryBMf9M1j8nAByGIHRdCeBKCJ+xnTzf3/pe20="
+      }
+      put('/repos/mislav/dotfiles/actions/secrets/DEPLOY_TOKEN') {
+        assert :key_id => "568250167242549743",
+               :visibility => :no
+        halt 400 unless params[:encrypted_value].unpack("m0").first.bytesize == 48 + 6
+        status 201
+      }
+      """
+    When I successfully run `hub secret set -b s3cr3t DEPLOY_TOKEN`
+    Then the output should contain exactly ""
+
+  Scenario: Set environment secret from standard input
+    Given the GitHub API server:
+      """
+      get('/repos/mislav/dotfiles/environments/pre%20prod/secrets/public-key') {
+        json :key_id => "568250167242549743",
+             :key => "E75P6uryBMf9M1j8nAByGIHRdCeBKCJ+xnTzf3/pe20="
+      }
+      put('/repos/mislav/dotfiles/environments/pre%20prod/secrets/SSH_KEY') {
+        halt 400 unless params[:encrypted_value].unpack("m0").first.bytesize == 48 + 6
+        status 204
+      }
+      """
+    When I run `hub secret set --env "pre prod" SSH_KEY` interactively
+    And I pass in:
+      """
+      abc123
+      """
+    Then the exit status should be 0
+
+  Scenario: Set organization secret
+    Given the GitHub API server:
+      """
+      get('/orgs/acme/actions/secrets/public-key') {
+        json :key_id => "568250167242549743",
+             :key => "E75P6uryBMf9M1j8nAByGIHRdCeBKCJ+xnTzf3/pe20="
+      }
+      put('/orgs/acme/actions/secrets/NPM_TOKEN') {
+        assert :visibility => "all"
+        status 201
+      }
+      """
+    When I successfully run `hub secret set --org acme --visibility all -b s3cr3t NPM_TOKEN`
+    Then the output should contain exactly ""
+
+  Scenario: Invalid public key
+    Given the GitHub API server:
+      """
+      get('/repos/mislav/dotfiles/actions/secrets/public-key') {
+        json :key_id => "568250167242549743",
+             :key => "c2hvcnQ="
+      }
+      """
+    When I run `hub secret set -b s3cr3t DEPLOY_TOKEN`
+    Then the exit status should be 1
+    And the stderr should contain exactly:
+      """
+      invalid public key: expected 32 bytes, got 5\n
+      """
+
+  Scenario: Visibility requires organization
+    When I run `hub secret set --visibility all -b s3cr3t DEPLOY_TOKEN`
+    Then the exit status should be 5
+    And the stderr should contain "the '--visibility' option requires '--org'"
+
+  Scenario: List secrets
+    Given the GitHub API server:
+      """
+      get('/repos/mislav/dotfiles/actions/secrets') {
+        assert :per_page => "100"
+        json :total_count => 2,
+             :secrets => [
+               { :name => "DEPLOY_TOKEN",
+                 :created_at => "2020-01-10T14:59:59Z",
+                 :updated_at => "2020-01-11T10:00:00Z" },
+               { :name => "NPM",
+                 :created_at => "2020-02-10T14:59:59Z",
+                 :updated_at => "2020-02-10T14:59:59Z" },
+             ]
+      }
+      """
+    When I successfully run `hub secret list`
+    Then the output should contain exactly:
+      """
+      DEPLOY_TOKEN  Updated 2020-01-11
+      NPM           Updated 2020-02-10\n
+      """
+
+  Scenario: Remove organization secret
+    Given the GitHub API server:
+      """
+      delete('/orgs/acme/actions/secrets/NPM_TOKEN') {
+        status 204
+      }
+      """
+    When I successfully run `hub secret remove --org acme NPM_TOKEN`
+    Then the output should contain exactly ""
+
+  Scenario: Environment and organization are mutually exclusive
+    When I run `hub secret list --env production --org acme`
+    Then the exit status should be 5
+    And the stderr should contain "the '--env' and '--org' options are mutually exclusive"
@@ -0,0 +1,84 @@
+Feature: hub security
+  Background:
+    Given I am in "git://github.com/mislav/dotfiles.git" git repo
+    And I am "mislav" on github.com with OAuth token "OTOKEN"
+
+  Scenario: List open Dependabot alerts
+    Given the GitHub API server:
+      """
+      get('/repos/mislav/dotfiles/dependabot/alerts') {
+        assert :state => 'open', :severity => 'high,critical'
+        json [
+          { :number => 12, :state => 'open',
+            :dependency => { :package => { :ecosystem => 'npm', :name => 'minimist' }, :manifest_path => 'package-lock.json' },
+            :security_advisory => { :ghsa_id => 'GHSA-xvch-5gv4-984h', :cve_id => 'CVE-2021-44906', :severity => 'critical' },
+            :security_vulnerability => { :severity => 'critical', :vulnerable_version_range => '< 1.2.6' },
+          },
+          { :number => 9, :state => 'open',
+            :dependency => { :package => { :ecosystem => 'pip', :name => 'jinja2' }, :manifest_path => 'requirements.txt' },
+            :security_advisory => { :ghsa_id => 'GHSA-h5c8-rqwp-cp95', :cve_id => nil, :severity => 'high' },
+            :security_vulnerability => { :severity => 'high', :vulnerable_version_range => '< 3.1.3' },
+          },
+        ]
+      }
+      """
+    When I successfully run `hub security alerts --severity high,critical`
+    Then the output should contain exactly:
+      """
+      #12  critical  npm/minimist  < 1.2.6  package-lock.json  CVE-2021-44906
+      #9   high      pip/jinja2    < 3.1.3  requirements.txt   GHSA-h5c8-rqwp-cp95\n
+      """
+
+  Scenario: Fail on severe alerts
+    Given the GitHub API server:
+      """
+      get('/repos/mislav/dotfiles/dependabot/alerts') {
+        json [
+          { :number => 3, :state => 'open',
+            :dependency => { :package => { :ecosystem => 'npm', :name => 'lodash' }, :manifest_path => 'package-lock.json' },
+            :security_advisory => { :ghsa_id => 'GHSA-p6mc-m468-83gw', :cve_id => 'CVE-2020-8203', :severity => 'high' },
+            :security_vulnerability => { :severity => 'high', :vulnerable_version_range => '< 4.17.19' },
+          },
+        ]
+      }
+      """
+    When I run `hub security alerts --fail-on high`
+    Then the exit status should be 1
+    And the stderr should contain exactly "1 alert of high severity or higher\n"
+    When I successfully run `hub security alerts --fail-on critical`
+    Then the stderr should contain exactly ""
+
+  Scenario: Token without a scope that grants reading alerts
+    Given the GitHub API server:
+      """
+      get('/repos/mislav/dotfiles/dependabot/alerts') {
+        response.headers['X-OAuth-Scopes'] = 'gist, read:org'
+        status 403
+        json :message => 'Resource not accessible by integration'
+      }
+      """
+    When I run `hub security alerts`
+    Then the exit status should be 4
+    And the stderr should contain exactly "can't read Dependabot alerts of mislav/dotfiles: the access token lacks the 'security_events' or 'repo' scope\n"
+
+  Scenario: Invalid severity
+    When I run `hub security alerts --severity high,urgent`
+    Then the exit status should be 5
+    And the stderr should contain "invalid value 'high,urgent' for --severity"
+
+  Scenario: List published security advisories
+    Given the GitHub API server:
+      """
+      get('/repos/mislav/dotfiles/security-advisories') {
+        assert :state => 'published'
+        json [
+          { :ghsa_id => 'GHSA-abcd-efgh-ijkl', :cve_id => 'CVE-2026-1234', :severity => 'medium',
+            :state => 'published', :published_at => '2026-03-01T12:00:00Z', :summary => 'Path traversal in install script' },
+        ]
+      }
+      """
+    When I successfully run `hub security advisories`
+    Then the output should contain exactly:
+      """
+      GHSA-abcd-efgh-ijkl  CVE-2026-1234  medium  2026-03-01  Path traversal in install script\n
+      """
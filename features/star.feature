@@ -0,0 +1,99 @@
+Feature: hub star and hub watch
+  Background:
+    Given I am in "git://github.com/mislav/dotfiles.git" git repo
+    And I am "mislav" on github.com with OAuth token "OTOKEN"
+
+  Scenario: Star the current repository
+    Given the GitHub API server:
+      """
+      get('/user/starred/mislav/dotfiles') {
+        status 404
+        json :message => 'Not Found'
+      }
+      put('/user/starred/mislav/dotfiles') {
+        status 204
+      }
+      """
+    When I successfully run `hub star`
+    Then the output should contain exactly "Starred mislav/dotfiles.\n"
+
+  Scenario: Star an already starred repository
+    Given the GitHub API server:
+      """
+      get('/user/starred/octocat/hello') {
+        status 204
+      }
+      """
+    When I successfully run `hub star octocat/hello`
+    Then the output should contain exactly ""
+
+  Scenario: Unstar a repository that isn't starred
+    Given the GitHub API server:
+      """
+      get('/user/starred/mislav/dotfiles') {
+        status 404
+        json :message => 'Not Found'
+      }
+      """
+    When I successfully run `hub unstar`
+    Then the output should contain exactly ""
+
+  Scenario: List starred repositories
+    Given the GitHub API server:
+      """
+      get('/users/octocat/starred') {
+        assert :per_page => '2'
+        json [
+          { :full_name => 'mislav/dotfiles', :description => 'My dotfiles', :private => false },
+          { :full_name => 'github/hub', :description => 'A command-line tool', :private => false },
+        ]
+      }
+      """
+    When I successfully run `hub star --list -L 2 -f "%R: %d%n" octocat`
+    Then the output should contain exactly:
+      """
+      mislav/dotfiles: My dotfiles
+      github/hub: A command-line tool\n
+      """
+
+  Scenario: Watch only releases
+    Given the GitHub API server:
+      """
+      get('/repos/mislav/dotfiles/subscription') {
+        json :subscribed => true, :ignored => false
+      }
+      put('/repos/mislav/dotfiles/subscription') {
+        assert :subscribed => false, :ignored => false
+        json :subscribed => false, :ignored => false
+      }
+      """
+    When I successfully run `hub watch --releases-only`
+    Then the output should contain exactly "Watching releases of mislav/dotfiles.\n"
+
+  Scenario: Watch an already watched repository
+    Given the GitHub API server:
+      """
+      get('/repos/mislav/dotfiles/subscription') {
+        json :subscribed => true, :ignored => false
+      }
+      """
+    When I successfully run `hub watch`
+    Then the output should contain exactly ""
+
+  Scenario: Unwatch
+    Given the GitHub API server:
+      """
+      get('/repos/mislav/dotfiles/subscription') {
+        json :subscribed => false, :ignored => true
+      }
+      delete('/repos/mislav/dotfiles/subscription') {
+        status 204
+      }
+      """
+    When I successfully run `hub unwatch`
+    Then the output should contain exactly "Stopped watching mislav/dotfiles.\n"
+
+  Scenario: Ignore and releases-only are mutually exclusive
+    When I run `hub watch --ignore --releases-only`
+    Then the exit status should be 5
+    And the stderr should contain "the '--ignore' and '--releases-only' options are mutually exclusive"
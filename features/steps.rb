@@ -305,6 +305,17 @@ When(/^I pass in:$/) do |input|
   @interactive.stdin.close
 end
 
+Given(/^an executable named "([^"]+)" on PATH with:$/) do |name, script|
+  plugin_dir = File.expand_path(File.join(current_dir, 'plugins'))
+  FileUtils.mkdir_p(plugin_dir)
+  File.open(File.join(plugin_dir, name), 'w', 0755) { |exe|
+    exe.puts "#!/bin/bash"
+    exe.puts "set -e"
+    exe.puts script
+  }
+  set_env 'PATH', "#{plugin_dir}:#{ENV['PATH']}"
+end
+
 Given(/^the git commit editor is "([^"]+)"$/) do |cmd|
   set_env('GIT_EDITOR', cmd)
 end
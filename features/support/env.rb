@@ -53,6 +53,8 @@ Before do
   set_env 'HUB_VERSION', 'dev'
   set_env 'HUB_REPORT_CRASH', 'never'
   set_env 'HUB_PROTOCOL', nil
+  # scenarios about server errors expect them to fail right away
+  set_env 'HUB_RETRIES', '0'
 
   FileUtils.mkdir_p ENV['HOME']
 
@@ -13,7 +13,7 @@ Feature: hub sync
     Given I am on the "feature" branch pushed to "origin/feature"
     And I successfully run `git reset -q --hard HEAD^`
     When I successfully run `hub sync`
-    Then the output should contain "Updated branch feature"
+    Then the output should contain "Updated branches:\n  feature (was "
     And "git merge --ff-only --quiet refs/remotes/origin/feature" should be run
 
   Scenario: Fast-forwards other local branches in the background
@@ -23,8 +23,9 @@ Feature: hub sync
     And I successfully run `git reset -q --hard HEAD^`
     And I successfully run `git checkout -q master`
     When I successfully run `hub sync`
-    Then the output should contain "Updated branch feature"
-    And the output should contain "Updated branch bugfix"
+    Then the output should contain "Updated branches:\n"
+    And the output should contain "  feature (was "
+    And the output should contain "  bugfix (was "
 
   Scenario: Refuses to update local branch which has diverged from upstream
     Given I am on the "feature" branch pushed to "origin/feature"
@@ -32,7 +33,8 @@ Feature: hub sync
     When I successfully run `hub sync`
     Then the stderr should contain exactly:
       """
-      warning: `feature' seems to contain unpushed commits\n
+      Skipped branches:
+        feature: seems to contain unpushed commits\n
       """
 
   Scenario: Deletes local branch that had its upstream deleted
@@ -43,7 +45,7 @@ Feature: hub sync
     And I successfully run `rm .git/refs/remotes/origin/feature`
     And I successfully run `git checkout -q feature`
     When I successfully run `hub sync`
-    Then the output should contain "Deleted branch feature"
+    Then the output should contain "Deleted branches:\n  feature (was "
 
   Scenario: Refuses to delete local branch whose upstream was deleted but not merged to master
     Given I am on the "feature" branch with upstream "origin/feature"
@@ -52,5 +54,141 @@ Feature: hub sync
     When I successfully run `hub sync`
     Then the stderr should contain exactly:
       """
-      warning: `feature' was deleted on origin, but appears not merged into master\n
+      Skipped branches:
+        feature: was deleted on origin, but appears not merged into master\n
       """
+
+  Scenario: Skips the current branch when uncommitted changes would be overwritten
+    Given I am on the "feature" branch pushed to "origin/feature"
+    And a file named "notes.txt" with:
+      """
+      one
+      """
+    And I successfully run `git add notes.txt`
+    And I successfully run `git commit -qm one`
+    And a file named "notes.txt" with:
+      """
+      two
+      """
+    And I successfully run `git commit -qam two`
+    And I successfully run `git update-ref refs/remotes/origin/feature HEAD`
+    And I successfully run `git reset -q --hard HEAD^`
+    And I am on the "bugfix" branch pushed to "origin/bugfix"
+    And I successfully run `git reset -q --hard HEAD^`
+    And I successfully run `git checkout -q feature`
+    And a file named "notes.txt" with:
+      """
+      local
+      """
+    When I successfully run `hub sync`
+    Then the stdout should contain "Updated branches:\n  bugfix (was "
+    And the stderr should contain exactly:
+      """
+      Skipped branches:
+        feature: the working tree has uncommitted changes that would be overwritten; commit or stash them, or use '--autostash'\n
+      """
+    And the file "notes.txt" should contain exactly "local"
+
+  Scenario: Stashes uncommitted changes while fast-forwarding the current branch
+    Given I am on the "feature" branch pushed to "origin/feature"
+    And a file named "notes.txt" with:
+      """
+      one
+      """
+    And a file named "todo.txt" with:
+      """
+      milk
+      """
+    And I successfully run `git add notes.txt todo.txt`
+    And I successfully run `git commit -qm one`
+    And a file named "notes.txt" with:
+      """
+      two
+      """
+    And I successfully run `git commit -qam two`
+    And I successfully run `git update-ref refs/remotes/origin/feature HEAD`
+    And I successfully run `git reset -q --hard HEAD^`
+    And a file named "todo.txt" with:
+      """
+      eggs
+      """
+    When I successfully run `hub sync --autostash`
+    Then the output should contain "Updated branches:\n  feature (was "
+    And the file "notes.txt" should contain exactly "two"
+    And the file "todo.txt" should contain exactly "eggs"
+    When I successfully run `git stash list`
+    Then the output should contain exactly ""
+
+  Scenario: Keeps the autostash when applying it conflicts
+    Given I am on the "feature" branch pushed to "origin/feature"
+    And a file named "notes.txt" with:
+      """
+      one
+      """
+    And I successfully run `git add notes.txt`
+    And I successfully run `git commit -qm one`
+    And a file named "notes.txt" with:
+      """
+      two
+      """
+    And I successfully run `git commit -qam two`
+    And I successfully run `git update-ref refs/remotes/origin/feature HEAD`
+    And I successfully run `git reset -q --hard HEAD^`
+    And a file named "notes.txt" with:
+      """
+      local
+      """
+    When I successfully run `hub sync --autostash`
+    Then the stderr should contain exactly:
+      """
+      Applying autostash resulted in conflicts.
+      Your changes are safe in the stash.
+      You can run "git stash pop" or "git stash drop" at any time.\n
+      """
+    And the output should contain "Updated branches:\n  feature (was "
+    And the file "notes.txt" should contain exactly "two"
+    When I successfully run `git stash list`
+    Then the output should contain "autostash"
+
+  Scenario: Prunes remote branches and reports them
+    When I successfully run `hub sync --prune`
+    Then "git fetch --quiet --progress origin" should be run
+    And "git remote prune origin" should be run
+
+  Scenario: Reports what would be done without doing it
+    Given I am on the "bugfix" branch with upstream "origin/bugfix"
+    And I successfully run `git update-ref refs/remotes/origin/master HEAD`
+    And I successfully run `rm .git/refs/remotes/origin/bugfix`
+    And I successfully run `git checkout -q master`
+    And I am on the "feature" branch pushed to "origin/feature"
+    And I successfully run `git reset -q --hard HEAD^`
+    And I successfully run `git checkout -q master`
+    When I successfully run `hub sync --dry-run --prune`
+    Then the output should contain "Would update branches:\n  feature (was "
+    And the output should contain "Would delete branches:\n  bugfix (was "
+    And "git remote prune --dry-run origin" should be run
+    And "git fetch --prune --quiet --progress origin" should not be run
+    When I successfully run `git branch --list`
+    Then the output should contain "  bugfix\n"
+
+  Scenario: Prints results using a format
+    Given I am on the "feature" branch pushed to "origin/feature"
+    And I successfully run `git reset -q --hard HEAD^`
+    And I am on the "bugfix" branch pushed to "origin/bugfix"
+    And I make a commit with message "diverge"
+    And I successfully run `git checkout -q master`
+    When I successfully run `hub sync --format "%a %b%n"`
+    Then the output should contain exactly:
+      """
+      skipped (diverged) bugfix
+      fast-forwarded feature\n
+      """
+
+  Scenario: Skips branches checked out in other worktrees
+    Given I am on the "feature" branch pushed to "origin/feature"
+    And I successfully run `git reset -q --hard HEAD^`
+    And I successfully run `git checkout -q master`
+    And I successfully run `git worktree add -q ../feature-worktree feature`
+    When I successfully run `hub sync`
+    Then the stderr should contain "Skipped branches:\n  feature: is checked out in "
+    And the output should not contain "Updated branches:"
@@ -0,0 +1,153 @@
+Feature: hub todo
+  Background:
+    Given I am "mislav" on github.com with OAuth token "OTOKEN"
+
+  Scenario: Summarize what needs attention
+    Given the GitHub API server:
+      """
+      get('/user') { json :login => "mislav" }
+      get('/search/issues') {
+        assert :sort => "updated", :order => "desc",
+               :per_page => params[:q].include?("comments:>0") ? "100" : "10"
+        case params[:q]
+        when "is:issue assignee:mislav is:open archived:false"
+          json :total_count => 12, :items => [
+            { :number => 12, :title => "Crash on startup",
+              :repository_url => "https://api.github.com/repos/acme/widgets" },
+            { :number => 3, :title => "Typo in README",
+              :repository_url => "https://api.github.com/repos/mislav/dotfiles" },
+          ]
+        when "is:pr review-requested:mislav is:open archived:false"
+          json :total_count => 1, :items => [
+            { :number => 80, :title => "Speed up builds",
+              :repository_url => "https://api.github.com/repos/acme/tools" },
+          ]
+        when "is:pr author:mislav status:failure is:open archived:false"
+          json :total_count => 0, :items => []
+        when "is:pr author:mislav comments:>0 updated:>=2020-01-01T00:00:00Z is:open archived:false"
+          json :total_count => 2, :items => [
+            { :number => 7, :title => "Add zsh config",
+              :repository_url => "https://api.github.com/repos/mislav/dotfiles" },
+            { :number => 9, :title => "Rebased onto master",
+              :repository_url => "https://api.github.com/repos/mislav/dotfiles" },
+          ]
+        else
+          status 422
+        end
+      }
+      get('/repos/mislav/dotfiles/issues/7/comments') {
+        assert :since => "2020-01-01T00:00:00Z"
+        json [
+          { :user => { :login => "mislav" }, :created_at => "2020-01-02T10:00:00Z" },
+          { :user => { :login => "hubot" }, :created_at => "2020-01-02T11:00:00Z" },
+        ]
+      }
+      get('/repos/mislav/dotfiles/issues/9/comments') {
+        json [
+          { :user => { :login => "mislav" }, :created_at => "2020-01-02T10:00:00Z" },
+        ]
+      }
+      """
+    When I successfully run `hub todo --since 2020-01-01T00:00:00Z`
+    Then the output should contain exactly:
+      """
+      Issues assigned to you
+        acme/widgets#12    Crash on startup
+        mislav/dotfiles#3  Typo in README
+        and 10 more
+
+      Pull requests awaiting your review
+        acme/tools#80  Speed up builds
+
+      Your pull requests with failing checks
+        nothing
+
+      Your pull requests with new comments
+        mislav/dotfiles#7  Add zsh config\n
+      """
+
+  Scenario: Count all pull requests with new comments
+    Given the GitHub API server:
+      """
+      get('/user') { json :login => "mislav" }
+      get('/search/issues') {
+        if params[:q].include?("comments:>0")
+          assert :per_page => "100"
+          json :total_count => 3, :items => [
+            { :number => 7, :title => "Add zsh config",
+              :repository_url => "https://api.github.com/repos/mislav/dotfiles" },
+            { :number => 8, :title => "Rebased onto master",
+              :repository_url => "https://api.github.com/repos/mislav/dotfiles" },
+            { :number => 9, :title => "Add fish config",
+              :repository_url => "https://api.github.com/repos/mislav/dotfiles" },
+          ]
+        else
+          json :total_count => 0, :items => []
+        end
+      }
+      get('/repos/mislav/dotfiles/issues/:number/comments') {
+        author = params[:number] == "8" ? "mislav" : "hubot"
+        json [
+          { :user => { :login => author }, :created_at => "2020-01-02T10:00:00Z" },
+        ]
+      }
+      """
+    When I successfully run `hub todo --since 2020-01-01T00:00:00Z -L 1`
+    Then the output should contain:
+      """
+      Your pull requests with new comments
+        mislav/dotfiles#7  Add zsh config
+        and 1 more\n
+      """
+
+  Scenario: Scope to organizations
+    Given the GitHub API server:
+      """
+      get('/user') { json :login => "mislav" }
+      get('/search/issues') {
+        assert :per_page => params[:q].include?("comments:>0") ? "100" : "2"
+        halt 422 unless params[:q].end_with?(" is:open archived:false org:acme org:umbrella")
+        json :total_count => 0, :items => []
+      }
+      """
+    When I successfully run `hub todo --org acme,umbrella -L 2`
+    Then the output should contain "Issues assigned to you\n  nothing\n"
+
+  Scenario: JSON output
+    Given the GitHub API server:
+      """
+      get('/user') { json :login => "mislav" }
+      get('/search/issues') {
+        if params[:q].start_with?("is:issue ")
+          json :total_count => 1, :items => [
+            { :number => 12, :title => "Crash on startup",
+              :html_url => "https://github.com/acme/widgets/issues/12",
+              :updated_at => "2020-01-02T10:00:00Z",
+              :repository_url => "https://api.github.com/repos/acme/widgets" },
+          ]
+        else
+          json :total_count => 0, :items => []
+        end
+      }
+      """
+    When I successfully run `hub todo --json`
+    Then the output should contain:
+      """
+        "assigned_issues": {
+          "total_count": 1,
+          "items": [
+            {
+              "repo": "acme/widgets",
+              "number": 12,
+              "title": "Crash on startup",
+              "url": "https://github.com/acme/widgets/issues/12",
+              "updated_at": "2020-01-02T10:00:00Z"
+            }
+          ]
+        },
+      """
+
+  Scenario: Invalid limit
+    When I run `hub todo -L 0`
+    Then the exit status should be 5
+    And the stderr should contain "--limit must be a number between 1 and 100"
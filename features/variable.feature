@@ -0,0 +1,101 @@
+Feature: hub variable
+  Background:
+    Given I am in "git://github.com/mislav/dotfiles.git" git repo
+    And I am "mislav" on github.com with OAuth token "OTOKEN"
+
+  Scenario: Create repository variable
+    Given the GitHub API server:
+      """
+      post('/repos/mislav/dotfiles/actions/variables') {
+        assert :name => "API_URL",
+               :value => "https://api.example.com",
+               :visibility => :no
+        status 201
+      }
+      """
+    When I successfully run `hub variable set API_URL https://api.example.com`
+    Then the output should contain exactly ""
+
+  Scenario: Update existing environment variable
+    Given the GitHub API server:
+      """
+      post('/repos/mislav/dotfiles/environments/production/variables') {
+        status 409
+        json :message => "Already exists - Variable already exists"
+      }
+      patch('/repos/mislav/dotfiles/environments/production/variables/API_URL') {
+        assert :name => "API_URL",
+               :value => "https://api.example.com"
+        status 204
+      }
+      """
+    When I successfully run `hub variable set --env production API_URL https://api.example.com`
+    Then the output should contain exactly ""
+
+  Scenario: Create organization variable
+    Given the GitHub API server:
+      """
+      post('/orgs/acme/actions/variables') {
+        assert :name => "REGION",
+               :value => "eu",
+               :visibility => "private"
+        status 201
+      }
+      """
+    When I successfully run `hub variable set --org acme REGION eu`
+    Then the output should contain exactly ""
+
+  Scenario: List variables
+    Given the GitHub API server:
+      """
+      get('/repos/mislav/dotfiles/actions/variables') {
+        assert :per_page => "30"
+        json :total_count => 2,
+             :variables => [
+               { :name => "API_URL",
+                 :value => "https://api.example.com",
+                 :created_at => "2020-01-10T14:59:59Z",
+                 :updated_at => "2020-01-11T10:00:00Z" },
+               { :name => "REGION",
+                 :value => "eu",
+                 :created_at => "2020-02-10T14:59:59Z",
+                 :updated_at => "2020-02-10T14:59:59Z" },
+             ]
+      }
+      """
+    When I successfully run `hub variable list`
+    Then the output should contain exactly:
+      """
+      API_URL  https://api.example.com  Updated 2020-01-11
+      REGION   eu                       Updated 2020-02-10\n
+      """
+
+  Scenario: List variables with format
+    Given the GitHub API server:
+      """
+      get('/orgs/acme/actions/variables') {
+        json :total_count => 1,
+             :variables => [
+               { :name => "REGION",
+                 :value => "eu",
+                 :visibility => "all",
+                 :created_at => "2020-02-10T14:59:59Z",
+                 :updated_at => "2020-02-11T14:59:59Z" },
+             ]
+      }
+      """
+    When I successfully run `hub variable list --org acme -f "%N=%v (%S, %uI)%n"`
+    Then the output should contain exactly:
+      """
+      REGION=eu (all, 2020-02-11T14:59:59Z)\n
+      """
+
+  Scenario: Remove variable
+    Given the GitHub API server:
+      """
+      delete('/repos/mislav/dotfiles/actions/variables/REGION') {
+        status 204
+      }
+      """
+    When I successfully run `hub variable remove REGION`
+    Then the output should contain exactly ""
@@ -4,8 +4,13 @@ import (
 	"fmt"
 	"io/ioutil"
 	"os"
+	"os/exec"
+	"path"
 	"path/filepath"
+	"strconv"
 	"strings"
+	"sync"
+	"syscall"
 
 	"github.com/github/hub/cmd"
 )
@@ -105,6 +110,19 @@ func HasFile(segments ...string) bool {
 	return false
 }
 
+// GitPath resolves a path within the git directory, such as "hooks/pre-push",
+// the way git does, e.g. taking core.hooksPath into account.
+func GitPath(segments ...string) (string, error) {
+	name := filepath.Join(segments...)
+	pathCmd := gitCmd("rev-parse", "-q", "--git-path", name)
+	pathCmd.Stderr = nil
+	output, err := pathCmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("unable to determine the location of %s in the git directory", name)
+	}
+	return filepath.Abs(firstLine(output))
+}
+
 func BranchAtRef(paths ...string) (name string, err error) {
 	dir, err := Dir()
 	if err != nil {
@@ -131,19 +149,56 @@ func BranchAtRef(paths ...string) (name string, err error) {
 	return
 }
 
-func Editor() (string, error) {
-	varCmd := gitCmd("var", "GIT_EDITOR")
-	varCmd.Stderr = nil
-	output, err := varCmd.Output()
-	if err != nil {
-		return "", fmt.Errorf("Can't load git var: GIT_EDITOR")
+func Head() (string, error) {
+	return BranchAtRef("HEAD")
+}
+
+// DetachedHeadError is returned by SymbolicRef when a ref like HEAD points
+// directly to a commit instead of to a branch.
+type DetachedHeadError struct {
+	Sha string
+}
+
+func (e *DetachedHeadError) Error() string {
+	sha := e.Sha
+	if len(sha) > 7 {
+		sha = sha[0:7]
 	}
+	return fmt.Sprintf("you are in detached HEAD state at %s", sha)
+}
 
-	return os.ExpandEnv(firstLine(output)), nil
+// UnbornBranchError is returned by SymbolicRef when a ref like HEAD points to
+// a branch that has no commits yet, as is the case in a fresh repository.
+type UnbornBranchError struct {
+	Branch string
 }
 
-func Head() (string, error) {
-	return BranchAtRef("HEAD")
+func (e *UnbornBranchError) Error() string {
+	return fmt.Sprintf("branch '%s' has no commits yet", strings.TrimPrefix(e.Branch, "refs/heads/"))
+}
+
+// SymbolicRef returns the full name of the branch that ref points to, such as
+// "refs/heads/master" for HEAD. It returns a *DetachedHeadError if ref points
+// to a commit, and the branch name together with an *UnbornBranchError if the
+// branch doesn't have any commits yet.
+func SymbolicRef(ref string) (string, error) {
+	symbolicCmd := gitCmd("symbolic-ref", "-q", ref)
+	symbolicCmd.Stderr = nil
+	output, err := symbolicCmd.Output()
+	if err != nil {
+		sha, shaErr := Ref(ref)
+		if shaErr != nil {
+			return "", fmt.Errorf("Unknown revision or path not in the working tree: %s", ref)
+		}
+		return "", &DetachedHeadError{Sha: sha}
+	}
+
+	name := firstLine(output)
+	if !gitCmd("rev-parse", "-q", "--verify", name).Success() {
+		return name, &UnbornBranchError{Branch: name}
+	}
+
+	return name, nil
 }
 
 func SymbolicFullName(name string) (string, error) {
@@ -168,6 +223,51 @@ func Ref(ref string) (string, error) {
 	return firstLine(output), nil
 }
 
+// HasCommit reports whether ref resolves to a commit that exists in the local
+// repository.
+func HasCommit(ref string) bool {
+	return gitCmd("rev-parse", "-q", "--verify", ref+"^{commit}").Success()
+}
+
+// HasUncommittedChanges reports whether tracked files in the working tree or
+// the index differ from HEAD.
+func HasUncommittedChanges() bool {
+	output, err := gitCmd("status", "--porcelain", "--untracked-files=no").Output()
+	return err == nil && strings.TrimSpace(output) != ""
+}
+
+// StashCreate records uncommitted changes as a stash commit without adding it
+// to the stash list or touching the working tree.
+func StashCreate(message string) (string, error) {
+	output, err := gitCmd("stash", "create", message).Output()
+	if err != nil {
+		return "", fmt.Errorf("Can't stash uncommitted changes")
+	}
+	return firstLine(output), nil
+}
+
+// AheadBehind counts the commits reachable from head but not from base, and
+// the ones reachable from base but not from head.
+func AheadBehind(base, head string) (ahead, behind int, err error) {
+	ref := fmt.Sprintf("%s...%s", base, head)
+	countCmd := gitCmd("rev-list", "--left-right", "--count", ref)
+	countCmd.Stderr = nil
+	output, err := countCmd.Output()
+	if err != nil {
+		err = fmt.Errorf("Can't count commits for %s", ref)
+		return
+	}
+
+	fields := strings.Fields(output)
+	if len(fields) != 2 {
+		err = fmt.Errorf("Can't count commits for %s", ref)
+		return
+	}
+	behind, _ = strconv.Atoi(fields[0])
+	ahead, _ = strconv.Atoi(fields[1])
+	return
+}
+
 func RefList(a, b string) ([]string, error) {
 	ref := fmt.Sprintf("%s...%s", a, b)
 	listCmd := gitCmd("rev-list", "--cherry-pick", "--right-only", "--no-merges", ref)
@@ -180,6 +280,34 @@ func RefList(a, b string) ([]string, error) {
 	return outputLines(output), nil
 }
 
+// A RangeCommit is a commit listed by RangeCommits.
+type RangeCommit struct {
+	Sha     string
+	Subject string
+}
+
+// RangeCommits lists at most limit commits of a revision range such as
+// "main..topic", newest first.
+func RangeCommits(revRange string, limit int) ([]RangeCommit, error) {
+	logCmd := gitCmd("-c", "log.showSignature=false", "log", "--no-color", "--format=%H %s", fmt.Sprintf("--max-count=%d", limit), revRange, "--")
+	logCmd.Stderr = nil
+	output, err := logCmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("Can't load commits for %s", revRange)
+	}
+
+	commits := []RangeCommit{}
+	for _, line := range outputLines(output) {
+		parts := strings.SplitN(line, " ", 2)
+		commit := RangeCommit{Sha: parts[0]}
+		if len(parts) > 1 {
+			commit.Subject = parts[1]
+		}
+		commits = append(commits, commit)
+	}
+	return commits, nil
+}
+
 func NewRange(a, b string) (*Range, error) {
 	parseCmd := gitCmd("rev-parse", "-q", a, b)
 	parseCmd.Stderr = nil
@@ -241,6 +369,95 @@ func Show(sha string) (string, error) {
 	return strings.TrimSpace(output), err
 }
 
+// DiffStat returns the "--stat" summary of the files that commit sha changed.
+func DiffStat(sha string) (string, error) {
+	showCmd := gitCmd("-c", "log.showSignature=false", "show", "--stat", "--format=", "--no-color", sha)
+	showCmd.Stderr = nil
+	output, err := showCmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("Can't load the changes of %s", sha)
+	}
+	return strings.Trim(output, "\n"), nil
+}
+
+// RepoPath turns file, relative to the current directory, into a path
+// relative to the root of the working tree.
+func RepoPath(file string) (string, error) {
+	prefixCmd := gitCmd("rev-parse", "--show-prefix")
+	prefixCmd.Stderr = nil
+	output, err := prefixCmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("unable to determine git working directory")
+	}
+	repoPath := path.Clean(firstLine(output) + filepath.ToSlash(file))
+	if repoPath == ".." || strings.HasPrefix(repoPath, "../") {
+		return "", fmt.Errorf("'%s' is outside of the repository", file)
+	}
+	return repoPath, nil
+}
+
+// FileAt returns the contents of the file at repoPath as of commit sha.
+func FileAt(sha, repoPath string) (string, error) {
+	showCmd := gitCmd("show", sha+":"+repoPath)
+	showCmd.Stderr = nil
+	output, err := showCmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("'%s' doesn't exist in %s", repoPath, sha)
+	}
+	return output, nil
+}
+
+// Trailers returns the trailers of the message of commit sha, such as
+// "Signed-off-by: Name <email>", as parsed by git-interpret-trailers(1).
+func Trailers(sha string) ([]string, error) {
+	showCmd := cmd.New("git")
+	showCmd.Stderr = nil
+	showCmd.WithArgs("-c", "log.showSignature=false", "show", "-s", "--format=%B", sha)
+	message, err := showCmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("Can't load commit message of %s", sha)
+	}
+
+	// git-interpret-trailers(1) reads the message from a file or stdin
+	messageFile, err := ioutil.TempFile("", "hub-trailers")
+	if err != nil {
+		return nil, err
+	}
+	defer os.Remove(messageFile.Name())
+	_, err = messageFile.WriteString(message)
+	messageFile.Close()
+	if err != nil {
+		return nil, err
+	}
+
+	trailersCmd := gitCmd("interpret-trailers", "--parse", messageFile.Name())
+	trailersCmd.Stderr = nil
+	output, err := trailersCmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("Can't parse trailers of %s", sha)
+	}
+
+	return outputLines(output), nil
+}
+
+// CommitterIdent returns the "Name <email>" identity that git records as the
+// committer of new commits, which is also what `git commit --signoff` uses.
+func CommitterIdent() (string, error) {
+	varCmd := gitCmd("var", "GIT_COMMITTER_IDENT")
+	varCmd.Stderr = nil
+	output, err := varCmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("Can't determine git committer identity")
+	}
+
+	ident := firstLine(output)
+	if i := strings.LastIndex(ident, ">"); i >= 0 {
+		// strip the timestamp
+		ident = ident[:i+1]
+	}
+	return ident, nil
+}
+
 func Log(sha1, sha2 string) (string, error) {
 	execCmd := cmd.New("git")
 	execCmd.WithArg("-c").WithArg("log.showSignature=false").WithArg("log").WithArg("--no-color")
@@ -257,13 +474,6 @@ func Log(sha1, sha2 string) (string, error) {
 	return outputs, nil
 }
 
-func Remotes() ([]string, error) {
-	remoteCmd := gitCmd("remote", "-v")
-	remoteCmd.Stderr = nil
-	output, err := remoteCmd.Output()
-	return outputLines(output), err
-}
-
 func Config(name string) (string, error) {
 	return gitGetConfig(name)
 }
@@ -291,6 +501,76 @@ func SetGlobalConfig(name, value string) error {
 	return err
 }
 
+// A ConfigEntry is a single value of a git config variable.
+type ConfigEntry struct {
+	Name  string
+	Value string
+}
+
+// GlobalConfigEntries lists the values of global config variables with names
+// that match the regular expression pattern. Variables with several values
+// are listed once for each of them.
+func GlobalConfigEntries(pattern string) ([]ConfigEntry, error) {
+	return configEntries("--global", pattern)
+}
+
+// ConfigEntries is like GlobalConfigEntries but reads the config of the
+// current repository as well.
+func ConfigEntries(pattern string) ([]ConfigEntry, error) {
+	return configEntries("", pattern)
+}
+
+func configEntries(scope, pattern string) ([]ConfigEntry, error) {
+	args := []string{"--null", "--get-regexp", pattern}
+	if scope != "" {
+		args = append([]string{scope}, args...)
+	}
+	configCmd := gitCmd(gitConfigCommand(args)...)
+	output, err := configCmd.Output()
+	if exitErr, ok := err.(*exec.ExitError); ok {
+		// git exits with 1 when no variable matches
+		if status, ok := exitErr.Sys().(syscall.WaitStatus); ok && status.ExitStatus() == 1 {
+			return []ConfigEntry{}, nil
+		}
+	}
+	if err != nil {
+		return nil, fmt.Errorf("Error reading config %s", pattern)
+	}
+
+	entries := []ConfigEntry{}
+	for _, entry := range strings.Split(strings.TrimSuffix(output, "\x00"), "\x00") {
+		if entry == "" {
+			continue
+		}
+		// a variable without "=" in the config file is a boolean that is true
+		parts := strings.SplitN(entry, "\n", 2)
+		if len(parts) == 1 {
+			parts = append(parts, "true")
+		}
+		entries = append(entries, ConfigEntry{Name: parts[0], Value: parts[1]})
+	}
+	return entries, nil
+}
+
+// AddGlobalConfig adds value to the global config variable name, keeping its
+// existing values.
+func AddGlobalConfig(name, value string) error {
+	_, err := gitConfig("--global", "--add", name, value)
+	return err
+}
+
+// UnsetGlobalConfig removes all values of the global config variable name. It
+// is not an error if the variable wasn't set.
+func UnsetGlobalConfig(name string) error {
+	_, err := gitConfig("--global", "--unset-all", name)
+	if exitErr, ok := err.(*exec.ExitError); ok {
+		if status, ok := exitErr.Sys().(syscall.WaitStatus); ok && status.ExitStatus() == 5 {
+			return nil
+		}
+	}
+	return err
+}
+
 func gitGetConfig(args ...string) (string, error) {
 	configCmd := gitCmd(gitConfigCommand(args)...)
 	output, err := configCmd.Output()
@@ -351,6 +631,55 @@ func LocalBranches() ([]string, error) {
 	return branches, nil
 }
 
+// RemotePrune deletes the remote-tracking branches of remote whose branches
+// no longer exist there, like 'git remote prune' does, and returns their
+// names, e.g. "origin/feature". With dryRun, it only reports which ones it
+// would delete.
+func RemotePrune(remote string, dryRun bool) ([]string, error) {
+	args := []string{"remote", "prune"}
+	if dryRun {
+		args = append(args, "--dry-run")
+	}
+	pruneCmd := gitCmd(append(args, remote)...)
+	output, err := pruneCmd.CombinedOutput()
+	if err != nil {
+		return nil, fmt.Errorf("Error pruning remote %s: %s", remote, strings.TrimSpace(output))
+	}
+
+	pruned := []string{}
+	for _, line := range outputLines(output) {
+		line = strings.TrimSpace(line)
+		for _, prefix := range []string{"* [pruned] ", "* [would prune] "} {
+			if strings.HasPrefix(line, prefix) {
+				pruned = append(pruned, strings.TrimPrefix(line, prefix))
+			}
+		}
+	}
+	return pruned, nil
+}
+
+// WorktreeBranches maps the names of branches that are checked out in
+// worktrees, including the main one, to the paths of those worktrees.
+func WorktreeBranches() (map[string]string, error) {
+	worktreeCmd := gitCmd("worktree", "list", "--porcelain")
+	worktreeCmd.Stderr = nil
+	output, err := worktreeCmd.Output()
+	if err != nil {
+		return nil, err
+	}
+
+	branches := map[string]string{}
+	path := ""
+	for _, line := range outputLines(output) {
+		if strings.HasPrefix(line, "worktree ") {
+			path = strings.TrimPrefix(line, "worktree ")
+		} else if strings.HasPrefix(line, "branch refs/heads/") {
+			branches[strings.TrimPrefix(line, "branch refs/heads/")] = path
+		}
+	}
+	return branches, nil
+}
+
 func outputLines(output string) []string {
 	output = strings.TrimSuffix(output, "\n")
 	if output == "" {
@@ -382,26 +711,36 @@ func gitCmd(args ...string) *cmd.Cmd {
 	return cmd
 }
 
+var (
+	builtInCommands     map[string]bool
+	builtInCommandsOnce sync.Once
+)
+
+// IsBuiltInGitCommand tells whether git has a command of that name. The
+// commands are looked up with `git help -a` once per process.
 func IsBuiltInGitCommand(command string) bool {
-	helpCommand := gitCmd("help", "--no-verbose", "-a")
-	helpCommand.Stderr = nil
-	helpCommandOutput, err := helpCommand.Output()
-	if err != nil {
-		// support git versions that don't recognize --no-verbose
-		helpCommand := gitCmd("help", "-a")
-		helpCommandOutput, err = helpCommand.Output()
-	}
-	if err != nil {
-		return false
-	}
-	for _, helpCommandOutputLine := range outputLines(helpCommandOutput) {
-		if strings.HasPrefix(helpCommandOutputLine, "  ") {
-			for _, gitCommand := range strings.Split(helpCommandOutputLine, " ") {
-				if gitCommand == command {
-					return true
+	builtInCommandsOnce.Do(func() {
+		builtInCommands = map[string]bool{}
+		helpCommand := gitCmd("help", "--no-verbose", "-a")
+		helpCommand.Stderr = nil
+		helpCommandOutput, err := helpCommand.Output()
+		if err != nil {
+			// support git versions that don't recognize --no-verbose
+			helpCommand := gitCmd("help", "-a")
+			helpCommandOutput, err = helpCommand.Output()
+		}
+		if err != nil {
+			return
+		}
+		for _, helpCommandOutputLine := range outputLines(helpCommandOutput) {
+			if strings.HasPrefix(helpCommandOutputLine, "  ") {
+				for _, gitCommand := range strings.Split(helpCommandOutputLine, " ") {
+					if gitCommand != "" {
+						builtInCommands[gitCommand] = true
+					}
 				}
 			}
 		}
-	}
-	return false
+	})
+	return builtInCommands[command]
 }
@@ -1,7 +1,6 @@
 package git
 
 import (
-	"fmt"
 	"os"
 	"strings"
 	"testing"
@@ -18,30 +17,6 @@ func TestGitDir(t *testing.T) {
 	assert.T(t, strings.Contains(gitDir, ".git"))
 }
 
-func TestGitEditor(t *testing.T) {
-	repo := fixtures.SetupTestRepo()
-	editor := os.Getenv("GIT_EDITOR")
-	if err := os.Unsetenv("GIT_EDITOR"); err != nil {
-		t.Fatal(err)
-	}
-	defer func() {
-		repo.TearDown()
-		if err := os.Setenv("GIT_EDITOR", editor); err != nil {
-			t.Fatal(err)
-		}
-		os.Unsetenv("FOO")
-		os.Unsetenv("BAR")
-	}()
-
-	os.Setenv("FOO", "hello")
-	os.Setenv("BAR", "happy world")
-
-	SetGlobalConfig("core.editor", `$FOO "${BAR}"`)
-	gitEditor, err := Editor()
-	assert.Equal(t, nil, err)
-	assert.Equal(t, `hello "happy world"`, gitEditor)
-}
-
 func TestGitLog(t *testing.T) {
 	repo := fixtures.SetupTestRepo()
 	defer repo.TearDown()
@@ -61,6 +36,44 @@ func TestGitRef(t *testing.T) {
 	assert.Equal(t, ref, gitRef)
 }
 
+func TestSymbolicRef(t *testing.T) {
+	repo := fixtures.SetupTestRepo()
+	defer repo.TearDown()
+
+	name, err := SymbolicRef("HEAD")
+	assert.Equal(t, nil, err)
+	assert.Equal(t, "refs/heads/master", name)
+
+	assert.T(t, gitCmd("checkout", "--quiet", "9b5a719a3d76ac9dc2fa635d9b1f34fd73994c06").Success())
+	name, err = SymbolicRef("HEAD")
+	assert.Equal(t, "", name)
+	assert.Equal(t, &DetachedHeadError{Sha: "9b5a719a3d76ac9dc2fa635d9b1f34fd73994c06"}, err)
+	assert.Equal(t, "you are in detached HEAD state at 9b5a719", err.Error())
+
+	assert.T(t, gitCmd("checkout", "--quiet", "--orphan", "fresh").Success())
+	name, err = SymbolicRef("HEAD")
+	assert.Equal(t, "refs/heads/fresh", name)
+	assert.Equal(t, &UnbornBranchError{Branch: "refs/heads/fresh"}, err)
+	assert.Equal(t, "branch 'fresh' has no commits yet", err.Error())
+}
+
+func TestGitTrailers(t *testing.T) {
+	repo := fixtures.SetupTestRepo()
+	defer repo.TearDown()
+
+	trailers, err := Trailers("9b5a719a3d76ac9dc2fa635d9b1f34fd73994c06")
+	assert.Equal(t, nil, err)
+	assert.Equal(t, []string{}, trailers)
+
+	commit := gitCmd("-c", "user.name=Hub", "-c", "user.email=hub@example.com", "commit", "--quiet", "--allow-empty",
+		"-m", "Signed commit", "-m", "Signed-off-by: Josh <josh@example.com>\nSigned-off-by: Mislav <mislav@example.com>")
+	assert.T(t, commit.Success())
+
+	trailers, err = Trailers("HEAD")
+	assert.Equal(t, nil, err)
+	assert.Equal(t, []string{"Signed-off-by: Josh <josh@example.com>", "Signed-off-by: Mislav <mislav@example.com>"}, trailers)
+}
+
 func TestGitRefList(t *testing.T) {
 	repo := fixtures.SetupTestRepo()
 	defer repo.TearDown()
@@ -72,6 +85,42 @@ func TestGitRefList(t *testing.T) {
 	assert.Equal(t, "9b5a719a3d76ac9dc2fa635d9b1f34fd73994c06", refList[0])
 }
 
+func TestGitRangeCommits(t *testing.T) {
+	repo := fixtures.SetupTestRepo()
+	defer repo.TearDown()
+
+	commits, err := RangeCommits("08f4b7b6513dffc6245857e497cfd6101dc47818..9b5a719a3d76ac9dc2fa635d9b1f34fd73994c06", 50)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, 1, len(commits))
+	assert.Equal(t, "9b5a719a3d76ac9dc2fa635d9b1f34fd73994c06", commits[0].Sha)
+	assert.NotEqual(t, "", commits[0].Subject)
+
+	commits, err = RangeCommits("9b5a719a3d76ac9dc2fa635d9b1f34fd73994c06", 1)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, 1, len(commits))
+
+	_, err = RangeCommits("nonexistent..master", 50)
+	assert.Equal(t, "Can't load commits for nonexistent..master", err.Error())
+}
+
+func TestGitAheadBehind(t *testing.T) {
+	repo := fixtures.SetupTestRepo()
+	defer repo.TearDown()
+
+	ahead, behind, err := AheadBehind("08f4b7b6513dffc6245857e497cfd6101dc47818", "9b5a719a3d76ac9dc2fa635d9b1f34fd73994c06")
+	assert.Equal(t, nil, err)
+	assert.Equal(t, 1, ahead)
+	assert.Equal(t, 0, behind)
+
+	ahead, behind, err = AheadBehind("9b5a719a3d76ac9dc2fa635d9b1f34fd73994c06", "08f4b7b6513dffc6245857e497cfd6101dc47818")
+	assert.Equal(t, nil, err)
+	assert.Equal(t, 0, ahead)
+	assert.Equal(t, 1, behind)
+
+	assert.T(t, HasCommit("9b5a719a3d76ac9dc2fa635d9b1f34fd73994c06"))
+	assert.T(t, !HasCommit("0000000000000000000000000000000000000001"))
+}
+
 func TestGitShow(t *testing.T) {
 	repo := fixtures.SetupTestRepo()
 	defer repo.TearDown()
@@ -99,60 +148,35 @@ func TestGitConfig(t *testing.T) {
 	assert.Equal(t, "", v)
 }
 
-func TestRemotes(t *testing.T) {
+func TestGlobalConfigEntries(t *testing.T) {
 	repo := fixtures.SetupTestRepo()
 	defer repo.TearDown()
 
-	type remote struct {
-		name    string
-		url     string
-		pushUrl string
-	}
-	testCases := map[string]remote{
-		"testremote1": {
-			"testremote1",
-			"https://example.com/test1/project1.git",
-			"no_push",
-		},
-		"testremote2": {
-			"testremote2",
-			"user@example.com:test2/project2.git",
-			"http://example.com/project.git",
-		},
-		"testremote3": {
-			"testremote3",
-			"https://example.com/test1/project2.git",
-			"",
-		},
-	}
-
-	for _, tc := range testCases {
-		repo.AddRemote(tc.name, tc.url, tc.pushUrl)
-	}
-
-	remotes, err := Remotes()
-	assert.Equal(t, nil, err)
-
-	// In addition to the remotes we added to the repo, repo will
-	// also have an additional remote "origin". So add it to the
-	// expected cases to test.
-	wantCases := map[string]struct{}{
-		fmt.Sprintf("origin	%s (fetch)", repo.Remote): {},
-		fmt.Sprintf("origin	%s (push)", repo.Remote): {},
-		"testremote1	https://example.com/test1/project1.git (fetch)": {},
-		"testremote1	no_push (push)": {},
-		"testremote2	user@example.com:test2/project2.git (fetch)": {},
-		"testremote2	http://example.com/project.git (push)": {},
-		"testremote3	https://example.com/test1/project2.git (fetch)": {},
-		"testremote3	https://example.com/test1/project2.git (push)": {},
-	}
-
-	assert.Equal(t, len(remotes), len(wantCases))
-	for _, got := range remotes {
-		if _, ok := wantCases[got]; !ok {
-			t.Errorf("Unexpected remote: %s", got)
-		}
-	}
+	entries, err := GlobalConfigEntries(`^hub\.`)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, []ConfigEntry{}, entries)
+
+	SetGlobalConfig("hub.protocol", "https")
+	AddGlobalConfig("hub.host", "git.example.com")
+	AddGlobalConfig("hub.host", "gh.example.org")
+	SetGlobalConfig("alias.lg", "log --graph\n--oneline")
+
+	entries, err = GlobalConfigEntries(`^hub\.`)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, []ConfigEntry{
+		{"hub.protocol", "https"},
+		{"hub.host", "git.example.com"},
+		{"hub.host", "gh.example.org"},
+	}, entries)
+
+	entries, err = GlobalConfigEntries(`^alias\.`)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, []ConfigEntry{{"alias.lg", "log --graph\n--oneline"}}, entries)
+
+	assert.Equal(t, nil, UnsetGlobalConfig("hub.host"))
+	assert.Equal(t, nil, UnsetGlobalConfig("hub.host"))
+	_, err = GlobalConfig("hub.host")
+	assert.NotEqual(t, nil, err)
 }
 
 func TestCommentChar(t *testing.T) {
@@ -184,3 +208,54 @@ func TestCommentChar(t *testing.T) {
 	char, err = CommentChar("#\n;\n@\n!\n$\n%\n^\n&\n|\n:")
 	assert.Equal(t, "unable to select a comment character that is not used in the current message", err.Error())
 }
+
+func TestGitPath(t *testing.T) {
+	repo := fixtures.SetupTestRepo()
+	defer repo.TearDown()
+
+	hook, err := GitPath("hooks", "pre-push")
+	assert.Equal(t, nil, err)
+	assert.T(t, strings.HasSuffix(hook, "/.git/hooks/pre-push"))
+
+	assert.T(t, Quiet("config", "core.hooksPath", "/tmp/hub-hooks"))
+	hook, err = GitPath("hooks", "pre-push")
+	assert.Equal(t, nil, err)
+	assert.Equal(t, "/tmp/hub-hooks/pre-push", hook)
+}
+
+func TestRemotePrune(t *testing.T) {
+	repo := fixtures.SetupTestRepo()
+	defer repo.TearDown()
+
+	assert.T(t, Quiet("update-ref", "refs/remotes/origin/stale", "HEAD"))
+
+	pruned, err := RemotePrune("origin", true)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, []string{"origin/stale"}, pruned)
+	assert.T(t, HasCommit("refs/remotes/origin/stale"))
+
+	pruned, err = RemotePrune("origin", false)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, []string{"origin/stale"}, pruned)
+	assert.T(t, !HasCommit("refs/remotes/origin/stale"))
+
+	pruned, err = RemotePrune("origin", false)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, []string{}, pruned)
+}
+
+func TestWorktreeBranches(t *testing.T) {
+	repo := fixtures.SetupTestRepo()
+	defer repo.TearDown()
+
+	workdir, _ := WorkdirName()
+	worktree := workdir + "-feature"
+	assert.T(t, Quiet("worktree", "add", "-q", "-b", "feature", worktree))
+	defer os.RemoveAll(worktree)
+
+	branches, err := WorktreeBranches()
+	assert.Equal(t, nil, err)
+	assert.Equal(t, 2, len(branches))
+	assert.Equal(t, worktree, branches["feature"])
+	assert.Equal(t, workdir, branches["master"])
+}
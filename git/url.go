@@ -64,3 +64,73 @@ func ParseURL(rawURL string) (u *url.URL, err error) {
 
 	return p.Parse(rawURL)
 }
+
+// URLRewrites are the "url.<base>.insteadOf" and "url.<base>.pushInsteadOf"
+// rules of git config, which replace a URL prefix with <base>.
+type URLRewrites struct {
+	insteadOf     map[string]string
+	pushInsteadOf map[string]string
+}
+
+var urlRewriteRe = regexp.MustCompile(`^url\.(.+)\.(insteadof|pushinsteadof)$`)
+
+// NewURLRewrites builds rewrite rules from "url.*" config entries.
+func NewURLRewrites(entries []ConfigEntry) *URLRewrites {
+	r := &URLRewrites{
+		insteadOf:     map[string]string{},
+		pushInsteadOf: map[string]string{},
+	}
+	for _, entry := range entries {
+		match := urlRewriteRe.FindStringSubmatch(entry.Name)
+		if match == nil {
+			continue
+		}
+		if match[2] == "pushinsteadof" {
+			r.pushInsteadOf[entry.Value] = match[1]
+		} else {
+			r.insteadOf[entry.Value] = match[1]
+		}
+	}
+	return r
+}
+
+// ReadURLRewrites reads the rewrite rules from git config.
+func ReadURLRewrites() (*URLRewrites, error) {
+	entries, err := ConfigEntries(`^url\..*\.(insteadof|pushinsteadof)$`)
+	if err != nil {
+		return nil, err
+	}
+	return NewURLRewrites(entries), nil
+}
+
+// rewrite replaces the longest of the prefixes that rawURL starts with, like
+// git does. The second return value reports whether any prefix matched.
+func rewrite(rules map[string]string, rawURL string) (string, bool) {
+	longest := ""
+	for prefix := range rules {
+		if len(prefix) > len(longest) && strings.HasPrefix(rawURL, prefix) {
+			longest = prefix
+		}
+	}
+	if longest == "" {
+		return rawURL, false
+	}
+	return rules[longest] + strings.TrimPrefix(rawURL, longest), true
+}
+
+// Fetch rewrites the URL that git fetches from, as well as an explicit
+// "remote.<name>.pushurl".
+func (r *URLRewrites) Fetch(rawURL string) string {
+	u, _ := rewrite(r.insteadOf, rawURL)
+	return u
+}
+
+// Push rewrites "remote.<name>.url" for pushing when the remote has no
+// explicit push URL. Rules of pushInsteadOf take precedence over those of
+// insteadOf.
+func (r *URLRewrites) Push(rawURL string) string {
+	if u, ok := rewrite(r.pushInsteadOf, rawURL); ok {
+		return u
+	}
+	return r.Fetch(rawURL)
+}
@@ -83,3 +83,29 @@ func TestURLParser_ParseURL_LocalPath(t *testing.T) {
 	assert.Equal(t, nil, err)
 	assert.Equal(t, `c:\path\to\repo.git`, u.String())
 }
+
+func TestURLRewrites(t *testing.T) {
+	r := NewURLRewrites([]ConfigEntry{
+		{Name: "url.git@github.com:.insteadof", Value: "https://github.com/"},
+		{Name: "url.https://github.com/.insteadof", Value: "gh:"},
+		{Name: "url.git@github.com:mirrors/.insteadof", Value: "https://github.com/upstream-"},
+		{Name: "url.ssh://push.example.com/.pushinsteadof", Value: "https://example.com/"},
+		{Name: "url.ssh://git@github.com/.pushinsteadof", Value: "gh:"},
+		{Name: "user.name", Value: "Mislav"},
+	})
+
+	assert.Equal(t, "git@github.com:github/hub.git", r.Fetch("https://github.com/github/hub.git"))
+	assert.Equal(t, "https://github.com/github/hub", r.Fetch("gh:github/hub"))
+	// the longest matching prefix wins
+	assert.Equal(t, "git@github.com:mirrors/hub", r.Fetch("https://github.com/upstream-hub"))
+	// rewritten URLs aren't rewritten again
+	assert.Equal(t, "git@github.com:github/hub", r.Fetch("https://github.com/github/hub"))
+	// prefixes only match at the start
+	assert.Equal(t, "ssh://https://github.com/hub", r.Fetch("ssh://https://github.com/hub"))
+	assert.Equal(t, "https://github.co/hub", r.Fetch("https://github.co/hub"))
+
+	assert.Equal(t, "https://example.com/project.git", r.Fetch("https://example.com/project.git"))
+	assert.Equal(t, "ssh://push.example.com/project.git", r.Push("https://example.com/project.git"))
+	assert.Equal(t, "ssh://git@github.com/github/hub", r.Push("gh:github/hub"))
+	assert.Equal(t, "git@github.com:github/hub", r.Push("https://github.com/github/hub"))
+}
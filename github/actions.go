@@ -0,0 +1,258 @@
+package github
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"fmt"
+	"net/url"
+	"time"
+
+	"golang.org/x/crypto/nacl/box"
+)
+
+// ActionsScope identifies where Actions secrets and variables are stored: in
+// a repository, in one of its deployment environments, or in an organization.
+type ActionsScope struct {
+	Project     *Project
+	Environment string
+	Org         string
+}
+
+func (s *ActionsScope) path(kind string) string {
+	if s.Org != "" {
+		return fmt.Sprintf("orgs/%s/actions/%s", s.Org, kind)
+	} else if s.Environment != "" {
+		return fmt.Sprintf("repos/%s/%s/environments/%s/%s", s.Project.Owner, s.Project.Name, url.PathEscape(s.Environment), kind)
+	}
+	return fmt.Sprintf("repos/%s/%s/actions/%s", s.Project.Owner, s.Project.Name, kind)
+}
+
+func (s *ActionsScope) String() string {
+	if s.Org != "" {
+		return "organization " + s.Org
+	} else if s.Environment != "" {
+		return fmt.Sprintf("environment %s of %s", s.Environment, s.Project)
+	}
+	return s.Project.String()
+}
+
+type SecretPublicKey struct {
+	KeyId string `json:"key_id"`
+	Key   string `json:"key"`
+}
+
+type Secret struct {
+	Name       string    `json:"name"`
+	Visibility string    `json:"visibility"`
+	CreatedAt  time.Time `json:"created_at"`
+	UpdatedAt  time.Time `json:"updated_at"`
+}
+
+func (client *Client) FetchSecretPublicKey(scope *ActionsScope) (key *SecretPublicKey, err error) {
+	api, err := client.simpleApi()
+	if err != nil {
+		return
+	}
+
+	res, err := api.Get(scope.path("secrets/public-key"))
+	if err = checkStatus(200, "fetching public key", res, err); err != nil {
+		return
+	}
+
+	key = &SecretPublicKey{}
+	err = res.Unmarshal(key)
+	return
+}
+
+func (client *Client) FetchSecrets(scope *ActionsScope) (secrets []Secret, err error) {
+	api, err := client.simpleApi()
+	if err != nil {
+		return
+	}
+
+	path := scope.path("secrets") + "?per_page=100"
+
+	secrets = []Secret{}
+	var res *simpleResponse
+
+	for path != "" {
+		res, err = api.Get(path)
+		if err = checkStatus(200, "fetching secrets", res, err); err != nil {
+			return
+		}
+		path = res.Link("next")
+
+		secretsPage := struct {
+			Secrets []Secret `json:"secrets"`
+		}{}
+		if err = res.Unmarshal(&secretsPage); err != nil {
+			return
+		}
+		secrets = append(secrets, secretsPage.Secrets...)
+	}
+
+	return
+}
+
+// SetSecret creates or updates a secret with a value that was encrypted with
+// EncryptSecret. The visibility only applies to organization secrets.
+func (client *Client) SetSecret(scope *ActionsScope, name, encryptedValue, keyId, visibility string) (err error) {
+	api, err := client.simpleApi()
+	if err != nil {
+		return
+	}
+
+	params := map[string]interface{}{
+		"encrypted_value": encryptedValue,
+		"key_id":          keyId,
+	}
+	if scope.Org != "" {
+		if visibility == "" {
+			visibility = "private"
+		}
+		params["visibility"] = visibility
+	}
+
+	res, err := api.PutJSON(scope.path("secrets/"+url.PathEscape(name)), params)
+	if err == nil && res.StatusCode == 201 {
+		res.discard()
+		return
+	}
+	if err = checkStatus(204, "setting secret", res, err); err != nil {
+		return
+	}
+
+	res.discard()
+	return
+}
+
+func (client *Client) DeleteSecret(scope *ActionsScope, name string) (err error) {
+	api, err := client.simpleApi()
+	if err != nil {
+		return
+	}
+
+	res, err := api.Delete(scope.path("secrets/" + url.PathEscape(name)))
+	if err = checkStatus(204, "deleting secret", res, err); err != nil {
+		return
+	}
+
+	res.discard()
+	return
+}
+
+// EncryptSecret seals value with the public key of a secrets endpoint so that
+// only GitHub can decrypt it, returning the base64 encoding the API expects.
+func EncryptSecret(key *SecretPublicKey, value []byte) (string, error) {
+	decoded, err := base64.StdEncoding.DecodeString(key.Key)
+	if err != nil {
+		return "", fmt.Errorf("invalid public key: %s", err)
+	}
+	if len(decoded) != 32 {
+		return "", fmt.Errorf("invalid public key: expected 32 bytes, got %d", len(decoded))
+	}
+
+	var publicKey [32]byte
+	copy(publicKey[:], decoded)
+
+	sealed, err := box.SealAnonymous(nil, value, &publicKey, rand.Reader)
+	if err != nil {
+		return "", err
+	}
+	return base64.StdEncoding.EncodeToString(sealed), nil
+}
+
+type Variable struct {
+	Name       string    `json:"name"`
+	Value      string    `json:"value"`
+	Visibility string    `json:"visibility"`
+	CreatedAt  time.Time `json:"created_at"`
+	UpdatedAt  time.Time `json:"updated_at"`
+}
+
+func (client *Client) FetchVariables(scope *ActionsScope) (variables []Variable, err error) {
+	api, err := client.simpleApi()
+	if err != nil {
+		return
+	}
+
+	path := scope.path("variables") + "?per_page=30"
+
+	variables = []Variable{}
+	var res *simpleResponse
+
+	for path != "" {
+		res, err = api.Get(path)
+		if err = checkStatus(200, "fetching variables", res, err); err != nil {
+			return
+		}
+		path = res.Link("next")
+
+		variablesPage := struct {
+			Variables []Variable `json:"variables"`
+		}{}
+		if err = res.Unmarshal(&variablesPage); err != nil {
+			return
+		}
+		variables = append(variables, variablesPage.Variables...)
+	}
+
+	return
+}
+
+// SetVariable creates a variable, or updates its value if a variable with the
+// same name already exists. The visibility only applies to organization
+// variables, which are created as private unless another visibility is given.
+func (client *Client) SetVariable(scope *ActionsScope, name, value, visibility string) (err error) {
+	api, err := client.simpleApi()
+	if err != nil {
+		return
+	}
+
+	params := map[string]interface{}{
+		"name":  name,
+		"value": value,
+	}
+	if scope.Org != "" {
+		params["visibility"] = "private"
+		if visibility != "" {
+			params["visibility"] = visibility
+		}
+	}
+
+	res, err := api.PostJSON(scope.path("variables"), params)
+	if err == nil && res.StatusCode == 409 {
+		// keep the visibility of the existing variable unless told otherwise
+		if visibility == "" {
+			delete(params, "visibility")
+		}
+		res.discard()
+		res, err = api.PatchJSON(scope.path("variables/"+url.PathEscape(name)), params)
+		if err = checkStatus(204, "updating variable", res, err); err != nil {
+			return
+		}
+		res.discard()
+		return
+	}
+	if err = checkStatus(201, "creating variable", res, err); err != nil {
+		return
+	}
+
+	res.discard()
+	return
+}
+
+func (client *Client) DeleteVariable(scope *ActionsScope, name string) (err error) {
+	api, err := client.simpleApi()
+	if err != nil {
+		return
+	}
+
+	res, err := api.Delete(scope.path("variables/" + url.PathEscape(name)))
+	if err = checkStatus(204, "deleting variable", res, err); err != nil {
+		return
+	}
+
+	res.discard()
+	return
+}
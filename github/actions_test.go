@@ -0,0 +1,158 @@
+package github
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/bmizerany/assert"
+	"golang.org/x/crypto/nacl/box"
+)
+
+// a keypair derived from a fixed seed, so that tests can open sealed values
+const (
+	testSecretPublicKey  = "E75P6uryBMf9M1j8nAByGIHRdCeBKCJ+xnTzf3/pe20="
+	testSecretPrivateKey = "BwcHBwcHBwcHBwcHBwcHBwcHBwcHBwcHBwcHBwcHBwc="
+)
+
+func openTestSecret(t *testing.T, encrypted string) string {
+	sealed, err := base64.StdEncoding.DecodeString(encrypted)
+	assert.Equal(t, nil, err)
+
+	var publicKey, privateKey [32]byte
+	pub, _ := base64.StdEncoding.DecodeString(testSecretPublicKey)
+	priv, _ := base64.StdEncoding.DecodeString(testSecretPrivateKey)
+	copy(publicKey[:], pub)
+	copy(privateKey[:], priv)
+
+	opened, ok := box.OpenAnonymous(nil, sealed, &publicKey, &privateKey)
+	assert.T(t, ok)
+	return string(opened)
+}
+
+func TestEncryptSecret(t *testing.T) {
+	key := &SecretPublicKey{KeyId: "1234", Key: testSecretPublicKey}
+
+	encrypted, err := EncryptSecret(key, []byte("s3cr3t\nvalue"))
+	assert.Equal(t, nil, err)
+	assert.Equal(t, "s3cr3t\nvalue", openTestSecret(t, encrypted))
+
+	// sealed boxes use a fresh ephemeral key every time
+	again, err := EncryptSecret(key, []byte("s3cr3t\nvalue"))
+	assert.Equal(t, nil, err)
+	assert.NotEqual(t, encrypted, again)
+}
+
+func TestEncryptSecret_Empty(t *testing.T) {
+	key := &SecretPublicKey{KeyId: "1234", Key: testSecretPublicKey}
+
+	encrypted, err := EncryptSecret(key, []byte{})
+	assert.Equal(t, nil, err)
+	sealed, _ := base64.StdEncoding.DecodeString(encrypted)
+	assert.Equal(t, box.AnonymousOverhead, len(sealed))
+	assert.Equal(t, "", openTestSecret(t, encrypted))
+}
+
+func TestEncryptSecret_InvalidKey(t *testing.T) {
+	_, err := EncryptSecret(&SecretPublicKey{Key: "not base64!"}, []byte("value"))
+	assert.NotEqual(t, nil, err)
+	assert.Equal(t, "invalid public key: illegal base64 data at input byte 3", err.Error())
+
+	short := base64.StdEncoding.EncodeToString([]byte("too short"))
+	_, err = EncryptSecret(&SecretPublicKey{Key: short}, []byte("value"))
+	assert.NotEqual(t, nil, err)
+	assert.Equal(t, "invalid public key: expected 32 bytes, got 9", err.Error())
+}
+
+func TestClient_FetchSecretPublicKey(t *testing.T) {
+	s := setupTestServer("")
+	defer s.Close()
+
+	paths := map[string]string{
+		"/api/v3/repos/mislav/dotfiles/actions/secrets/public-key":                 "repo",
+		"/api/v3/repos/mislav/dotfiles/environments/pre%20prod/secrets/public-key": "env",
+		"/api/v3/orgs/acme/actions/secrets/public-key":                             "org",
+	}
+	requested := []string{}
+	s.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		requested = append(requested, paths[r.URL.EscapedPath()])
+		assert.Equal(t, "token OTOKEN", r.Header.Get("Authorization"))
+		json.NewEncoder(w).Encode(map[string]string{
+			"key_id": "568250167242549743",
+			"key":    testSecretPublicKey,
+		})
+	})
+
+	client := NewClientWithHost(&Host{
+		Host:        s.URL.Host,
+		AccessToken: "OTOKEN",
+		Protocol:    "http",
+	})
+	project := &Project{Owner: "mislav", Name: "dotfiles", Host: s.URL.Host}
+
+	scopes := []*ActionsScope{
+		{Project: project},
+		{Project: project, Environment: "pre prod"},
+		{Project: project, Org: "acme"},
+	}
+	for _, scope := range scopes {
+		key, err := client.FetchSecretPublicKey(scope)
+		assert.Equal(t, nil, err)
+		assert.Equal(t, "568250167242549743", key.KeyId)
+
+		encrypted, err := EncryptSecret(key, []byte("value"))
+		assert.Equal(t, nil, err)
+		assert.Equal(t, "value", openTestSecret(t, encrypted))
+	}
+	assert.Equal(t, "[repo env org]", fmt.Sprint(requested))
+}
+
+func TestClient_SetVariable(t *testing.T) {
+	s := setupTestServer("")
+	defer s.Close()
+
+	existing := map[string]bool{"API_URL": true}
+	requests := []string{}
+	s.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		params := map[string]string{}
+		json.NewDecoder(r.Body).Decode(&params)
+		requests = append(requests, fmt.Sprintf("%s %s %v", r.Method, r.URL.Path, params))
+
+		if r.Method == "POST" && existing[params["name"]] {
+			w.WriteHeader(http.StatusConflict)
+			return
+		} else if r.Method == "POST" {
+			w.WriteHeader(http.StatusCreated)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	})
+
+	client := NewClientWithHost(&Host{
+		Host:        s.URL.Host,
+		AccessToken: "OTOKEN",
+		Protocol:    "http",
+	})
+	project := &Project{Owner: "mislav", Name: "dotfiles", Host: s.URL.Host}
+
+	err := client.SetVariable(&ActionsScope{Project: project}, "NEW", "1", "")
+	assert.Equal(t, nil, err)
+	err = client.SetVariable(&ActionsScope{Project: project}, "API_URL", "2", "")
+	assert.Equal(t, nil, err)
+	err = client.SetVariable(&ActionsScope{Org: "acme"}, "API_URL", "3", "")
+	assert.Equal(t, nil, err)
+	err = client.SetVariable(&ActionsScope{Org: "acme"}, "API_URL", "4", "all")
+	assert.Equal(t, nil, err)
+
+	assert.Equal(t, []string{
+		"POST /api/v3/repos/mislav/dotfiles/actions/variables map[name:NEW value:1]",
+		"POST /api/v3/repos/mislav/dotfiles/actions/variables map[name:API_URL value:2]",
+		"PATCH /api/v3/repos/mislav/dotfiles/actions/variables/API_URL map[name:API_URL value:2]",
+		"POST /api/v3/orgs/acme/actions/variables map[name:API_URL value:3 visibility:private]",
+		"PATCH /api/v3/orgs/acme/actions/variables/API_URL map[name:API_URL value:3]",
+		"POST /api/v3/orgs/acme/actions/variables map[name:API_URL value:4 visibility:all]",
+		"PATCH /api/v3/orgs/acme/actions/variables/API_URL map[name:API_URL value:4 visibility:all]",
+	}, requests)
+}
@@ -0,0 +1,148 @@
+package github
+
+import (
+	"fmt"
+	"net/url"
+	"time"
+)
+
+// RateLimit is the state of one of the API rate limits.
+type RateLimit struct {
+	Limit     int   `json:"limit"`
+	Remaining int   `json:"remaining"`
+	Reset     int64 `json:"reset"`
+}
+
+// ResetAt returns when the rate limit is replenished.
+func (r *RateLimit) ResetAt() time.Time {
+	return time.Unix(r.Reset, 0)
+}
+
+// RateLimits returns the rate limits of the core API and of the search API.
+// Looking them up doesn't count against either.
+func (client *Client) RateLimits() (core, search *RateLimit, err error) {
+	api, err := client.simpleApi()
+	if err != nil {
+		return
+	}
+
+	res, err := api.Get("rate_limit")
+	if err = checkStatus(200, "fetching rate limits", res, err); err != nil {
+		return
+	}
+
+	result := struct {
+		Resources struct {
+			Core   *RateLimit `json:"core"`
+			Search *RateLimit `json:"search"`
+		} `json:"resources"`
+	}{}
+	if err = res.Unmarshal(&result); err != nil {
+		return
+	}
+	if result.Resources.Core == nil || result.Resources.Search == nil {
+		err = fmt.Errorf("Error fetching rate limits: missing from the response")
+		return
+	}
+	return result.Resources.Core, result.Resources.Search, nil
+}
+
+// FetchOrgRepositories lists up to limit repositories of org that the user can
+// see, most recently pushed to first. Archived repositories are left out.
+func (client *Client) FetchOrgRepositories(org string, limit int) (repos []Repository, err error) {
+	api, err := client.simpleApi()
+	if err != nil {
+		return
+	}
+
+	path := fmt.Sprintf("orgs/%s/repos?sort=pushed&direction=desc&per_page=%d", url.PathEscape(org), perPage(limit, 100))
+	repos = []Repository{}
+	for path != "" {
+		res, err := api.Get(path)
+		if err = checkStatus(200, "fetching organization repositories", res, err); err != nil {
+			return nil, err
+		}
+		path = res.Link("next")
+
+		page := []Repository{}
+		if err = res.Unmarshal(&page); err != nil {
+			return nil, err
+		}
+		for _, repo := range page {
+			if repo.Archived {
+				continue
+			}
+			repos = append(repos, repo)
+			if limit > 0 && len(repos) == limit {
+				return repos, nil
+			}
+		}
+	}
+	return
+}
+
+// FetchReleasesSince lists the releases of project that were published after
+// since, newest first. Drafts are left out.
+func (client *Client) FetchReleasesSince(project *Project, since time.Time) (releases []Release, err error) {
+	api, err := client.simpleApi()
+	if err != nil {
+		return
+	}
+
+	path := fmt.Sprintf("repos/%s/%s/releases?per_page=100", project.Owner, project.Name)
+	releases = []Release{}
+	for path != "" {
+		res, err := api.Get(path)
+		if err = checkStatus(200, "fetching releases", res, err); err != nil {
+			return nil, err
+		}
+		path = res.Link("next")
+
+		page := []Release{}
+		if err = res.Unmarshal(&page); err != nil {
+			return nil, err
+		}
+		for _, release := range page {
+			if !release.Draft && release.PublishedAt.After(since) {
+				releases = append(releases, release)
+			}
+			// releases are listed by when they were created, which is never
+			// after they were published
+			if release.CreatedAt.Before(since) {
+				path = ""
+			}
+		}
+	}
+	return
+}
+
+// HasCommitsBefore tells whether author has authored any commits in the
+// default branch of project before until.
+func (client *Client) HasCommitsBefore(project *Project, author string, until time.Time) (bool, error) {
+	api, err := client.simpleApi()
+	if err != nil {
+		return false, err
+	}
+
+	params := url.Values{}
+	params.Set("author", author)
+	params.Set("until", until.UTC().Format(time.RFC3339))
+	params.Set("per_page", "1")
+	res, err := api.Get(fmt.Sprintf("repos/%s/%s/commits?%s", project.Owner, project.Name, params.Encode()))
+	if err == nil && res.StatusCode == 409 {
+		// the repository is empty
+		res.discard()
+		return false, nil
+	}
+	if err = checkStatus(200, "fetching commits", res, err); err != nil {
+		return false, err
+	}
+
+	commits := []struct {
+		Sha string `json:"sha"`
+	}{}
+	if err = res.Unmarshal(&commits); err != nil {
+		return false, err
+	}
+	return len(commits) > 0, nil
+}
@@ -0,0 +1,75 @@
+package github
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/bmizerany/assert"
+)
+
+func TestClient_HasCommitsBefore(t *testing.T) {
+	client, cleanup := setupTestClient(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "2024-05-01T00:00:00Z", r.URL.Query().Get("until"))
+		assert.Equal(t, "1", r.URL.Query().Get("per_page"))
+		switch r.URL.Path {
+		case "/repos/mislav/dotfiles/commits":
+			if r.URL.Query().Get("author") == "mislav" {
+				fmt.Fprint(w, `[{"sha": "abc123"}]`)
+			} else {
+				fmt.Fprint(w, `[]`)
+			}
+		case "/repos/mislav/empty/commits":
+			w.WriteHeader(http.StatusConflict)
+			fmt.Fprint(w, `{"message": "Git Repository is empty."}`)
+		}
+	})
+	defer cleanup()
+
+	until := time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)
+	project := &Project{Owner: "mislav", Name: "dotfiles"}
+	found, err := client.HasCommitsBefore(project, "mislav", until)
+	assert.Equal(t, nil, err)
+	assert.T(t, found)
+
+	found, err = client.HasCommitsBefore(project, "hubot", until)
+	assert.Equal(t, nil, err)
+	assert.T(t, !found)
+
+	found, err = client.HasCommitsBefore(&Project{Owner: "mislav", Name: "empty"}, "hubot", until)
+	assert.Equal(t, nil, err)
+	assert.T(t, !found)
+}
+
+func TestClient_PauseOnRateLimit(t *testing.T) {
+	requests := 0
+	client, cleanup := setupTestClient(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		if requests == 1 {
+			w.Header().Set("Retry-After", "3")
+			w.WriteHeader(http.StatusForbidden)
+			fmt.Fprint(w, `{"message": "You have exceeded a secondary rate limit."}`)
+			return
+		}
+		fmt.Fprint(w, `{"resources": {
+			"core": {"limit": 5000, "remaining": 4990, "reset": 1714521600},
+			"search": {"limit": 30, "remaining": `+strconv.Itoa(30-requests)+`, "reset": 1714521600}
+		}}`)
+	})
+	defer cleanup()
+
+	waits := []time.Duration{}
+	client.PauseOnRateLimit(func(wait time.Duration) error {
+		waits = append(waits, wait)
+		return nil
+	})
+
+	core, search, err := client.RateLimits()
+	assert.Equal(t, nil, err)
+	assert.Equal(t, []time.Duration{3 * time.Second}, waits)
+	assert.Equal(t, 4990, core.Remaining)
+	assert.Equal(t, 28, search.Remaining)
+	assert.Equal(t, time.Unix(1714521600, 0), search.ResetAt())
+}
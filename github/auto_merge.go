@@ -0,0 +1,153 @@
+package github
+
+import (
+	"fmt"
+	"strings"
+)
+
+// GraphQLError holds the errors that a GraphQL request was answered with.
+type GraphQLError struct {
+	Action   string
+	Messages []string
+}
+
+func (e *GraphQLError) Error() string {
+	return fmt.Sprintf("Error %s: %s", e.Action, strings.Join(e.Messages, "\n"))
+}
+
+func (e *GraphQLError) contains(message string) bool {
+	for _, m := range e.Messages {
+		if strings.Contains(strings.ToLower(m), strings.ToLower(message)) {
+			return true
+		}
+	}
+	return false
+}
+
+func (client *Client) graphQL(action, query string, variables map[string]interface{}, data interface{}) error {
+	api, err := client.simpleApi()
+	if err != nil {
+		return err
+	}
+
+	payload := map[string]interface{}{
+		"query":     query,
+		"variables": variables,
+	}
+	res, err := api.PostJSON("graphql", payload)
+	if err = checkStatus(200, action, res, err); err != nil {
+		return err
+	}
+
+	result := struct {
+		Data   interface{} `json:"data"`
+		Errors []struct {
+			Message string `json:"message"`
+		} `json:"errors"`
+	}{Data: data}
+	if err = res.Unmarshal(&result); err != nil {
+		return err
+	}
+	if len(result.Errors) > 0 {
+		graphQLErr := &GraphQLError{Action: action}
+		for _, e := range result.Errors {
+			graphQLErr.Messages = append(graphQLErr.Messages, e.Message)
+		}
+		return graphQLErr
+	}
+	return nil
+}
+
+// An AutoMergeNotAllowedError means that auto-merge is turned off in the
+// settings of the repository.
+type AutoMergeNotAllowedError struct {
+	Project *Project
+}
+
+func (e *AutoMergeNotAllowedError) Error() string {
+	return fmt.Sprintf("auto-merge isn't allowed in %s", e.Project)
+}
+
+// EnableAutoMerge has the pull request merged with method ("merge", "squash"
+// or "rebase") once the requirements of its base branch are met. A pull
+// request that already meets them gets merged right away, which is reported
+// with merged.
+func (client *Client) EnableAutoMerge(project *Project, pr *PullRequest, method string) (merged bool, err error) {
+	query := `mutation($id: ID!, $method: PullRequestMergeMethod) {
+  enablePullRequestAutoMerge(input: {pullRequestId: $id, mergeMethod: $method}) {
+    pullRequest { merged }
+  }
+}`
+	variables := map[string]interface{}{
+		"id":     pr.NodeId,
+		"method": strings.ToUpper(method),
+	}
+	data := struct {
+		EnablePullRequestAutoMerge struct {
+			PullRequest struct {
+				Merged bool `json:"merged"`
+			} `json:"pullRequest"`
+		} `json:"enablePullRequestAutoMerge"`
+	}{}
+
+	err = client.graphQL("enabling auto-merge", query, variables, &data)
+	if graphQLErr, ok := err.(*GraphQLError); ok {
+		if graphQLErr.contains("auto merge is not allowed") {
+			err = &AutoMergeNotAllowedError{project}
+		} else if graphQLErr.contains("clean status") {
+			// there's nothing to wait for, which the API refuses to auto-merge
+			err = client.MergePullRequest(project, pr.Number, method, "", "")
+			merged = err == nil
+		}
+		return
+	}
+	merged = data.EnablePullRequestAutoMerge.PullRequest.Merged
+	return
+}
+
+// DisableAutoMerge cancels auto-merge of the pull request.
+func (client *Client) DisableAutoMerge(pr *PullRequest) error {
+	query := `mutation($id: ID!) {
+  disablePullRequestAutoMerge(input: {pullRequestId: $id}) {
+    pullRequest { number }
+  }
+}`
+	return client.graphQL("disabling auto-merge", query, map[string]interface{}{"id": pr.NodeId}, nil)
+}
+
+// MergePullRequest merges the pull request with method ("merge", "squash" or
+// "rebase"). The title and the message of the merge commit are left for
+// GitHub to fill in when empty. A method that the settings of the repository
+// don't allow is refused with HTTP 405.
+func (client *Client) MergePullRequest(project *Project, number int, method, title, message string) (err error) {
+	api, err := client.simpleApi()
+	if err != nil {
+		return
+	}
+
+	params := map[string]interface{}{"merge_method": method}
+	if title != "" {
+		params["commit_title"] = title
+	}
+	if message != "" {
+		params["commit_message"] = message
+	}
+	res, err := api.PutJSON(fmt.Sprintf("repos/%s/%s/pulls/%d/merge", project.Owner, project.Name, number), params)
+	if err = checkStatus(200, "merging pull request", res, err); err != nil {
+		return
+	}
+
+	res.discard()
+	return
+}
+
+// MarkPullRequestReady takes a draft pull request out of draft, which the REST
+// API has no way of doing.
+func (client *Client) MarkPullRequestReady(pr *PullRequest) error {
+	query := `mutation($id: ID!) {
+  markPullRequestReadyForReview(input: {pullRequestId: $id}) {
+    pullRequest { isDraft }
+  }
+}`
+	return client.graphQL("marking pull request as ready for review", query, map[string]interface{}{"id": pr.NodeId}, nil)
+}
@@ -0,0 +1,215 @@
+package github
+
+import (
+	"crypto/md5"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/github/hub/git"
+)
+
+// Feature is an API feature that older GitHub Enterprise versions lack.
+type Feature string
+
+const (
+	FeatureDraftPullRequests    Feature = "drafts"
+	FeatureAutoMerge            Feature = "auto-merge"
+	FeatureGenerateReleaseNotes Feature = "generate-notes"
+	FeatureProjectsV2           Feature = "projects-v2"
+	FeatureIssueForms           Feature = "issue-forms"
+)
+
+// featureVersions lists the GitHub Enterprise Server version that each feature
+// first shipped in. GitHub.com supports all of them.
+var featureVersions = map[Feature]string{
+	FeatureDraftPullRequests:    "2.17",
+	FeatureAutoMerge:            "3.1",
+	FeatureGenerateReleaseNotes: "3.5",
+	FeatureProjectsV2:           "3.8",
+	FeatureIssueForms:           "3.10",
+}
+
+var featureDescriptions = map[Feature]string{
+	FeatureDraftPullRequests:    "draft pull requests",
+	FeatureAutoMerge:            "auto-merge",
+	FeatureGenerateReleaseNotes: "generated release notes",
+	FeatureProjectsV2:           "Projects",
+	FeatureIssueForms:           "issue forms",
+}
+
+const capabilitiesTTL = 24 * time.Hour
+
+// capabilitiesDir is looked up late, since the home directory is cached once
+// it's known.
+var capabilitiesDir = func() string { return cacheDir("capabilities") }
+
+// Capabilities is what hub knows about the features of a host. The installed
+// version is looked up from the "meta" API of Enterprise hosts and remembered
+// on disk for a day, so that most commands don't need the extra request.
+type Capabilities struct {
+	Host      string    `json:"host"`
+	Version   string    `json:"installed_version"`
+	FetchedAt time.Time `json:"fetched_at"`
+
+	assumed map[Feature]bool
+}
+
+// Supports reports whether the host is known to have feature. Hosts whose
+// version couldn't be determined are assumed to have every feature, so that
+// the API gets the final say.
+func (c *Capabilities) Supports(feature Feature) bool {
+	if c.Version == "" || c.assumed[feature] {
+		return true
+	}
+	required, ok := featureVersions[feature]
+	return !ok || compareVersions(c.Version, required) >= 0
+}
+
+// Require returns an error explaining which version of GitHub Enterprise is
+// needed for feature if the host doesn't support it.
+func (c *Capabilities) Require(feature Feature) error {
+	if c.Supports(feature) {
+		return nil
+	}
+	description := featureDescriptions[feature]
+	if description == "" {
+		description = string(feature)
+	}
+	return fmt.Errorf("Error: %s requires GitHub Enterprise %s or later, but %s runs %s", description, featureVersions[feature], c.Host, c.Version)
+}
+
+// Supports reports whether the host of the client has feature.
+func (client *Client) Supports(feature Feature) bool {
+	return client.Capabilities().Supports(feature)
+}
+
+// RequireFeature returns an error for commands to abort with before making a
+// request that the host doesn't support.
+func (client *Client) RequireFeature(feature Feature) error {
+	return client.Capabilities().Require(feature)
+}
+
+// Capabilities determines the features of the host of the client when they're
+// first needed.
+func (client *Client) Capabilities() *Capabilities {
+	client.capabilitiesMutex.Lock()
+	defer client.capabilitiesMutex.Unlock()
+
+	if client.capabilities == nil {
+		host := client.Host.Host
+		caps := &Capabilities{Host: host}
+		if !strings.HasPrefix(normalizeHost(host), "api.github.") {
+			file := capabilitiesFile(host)
+			if cached := readCapabilities(file); cached != nil {
+				caps = cached
+			} else if version, err := client.installedVersion(); err == nil {
+				caps.Version = version
+				caps.FetchedAt = time.Now()
+				writeCapabilities(file, caps)
+			}
+		}
+		caps.assumed = assumedFeatures()
+		client.capabilities = caps
+	}
+	return client.capabilities
+}
+
+func (client *Client) installedVersion() (string, error) {
+	api, err := client.simpleApi()
+	if err != nil {
+		return "", err
+	}
+
+	res, err := api.Get("meta")
+	if err = checkStatus(200, "fetching server metadata", res, err); err != nil {
+		return "", err
+	}
+
+	meta := struct {
+		InstalledVersion string `json:"installed_version"`
+	}{}
+	err = res.Unmarshal(&meta)
+	return meta.InstalledVersion, err
+}
+
+// assumedFeatures reads the "hub.assumeFeatures" git config, which lists the
+// features that were backported to an appliance despite its version.
+func assumedFeatures() map[Feature]bool {
+	assumed := map[Feature]bool{}
+	values, _ := git.ConfigAll("hub.assumeFeatures")
+	for _, value := range values {
+		for _, name := range strings.FieldsFunc(value, func(r rune) bool { return r == ',' || r == ' ' }) {
+			assumed[Feature(strings.ToLower(name))] = true
+		}
+	}
+	return assumed
+}
+
+func capabilitiesFile(host string) string {
+	dir := capabilitiesDir()
+	if dir == "" {
+		return ""
+	}
+	return filepath.Join(dir, fmt.Sprintf("%x", md5.Sum([]byte(strings.ToLower(host)))))
+}
+
+func readCapabilities(file string) *Capabilities {
+	data, err := ioutil.ReadFile(file)
+	if err != nil {
+		return nil
+	}
+	caps := &Capabilities{}
+	if json.Unmarshal(data, caps) != nil || time.Since(caps.FetchedAt) > capabilitiesTTL {
+		return nil
+	}
+	return caps
+}
+
+func writeCapabilities(file string, caps *Capabilities) {
+	if file == "" {
+		return
+	}
+	if data, err := json.Marshal(caps); err == nil && os.MkdirAll(filepath.Dir(file), 0700) == nil {
+		ioutil.WriteFile(file, data, 0600)
+	}
+}
+
+// compareVersions compares dotted version numbers such as "3.1.12", ignoring
+// any suffix like "-rc1". It returns -1, 0 or 1.
+func compareVersions(a, b string) int {
+	aParts := versionParts(a)
+	bParts := versionParts(b)
+	for i := 0; i < len(aParts) || i < len(bParts); i++ {
+		var x, y int
+		if i < len(aParts) {
+			x = aParts[i]
+		}
+		if i < len(bParts) {
+			y = bParts[i]
+		}
+		if x < y {
+			return -1
+		} else if x > y {
+			return 1
+		}
+	}
+	return 0
+}
+
+func versionParts(version string) []int {
+	if i := strings.IndexAny(version, "-+ "); i >= 0 {
+		version = version[:i]
+	}
+	parts := []int{}
+	for _, part := range strings.Split(version, ".") {
+		n, _ := strconv.Atoi(part)
+		parts = append(parts, n)
+	}
+	return parts
+}
@@ -0,0 +1,90 @@
+package github
+
+import (
+	"io/ioutil"
+	"net/http"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/bmizerany/assert"
+	"github.com/github/hub/fixtures"
+	"github.com/github/hub/git"
+)
+
+func setupCapabilitiesDir(t *testing.T) func() {
+	dir, err := ioutil.TempDir("", "hub-capabilities-")
+	assert.Equal(t, nil, err)
+	originalDir := capabilitiesDir
+	capabilitiesDir = func() string { return dir }
+	return func() {
+		capabilitiesDir = originalDir
+		os.RemoveAll(dir)
+	}
+}
+
+func TestCompareVersions(t *testing.T) {
+	assert.Equal(t, 0, compareVersions("3.1", "3.1.0"))
+	assert.Equal(t, -1, compareVersions("2.22.5", "3.1"))
+	assert.Equal(t, 1, compareVersions("3.10.2", "3.9"))
+	assert.Equal(t, 0, compareVersions("3.5.0-rc1", "3.5"))
+}
+
+func TestCapabilities_Supports(t *testing.T) {
+	caps := &Capabilities{Host: "git.example.com", Version: "3.4.2"}
+	assert.Equal(t, true, caps.Supports(FeatureDraftPullRequests))
+	assert.Equal(t, true, caps.Supports(FeatureAutoMerge))
+	assert.Equal(t, false, caps.Supports(FeatureGenerateReleaseNotes))
+	assert.Equal(t, false, caps.Supports(FeatureIssueForms))
+	assert.Equal(t, nil, caps.Require(FeatureAutoMerge))
+	assert.Equal(t, "Error: generated release notes requires GitHub Enterprise 3.5 or later, but git.example.com runs 3.4.2",
+		caps.Require(FeatureGenerateReleaseNotes).Error())
+
+	caps.assumed = map[Feature]bool{FeatureGenerateReleaseNotes: true}
+	assert.Equal(t, true, caps.Supports(FeatureGenerateReleaseNotes))
+
+	unknown := &Capabilities{Host: "git.example.com"}
+	assert.Equal(t, true, unknown.Supports(FeatureProjectsV2))
+}
+
+func TestClient_Capabilities(t *testing.T) {
+	repo := fixtures.SetupTestRepo()
+	defer repo.TearDown()
+	defer setupCapabilitiesDir(t)()
+
+	requests := 0
+	client, cleanup := setupTestClient(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/meta", r.URL.Path)
+		requests++
+		w.Write([]byte(`{"installed_version": "3.0.4"}`))
+	})
+	defer cleanup()
+
+	assert.Equal(t, false, client.Supports(FeatureAutoMerge))
+	assert.Equal(t, true, client.Supports(FeatureDraftPullRequests))
+	assert.Equal(t, 1, requests)
+
+	// another client for the same host reads the version from the cache
+	other := NewClientWithHost(client.Host)
+	assert.Equal(t, "3.0.4", other.Capabilities().Version)
+	assert.Equal(t, 1, requests)
+
+	// the cache expires
+	caps := readCapabilities(capabilitiesFile(client.Host.Host))
+	caps.FetchedAt = time.Now().Add(-25 * time.Hour)
+	writeCapabilities(capabilitiesFile(client.Host.Host), caps)
+	other = NewClientWithHost(client.Host)
+	assert.Equal(t, "3.0.4", other.Capabilities().Version)
+	assert.Equal(t, 2, requests)
+
+	assert.Equal(t, nil, git.SetGlobalConfig("hub.assumeFeatures", "auto-merge, drafts"))
+	other = NewClientWithHost(client.Host)
+	assert.Equal(t, true, other.Supports(FeatureAutoMerge))
+	assert.Equal(t, false, other.Supports(FeatureProjectsV2))
+}
+
+func TestClient_Capabilities_GitHubDotCom(t *testing.T) {
+	client := NewClientWithHost(&Host{Host: GitHubHost})
+	assert.Equal(t, "", client.Capabilities().Version)
+	assert.Equal(t, true, client.Supports(FeatureIssueForms))
+}
@@ -0,0 +1,65 @@
+package github_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/bmizerany/assert"
+	"github.com/github/hub/fixtures"
+	"github.com/github/hub/github"
+	"github.com/github/hub/github/githubtest"
+)
+
+func TestClient_FetchCIStatus(t *testing.T) {
+	client, server := githubtest.NewClient()
+	defer server.Close()
+	assert.Equal(t, nil, server.LoadFixtures(fixtures.Path("api", "ci_status.json")))
+
+	status, err := client.FetchCIStatus(&github.Project{Owner: "mislav", Name: "dotfiles"}, "abc")
+	assert.Equal(t, nil, err)
+	started := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	assert.Equal(t, []github.CIStatus{
+		{State: "failure", Context: "build", TargetUrl: "https://github.com/runs/3"},
+		{State: "neutral", Context: "deploy", TargetUrl: "https://github.com/runs/5"},
+		{State: "pending", Context: "Lint", TargetUrl: "https://github.com/runs/4", StartedAt: &started},
+		{State: "success", Context: "travis-ci", TargetUrl: "https://travis-ci.org/1"},
+	}, status.Statuses)
+
+	server.AssertRequests(t,
+		"GET /repos/mislav/dotfiles/commits/abc/status",
+		"GET /repos/mislav/dotfiles/commits/abc/check-runs",
+		"GET /repos/mislav/dotfiles/commits/abc/check-runs",
+	)
+	checkRuns := server.AssertRequested(t, "GET", "/repos/mislav/dotfiles/commits/abc/check-runs")
+	assert.Equal(t, "100", checkRuns.Query.Get("per_page"))
+	assert.Equal(t, "2", checkRuns.Query.Get("page"))
+}
+
+func TestClient_FetchCIStatus_ssoError(t *testing.T) {
+	client, server := githubtest.NewClient()
+	defer server.Close()
+	server.Handle(githubtest.Fixture{
+		Path:  "/repos/acme/secret/commits/abc/status",
+		Error: githubtest.ErrorSSO,
+	})
+
+	_, err := client.FetchCIStatus(&github.Project{Owner: "acme", Name: "secret"}, "abc")
+	assert.Equal(t, "Error fetching statuses: Forbidden (HTTP 403)\nResource protected by organization SAML enforcement. You must grant your Personal Access token access to this organization.", err.Error())
+}
+
+func TestClient_RerunCheckRun(t *testing.T) {
+	client, server := githubtest.NewClient()
+	defer server.Close()
+	server.Handle(githubtest.Fixture{Method: "POST", Path: "/repos/mislav/dotfiles/actions/jobs/7/rerun", Status: 201})
+	server.Handle(githubtest.Fixture{Method: "POST", Path: "/repos/mislav/dotfiles/check-runs/8/rerequest", Status: 201})
+
+	project := &github.Project{Owner: "mislav", Name: "dotfiles"}
+	err := client.RerunCheckRun(project, github.CheckRun{Id: 7, App: &github.CheckRunApp{Slug: "github-actions"}})
+	assert.Equal(t, nil, err)
+	err = client.RerunCheckRun(project, github.CheckRun{Id: 8, App: &github.CheckRunApp{Slug: "circleci-checks"}})
+	assert.Equal(t, nil, err)
+	server.AssertRequests(t,
+		"POST /repos/mislav/dotfiles/actions/jobs/7/rerun",
+		"POST /repos/mislav/dotfiles/check-runs/8/rerequest",
+	)
+}
@@ -3,17 +3,22 @@ package github
 import (
 	"bytes"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
+	"io/ioutil"
 	"net/http"
 	"net/url"
 	"os"
 	"os/exec"
 	"path/filepath"
 	"sort"
+	"strconv"
 	"strings"
+	"sync"
 	"time"
 
+	"github.com/github/hub/utils"
 	"github.com/github/hub/version"
 )
 
@@ -24,16 +29,74 @@ const (
 
 var UserAgent = "Hub " + version.Version
 
+var (
+	clients      = map[string]*Client{}
+	clientsMutex sync.Mutex
+)
+
+// NewClient returns the client for host h. There's one per host so that
+// lookups of the access token and the connections to the API are shared by
+// all the requests a command makes.
 func NewClient(h string) *Client {
-	return NewClientWithHost(&Host{Host: h})
+	clientsMutex.Lock()
+	defer clientsMutex.Unlock()
+
+	key := strings.ToLower(h)
+	if client, ok := clients[key]; ok {
+		return client
+	}
+	client := NewClientWithHost(&Host{Host: h})
+	clients[key] = client
+	return client
 }
 
 func NewClientWithHost(host *Host) *Client {
-	return &Client{host}
+	return &Client{Host: host, conditional: persistentResponseCache()}
+}
+
+// NewAnonymousClient returns a client for host h that makes its requests
+// without an access token instead of prompting for credentials, which only
+// works for public API endpoints.
+func NewAnonymousClient(h string) *Client {
+	client := NewClientWithHost(&Host{Host: h})
+	client.anonymous = true
+	return client
 }
 
 type Client struct {
-	Host *Host
+	Host          *Host
+	anonymous     bool
+	conditional   *conditionalCache
+	rateLimitWait func(time.Duration) error
+	tokenMutex    sync.Mutex
+
+	capabilities      *Capabilities
+	capabilitiesMutex sync.Mutex
+
+	branchPulls      map[string]*PullRequest
+	branchPullsMutex sync.Mutex
+
+	requiredChecks      map[string][]string
+	requiredChecksMutex sync.Mutex
+
+	repositories      map[string]*Repository
+	repositoriesMutex sync.Mutex
+}
+
+// UseConditionalRequests makes the client remember the responses to GET
+// requests and revalidate them using their ETag when they are repeated.
+func (client *Client) UseConditionalRequests() {
+	if client.conditional == nil {
+		client.conditional = newConditionalCache()
+	}
+}
+
+// PauseOnRateLimit makes the client retry requests that were rejected for
+// exceeding an API rate limit once wait returns, which it's called with the
+// time until the rate limit allows for the request. An error from wait fails
+// the request instead.
+func (client *Client) PauseOnRateLimit(wait func(time.Duration) error) {
+	client.rateLimitWait = wait
 }
 
 func (client *Client) FetchPullRequests(project *Project, filterParams map[string]interface{}, limit int, filter func(*PullRequest) bool) (pulls []PullRequest, err error) {
@@ -82,6 +145,13 @@ func (client *Client) FetchPullRequests(project *Project, filterParams map[strin
 	return
 }
 
+// CountPullRequests returns the number of pull requests matching
+// filterParams without fetching them.
+func (client *Client) CountPullRequests(project *Project, filterParams map[string]interface{}) (int, error) {
+	path := fmt.Sprintf("repos/%s/%s/pulls", project.Owner, project.Name)
+	return client.countItems(path, filterParams, "counting pull requests")
+}
+
 func (client *Client) PullRequest(project *Project, id string) (pr *PullRequest, err error) {
 	api, err := client.simpleApi()
 	if err != nil {
@@ -113,6 +183,24 @@ func (client *Client) PullRequestPatch(project *Project, id string) (patch io.Re
 	return res.Body, nil
 }
 
+// PullRequestDiff fetches the diff between the base and the head of a pull
+// request, as 'git diff' would print it.
+func (client *Client) PullRequestDiff(project *Project, number int) (string, error) {
+	api, err := client.simpleApi()
+	if err != nil {
+		return "", err
+	}
+
+	res, err := api.GetFile(fmt.Sprintf("repos/%s/%s/pulls/%d", project.Owner, project.Name, number), diffMediaType)
+	if err = checkStatus(200, "getting pull request diff", res, err); err != nil {
+		return "", err
+	}
+
+	defer res.Body.Close()
+	body, err := ioutil.ReadAll(res.Body)
+	return string(body), err
+}
+
 func (client *Client) CreatePullRequest(project *Project, params map[string]interface{}) (pr *PullRequest, err error) {
 	api, err := client.simpleApi()
 	if err != nil {
@@ -123,7 +211,7 @@ func (client *Client) CreatePullRequest(project *Project, params map[string]inte
 	if err = checkStatus(201, "creating pull request", res, err); err != nil {
 		if res != nil && res.StatusCode == 404 {
 			projectUrl := strings.SplitN(project.WebURL("", "", ""), "://", 2)[1]
-			err = fmt.Errorf("%s\nAre you sure that %s exists?", err, projectUrl)
+			err = utils.WithExitStatus(fmt.Errorf("%s\nAre you sure that %s exists?", err, projectUrl), utils.ExitNotFound)
 		}
 		return
 	}
@@ -145,7 +233,27 @@ func (client *Client) RequestReview(project *Project, prNumber int, params map[s
 		return
 	}
 
-	res.Body.Close()
+	res.discard()
+	return
+}
+
+func (client *Client) CommitExists(project *Project, ref string) (exists bool, err error) {
+	api, err := client.simpleApi()
+	if err != nil {
+		return
+	}
+
+	res, err := api.Get(fmt.Sprintf("repos/%s/%s/commits/%s", project.Owner, project.Name, ref))
+	if err == nil && (res.StatusCode == 404 || res.StatusCode == 422) {
+		res.discard()
+		return
+	}
+	if err = checkStatus(200, "fetching commit", res, err); err != nil {
+		return
+	}
+
+	res.discard()
+	exists = true
 	return
 }
 
@@ -163,11 +271,250 @@ func (client *Client) CommitPatch(project *Project, sha string) (patch io.ReadCl
 	return res.Body, nil
 }
 
+type Commit struct {
+	Sha     string       `json:"sha"`
+	HtmlUrl string       `json:"html_url"`
+	Files   []CommitFile `json:"files"`
+}
+
+type CommitFile struct {
+	Filename         string `json:"filename"`
+	PreviousFilename string `json:"previous_filename,omitempty"`
+	Status           string `json:"status"`
+	Additions        int    `json:"additions"`
+	Deletions        int    `json:"deletions"`
+	Patch            string `json:"patch,omitempty"`
+}
+
+// CommitPullRequests returns the pull requests that a commit is part of. Hosts
+// that can't list them for a commit are searched for pull requests mentioning
+// the SHA instead.
+func (client *Client) CommitPullRequests(project *Project, sha string) (pulls []PullRequest, err error) {
+	api, err := client.simpleApi()
+	if err != nil {
+		return
+	}
+
+	res, err := api.GetFile(fmt.Sprintf("repos/%s/%s/commits/%s/pulls?per_page=100", project.Owner, project.Name, sha), commitPullsType)
+	if err == nil && (res.StatusCode == 404 || res.StatusCode == 415) {
+		res.discard()
+		return client.searchCommitPullRequests(project, sha)
+	}
+	if err = checkStatus(200, "fetching pull requests of commit", res, err); err != nil {
+		return
+	}
+
+	pulls = []PullRequest{}
+	err = res.Unmarshal(&pulls)
+	return
+}
+
+func (client *Client) searchCommitPullRequests(project *Project, sha string) (pulls []PullRequest, err error) {
+	issues, _, err := client.SearchIssues(project, "is:pr "+sha, 100)
+	if err != nil {
+		return
+	}
+
+	pulls = []PullRequest{}
+	for _, issue := range issues {
+		pr := PullRequest(issue)
+		// search results only know that a pull request was merged from its
+		// "pull_request" field
+		if issue.PullRequest != nil {
+			pr.MergedAt = issue.PullRequest.MergedAt
+		}
+		pulls = append(pulls, pr)
+	}
+	return
+}
+
+func (client *Client) FetchCommit(project *Project, sha string) (commit *Commit, err error) {
+	api, err := client.simpleApi()
+	if err != nil {
+		return
+	}
+
+	res, err := api.Get(fmt.Sprintf("repos/%s/%s/commits/%s", project.Owner, project.Name, sha))
+	if err = checkStatus(200, "fetching commit", res, err); err != nil {
+		return
+	}
+
+	commit = &Commit{}
+	err = res.Unmarshal(commit)
+	return
+}
+
+type Comparison struct {
+	Status       string       `json:"status"`
+	AheadBy      int          `json:"ahead_by"`
+	BehindBy     int          `json:"behind_by"`
+	TotalCommits int          `json:"total_commits"`
+	TotalFiles   int          `json:"total_files"`
+	Files        []CommitFile `json:"files"`
+}
+
+// FetchComparison compares two refs, either of which may be given as
+// "OWNER:REF" to refer to a fork. The API lists at most 300 changed files, and
+// only a single commit is requested since the commits are just counted.
+func (client *Client) FetchComparison(project *Project, base, head string) (comparison *Comparison, err error) {
+	api, err := client.simpleApi()
+	if err != nil {
+		return
+	}
+
+	escape := func(ref string) string {
+		return strings.Replace(url.PathEscape(ref), "%2F", "/", -1)
+	}
+	res, err := api.Get(fmt.Sprintf("repos/%s/%s/compare/%s...%s?per_page=1", project.Owner, project.Name, escape(base), escape(head)))
+	if err = checkStatus(200, "comparing refs", res, err); err != nil {
+		return
+	}
+
+	comparison = &Comparison{}
+	err = res.Unmarshal(comparison)
+	return
+}
+
+type CommitComment struct {
+	Id        int       `json:"id"`
+	Body      string    `json:"body"`
+	Path      string    `json:"path"`
+	Line      int       `json:"line"`
+	CommitId  string    `json:"commit_id"`
+	User      *User     `json:"user"`
+	HtmlUrl   string    `json:"html_url"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+func (client *Client) FetchCommitComments(project *Project, sha string) (comments []CommitComment, err error) {
+	api, err := client.simpleApi()
+	if err != nil {
+		return
+	}
+
+	path := fmt.Sprintf("repos/%s/%s/commits/%s/comments?per_page=100", project.Owner, project.Name, sha)
+	comments = []CommitComment{}
+	var res *simpleResponse
+
+	for path != "" {
+		res, err = api.Get(path)
+		if err = checkStatus(200, "fetching commit comments", res, err); err != nil {
+			return
+		}
+		path = res.Link("next")
+
+		commentsPage := []CommitComment{}
+		if err = res.Unmarshal(&commentsPage); err != nil {
+			return
+		}
+		comments = append(comments, commentsPage...)
+	}
+
+	return
+}
+
+func (client *Client) CreateCommitComment(project *Project, sha string, params map[string]interface{}) (comment *CommitComment, err error) {
+	api, err := client.simpleApi()
+	if err != nil {
+		return
+	}
+
+	res, err := api.PostJSON(fmt.Sprintf("repos/%s/%s/commits/%s/comments", project.Owner, project.Name, sha), params)
+	if err = checkStatus(201, "creating commit comment", res, err); err != nil {
+		return
+	}
+
+	comment = &CommitComment{}
+	err = res.Unmarshal(comment)
+	return
+}
+
+func (client *Client) FetchPullRequestFiles(project *Project, number int) (files []CommitFile, err error) {
+	api, err := client.simpleApi()
+	if err != nil {
+		return
+	}
+
+	path := fmt.Sprintf("repos/%s/%s/pulls/%d/files?per_page=100", project.Owner, project.Name, number)
+
+	files = []CommitFile{}
+	var res *simpleResponse
+
+	for path != "" {
+		res, err = api.Get(path)
+		if err = checkStatus(200, "fetching pull request files", res, err); err != nil {
+			return
+		}
+		path = res.Link("next")
+
+		filesPage := []CommitFile{}
+		if err = res.Unmarshal(&filesPage); err != nil {
+			return
+		}
+		files = append(files, filesPage...)
+	}
+
+	return
+}
+
+type ReviewComment struct {
+	Id        int       `json:"id"`
+	Body      string    `json:"body"`
+	Path      string    `json:"path"`
+	Line      int       `json:"line"`
+	Side      string    `json:"side"`
+	StartLine int       `json:"start_line"`
+	InReplyTo int       `json:"in_reply_to_id"`
+	User      *User     `json:"user"`
+	HtmlUrl   string    `json:"html_url"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+func (client *Client) CreateReviewComment(project *Project, number int, params map[string]interface{}) (comment *ReviewComment, err error) {
+	api, err := client.simpleApi()
+	if err != nil {
+		return
+	}
+
+	res, err := api.PostJSON(fmt.Sprintf("repos/%s/%s/pulls/%d/comments", project.Owner, project.Name, number), params)
+	if err = checkStatus(201, "creating review comment", res, err); err != nil {
+		return
+	}
+
+	comment = &ReviewComment{}
+	err = res.Unmarshal(comment)
+	return
+}
+
+func (client *Client) ReplyToReviewComment(project *Project, number, commentId int, body string) (comment *ReviewComment, err error) {
+	api, err := client.simpleApi()
+	if err != nil {
+		return
+	}
+
+	params := map[string]interface{}{
+		"body": body,
+	}
+	res, err := api.PostJSON(fmt.Sprintf("repos/%s/%s/pulls/%d/comments/%d/replies", project.Owner, project.Name, number, commentId), params)
+	if err = checkStatus(201, "replying to review comment", res, err); err != nil {
+		return
+	}
+
+	comment = &ReviewComment{}
+	err = res.Unmarshal(comment)
+	return
+}
+
 type Gist struct {
-	Files map[string]GistFile `json:"files"`
+	Id      string              `json:"id"`
+	HtmlUrl string              `json:"html_url"`
+	Owner   *User               `json:"owner"`
+	Files   map[string]GistFile `json:"files"`
 }
 type GistFile struct {
-	RawUrl string `json:"raw_url"`
+	RawUrl    string `json:"raw_url"`
+	Content   string `json:"content"`
+	Truncated bool   `json:"truncated"`
 }
 
 func (client *Client) GistPatch(id string) (patch io.ReadCloser, err error) {
@@ -199,7 +546,98 @@ func (client *Client) GistPatch(id string) (patch io.ReadCloser, err error) {
 	return res.Body, nil
 }
 
+func (client *Client) FetchGist(id string) (gist *Gist, err error) {
+	api, err := client.simpleApi()
+	if err != nil {
+		return
+	}
+
+	res, err := api.Get(fmt.Sprintf("gists/%s", id))
+	if err = checkStatus(200, "getting gist", res, err); err != nil {
+		return
+	}
+
+	gist = &Gist{}
+	err = res.Unmarshal(gist)
+	return
+}
+
+// GistFileContent returns the content of a gist file, downloading it if it
+// was too large to be included with the gist.
+func (client *Client) GistFileContent(file GistFile) (content string, err error) {
+	if !file.Truncated {
+		return file.Content, nil
+	}
+
+	api, err := client.simpleApi()
+	if err != nil {
+		return
+	}
+
+	res, err := api.GetFile(file.RawUrl, textMediaType)
+	if err = checkStatus(200, "downloading gist file", res, err); err != nil {
+		return
+	}
+	defer res.Body.Close()
+
+	body, err := ioutil.ReadAll(res.Body)
+	content = string(body)
+	return
+}
+
+// EditGist updates the files of a gist in a single request. A file is removed
+// when its content is nil.
+func (client *Client) EditGist(id string, files map[string]*string) (gist *Gist, err error) {
+	api, err := client.simpleApi()
+	if err != nil {
+		return
+	}
+
+	fileParams := map[string]interface{}{}
+	for name, content := range files {
+		if content == nil {
+			fileParams[name] = nil
+		} else {
+			fileParams[name] = map[string]interface{}{"content": *content}
+		}
+	}
+	params := map[string]interface{}{"files": fileParams}
+
+	res, err := api.PatchJSON(fmt.Sprintf("gists/%s", id), params)
+	if err = checkStatus(200, "updating gist", res, err); err != nil {
+		return
+	}
+
+	gist = &Gist{}
+	err = res.Unmarshal(gist)
+	return
+}
+
+func (client *Client) DeleteGist(id string) error {
+	api, err := client.simpleApi()
+	if err != nil {
+		return err
+	}
+
+	res, err := api.Delete(fmt.Sprintf("gists/%s", id))
+	if err = checkStatus(204, "deleting gist", res, err); err != nil {
+		return err
+	}
+
+	res.discard()
+	return nil
+}
+
+// Repository fetches the info of project. Lookups are remembered for as long
+// as the client lives.
 func (client *Client) Repository(project *Project) (repo *Repository, err error) {
+	key := strings.ToLower(fmt.Sprintf("%s/%s", project.Owner, project.Name))
+	client.repositoriesMutex.Lock()
+	defer client.repositoriesMutex.Unlock()
+	if repo, found := client.repositories[key]; found {
+		return repo, nil
+	}
+
 	api, err := client.simpleApi()
 	if err != nil {
 		return
@@ -211,10 +649,59 @@ func (client *Client) Repository(project *Project) (repo *Repository, err error)
 	}
 
 	repo = &Repository{}
-	err = res.Unmarshal(&repo)
+	if err = res.Unmarshal(&repo); err != nil {
+		return
+	}
+
+	if client.repositories == nil {
+		client.repositories = map[string]*Repository{}
+	}
+	client.repositories[key] = repo
 	return
 }
 
+// CachedRepository returns the info of project from a response that 'hub api
+// --cache' stored no longer than ttl seconds ago, or nil if there's none. It
+// never sends a request, nor does it prompt for credentials.
+func (client *Client) CachedRepository(project *Project, ttl int) *Repository {
+	if client.Host.AccessToken == "" {
+		return nil
+	}
+	api, err := client.simpleApi()
+	if err != nil {
+		return nil
+	}
+	api.CacheTTL = ttl
+
+	res := api.cachedGet(fmt.Sprintf("repos/%s/%s", project.Owner, project.Name))
+	if res == nil || res.StatusCode != 200 {
+		return nil
+	}
+	repo := &Repository{}
+	if err := res.Unmarshal(repo); err != nil {
+		return nil
+	}
+	return repo
+}
+
+// EnsureWritable fails if project is archived, since GitHub rejects any change
+// to archived repositories, or if it's disabled, unless force is set because
+// some changes to disabled repositories still succeed. Failing to look up the
+// repository isn't an error here; the change itself will report the problem.
+func (client *Client) EnsureWritable(project *Project, force bool) error {
+	repo, err := client.Repository(project)
+	if err != nil {
+		return nil
+	}
+	if repo.Archived {
+		return fmt.Errorf("Aborted: repository %s is archived (read-only)", project)
+	}
+	if repo.Disabled && !force {
+		return fmt.Errorf("Aborted: repository %s is disabled\n(use `--force` to try anyway)", project)
+	}
+	return nil
+}
+
 func (client *Client) CreateRepository(project *Project, description, homepage string, isPrivate bool) (repo *Repository, err error) {
 	repoURL := "user/repos"
 	if project.Owner != client.Host.User {
@@ -243,6 +730,29 @@ func (client *Client) CreateRepository(project *Project, description, homepage s
 	return
 }
 
+// DeleteBranch deletes a branch of project. A branch that's already gone, for
+// example because GitHub deleted it after merging, is not an error.
+func (client *Client) DeleteBranch(project *Project, branch string) error {
+	api, err := client.simpleApi()
+	if err != nil {
+		return err
+	}
+
+	// slashes separate the parts of the ref, so they are left as they are
+	ref := strings.Replace(url.PathEscape(branch), "%2F", "/", -1)
+	res, err := api.Delete(fmt.Sprintf("repos/%s/%s/git/refs/heads/%s", project.Owner, project.Name, ref))
+	if err == nil && res.StatusCode == 422 {
+		res.discard()
+		return nil
+	}
+	if err = checkStatus(204, "deleting branch", res, err); err != nil {
+		return err
+	}
+
+	res.discard()
+	return nil
+}
+
 func (client *Client) DeleteRepository(project *Project) error {
 	api, err := client.simpleApi()
 	if err != nil {
@@ -251,7 +761,60 @@ func (client *Client) DeleteRepository(project *Project) error {
 
 	repoURL := fmt.Sprintf("repos/%s/%s", project.Owner, project.Name)
 	res, err := api.Delete(repoURL)
-	return checkStatus(204, "deleting repository", res, err)
+	if err = checkStatus(204, "deleting repository", res, err); err != nil {
+		return err
+	}
+
+	res.discard()
+	return nil
+}
+
+func (client *Client) TransferRepository(project *Project, newOwner string, teamIDs []int) (repo *Repository, err error) {
+	api, err := client.simpleApi()
+	if err != nil {
+		return
+	}
+
+	params := map[string]interface{}{
+		"new_owner": newOwner,
+	}
+	if len(teamIDs) > 0 {
+		params["team_ids"] = teamIDs
+	}
+
+	res, err := api.PostJSON(fmt.Sprintf("repos/%s/%s/transfer", project.Owner, project.Name), params)
+	if err = checkStatus(202, "transferring repository", res, err); err != nil {
+		return
+	}
+
+	repo = &Repository{}
+	err = res.Unmarshal(repo)
+	return
+}
+
+func (client *Client) SetRepositoryArchived(project *Project, archived bool) (repo *Repository, err error) {
+	api, err := client.simpleApi()
+	if err != nil {
+		return
+	}
+
+	params := map[string]interface{}{
+		"archived": archived,
+	}
+
+	action := "archiving repository"
+	if !archived {
+		action = "unarchiving repository"
+	}
+
+	res, err := api.PatchJSON(fmt.Sprintf("repos/%s/%s", project.Owner, project.Name), params)
+	if err = checkStatus(200, action, res, err); err != nil {
+		return
+	}
+
+	repo = &Repository{}
+	err = res.Unmarshal(repo)
+	return
 }
 
 type Release struct {
@@ -272,10 +835,12 @@ type Release struct {
 }
 
 type ReleaseAsset struct {
-	Name        string `json:"name"`
-	Label       string `json:"label"`
-	DownloadUrl string `json:"browser_download_url"`
-	ApiUrl      string `json:"url"`
+	Name          string `json:"name"`
+	Label         string `json:"label"`
+	DownloadUrl   string `json:"browser_download_url"`
+	ApiUrl        string `json:"url"`
+	Size          int64  `json:"size"`
+	DownloadCount int    `json:"download_count"`
 }
 
 func (client *Client) FetchReleases(project *Project, limit int, filter func(*Release) bool) (releases []Release, err error) {
@@ -321,7 +886,7 @@ func (client *Client) FetchRelease(project *Project, tagName string) (*Release,
 
 	if err == nil {
 		if len(releases) < 1 {
-			return nil, fmt.Errorf("Unable to find release with tag name `%s'", tagName)
+			return nil, utils.WithExitStatus(fmt.Errorf("Unable to find release with tag name `%s'", tagName), utils.ExitNotFound)
 		} else {
 			return &releases[0], nil
 		}
@@ -373,10 +938,28 @@ func (client *Client) DeleteRelease(release *Release) (err error) {
 		return
 	}
 
+	res.discard()
 	return
 }
 
-func (client *Client) UploadReleaseAsset(release *Release, filename, label string) (asset *ReleaseAsset, err error) {
+// UploadReleaseAsset attaches filename to release, trying up to attempts times
+// when the upload fails because of the network or the server. Before another
+// try, the incomplete asset that the upload may have left is deleted. The
+// progress callback, if given, is told how many bytes were sent so far.
+func (client *Client) UploadReleaseAsset(release *Release, filename, label string, attempts int, progress func(sent, total int64)) (asset *ReleaseAsset, err error) {
+	for attempt := 1; ; attempt++ {
+		var retry bool
+		asset, retry, err = client.uploadReleaseAsset(release, filename, label, progress)
+		if err == nil || !retry || attempt >= attempts {
+			return
+		}
+		if cleanupErr := client.deleteIncompleteReleaseAsset(release, filepath.Base(filename)); cleanupErr != nil {
+			return
+		}
+	}
+}
+
+func (client *Client) uploadReleaseAsset(release *Release, filename, label string, progress func(sent, total int64)) (asset *ReleaseAsset, retry bool, err error) {
 	api, err := client.simpleApi()
 	if err != nil {
 		return
@@ -389,7 +972,11 @@ func (client *Client) UploadReleaseAsset(release *Release, filename, label strin
 		uploadUrl += "&label=" + url.QueryEscape(label)
 	}
 
-	res, err := api.PostFile(uploadUrl, filename)
+	res, err := api.PostFile(uploadUrl, filename, progress)
+	// there's no point in retrying when the file can't be read
+	if _, isPathErr := err.(*os.PathError); !isPathErr {
+		retry = err != nil || res.StatusCode >= 500
+	}
 	if err = checkStatus(201, "uploading release asset", res, err); err != nil {
 		return
 	}
@@ -399,6 +986,30 @@ func (client *Client) UploadReleaseAsset(release *Release, filename, label strin
 	return
 }
 
+// deleteIncompleteReleaseAsset removes the asset named name from release, if
+// a failed upload created one.
+func (client *Client) deleteIncompleteReleaseAsset(release *Release, name string) (err error) {
+	api, err := client.simpleApi()
+	if err != nil {
+		return
+	}
+
+	res, err := api.Get(release.ApiUrl + "/assets?per_page=100")
+	if err = checkStatus(200, "fetching release assets", res, err); err != nil {
+		return
+	}
+	assets := []ReleaseAsset{}
+	if err = res.Unmarshal(&assets); err != nil {
+		return
+	}
+	for _, asset := range assets {
+		if asset.Name == name {
+			return client.DeleteReleaseAsset(&asset)
+		}
+	}
+	return
+}
+
 func (client *Client) DeleteReleaseAsset(asset *ReleaseAsset) (err error) {
 	api, err := client.simpleApi()
 	if err != nil {
@@ -406,8 +1017,11 @@ func (client *Client) DeleteReleaseAsset(asset *ReleaseAsset) (err error) {
 	}
 
 	res, err := api.Delete(asset.ApiUrl)
-	err = checkStatus(204, "deleting release asset", res, err)
+	if err = checkStatus(204, "deleting release asset", res, err); err != nil {
+		return
+	}
 
+	res.discard()
 	return
 }
 
@@ -434,20 +1048,68 @@ type CIStatus struct {
 	State     string `json:"state"`
 	Context   string `json:"context"`
 	TargetUrl string `json:"target_url"`
+	// StartedAt and CompletedAt are only known for check runs.
+	StartedAt   *time.Time `json:"started_at,omitempty"`
+	CompletedAt *time.Time `json:"completed_at,omitempty"`
+}
+
+type CheckRunsResponse struct {
+	CheckRuns []CheckRun `json:"check_runs"`
+}
+
+type CheckRun struct {
+	Id          int64        `json:"id"`
+	Status      string       `json:"status"`
+	Conclusion  string       `json:"conclusion"`
+	Name        string       `json:"name"`
+	HtmlUrl     string       `json:"html_url"`
+	App         *CheckRunApp `json:"app"`
+	StartedAt   *time.Time   `json:"started_at"`
+	CompletedAt *time.Time   `json:"completed_at"`
+}
+
+type CheckRunApp struct {
+	Slug string `json:"slug"`
+}
+
+// RerunPath is the API path to request a check run to run again. A check run
+// of GitHub Actions is re-run as the workflow job that it reports on, while
+// other check runs are re-requested from the app that created them.
+func (checkRun CheckRun) RerunPath(project *Project) string {
+	if checkRun.App != nil && checkRun.App.Slug == "github-actions" {
+		return fmt.Sprintf("repos/%s/%s/actions/jobs/%d/rerun", project.Owner, project.Name, checkRun.Id)
+	}
+	return fmt.Sprintf("repos/%s/%s/check-runs/%d/rerequest", project.Owner, project.Name, checkRun.Id)
 }
 
-type CheckRunsResponse struct {
-	CheckRuns []CheckRun `json:"check_runs"`
+// State maps the status and conclusion of a check run to the states of
+// commit statuses. Check runs that haven't completed are "pending"; stale ones
+// were given up on by GitHub and won't complete anymore.
+func (checkRun CheckRun) State() string {
+	if checkRun.Status != "completed" {
+		return "pending"
+	}
+	switch checkRun.Conclusion {
+	case "skipped", "stale":
+		return "neutral"
+	case "startup_failure":
+		return "failure"
+	default:
+		return checkRun.Conclusion
+	}
 }
 
-type CheckRun struct {
-	Status     string `json:"status"`
-	Conclusion string `json:"conclusion"`
-	Name       string `json:"name"`
-	HtmlUrl    string `json:"html_url"`
+// FetchCIStatus fetches both the legacy commit statuses and the check runs of
+// sha. A check run and a commit status with the same name are reported once,
+// as the check run.
+func (client *Client) FetchCIStatus(project *Project, sha string) (*CIStatusResponse, error) {
+	status, _, err := client.FetchCIStatusAndCheckRuns(project, sha)
+	return status, err
 }
 
-func (client *Client) FetchCIStatus(project *Project, sha string) (status *CIStatusResponse, err error) {
+// FetchCIStatusAndCheckRuns is like FetchCIStatus, but also returns the check
+// runs that the statuses were made from.
+func (client *Client) FetchCIStatusAndCheckRuns(project *Project, sha string) (status *CIStatusResponse, checkRuns []CheckRun, err error) {
 	api, err := client.simpleApi()
 	if err != nil {
 		return
@@ -463,47 +1125,88 @@ func (client *Client) FetchCIStatus(project *Project, sha string) (status *CISta
 		return
 	}
 
-	sortStatuses := func() {
-		sort.Slice(status.Statuses, func(a, b int) bool {
-			sA := status.Statuses[a]
-			sB := status.Statuses[b]
-			cmp := strings.Compare(strings.ToLower(sA.Context), strings.ToLower(sB.Context))
-			if cmp == 0 {
-				return strings.Compare(sA.TargetUrl, sB.TargetUrl) < 0
-			} else {
-				return cmp < 0
-			}
+	checkRuns, err = client.FetchCheckRuns(project, sha)
+	if err != nil {
+		return
+	}
+
+	checkNames := map[string]bool{}
+	for _, checkRun := range checkRuns {
+		checkNames[checkRun.Name] = true
+	}
+	statuses := []CIStatus{}
+	for _, s := range status.Statuses {
+		if !checkNames[s.Context] {
+			statuses = append(statuses, s)
+		}
+	}
+	for _, checkRun := range checkRuns {
+		statuses = append(statuses, CIStatus{
+			State:       checkRun.State(),
+			Context:     checkRun.Name,
+			TargetUrl:   checkRun.HtmlUrl,
+			StartedAt:   checkRun.StartedAt,
+			CompletedAt: checkRun.CompletedAt,
 		})
 	}
-	sortStatuses()
 
-	res, err = api.GetFile(fmt.Sprintf("repos/%s/%s/commits/%s/check-runs", project.Owner, project.Name, sha), checksType)
-	if err == nil && (res.StatusCode == 403 || res.StatusCode == 404 || res.StatusCode == 422) {
-		return
+	sort.Slice(statuses, func(a, b int) bool {
+		sA := statuses[a]
+		sB := statuses[b]
+		cmp := strings.Compare(strings.ToLower(sA.Context), strings.ToLower(sB.Context))
+		if cmp == 0 {
+			return strings.Compare(sA.TargetUrl, sB.TargetUrl) < 0
+		} else {
+			return cmp < 0
+		}
+	})
+	status.Statuses = statuses
+
+	return
+}
+
+func (client *Client) RerunCheckRun(project *Project, checkRun CheckRun) error {
+	api, err := client.simpleApi()
+	if err != nil {
+		return err
 	}
-	if err = checkStatus(200, "fetching checks", res, err); err != nil {
-		return
+
+	res, err := api.PostJSON(checkRun.RerunPath(project), map[string]interface{}{})
+	if err = checkStatus(201, "re-running check", res, err); err != nil {
+		return err
 	}
+	res.discard()
+	return nil
+}
 
-	checks := &CheckRunsResponse{}
-	if err = res.Unmarshal(checks); err != nil {
+// FetchCheckRuns fetches all check runs of sha. Hosts that don't support
+// the Checks API, such as older GitHub Enterprise versions, have none.
+func (client *Client) FetchCheckRuns(project *Project, sha string) (checkRuns []CheckRun, err error) {
+	api, err := client.simpleApi()
+	if err != nil {
 		return
 	}
 
-	for _, checkRun := range checks.CheckRuns {
-		state := "pending"
-		if checkRun.Status == "completed" {
-			state = checkRun.Conclusion
+	path := fmt.Sprintf("repos/%s/%s/commits/%s/check-runs?per_page=100", project.Owner, project.Name, sha)
+	checkRuns = []CheckRun{}
+	var res *simpleResponse
+	for path != "" {
+		res, err = api.GetFile(path, checksType)
+		if err == nil && len(checkRuns) == 0 && (res.StatusCode == 403 || res.StatusCode == 404 || res.StatusCode == 422) {
+			res.discard()
+			return
 		}
-		checkStatus := CIStatus{
-			State:     state,
-			Context:   checkRun.Name,
-			TargetUrl: checkRun.HtmlUrl,
+		if err = checkStatus(200, "fetching checks", res, err); err != nil {
+			return
 		}
-		status.Statuses = append(status.Statuses, checkStatus)
-	}
+		path = res.Link("next")
 
-	sortStatuses()
+		checks := &CheckRunsResponse{}
+		if err = res.Unmarshal(checks); err != nil {
+			return
+		}
+		checkRuns = append(checkRuns, checks.CheckRuns...)
+	}
 
 	return
 }
@@ -511,13 +1214,17 @@ func (client *Client) FetchCIStatus(project *Project, sha string) (status *CISta
 type Repository struct {
 	Name          string                 `json:"name"`
 	FullName      string                 `json:"full_name"`
+	Description   string                 `json:"description"`
 	Parent        *Repository            `json:"parent"`
 	Owner         *User                  `json:"owner"`
 	Private       bool                   `json:"private"`
+	Archived      bool                   `json:"archived"`
+	Disabled      bool                   `json:"disabled"`
 	HasWiki       bool                   `json:"has_wiki"`
 	Permissions   *RepositoryPermissions `json:"permissions"`
 	HtmlUrl       string                 `json:"html_url"`
 	DefaultBranch string                 `json:"default_branch"`
+	ForksCount    int                    `json:"forks_count"`
 }
 
 type RepositoryPermissions struct {
@@ -543,6 +1250,84 @@ func (client *Client) ForkRepository(project *Project, params map[string]interfa
 	return
 }
 
+// repositoryPollInterval is how long to wait before first asking again
+// whether a repository exists in WaitForRepository. The wait doubles with each
+// attempt, up to maxRepositoryPollInterval.
+var (
+	repositoryPollInterval    = time.Second
+	maxRepositoryPollInterval = 8 * time.Second
+)
+
+// WaitForRepository asks for project until it exists, such as a fork that
+// GitHub is still creating in the background, or until timeout has passed.
+// The waiting function, if any, is called once before the first wait.
+func (client *Client) WaitForRepository(project *Project, timeout time.Duration, waiting func()) (repo *Repository, err error) {
+	api, err := client.simpleApi()
+	if err != nil {
+		return
+	}
+
+	deadline := time.Now().Add(timeout)
+	interval := repositoryPollInterval
+	for attempt := 0; ; attempt++ {
+		res, err := api.Get(fmt.Sprintf("repos/%s/%s", project.Owner, project.Name))
+		if err != nil || res.StatusCode != 404 {
+			if err = checkStatus(200, "getting repository info", res, err); err != nil {
+				return nil, err
+			}
+			repo = &Repository{}
+			err = res.Unmarshal(repo)
+			return repo, err
+		}
+		res.discard()
+
+		if !time.Now().Add(interval).Before(deadline) {
+			return nil, fmt.Errorf("Error: %s still doesn't exist after %s", project, timeout)
+		}
+		if attempt == 0 && waiting != nil {
+			waiting()
+		}
+		time.Sleep(interval)
+		if interval *= 2; interval > maxRepositoryPollInterval {
+			interval = maxRepositoryPollInterval
+		}
+	}
+}
+
+// MergeUpstreamResult describes how a branch of a fork was brought up to date
+// with its upstream repository. MergeType is "fast-forward", "merge", "none"
+// when the branch was already up to date, or "conflict" when the branch
+// couldn't be synced without resolving merge conflicts.
+type MergeUpstreamResult struct {
+	Message    string `json:"message"`
+	MergeType  string `json:"merge_type"`
+	BaseBranch string `json:"base_branch"`
+}
+
+func (client *Client) MergeUpstream(project *Project, branch string) (result *MergeUpstreamResult, err error) {
+	api, err := client.simpleApi()
+	if err != nil {
+		return
+	}
+
+	params := map[string]interface{}{"branch": branch}
+	res, err := api.PostJSON(fmt.Sprintf("repos/%s/%s/merge-upstream", project.Owner, project.Name), params)
+	if err == nil && res.StatusCode == 409 {
+		// a 409 means that the branch couldn't be merged without conflicts
+		res.discard()
+		result = &MergeUpstreamResult{MergeType: "conflict"}
+		return
+	}
+	if err = checkStatus(200, "syncing fork", res, err); err != nil {
+		return
+	}
+
+	result = &MergeUpstreamResult{}
+	err = res.Unmarshal(result)
+
+	return
+}
+
 type Comment struct {
 	Id        int       `json:"id"`
 	Body      string    `json:"body"`
@@ -551,6 +1336,8 @@ type Comment struct {
 }
 
 type Issue struct {
+	Id     int64  `json:"id"`
+	NodeId string `json:"node_id"`
 	Number int    `json:"number"`
 	State  string `json:"state"`
 	Title  string `json:"title"`
@@ -562,8 +1349,12 @@ type Issue struct {
 	Base        *PullRequestSpec `json:"base"`
 
 	MergeCommitSha      string `json:"merge_commit_sha"`
+	MergeableState      string `json:"mergeable_state"`
 	MaintainerCanModify bool   `json:"maintainer_can_modify"`
 	Draft               bool   `json:"draft"`
+	Additions           int    `json:"additions"`
+	Deletions           int    `json:"deletions"`
+	ChangedFiles        int    `json:"changed_files"`
 
 	Comments  int          `json:"comments"`
 	Labels    []IssueLabel `json:"labels"`
@@ -576,8 +1367,9 @@ type Issue struct {
 	RequestedReviewers []User `json:"requested_reviewers"`
 	RequestedTeams     []Team `json:"requested_teams"`
 
-	ApiUrl  string `json:"url"`
-	HtmlUrl string `json:"html_url"`
+	ApiUrl        string `json:"url"`
+	HtmlUrl       string `json:"html_url"`
+	RepositoryUrl string `json:"repository_url"`
 
 	ClosedBy *User `json:"closed_by"`
 }
@@ -680,6 +1472,130 @@ func (client *Client) FetchIssues(project *Project, filterParams map[string]inte
 	return
 }
 
+// CountIssues returns the number of issues and pull requests matching
+// filterParams without fetching them.
+func (client *Client) CountIssues(project *Project, filterParams map[string]interface{}) (int, error) {
+	path := fmt.Sprintf("repos/%s/%s/issues", project.Owner, project.Name)
+	return client.countItems(path, filterParams, "counting issues")
+}
+
+// CountSearchIssues returns the total number of issues and pull requests
+// matching a search query.
+func (client *Client) CountSearchIssues(query string) (count int, err error) {
+	api, err := client.simpleApi()
+	if err != nil {
+		return
+	}
+
+	params := url.Values{}
+	params.Set("q", query)
+	params.Set("per_page", "1")
+	res, err := api.Get("search/issues?" + params.Encode())
+	if err = checkStatus(200, "searching issues", res, err); err != nil {
+		return
+	}
+
+	result := struct {
+		TotalCount int `json:"total_count"`
+	}{}
+	err = res.Unmarshal(&result)
+	count = result.TotalCount
+	return
+}
+
+// SearchIssues returns up to limit issues and pull requests matching a search
+// query, along with the total number of matches. A non-nil project scopes the
+// search to that repository. Results are most recently updated first unless
+// the query has a "sort:" qualifier of its own.
+func (client *Client) SearchIssues(project *Project, query string, limit int) (issues []Issue, total int, err error) {
+	api, err := client.simpleApi()
+	if err != nil {
+		return
+	}
+
+	if project != nil {
+		query = fmt.Sprintf("repo:%s/%s %s", project.Owner, project.Name, query)
+	}
+	params := url.Values{}
+	params.Set("q", query)
+	if !hasSortQualifier(query) {
+		params.Set("sort", "updated")
+		params.Set("order", "desc")
+	}
+	if limit > 0 && limit < 100 {
+		params.Set("per_page", strconv.Itoa(limit))
+	} else {
+		params.Set("per_page", "100")
+	}
+	path := "search/issues?" + params.Encode()
+
+	issues = []Issue{}
+	var res *simpleResponse
+	for path != "" {
+		res, err = api.Get(path)
+		if err = checkStatus(200, "searching issues", res, err); err != nil {
+			return
+		}
+		path = res.Link("next")
+
+		result := struct {
+			TotalCount int     `json:"total_count"`
+			Items      []Issue `json:"items"`
+		}{}
+		if err = res.Unmarshal(&result); err != nil {
+			return
+		}
+		total = result.TotalCount
+		for _, issue := range result.Items {
+			issues = append(issues, issue)
+			if limit > 0 && len(issues) == limit {
+				path = ""
+				break
+			}
+		}
+	}
+	return
+}
+
+// hasSortQualifier tells whether a search query picks its own order.
+func hasSortQualifier(query string) bool {
+	for _, term := range strings.Fields(query) {
+		if strings.HasPrefix(term, "sort:") {
+			return true
+		}
+	}
+	return false
+}
+
+// countItems requests a listing with a single item per page, so that the
+// number of the last page is the number of items.
+func (client *Client) countItems(path string, filterParams map[string]interface{}, action string) (count int, err error) {
+	api, err := client.simpleApi()
+	if err != nil {
+		return
+	}
+
+	params := map[string]interface{}{"per_page": 1}
+	for key, value := range filterParams {
+		params[key] = value
+	}
+	res, err := api.Get(addQuery(path, params))
+	if err = checkStatus(200, action, res, err); err != nil {
+		return
+	}
+
+	if lastPage, parseErr := url.Parse(res.Link("last")); parseErr == nil {
+		if page, atoiErr := strconv.Atoi(lastPage.Query().Get("page")); atoiErr == nil {
+			return page, nil
+		}
+	}
+
+	items := []interface{}{}
+	err = res.Unmarshal(&items)
+	count = len(items)
+	return
+}
+
 func (client *Client) FetchIssue(project *Project, number string) (issue *Issue, err error) {
 	api, err := client.simpleApi()
 	if err != nil {
@@ -697,12 +1613,23 @@ func (client *Client) FetchIssue(project *Project, number string) (issue *Issue,
 }
 
 func (client *Client) FetchComments(project *Project, number string) (comments []Comment, err error) {
+	return client.fetchComments(fmt.Sprintf("repos/%s/%s/issues/%s/comments", project.Owner, project.Name, number))
+}
+
+// FetchCommentsSince returns the comments on an issue or pull request that were
+// made or edited after since.
+func (client *Client) FetchCommentsSince(project *Project, number string, since time.Time) (comments []Comment, err error) {
+	path := fmt.Sprintf("repos/%s/%s/issues/%s/comments?since=%s", project.Owner, project.Name, number, since.UTC().Format(time.RFC3339))
+	return client.fetchComments(path)
+}
+
+func (client *Client) fetchComments(path string) (comments []Comment, err error) {
 	api, err := client.simpleApi()
 	if err != nil {
 		return
 	}
 
-	res, err := api.Get(fmt.Sprintf("repos/%s/%s/issues/%s/comments", project.Owner, project.Name, number))
+	res, err := api.Get(path)
 	if err = checkStatus(200, "fetching comments for issue", res, err); err != nil {
 		return nil, err
 	}
@@ -739,7 +1666,7 @@ func (client *Client) UpdateIssue(project *Project, issueNumber int, params map[
 		return
 	}
 
-	res.Body.Close()
+	res.discard()
 	return
 }
 
@@ -755,6 +1682,24 @@ func (s sortedLabels) Less(i, j int) bool {
 	return strings.Compare(strings.ToLower(s[i].Name), strings.ToLower(s[j].Name)) < 0
 }
 
+// FetchAssignees returns the first page of users that issues in project can be
+// assigned to.
+func (client *Client) FetchAssignees(project *Project) (users []User, err error) {
+	api, err := client.simpleApi()
+	if err != nil {
+		return
+	}
+
+	res, err := api.Get(fmt.Sprintf("repos/%s/%s/assignees?per_page=100", project.Owner, project.Name))
+	if err = checkStatus(200, "fetching assignees", res, err); err != nil {
+		return
+	}
+
+	users = []User{}
+	err = res.Unmarshal(&users)
+	return
+}
+
 func (client *Client) FetchLabels(project *Project) (labels []IssueLabel, err error) {
 	api, err := client.simpleApi()
 	if err != nil {
@@ -785,6 +1730,22 @@ func (client *Client) FetchLabels(project *Project) (labels []IssueLabel, err er
 	return
 }
 
+func (client *Client) FetchMilestone(project *Project, number string) (milestone *Milestone, err error) {
+	api, err := client.simpleApi()
+	if err != nil {
+		return
+	}
+
+	res, err := api.Get(fmt.Sprintf("repos/%s/%s/milestones/%s", project.Owner, project.Name, number))
+	if err = checkStatus(200, "fetching milestone", res, err); err != nil {
+		return
+	}
+
+	milestone = &Milestone{}
+	err = res.Unmarshal(milestone)
+	return
+}
+
 func (client *Client) FetchMilestones(project *Project) (milestones []Milestone, err error) {
 	api, err := client.simpleApi()
 	if err != nil {
@@ -813,6 +1774,87 @@ func (client *Client) FetchMilestones(project *Project) (milestones []Milestone,
 	return
 }
 
+type GitignoreTemplate struct {
+	Name   string `json:"name"`
+	Source string `json:"source"`
+}
+
+func (client *Client) FetchGitignoreTemplateNames() (names []string, err error) {
+	api, err := client.simpleApi()
+	if err != nil {
+		return
+	}
+
+	res, err := api.Get("gitignore/templates")
+	if err = checkStatus(200, "fetching gitignore templates", res, err); err != nil {
+		return
+	}
+
+	names = []string{}
+	err = res.Unmarshal(&names)
+	return
+}
+
+func (client *Client) FetchGitignoreTemplate(name string) (template *GitignoreTemplate, err error) {
+	api, err := client.simpleApi()
+	if err != nil {
+		return
+	}
+
+	res, err := api.Get("gitignore/templates/" + url.PathEscape(name))
+	if err = checkStatus(200, "fetching gitignore template", res, err); err != nil {
+		return
+	}
+
+	template = &GitignoreTemplate{}
+	err = res.Unmarshal(template)
+	return
+}
+
+type License struct {
+	Key    string `json:"key"`
+	Name   string `json:"name"`
+	SpdxId string `json:"spdx_id"`
+	Body   string `json:"body"`
+}
+
+func (client *Client) FetchLicenses() (licenses []License, err error) {
+	api, err := client.simpleApi()
+	if err != nil {
+		return
+	}
+
+	res, err := api.Get("licenses?per_page=100")
+	if err = checkStatus(200, "fetching licenses", res, err); err != nil {
+		return
+	}
+
+	licenses = []License{}
+	err = res.Unmarshal(&licenses)
+	return
+}
+
+// FetchLicense returns nil if there is no license with the given key.
+func (client *Client) FetchLicense(key string) (license *License, err error) {
+	api, err := client.simpleApi()
+	if err != nil {
+		return
+	}
+
+	res, err := api.Get("licenses/" + url.PathEscape(key))
+	if err == nil && res.StatusCode == 404 {
+		res.discard()
+		return
+	}
+	if err = checkStatus(200, "fetching license", res, err); err != nil {
+		return
+	}
+
+	license = &License{}
+	err = res.Unmarshal(license)
+	return
+}
+
 func (client *Client) GenericAPIRequest(method, path string, data interface{}, headers map[string]string, ttl int) (*simpleResponse, error) {
 	api, err := client.simpleApi()
 	if err != nil {
@@ -862,6 +1904,48 @@ func (client *Client) CurrentUser() (user *User, err error) {
 	return
 }
 
+// CurrentUserOrgs lists the organizations that the authenticated user is a
+// member of.
+func (client *Client) CurrentUserOrgs() (orgs []User, err error) {
+	api, err := client.simpleApi()
+	if err != nil {
+		return
+	}
+
+	res, err := api.Get("user/orgs?per_page=100")
+	if err = checkStatus(200, "fetching organizations", res, err); err != nil {
+		return
+	}
+
+	orgs = []User{}
+	err = res.Unmarshal(&orgs)
+	return
+}
+
+// SearchRepositories returns up to limit repositories matching a search query,
+// best matches first.
+func (client *Client) SearchRepositories(query string, limit int) (repos []Repository, err error) {
+	api, err := client.simpleApi()
+	if err != nil {
+		return
+	}
+
+	params := url.Values{}
+	params.Set("q", query)
+	params.Set("per_page", strconv.Itoa(limit))
+	res, err := api.Get("search/repositories?" + params.Encode())
+	if err = checkStatus(200, "searching repositories", res, err); err != nil {
+		return
+	}
+
+	result := struct {
+		Items []Repository `json:"items"`
+	}{}
+	err = res.Unmarshal(&result)
+	repos = result.Items
+	return
+}
+
 type AuthorizationEntry struct {
 	Token string `json:"token"`
 }
@@ -871,11 +1955,12 @@ func isToken(api *simpleClient, password string) bool {
 		req.Header.Set("Authorization", "token "+password)
 	}
 
-	res, _ := api.Get("user")
-	if res != nil && res.StatusCode == 200 {
-		return true
+	res, err := api.Get("user")
+	if err != nil {
+		return false
 	}
-	return false
+	res.discard()
+	return res.StatusCode == 200
 }
 
 func (client *Client) FindOrCreateToken(user, password, twoFactorCode string) (token string, err error) {
@@ -919,6 +2004,7 @@ func (client *Client) FindOrCreateToken(user, password, twoFactorCode string) (t
 			token = auth.Token
 			break
 		} else if res.StatusCode == 422 && count < maxTries {
+			res.discard()
 			count++
 		} else {
 			errInfo, e := res.ErrorInfo()
@@ -935,7 +2021,10 @@ func (client *Client) FindOrCreateToken(user, password, twoFactorCode string) (t
 }
 
 func (client *Client) ensureAccessToken() (err error) {
-	if client.Host.AccessToken == "" {
+	client.tokenMutex.Lock()
+	defer client.tokenMutex.Unlock()
+
+	if client.Host.AccessToken == "" && !client.anonymous {
 		host, err := CurrentConfig().PromptForHost(client.Host.Host)
 		if err == nil {
 			client.Host = host
@@ -952,29 +2041,54 @@ func (client *Client) simpleApi() (c *simpleClient, err error) {
 
 	c = client.apiClient()
 	c.PrepareRequest = func(req *http.Request) {
-		clientDomain := normalizeHost(client.Host.Host)
-		if strings.HasPrefix(clientDomain, "api.github.") {
-			clientDomain = strings.TrimPrefix(clientDomain, "api.")
-		}
-		requestHost := strings.ToLower(req.URL.Host)
-		if requestHost == clientDomain || strings.HasSuffix(requestHost, "."+clientDomain) {
+		if client.Host.AccessToken != "" && client.isAuthorizedHost(req.URL.Host) {
 			req.Header.Set("Authorization", "token "+client.Host.AccessToken)
 		}
 	}
+	c.OnResponse = func(res *http.Response) {
+		checkTokenExpiration(client.Host.Host, res)
+	}
+	c.httpClient.CheckRedirect = func(req *http.Request, via []*http.Request) error {
+		if c.isRepositoryMove(req, via) {
+			return http.ErrUseLastResponse
+		}
+		if len(via) >= 10 {
+			return errors.New("stopped after 10 redirects")
+		}
+		// downloads get redirected to CDN hosts that must never see the token
+		if !client.isAuthorizedHost(req.URL.Host) {
+			req.Header.Del("Authorization")
+		}
+		return nil
+	}
 	return
 }
 
+// isAuthorizedHost reports whether requests to host may carry the access token,
+// which is the case for the GitHub host of the client and its subdomains.
+func (client *Client) isAuthorizedHost(host string) bool {
+	clientDomain := normalizeHost(client.Host.Host)
+	if strings.HasPrefix(clientDomain, "api.github.") {
+		clientDomain = strings.TrimPrefix(clientDomain, "api.")
+	}
+	requestHost := strings.ToLower(host)
+	return requestHost == clientDomain || strings.HasSuffix(requestHost, "."+clientDomain)
+}
+
 func (client *Client) apiClient() *simpleClient {
 	unixSocket := os.ExpandEnv(client.Host.UnixSocket)
-	httpClient := newHttpClient(os.Getenv("HUB_TEST_HOST"), os.Getenv("HUB_VERBOSE") != "", unixSocket)
 	apiRoot := client.absolute(normalizeHost(client.Host.Host))
 	if !strings.HasPrefix(apiRoot.Host, "api.github.") {
 		apiRoot.Path = "/api/v3/"
 	}
+	tr := sharedTransport(os.Getenv("HUB_TEST_HOST"), os.Getenv("HUB_VERBOSE") != "", unixSocket, apiRoot)
 
 	return &simpleClient{
-		httpClient: httpClient,
-		rootUrl:    apiRoot,
+		httpClient:    &http.Client{Transport: tr},
+		rootUrl:       apiRoot,
+		host:          client.Host.Host,
+		conditional:   client.conditional,
+		rateLimitWait: client.rateLimitWait,
 	}
 }
 
@@ -1004,11 +2118,17 @@ func checkStatus(expectedStatus int, action string, response *simpleResponse, er
 	if err != nil {
 		return fmt.Errorf("Error %s: %s", action, err.Error())
 	} else if response.StatusCode != expectedStatus {
+		if response.StatusCode == http.StatusUnauthorized {
+			if expiredOn := tokenExpiredOn(response.Response); expiredOn != "" {
+				response.discard()
+				return utils.WithExitStatus(fmt.Errorf("Error %s: token expired on %s (HTTP 401)", action, expiredOn), utils.ExitAuth)
+			}
+		}
 		errInfo, err := response.ErrorInfo()
 		if err == nil {
 			return FormatError(action, errInfo)
 		} else {
-			return fmt.Errorf("Error %s: %s (HTTP %d)", action, err.Error(), response.StatusCode)
+			return utils.WithExitStatus(fmt.Errorf("Error %s: %s (HTTP %d)", action, err.Error(), response.StatusCode), httpExitStatus(response.Response))
 		}
 	} else {
 		return nil
@@ -1055,12 +2175,24 @@ func FormatError(action string, err error) (ee error) {
 			errStr = fmt.Sprintf("%s\n%s", errStr, errorMessage)
 		}
 
-		ee = fmt.Errorf(errStr)
+		ee = utils.WithExitStatus(errors.New(errStr), httpExitStatus(e.Response))
 	}
 
 	return
 }
 
+// httpExitStatus is the exit status for an API request that failed with res.
+func httpExitStatus(res *http.Response) int {
+	switch res.StatusCode {
+	case http.StatusNotFound, http.StatusGone:
+		return utils.ExitNotFound
+	case http.StatusUnauthorized, http.StatusForbidden, http.StatusTooManyRequests:
+		return utils.ExitAuth
+	default:
+		return utils.ExitError
+	}
+}
+
 func authTokenNote(num int) (string, error) {
 	n := os.Getenv("USER")
 
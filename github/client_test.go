@@ -7,6 +7,7 @@ import (
 	"testing"
 
 	"github.com/bmizerany/assert"
+	"github.com/github/hub/utils"
 )
 
 func TestClient_FormatError(t *testing.T) {
@@ -31,6 +32,28 @@ func TestClient_FormatError(t *testing.T) {
 	assert.Equal(t, "Error action: Unprocessable Entity (HTTP 422)\nerror message", fmt.Sprintf("%s", err))
 }
 
+func TestClient_FormatError_exitStatus(t *testing.T) {
+	statuses := map[int]int{
+		401: utils.ExitAuth,
+		403: utils.ExitAuth,
+		404: utils.ExitNotFound,
+		410: utils.ExitNotFound,
+		422: utils.ExitError,
+		429: utils.ExitAuth,
+		500: utils.ExitError,
+	}
+	for statusCode, expected := range statuses {
+		e := &errorInfo{
+			Response: &http.Response{
+				StatusCode: statusCode,
+				Status:     fmt.Sprintf("%d %s", statusCode, http.StatusText(statusCode)),
+			},
+		}
+		err := FormatError("action", e)
+		assert.Equal(t, expected, utils.ExitStatus(err))
+	}
+}
+
 func TestAuthTokenNote(t *testing.T) {
 	note, err := authTokenNote(1)
 	assert.Equal(t, nil, err)
@@ -45,3 +68,103 @@ func TestAuthTokenNote(t *testing.T) {
 	assert.T(t, reg.MatchString(note))
 
 }
+
+func TestClient_isAuthorizedHost(t *testing.T) {
+	client := NewClientWithHost(&Host{Host: "github.com"})
+	assert.T(t, client.isAuthorizedHost("api.github.com"))
+	assert.T(t, client.isAuthorizedHost("codeload.GitHub.com"))
+	assert.T(t, !client.isAuthorizedHost("objects.githubusercontent.com"))
+	assert.T(t, !client.isAuthorizedHost("github.com.evil.example"))
+
+	client = NewClientWithHost(&Host{Host: "git.my.org"})
+	assert.T(t, client.isAuthorizedHost("git.my.org"))
+	assert.T(t, !client.isAuthorizedHost("my.org"))
+}
+
+func TestCheckRun_State(t *testing.T) {
+	assert.Equal(t, "pending", CheckRun{Status: "queued"}.State())
+	assert.Equal(t, "pending", CheckRun{Status: "in_progress"}.State())
+	assert.Equal(t, "success", CheckRun{Status: "completed", Conclusion: "success"}.State())
+	assert.Equal(t, "timed_out", CheckRun{Status: "completed", Conclusion: "timed_out"}.State())
+	assert.Equal(t, "neutral", CheckRun{Status: "completed", Conclusion: "stale"}.State())
+	assert.Equal(t, "failure", CheckRun{Status: "completed", Conclusion: "startup_failure"}.State())
+}
+
+func TestClient_SearchIssues(t *testing.T) {
+	var serverURL string
+	pages := 0
+	client, cleanup := setupTestClient(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/search/issues", r.URL.Path)
+		query := r.URL.Query()
+		pages++
+		if query.Get("page") == "" {
+			assert.Equal(t, "repo:mislav/dotfiles is:issue label:bug sort:reactions", query.Get("q"))
+			assert.Equal(t, "", query.Get("sort"))
+			assert.Equal(t, "3", query.Get("per_page"))
+			w.Header().Set("Link", fmt.Sprintf(`<%s/api/v3/search/issues?page=2>; rel="next"`, serverURL))
+			fmt.Fprint(w, `{"total_count": 5, "items": [{"number": 1}, {"number": 2}]}`)
+		} else {
+			w.Header().Set("Link", fmt.Sprintf(`<%s/api/v3/search/issues?page=3>; rel="next"`, serverURL))
+			fmt.Fprint(w, `{"total_count": 5, "items": [{"number": 3}, {"number": 4}]}`)
+		}
+	})
+	defer cleanup()
+	serverURL = "http://" + client.Host.Host
+
+	issues, total, err := client.SearchIssues(&Project{Owner: "mislav", Name: "dotfiles"}, "is:issue label:bug sort:reactions", 3)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, 5, total)
+	assert.Equal(t, 3, len(issues))
+	assert.Equal(t, 3, issues[2].Number)
+	assert.Equal(t, 2, pages)
+}
+
+func TestClient_EnsureWritable(t *testing.T) {
+	requests := map[string]int{}
+	client, cleanup := setupTestClient(func(w http.ResponseWriter, r *http.Request) {
+		requests[r.URL.Path]++
+		switch r.URL.Path {
+		case "/repos/acme/old":
+			fmt.Fprint(w, `{"name": "old", "archived": true}`)
+		case "/repos/acme/banned":
+			fmt.Fprint(w, `{"name": "banned", "disabled": true}`)
+		case "/repos/acme/tools":
+			fmt.Fprint(w, `{"name": "tools"}`)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			fmt.Fprint(w, `{"message": "Not Found"}`)
+		}
+	})
+	defer cleanup()
+
+	project := func(name string) *Project {
+		return &Project{Owner: "acme", Name: name}
+	}
+
+	err := client.EnsureWritable(project("old"), true)
+	assert.Equal(t, "Aborted: repository acme/old is archived (read-only)", err.Error())
+
+	err = client.EnsureWritable(project("banned"), false)
+	assert.Equal(t, "Aborted: repository acme/banned is disabled\n(use `--force` to try anyway)", err.Error())
+	assert.Equal(t, nil, client.EnsureWritable(project("banned"), true))
+
+	assert.Equal(t, nil, client.EnsureWritable(project("tools"), false))
+	repo, err := client.Repository(project("Tools"))
+	assert.Equal(t, nil, err)
+	assert.Equal(t, "tools", repo.Name)
+	assert.Equal(t, 1, requests["/repos/acme/tools"])
+
+	assert.Equal(t, nil, client.EnsureWritable(project("missing"), false))
+}
+
+func TestClient_DeleteBranch(t *testing.T) {
+	client, cleanup := setupTestClient(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "DELETE", r.Method)
+		assert.Equal(t, "/repos/mislav/dotfiles/git/refs/heads/fix/50%25-off%23now", r.URL.EscapedPath())
+		w.WriteHeader(http.StatusNoContent)
+	})
+	defer cleanup()
+
+	err := client.DeleteBranch(&Project{Owner: "mislav", Name: "dotfiles"}, "fix/50%-off#now")
+	assert.Equal(t, nil, err)
+}
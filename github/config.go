@@ -19,6 +19,7 @@ import (
 )
 
 type yamlHost struct {
+	Name       string `yaml:"name,omitempty"`
 	User       string `yaml:"user"`
 	OAuthToken string `yaml:"oauth_token"`
 	Protocol   string `yaml:"protocol"`
@@ -31,15 +32,33 @@ type Host struct {
 	AccessToken string `toml:"access_token"`
 	Protocol    string `toml:"protocol"`
 	UnixSocket  string `toml:"unix_socket,omitempty"`
+	Name        string `toml:"name,omitempty"`
 }
 
+// IdentityName returns the name under which this set of credentials can be
+// selected with `--as`. Entries without an explicit name are known by their
+// user login.
+func (h *Host) IdentityName() string {
+	if h.Name != "" {
+		return h.Name
+	}
+	return h.User
+}
+
+// SelectedIdentity is the name of the identity chosen for the current
+// invocation with the global `--as` flag. When blank, the first identity
+// listed for a host is used.
+var SelectedIdentity string
+
 type Config struct {
 	Hosts []*Host `toml:"hosts"`
 }
 
+// PromptForHost returns the credentials for host. The access token comes from
+// the first source that has one; see lookupToken. Only tokens obtained by
+// prompting for a username and password are saved to hub's hosts file.
 func (c *Config) PromptForHost(host string) (h *Host, err error) {
-	token := c.DetectToken()
-	tokenFromEnv := token != ""
+	token, source := c.lookupToken(host)
 
 	if host != GitHubHost {
 		if _, e := url.Parse("https://" + host); e != nil {
@@ -63,23 +82,26 @@ func (c *Config) PromptForHost(host string) (h *Host, err error) {
 			err := newConfigService().Save(configsFile(), c)
 			utils.Check(err)
 		}
-		if tokenFromEnv {
-			h.AccessToken = token
-		} else {
+		if source == credentialsFromConfig || source == "" {
 			return
 		}
+		h.AccessToken = token
 	} else {
 		h = &Host{
 			Host:        host,
 			AccessToken: token,
 			Protocol:    "https",
+			Name:        SelectedIdentity,
 		}
 		c.Hosts = append(c.Hosts, h)
 	}
 
 	client := NewClientWithHost(h)
 
-	if !tokenFromEnv {
+	// tokens from the environment, netrc or git credential helpers are used
+	// as they are, but never stored
+	prompted := source == ""
+	if prompted {
 		utils.Check(CheckWriteable(configsFile()))
 		err = c.authorizeClient(client, host)
 		if err != nil {
@@ -92,8 +114,11 @@ func (c *Config) PromptForHost(host string) (h *Host, err error) {
 		return
 	}
 	h.User = currentUser.Login
+	if h.Name == h.User {
+		h.Name = ""
+	}
 
-	if !tokenFromEnv {
+	if prompted {
 		err = newConfigService().Save(configsFile(), c)
 	}
 
@@ -178,6 +203,11 @@ func (c *Config) scanLine() string {
 	return line
 }
 
+// ReadHiddenInput reads a line from the terminal on stdin without echoing it.
+func ReadHiddenInput() (string, error) {
+	return getPassword()
+}
+
 func getPassword() (string, error) {
 	stdin := int(syscall.Stdin)
 	initialTermState, err := terminal.GetState(stdin)
@@ -194,9 +224,10 @@ func getPassword() (string, error) {
 		case syscall.Signal:
 			if int(sig) == 2 {
 				fmt.Println("^C")
+				os.Exit(utils.ExitInterrupted)
 			}
 		}
-		os.Exit(1)
+		os.Exit(utils.ExitError)
 	}()
 
 	passBytes, err := terminal.ReadPassword(stdin)
@@ -211,7 +242,7 @@ func getPassword() (string, error) {
 
 func (c *Config) Find(host string) *Host {
 	for _, h := range c.Hosts {
-		if h.Host == host {
+		if h.Host == host && (SelectedIdentity == "" || h.IdentityName() == SelectedIdentity) {
 			return h
 		}
 	}
@@ -219,16 +250,62 @@ func (c *Config) Find(host string) *Host {
 	return nil
 }
 
-func (c *Config) selectHost() *Host {
-	options := len(c.Hosts)
+// Identities lists all sets of credentials stored for host. The first one is
+// the default identity for that host.
+func (c *Config) Identities(host string) (identities []*Host) {
+	for _, h := range c.Hosts {
+		if h.Host == host {
+			identities = append(identities, h)
+		}
+	}
+	return
+}
+
+// SwitchIdentity makes the identity with the given name the default one for
+// host and persists the change to the config file.
+func (c *Config) SwitchIdentity(host, name string) error {
+	first := -1
+	for i, h := range c.Hosts {
+		if h.Host != host {
+			continue
+		}
+		if first < 0 {
+			first = i
+		}
+		if h.IdentityName() == name {
+			if i != first {
+				copy(c.Hosts[first+1:i+1], c.Hosts[first:i])
+				c.Hosts[first] = h
+			}
+			return newConfigService().Save(configsFile(), c)
+		}
+	}
+
+	return fmt.Errorf("no identity named '%s' found for %s", name, host)
+}
+
+func (c *Config) hostNames() (names []string) {
+	seen := map[string]bool{}
+	for _, h := range c.Hosts {
+		if !seen[h.Host] {
+			seen[h.Host] = true
+			names = append(names, h.Host)
+		}
+	}
+	return
+}
+
+func (c *Config) selectHost() string {
+	names := c.hostNames()
+	options := len(names)
 
 	if options == 1 {
-		return c.Hosts[0]
+		return names[0]
 	}
 
 	prompt := "Select host:\n"
-	for idx, host := range c.Hosts {
-		prompt += fmt.Sprintf(" %d. %s\n", idx+1, host.Host)
+	for idx, name := range names {
+		prompt += fmt.Sprintf(" %d. %s\n", idx+1, name)
 	}
 	prompt += fmt.Sprint("> ")
 
@@ -239,7 +316,7 @@ func (c *Config) selectHost() *Host {
 		utils.Check(fmt.Errorf("Error: must enter a number [1-%d]", options))
 	}
 
-	return c.Hosts[i-1]
+	return names[i-1]
 }
 
 var defaultConfigsFile string
@@ -321,9 +398,8 @@ func (c *Config) DefaultHost() (host *Host, err error) {
 	if GitHubHostEnv != "" {
 		host, err = c.PromptForHost(GitHubHostEnv)
 	} else if len(c.Hosts) > 0 {
-		host = c.selectHost()
 		// HACK: forces host to inherit GITHUB_TOKEN if applicable
-		host, err = c.PromptForHost(host.Host)
+		host, err = c.PromptForHost(c.selectHost())
 	} else {
 		host, err = c.PromptForHost(DefaultGitHubHost())
 	}
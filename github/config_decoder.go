@@ -38,23 +38,24 @@ func (y *yamlConfigDecoder) Decode(r io.Reader, c *Config) error {
 
 	for _, hostEntry := range yc {
 		v := hostEntry.Value.([]interface{})
-		if len(v) < 1 {
-			continue
-		}
-		host := &Host{Host: hostEntry.Key.(string)}
-		for _, prop := range v[0].(yaml.MapSlice) {
-			switch prop.Key.(string) {
-			case "user":
-				host.User = prop.Value.(string)
-			case "oauth_token":
-				host.AccessToken = prop.Value.(string)
-			case "protocol":
-				host.Protocol = prop.Value.(string)
-			case "unix_socket":
-				host.UnixSocket = prop.Value.(string)
+		for _, identity := range v {
+			host := &Host{Host: hostEntry.Key.(string)}
+			for _, prop := range identity.(yaml.MapSlice) {
+				switch prop.Key.(string) {
+				case "name":
+					host.Name = prop.Value.(string)
+				case "user":
+					host.User = prop.Value.(string)
+				case "oauth_token":
+					host.AccessToken = prop.Value.(string)
+				case "protocol":
+					host.Protocol = prop.Value.(string)
+				case "unix_socket":
+					host.UnixSocket = prop.Value.(string)
+				}
 			}
+			c.Hosts = append(c.Hosts, host)
 		}
-		c.Hosts = append(c.Hosts, host)
 	}
 
 	return nil
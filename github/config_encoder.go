@@ -24,18 +24,24 @@ type yamlConfigEncoder struct {
 
 func (y *yamlConfigEncoder) Encode(w io.Writer, c *Config) error {
 	yc := yaml.MapSlice{}
+	hostIndex := map[string]int{}
 	for _, h := range c.Hosts {
-		yc = append(yc, yaml.MapItem{
-			Key: h.Host,
-			Value: []yamlHost{
-				{
-					User:       h.User,
-					OAuthToken: h.AccessToken,
-					Protocol:   h.Protocol,
-					UnixSocket: h.UnixSocket,
-				},
-			},
-		})
+		identity := yamlHost{
+			Name:       h.Name,
+			User:       h.User,
+			OAuthToken: h.AccessToken,
+			Protocol:   h.Protocol,
+			UnixSocket: h.UnixSocket,
+		}
+		if i, ok := hostIndex[h.Host]; ok {
+			yc[i].Value = append(yc[i].Value.([]yamlHost), identity)
+		} else {
+			hostIndex[h.Host] = len(yc)
+			yc = append(yc, yaml.MapItem{
+				Key:   h.Host,
+				Value: []yamlHost{identity},
+			})
+		}
 	}
 
 	d, err := yaml.Marshal(yc)
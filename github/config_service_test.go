@@ -207,3 +207,38 @@ func TestConfigService_YamlSave_UnixSocket(t *testing.T) {
   unix_socket: /tmp/go.sock`
 	assert.Equal(t, content, strings.TrimSpace(string(b)))
 }
+
+func TestConfigService_YamlLoad_MultipleIdentities(t *testing.T) {
+	file, _ := ioutil.TempFile("", "test-gh-config-")
+	defer os.RemoveAll(file.Name())
+
+	content := `github.com:
+- user: mislav
+  oauth_token: "123"
+  protocol: https
+- name: bot
+  user: mislav-bot
+  oauth_token: "456"
+  protocol: https`
+	ioutil.WriteFile(file.Name(), []byte(content), os.ModePerm)
+
+	cc := &Config{}
+	cs := &configService{
+		Encoder: &yamlConfigEncoder{},
+		Decoder: &yamlConfigDecoder{},
+	}
+	err := cs.Load(file.Name(), cc)
+	assert.Equal(t, nil, err)
+
+	assert.Equal(t, 2, len(cc.Hosts))
+	assert.Equal(t, "mislav", cc.Hosts[0].IdentityName())
+	assert.Equal(t, "bot", cc.Hosts[1].IdentityName())
+	assert.Equal(t, "mislav-bot", cc.Hosts[1].User)
+	assert.Equal(t, "456", cc.Hosts[1].AccessToken)
+
+	err = cs.Save(file.Name(), cc)
+	assert.Equal(t, nil, err)
+
+	b, _ := ioutil.ReadFile(file.Name())
+	assert.Equal(t, content, strings.TrimSpace(string(b)))
+}
@@ -0,0 +1,32 @@
+package github
+
+import (
+	"testing"
+
+	"github.com/bmizerany/assert"
+)
+
+func TestConfig_Find_SelectedIdentity(t *testing.T) {
+	defer func(name string) { SelectedIdentity = name }(SelectedIdentity)
+
+	personal := &Host{Host: "github.com", User: "mislav", AccessToken: "123"}
+	bot := &Host{Host: "github.com", User: "mislav-bot", AccessToken: "456", Name: "bot"}
+	enterprise := &Host{Host: "git.my.org", User: "mislav", AccessToken: "789"}
+	c := &Config{Hosts: []*Host{enterprise, personal, bot}}
+
+	SelectedIdentity = ""
+	assert.Equal(t, personal, c.Find("github.com"))
+	assert.Equal(t, enterprise, c.Find("git.my.org"))
+
+	SelectedIdentity = "bot"
+	assert.Equal(t, bot, c.Find("github.com"))
+
+	SelectedIdentity = "mislav"
+	assert.Equal(t, personal, c.Find("github.com"))
+
+	SelectedIdentity = "mislav-bot"
+	assert.Equal(t, (*Host)(nil), c.Find("github.com"))
+
+	SelectedIdentity = "nobody"
+	assert.Equal(t, (*Host)(nil), c.Find("github.com"))
+}
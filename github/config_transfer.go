@@ -0,0 +1,356 @@
+package github
+
+import (
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/github/hub/git"
+	"gopkg.in/yaml.v2"
+)
+
+const configDocumentVersion = 1
+
+// configDocumentGitConfig selects the global git config variables that are
+// part of hub configuration. git reports section names in lower case.
+const configDocumentGitConfig = `^(hub|alias)\.`
+
+var configDocumentGitConfigRe = regexp.MustCompile(`(?i)` + configDocumentGitConfig)
+
+var yamlUnknownFieldRe = regexp.MustCompile(`field (\S+) not found in type \S+`)
+
+// A ConfigDocument holds all hub configuration of a machine in a form that
+// can be exported with `hub config export` and imported elsewhere.
+type ConfigDocument struct {
+	Hosts     []*Host
+	GitConfig []GitConfigVariable
+}
+
+// A GitConfigVariable is a global git config variable with all its values.
+type GitConfigVariable struct {
+	Name   string
+	Values []string
+}
+
+type documentIdentity struct {
+	Name       string `yaml:"name,omitempty"`
+	User       string `yaml:"user"`
+	OAuthToken string `yaml:"oauth_token,omitempty"`
+	Protocol   string `yaml:"protocol,omitempty"`
+	UnixSocket string `yaml:"unix_socket,omitempty"`
+}
+
+type yamlConfigDocument struct {
+	Version   int           `yaml:"version"`
+	Hosts     yaml.MapSlice `yaml:"hosts,omitempty"`
+	GitConfig yaml.MapSlice `yaml:"git_config,omitempty"`
+}
+
+// CurrentConfigDocument collects the identities stored in the hub
+// configuration file and the hub-related variables of the global git config.
+func CurrentConfigDocument() (*ConfigDocument, error) {
+	doc := &ConfigDocument{}
+	for _, h := range CurrentConfig().Hosts {
+		host := *h
+		doc.Hosts = append(doc.Hosts, &host)
+	}
+
+	entries, err := git.GlobalConfigEntries(configDocumentGitConfig)
+	if err != nil {
+		return nil, err
+	}
+	variableIndex := map[string]int{}
+	for _, entry := range entries {
+		if i, ok := variableIndex[entry.Name]; ok {
+			doc.GitConfig[i].Values = append(doc.GitConfig[i].Values, entry.Value)
+		} else {
+			variableIndex[entry.Name] = len(doc.GitConfig)
+			doc.GitConfig = append(doc.GitConfig, GitConfigVariable{entry.Name, []string{entry.Value}})
+		}
+	}
+
+	return doc, nil
+}
+
+// HasTokens reports whether any identity in the document includes an access
+// token.
+func (doc *ConfigDocument) HasTokens() bool {
+	for _, h := range doc.Hosts {
+		if h.AccessToken != "" {
+			return true
+		}
+	}
+	return false
+}
+
+// Encode writes the document as YAML. Access tokens are left out unless
+// includeTokens is set.
+func (doc *ConfigDocument) Encode(w io.Writer, includeTokens bool) error {
+	yd := yamlConfigDocument{Version: configDocumentVersion}
+
+	hostIndex := map[string]int{}
+	for _, h := range doc.Hosts {
+		identity := documentIdentity{
+			Name:       h.Name,
+			User:       h.User,
+			Protocol:   h.Protocol,
+			UnixSocket: h.UnixSocket,
+		}
+		if includeTokens {
+			identity.OAuthToken = h.AccessToken
+		}
+		if i, ok := hostIndex[h.Host]; ok {
+			yd.Hosts[i].Value = append(yd.Hosts[i].Value.([]documentIdentity), identity)
+		} else {
+			hostIndex[h.Host] = len(yd.Hosts)
+			yd.Hosts = append(yd.Hosts, yaml.MapItem{Key: h.Host, Value: []documentIdentity{identity}})
+		}
+	}
+
+	for _, variable := range doc.GitConfig {
+		var value interface{} = variable.Values
+		if len(variable.Values) == 1 {
+			value = variable.Values[0]
+		}
+		yd.GitConfig = append(yd.GitConfig, yaml.MapItem{Key: variable.Name, Value: value})
+	}
+
+	d, err := yaml.Marshal(yd)
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(d)
+	return err
+}
+
+// ParseConfigDocument reads a document produced by Encode and validates it.
+func ParseConfigDocument(data []byte) (*ConfigDocument, error) {
+	invalid := func(format string, a ...interface{}) error {
+		return fmt.Errorf("invalid config document: %s", fmt.Sprintf(format, a...))
+	}
+	yamlError := func(err error) string {
+		msg := strings.TrimPrefix(err.Error(), "yaml: ")
+		return yamlUnknownFieldRe.ReplaceAllString(msg, "unknown property \"$1\"")
+	}
+
+	yd := yamlConfigDocument{}
+	if err := yaml.UnmarshalStrict(data, &yd); err != nil {
+		return nil, invalid("%s", yamlError(err))
+	}
+	if yd.Version != configDocumentVersion {
+		return nil, invalid("unsupported version %d, expected %d", yd.Version, configDocumentVersion)
+	}
+
+	doc := &ConfigDocument{}
+	for _, item := range yd.Hosts {
+		hostName, ok := item.Key.(string)
+		if !ok || hostName == "" {
+			return nil, invalid("host names must be strings")
+		}
+		if _, err := url.Parse("https://" + hostName); err != nil {
+			return nil, invalid("invalid host name %q", hostName)
+		}
+
+		// decode each host again to reject unknown identity properties
+		d, err := yaml.Marshal(item.Value)
+		if err != nil {
+			return nil, err
+		}
+		identities := []documentIdentity{}
+		if err := yaml.UnmarshalStrict(d, &identities); err != nil {
+			return nil, invalid("hosts.%s: %s", hostName, yamlError(err))
+		}
+
+		names := map[string]bool{}
+		for i, identity := range identities {
+			h := &Host{
+				Host:        hostName,
+				Name:        identity.Name,
+				User:        identity.User,
+				AccessToken: identity.OAuthToken,
+				Protocol:    identity.Protocol,
+				UnixSocket:  identity.UnixSocket,
+			}
+			if h.User == "" {
+				return nil, invalid("hosts.%s[%d]: user is missing", hostName, i)
+			}
+			if h.Protocol != "" && h.Protocol != "https" && h.Protocol != "http" {
+				return nil, invalid("hosts.%s[%d]: protocol must be \"https\" or \"http\"", hostName, i)
+			}
+			if names[h.IdentityName()] {
+				return nil, invalid("hosts.%s: more than one identity named %q", hostName, h.IdentityName())
+			}
+			names[h.IdentityName()] = true
+			doc.Hosts = append(doc.Hosts, h)
+		}
+	}
+
+	for _, item := range yd.GitConfig {
+		name, ok := item.Key.(string)
+		if !ok || !configDocumentGitConfigRe.MatchString(name) {
+			return nil, invalid("git_config.%v: only \"hub.*\" and \"alias.*\" variables can be imported", item.Key)
+		}
+
+		variable := GitConfigVariable{Name: name}
+		switch value := item.Value.(type) {
+		case []interface{}:
+			for _, v := range value {
+				s, ok := configScalar(v)
+				if !ok {
+					return nil, invalid("git_config.%s: values must be strings", name)
+				}
+				variable.Values = append(variable.Values, s)
+			}
+		default:
+			s, ok := configScalar(value)
+			if !ok {
+				return nil, invalid("git_config.%s: value must be a string or a list of strings", name)
+			}
+			variable.Values = []string{s}
+		}
+		doc.GitConfig = append(doc.GitConfig, variable)
+	}
+
+	return doc, nil
+}
+
+// configScalar returns the git config value for v, accepting values such as
+// `true` or `10` that YAML would not read as strings.
+func configScalar(v interface{}) (string, bool) {
+	switch v := v.(type) {
+	case string:
+		return v, true
+	case bool, int, int64, uint64, float64:
+		return fmt.Sprint(v), true
+	}
+	return "", false
+}
+
+// ImportConfigDocument stores the configuration from doc. By default, it is
+// merged with the existing configuration: identities are matched by host and
+// name, and git config variables in doc overwrite existing ones. With
+// replace, identities and hub-related git config variables that aren't in
+// doc are removed. Identities without an access token keep the one that is
+// already stored for them.
+//
+// The configuration file is replaced atomically and never becomes accessible
+// by more users than before.
+func ImportConfigDocument(doc *ConfigDocument, replace bool) error {
+	filename := configsFile()
+	existing := &Config{}
+	if err := newConfigService().Load(filename, existing); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+
+	find := func(hosts []*Host, h *Host) *Host {
+		for _, candidate := range hosts {
+			if candidate.Host == h.Host && candidate.IdentityName() == h.IdentityName() {
+				return candidate
+			}
+		}
+		return nil
+	}
+
+	c := &Config{}
+	if !replace {
+		c.Hosts = existing.Hosts
+	}
+	for _, imported := range doc.Hosts {
+		h := *imported
+		if h.Protocol == "" {
+			h.Protocol = "https"
+		}
+		if h.AccessToken == "" {
+			if current := find(existing.Hosts, &h); current != nil {
+				h.AccessToken = current.AccessToken
+			}
+		}
+		if current := find(c.Hosts, &h); current != nil {
+			*current = h
+		} else {
+			c.Hosts = append(c.Hosts, &h)
+		}
+	}
+
+	if err := saveConfigAtomically(filename, c); err != nil {
+		return err
+	}
+	configLoadedFrom = ""
+
+	if replace {
+		entries, err := git.GlobalConfigEntries(configDocumentGitConfig)
+		if err != nil {
+			return err
+		}
+		kept := map[string]bool{}
+		for _, variable := range doc.GitConfig {
+			kept[strings.ToLower(variable.Name)] = true
+		}
+		for _, entry := range entries {
+			if !kept[strings.ToLower(entry.Name)] {
+				if err := git.UnsetGlobalConfig(entry.Name); err != nil {
+					return err
+				}
+			}
+		}
+	}
+
+	for _, variable := range doc.GitConfig {
+		if err := git.UnsetGlobalConfig(variable.Name); err != nil {
+			return err
+		}
+		for _, value := range variable.Values {
+			if err := git.AddGlobalConfig(variable.Name, value); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// saveConfigAtomically writes c to a temporary file that then takes the place
+// of filename, so that a failed write never leaves a partial configuration.
+// The permissions of an existing file are kept, but access tokens are never
+// written to a file that other users can read.
+func saveConfigAtomically(filename string, c *Config) error {
+	mode := os.FileMode(0600)
+	if info, err := os.Stat(filename); err == nil {
+		mode = info.Mode().Perm()
+	} else if !os.IsNotExist(err) {
+		return err
+	}
+
+	for _, h := range c.Hosts {
+		if h.AccessToken != "" && mode&0077 != 0 {
+			return fmt.Errorf("refusing to write access tokens to %s since other users can read it\nRestrict its permissions first with `chmod 600 %s`", filename, filename)
+		}
+	}
+
+	dir := filepath.Dir(filename)
+	if err := os.MkdirAll(dir, 0771); err != nil {
+		return err
+	}
+
+	tmp, err := ioutil.TempFile(dir, ".hub-")
+	if err != nil {
+		return err
+	}
+	err = newConfigService().Encoder.Encode(tmp, c)
+	if err == nil {
+		err = tmp.Chmod(mode)
+	}
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	return os.Rename(tmp.Name(), filename)
+}
@@ -0,0 +1,236 @@
+package github
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/bmizerany/assert"
+	"github.com/github/hub/fixtures"
+	"github.com/github/hub/git"
+)
+
+const testConfigDocument = `version: 1
+hosts:
+  github.com:
+  - user: mislav
+    oauth_token: OTOKEN
+    protocol: https
+  - name: bot
+    user: mislav-bot
+    oauth_token: BOTTOKEN
+    protocol: https
+  git.example.com:
+  - user: jane
+    oauth_token: ETOKEN
+    protocol: http
+    unix_socket: /tmp/hub.sock
+git_config:
+  hub.protocol: ssh
+  hub.host:
+  - git.example.com
+  - gh.example.org
+  alias.lg: |-
+    log --graph
+    --oneline
+`
+
+// setupConfigTransfer isolates both the hub configuration file and the global
+// git config of the test.
+func setupConfigTransfer(t *testing.T) (configFile string, tearDown func()) {
+	repo := fixtures.SetupTestRepo()
+	configFile = filepath.Join(os.Getenv("HOME"), ".config", "hub")
+	os.Setenv("HUB_CONFIG", configFile)
+	configLoadedFrom = ""
+	return configFile, func() {
+		os.Unsetenv("HUB_CONFIG")
+		configLoadedFrom = ""
+		repo.TearDown()
+	}
+}
+
+func exportTestConfig(t *testing.T, includeTokens bool) string {
+	configLoadedFrom = ""
+	doc, err := CurrentConfigDocument()
+	assert.Equal(t, nil, err)
+	out := &bytes.Buffer{}
+	assert.Equal(t, nil, doc.Encode(out, includeTokens))
+	return out.String()
+}
+
+func importTestConfig(t *testing.T, document string, replace bool) error {
+	doc, err := ParseConfigDocument([]byte(document))
+	assert.Equal(t, nil, err)
+	return ImportConfigDocument(doc, replace)
+}
+
+func TestConfigDocument_RoundTrip(t *testing.T) {
+	_, tearDown := setupConfigTransfer(t)
+	defer tearDown()
+
+	assert.Equal(t, nil, importTestConfig(t, testConfigDocument, true))
+	assert.Equal(t, testConfigDocument, exportTestConfig(t, true))
+
+	hosts := CurrentConfig().Hosts
+	assert.Equal(t, 3, len(hosts))
+	assert.Equal(t, Host{
+		Host:        "git.example.com",
+		User:        "jane",
+		AccessToken: "ETOKEN",
+		Protocol:    "http",
+		UnixSocket:  "/tmp/hub.sock",
+	}, *hosts[2])
+
+	values, _ := git.ConfigAll("hub.host")
+	assert.Equal(t, []string{"git.example.com", "gh.example.org"}, values)
+
+	assert.Equal(t, nil, importTestConfig(t, exportTestConfig(t, true), true))
+	assert.Equal(t, testConfigDocument, exportTestConfig(t, true))
+}
+
+func TestConfigDocument_ExportWithoutTokens(t *testing.T) {
+	_, tearDown := setupConfigTransfer(t)
+	defer tearDown()
+
+	assert.Equal(t, nil, importTestConfig(t, testConfigDocument, true))
+	out := exportTestConfig(t, false)
+	assert.Equal(t, false, strings.Contains(out, "oauth_token"))
+	assert.Equal(t, false, strings.Contains(out, "TOKEN"))
+
+	// importing a document without tokens keeps the stored ones
+	assert.Equal(t, nil, importTestConfig(t, out, true))
+	assert.Equal(t, testConfigDocument, exportTestConfig(t, true))
+}
+
+func TestConfigDocument_Merge(t *testing.T) {
+	_, tearDown := setupConfigTransfer(t)
+	defer tearDown()
+
+	assert.Equal(t, nil, importTestConfig(t, testConfigDocument, true))
+	git.SetGlobalConfig("hub.hyperlinks", "false")
+
+	err := importTestConfig(t, `version: 1
+hosts:
+  github.com:
+  - name: bot
+    user: mislav-robot
+  ci.example.com:
+  - user: builder
+    oauth_token: CITOKEN
+git_config:
+  hub.protocol: https
+`, false)
+	assert.Equal(t, nil, err)
+
+	hosts := CurrentConfig().Hosts
+	assert.Equal(t, 4, len(hosts))
+	assert.Equal(t, "mislav-robot", hosts[1].User)
+	assert.Equal(t, "BOTTOKEN", hosts[1].AccessToken)
+	assert.Equal(t, Host{Host: "ci.example.com", User: "builder", AccessToken: "CITOKEN", Protocol: "https"}, *hosts[3])
+
+	protocol, _ := git.GlobalConfig("hub.protocol")
+	assert.Equal(t, "https", protocol)
+	hyperlinks, _ := git.GlobalConfig("hub.hyperlinks")
+	assert.Equal(t, "false", hyperlinks)
+	values, _ := git.ConfigAll("hub.host")
+	assert.Equal(t, 2, len(values))
+}
+
+func TestConfigDocument_Replace(t *testing.T) {
+	_, tearDown := setupConfigTransfer(t)
+	defer tearDown()
+
+	assert.Equal(t, nil, importTestConfig(t, testConfigDocument, true))
+	git.SetGlobalConfig("user.name", "Mislav")
+
+	err := importTestConfig(t, `version: 1
+hosts:
+  github.com:
+  - user: mislav
+git_config:
+  alias.co: checkout
+`, true)
+	assert.Equal(t, nil, err)
+
+	assert.Equal(t, `version: 1
+hosts:
+  github.com:
+  - user: mislav
+    oauth_token: OTOKEN
+    protocol: https
+git_config:
+  alias.co: checkout
+`, exportTestConfig(t, true))
+
+	name, _ := git.GlobalConfig("user.name")
+	assert.Equal(t, "Mislav", name)
+}
+
+func TestConfigDocument_KeepsPermissions(t *testing.T) {
+	configFile, tearDown := setupConfigTransfer(t)
+	defer tearDown()
+
+	assert.Equal(t, nil, importTestConfig(t, testConfigDocument, true))
+	info, err := os.Stat(configFile)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, os.FileMode(0600), info.Mode().Perm())
+
+	assert.Equal(t, nil, os.Chmod(configFile, 0400))
+	assert.Equal(t, nil, importTestConfig(t, testConfigDocument, false))
+	info, _ = os.Stat(configFile)
+	assert.Equal(t, os.FileMode(0400), info.Mode().Perm())
+
+	assert.Equal(t, nil, os.Chmod(configFile, 0644))
+	before, _ := ioutil.ReadFile(configFile)
+	err = importTestConfig(t, testConfigDocument, false)
+	assert.NotEqual(t, nil, err)
+	assert.T(t, strings.HasPrefix(err.Error(), "refusing to write access tokens to "+configFile))
+	after, _ := ioutil.ReadFile(configFile)
+	assert.Equal(t, string(before), string(after))
+	info, _ = os.Stat(configFile)
+	assert.Equal(t, os.FileMode(0644), info.Mode().Perm())
+
+	// without tokens, there is nothing to protect
+	assert.Equal(t, nil, importTestConfig(t, "version: 1\nhosts:\n  ci.example.com:\n  - user: builder\n", true))
+	info, _ = os.Stat(configFile)
+	assert.Equal(t, os.FileMode(0644), info.Mode().Perm())
+}
+
+func TestParseConfigDocument_Invalid(t *testing.T) {
+	invalid := map[string]string{
+		"hosts: {}\n":               "unsupported version 0, expected 1",
+		"version: 2\n":              "unsupported version 2, expected 1",
+		"version: 1\nremotes: {}\n": `line 2: unknown property "remotes"`,
+		"version: 1\nhosts:\n  github.com: yes\n": "hosts.github.com: unmarshal errors",
+		"version: 1\nhosts:\n  github.com:\n  - user: mislav\n    token: T\n": `hosts.github.com: unmarshal errors:
+  line 2: unknown property "token"`,
+		"version: 1\nhosts:\n  github.com:\n  - name: bot\n":                                     "hosts.github.com[0]: user is missing",
+		"version: 1\nhosts:\n  github.com:\n  - user: mislav\n    protocol: ssh\n":               `hosts.github.com[0]: protocol must be "https" or "http"`,
+		"version: 1\nhosts:\n  github.com:\n  - user: mislav\n  - name: mislav\n    user: bot\n": `hosts.github.com: more than one identity named "mislav"`,
+		"version: 1\ngit_config:\n  user.name: Mislav\n":                                         `git_config.user.name: only "hub.*" and "alias.*" variables can be imported`,
+		"version: 1\ngit_config:\n  hub.host:\n  - a: b\n":                                       "git_config.hub.host: values must be strings",
+	}
+
+	for document, message := range invalid {
+		_, err := ParseConfigDocument([]byte(document))
+		if err == nil {
+			t.Errorf("expected error for %q", document)
+			continue
+		}
+		if !strings.Contains(err.Error(), message) {
+			t.Errorf("expected error for %q to contain %q, got %q", document, message, err.Error())
+		}
+	}
+}
+
+func TestParseConfigDocument_Scalars(t *testing.T) {
+	doc, err := ParseConfigDocument([]byte("version: 1\ngit_config:\n  hub.protectPrCheckouts: true\n  hub.test:\n  - 10\n  - x\n"))
+	assert.Equal(t, nil, err)
+	assert.Equal(t, []GitConfigVariable{
+		{"hub.protectPrCheckouts", []string{"true"}},
+		{"hub.test", []string{"10", "x"}},
+	}, doc.GitConfig)
+}
@@ -0,0 +1,122 @@
+package github
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/mitchellh/go-homedir"
+)
+
+// Where the access token for a host came from, in order of precedence.
+const (
+	credentialsFromEnv           = "GITHUB_TOKEN"
+	credentialsFromConfig        = "hub config"
+	credentialsFromNetrc         = "netrc"
+	credentialsFromGitCredential = "git credential"
+)
+
+// lookupToken returns the access token for host from the first source that
+// has one: the GITHUB_TOKEN environment variable, hub's hosts file, a netrc
+// file, and git credential helpers, along with the name of that source. Both
+// are empty if none of them has a token, in which case the user has to be
+// prompted for credentials.
+func (c *Config) lookupToken(host string) (token, source string) {
+	if token = c.DetectToken(); token != "" {
+		return token, credentialsFromEnv
+	}
+	if h := c.Find(host); h != nil && h.AccessToken != "" {
+		return h.AccessToken, credentialsFromConfig
+	}
+	if token = netrcToken(host); token != "" {
+		return token, credentialsFromNetrc
+	}
+	if token = gitCredentialToken(host); token != "" {
+		return token, credentialsFromGitCredential
+	}
+	return "", ""
+}
+
+// netrcToken looks up the password of the "machine" entry for the API host of
+// host, or for host itself, in "~/.netrc" (or "~/_netrc" on Windows). Missing
+// or unreadable files yield an empty string.
+func netrcToken(host string) string {
+	home, err := homedir.Dir()
+	if err != nil {
+		return ""
+	}
+
+	for _, name := range []string{".netrc", "_netrc"} {
+		f, err := os.Open(filepath.Join(home, name))
+		if err != nil {
+			continue
+		}
+		machines := parseNetrc(bufio.NewScanner(f))
+		f.Close()
+
+		for _, machine := range []string{normalizeHost(host), strings.ToLower(host)} {
+			if password := machines[machine]; password != "" {
+				return password
+			}
+		}
+		return ""
+	}
+	return ""
+}
+
+// parseNetrc maps the machine names of a netrc file to their passwords. The
+// "default" entry is ignored, since its credentials aren't meant for any host
+// in particular.
+func parseNetrc(scanner *bufio.Scanner) map[string]string {
+	scanner.Split(bufio.ScanWords)
+	passwords := map[string]string{}
+	machine := ""
+	for scanner.Scan() {
+		switch scanner.Text() {
+		case "machine":
+			machine = ""
+			if scanner.Scan() {
+				machine = strings.ToLower(scanner.Text())
+			}
+		case "default":
+			machine = ""
+		case "password":
+			if scanner.Scan() && machine != "" {
+				if _, seen := passwords[machine]; !seen {
+					passwords[machine] = scanner.Text()
+				}
+			}
+		case "login", "account":
+			scanner.Scan()
+		case "macdef":
+			// macro definitions run until the end of the file as far as a
+			// word-based scanner is concerned
+			return passwords
+		}
+	}
+	return passwords
+}
+
+// gitCredentialToken asks git credential helpers for the password for host,
+// like git does before fetching over HTTPS. This includes GIT_ASKPASS, but
+// git is kept from prompting on the terminal. Any failure yields an empty
+// string.
+func gitCredentialToken(host string) string {
+	fill := exec.Command("git", "credential", "fill")
+	fill.Stdin = strings.NewReader(fmt.Sprintf("protocol=https\nhost=%s\n\n", host))
+	fill.Env = append(os.Environ(), "GIT_TERMINAL_PROMPT=0")
+	output, err := fill.Output()
+	if err != nil {
+		return ""
+	}
+
+	for _, line := range strings.Split(string(output), "\n") {
+		if strings.HasPrefix(line, "password=") {
+			return strings.TrimSpace(strings.TrimPrefix(line, "password="))
+		}
+	}
+	return ""
+}
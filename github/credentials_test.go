@@ -0,0 +1,79 @@
+package github
+
+import (
+	"bufio"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/bmizerany/assert"
+	"github.com/github/hub/cmd"
+	"github.com/github/hub/fixtures"
+)
+
+func TestConfig_lookupToken(t *testing.T) {
+	repo := fixtures.SetupTestRepo()
+	defer repo.TearDown()
+
+	envToken := os.Getenv("GITHUB_TOKEN")
+	os.Unsetenv("GITHUB_TOKEN")
+	defer os.Setenv("GITHUB_TOKEN", envToken)
+
+	setHelper := func(helper string) {
+		if _, err := cmd.New("git").WithArgs("config", "--global", "credential.helper", helper).CombinedOutput(); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	c := &Config{}
+	token, source := c.lookupToken("github.com")
+	assert.Equal(t, "", token)
+	assert.Equal(t, "", source)
+
+	// failing helpers fall through to the next source
+	setHelper("!false")
+	token, source = c.lookupToken("github.com")
+	assert.Equal(t, "", token)
+	assert.Equal(t, "", source)
+
+	setHelper(`!f() { test "$1" = get && printf "username=mislav\npassword=HELPERTOKEN\n"; }; f`)
+	token, source = c.lookupToken("github.com")
+	assert.Equal(t, "HELPERTOKEN", token)
+	assert.Equal(t, credentialsFromGitCredential, source)
+
+	netrc := filepath.Join(os.Getenv("HOME"), ".netrc")
+	err := ioutil.WriteFile(netrc, []byte("machine api.github.com login mislav password NETRCTOKEN\n"), 0600)
+	assert.Equal(t, nil, err)
+	token, source = c.lookupToken("github.com")
+	assert.Equal(t, "NETRCTOKEN", token)
+	assert.Equal(t, credentialsFromNetrc, source)
+
+	c.Hosts = []*Host{{Host: "github.com", User: "mislav", AccessToken: "CONFIGTOKEN"}}
+	token, source = c.lookupToken("github.com")
+	assert.Equal(t, "CONFIGTOKEN", token)
+	assert.Equal(t, credentialsFromConfig, source)
+
+	os.Setenv("GITHUB_TOKEN", "ENVTOKEN")
+	token, source = c.lookupToken("github.com")
+	assert.Equal(t, "ENVTOKEN", token)
+	assert.Equal(t, credentialsFromEnv, source)
+}
+
+func TestParseNetrc(t *testing.T) {
+	passwords := parseNetrc(bufio.NewScanner(strings.NewReader(`
+machine api.github.com
+  login mislav
+  password TOKEN1
+machine GHE.Example.com login hubot account ops password TOKEN2
+machine api.github.com login other password TOKEN3
+default login anonymous password guest
+macdef init
+  cd /pub
+`)))
+	assert.Equal(t, map[string]string{
+		"api.github.com":  "TOKEN1",
+		"ghe.example.com": "TOKEN2",
+	}, passwords)
+}
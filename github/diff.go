@@ -0,0 +1,43 @@
+package github
+
+import "github.com/github/hub/diff"
+
+// DiffHunk lists the line numbers of the old (LEFT) and new (RIGHT) version
+// of a file that a hunk of its diff covers. These are the lines that review
+// comments can be placed on.
+type DiffHunk struct {
+	Left  []int
+	Right []int
+}
+
+// Lines returns the line numbers covered on the given side, "LEFT" or "RIGHT".
+func (h DiffHunk) Lines(side string) []int {
+	if side == "LEFT" {
+		return h.Left
+	}
+	return h.Right
+}
+
+// Contains reports whether the line is covered on the given side.
+func (h DiffHunk) Contains(side string, line int) bool {
+	for _, l := range h.Lines(side) {
+		if l == line {
+			return true
+		}
+	}
+	return false
+}
+
+// ParseDiffHunks parses the patch the API returns for a changed file.
+func ParseDiffHunks(patch string) ([]DiffHunk, error) {
+	parsed, err := diff.ParseHunks(patch)
+	if err != nil {
+		return nil, err
+	}
+	hunks := []DiffHunk{}
+	for _, hunk := range parsed {
+		left, right := hunk.LineNumbers()
+		hunks = append(hunks, DiffHunk{Left: left, Right: right})
+	}
+	return hunks, nil
+}
@@ -0,0 +1,48 @@
+package github
+
+import (
+	"testing"
+
+	"github.com/bmizerany/assert"
+)
+
+func TestParseDiffHunks(t *testing.T) {
+	patch := `@@ -1,4 +1,5 @@ package main
+ import (
+-	"os"
++	"fmt"
++	"strings"
+ )
+ 
+@@ -20,2 +21,2 @@ func main() {
+-	os.Exit(1)
++	fmt.Println("hello")
+\ No newline at end of file`
+
+	hunks, err := ParseDiffHunks(patch)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, 2, len(hunks))
+
+	assert.Equal(t, []int{1, 2, 3, 4}, hunks[0].Left)
+	assert.Equal(t, []int{1, 2, 3, 4, 5}, hunks[0].Right)
+	assert.Equal(t, []int{20}, hunks[1].Left)
+	assert.Equal(t, []int{21}, hunks[1].Right)
+
+	assert.T(t, hunks[0].Contains("RIGHT", 3))
+	assert.T(t, !hunks[0].Contains("LEFT", 5))
+	assert.T(t, hunks[1].Contains("LEFT", 20))
+	assert.T(t, !hunks[1].Contains("RIGHT", 20))
+}
+
+func TestParseDiffHunks_SingleLine(t *testing.T) {
+	hunks, err := ParseDiffHunks("@@ -0,0 +1 @@\n+hello\n")
+	assert.Equal(t, nil, err)
+	assert.Equal(t, 1, len(hunks))
+	assert.Equal(t, 0, len(hunks[0].Left))
+	assert.Equal(t, []int{1}, hunks[0].Right)
+}
+
+func TestParseDiffHunks_Invalid(t *testing.T) {
+	_, err := ParseDiffHunks("Binary files differ")
+	assert.Equal(t, `invalid diff: expected a hunk header, got "Binary files differ"`, err.Error())
+}
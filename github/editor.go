@@ -3,19 +3,24 @@ package github
 import (
 	"bufio"
 	"bytes"
+	"errors"
 	"fmt"
 	"io/ioutil"
 	"os"
 	"path/filepath"
 	"regexp"
+	"runtime"
 	"strings"
 
 	"github.com/github/hub/cmd"
 	"github.com/github/hub/git"
+	"github.com/kballard/go-shellquote"
 )
 
 const Scissors = "------------------------ >8 ------------------------"
 
+var errNoEditor = errors.New("no editor available; use -m or -F")
+
 func NewEditor(filename, topic, message string) (editor *Editor, err error) {
 	gitDir, err := git.Dir()
 	if err != nil {
@@ -23,10 +28,15 @@ func NewEditor(filename, topic, message string) (editor *Editor, err error) {
 	}
 	messageFile := filepath.Join(gitDir, filename)
 
-	program, err := git.Editor()
+	program, err := resolveEditor()
 	if err != nil {
 		return
 	}
+	// an editor that scripts set through GIT_EDITOR might not need a terminal
+	if os.Getenv("GIT_EDITOR") == "" && !hasConsole() {
+		err = errNoEditor
+		return
+	}
 
 	cs, err := git.CommentChar(message)
 	if err != nil {
@@ -67,6 +77,25 @@ func (e *Editor) AddCommentedSection(text string) {
 	e.Message = e.Message + "\n" + text
 }
 
+// AddCommentedLines adds a section below the scissors line in which every line
+// is prefixed with the comment character, like the status that git lists in
+// commit message templates. The section is separated from any previous
+// commented section by a blank line.
+func (e *Editor) AddCommentedLines(text string) {
+	if e.addedFirstComment {
+		e.Message += "\n"
+	}
+	lines := strings.Split(text, "\n")
+	for i, line := range lines {
+		if line == "" {
+			lines[i] = e.CS
+		} else {
+			lines[i] = e.CS + " " + line
+		}
+	}
+	e.AddCommentedSection(strings.Join(lines, "\n"))
+}
+
 func (e *Editor) DeleteFile() error {
 	return os.Remove(e.File)
 }
@@ -136,16 +165,130 @@ func (e *Editor) readContent() (content []byte, err error) {
 	return ioutil.ReadFile(e.File)
 }
 
+// resolveEditor picks the text editor the way git does: GIT_EDITOR, the
+// core.editor setting, VISUAL unless the terminal is dumb, EDITOR, and "vi"
+// when none of them is set. Over SSH without a display, EDITOR is preferred to
+// VISUAL, which often names a graphical editor set up for the desktop.
+func resolveEditor() (string, error) {
+	if editor := os.Getenv("GIT_EDITOR"); editor != "" {
+		return editor, nil
+	}
+	if editor, err := git.Config("core.editor"); err == nil && editor != "" {
+		return editor, nil
+	}
+
+	term := os.Getenv("TERM")
+	terminalIsDumb := term == "" || term == "dumb"
+	visual, editor := os.Getenv("VISUAL"), os.Getenv("EDITOR")
+	if visual != "" && !terminalIsDumb && (editor == "" || !isRemoteWithoutDisplay()) {
+		return visual, nil
+	}
+	if editor != "" {
+		return editor, nil
+	}
+	if terminalIsDumb {
+		return "", fmt.Errorf("terminal is dumb, but EDITOR unset; use -m or -F")
+	}
+	return "vi", nil
+}
+
+func isRemoteWithoutDisplay() bool {
+	isRemote := os.Getenv("SSH_CONNECTION") != "" || os.Getenv("SSH_TTY") != ""
+	return isRemote && os.Getenv("DISPLAY") == "" && os.Getenv("WAYLAND_DISPLAY") == ""
+}
+
 func openTextEditor(program, file string) error {
-	editCmd := cmd.New(program)
-	r := regexp.MustCompile(`\b(?:[gm]?vim)(?:\.exe)?$`)
-	if r.MatchString(editCmd.Name) {
-		editCmd.WithArg("--cmd")
-		editCmd.WithArg("set ft=gitcommit tw=0 wrap lbr")
+	return spawnEditor(editorCommand(program, file, runtime.GOOS == "windows"))
+}
+
+// EditFile opens an existing file in the text editor and waits for it to be
+// closed. Unlike with an Editor, the file isn't a message, so nothing is added
+// to it and it may be outside of a git repository.
+func EditFile(file string) error {
+	program, err := resolveEditor()
+	if err != nil {
+		return err
+	}
+	if os.Getenv("GIT_EDITOR") == "" && !hasConsole() {
+		return errors.New("no editor available")
 	}
-	editCmd.WithArg(file)
+
+	if err := spawnEditor(plainEditorCommand(program, file, runtime.GOOS == "windows")); err != nil {
+		return fmt.Errorf("error using text editor for %s", filepath.Base(file))
+	}
+	return nil
+}
+
+func spawnEditor(args []string) error {
+	editCmd := cmd.NewWithArray(args)
 	// Reattach stdin to the console before opening the editor
 	setConsole(editCmd)
 
 	return editCmd.Spawn()
 }
+
+// editorCommand returns the command line that opens file with the editor
+// program. Like git, it has the shell run the program so that it can include
+// arguments, quotes and variables, and passes the filename as "$@" so that it
+// needs no quoting. The Windows console has no such shell, so there the program
+// is split on whitespace outside of double quotes, leaving the backslashes in
+// paths alone.
+func editorCommand(program, file string, windows bool) []string {
+	words := editorWords(program, windows)
+
+	args := []string{}
+	vimPattern := regexp.MustCompile(`\b(?:[gm]?vim)(?:\.exe)?$`)
+	if len(words) > 0 && vimPattern.MatchString(words[0]) {
+		args = append(args, "--cmd", "set ft=gitcommit tw=0 wrap lbr")
+	}
+	args = append(args, file)
+
+	if windows {
+		return append(words, args...)
+	}
+	return append([]string{"sh", "-c", program + ` "$@"`, program}, args...)
+}
+
+// plainEditorCommand is like editorCommand for files that aren't commit
+// messages, leaving it to the editor to pick their file type.
+func plainEditorCommand(program, file string, windows bool) []string {
+	if windows {
+		return append(editorWords(program, windows), file)
+	}
+	return []string{"sh", "-c", program + ` "$@"`, program, file}
+}
+
+func editorWords(program string, windows bool) []string {
+	if windows {
+		return splitWindowsCommand(os.ExpandEnv(program))
+	}
+	words, _ := shellquote.Split(program)
+	return words
+}
+
+// splitWindowsCommand splits a command line into words separated by
+// whitespace, keeping together what's enclosed in double quotes.
+func splitWindowsCommand(command string) []string {
+	words := []string{}
+	word := ""
+	inWord, inQuotes := false, false
+	for _, c := range command {
+		switch {
+		case c == '"':
+			inQuotes = !inQuotes
+			inWord = true
+		case (c == ' ' || c == '\t') && !inQuotes:
+			if inWord {
+				words = append(words, word)
+				word, inWord = "", false
+			}
+		default:
+			word += string(c)
+			inWord = true
+		}
+	}
+	if inWord {
+		words = append(words, word)
+	}
+	return words
+}
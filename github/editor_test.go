@@ -4,6 +4,10 @@ import (
 	"fmt"
 	"io/ioutil"
 	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
 	"testing"
 
 	"github.com/bmizerany/assert"
@@ -76,3 +80,139 @@ func TestEditor_openAndEdit_writeFileIfNotExist(t *testing.T) {
 	assert.Equal(t, nil, err)
 	assert.Equal(t, "hello", string(content))
 }
+
+func TestEditor_AddCommentedLines(t *testing.T) {
+	tempFile, _ := ioutil.TempFile("", "editor-test")
+	tempFile.Close()
+	os.Remove(tempFile.Name())
+	defer os.Remove(tempFile.Name())
+
+	editor := Editor{
+		Program: "memory",
+		File:    tempFile.Name(),
+		Message: "Title\n\n# Heading",
+		CS:      ";",
+		openEditor: func(program string, file string) error {
+			return nil
+		},
+	}
+	editor.AddCommentedLines("Recent issues:\n\n  #12 Fix the build")
+
+	assert.Equal(t, `Title
+
+# Heading
+; ------------------------ >8 ------------------------
+; Do not modify or remove the line above.
+; Everything below it will be ignored.
+
+; Recent issues:
+;
+;   #12 Fix the build`, editor.Message)
+
+	content, err := editor.EditContent()
+	assert.Equal(t, nil, err)
+	assert.Equal(t, "Title\n\n# Heading", content)
+}
+
+func TestEditorCommand(t *testing.T) {
+	file := "/tmp/my dir/PULLREQ_EDITMSG"
+
+	assert.Equal(t, []string{"sh", "-c", `code --wait "$@"`, "code --wait", file}, editorCommand("code --wait", file, false))
+
+	subl := `"/Applications/Sublime Text.app/bin/subl" -w`
+	assert.Equal(t, []string{"sh", "-c", subl + ` "$@"`, subl, file}, editorCommand(subl, file, false))
+
+	assert.Equal(t, []string{"sh", "-c", `vim "$@"`, "vim", "--cmd", "set ft=gitcommit tw=0 wrap lbr", file}, editorCommand("vim", file, false))
+	assert.Equal(t, []string{"sh", "-c", `/usr/local/bin/gvim -f "$@"`, "/usr/local/bin/gvim -f", "--cmd", "set ft=gitcommit tw=0 wrap lbr", file}, editorCommand("/usr/local/bin/gvim -f", file, false))
+
+	// other files are left to the editor to detect the type of
+	assert.Equal(t, []string{"sh", "-c", `vim "$@"`, "vim", "/tmp/notes.md"}, plainEditorCommand("vim", "/tmp/notes.md", false))
+	assert.Equal(t, []string{`C:\tools\vim\gvim.exe`, "notes.md"}, plainEditorCommand(`C:\tools\vim\gvim.exe`, "notes.md", true))
+}
+
+func TestEditorCommand_Windows(t *testing.T) {
+	file := `C:\Users\Mona Lisa\repo\.git\PULLREQ_EDITMSG`
+
+	notepad := `"C:\Program Files\Notepad++\notepad++.exe" -multiInst -nosession`
+	assert.Equal(t, []string{`C:\Program Files\Notepad++\notepad++.exe`, "-multiInst", "-nosession", file}, editorCommand(notepad, file, true))
+
+	assert.Equal(t, []string{`C:\tools\vim\gvim.exe`, "--cmd", "set ft=gitcommit tw=0 wrap lbr", file}, editorCommand(`C:\tools\vim\gvim.exe`, file, true))
+	assert.Equal(t, []string{"notepad", file}, editorCommand("  notepad ", file, true))
+}
+
+func TestOpenTextEditor_ArgumentsAndSpaces(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("editors are run by sh")
+	}
+	if _, err := exec.LookPath("sh"); err != nil {
+		t.Skip("sh is not available")
+	}
+
+	dir, _ := ioutil.TempDir("", "editor test")
+	defer os.RemoveAll(dir)
+
+	editor := filepath.Join(dir, "my editor")
+	ioutil.WriteFile(editor, []byte("#!/bin/sh\nprintf '%s|' \"$@\" > \"$2\"\n"), 0755)
+	file := filepath.Join(dir, "PULLREQ EDITMSG")
+
+	err := openTextEditor(fmt.Sprintf("'%s' --wait", editor), file)
+	assert.Equal(t, nil, err)
+	content, _ := ioutil.ReadFile(file)
+	assert.Equal(t, "--wait|"+file+"|", string(content))
+}
+
+func withEditorEnv(t *testing.T, env map[string]string, fn func()) {
+	names := []string{"GIT_EDITOR", "GIT_CONFIG_PARAMETERS", "GIT_CONFIG_GLOBAL", "GIT_CONFIG_NOSYSTEM", "VISUAL", "EDITOR", "TERM", "SSH_CONNECTION", "SSH_TTY", "DISPLAY", "WAYLAND_DISPLAY"}
+	// leave out the editor that the user might have configured
+	env["GIT_CONFIG_GLOBAL"] = os.DevNull
+	env["GIT_CONFIG_NOSYSTEM"] = "1"
+	saved := map[string]string{}
+	for _, name := range names {
+		if value, ok := os.LookupEnv(name); ok {
+			saved[name] = value
+		}
+		os.Unsetenv(name)
+		if value, ok := env[name]; ok {
+			os.Setenv(name, value)
+		}
+	}
+	defer func() {
+		for _, name := range names {
+			if value, ok := saved[name]; ok {
+				os.Setenv(name, value)
+			} else {
+				os.Unsetenv(name)
+			}
+		}
+	}()
+	fn()
+}
+
+func TestResolveEditor(t *testing.T) {
+	examples := []struct {
+		env    map[string]string
+		editor string
+	}{
+		{map[string]string{"GIT_EDITOR": "code --wait", "GIT_CONFIG_PARAMETERS": "'core.editor=nano'", "VISUAL": "emacs", "TERM": "xterm"}, "code --wait"},
+		{map[string]string{"GIT_CONFIG_PARAMETERS": "'core.editor=nano -w'", "VISUAL": "emacs", "TERM": "xterm"}, "nano -w"},
+		{map[string]string{"VISUAL": "emacs", "EDITOR": "nano", "TERM": "xterm"}, "emacs"},
+		{map[string]string{"VISUAL": "emacs", "EDITOR": "nano", "TERM": "dumb"}, "nano"},
+		{map[string]string{"VISUAL": "subl -w", "EDITOR": "nano", "TERM": "xterm", "SSH_CONNECTION": "10.0.0.1 22 10.0.0.2 22"}, "nano"},
+		{map[string]string{"VISUAL": "subl -w", "EDITOR": "nano", "TERM": "xterm", "SSH_TTY": "/dev/pts/1", "DISPLAY": ":0"}, "subl -w"},
+		{map[string]string{"VISUAL": "vim", "TERM": "xterm", "SSH_TTY": "/dev/pts/1"}, "vim"},
+		{map[string]string{"TERM": "xterm"}, "vi"},
+	}
+
+	for _, example := range examples {
+		withEditorEnv(t, example.env, func() {
+			editor, err := resolveEditor()
+			assert.Equal(t, nil, err)
+			assert.Equal(t, example.editor, editor)
+		})
+	}
+
+	withEditorEnv(t, map[string]string{"TERM": "dumb"}, func() {
+		_, err := resolveEditor()
+		assert.T(t, strings.HasPrefix(err.Error(), "terminal is dumb, but EDITOR unset"))
+	})
+}
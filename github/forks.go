@@ -0,0 +1,62 @@
+package github
+
+import (
+	"fmt"
+	"strings"
+)
+
+// forksListingThreshold is the most forks that FindForks lists in a single
+// request. Repositories with more forks get each owner's fork looked up
+// instead.
+const forksListingThreshold = 100
+
+// FindForks looks up the forks of project that belong to owners. The result
+// is keyed by the lowercased login of the owner; owners without a fork are
+// left out.
+func (client *Client) FindForks(project *Project, owners []string) (forks map[string]*Repository, err error) {
+	repo, err := client.Repository(project)
+	if err != nil {
+		return
+	}
+
+	forks = map[string]*Repository{}
+	wanted := map[string]bool{}
+	for _, owner := range owners {
+		wanted[strings.ToLower(owner)] = true
+	}
+
+	if repo.ForksCount <= forksListingThreshold {
+		var api *simpleClient
+		if api, err = client.simpleApi(); err != nil {
+			return nil, err
+		}
+		res, err := api.Get(fmt.Sprintf("repos/%s/%s/forks?per_page=%d", project.Owner, project.Name, forksListingThreshold))
+		if err = checkStatus(200, "listing forks", res, err); err != nil {
+			return nil, err
+		}
+		listing := []Repository{}
+		if err = res.Unmarshal(&listing); err != nil {
+			return nil, err
+		}
+		for i, fork := range listing {
+			if fork.Owner != nil && wanted[strings.ToLower(fork.Owner.Login)] {
+				forks[strings.ToLower(fork.Owner.Login)] = &listing[i]
+			}
+		}
+		return forks, nil
+	}
+
+	for owner := range wanted {
+		fork, err := client.Repository(NewProject(owner, project.Name, project.Host))
+		if err != nil {
+			if strings.Contains(err.Error(), "HTTP 404") {
+				continue
+			}
+			return nil, err
+		}
+		if fork.Parent != nil && strings.EqualFold(fork.Parent.FullName, repo.FullName) {
+			forks[owner] = fork
+		}
+	}
+	return forks, nil
+}
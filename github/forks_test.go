@@ -0,0 +1,84 @@
+package github
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/bmizerany/assert"
+)
+
+func TestClient_FindForks_listing(t *testing.T) {
+	requests := []string{}
+	client, cleanup := setupTestClient(func(w http.ResponseWriter, r *http.Request) {
+		requests = append(requests, r.URL.Path)
+		switch r.URL.Path {
+		case "/repos/github/hub":
+			fmt.Fprint(w, `{"full_name": "github/hub", "forks_count": 3}`)
+		case "/repos/github/hub/forks":
+			fmt.Fprint(w, `[
+				{"name": "hub", "owner": {"login": "Mislav"}},
+				{"name": "hub-fork", "owner": {"login": "josh"}},
+				{"name": "hub", "owner": {"login": "defunkt"}}
+			]`)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	})
+	defer cleanup()
+
+	forks, err := client.FindForks(&Project{Owner: "github", Name: "hub"}, []string{"mislav", "josh", "hubot"})
+	assert.Equal(t, nil, err)
+	assert.Equal(t, 2, len(forks))
+	assert.Equal(t, "Mislav", forks["mislav"].Owner.Login)
+	assert.Equal(t, "hub-fork", forks["josh"].Name)
+	assert.Equal(t, []string{"/repos/github/hub", "/repos/github/hub/forks"}, requests)
+}
+
+func TestClient_FindForks_lookups(t *testing.T) {
+	client, cleanup := setupTestClient(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/repos/github/hub":
+			fmt.Fprint(w, `{"full_name": "github/hub", "forks_count": 5000}`)
+		case "/repos/mislav/hub":
+			fmt.Fprint(w, `{"name": "hub", "owner": {"login": "mislav"}, "parent": {"full_name": "github/hub"}}`)
+		case "/repos/josh/hub":
+			fmt.Fprint(w, `{"name": "hub", "owner": {"login": "josh"}, "parent": null}`)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	})
+	defer cleanup()
+
+	forks, err := client.FindForks(&Project{Owner: "github", Name: "hub"}, []string{"mislav", "josh", "hubot"})
+	assert.Equal(t, nil, err)
+	assert.Equal(t, 1, len(forks))
+	assert.Equal(t, "mislav", forks["mislav"].Owner.Login)
+}
+
+func TestClient_WaitForRepository(t *testing.T) {
+	repositoryPollInterval = time.Millisecond
+	defer func() { repositoryPollInterval = time.Second }()
+
+	requests := 0
+	client, cleanup := setupTestClient(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		if r.URL.Path != "/repos/mislav/hub" || requests < 3 {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		fmt.Fprint(w, `{"name": "hub", "owner": {"login": "mislav"}}`)
+	})
+	defer cleanup()
+
+	waited := 0
+	repo, err := client.WaitForRepository(&Project{Owner: "mislav", Name: "hub"}, time.Minute, func() { waited++ })
+	assert.Equal(t, nil, err)
+	assert.Equal(t, "hub", repo.Name)
+	assert.Equal(t, 3, requests)
+	assert.Equal(t, 1, waited)
+
+	_, err = client.WaitForRepository(&Project{Owner: "mislav", Name: "dotfiles"}, 10*time.Millisecond, nil)
+	assert.Equal(t, "Error: mislav/dotfiles still doesn't exist after 10ms", err.Error())
+}
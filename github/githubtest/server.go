@@ -0,0 +1,365 @@
+// Package githubtest provides a fake GitHub API server for testing code that
+// is built on top of the github package.
+//
+// The server answers requests with fixtures, which are registered with Handle
+// or read from a JSON file with LoadFixtures. A fixture file holds an array of
+// objects with these fields:
+//
+//	method      the HTTP method to answer (default: "GET")
+//	path        the API path, without the "/api/v3" prefix and query string,
+//	            e.g. "/repos/OWNER/REPO/releases"
+//	query       query parameters that the request must have, as an object of
+//	            strings; other parameters are ignored
+//	status      the HTTP status of the response (default: 200)
+//	headers     response headers, as an object of strings
+//	body        the JSON body of the response
+//	items       a JSON array to serve in pages instead of body, with "Link"
+//	            headers pointing to the next and last pages; the "page" and
+//	            "per_page" query parameters select the page
+//	items_key   serve each page of items as the array under this key of an
+//	            object that also has "total_count", like search results and
+//	            check runs are served
+//	per_page    the size of pages of items regardless of "per_page"
+//	error       answer with a canned error instead: "rate_limit",
+//	            "secondary_rate_limit", or "sso"
+//	fail_times  answer the first matching requests with HTTP 502 this many
+//	            times before serving the fixture
+//
+// For example:
+//
+//	[
+//	  {"path": "/repos/mislav/dotfiles", "body": {"name": "dotfiles"}},
+//	  {"method": "DELETE", "path": "/repos/mislav/dotfiles", "status": 204},
+//	  {"path": "/repos/mislav/dotfiles/releases", "items": [{"tag_name": "v2"}, {"tag_name": "v1"}], "per_page": 1}
+//	]
+//
+// Fixtures registered later take precedence over earlier ones for the same
+// request. Requests that no fixture matches are answered with HTTP 404. All
+// requests are recorded for making assertions about them afterwards.
+package githubtest
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/github/hub/github"
+)
+
+// The canned errors that a fixture can answer with.
+const (
+	ErrorRateLimit          = "rate_limit"
+	ErrorSecondaryRateLimit = "secondary_rate_limit"
+	ErrorSSO                = "sso"
+)
+
+// AccessToken is the token of the clients that NewClient returns.
+const AccessToken = "OTOKEN"
+
+// Fixture is a canned response of the fake server. See the package
+// documentation for what its fields mean.
+type Fixture struct {
+	Method    string            `json:"method"`
+	Path      string            `json:"path"`
+	Query     map[string]string `json:"query"`
+	Status    int               `json:"status"`
+	Headers   map[string]string `json:"headers"`
+	Body      json.RawMessage   `json:"body"`
+	Items     []json.RawMessage `json:"items"`
+	ItemsKey  string            `json:"items_key"`
+	PerPage   int               `json:"per_page"`
+	Error     string            `json:"error"`
+	FailTimes int               `json:"fail_times"`
+
+	handler  http.HandlerFunc
+	failures int
+}
+
+// Request is a request that the fake server received.
+type Request struct {
+	Method string
+	// Path is without the "/api/v3" prefix that clients send for hosts other
+	// than github.com.
+	Path   string
+	Query  url.Values
+	Header http.Header
+	Body   []byte
+}
+
+// String is the method and the path of the request, e.g.
+// "GET /repos/mislav/dotfiles".
+func (r Request) String() string {
+	return r.Method + " " + r.Path
+}
+
+// Unmarshal decodes the JSON body of the request into v.
+func (r Request) Unmarshal(v interface{}) error {
+	return json.Unmarshal(r.Body, v)
+}
+
+// Server is a fake GitHub API server.
+type Server struct {
+	// URL is the root of the API, e.g. "http://127.0.0.1:1234/api/v3".
+	URL string
+
+	server   *httptest.Server
+	mutex    sync.Mutex
+	fixtures []*Fixture
+	requests []Request
+}
+
+// NewServer starts a fake server, which has to be stopped with Close.
+func NewServer() *Server {
+	s := &Server{}
+	s.server = httptest.NewServer(http.HandlerFunc(s.serveHTTP))
+	s.URL = s.server.URL + "/api/v3"
+	return s
+}
+
+// NewClient starts a fake server and returns a client that sends requests to
+// it, authenticated with AccessToken.
+func NewClient() (*github.Client, *Server) {
+	s := NewServer()
+	return s.Client(), s
+}
+
+// Client returns a client that sends requests to the server.
+func (s *Server) Client() *github.Client {
+	u, _ := url.Parse(s.server.URL)
+	return github.NewClientWithHost(&github.Host{
+		Host:        u.Host,
+		AccessToken: AccessToken,
+		Protocol:    "http",
+	})
+}
+
+// Close stops the server.
+func (s *Server) Close() {
+	s.server.Close()
+}
+
+// Handle registers fixture.
+func (s *Server) Handle(fixture Fixture) {
+	if fixture.Method == "" {
+		fixture.Method = "GET"
+	}
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.fixtures = append(s.fixtures, &fixture)
+}
+
+// HandleFunc answers requests for path with handler, for responses that a
+// fixture can't describe. The path of the request that handler gets is
+// without the "/api/v3" prefix.
+func (s *Server) HandleFunc(method, path string, handler http.HandlerFunc) {
+	s.Handle(Fixture{Method: method, Path: path, handler: handler})
+}
+
+// LoadFixtures registers the fixtures in a JSON file.
+func (s *Server) LoadFixtures(file string) error {
+	data, err := ioutil.ReadFile(file)
+	if err != nil {
+		return err
+	}
+	fixtures := []Fixture{}
+	if err := json.Unmarshal(data, &fixtures); err != nil {
+		return fmt.Errorf("%s: %s", file, err)
+	}
+	for _, fixture := range fixtures {
+		s.Handle(fixture)
+	}
+	return nil
+}
+
+// Requests lists the requests received so far.
+func (s *Server) Requests() []Request {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return append([]Request{}, s.requests...)
+}
+
+// AssertRequests fails the test unless the server received exactly the
+// requests in want, in order, given as "METHOD /path".
+func (s *Server) AssertRequests(t testing.TB, want ...string) {
+	t.Helper()
+	got := []string{}
+	for _, r := range s.Requests() {
+		got = append(got, r.String())
+	}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("expected requests:\n  %s\ngot:\n  %s", strings.Join(want, "\n  "), strings.Join(got, "\n  "))
+	}
+}
+
+// AssertRequested fails the test unless the server received a request for
+// method and path, and returns the last such request.
+func (s *Server) AssertRequested(t testing.TB, method, path string) Request {
+	t.Helper()
+	requests := s.Requests()
+	for i := len(requests) - 1; i >= 0; i-- {
+		if requests[i].Method == method && requests[i].Path == path {
+			return requests[i]
+		}
+	}
+	t.Errorf("expected a request for %s %s", method, path)
+	return Request{}
+}
+
+func (s *Server) serveHTTP(w http.ResponseWriter, r *http.Request) {
+	body, _ := ioutil.ReadAll(r.Body)
+	r.Body = ioutil.NopCloser(bytes.NewReader(body))
+	r.URL.Path = strings.TrimPrefix(r.URL.Path, "/api/v3")
+	request := Request{
+		Method: r.Method,
+		Path:   r.URL.Path,
+		Query:  r.URL.Query(),
+		Header: r.Header,
+		Body:   body,
+	}
+
+	s.mutex.Lock()
+	s.requests = append(s.requests, request)
+	fixture := s.match(request)
+	failing := fixture != nil && fixture.failures < fixture.FailTimes
+	if failing {
+		fixture.failures++
+	}
+	s.mutex.Unlock()
+
+	switch {
+	case fixture == nil:
+		writeJSON(w, http.StatusNotFound, map[string]string{"message": "Not Found"})
+	case failing:
+		writeJSON(w, http.StatusBadGateway, map[string]string{"message": "Server Error"})
+	case fixture.handler != nil:
+		fixture.handler(w, r)
+	default:
+		s.serveFixture(w, request, fixture)
+	}
+}
+
+// match finds the last registered fixture for request.
+func (s *Server) match(request Request) *Fixture {
+	for i := len(s.fixtures) - 1; i >= 0; i-- {
+		fixture := s.fixtures[i]
+		if fixture.Method != request.Method || fixture.Path != request.Path {
+			continue
+		}
+		matches := true
+		for key, value := range fixture.Query {
+			if request.Query.Get(key) != value {
+				matches = false
+			}
+		}
+		if matches {
+			return fixture
+		}
+	}
+	return nil
+}
+
+func (s *Server) serveFixture(w http.ResponseWriter, request Request, fixture *Fixture) {
+	for key, value := range fixture.Headers {
+		w.Header().Set(key, value)
+	}
+
+	switch fixture.Error {
+	case ErrorRateLimit:
+		w.Header().Set("X-RateLimit-Limit", "5000")
+		w.Header().Set("X-RateLimit-Remaining", "0")
+		w.Header().Set("X-RateLimit-Reset", strconv.FormatInt(time.Now().Add(time.Hour).Unix(), 10))
+		writeJSON(w, http.StatusForbidden, map[string]string{"message": "API rate limit exceeded"})
+		return
+	case ErrorSecondaryRateLimit:
+		w.Header().Set("Retry-After", "60")
+		writeJSON(w, http.StatusForbidden, map[string]string{"message": "You have exceeded a secondary rate limit. Please wait a few minutes before you try again."})
+		return
+	case ErrorSSO:
+		w.Header().Set("X-GitHub-SSO", "required; url=https://github.com/orgs/acme/sso?authorization_request=1")
+		writeJSON(w, http.StatusForbidden, map[string]string{"message": "Resource protected by organization SAML enforcement. You must grant your Personal Access token access to this organization."})
+		return
+	case "":
+	default:
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"message": "unknown fixture error: " + fixture.Error})
+		return
+	}
+
+	status := fixture.Status
+	if status == 0 {
+		status = http.StatusOK
+	}
+	if fixture.Items != nil {
+		writeJSON(w, status, s.page(w, request, fixture))
+	} else if fixture.Body != nil {
+		writeJSON(w, status, fixture.Body)
+	} else {
+		w.WriteHeader(status)
+	}
+}
+
+// page selects the page of the items of fixture that request asks for and
+// sets the "Link" header to the next and the last page.
+func (s *Server) page(w http.ResponseWriter, request Request, fixture *Fixture) interface{} {
+	perPage := fixture.PerPage
+	if perPage < 1 {
+		if perPage, _ = strconv.Atoi(request.Query.Get("per_page")); perPage < 1 {
+			perPage = 30
+		}
+	}
+	page, _ := strconv.Atoi(request.Query.Get("page"))
+	if page < 1 {
+		page = 1
+	}
+	lastPage := (len(fixture.Items) + perPage - 1) / perPage
+	if lastPage < 1 {
+		lastPage = 1
+	}
+
+	links := []string{}
+	pageURL := func(n int) string {
+		query := url.Values{}
+		for key, values := range request.Query {
+			query[key] = values
+		}
+		query.Set("page", strconv.Itoa(n))
+		return s.URL + request.Path + "?" + query.Encode()
+	}
+	if page < lastPage {
+		links = append(links, fmt.Sprintf(`<%s>; rel="next"`, pageURL(page+1)))
+		links = append(links, fmt.Sprintf(`<%s>; rel="last"`, pageURL(lastPage)))
+	}
+	if len(links) > 0 {
+		w.Header().Set("Link", strings.Join(links, ", "))
+	}
+
+	items := []json.RawMessage{}
+	if start := (page - 1) * perPage; start < len(fixture.Items) {
+		end := start + perPage
+		if end > len(fixture.Items) {
+			end = len(fixture.Items)
+		}
+		items = fixture.Items[start:end]
+	}
+	if fixture.ItemsKey == "" {
+		return items
+	}
+	return map[string]interface{}{
+		"total_count":    len(fixture.Items),
+		fixture.ItemsKey: items,
+	}
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
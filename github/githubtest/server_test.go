@@ -0,0 +1,117 @@
+package githubtest
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/bmizerany/assert"
+	"github.com/github/hub/github"
+)
+
+func get(t *testing.T, url string) (*http.Response, string) {
+	res, err := http.Get(url)
+	assert.Equal(t, nil, err)
+	body, _ := ioutil.ReadAll(res.Body)
+	res.Body.Close()
+	return res, strings.TrimSpace(string(body))
+}
+
+func TestServer_pagination(t *testing.T) {
+	s := NewServer()
+	defer s.Close()
+	s.Handle(Fixture{
+		Path:     "/search/issues",
+		ItemsKey: "items",
+		Items:    []json.RawMessage{json.RawMessage(`1`), json.RawMessage(`2`), json.RawMessage(`3`)},
+	})
+
+	res, body := get(t, s.URL+"/search/issues?q=bug&per_page=2")
+	assert.Equal(t, `{"items":[1,2],"total_count":3}`, body)
+	assert.Equal(t, fmt.Sprintf(`<%[1]s/search/issues?page=2&per_page=2&q=bug>; rel="next", <%[1]s/search/issues?page=2&per_page=2&q=bug>; rel="last"`, s.URL), res.Header.Get("Link"))
+
+	res, body = get(t, s.URL+"/search/issues?q=bug&per_page=2&page=2")
+	assert.Equal(t, `{"items":[3],"total_count":3}`, body)
+	assert.Equal(t, "", res.Header.Get("Link"))
+
+	_, body = get(t, s.URL+"/search/issues?page=5")
+	assert.Equal(t, `{"items":[],"total_count":3}`, body)
+}
+
+func TestServer_matching(t *testing.T) {
+	s := NewServer()
+	defer s.Close()
+	s.Handle(Fixture{Path: "/repos/acme/tools/pulls", Body: json.RawMessage(`"all"`)})
+	s.Handle(Fixture{Path: "/repos/acme/tools/pulls", Query: map[string]string{"state": "closed"}, Body: json.RawMessage(`"closed"`)})
+	s.HandleFunc("PATCH", "/repos/acme/tools", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusTeapot)
+	})
+
+	_, body := get(t, s.URL+"/repos/acme/tools/pulls?state=open")
+	assert.Equal(t, `"all"`, body)
+	_, body = get(t, s.URL+"/repos/acme/tools/pulls?state=closed")
+	assert.Equal(t, `"closed"`, body)
+
+	res, _ := get(t, s.URL+"/repos/acme/tools")
+	assert.Equal(t, http.StatusNotFound, res.StatusCode)
+
+	req, _ := http.NewRequest("PATCH", s.URL+"/repos/acme/tools", strings.NewReader(`{"archived":true}`))
+	res, err := http.DefaultClient.Do(req)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, http.StatusTeapot, res.StatusCode)
+
+	s.AssertRequests(t,
+		"GET /repos/acme/tools/pulls",
+		"GET /repos/acme/tools/pulls",
+		"GET /repos/acme/tools",
+		"PATCH /repos/acme/tools",
+	)
+	patch := map[string]bool{}
+	assert.Equal(t, nil, s.AssertRequested(t, "PATCH", "/repos/acme/tools").Unmarshal(&patch))
+	assert.Equal(t, map[string]bool{"archived": true}, patch)
+}
+
+func TestServer_errors(t *testing.T) {
+	s := NewServer()
+	defer s.Close()
+	s.Handle(Fixture{Path: "/rate_limited", Error: ErrorRateLimit})
+	s.Handle(Fixture{Path: "/flaky", FailTimes: 2, Body: json.RawMessage(`{}`)})
+
+	res, _ := get(t, s.URL+"/rate_limited")
+	assert.Equal(t, http.StatusForbidden, res.StatusCode)
+	assert.Equal(t, "0", res.Header.Get("X-RateLimit-Remaining"))
+
+	statuses := []int{}
+	for i := 0; i < 3; i++ {
+		res, _ := get(t, s.URL+"/flaky")
+		statuses = append(statuses, res.StatusCode)
+	}
+	assert.Equal(t, []int{502, 502, 200}, statuses)
+}
+
+func TestServer_LoadFixtures(t *testing.T) {
+	file, _ := ioutil.TempFile("", "fixtures")
+	file.WriteString(`[
+		{"path": "/repos/mislav/dotfiles", "body": {"name": "dotfiles", "archived": true}},
+		{"method": "DELETE", "path": "/repos/mislav/dotfiles", "status": 204}
+	]`)
+	file.Close()
+	defer os.Remove(file.Name())
+
+	client, s := NewClient()
+	defer s.Close()
+	assert.Equal(t, nil, s.LoadFixtures(file.Name()))
+
+	project := &github.Project{Owner: "mislav", Name: "dotfiles"}
+	repo, err := client.Repository(project)
+	assert.Equal(t, nil, err)
+	assert.T(t, repo.Archived)
+	assert.Equal(t, nil, client.DeleteRepository(project))
+
+	s.AssertRequests(t, "GET /repos/mislav/dotfiles", "DELETE /repos/mislav/dotfiles")
+	assert.Equal(t, "token "+AccessToken, s.Requests()[0].Header.Get("Authorization"))
+}
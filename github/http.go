@@ -8,6 +8,7 @@ import (
 	"fmt"
 	"io"
 	"io/ioutil"
+	"math/rand"
 	"net"
 	"net/http"
 	"net/url"
@@ -18,6 +19,7 @@ import (
 	"sort"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/github/hub/ui"
@@ -26,11 +28,40 @@ import (
 
 const apiPayloadVersion = "application/vnd.github.v3+json;charset=utf-8"
 const patchMediaType = "application/vnd.github.v3.patch;charset=utf-8"
+const diffMediaType = "application/vnd.github.v3.diff;charset=utf-8"
 const textMediaType = "text/plain;charset=utf-8"
 const checksType = "application/vnd.github.antiope-preview+json;charset=utf-8"
 const draftsType = "application/vnd.github.shadow-cat-preview+json;charset=utf-8"
+const commitPullsType = "application/vnd.github.groot-preview+json;charset=utf-8"
+const projectsType = "application/vnd.github.inertia-preview+json;charset=utf-8"
 const cacheVersion = 2
 
+// maxIdleConnsPerHost is how many keep-alive connections to the API host are
+// kept open for reuse. Batch operations that issue requests concurrently would
+// otherwise close most of their connections with the default of 2 and pay for
+// a new TLS handshake on the next request.
+const maxIdleConnsPerHost = 16
+
+// maxDrainBytes bounds how much of an unread response body gets read before
+// closing it. A connection can only be reused once its response was read to
+// the end, but downloading a large body just for that isn't worth it.
+const maxDrainBytes = 256 << 10
+
+// defaultRetries is how many times a request that's safe to repeat is sent
+// again when the network or the server fails.
+const defaultRetries = 3
+
+var (
+	// retryBaseDelay is the pause before the first retry of a request, which
+	// doubles with each further retry up to maxRetryDelay.
+	retryBaseDelay = time.Second
+	maxRetryDelay  = 30 * time.Second
+	retrySleep     = time.Sleep
+
+	// DisableRetries turns off retrying failed requests, as for '--noop'.
+	DisableRetries bool
+)
+
 var inspectHeaders = []string{
 	"Authorization",
 	"X-GitHub-OTP",
@@ -45,9 +76,27 @@ type verboseTransport struct {
 	OverrideURL *url.URL
 	Out         io.Writer
 	Colorized   bool
+	// ExtraHeaders are only sent to ExtraHeadersHost, and not along redirects
+	// to other hosts, such as the ones that serve release assets.
+	ExtraHeaders     http.Header
+	ExtraHeadersHost string
+	ConfigError      error
 }
 
 func (t *verboseTransport) RoundTrip(req *http.Request) (resp *http.Response, err error) {
+	if t.ConfigError != nil {
+		return nil, t.ConfigError
+	}
+
+	if len(t.ExtraHeaders) > 0 && strings.EqualFold(req.URL.Host, t.ExtraHeadersHost) {
+		req = cloneRequest(req)
+		for name, values := range t.ExtraHeaders {
+			for _, value := range values {
+				req.Header.Add(name, value)
+			}
+		}
+	}
+
 	if t.Verbose {
 		t.dumpRequest(req)
 	}
@@ -105,7 +154,7 @@ func (t *verboseTransport) dumpHeaders(header http.Header, indent string) {
 			}
 			for _, v := range vv {
 				if v != "" {
-					r := regexp.MustCompile("(?i)^(basic|token) (.+)")
+					r := regexp.MustCompile("(?i)^(basic|bearer|token) (.+)")
 					if r.MatchString(v) {
 						v = r.ReplaceAllString(v, "$1 [REDACTED]")
 					}
@@ -159,6 +208,8 @@ func newHttpClient(testHost string, verbose bool, unixSocket string) *http.Clien
 		httpTransport = &http.Transport{
 			DialContext:           dialContext,
 			DialTLS:               dialFunc,
+			MaxIdleConnsPerHost:   maxIdleConnsPerHost,
+			IdleConnTimeout:       90 * time.Second,
 			ResponseHeaderTimeout: 30 * time.Second,
 			ExpectContinueTimeout: 10 * time.Second,
 			TLSHandshakeTimeout:   10 * time.Second,
@@ -170,6 +221,8 @@ func newHttpClient(testHost string, verbose bool, unixSocket string) *http.Clien
 				Timeout:   30 * time.Second,
 				KeepAlive: 30 * time.Second,
 			}).DialContext,
+			MaxIdleConnsPerHost: maxIdleConnsPerHost,
+			IdleConnTimeout:     90 * time.Second,
 			TLSHandshakeTimeout: 10 * time.Second,
 		}
 	}
@@ -186,6 +239,38 @@ func newHttpClient(testHost string, verbose bool, unixSocket string) *http.Clien
 	}
 }
 
+type transportKey struct {
+	testHost   string
+	verbose    bool
+	unixSocket string
+	apiRoot    string
+}
+
+var (
+	sharedTransports      = map[transportKey]*verboseTransport{}
+	sharedTransportsMutex sync.Mutex
+)
+
+// sharedTransport returns the transport for API requests to apiRoot, creating
+// it on first use. All clients talking to the same host share it, and with it
+// the pool of keep-alive connections.
+func sharedTransport(testHost string, verbose bool, unixSocket string, apiRoot *url.URL) *verboseTransport {
+	key := transportKey{testHost, verbose, unixSocket, apiRoot.String()}
+
+	sharedTransportsMutex.Lock()
+	defer sharedTransportsMutex.Unlock()
+
+	if tr, ok := sharedTransports[key]; ok {
+		return tr
+	}
+	tr := newHttpClient(testHost, verbose, unixSocket).Transport.(*verboseTransport)
+	if unixSocket == "" {
+		tr.ConfigError = loadHttpConfig(readGitHttpConfig(), apiRoot).apply(tr)
+	}
+	sharedTransports[key] = tr
+	return tr
+}
+
 func cloneRequest(req *http.Request) *http.Request {
 	dup := new(http.Request)
 	*dup = *req
@@ -207,6 +292,10 @@ func proxyFromEnvironment(req *http.Request) (*url.URL, error) {
 		return nil, nil
 	}
 
+	return parseProxyURL(proxy)
+}
+
+func parseProxyURL(proxy string) (*url.URL, error) {
 	proxyURL, err := url.Parse(proxy)
 	if err != nil || !strings.HasPrefix(proxyURL.Scheme, "http") {
 		if proxyURL, err := url.Parse("http://" + proxy); err == nil {
@@ -224,8 +313,12 @@ func proxyFromEnvironment(req *http.Request) (*url.URL, error) {
 type simpleClient struct {
 	httpClient     *http.Client
 	rootUrl        *url.URL
+	host           string
 	PrepareRequest func(*http.Request)
+	OnResponse     func(*http.Response)
 	CacheTTL       int
+	conditional    *conditionalCache
+	rateLimitWait  func(time.Duration) error
 }
 
 func (c *simpleClient) performRequest(method, path string, body io.Reader, configure func(*http.Request)) (*simpleResponse, error) {
@@ -239,6 +332,12 @@ func (c *simpleClient) performRequest(method, path string, body io.Reader, confi
 }
 
 func (c *simpleClient) performRequestUrl(method string, url *url.URL, body io.Reader, configure func(*http.Request)) (res *simpleResponse, err error) {
+	return c.doRequest(method, c.rewriteMovedRepository(url), body, configure, true)
+}
+
+// doRequest performs the request. With followMoves, a request for a repository
+// that has moved is retried once at its new location.
+func (c *simpleClient) doRequest(method string, url *url.URL, body io.Reader, configure func(*http.Request), followMoves bool) (res *simpleResponse, err error) {
 	req, err := http.NewRequest(method, url.String(), body)
 	if err != nil {
 		return
@@ -259,10 +358,57 @@ func (c *simpleClient) performRequestUrl(method string, url *url.URL, body io.Re
 		return
 	}
 
-	httpResponse, err := c.httpClient.Do(req)
+	if c.conditional != nil {
+		c.conditional.prepare(key, req)
+	}
+
+	httpResponse, err := c.send(req)
 	if err != nil {
 		return
 	}
+	if c.OnResponse != nil {
+		c.OnResponse(httpResponse)
+	}
+
+	// pause for a rate limit and try again, as long as the body can be sent
+	// again. A limit that has already reset, or whose reset time is unknown,
+	// would only be hit again right away.
+	if c.rateLimitWait != nil && (req.Body == nil || req.GetBody != nil) {
+		limited := &simpleResponse{httpResponse}
+		if wait := limited.RateLimitWait(time.Now()); limited.RateLimited() && wait > 0 {
+			discardBody(httpResponse.Body)
+			if err = c.rateLimitWait(wait); err != nil {
+				return
+			}
+			if req.GetBody != nil {
+				if body, err = req.GetBody(); err != nil {
+					return
+				}
+			}
+			return c.doRequest(method, url, body, configure, followMoves)
+		}
+	}
+
+	if followMoves && (req.Body == nil || req.GetBody != nil) {
+		if location := c.repositoryMoveLocation(req, httpResponse); location != nil {
+			discardBody(httpResponse.Body)
+			if req.GetBody != nil {
+				if body, err = req.GetBody(); err != nil {
+					return
+				}
+			}
+			if res, err = c.doRequest(method, location, body, configure, false); err == nil {
+				c.recordRepositoryMove(req, location, res)
+			}
+			return
+		}
+	}
+
+	if c.conditional != nil {
+		if httpResponse, err = c.conditional.process(key, httpResponse); err != nil {
+			return
+		}
+	}
 
 	c.cacheWrite(key, httpResponse)
 	res = &simpleResponse{httpResponse}
@@ -270,6 +416,100 @@ func (c *simpleClient) performRequestUrl(method string, url *url.URL, body io.Re
 	return
 }
 
+// send performs req, and sends it again after a pause if the network or the
+// server fails in a way that may be temporary, as long as it's safe to repeat
+// req and its body can be sent again.
+func (c *simpleClient) send(req *http.Request) (*http.Response, error) {
+	retries := requestRetries(req.Method)
+	if req.Body != nil && req.GetBody == nil {
+		retries = 0
+	}
+
+	for attempt := 0; ; attempt++ {
+		res, err := c.httpClient.Do(req)
+		if attempt >= retries || !isTransientFailure(res, err) {
+			return res, err
+		}
+		delay, ok := retryDelay(attempt, res)
+		if !ok {
+			return res, err
+		}
+
+		reason := ""
+		if err != nil {
+			reason = err.Error()
+		} else {
+			reason = fmt.Sprintf("HTTP %d", res.StatusCode)
+			discardBody(res.Body)
+		}
+		if tr, ok := c.httpClient.Transport.(*verboseTransport); ok && tr.Verbose {
+			tr.verbosePrintln(fmt.Sprintf("* %s; retrying %s %s in %s (%d of %d)", reason, req.Method, req.URL, delay.Round(time.Millisecond), attempt+1, retries))
+		}
+		retrySleep(delay)
+
+		if req.GetBody != nil {
+			if req.Body, err = req.GetBody(); err != nil {
+				return nil, err
+			}
+		}
+	}
+}
+
+// requestRetries is how many times a failed request with method is retried.
+// Requests that might not be idempotent aren't, unless HUB_RETRIES sets the
+// number of retries for all requests.
+func requestRetries(method string) int {
+	if DisableRetries {
+		return 0
+	}
+	if retries, err := strconv.Atoi(os.Getenv("HUB_RETRIES")); err == nil && retries >= 0 {
+		return retries
+	}
+	switch method {
+	case "GET", "HEAD", "OPTIONS", "PUT", "DELETE":
+		return defaultRetries
+	default:
+		return 0
+	}
+}
+
+// isTransientFailure reports whether a request that got res or failed with
+// err may succeed when it's sent again: after a server error, a timeout, or a
+// network failure such as a reset connection.
+func isTransientFailure(res *http.Response, err error) bool {
+	if err == nil {
+		return res.StatusCode >= 500 || res.StatusCode == http.StatusRequestTimeout
+	}
+	if urlErr, ok := err.(*url.Error); ok {
+		err = urlErr.Err
+	}
+	if _, ok := err.(net.Error); ok {
+		return true
+	}
+	return err == io.EOF || err == io.ErrUnexpectedEOF
+}
+
+// retryDelay is the jittered pause before the retry that follows attempt,
+// which is 0 for the first one. A "Retry-After" header in res takes precedence,
+// but a request isn't retried at all if it asks for longer than maxRetryDelay.
+func retryDelay(attempt int, res *http.Response) (time.Duration, bool) {
+	if res != nil {
+		if seconds, err := strconv.Atoi(res.Header.Get("Retry-After")); err == nil && seconds >= 0 {
+			delay := time.Duration(seconds) * time.Second
+			return delay, delay <= maxRetryDelay
+		}
+	}
+
+	delay := retryBaseDelay << uint(attempt)
+	if delay > maxRetryDelay || delay <= 0 {
+		delay = maxRetryDelay
+	}
+	// anywhere between half of the delay and all of it, so that clients that
+	// failed at the same time don't all retry at the same time
+	half := delay / 2
+	return half + time.Duration(rand.Int63n(int64(delay-half)+1)), true
+}
+
 func isGraphQL(req *http.Request) bool {
 	return req.URL.Path == "/graphql"
 }
@@ -328,8 +568,32 @@ func (c *simpleClient) cacheRead(key string, req *http.Request) (res *http.Respo
 	return
 }
 
+// cachedGet returns the cached response to a GET request for path without
+// sending the request, or nil if there's no such response younger than
+// CacheTTL.
+func (c *simpleClient) cachedGet(path string) *simpleResponse {
+	u, err := url.Parse(path)
+	if err != nil {
+		return nil
+	}
+	req, err := http.NewRequest("GET", c.rootUrl.ResolveReference(u).String(), nil)
+	if err != nil {
+		return nil
+	}
+	if c.PrepareRequest != nil {
+		c.PrepareRequest(req)
+	}
+	req.Header.Set("User-Agent", UserAgent)
+	req.Header.Set("Accept", apiPayloadVersion)
+
+	if res := c.cacheRead(cacheKey(req), req); res != nil {
+		return &simpleResponse{res}
+	}
+	return nil
+}
+
 func (c *simpleClient) cacheWrite(key string, res *http.Response) {
-	if c.CacheTTL > 0 && canCache(res.Request) && res.StatusCode < 500 && res.StatusCode != 403 {
+	if c.CacheTTL > 0 && canCache(res.Request) && res.StatusCode < 500 && res.StatusCode != 403 && res.StatusCode != 429 {
 		bodyCopy := &bytes.Buffer{}
 		bodyReplacement := readCloserCallback{
 			Reader: io.TeeReader(res.Body, bodyCopy),
@@ -355,6 +619,99 @@ func (c *simpleClient) cacheWrite(key string, res *http.Response) {
 	}
 }
 
+// conditionalCache keeps the ETag and body of GET responses in memory so that
+// repeating the same request can be done with "If-None-Match". Responses with
+// "304 Not Modified" status don't count against the API rate limit. With a
+// dir, the entries are also kept on disk for other hub processes.
+type conditionalCache struct {
+	entries map[string]*conditionalEntry
+	mu      sync.Mutex
+
+	dir     string
+	maxSize int64
+}
+
+type conditionalEntry struct {
+	etag   string
+	header http.Header
+	body   []byte
+}
+
+func newConditionalCache() *conditionalCache {
+	return &conditionalCache{
+		entries: map[string]*conditionalEntry{},
+	}
+}
+
+func (cc *conditionalCache) prepare(key string, req *http.Request) {
+	if !strings.EqualFold(req.Method, "GET") {
+		return
+	}
+	cc.mu.Lock()
+	defer cc.mu.Unlock()
+	entry, ok := cc.entries[key]
+	if !ok && cc.dir != "" {
+		if entry = cc.load(key); entry != nil {
+			cc.entries[key] = entry
+			ok = true
+		}
+	}
+	if ok {
+		req.Header.Set("If-None-Match", entry.etag)
+	}
+}
+
+func (cc *conditionalCache) process(key string, res *http.Response) (*http.Response, error) {
+	if !strings.EqualFold(res.Request.Method, "GET") {
+		return res, nil
+	}
+
+	if res.StatusCode == http.StatusNotModified {
+		cc.mu.Lock()
+		entry, ok := cc.entries[key]
+		cc.mu.Unlock()
+		if ok {
+			if cc.dir != "" {
+				cc.touch(key)
+			}
+			discardBody(res.Body)
+			res.StatusCode = http.StatusOK
+			res.Status = "200 OK"
+			res.Header = entry.header
+			res.Body = ioutil.NopCloser(bytes.NewReader(entry.body))
+		}
+		return res, nil
+	}
+
+	etag := res.Header.Get("ETag")
+	if res.StatusCode != http.StatusOK || etag == "" || strings.Contains(strings.ToLower(res.Header.Get("Cache-Control")), "no-store") {
+		return res, nil
+	}
+	if cc.dir != "" && res.ContentLength > cc.maxSize {
+		// such as a download of a release asset
+		return res, nil
+	}
+
+	body, err := ioutil.ReadAll(res.Body)
+	res.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	entry := &conditionalEntry{
+		etag:   etag,
+		header: res.Header,
+		body:   body,
+	}
+	cc.mu.Lock()
+	cc.entries[key] = entry
+	if cc.dir != "" {
+		cc.store(key, entry)
+	}
+	cc.mu.Unlock()
+	res.Body = ioutil.NopCloser(bytes.NewReader(body))
+	return res, nil
+}
+
 type readCloserCallback struct {
 	Callback func()
 	Closer   io.Closer
@@ -443,7 +800,13 @@ func (c *simpleClient) PatchJSON(path string, payload interface{}) (*simpleRespo
 	return c.jsonRequest("PATCH", path, payload, nil)
 }
 
-func (c *simpleClient) PostFile(path, filename string) (*simpleResponse, error) {
+func (c *simpleClient) PutJSON(path string, payload interface{}) (*simpleResponse, error) {
+	return c.jsonRequest("PUT", path, payload, nil)
+}
+
+// PostFile sends the contents of filename as the request body. The progress
+// callback, if given, is told how many bytes were read so far.
+func (c *simpleClient) PostFile(path, filename string, progress func(sent, total int64)) (*simpleResponse, error) {
 	stat, err := os.Stat(filename)
 	if err != nil {
 		return nil, err
@@ -455,16 +818,49 @@ func (c *simpleClient) PostFile(path, filename string) (*simpleResponse, error)
 	}
 	defer file.Close()
 
-	return c.performRequest("POST", path, file, func(req *http.Request) {
+	var body io.Reader = file
+	if progress != nil {
+		body = &progressReader{Reader: file, total: stat.Size(), progress: progress}
+	}
+
+	return c.performRequest("POST", path, body, func(req *http.Request) {
 		req.ContentLength = stat.Size()
 		req.Header.Set("Content-Type", "application/octet-stream")
 	})
 }
 
+// progressReader reports how much of a request body was read.
+type progressReader struct {
+	io.Reader
+	sent     int64
+	total    int64
+	progress func(sent, total int64)
+}
+
+func (r *progressReader) Read(p []byte) (n int, err error) {
+	n, err = r.Reader.Read(p)
+	if n > 0 {
+		r.sent += int64(n)
+		r.progress(r.sent, r.total)
+	}
+	return
+}
+
 type simpleResponse struct {
 	*http.Response
 }
 
+// discard closes the response body after reading what's left of it, which
+// lets the connection be reused for the next request.
+func (res *simpleResponse) discard() {
+	discardBody(res.Body)
+}
+
+func discardBody(body io.ReadCloser) {
+	io.CopyN(ioutil.Discard, body, maxDrainBytes)
+	body.Close()
+}
+
 type errorInfo struct {
 	Message  string       `json:"message"`
 	Errors   []fieldError `json:"errors"`
@@ -535,3 +931,38 @@ func (res *simpleResponse) Link(name string) string {
 	}
 	return ""
 }
+
+// RateLimited reports whether the request was rejected for exceeding either
+// the primary API rate limit or a secondary one.
+func (res *simpleResponse) RateLimited() bool {
+	if res.StatusCode != 403 && res.StatusCode != 429 {
+		return false
+	}
+	return res.Header.Get("X-RateLimit-Remaining") == "0" || res.Header.Get("Retry-After") != ""
+}
+
+// RateLimitWait returns how long to wait before the next request once the
+// response says that the rate limit is exhausted: for as long as "Retry-After"
+// asks in case of a secondary rate limit, or else until the primary rate limit
+// resets. It returns 0 if there's no need to wait.
+func (res *simpleResponse) RateLimitWait(now time.Time) time.Duration {
+	if retryAfter := res.Header.Get("Retry-After"); retryAfter != "" && (res.StatusCode == 403 || res.StatusCode == 429) {
+		if seconds, err := strconv.Atoi(retryAfter); err == nil && seconds > 0 {
+			return time.Duration(seconds) * time.Second
+		}
+	}
+
+	if res.Header.Get("X-RateLimit-Remaining") != "0" {
+		return 0
+	}
+	reset, err := strconv.ParseInt(res.Header.Get("X-RateLimit-Reset"), 10, 64)
+	if err != nil {
+		return 0
+	}
+	// the reset timestamp has a granularity of seconds, so allow for the
+	// second that it falls within to pass
+	if wait := time.Unix(reset+1, 0).Sub(now); wait > 0 {
+		return wait
+	}
+	return 0
+}
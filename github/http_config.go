@@ -0,0 +1,296 @@
+package github
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/github/hub/git"
+)
+
+// gitConfigEntry is a single "http.*" key from git config, such as
+// "http.https://github.example.com/.proxy".
+type gitConfigEntry struct {
+	key   string
+	value string
+}
+
+// httpConfig is the subset of git's "http.*" settings that applies to API
+// requests made to a particular URL.
+type httpConfig struct {
+	// host is the one that extraHeaders are sent to
+	host         string
+	proxy        string
+	sslCAInfo    string
+	sslCAPath    string
+	sslVerify    bool
+	extraHeaders []string
+}
+
+// urlMatch records how specifically the URL of a "http.<url>.*" config key
+// matched the URL of a request. Matches are ranked the way git ranks them:
+// by the length of the matched host, then of the matched path, and finally by
+// whether a user name matched.
+type urlMatch struct {
+	hostLen int
+	pathLen int
+	user    bool
+}
+
+func (m urlMatch) compare(other urlMatch) int {
+	if m.hostLen != other.hostLen {
+		return m.hostLen - other.hostLen
+	} else if m.pathLen != other.pathLen {
+		return m.pathLen - other.pathLen
+	} else if m.user != other.user {
+		if m.user {
+			return 1
+		}
+		return -1
+	}
+	return 0
+}
+
+var (
+	cachedGitHttpConfig []gitConfigEntry
+	gitHttpConfigMutex  sync.Mutex
+)
+
+func readGitHttpConfig() []gitConfigEntry {
+	// API clients can be set up concurrently, e.g. by 'ci-status --batch'
+	gitHttpConfigMutex.Lock()
+	defer gitHttpConfigMutex.Unlock()
+
+	if cachedGitHttpConfig != nil {
+		return cachedGitHttpConfig
+	}
+
+	entries := []gitConfigEntry{}
+	if lines, err := git.ConfigAll(`^http\..*`); err == nil {
+		for _, line := range lines {
+			parts := strings.SplitN(line, " ", 2)
+			entry := gitConfigEntry{key: parts[0]}
+			if len(parts) > 1 {
+				entry.value = parts[1]
+			}
+			entries = append(entries, entry)
+		}
+	}
+
+	cachedGitHttpConfig = entries
+	return entries
+}
+
+// loadHttpConfig resolves the git config settings that apply to requests to
+// target. Settings scoped to the most specific matching URL win over less
+// specific ones and over the global "http.*" settings, while environment
+// variables that git also respects take precedence over config.
+func loadHttpConfig(entries []gitConfigEntry, target *url.URL) *httpConfig {
+	config := &httpConfig{host: target.Host, sslVerify: true}
+	best := map[string]urlMatch{}
+
+	for _, entry := range entries {
+		name := strings.TrimPrefix(entry.key, "http.")
+		match := urlMatch{}
+		if i := strings.LastIndex(name, "."); i >= 0 {
+			var ok bool
+			match, ok = matchConfigURL(name[:i], target)
+			if !ok {
+				continue
+			}
+			name = name[i+1:]
+		}
+		name = strings.ToLower(name)
+
+		if previous, seen := best[name]; seen && match.compare(previous) < 0 {
+			continue
+		}
+		best[name] = match
+
+		switch name {
+		case "proxy":
+			config.proxy = entry.value
+		case "sslcainfo":
+			config.sslCAInfo = entry.value
+		case "sslcapath":
+			config.sslCAPath = entry.value
+		case "sslverify":
+			config.sslVerify = parseGitBool(entry.value)
+		case "extraheader":
+			if entry.value == "" {
+				// an empty value resets the list of headers configured so far
+				config.extraHeaders = nil
+			} else {
+				config.extraHeaders = append(config.extraHeaders, entry.value)
+			}
+		}
+	}
+
+	if value := os.Getenv("GIT_SSL_CAINFO"); value != "" {
+		config.sslCAInfo = value
+	}
+	if value := os.Getenv("GIT_SSL_CAPATH"); value != "" {
+		config.sslCAPath = value
+	}
+	if os.Getenv("GIT_SSL_NO_VERIFY") != "" {
+		config.sslVerify = false
+	}
+
+	return config
+}
+
+// matchConfigURL reports whether the URL of a "http.<url>.*" config key applies
+// to target. The scheme, host and port must be the same, except that a "*" in
+// the config URL matches a single part of the host name. The path of the
+// config URL must be a prefix of the target path that ends on a "/" boundary.
+func matchConfigURL(configURL string, target *url.URL) (match urlMatch, ok bool) {
+	u, err := url.Parse(configURL)
+	if err != nil || u.Host == "" {
+		return
+	}
+
+	if !strings.EqualFold(u.Scheme, target.Scheme) {
+		return
+	}
+	if urlPort(u) != urlPort(target) {
+		return
+	}
+
+	patternLabels := strings.Split(strings.ToLower(u.Hostname()), ".")
+	hostLabels := strings.Split(strings.ToLower(target.Hostname()), ".")
+	if len(patternLabels) != len(hostLabels) {
+		return
+	}
+	for i, label := range patternLabels {
+		if matched, _ := path.Match(label, hostLabels[i]); !matched {
+			return
+		}
+	}
+
+	userMatched := false
+	if u.User != nil {
+		if target.User == nil || u.User.Username() != target.User.Username() {
+			return
+		}
+		userMatched = true
+	}
+
+	configPath := strings.TrimSuffix(u.Path, "/")
+	targetPath := target.Path
+	if configPath != "" {
+		if !strings.HasPrefix(targetPath, configPath) {
+			return
+		}
+		if rest := targetPath[len(configPath):]; rest != "" && !strings.HasPrefix(rest, "/") {
+			return
+		}
+	}
+
+	return urlMatch{
+		hostLen: len(u.Hostname()),
+		pathLen: len(configPath),
+		user:    userMatched,
+	}, true
+}
+
+func urlPort(u *url.URL) string {
+	if port := u.Port(); port != "" {
+		return port
+	}
+	switch strings.ToLower(u.Scheme) {
+	case "http":
+		return "80"
+	case "https":
+		return "443"
+	}
+	return ""
+}
+
+func parseGitBool(value string) bool {
+	switch strings.ToLower(value) {
+	case "false", "no", "off", "0":
+		return false
+	}
+	return true
+}
+
+// apply configures the transport used for API requests with the settings.
+func (c *httpConfig) apply(tr *verboseTransport) error {
+	if c.proxy != "" {
+		proxyURL, err := parseProxyURL(c.proxy)
+		if err != nil {
+			return err
+		}
+		tr.Transport.Proxy = func(req *http.Request) (*url.URL, error) {
+			if envProxy, err := proxyFromEnvironment(req); envProxy != nil || err != nil {
+				return envProxy, err
+			}
+			return proxyURL, nil
+		}
+	}
+
+	if c.sslCAInfo != "" || c.sslCAPath != "" || !c.sslVerify {
+		tlsConfig := &tls.Config{InsecureSkipVerify: !c.sslVerify}
+		if c.sslCAInfo != "" || c.sslCAPath != "" {
+			pool, err := c.certPool()
+			if err != nil {
+				return err
+			}
+			tlsConfig.RootCAs = pool
+		}
+		tr.Transport.TLSClientConfig = tlsConfig
+	}
+
+	if len(c.extraHeaders) > 0 {
+		tr.ExtraHeaders = http.Header{}
+		tr.ExtraHeadersHost = c.host
+		for _, header := range c.extraHeaders {
+			parts := strings.SplitN(header, ":", 2)
+			if len(parts) != 2 || strings.TrimSpace(parts[0]) == "" {
+				return fmt.Errorf("invalid http.extraHeader: %q", header)
+			}
+			tr.ExtraHeaders.Add(strings.TrimSpace(parts[0]), strings.TrimSpace(parts[1]))
+		}
+	}
+
+	return nil
+}
+
+func (c *httpConfig) certPool() (*x509.CertPool, error) {
+	pool := x509.NewCertPool()
+
+	if c.sslCAInfo != "" {
+		pem, err := ioutil.ReadFile(c.sslCAInfo)
+		if err != nil {
+			return nil, fmt.Errorf("error reading http.sslCAInfo: %s", err)
+		}
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("error reading http.sslCAInfo: no certificates found in %s", c.sslCAInfo)
+		}
+	}
+
+	if c.sslCAPath != "" {
+		files, err := ioutil.ReadDir(c.sslCAPath)
+		if err != nil {
+			return nil, fmt.Errorf("error reading http.sslCAPath: %s", err)
+		}
+		for _, file := range files {
+			if file.IsDir() {
+				continue
+			}
+			if pem, err := ioutil.ReadFile(filepath.Join(c.sslCAPath, file.Name())); err == nil {
+				pool.AppendCertsFromPEM(pem)
+			}
+		}
+	}
+
+	return pool, nil
+}
@@ -0,0 +1,185 @@
+package github
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"testing"
+
+	"github.com/bmizerany/assert"
+)
+
+// clearEnv unsets environment variables for the duration of a test.
+func clearEnv(names ...string) func() {
+	saved := map[string]string{}
+	for _, name := range names {
+		if value, ok := os.LookupEnv(name); ok {
+			saved[name] = value
+		}
+		os.Unsetenv(name)
+	}
+	return func() {
+		for _, name := range names {
+			os.Unsetenv(name)
+		}
+		for name, value := range saved {
+			os.Setenv(name, value)
+		}
+	}
+}
+
+func TestMatchConfigURL(t *testing.T) {
+	target, _ := url.Parse("https://github.example.com/api/v3/")
+
+	tests := []struct {
+		configURL string
+		ok        bool
+		match     urlMatch
+	}{
+		{"https://github.example.com", true, urlMatch{hostLen: 18}},
+		{"https://github.example.com/", true, urlMatch{hostLen: 18}},
+		{"https://GitHub.Example.com:443", true, urlMatch{hostLen: 18}},
+		{"https://github.example.com/api", true, urlMatch{hostLen: 18, pathLen: 4}},
+		{"https://github.example.com/api/v3", true, urlMatch{hostLen: 18, pathLen: 7}},
+		{"https://github.example.com/api/", true, urlMatch{hostLen: 18, pathLen: 4}},
+		{"https://*.example.com", true, urlMatch{hostLen: 13}},
+		{"https://github.example.com/ap", false, urlMatch{}},
+		{"https://github.example.com/api/v3/graphql", false, urlMatch{}},
+		{"http://github.example.com", false, urlMatch{}},
+		{"https://github.example.com:8443", false, urlMatch{}},
+		{"https://example.com", false, urlMatch{}},
+		{"https://*.com", false, urlMatch{}},
+		{"https://mislav@github.example.com", false, urlMatch{}},
+		{"github.example.com", false, urlMatch{}},
+	}
+
+	for _, test := range tests {
+		match, ok := matchConfigURL(test.configURL, target)
+		if ok != test.ok || match != test.match {
+			t.Errorf("matchConfigURL(%q) = %v, %t; want %v, %t", test.configURL, match, ok, test.match, test.ok)
+		}
+	}
+}
+
+func TestLoadHttpConfig_Precedence(t *testing.T) {
+	defer clearEnv("GIT_SSL_CAINFO", "GIT_SSL_CAPATH", "GIT_SSL_NO_VERIFY")()
+	target, _ := url.Parse("https://github.example.com/api/v3/")
+
+	entries := []gitConfigEntry{
+		{"http.https://github.example.com/api.proxy", "api-proxy:8080"},
+		{"http.https://github.example.com.proxy", "host-proxy:8080"},
+		{"http.proxy", "global-proxy:8080"},
+		{"http.https://other.example.com.proxy", "other-proxy:8080"},
+		{"http.sslverify", "false"},
+		{"http.https://*.example.com.sslVerify", "true"},
+		{"http.sslCAInfo", "/etc/ssl/global.pem"},
+	}
+	config := loadHttpConfig(entries, target)
+
+	assert.Equal(t, "api-proxy:8080", config.proxy)
+	assert.Equal(t, true, config.sslVerify)
+	assert.Equal(t, "/etc/ssl/global.pem", config.sslCAInfo)
+
+	otherTarget, _ := url.Parse("https://api.github.com/")
+	config = loadHttpConfig(entries, otherTarget)
+	assert.Equal(t, "global-proxy:8080", config.proxy)
+	assert.Equal(t, false, config.sslVerify)
+}
+
+func TestLoadHttpConfig_ExtraHeaders(t *testing.T) {
+	defer clearEnv("GIT_SSL_CAINFO", "GIT_SSL_CAPATH", "GIT_SSL_NO_VERIFY")()
+	target, _ := url.Parse("https://github.example.com/api/v3/")
+
+	config := loadHttpConfig([]gitConfigEntry{
+		{"http.extraheader", "X-Global: 1"},
+		{"http.https://github.example.com.extraheader", "X-Host: 1"},
+		{"http.https://github.example.com.extraheader", "X-Host: 2"},
+		{"http.https://other.example.com.extraheader", "X-Other: 1"},
+	}, target)
+	assert.Equal(t, []string{"X-Global: 1", "X-Host: 1", "X-Host: 2"}, config.extraHeaders)
+
+	config = loadHttpConfig([]gitConfigEntry{
+		{"http.extraheader", "X-Global: 1"},
+		{"http.https://github.example.com.extraheader", ""},
+		{"http.https://github.example.com.extraheader", "Authorization: Bearer SSO"},
+	}, target)
+	assert.Equal(t, []string{"Authorization: Bearer SSO"}, config.extraHeaders)
+}
+
+func TestLoadHttpConfig_Environment(t *testing.T) {
+	defer clearEnv("GIT_SSL_CAINFO", "GIT_SSL_CAPATH", "GIT_SSL_NO_VERIFY")()
+	target, _ := url.Parse("https://github.example.com/api/v3/")
+
+	os.Setenv("GIT_SSL_NO_VERIFY", "1")
+	os.Setenv("GIT_SSL_CAINFO", "/tmp/env.pem")
+
+	config := loadHttpConfig([]gitConfigEntry{
+		{"http.https://github.example.com.sslverify", "true"},
+		{"http.https://github.example.com.sslcainfo", "/tmp/config.pem"},
+	}, target)
+	assert.Equal(t, false, config.sslVerify)
+	assert.Equal(t, "/tmp/env.pem", config.sslCAInfo)
+}
+
+func TestHttpConfig_Apply(t *testing.T) {
+	defer clearEnv("http_proxy", "HTTP_PROXY")()
+	s := setupTestServer("")
+	defer s.Close()
+
+	s.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		// requests through a proxy carry the absolute URL of the destination
+		assert.Equal(t, "http://github.example.com/api/v3/user", r.URL.String())
+		assert.Equal(t, "SSO", r.Header.Get("X-Corp-Auth"))
+		assert.Equal(t, []string{"a", "b"}, r.Header["X-Multi"])
+		w.Write([]byte("proxied"))
+	})
+
+	c := newHttpClient("", false, "")
+	tr := c.Transport.(*verboseTransport)
+	config := &httpConfig{
+		host:         "github.example.com",
+		proxy:        s.URL.Host,
+		sslVerify:    true,
+		extraHeaders: []string{"X-Corp-Auth: SSO", "X-Multi: a", "X-Multi: b"},
+	}
+	assert.Equal(t, nil, config.apply(tr))
+
+	res, err := c.Get("http://github.example.com/api/v3/user")
+	assert.Equal(t, nil, err)
+	assert.Equal(t, 200, res.StatusCode)
+}
+
+func TestHttpConfig_ExtraHeadersOnlyToHost(t *testing.T) {
+	assets := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "", r.Header.Get("X-Corp-Auth"))
+		w.Write([]byte("asset"))
+	}))
+	defer assets.Close()
+	api := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "SSO", r.Header.Get("X-Corp-Auth"))
+		http.Redirect(w, r, assets.URL+"/hub.tgz", http.StatusFound)
+	}))
+	defer api.Close()
+
+	c := newHttpClient("", false, "")
+	apiURL, _ := url.Parse(api.URL)
+	config := loadHttpConfig([]gitConfigEntry{{"http.extraheader", "X-Corp-Auth: SSO"}}, apiURL)
+	assert.Equal(t, nil, config.apply(c.Transport.(*verboseTransport)))
+
+	res, err := c.Get(api.URL + "/repos/github/hub/releases/assets/1")
+	assert.Equal(t, nil, err)
+	body, _ := ioutil.ReadAll(res.Body)
+	assert.Equal(t, "asset", string(body))
+}
+
+func TestHttpConfig_ApplyErrors(t *testing.T) {
+	tr := newHttpClient("", false, "").Transport.(*verboseTransport)
+
+	err := (&httpConfig{sslVerify: true, extraHeaders: []string{"no separator"}}).apply(tr)
+	assert.Equal(t, `invalid http.extraHeader: "no separator"`, err.Error())
+
+	err = (&httpConfig{sslVerify: true, sslCAInfo: "/nonexistent/ca.pem"}).apply(tr)
+	assert.Equal(t, "error reading http.sslCAInfo: open /nonexistent/ca.pem: no such file or directory", err.Error())
+}
@@ -10,7 +10,11 @@ import (
 	"net/http/httptest"
 	"net/url"
 	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
 	"testing"
+	"time"
 
 	"github.com/bmizerany/assert"
 )
@@ -46,6 +50,22 @@ func (s *testServer) Close() {
 	s.Server.Close()
 }
 
+// setupTestClient returns a client for a test server that answers API
+// requests with handler, which gets paths without the "/api/v3" prefix.
+func setupTestClient(handler http.HandlerFunc) (*Client, func()) {
+	s := setupTestServer("")
+	s.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		r.URL.Path = strings.TrimPrefix(r.URL.Path, "/api/v3")
+		handler(w, r)
+	})
+	client := NewClientWithHost(&Host{
+		Host:        s.URL.Host,
+		AccessToken: "OTOKEN",
+		Protocol:    "http",
+	})
+	return client, s.Close
+}
+
 func TestNewHttpClient_OverrideURL(t *testing.T) {
 	s := setupTestServer("")
 	defer s.Close()
@@ -92,3 +112,366 @@ func TestVerboseTransport_VerbosePrintln(t *testing.T) {
 	tr.verbosePrintln("foo")
 	assert.Equal(t, "\033[36mfoo\033[0m\n", b.String())
 }
+
+func TestSimpleClient_ConditionalRequests(t *testing.T) {
+	s := setupTestServer("")
+	defer s.Close()
+
+	notModified := 0
+	s.HandleFunc("/pulls", func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("If-None-Match") == `"abc"` {
+			notModified++
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		w.Header().Set("ETag", `"abc"`)
+		w.Write([]byte("[]"))
+	})
+
+	c := &simpleClient{
+		httpClient:  newHttpClient("", false, ""),
+		rootUrl:     s.URL,
+		conditional: newConditionalCache(),
+	}
+
+	for i := 0; i < 2; i++ {
+		res, err := c.Get("pulls")
+		assert.Equal(t, nil, err)
+		assert.Equal(t, 200, res.StatusCode)
+		body, _ := ioutil.ReadAll(res.Body)
+		assert.Equal(t, "[]", string(body))
+	}
+	assert.Equal(t, 1, notModified)
+}
+
+func TestSimpleClient_PersistentConditionalRequests(t *testing.T) {
+	s := setupTestServer("")
+	defer s.Close()
+
+	dir, err := ioutil.TempDir("", "hub-responses")
+	assert.Equal(t, nil, err)
+	defer os.RemoveAll(dir)
+
+	requests := map[string]int{}
+	notModified := 0
+	s.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		requests[r.URL.Path]++
+		if r.Header.Get("If-None-Match") == `"abc"` {
+			notModified++
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		w.Header().Set("ETag", `"abc"`)
+		w.Header().Set("X-Custom", r.URL.Path)
+		if r.URL.Path == "/secret" {
+			w.Header().Set("Cache-Control", "private, no-store")
+		}
+		w.Write([]byte(strings.Repeat("x", 40)))
+	})
+
+	var maxSize int64 = 1 << 20
+	get := func(path string) {
+		// a new client for each request, as if from separate hub processes
+		c := &simpleClient{
+			httpClient:  newHttpClient("", false, ""),
+			rootUrl:     s.URL,
+			conditional: newPersistentConditionalCache(dir, maxSize),
+		}
+		res, err := c.Get(path)
+		assert.Equal(t, nil, err)
+		assert.Equal(t, 200, res.StatusCode)
+		assert.Equal(t, "/"+path, res.Header.Get("X-Custom"))
+		body, _ := ioutil.ReadAll(res.Body)
+		assert.Equal(t, strings.Repeat("x", 40), string(body))
+	}
+
+	get("pulls")
+	get("pulls")
+	assert.Equal(t, 1, notModified)
+
+	// make room for two entries, but not three
+	filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err == nil && info.Mode().IsRegular() {
+			maxSize = info.Size()*5/2 + 1
+		}
+		return nil
+	})
+
+	get("secret")
+	get("secret")
+	assert.Equal(t, 1, notModified)
+
+	time.Sleep(10 * time.Millisecond)
+	get("issues")
+	time.Sleep(10 * time.Millisecond)
+	get("pulls")
+	time.Sleep(10 * time.Millisecond)
+	get("releases")
+	assert.Equal(t, 2, notModified)
+
+	get("pulls")
+	get("issues")
+	assert.Equal(t, 3, notModified)
+	assert.Equal(t, 2, requests["/issues"])
+}
+
+// setupConnCountingServer starts an API server that counts the connections
+// made to it.
+func setupConnCountingServer(handler http.HandlerFunc) (*httptest.Server, *int32) {
+	var conns int32
+	s := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		r.URL.Path = strings.TrimPrefix(r.URL.Path, "/api/v3")
+		handler(w, r)
+	}))
+	s.Config.ConnState = func(conn net.Conn, state http.ConnState) {
+		if state == http.StateNew {
+			atomic.AddInt32(&conns, 1)
+		}
+	}
+	s.Start()
+	return s, &conns
+}
+
+func connReuseTestClient(s *httptest.Server) *Client {
+	u, _ := url.Parse(s.URL)
+	return NewClientWithHost(&Host{
+		Host:        u.Host,
+		AccessToken: "OTOKEN",
+		Protocol:    "http",
+	})
+}
+
+func TestClient_ReusesConnections(t *testing.T) {
+	s, conns := setupConnCountingServer(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case strings.HasPrefix(r.URL.Path, "/repos/"):
+			w.Write([]byte(`{"sha": "` + strings.Repeat("a", 4096) + `"}`))
+		case strings.HasPrefix(r.URL.Path, "/releases/"):
+			w.WriteHeader(http.StatusNoContent)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`{"message": "Not Found"}`))
+		}
+	})
+	defer s.Close()
+
+	project := &Project{Owner: "mislav", Name: "dotfiles"}
+	release := &Release{ApiUrl: s.URL + "/api/v3/releases/1"}
+
+	// separate clients for the same host share their connections, too
+	for i := 0; i < 50; i++ {
+		client := connReuseTestClient(s)
+		switch i % 3 {
+		case 0:
+			exists, err := client.CommitExists(project, "master")
+			assert.Equal(t, nil, err)
+			assert.Equal(t, true, exists)
+		case 1:
+			assert.Equal(t, nil, client.DeleteRelease(release))
+		case 2:
+			isMember, err := client.IsOrgMember("acme", "octocat", "")
+			assert.Equal(t, nil, err)
+			assert.Equal(t, false, isMember)
+		}
+	}
+
+	assert.Equal(t, int32(1), atomic.LoadInt32(conns))
+}
+
+func TestClient_ReusesConnectionsConcurrently(t *testing.T) {
+	s, conns := setupConnCountingServer(func(w http.ResponseWriter, r *http.Request) {
+		login := strings.TrimPrefix(r.URL.Path, "/users/")
+		w.Write([]byte(`{"login": "` + login + `"}`))
+	})
+	defer s.Close()
+
+	logins := []string{}
+	for i := 0; i < 50; i++ {
+		logins = append(logins, fmt.Sprintf("user%d", i))
+	}
+
+	client := connReuseTestClient(s)
+	for round := 0; round < 2; round++ {
+		users, err := client.FetchUsers(logins)
+		assert.Equal(t, nil, err)
+		assert.Equal(t, 50, len(users))
+	}
+
+	if n := atomic.LoadInt32(conns); n > concurrentUserLookups {
+		t.Errorf("expected at most %d connections, got %d", concurrentUserLookups, n)
+	}
+}
+
+func BenchmarkSimpleClient_ConnectionReuse(b *testing.B) {
+	s := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"login": "mislav"}`))
+	}))
+	defer s.Close()
+	u, _ := url.Parse(s.URL)
+
+	newClient := func() *simpleClient {
+		tr := newHttpClient("", false, "").Transport.(*verboseTransport)
+		tr.Transport.TLSClientConfig = s.Client().Transport.(*http.Transport).TLSClientConfig
+		return &simpleClient{
+			httpClient: &http.Client{Transport: tr},
+			rootUrl:    u,
+		}
+	}
+	get := func(c *simpleClient) {
+		res, err := c.Get("user")
+		if err != nil {
+			b.Fatal(err)
+		}
+		res.discard()
+	}
+
+	b.Run("shared transport", func(b *testing.B) {
+		c := newClient()
+		for i := 0; i < b.N; i++ {
+			get(c)
+		}
+	})
+
+	b.Run("transport per request", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			get(newClient())
+		}
+	})
+}
+
+func TestSimpleResponse_RateLimitWait(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	response := func(status int, headers map[string]string) *simpleResponse {
+		res := &http.Response{StatusCode: status, Header: http.Header{}}
+		for name, value := range headers {
+			res.Header.Set(name, value)
+		}
+		return &simpleResponse{res}
+	}
+
+	res := response(200, map[string]string{"X-RateLimit-Remaining": "12", "X-RateLimit-Reset": "1700000100"})
+	assert.T(t, !res.RateLimited())
+	assert.Equal(t, time.Duration(0), res.RateLimitWait(now))
+
+	res = response(200, map[string]string{"X-RateLimit-Remaining": "0", "X-RateLimit-Reset": "1700000100"})
+	assert.T(t, !res.RateLimited())
+	assert.Equal(t, 101*time.Second, res.RateLimitWait(now))
+
+	res = response(403, map[string]string{"X-RateLimit-Remaining": "0", "X-RateLimit-Reset": "1699999000"})
+	assert.T(t, res.RateLimited())
+	assert.Equal(t, time.Duration(0), res.RateLimitWait(now))
+
+	res = response(403, map[string]string{"X-RateLimit-Remaining": "4000", "Retry-After": "60"})
+	assert.T(t, res.RateLimited())
+	assert.Equal(t, 60*time.Second, res.RateLimitWait(now))
+
+	res = response(403, map[string]string{})
+	assert.T(t, !res.RateLimited())
+}
+
+// fakeTransport answers each request with the next of its responses, where a
+// nil response stands for a reset connection.
+type fakeTransport struct {
+	responses []*http.Response
+	requests  []string
+}
+
+func (t *fakeTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	body := ""
+	if req.Body != nil {
+		data, _ := ioutil.ReadAll(req.Body)
+		body = string(data)
+	}
+	t.requests = append(t.requests, req.Method+" "+body)
+	res := t.responses[0]
+	t.responses = t.responses[1:]
+	if res == nil {
+		return nil, &net.OpError{Op: "read", Net: "tcp", Err: fmt.Errorf("connection reset by peer")}
+	}
+	res.Request = req
+	if res.Body == nil {
+		res.Body = ioutil.NopCloser(strings.NewReader("{}"))
+	}
+	return res, nil
+}
+
+func fakeResponse(status int, headers ...string) *http.Response {
+	res := &http.Response{StatusCode: status, Header: http.Header{}}
+	for i := 0; i+1 < len(headers); i += 2 {
+		res.Header.Set(headers[i], headers[i+1])
+	}
+	return res
+}
+
+func setupRetryTest(t *testing.T, responses ...*http.Response) (*simpleClient, *fakeTransport, *[]time.Duration) {
+	delays := []time.Duration{}
+	origSleep := retrySleep
+	retrySleep = func(d time.Duration) { delays = append(delays, d) }
+	t.Cleanup(func() { retrySleep = origSleep })
+
+	tr := &fakeTransport{responses: responses}
+	rootUrl, _ := url.Parse("https://api.github.com/")
+	return &simpleClient{httpClient: &http.Client{Transport: tr}, rootUrl: rootUrl}, tr, &delays
+}
+
+func TestSimpleClient_RetrySucceeds(t *testing.T) {
+	c, tr, delays := setupRetryTest(t, nil, fakeResponse(200))
+
+	res, err := c.Get("user")
+	assert.Equal(t, nil, err)
+	assert.Equal(t, 200, res.StatusCode)
+	assert.Equal(t, []string{"GET ", "GET "}, tr.requests)
+	assert.Equal(t, 1, len(*delays))
+	assert.T(t, (*delays)[0] >= retryBaseDelay/2 && (*delays)[0] <= retryBaseDelay)
+}
+
+func TestSimpleClient_RetryGivesUp(t *testing.T) {
+	c, tr, delays := setupRetryTest(t, fakeResponse(502), fakeResponse(503), fakeResponse(408), fakeResponse(504))
+
+	res, err := c.Get("user")
+	assert.Equal(t, nil, err)
+	assert.Equal(t, 504, res.StatusCode)
+	assert.Equal(t, 4, len(tr.requests))
+	assert.Equal(t, 3, len(*delays))
+	assert.T(t, (*delays)[2] >= 2*retryBaseDelay && (*delays)[2] <= 4*retryBaseDelay)
+}
+
+func TestSimpleClient_RetryAfter(t *testing.T) {
+	c, _, delays := setupRetryTest(t, fakeResponse(503, "Retry-After", "7"), fakeResponse(200))
+
+	res, err := c.Get("user")
+	assert.Equal(t, nil, err)
+	assert.Equal(t, 200, res.StatusCode)
+	assert.Equal(t, []time.Duration{7 * time.Second}, *delays)
+
+	c, tr, _ := setupRetryTest(t, fakeResponse(503, "Retry-After", "3600"), fakeResponse(200))
+	res, _ = c.Get("user")
+	assert.Equal(t, 503, res.StatusCode)
+	assert.Equal(t, 1, len(tr.requests))
+}
+
+func TestSimpleClient_RetryMethods(t *testing.T) {
+	c, tr, _ := setupRetryTest(t, fakeResponse(502), fakeResponse(201))
+	res, _ := c.PostJSON("user/repos", map[string]string{"name": "dotfiles"})
+	assert.Equal(t, 502, res.StatusCode)
+	assert.Equal(t, 1, len(tr.requests))
+
+	os.Setenv("HUB_RETRIES", "1")
+	defer os.Unsetenv("HUB_RETRIES")
+	c, tr, _ = setupRetryTest(t, fakeResponse(502), fakeResponse(201))
+	res, _ = c.PostJSON("user/repos", map[string]string{"name": "dotfiles"})
+	assert.Equal(t, 201, res.StatusCode)
+	assert.Equal(t, []string{`POST {"name":"dotfiles"}`, `POST {"name":"dotfiles"}`}, tr.requests)
+
+	c, tr, _ = setupRetryTest(t, fakeResponse(502), fakeResponse(502), fakeResponse(200))
+	res, _ = c.Get("user")
+	assert.Equal(t, 502, res.StatusCode)
+	assert.Equal(t, 2, len(tr.requests))
+
+	DisableRetries = true
+	defer func() { DisableRetries = false }()
+	c, tr, _ = setupRetryTest(t, fakeResponse(502), fakeResponse(200))
+	res, _ = c.Get("user")
+	assert.Equal(t, 502, res.StatusCode)
+	assert.Equal(t, 1, len(tr.requests))
+}
@@ -0,0 +1,164 @@
+package github
+
+import (
+	"crypto/md5"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+const (
+	idempotencyTTL       = 24 * time.Hour
+	idempotencyLockStale = 10 * time.Minute
+	idempotencyLockWait  = 30 * time.Second
+)
+
+// IdempotencyLedger records the outcome of mutating operations performed under
+// an idempotency key, so that repeating an operation with the same key replays
+// the recorded output instead of performing it again. Each key is stored in its
+// own file and guarded by a lock file, which makes the ledger safe to share
+// between concurrent processes.
+type IdempotencyLedger struct {
+	Dir string
+	TTL time.Duration
+}
+
+type idempotencyRecord struct {
+	Host      string    `json:"host"`
+	Key       string    `json:"key"`
+	Output    string    `json:"output"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+func NewIdempotencyLedger() *IdempotencyLedger {
+	return &IdempotencyLedger{
+		Dir: cacheDir("idempotency"),
+		TTL: idempotencyTTL,
+	}
+}
+
+// Do performs fn unless an operation with the same host and key has already
+// been recorded as successful, in which case its recorded output is returned
+// with replayed set. The output of fn is only recorded when it reports success.
+func (l *IdempotencyLedger) Do(host, key string, fn func() (output string, success bool, err error)) (output string, replayed bool, err error) {
+	if l.Dir == "" {
+		err = fmt.Errorf("can't record idempotency keys without a home directory")
+		return
+	}
+	if err = os.MkdirAll(l.Dir, 0700); err != nil {
+		return
+	}
+	l.cleanup()
+
+	file := l.file(host, key)
+	unlock, err := l.lock(file)
+	if err != nil {
+		return
+	}
+	defer unlock()
+
+	if record := l.read(file); record != nil {
+		return record.Output, true, nil
+	}
+
+	output, success, err := fn()
+	if err == nil && success {
+		err = l.write(file, &idempotencyRecord{
+			Host:      host,
+			Key:       key,
+			Output:    output,
+			CreatedAt: time.Now(),
+		})
+	}
+	return
+}
+
+func (l *IdempotencyLedger) file(host, key string) string {
+	hash := md5.New()
+	fmt.Fprintf(hash, "%s\x00%s", host, key)
+	return filepath.Join(l.Dir, fmt.Sprintf("%x", hash.Sum(nil)))
+}
+
+func (l *IdempotencyLedger) lock(file string) (unlock func(), err error) {
+	lockFile := file + ".lock"
+	deadline := time.Now().Add(idempotencyLockWait)
+	for {
+		var f *os.File
+		f, err = os.OpenFile(lockFile, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0600)
+		if err == nil {
+			f.Close()
+			unlock = func() { os.Remove(lockFile) }
+			return
+		}
+		if !os.IsExist(err) {
+			return
+		}
+
+		// a process that died while holding the lock leaves it behind
+		if info, statErr := os.Stat(lockFile); statErr == nil && time.Since(info.ModTime()) > idempotencyLockStale {
+			os.Remove(lockFile)
+			continue
+		}
+		if time.Now().After(deadline) {
+			err = fmt.Errorf("timed out waiting for another operation with the same idempotency key to finish")
+			return
+		}
+		time.Sleep(100 * time.Millisecond)
+	}
+}
+
+func (l *IdempotencyLedger) read(file string) *idempotencyRecord {
+	data, err := ioutil.ReadFile(file)
+	if err != nil {
+		return nil
+	}
+	record := &idempotencyRecord{}
+	if err := json.Unmarshal(data, record); err != nil {
+		return nil
+	}
+	if time.Since(record.CreatedAt) > l.TTL {
+		return nil
+	}
+	return record
+}
+
+func (l *IdempotencyLedger) write(file string, record *idempotencyRecord) error {
+	data, err := json.Marshal(record)
+	if err != nil {
+		return err
+	}
+
+	// write to a temporary file first so that readers never see partial records
+	tmp, err := ioutil.TempFile(l.Dir, ".record-")
+	if err != nil {
+		return err
+	}
+	_, err = tmp.Write(data)
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	return os.Rename(tmp.Name(), file)
+}
+
+func (l *IdempotencyLedger) cleanup() {
+	entries, err := ioutil.ReadDir(l.Dir)
+	if err != nil {
+		return
+	}
+	for _, entry := range entries {
+		maxAge := l.TTL
+		if filepath.Ext(entry.Name()) == ".lock" {
+			maxAge = idempotencyLockStale
+		}
+		if time.Since(entry.ModTime()) > maxAge {
+			os.Remove(filepath.Join(l.Dir, entry.Name()))
+		}
+	}
+}
@@ -0,0 +1,77 @@
+package github
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/bmizerany/assert"
+)
+
+func newTestLedger(t *testing.T) *IdempotencyLedger {
+	dir, err := ioutil.TempDir("", "hub-idempotency-")
+	assert.Equal(t, nil, err)
+	return &IdempotencyLedger{Dir: dir, TTL: time.Hour}
+}
+
+func TestIdempotencyLedger_Replay(t *testing.T) {
+	ledger := newTestLedger(t)
+	defer os.RemoveAll(ledger.Dir)
+
+	calls := 0
+	fn := func() (string, bool, error) {
+		calls++
+		return "https://github.com/mislav/dotfiles/releases/v1.0", true, nil
+	}
+
+	output, replayed, err := ledger.Do("github.com", "release-1", fn)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, false, replayed)
+	assert.Equal(t, "https://github.com/mislav/dotfiles/releases/v1.0", output)
+
+	output, replayed, err = ledger.Do("github.com", "release-1", fn)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, true, replayed)
+	assert.Equal(t, "https://github.com/mislav/dotfiles/releases/v1.0", output)
+	assert.Equal(t, 1, calls)
+
+	_, replayed, _ = ledger.Do("example.com", "release-1", fn)
+	assert.Equal(t, false, replayed)
+	assert.Equal(t, 2, calls)
+}
+
+func TestIdempotencyLedger_FailureNotRecorded(t *testing.T) {
+	ledger := newTestLedger(t)
+	defer os.RemoveAll(ledger.Dir)
+
+	calls := 0
+	fn := func() (string, bool, error) {
+		calls++
+		return "error", false, nil
+	}
+
+	ledger.Do("github.com", "key", fn)
+	_, replayed, _ := ledger.Do("github.com", "key", fn)
+	assert.Equal(t, false, replayed)
+	assert.Equal(t, 2, calls)
+}
+
+func TestIdempotencyLedger_Expired(t *testing.T) {
+	ledger := newTestLedger(t)
+	defer os.RemoveAll(ledger.Dir)
+
+	fn := func() (string, bool, error) {
+		return "ok", true, nil
+	}
+
+	ledger.Do("github.com", "key", fn)
+	ledger.TTL = time.Nanosecond
+	time.Sleep(time.Millisecond)
+
+	_, replayed, _ := ledger.Do("github.com", "key", fn)
+	assert.Equal(t, false, replayed)
+
+	entries, _ := ioutil.ReadDir(ledger.Dir)
+	assert.Equal(t, 1, len(entries))
+}
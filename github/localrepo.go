@@ -1,9 +1,16 @@
 package github
 
 import (
+	"crypto/md5"
+	"encoding/json"
 	"fmt"
+	"io/ioutil"
 	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
 	"strings"
+	"time"
 
 	"github.com/github/hub/git"
 )
@@ -22,6 +29,12 @@ func LocalRepo() (repo *GitHubRepo, err error) {
 
 type GitHubRepo struct {
 	remotes []Remote
+
+	// upstream is what upstreamRemote found, once upstreamChecked is set
+	upstream        *Remote
+	upstreamChecked bool
+	// lookupParent replaces asking the API for the parent of a fork in tests
+	lookupParent func(*Project) (*Project, error)
 }
 
 func (r *GitHubRepo) loadRemotes() error {
@@ -52,45 +65,213 @@ func (r *GitHubRepo) RemoteByName(name string) (*Remote, error) {
 	return nil, fmt.Errorf("No git remote with name %s", name)
 }
 
+// remotesForPublish lists the remotes that a branch might be pushed to, most
+// likely first: the remote named by the "hub.forkRemote" git config, those
+// that push to a repository of owner, the remotes with the conventional names
+// in the reverse order of OriginNamesInLookupOrder, and the remote for the
+// upstream repository last. Other remotes are left out.
 func (r *GitHubRepo) remotesForPublish(owner string) (remotes []Remote) {
 	r.loadRemotes()
-	remotesMap := make(map[string]Remote)
+	forkName, _ := git.Config("hub.forkRemote")
+	upstream := r.upstreamRemote()
 
-	if owner != "" {
-		for _, remote := range r.remotes {
-			p, e := remote.Project()
-			if e == nil && strings.EqualFold(p.Owner, owner) {
-				remotesMap[remote.Name] = remote
+	rank := func(remote Remote) int {
+		if remote.Name == forkName {
+			return 0
+		}
+		if p, err := remote.PushProject(); err == nil && owner != "" && strings.EqualFold(p.Owner, owner) {
+			return 1
+		}
+		if upstream != nil && remote.Name == upstream.Name {
+			return 2 + len(OriginNamesInLookupOrder)
+		}
+		for i, name := range OriginNamesInLookupOrder {
+			if remote.Name == name {
+				return 2 + len(OriginNamesInLookupOrder) - 1 - i
 			}
 		}
+		return -1
+	}
+
+	for _, remote := range r.remotes {
+		if rank(remote) >= 0 {
+			remotes = append(remotes, remote)
+		}
+	}
+	sort.SliceStable(remotes, func(a, b int) bool {
+		return rank(remotes[a]) < rank(remotes[b])
+	})
+	return
+}
+
+// ForkRemote finds the remote for the fork of owner: the remote named by the
+// "hub.forkRemote" git config, or else the first remote that pushes to a
+// repository owned by owner.
+func (r *GitHubRepo) ForkRemote(owner string) (*Remote, error) {
+	if err := r.loadRemotes(); err != nil {
+		return nil, err
+	}
+
+	if name, _ := git.Config("hub.forkRemote"); name != "" {
+		if remote, err := r.RemoteByName(name); err == nil {
+			return remote, nil
+		}
+	}
+	for i, remote := range r.remotes {
+		if p, err := remote.PushProject(); err == nil && owner != "" && strings.EqualFold(p.Owner, owner) {
+			return &r.remotes[i], nil
+		}
+	}
+	return nil, fmt.Errorf("could not find a git remote for a fork owned by '%s'", owner)
+}
+
+// upstreamRemote tells the remote for the repository that is worked on apart
+// from the remotes for forks of it by what they point to rather than by their
+// names. That's the remote named by the "hub.upstreamRemote" git config, or
+// else, when one of several GitHub remotes is for a repository of the
+// authenticated user, the remote for the parent of that repository, or that
+// remote itself if its repository isn't a fork. It's nil when neither
+// applies, such as without stored credentials for the host.
+func (r *GitHubRepo) upstreamRemote() *Remote {
+	if r.upstreamChecked {
+		return r.upstream
+	}
+	r.upstreamChecked = true
+
+	if name, _ := git.Config("hub.upstreamRemote"); name != "" {
+		if remote, err := r.RemoteByName(name); err == nil {
+			r.upstream = remote
+			return r.upstream
+		}
 	}
 
-	names := OriginNamesInLookupOrder
-	for _, name := range names {
-		if _, ok := remotesMap[name]; ok {
+	var fork *Remote
+	githubRemotes := 0
+	for i, remote := range r.remotes {
+		project, err := remote.Project()
+		if err != nil {
 			continue
 		}
+		githubRemotes++
+		if fork == nil {
+			if host := CurrentConfig().Find(project.Host); host != nil && strings.EqualFold(project.Owner, host.User) {
+				fork = &r.remotes[i]
+			}
+		}
+	}
+	if name, _ := git.Config("hub.forkRemote"); name != "" {
+		if remote, err := r.RemoteByName(name); err == nil {
+			fork = remote
+		}
+	}
+	if fork == nil || githubRemotes < 2 {
+		return nil
+	}
+	// the remote picked by name is fine unless it's the user's own, which
+	// saves asking the API for the usual "origin" and "upstream" setups
+	for i, remote := range r.remotes {
+		if _, err := remote.Project(); err == nil {
+			if fork.Name != r.remotes[i].Name {
+				return nil
+			}
+			break
+		}
+	}
+
+	project, err := fork.Project()
+	if err != nil {
+		return nil
+	}
+	parent, err := r.parentProject(project)
+	if err != nil {
+		return nil
+	}
+	if parent == nil {
+		r.upstream = fork
+	} else if remote, err := r.RemoteForProject(parent); err == nil {
+		r.upstream = remote
+	}
+	return r.upstream
+}
 
-		remote, err := r.RemoteByName(name)
-		if err == nil {
-			remotesMap[remote.Name] = *remote
+// parentProject is the repository that project was forked from, or nil if
+// it isn't a fork. The API is only asked with stored credentials for the host
+// so as not to prompt for them, and the answer is remembered for a day.
+func (r *GitHubRepo) parentProject(project *Project) (*Project, error) {
+	if r.lookupParent != nil {
+		return r.lookupParent(project)
+	}
+
+	file := parentProjectFile(project)
+	if cached := readParentProject(file); cached != nil {
+		if cached.Name == "" {
+			return nil, nil
 		}
+		return NewProject(cached.Owner, cached.Name, project.Host), nil
 	}
 
-	for i := len(names) - 1; i >= 0; i-- {
-		name := names[i]
-		if remote, ok := remotesMap[name]; ok {
-			remotes = append(remotes, remote)
-			delete(remotesMap, name)
+	host := CurrentConfig().Find(project.Host)
+	if host == nil {
+		return nil, fmt.Errorf("no credentials for %s", project.Host)
+	}
+	repo, err := NewClientWithHost(host).Repository(project)
+	if err != nil {
+		return nil, err
+	}
+	cached := &parentProjectRecord{FetchedAt: time.Now()}
+	var parent *Project
+	if repo.Parent != nil {
+		if parent, err = NewProjectFromRepo(repo.Parent); err != nil {
+			return nil, err
 		}
+		cached.Owner, cached.Name = parent.Owner, parent.Name
 	}
+	writeParentProject(file, cached)
+	return parent, nil
+}
+
+const parentProjectTTL = 24 * time.Hour
+
+// parentProjectsDir is looked up late, since the home directory is cached
+// once it's known.
+var parentProjectsDir = func() string { return cacheDir("parents") }
+
+// parentProjectRecord is what parentProject remembers about a repository: the
+// owner and name of its parent, which are empty if it isn't a fork.
+type parentProjectRecord struct {
+	Owner     string    `json:"owner"`
+	Name      string    `json:"name"`
+	FetchedAt time.Time `json:"fetched_at"`
+}
 
-	// anything other than names has higher priority
-	for _, remote := range remotesMap {
-		remotes = append([]Remote{remote}, remotes...)
+func parentProjectFile(project *Project) string {
+	dir := parentProjectsDir()
+	if dir == "" {
+		return ""
 	}
+	key := strings.ToLower(fmt.Sprintf("%s/%s/%s", project.Host, project.Owner, project.Name))
+	return filepath.Join(dir, fmt.Sprintf("%x", md5.Sum([]byte(key))))
+}
 
-	return
+func readParentProject(file string) *parentProjectRecord {
+	data, err := ioutil.ReadFile(file)
+	if err != nil {
+		return nil
+	}
+	record := &parentProjectRecord{}
+	if json.Unmarshal(data, record) != nil || time.Since(record.FetchedAt) > parentProjectTTL {
+		return nil
+	}
+	return record
+}
+
+func writeParentProject(file string, record *parentProjectRecord) {
+	if file == "" {
+		return
+	}
+	if data, err := json.Marshal(record); err == nil && os.MkdirAll(filepath.Dir(file), 0700) == nil {
+		ioutil.WriteFile(file, data, 0600)
+	}
 }
 
 func (r *GitHubRepo) CurrentBranch() (branch *Branch, err error) {
@@ -112,15 +293,24 @@ func (r *GitHubRepo) MasterBranch() *Branch {
 	}
 }
 
+// DefaultBranch guesses the default branch of the repository that remote
+// points to. Unless the HEAD of the remote is known locally, the API is only
+// consulted if credentials for its host are available, since this mustn't
+// prompt for them. The last resort is "master".
 func (r *GitHubRepo) DefaultBranch(remote *Remote) *Branch {
 	var name string
 	if remote != nil {
-		name, _ = git.BranchAtRef("refs", "remotes", remote.Name, "HEAD")
+		name = remoteHead(remote)
+		if name == "" {
+			if project, err := remote.Project(); err == nil && CurrentConfig().Find(project.Host) != nil {
+				name, _ = project.DefaultBranch()
+			}
+		}
 	}
 	if name == "" {
-		name = "refs/heads/master"
+		name = "master"
 	}
-	return &Branch{r, name}
+	return &Branch{r, "refs/heads/" + name}
 }
 
 func (r *GitHubRepo) RemoteBranchAndProject(owner string, preferUpstream bool) (branch *Branch, project *Project, err error) {
@@ -128,12 +318,7 @@ func (r *GitHubRepo) RemoteBranchAndProject(owner string, preferUpstream bool) (
 		return
 	}
 
-	for _, remote := range r.remotes {
-		if p, err := remote.Project(); err == nil {
-			project = p
-			break
-		}
-	}
+	project, _ = r.MainProject()
 
 	branch, err = r.CurrentBranch()
 	if err != nil {
@@ -192,9 +377,30 @@ func (r *GitHubRepo) RemoteForProject(project *Project) (*Remote, error) {
 	return nil, fmt.Errorf("could not find a git remote for '%s'", project)
 }
 
+func (r *GitHubRepo) RemoteForOwner(owner string) (*Remote, error) {
+	if err := r.loadRemotes(); err != nil {
+		return nil, err
+	}
+
+	for _, remote := range r.remotes {
+		remoteProject, err := remote.Project()
+		if err == nil && strings.EqualFold(remoteProject.Owner, owner) {
+			return &remote, nil
+		}
+	}
+	return nil, fmt.Errorf("could not find a git remote for a repository owned by '%s'", owner)
+}
+
+// MainRemote is the remote for the repository that is worked on, as opposed
+// to a fork of it. See upstreamRemote for how it's told apart from the
+// remotes for forks; failing that, it's the first remote in the order of
+// OriginNamesInLookupOrder.
 func (r *GitHubRepo) MainRemote() (*Remote, error) {
 	r.loadRemotes()
 
+	if remote := r.upstreamRemote(); remote != nil {
+		return remote, nil
+	}
 	if len(r.remotes) > 0 {
 		return &r.remotes[0], nil
 	} else {
@@ -205,6 +411,11 @@ func (r *GitHubRepo) MainRemote() (*Remote, error) {
 func (r *GitHubRepo) MainProject() (*Project, error) {
 	r.loadRemotes()
 
+	if remote := r.upstreamRemote(); remote != nil {
+		if project, err := remote.Project(); err == nil {
+			return project, nil
+		}
+	}
 	for _, remote := range r.remotes {
 		if project, err := remote.Project(); err == nil {
 			return project, nil
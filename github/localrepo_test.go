@@ -1,10 +1,16 @@
 package github
 
 import (
+	"fmt"
+	"io/ioutil"
 	"net/url"
+	"os"
 	"testing"
+	"time"
 
 	"github.com/bmizerany/assert"
+	"github.com/github/hub/fixtures"
+	"github.com/github/hub/git"
 )
 
 func TestGitHubRepo_remotesForPublish(t *testing.T) {
@@ -15,10 +21,127 @@ func TestGitHubRepo_remotesForPublish(t *testing.T) {
 			URL:  url,
 		},
 	}
-	repo := GitHubRepo{remotes}
+	repo := GitHubRepo{remotes: remotes, upstreamChecked: true}
 	remotesForPublish := repo.remotesForPublish("owner")
 
 	assert.Equal(t, 1, len(remotesForPublish))
 	assert.Equal(t, "Owner", remotesForPublish[0].Name)
 	assert.Equal(t, url.String(), remotesForPublish[0].URL.String())
 }
+
+func remoteNames(remotes []Remote) []string {
+	names := []string{}
+	for _, remote := range remotes {
+		names = append(names, remote.Name)
+	}
+	return names
+}
+
+func TestGitHubRepo_remoteRoles(t *testing.T) {
+	testRepo := fixtures.SetupTestRepo()
+	defer testRepo.TearDown()
+	testConfig := fixtures.SetupTestConfigs()
+	defer testConfig.TearDown()
+
+	parents := map[string]string{"jingweno/hub": "github/hub"}
+	lookupParent := func(project *Project) (*Project, error) {
+		parent, found := parents[project.String()]
+		if !found {
+			return nil, nil
+		}
+		return NewProject(parent[:6], parent[7:], project.Host), nil
+	}
+	offline := func(project *Project) (*Project, error) {
+		return nil, fmt.Errorf("no credentials")
+	}
+	mainRemote := func(repo *GitHubRepo) string {
+		remote, err := repo.MainRemote()
+		assert.Equal(t, nil, err)
+		return remote.Name
+	}
+
+	// only an origin
+	git.Quiet("remote", "set-url", "origin", "https://github.com/jingweno/hub.git")
+	repo := &GitHubRepo{lookupParent: lookupParent}
+	assert.Equal(t, "origin", mainRemote(repo))
+
+	// a triangular setup with unconventional names
+	git.Quiet("remote", "remove", "origin")
+	testRepo.AddRemote("gh-resolved", "https://github.com/github/hub.git", "")
+	testRepo.AddRemote("fork", "git@github.com:jingweno/hub.git", "")
+	testRepo.AddRemote("josh", "https://github.com/josh/hub.git", "")
+
+	repo = &GitHubRepo{lookupParent: lookupParent}
+	assert.Equal(t, "gh-resolved", mainRemote(repo))
+	project, err := repo.MainProject()
+	assert.Equal(t, nil, err)
+	assert.Equal(t, "github/hub", project.String())
+	fork, err := repo.ForkRemote("jingweno")
+	assert.Equal(t, nil, err)
+	assert.Equal(t, "fork", fork.Name)
+	assert.Equal(t, []string{"fork", "gh-resolved"}, remoteNames(repo.remotesForPublish("jingweno")))
+
+	// the fork of the authenticated user isn't a fork after all
+	delete(parents, "jingweno/hub")
+	repo = &GitHubRepo{lookupParent: lookupParent}
+	assert.Equal(t, "fork", mainRemote(repo))
+
+	// without asking the API, remotes are ordered by name
+	repo = &GitHubRepo{lookupParent: offline}
+	assert.Equal(t, "fork", mainRemote(repo))
+
+	// explicit configuration
+	git.Quiet("config", "hub.upstreamRemote", "gh-resolved")
+	git.Quiet("config", "hub.forkRemote", "josh")
+	repo = &GitHubRepo{lookupParent: offline}
+	assert.Equal(t, "gh-resolved", mainRemote(repo))
+	fork, err = repo.ForkRemote("jingweno")
+	assert.Equal(t, nil, err)
+	assert.Equal(t, "josh", fork.Name)
+	assert.Equal(t, []string{"josh", "fork", "gh-resolved"}, remoteNames(repo.remotesForPublish("jingweno")))
+}
+
+func TestGitHubRepo_remoteRolesByName(t *testing.T) {
+	testRepo := fixtures.SetupTestRepo()
+	defer testRepo.TearDown()
+	testConfig := fixtures.SetupTestConfigs()
+	defer testConfig.TearDown()
+
+	git.Quiet("remote", "set-url", "origin", "https://github.com/jingweno/hub.git")
+	testRepo.AddRemote("upstream", "https://github.com/github/hub.git", "")
+
+	repo := &GitHubRepo{lookupParent: func(project *Project) (*Project, error) {
+		t.Fatalf("unexpected lookup of the parent of %s", project)
+		return nil, nil
+	}}
+	remote, err := repo.MainRemote()
+	assert.Equal(t, nil, err)
+	assert.Equal(t, "upstream", remote.Name)
+}
+
+func TestGitHubRepo_parentProjectCache(t *testing.T) {
+	dir, err := ioutil.TempDir("", "hub-parents-")
+	assert.Equal(t, nil, err)
+	defer os.RemoveAll(dir)
+	originalDir := parentProjectsDir
+	parentProjectsDir = func() string { return dir }
+	defer func() { parentProjectsDir = originalDir }()
+
+	fork := NewProject("jingweno", "hub", "example.com")
+	writeParentProject(parentProjectFile(fork), &parentProjectRecord{Owner: "github", Name: "hub", FetchedAt: time.Now()})
+	original := NewProject("github", "hub", "example.com")
+	writeParentProject(parentProjectFile(original), &parentProjectRecord{FetchedAt: time.Now()})
+
+	// without credentials for the host, only the cache can answer
+	repo := &GitHubRepo{}
+	parent, err := repo.parentProject(fork)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, "github/hub", parent.String())
+	parent, err = repo.parentProject(original)
+	assert.Equal(t, nil, err)
+	assert.T(t, parent == nil)
+
+	writeParentProject(parentProjectFile(fork), &parentProjectRecord{Owner: "github", Name: "hub", FetchedAt: time.Now().Add(-25 * time.Hour)})
+	_, err = repo.parentProject(fork)
+	assert.Equal(t, "no credentials for example.com", err.Error())
+}
@@ -0,0 +1,214 @@
+package github
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+	"sync"
+)
+
+// Member is a user that belongs to an organization or team. Name is only
+// known after looking up the user with FetchUsers.
+type Member struct {
+	Login string `json:"login"`
+	Name  string `json:"name"`
+}
+
+// MembersFilter narrows down the members of an organization or team. Role is
+// "admin" or "member" for organizations and "maintainer" or "member" for teams.
+// TwoFactorDisabled only applies to organizations.
+type MembersFilter struct {
+	Role              string
+	TwoFactorDisabled bool
+}
+
+// A MembershipScopeError means that GitHub would only reveal the public
+// members of an organization, which would make listings and membership checks
+// silently incomplete.
+type MembershipScopeError struct {
+	Org    string
+	Reason string
+}
+
+func (e *MembershipScopeError) Error() string {
+	return fmt.Sprintf("can't see private members of %s: %s", e.Org, e.Reason)
+}
+
+// orgScopes grant access to private organization membership.
+var orgScopes = []string{"read:org", "write:org", "admin:org"}
+
+func checkOrgScope(org string, res *simpleResponse) error {
+	if strings.Contains(res.Request.URL.Path, "/public_members") {
+		return &MembershipScopeError{org, "you're not a member of this organization"}
+	}
+	header, present := res.Header["X-Oauth-Scopes"]
+	if !present {
+		// fine-grained tokens and app tokens don't report scopes
+		return nil
+	}
+	for _, scope := range strings.Split(strings.Join(header, ","), ",") {
+		for _, orgScope := range orgScopes {
+			if strings.TrimSpace(scope) == orgScope {
+				return nil
+			}
+		}
+	}
+	return &MembershipScopeError{org, "the access token lacks the 'read:org' scope"}
+}
+
+func (client *Client) FetchOrgMembers(org string, filter MembersFilter) ([]Member, error) {
+	query := url.Values{}
+	if filter.Role != "" {
+		query.Set("role", filter.Role)
+	}
+	if filter.TwoFactorDisabled {
+		query.Set("filter", "2fa_disabled")
+	}
+	return client.fetchMembers(org, fmt.Sprintf("orgs/%s/members", org), query)
+}
+
+func (client *Client) FetchTeamMembers(org, team string, filter MembersFilter) ([]Member, error) {
+	query := url.Values{}
+	if filter.Role != "" {
+		query.Set("role", filter.Role)
+	}
+	return client.fetchMembers(org, fmt.Sprintf("orgs/%s/teams/%s/members", org, team), query)
+}
+
+func (client *Client) fetchMembers(org, path string, query url.Values) (members []Member, err error) {
+	api, err := client.simpleApi()
+	if err != nil {
+		return
+	}
+
+	query.Set("per_page", "100")
+	path += "?" + query.Encode()
+
+	members = []Member{}
+	var res *simpleResponse
+
+	for path != "" {
+		res, err = api.Get(path)
+		if err = checkStatus(200, "fetching members", res, err); err != nil {
+			return
+		}
+		if err = checkOrgScope(org, res); err != nil {
+			res.discard()
+			return
+		}
+		path = res.Link("next")
+
+		membersPage := []Member{}
+		if err = res.Unmarshal(&membersPage); err != nil {
+			return
+		}
+		members = append(members, membersPage...)
+	}
+
+	return
+}
+
+// IsOrgMember reports whether login is a member of org, and of the given role
+// unless role is empty.
+func (client *Client) IsOrgMember(org, login, role string) (bool, error) {
+	api, err := client.simpleApi()
+	if err != nil {
+		return false, err
+	}
+
+	res, err := api.Get(fmt.Sprintf("orgs/%s/members/%s", org, login))
+	if err == nil && res.StatusCode == 404 {
+		res.discard()
+		// after a redirect to the public members, a 404 only means that the
+		// membership isn't public
+		if strings.Contains(res.Request.URL.Path, "/public_members") {
+			return false, checkOrgScope(org, res)
+		}
+		return false, nil
+	}
+	if err = checkStatus(204, "checking membership", res, err); err != nil {
+		return false, err
+	}
+	res.discard()
+	if err = checkOrgScope(org, res); err != nil {
+		return false, err
+	}
+	if role == "" {
+		return true, nil
+	}
+	return client.hasMembershipRole(fmt.Sprintf("orgs/%s/memberships/%s", org, login), role)
+}
+
+// IsTeamMember reports whether login is an active member of the team, and of
+// the given role unless role is empty.
+func (client *Client) IsTeamMember(org, team, login, role string) (bool, error) {
+	return client.hasMembershipRole(fmt.Sprintf("orgs/%s/teams/%s/memberships/%s", org, team, login), role)
+}
+
+func (client *Client) hasMembershipRole(path, role string) (bool, error) {
+	api, err := client.simpleApi()
+	if err != nil {
+		return false, err
+	}
+
+	res, err := api.Get(path)
+	if err == nil && res.StatusCode == 404 {
+		res.discard()
+		return false, nil
+	}
+	if err = checkStatus(200, "checking membership", res, err); err != nil {
+		return false, err
+	}
+
+	membership := struct {
+		State string `json:"state"`
+		Role  string `json:"role"`
+	}{}
+	if err = res.Unmarshal(&membership); err != nil {
+		return false, err
+	}
+	return membership.State == "active" && (role == "" || membership.Role == role), nil
+}
+
+// concurrentUserLookups limits how many user lookups FetchUsers performs at
+// the same time.
+const concurrentUserLookups = 8
+
+// FetchUsers looks up the profiles of the given users concurrently and returns
+// them in the same order.
+func (client *Client) FetchUsers(logins []string) ([]Member, error) {
+	api, err := client.simpleApi()
+	if err != nil {
+		return nil, err
+	}
+
+	users := make([]Member, len(logins))
+	errs := make([]error, len(logins))
+	slots := make(chan bool, concurrentUserLookups)
+	var wg sync.WaitGroup
+
+	for i, login := range logins {
+		wg.Add(1)
+		slots <- true
+		go func(i int, login string) {
+			defer func() {
+				<-slots
+				wg.Done()
+			}()
+			res, err := api.Get("users/" + login)
+			if err = checkStatus(200, "fetching user", res, err); err != nil {
+				errs[i] = err
+				return
+			}
+			errs[i] = res.Unmarshal(&users[i])
+		}(i, login)
+	}
+	wg.Wait()
+
+	for _, err := range errs {
+		if err != nil {
+			return nil, err
+		}
+	}
+	return users, nil
+}
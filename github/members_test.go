@@ -0,0 +1,126 @@
+package github
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/bmizerany/assert"
+)
+
+func TestClient_FetchOrgMembers(t *testing.T) {
+	var serverURL string
+	client, cleanup := setupTestClient(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/orgs/acme/members", r.URL.Path)
+		assert.Equal(t, "admin", r.URL.Query().Get("role"))
+		assert.Equal(t, "2fa_disabled", r.URL.Query().Get("filter"))
+		w.Header().Set("X-OAuth-Scopes", "repo, read:org")
+		if r.URL.Query().Get("page") == "" {
+			w.Header().Set("Link", fmt.Sprintf(`<%s/api/v3/orgs/acme/members?role=admin&filter=2fa_disabled&page=2>; rel="next"`, serverURL))
+			json.NewEncoder(w).Encode([]Member{{Login: "mona"}})
+		} else {
+			json.NewEncoder(w).Encode([]Member{{Login: "hubot"}})
+		}
+	})
+	defer cleanup()
+	serverURL = "http://" + client.Host.Host
+
+	members, err := client.FetchOrgMembers("acme", MembersFilter{Role: "admin", TwoFactorDisabled: true})
+	assert.Equal(t, nil, err)
+	assert.Equal(t, []Member{{Login: "mona"}, {Login: "hubot"}}, members)
+}
+
+func TestClient_FetchOrgMembers_scopeError(t *testing.T) {
+	client, cleanup := setupTestClient(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-OAuth-Scopes", "repo")
+		json.NewEncoder(w).Encode([]Member{{Login: "mona"}})
+	})
+	defer cleanup()
+
+	_, err := client.FetchOrgMembers("acme", MembersFilter{})
+	assert.Equal(t, "can't see private members of acme: the access token lacks the 'read:org' scope", err.Error())
+}
+
+func TestClient_IsOrgMember(t *testing.T) {
+	client, cleanup := setupTestClient(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-OAuth-Scopes", "admin:org")
+		switch r.URL.Path {
+		case "/orgs/acme/members/mona":
+			w.WriteHeader(http.StatusNoContent)
+		case "/orgs/acme/memberships/mona":
+			fmt.Fprint(w, `{"state":"active","role":"member"}`)
+		case "/orgs/secretive/members/mona":
+			http.Redirect(w, r, "/api/v3/orgs/secretive/public_members/mona", http.StatusFound)
+		case "/orgs/secretive/public_members/mona":
+			w.WriteHeader(http.StatusNoContent)
+		case "/orgs/secretive/members/hubot":
+			http.Redirect(w, r, "/api/v3/orgs/secretive/public_members/hubot", http.StatusFound)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	})
+	defer cleanup()
+
+	isMember, err := client.IsOrgMember("acme", "mona", "")
+	assert.Equal(t, nil, err)
+	assert.T(t, isMember)
+
+	isMember, err = client.IsOrgMember("acme", "mona", "admin")
+	assert.Equal(t, nil, err)
+	assert.T(t, !isMember)
+
+	isMember, err = client.IsOrgMember("acme", "hubot", "")
+	assert.Equal(t, nil, err)
+	assert.T(t, !isMember)
+
+	_, err = client.IsOrgMember("secretive", "mona", "")
+	assert.Equal(t, "can't see private members of secretive: you're not a member of this organization", err.Error())
+
+	isMember, err = client.IsOrgMember("secretive", "hubot", "")
+	assert.T(t, !isMember)
+	assert.Equal(t, "can't see private members of secretive: you're not a member of this organization", err.Error())
+}
+
+func TestClient_IsTeamMember(t *testing.T) {
+	client, cleanup := setupTestClient(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/orgs/acme/teams/security/memberships/mona":
+			fmt.Fprint(w, `{"state":"active","role":"maintainer"}`)
+		case "/orgs/acme/teams/security/memberships/hubot":
+			fmt.Fprint(w, `{"state":"pending","role":"member"}`)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	})
+	defer cleanup()
+
+	isMember, err := client.IsTeamMember("acme", "security", "mona", "maintainer")
+	assert.Equal(t, nil, err)
+	assert.T(t, isMember)
+
+	isMember, err = client.IsTeamMember("acme", "security", "hubot", "")
+	assert.Equal(t, nil, err)
+	assert.T(t, !isMember)
+}
+
+func TestClient_FetchUsers(t *testing.T) {
+	client, cleanup := setupTestClient(func(w http.ResponseWriter, r *http.Request) {
+		login := strings.TrimPrefix(r.URL.Path, "/users/")
+		json.NewEncoder(w).Encode(Member{Login: login, Name: strings.ToUpper(login)})
+	})
+	defer cleanup()
+
+	logins := []string{}
+	expected := []Member{}
+	for i := 0; i < 20; i++ {
+		login := fmt.Sprintf("user%d", i)
+		logins = append(logins, login)
+		expected = append(expected, Member{Login: login, Name: strings.ToUpper(login)})
+	}
+
+	users, err := client.FetchUsers(logins)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, expected, users)
+}
@@ -0,0 +1,128 @@
+package github
+
+import (
+	"fmt"
+	"net/url"
+)
+
+// MergeQueueEntry is the place of a pull request in the merge queue of its
+// base branch. State is one of "QUEUED", "AWAITING_CHECKS", "MERGEABLE",
+// "UNMERGEABLE", or "LOCKED".
+type MergeQueueEntry struct {
+	Position int    `json:"position"`
+	State    string `json:"state"`
+}
+
+// MergeQueueStatus tells whether a pull request was merged, and where it is in
+// the merge queue if it's still in one.
+type MergeQueueStatus struct {
+	Merged bool
+	State  string
+	Entry  *MergeQueueEntry
+}
+
+// HasMergeQueue tells whether the rules of branch, which come from the branch
+// protection and the rulesets of the repository, require pull requests to be
+// merged through a merge queue. Hosts that don't know about rules have no
+// merge queues.
+func (client *Client) HasMergeQueue(project *Project, branch string) (bool, error) {
+	api, err := client.simpleApi()
+	if err != nil {
+		return false, err
+	}
+
+	res, err := api.Get(fmt.Sprintf("repos/%s/%s/rules/branches/%s", project.Owner, project.Name, url.PathEscape(branch)))
+	if err == nil && res.StatusCode == 404 {
+		res.discard()
+		return false, nil
+	}
+	if err = checkStatus(200, "fetching branch rules", res, err); err != nil {
+		return false, err
+	}
+
+	rules := []struct {
+		Type string `json:"type"`
+	}{}
+	if err = res.Unmarshal(&rules); err != nil {
+		return false, err
+	}
+	for _, rule := range rules {
+		if rule.Type == "merge_queue" {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// EnqueuePullRequest adds the pull request to the merge queue of its base
+// branch, which merges it with the method that the queue is set up with once
+// the checks of the queue pass.
+func (client *Client) EnqueuePullRequest(pr *PullRequest) (*MergeQueueEntry, error) {
+	query := `mutation($id: ID!) {
+  enqueuePullRequest(input: {pullRequestId: $id}) {
+    mergeQueueEntry { position state }
+  }
+}`
+	data := struct {
+		EnqueuePullRequest struct {
+			MergeQueueEntry *MergeQueueEntry `json:"mergeQueueEntry"`
+		} `json:"enqueuePullRequest"`
+	}{}
+	if err := client.graphQL("adding pull request to merge queue", query, map[string]interface{}{"id": pr.NodeId}, &data); err != nil {
+		return nil, err
+	}
+	if data.EnqueuePullRequest.MergeQueueEntry == nil {
+		return nil, fmt.Errorf("Error adding pull request to merge queue: pull request #%d wasn't queued", pr.Number)
+	}
+	return data.EnqueuePullRequest.MergeQueueEntry, nil
+}
+
+// DequeuePullRequest removes the pull request from the merge queue.
+func (client *Client) DequeuePullRequest(pr *PullRequest) error {
+	query := `mutation($id: ID!) {
+  dequeuePullRequest(input: {id: $id}) {
+    mergeQueueEntry { state }
+  }
+}`
+	return client.graphQL("removing pull request from merge queue", query, map[string]interface{}{"id": pr.NodeId}, nil)
+}
+
+// FetchMergeQueueStatus looks up whether the pull request was merged and its
+// place in the merge queue, if any.
+func (client *Client) FetchMergeQueueStatus(project *Project, number int) (*MergeQueueStatus, error) {
+	query := `query($owner: String!, $name: String!, $number: Int!) {
+  repository(owner: $owner, name: $name) {
+    pullRequest(number: $number) {
+      merged
+      state
+      mergeQueueEntry { position state }
+    }
+  }
+}`
+	variables := map[string]interface{}{
+		"owner":  project.Owner,
+		"name":   project.Name,
+		"number": number,
+	}
+	data := struct {
+		Repository struct {
+			PullRequest *struct {
+				Merged          bool             `json:"merged"`
+				State           string           `json:"state"`
+				MergeQueueEntry *MergeQueueEntry `json:"mergeQueueEntry"`
+			} `json:"pullRequest"`
+		} `json:"repository"`
+	}{}
+	if err := client.graphQL("fetching merge queue status", query, variables, &data); err != nil {
+		return nil, err
+	}
+	pr := data.Repository.PullRequest
+	if pr == nil {
+		return nil, fmt.Errorf("Error fetching merge queue status: pull request #%d not found in %s", number, project)
+	}
+	return &MergeQueueStatus{
+		Merged: pr.Merged,
+		State:  pr.State,
+		Entry:  pr.MergeQueueEntry,
+	}, nil
+}
@@ -0,0 +1,82 @@
+package github_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/bmizerany/assert"
+	"github.com/github/hub/github"
+	"github.com/github/hub/github/githubtest"
+)
+
+func TestClient_HasMergeQueue(t *testing.T) {
+	client, server := githubtest.NewClient()
+	defer server.Close()
+	server.Handle(githubtest.Fixture{
+		Path: "/repos/acme/tools/rules/branches/main",
+		Body: json.RawMessage(`[{"type": "pull_request"}, {"type": "merge_queue", "parameters": {"merge_method": "SQUASH"}}]`),
+	})
+	server.Handle(githubtest.Fixture{
+		Path: "/repos/acme/tools/rules/branches/release/1.x",
+		Body: json.RawMessage(`[{"type": "deletion"}]`),
+	})
+
+	project := &github.Project{Owner: "acme", Name: "tools"}
+	queued, err := client.HasMergeQueue(project, "main")
+	assert.Equal(t, nil, err)
+	assert.Equal(t, true, queued)
+
+	queued, err = client.HasMergeQueue(project, "release/1.x")
+	assert.Equal(t, nil, err)
+	assert.Equal(t, false, queued)
+
+	queued, err = client.HasMergeQueue(project, "unknown")
+	assert.Equal(t, nil, err)
+	assert.Equal(t, false, queued)
+}
+
+func TestClient_EnqueuePullRequest(t *testing.T) {
+	client, server := githubtest.NewClient()
+	defer server.Close()
+	server.Handle(githubtest.Fixture{
+		Method: "POST",
+		Path:   "/graphql",
+		Body:   json.RawMessage(`{"data": {"enqueuePullRequest": {"mergeQueueEntry": {"position": 3, "state": "QUEUED"}}}}`),
+	})
+
+	entry, err := client.EnqueuePullRequest(&github.PullRequest{Number: 12, NodeId: "PR_12"})
+	assert.Equal(t, nil, err)
+	assert.Equal(t, github.MergeQueueEntry{Position: 3, State: "QUEUED"}, *entry)
+
+	payload := struct {
+		Variables map[string]string `json:"variables"`
+	}{}
+	assert.Equal(t, nil, server.AssertRequested(t, "POST", "/graphql").Unmarshal(&payload))
+	assert.Equal(t, "PR_12", payload.Variables["id"])
+}
+
+func TestClient_FetchMergeQueueStatus(t *testing.T) {
+	client, server := githubtest.NewClient()
+	defer server.Close()
+	server.Handle(githubtest.Fixture{
+		Method: "POST",
+		Path:   "/graphql",
+		Body:   json.RawMessage(`{"data": {"repository": {"pullRequest": {"merged": false, "state": "OPEN", "mergeQueueEntry": {"position": 1, "state": "AWAITING_CHECKS"}}}}}`),
+	})
+
+	project := &github.Project{Owner: "acme", Name: "tools"}
+	status, err := client.FetchMergeQueueStatus(project, 12)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, false, status.Merged)
+	assert.Equal(t, 1, status.Entry.Position)
+
+	server.Handle(githubtest.Fixture{
+		Method: "POST",
+		Path:   "/graphql",
+		Body:   json.RawMessage(`{"data": {"repository": {"pullRequest": {"merged": true, "state": "MERGED", "mergeQueueEntry": null}}}}`),
+	})
+	status, err = client.FetchMergeQueueStatus(project, 12)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, true, status.Merged)
+	assert.Equal(t, (*github.MergeQueueEntry)(nil), status.Entry)
+}
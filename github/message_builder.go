@@ -11,6 +11,7 @@ type MessageBuilder struct {
 	Message           string
 	Edit              bool
 	commentedSections []string
+	commentedLines    []string
 	editor            *Editor
 }
 
@@ -18,24 +19,16 @@ func (b *MessageBuilder) AddCommentedSection(section string) {
 	b.commentedSections = append(b.commentedSections, section)
 }
 
-func (b *MessageBuilder) Extract() (title, body string, err error) {
-	content := b.Message
+// AddCommentedLines adds a section that is shown with every line prefixed with
+// the comment character after the other commented sections.
+func (b *MessageBuilder) AddCommentedLines(section string) {
+	b.commentedLines = append(b.commentedLines, section)
+}
 
-	if b.Edit {
-		b.editor, err = NewEditor(b.Filename, b.Title, content)
-		if err != nil {
-			return
-		}
-		for _, section := range b.commentedSections {
-			b.editor.AddCommentedSection(section)
-		}
-		content, err = b.editor.EditContent()
-		if err != nil {
-			return
-		}
-	} else {
-		nl := regexp.MustCompile(`\r?\n`)
-		content = nl.ReplaceAllString(content, "\n")
+func (b *MessageBuilder) Extract() (title, body string, err error) {
+	content, err := b.ExtractText()
+	if err != nil {
+		return
 	}
 
 	parts := strings.SplitN(content, "\n\n", 2)
@@ -53,6 +46,30 @@ func (b *MessageBuilder) Extract() (title, body string, err error) {
 	return
 }
 
+// ExtractText returns the message, edited in a text editor if Edit is set,
+// without splitting it into a title and a body.
+func (b *MessageBuilder) ExtractText() (content string, err error) {
+	content = b.Message
+
+	if b.Edit {
+		b.editor, err = NewEditor(b.Filename, b.Title, content)
+		if err != nil {
+			return
+		}
+		for _, section := range b.commentedSections {
+			b.editor.AddCommentedSection(section)
+		}
+		for _, section := range b.commentedLines {
+			b.editor.AddCommentedLines(section)
+		}
+		content, err = b.editor.EditContent()
+	} else {
+		nl := regexp.MustCompile(`\r?\n`)
+		content = nl.ReplaceAllString(content, "\n")
+	}
+	return
+}
+
 func (b *MessageBuilder) Cleanup() {
 	if b.editor != nil {
 		b.editor.DeleteFile()
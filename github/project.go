@@ -22,6 +22,40 @@ func (p Project) String() string {
 	return fmt.Sprintf("%s/%s", p.Owner, p.Name)
 }
 
+var defaultBranchCache = map[string]string{}
+
+// DefaultBranch returns the name of the default branch of the repository. It's
+// read from the HEAD of a git remote for the repository if there is one, and
+// otherwise looked up with the API. The result of the lookup is then recorded
+// as the HEAD of the remote, so that later calls don't need network access.
+func (p *Project) DefaultBranch() (string, error) {
+	var remote *Remote
+	if localRepo, err := LocalRepo(); err == nil {
+		remote, _ = localRepo.RemoteForProject(p)
+	}
+	if remote != nil {
+		if name := remoteHead(remote); name != "" {
+			return name, nil
+		}
+	}
+
+	key := strings.ToLower(fmt.Sprintf("%s/%s", p.Host, p))
+	name, ok := defaultBranchCache[key]
+	if !ok {
+		repo, err := NewClient(p.Host).Repository(p)
+		if err != nil {
+			return "", err
+		}
+		name = repo.DefaultBranch
+		defaultBranchCache[key] = name
+	}
+
+	if remote != nil {
+		setRemoteHead(remote, name)
+	}
+	return name, nil
+}
+
 func (p *Project) SameAs(other *Project) bool {
 	return strings.ToLower(p.Owner) == strings.ToLower(other.Owner) &&
 		strings.ToLower(p.Name) == strings.ToLower(other.Name) &&
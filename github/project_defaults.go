@@ -0,0 +1,78 @@
+package github
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/github/hub/git"
+)
+
+// ProjectDefaultNames lists the settings that can be remembered per project.
+var ProjectDefaultNames = []string{"base", "reviewers", "labels"}
+
+// ProjectDefaults are what pull requests for a repository are opened with
+// unless told otherwise. They are stored in the global git config as
+// "hub.<OWNER>/<REPO>.<NAME>".
+type ProjectDefaults struct {
+	Base      string
+	Reviewers []string
+	Labels    []string
+}
+
+func projectDefaultKey(project *Project, name string) string {
+	return strings.ToLower(fmt.Sprintf("hub.%s/%s.%s", project.Owner, project.Name, name))
+}
+
+func isProjectDefaultName(name string) bool {
+	for _, n := range ProjectDefaultNames {
+		if n == name {
+			return true
+		}
+	}
+	return false
+}
+
+func LoadProjectDefaults(project *Project) *ProjectDefaults {
+	defaults := &ProjectDefaults{}
+	if project == nil {
+		return defaults
+	}
+	defaults.Base, _ = git.Config(projectDefaultKey(project, "base"))
+	if value, err := git.Config(projectDefaultKey(project, "reviewers")); err == nil {
+		defaults.Reviewers = splitProjectDefault(value)
+	}
+	if value, err := git.Config(projectDefaultKey(project, "labels")); err == nil {
+		defaults.Labels = splitProjectDefault(value)
+	}
+	return defaults
+}
+
+func splitProjectDefault(value string) []string {
+	values := []string{}
+	for _, v := range strings.Split(value, ",") {
+		if v = strings.TrimSpace(v); v != "" {
+			values = append(values, v)
+		}
+	}
+	return values
+}
+
+func SetProjectDefault(project *Project, name, value string) error {
+	if !isProjectDefaultName(name) {
+		return fmt.Errorf("unknown project setting %q; expected one of: %s", name, strings.Join(ProjectDefaultNames, ", "))
+	}
+	if name != "base" {
+		value = strings.Join(splitProjectDefault(value), ",")
+	}
+	if value == "" {
+		return fmt.Errorf("the value for %q can't be empty", name)
+	}
+	return git.SetGlobalConfig(projectDefaultKey(project, name), value)
+}
+
+func UnsetProjectDefault(project *Project, name string) error {
+	if !isProjectDefaultName(name) {
+		return fmt.Errorf("unknown project setting %q; expected one of: %s", name, strings.Join(ProjectDefaultNames, ", "))
+	}
+	return git.UnsetGlobalConfig(projectDefaultKey(project, name))
+}
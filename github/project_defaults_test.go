@@ -0,0 +1,51 @@
+package github
+
+import (
+	"testing"
+
+	"github.com/bmizerany/assert"
+	"github.com/github/hub/fixtures"
+	"github.com/github/hub/git"
+)
+
+func TestProjectDefaults(t *testing.T) {
+	repo := fixtures.SetupTestRepo()
+	defer repo.TearDown()
+
+	project := &Project{Owner: "Mislav", Name: "Dotfiles", Host: "github.com"}
+	assert.Equal(t, ProjectDefaults{}, *LoadProjectDefaults(project))
+
+	assert.Equal(t, nil, SetProjectDefault(project, "base", "develop"))
+	assert.Equal(t, nil, SetProjectDefault(project, "reviewers", " josh, github/core ,"))
+	assert.Equal(t, nil, SetProjectDefault(project, "labels", "needs-review"))
+
+	value, _ := git.GlobalConfig("hub.mislav/dotfiles.reviewers")
+	assert.Equal(t, "josh,github/core", value)
+
+	// owner and name are matched regardless of case
+	defaults := LoadProjectDefaults(&Project{Owner: "mislav", Name: "dotfiles", Host: "github.com"})
+	assert.Equal(t, ProjectDefaults{
+		Base:      "develop",
+		Reviewers: []string{"josh", "github/core"},
+		Labels:    []string{"needs-review"},
+	}, *defaults)
+
+	assert.Equal(t, ProjectDefaults{}, *LoadProjectDefaults(&Project{Owner: "mislav", Name: "other", Host: "github.com"}))
+
+	assert.Equal(t, nil, UnsetProjectDefault(project, "base"))
+	assert.Equal(t, "", LoadProjectDefaults(project).Base)
+	assert.Equal(t, nil, UnsetProjectDefault(project, "base"))
+}
+
+func TestProjectDefaults_Invalid(t *testing.T) {
+	project := &Project{Owner: "mislav", Name: "dotfiles", Host: "github.com"}
+
+	err := SetProjectDefault(project, "head", "feature")
+	assert.Equal(t, `unknown project setting "head"; expected one of: base, reviewers, labels`, err.Error())
+
+	err = SetProjectDefault(project, "labels", " , ")
+	assert.Equal(t, `the value for "labels" can't be empty`, err.Error())
+
+	err = UnsetProjectDefault(project, "assignees")
+	assert.NotEqual(t, nil, err)
+}
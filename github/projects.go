@@ -0,0 +1,126 @@
+package github
+
+import (
+	"fmt"
+	"strings"
+)
+
+// ProjectBoard is a classic project board of a repository or an organization.
+type ProjectBoard struct {
+	Id      int64  `json:"id"`
+	Name    string `json:"name"`
+	HtmlUrl string `json:"html_url"`
+}
+
+// ProjectColumn is a column of a classic project board.
+type ProjectColumn struct {
+	Id   int64  `json:"id"`
+	Name string `json:"name"`
+}
+
+// FindProjectColumn looks up the column named column of the open project board
+// named board, first among the boards of project and then among those of the
+// organization that owns it. Names are matched without regard to case.
+func (client *Client) FindProjectColumn(project *Project, board, column string) (*ProjectColumn, error) {
+	paths := []string{
+		fmt.Sprintf("repos/%s/%s/projects?state=open&per_page=100", project.Owner, project.Name),
+		fmt.Sprintf("orgs/%s/projects?state=open&per_page=100", project.Owner),
+	}
+	var found *ProjectBoard
+	for _, path := range paths {
+		boards := []ProjectBoard{}
+		appendPage := func(res *simpleResponse) error {
+			page := []ProjectBoard{}
+			err := res.Unmarshal(&page)
+			boards = append(boards, page...)
+			return err
+		}
+		// repositories with projects turned off and owners that aren't
+		// organizations have no boards to look up
+		if err := client.fetchProjectsPages(path, "fetching projects", appendPage, 404, 410); err != nil {
+			return nil, err
+		}
+		for i, b := range boards {
+			if strings.EqualFold(b.Name, board) {
+				found = &boards[i]
+				break
+			}
+		}
+		if found != nil {
+			break
+		}
+	}
+	if found == nil {
+		return nil, fmt.Errorf("no open project named '%s' in %s or its organization", board, project)
+	}
+
+	columns := []ProjectColumn{}
+	appendPage := func(res *simpleResponse) error {
+		page := []ProjectColumn{}
+		err := res.Unmarshal(&page)
+		columns = append(columns, page...)
+		return err
+	}
+	if err := client.fetchProjectsPages(fmt.Sprintf("projects/%d/columns?per_page=100", found.Id), "fetching project columns", appendPage); err != nil {
+		return nil, err
+	}
+	names := []string{}
+	for i, c := range columns {
+		if strings.EqualFold(c.Name, column) {
+			return &columns[i], nil
+		}
+		names = append(names, c.Name)
+	}
+	return nil, fmt.Errorf("no column named '%s' in project '%s'; its columns are: %s", column, found.Name, strings.Join(names, ", "))
+}
+
+// fetchProjectsPages passes each page of the listing at path to appendPage.
+// Responses with one of the skipped statuses are treated as an empty listing.
+func (client *Client) fetchProjectsPages(path, action string, appendPage func(*simpleResponse) error, skipped ...int) error {
+	api, err := client.simpleApi()
+	if err != nil {
+		return err
+	}
+
+	for path != "" {
+		res, err := api.GetFile(path, projectsType)
+		if err == nil {
+			for _, status := range skipped {
+				if res.StatusCode == status {
+					res.discard()
+					return nil
+				}
+			}
+		}
+		if err = checkStatus(200, action, res, err); err != nil {
+			return err
+		}
+		path = res.Link("next")
+
+		if err = appendPage(res); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// AddProjectCard adds an issue or a pull request to a column of a project
+// board. The id is the numeric ID of the issue, not its number.
+func (client *Client) AddProjectCard(column *ProjectColumn, issueID int64, contentType string) error {
+	api, err := client.simpleApi()
+	if err != nil {
+		return err
+	}
+
+	params := map[string]interface{}{
+		"content_id":   issueID,
+		"content_type": contentType,
+	}
+	res, err := api.PostJSONPreview(fmt.Sprintf("projects/columns/%d/cards", column.Id), params, projectsType)
+	if err = checkStatus(201, "adding project card", res, err); err != nil {
+		return err
+	}
+
+	res.discard()
+	return nil
+}
@@ -0,0 +1,62 @@
+package github_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/bmizerany/assert"
+	"github.com/github/hub/github"
+	"github.com/github/hub/github/githubtest"
+)
+
+func TestClient_FindProjectColumn(t *testing.T) {
+	client, server := githubtest.NewClient()
+	defer server.Close()
+	server.Handle(githubtest.Fixture{
+		Path:  "/repos/acme/tools/projects",
+		Items: []json.RawMessage{json.RawMessage(`{"id": 1, "name": "Roadmap"}`)},
+	})
+	server.Handle(githubtest.Fixture{
+		Path:    "/orgs/acme/projects",
+		PerPage: 1,
+		Items: []json.RawMessage{
+			json.RawMessage(`{"id": 2, "name": "Ops"}`),
+			json.RawMessage(`{"id": 3, "name": "Triage"}`),
+		},
+	})
+	server.Handle(githubtest.Fixture{
+		Path: "/projects/3/columns",
+		Body: json.RawMessage(`[{"id": 30, "name": "To do"}, {"id": 31, "name": "Done"}]`),
+	})
+
+	project := &github.Project{Owner: "acme", Name: "tools"}
+	column, err := client.FindProjectColumn(project, "triage", "to do")
+	assert.Equal(t, nil, err)
+	assert.Equal(t, int64(30), column.Id)
+	server.AssertRequests(t,
+		"GET /repos/acme/tools/projects",
+		"GET /orgs/acme/projects",
+		"GET /orgs/acme/projects",
+		"GET /projects/3/columns",
+	)
+	assert.Equal(t, "application/vnd.github.inertia-preview+json;charset=utf-8", server.Requests()[0].Header.Get("Accept"))
+
+	_, err = client.FindProjectColumn(project, "Triage", "Doing")
+	assert.Equal(t, "no column named 'Doing' in project 'Triage'; its columns are: To do, Done", err.Error())
+
+	_, err = client.FindProjectColumn(&github.Project{Owner: "mislav", Name: "dotfiles"}, "Roadmap", "To do")
+	assert.Equal(t, "no open project named 'Roadmap' in mislav/dotfiles or its organization", err.Error())
+}
+
+func TestClient_AddProjectCard(t *testing.T) {
+	client, server := githubtest.NewClient()
+	defer server.Close()
+	server.Handle(githubtest.Fixture{Method: "POST", Path: "/projects/columns/30/cards", Status: 201, Body: json.RawMessage(`{}`)})
+
+	err := client.AddProjectCard(&github.ProjectColumn{Id: 30}, 1234, "Issue")
+	assert.Equal(t, nil, err)
+
+	card := map[string]interface{}{}
+	assert.Equal(t, nil, server.AssertRequested(t, "POST", "/projects/columns/30/cards").Unmarshal(&card))
+	assert.Equal(t, map[string]interface{}{"content_id": float64(1234), "content_type": "Issue"}, card)
+}
@@ -0,0 +1,113 @@
+package github
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/github/hub/git"
+)
+
+// FindPullRequestForBranch looks up the pull request to project that was
+// opened from branchName of owner, or of the authenticated user if owner is
+// empty. Open pull requests win over closed ones, and the most recently
+// created one wins among those. It returns nil if there's no such pull
+// request. Lookups are remembered for as long as the client lives.
+func (client *Client) FindPullRequestForBranch(project *Project, branchName, owner string) (*PullRequest, error) {
+	if owner == "" {
+		if err := client.ensureAccessToken(); err != nil {
+			return nil, err
+		}
+		if client.Host.User != "" {
+			owner = client.Host.User
+		} else {
+			user, err := client.CurrentUser()
+			if err != nil {
+				return nil, err
+			}
+			owner = user.Login
+		}
+	}
+
+	head := fmt.Sprintf("%s:%s", owner, branchName)
+	key := strings.ToLower(fmt.Sprintf("%s/%s %s", project.Owner, project.Name, head))
+
+	client.branchPullsMutex.Lock()
+	defer client.branchPullsMutex.Unlock()
+	if pr, found := client.branchPulls[key]; found {
+		return pr, nil
+	}
+
+	pulls, err := client.FetchPullRequests(project, map[string]interface{}{
+		"head":  head,
+		"state": "all",
+	}, 0, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var found *PullRequest
+	for i, pr := range pulls {
+		if found == nil ||
+			pr.State == "open" && found.State != "open" ||
+			pr.State == found.State && pr.CreatedAt.After(found.CreatedAt) {
+			found = &pulls[i]
+		}
+	}
+
+	if client.branchPulls == nil {
+		client.branchPulls = map[string]*PullRequest{}
+	}
+	client.branchPulls[key] = found
+	return found, nil
+}
+
+// PushOwner returns the owner of the GitHub repository that the branch is
+// pushed to, along with the name of the branch there. That's the push remote
+// of the branch if one is configured, or else its upstream. The owner is empty
+// when neither is a GitHub repository.
+//
+// Branches that 'hub pr checkout' created from "refs/pull/<NUMBER>/head" have
+// no push target; use PullRequestRef for them instead.
+func (b *Branch) PushOwner() (owner, name string) {
+	name = b.ShortName()
+	remoteName, _ := git.Config(fmt.Sprintf("branch.%s.pushRemote", name))
+	if remoteName == "" {
+		remoteName, _ = git.Config("remote.pushDefault")
+	}
+	if remoteName == "" {
+		remoteName, _ = git.Config(fmt.Sprintf("branch.%s.remote", name))
+		if merge, _ := git.Config(fmt.Sprintf("branch.%s.merge", name)); remoteName != "" && strings.HasPrefix(merge, "refs/heads/") {
+			name = strings.TrimPrefix(merge, "refs/heads/")
+		}
+	}
+
+	if remoteName == "" || remoteName == "." {
+		return
+	}
+	if remote, err := b.Repo.RemoteByName(remoteName); err == nil {
+		if project, err := remote.PushProject(); err == nil {
+			owner = project.Owner
+		}
+	} else if u, err := git.ParseURL(remoteName); err == nil {
+		// 'hub pr checkout' configures the URL of a fork that has no remote
+		if project, err := NewProjectFromURL(u); err == nil {
+			owner = project.Owner
+		}
+	}
+	return
+}
+
+var pullRequestRefRegexp = regexp.MustCompile(`^refs/pull/(\d+)/head$`)
+
+// PullRequestRef returns the number of the pull request that the branch
+// tracks the head of, or 0 if it doesn't track one.
+func (b *Branch) PullRequestRef() int {
+	merge, _ := git.Config(fmt.Sprintf("branch.%s.merge", b.ShortName()))
+	if match := pullRequestRefRegexp.FindStringSubmatch(merge); match != nil {
+		number, _ := strconv.Atoi(match[1])
+		return number
+	}
+	return 0
+}
@@ -0,0 +1,96 @@
+package github
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/bmizerany/assert"
+	"github.com/github/hub/cmd"
+	"github.com/github/hub/fixtures"
+)
+
+func TestClient_FindPullRequestForBranch(t *testing.T) {
+	requests := 0
+	client, cleanup := setupTestClient(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/repos/github/hub/pulls", r.URL.Path)
+		assert.Equal(t, "all", r.URL.Query().Get("state"))
+		requests++
+
+		switch r.URL.Query().Get("head") {
+		case "mislav:feature":
+			w.Write([]byte(`[
+				{"number": 10, "state": "closed", "created_at": "2024-01-01T00:00:00Z"},
+				{"number": 12, "state": "open", "created_at": "2024-02-01T00:00:00Z"},
+				{"number": 11, "state": "closed", "created_at": "2024-03-01T00:00:00Z"}
+			]`))
+		case "hubot:fix":
+			w.Write([]byte(`[
+				{"number": 20, "state": "closed", "created_at": "2024-01-01T00:00:00Z"},
+				{"number": 21, "state": "closed", "created_at": "2024-03-01T00:00:00Z"}
+			]`))
+		default:
+			w.Write([]byte(`[]`))
+		}
+	})
+	defer cleanup()
+	client.Host.User = "hubot"
+
+	project := &Project{Owner: "github", Name: "hub"}
+	pr, err := client.FindPullRequestForBranch(project, "feature", "mislav")
+	assert.Equal(t, nil, err)
+	assert.Equal(t, 12, pr.Number)
+
+	pr, err = client.FindPullRequestForBranch(project, "fix", "")
+	assert.Equal(t, nil, err)
+	assert.Equal(t, 21, pr.Number)
+
+	pr, err = client.FindPullRequestForBranch(project, "nothing", "mislav")
+	assert.Equal(t, nil, err)
+	assert.T(t, pr == nil)
+
+	// lookups are remembered
+	pr, _ = client.FindPullRequestForBranch(project, "feature", "mislav")
+	assert.Equal(t, 12, pr.Number)
+	assert.Equal(t, 3, requests)
+}
+
+func TestBranch_PushOwner(t *testing.T) {
+	repo := fixtures.SetupTestRepo()
+	defer repo.TearDown()
+	repo.AddRemote("upstream", "https://github.com/github/hub.git", "")
+	repo.AddRemote("fork", "https://github.com/mislav/hub.git", "")
+
+	gitConfig := func(name, value string) {
+		if _, err := cmd.New("git").WithArgs("config", name, value).CombinedOutput(); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	localRepo, _ := LocalRepo()
+	branch := &Branch{localRepo, "refs/heads/topic"}
+	owner, name := branch.PushOwner()
+	assert.Equal(t, "", owner)
+	assert.Equal(t, "topic", name)
+
+	gitConfig("branch.topic.remote", "upstream")
+	gitConfig("branch.topic.merge", "refs/heads/remote-topic")
+	owner, name = branch.PushOwner()
+	assert.Equal(t, "github", owner)
+	assert.Equal(t, "remote-topic", name)
+
+	gitConfig("branch.topic.pushRemote", "fork")
+	owner, name = branch.PushOwner()
+	assert.Equal(t, "mislav", owner)
+	assert.Equal(t, "topic", name)
+
+	checkedOut := &Branch{localRepo, "refs/heads/hubot-master"}
+	gitConfig("branch.hubot-master.remote", "https://github.com/hubot/hub.git")
+	gitConfig("branch.hubot-master.merge", "refs/heads/master")
+	owner, name = checkedOut.PushOwner()
+	assert.Equal(t, "hubot", owner)
+	assert.Equal(t, "master", name)
+	assert.Equal(t, 0, checkedOut.PullRequestRef())
+
+	gitConfig("branch.hubot-master.merge", "refs/pull/42/head")
+	assert.Equal(t, 42, checkedOut.PullRequestRef())
+}
@@ -0,0 +1,198 @@
+package github
+
+import (
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// The readiness of a pull request to be merged, as reported by
+// PullRequestReadiness.
+const (
+	PullRequestReady            = "ready"
+	PullRequestBlockedByChecks  = "blocked: checks"
+	PullRequestBlockedByReviews = "blocked: reviews"
+	PullRequestBlocked          = "blocked"
+	PullRequestBehind           = "behind"
+	PullRequestDraft            = "draft"
+	PullRequestPending          = "pending"
+	PullRequestConflicts        = "conflicts"
+)
+
+// mergeableStatePollInterval is how long to give GitHub to compute whether a
+// pull request can be merged before asking again.
+var mergeableStatePollInterval = time.Second
+
+// RequiredStatusChecks returns the names of the status checks that the branch
+// protection of branch requires to pass before merging. The list is empty if
+// the branch isn't protected or doesn't require any checks. Lookups are
+// remembered for as long as the client lives.
+func (client *Client) RequiredStatusChecks(project *Project, branch string) ([]string, error) {
+	key := strings.ToLower(fmt.Sprintf("%s/%s %s", project.Owner, project.Name, branch))
+	client.requiredChecksMutex.Lock()
+	defer client.requiredChecksMutex.Unlock()
+	if checks, found := client.requiredChecks[key]; found {
+		return checks, nil
+	}
+
+	api, err := client.simpleApi()
+	if err != nil {
+		return nil, err
+	}
+
+	// unlike the branch protection endpoint, this one doesn't require admin
+	// access to the repository
+	res, err := api.Get(fmt.Sprintf("repos/%s/%s/branches/%s", project.Owner, project.Name, url.PathEscape(branch)))
+	if err = checkStatus(200, "fetching branch protection", res, err); err != nil {
+		return nil, err
+	}
+
+	branchInfo := struct {
+		Protection struct {
+			RequiredStatusChecks requiredStatusChecks `json:"required_status_checks"`
+		} `json:"protection"`
+	}{}
+	if err = res.Unmarshal(&branchInfo); err != nil {
+		return nil, err
+	}
+	checks := branchInfo.Protection.RequiredStatusChecks.names()
+
+	if client.requiredChecks == nil {
+		client.requiredChecks = map[string][]string{}
+	}
+	client.requiredChecks[key] = checks
+	return checks, nil
+}
+
+// ProtectedStatusChecks returns the status checks that the branch protection
+// of branch requires, as listed by the protection settings of the branch. The
+// list is empty if the branch isn't protected or doesn't require any checks.
+// Reading the settings takes admin access to the repository; without it,
+// readable is false.
+func (client *Client) ProtectedStatusChecks(project *Project, branch string) (checks []string, readable bool, err error) {
+	api, err := client.simpleApi()
+	if err != nil {
+		return
+	}
+
+	res, err := api.Get(fmt.Sprintf("repos/%s/%s/branches/%s/protection/required_status_checks", project.Owner, project.Name, url.PathEscape(branch)))
+	if err == nil && (res.StatusCode == 403 || res.StatusCode == 404) {
+		errInfo, infoErr := res.ErrorInfo()
+		if infoErr == nil && res.StatusCode == 404 && (errInfo.Message == "Branch not protected" || errInfo.Message == "Required status checks not enabled") {
+			return []string{}, true, nil
+		}
+		return nil, false, nil
+	}
+	if err = checkStatus(200, "fetching required status checks", res, err); err != nil {
+		return
+	}
+
+	required := requiredStatusChecks{}
+	if err = res.Unmarshal(&required); err != nil {
+		return
+	}
+	return required.names(), true, nil
+}
+
+// requiredStatusChecks is the "required_status_checks" setting of a branch
+// protection. Older protections list the checks as contexts only.
+type requiredStatusChecks struct {
+	Contexts []string `json:"contexts"`
+	Checks   []struct {
+		Context string `json:"context"`
+	} `json:"checks"`
+}
+
+// names returns the required checks, each once, in the order they're listed.
+func (required requiredStatusChecks) names() []string {
+	checks := []string{}
+	seen := map[string]bool{}
+	for _, context := range required.Contexts {
+		if !seen[context] {
+			seen[context] = true
+			checks = append(checks, context)
+		}
+	}
+	for _, check := range required.Checks {
+		if !seen[check.Context] {
+			seen[check.Context] = true
+			checks = append(checks, check.Context)
+		}
+	}
+	return checks
+}
+
+// PullRequestReadiness tells whether pr is ready to be merged: whether the
+// status checks that its base branch requires have passed, whether its review
+// decision allows merging, and whether GitHub considers it mergeable. Each of
+// these takes API requests, so the evaluation stops at the first one that
+// blocks the pull request. Pull requests that aren't open yield an empty
+// string.
+func (client *Client) PullRequestReadiness(project *Project, pr *PullRequest) (string, error) {
+	if pr.State != "open" || pr.Base == nil || pr.Head == nil {
+		return "", nil
+	}
+
+	required, err := client.RequiredStatusChecks(project, pr.Base.Ref)
+	if err != nil {
+		return "", err
+	}
+	if len(required) > 0 {
+		status, err := client.FetchCIStatus(project, pr.Head.Sha)
+		if err != nil {
+			return "", err
+		}
+		passed := map[string]bool{}
+		for _, check := range status.Statuses {
+			if check.State == "success" || check.State == "neutral" {
+				passed[check.Context] = true
+			}
+		}
+		for _, context := range required {
+			if !passed[context] {
+				return PullRequestBlockedByChecks, nil
+			}
+		}
+	}
+
+	statuses, err := client.FetchPullRequestStatuses(project, []int{pr.Number})
+	if err != nil {
+		return "", err
+	}
+	if status := statuses[pr.Number]; status != nil && status.ReviewDecision != "" && status.ReviewDecision != "APPROVED" {
+		return PullRequestBlockedByReviews, nil
+	}
+
+	// the listing of pull requests doesn't say whether they can be merged, and
+	// GitHub computes that in the background the first time it's asked
+	mergeableState := pr.MergeableState
+	for attempt := 0; attempt < 2 && (mergeableState == "" || mergeableState == "unknown"); attempt++ {
+		if attempt > 0 {
+			time.Sleep(mergeableStatePollInterval)
+		}
+		full, err := client.PullRequest(project, strconv.Itoa(pr.Number))
+		if err != nil {
+			return "", err
+		}
+		mergeableState = full.MergeableState
+	}
+
+	switch mergeableState {
+	case "clean", "has_hooks", "unstable":
+		// "unstable" means that only checks that aren't required have failed
+		return PullRequestReady, nil
+	case "dirty":
+		return PullRequestConflicts, nil
+	case "behind":
+		return PullRequestBehind, nil
+	case "draft":
+		return PullRequestDraft, nil
+	case "", "unknown":
+		// GitHub is still working out whether it can be merged
+		return PullRequestPending, nil
+	default:
+		return PullRequestBlocked, nil
+	}
+}
@@ -0,0 +1,147 @@
+package github
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/bmizerany/assert"
+)
+
+func TestClient_PullRequestReadiness(t *testing.T) {
+	mergeableStatePollInterval = 0
+	var mutex sync.Mutex
+	requests := map[string]int{}
+
+	client, cleanup := setupTestClient(func(w http.ResponseWriter, r *http.Request) {
+		mutex.Lock()
+		requests[r.URL.Path]++
+		count := requests[r.URL.Path]
+		mutex.Unlock()
+
+		switch r.URL.Path {
+		case "/repos/github/hub/branches/main":
+			fmt.Fprint(w, `{"protection":{"required_status_checks":{"contexts":["build"],"checks":[{"context":"build"},{"context":"lint"}]}}}`)
+		case "/repos/github/hub/commits/failing/status", "/repos/github/hub/commits/passing/status":
+			fmt.Fprint(w, `{"state":"success","statuses":[{"state":"success","context":"build"}]}`)
+		case "/repos/github/hub/commits/failing/check-runs":
+			fmt.Fprint(w, `{"check_runs":[{"name":"lint","status":"completed","conclusion":"failure"}]}`)
+		case "/repos/github/hub/commits/passing/check-runs":
+			fmt.Fprint(w, `{"check_runs":[{"name":"lint","status":"completed","conclusion":"success"}]}`)
+		case "/graphql":
+			payload := struct {
+				Query string `json:"query"`
+			}{}
+			json.NewDecoder(r.Body).Decode(&payload)
+			number, decision := 3, "APPROVED"
+			if strings.Contains(payload.Query, "pullRequest(number: 2)") {
+				number, decision = 2, "CHANGES_REQUESTED"
+			}
+			fmt.Fprintf(w, `{"data":{"repository":{"pr":{"number":%d,"reviewDecision":%q,"commits":{"nodes":[]}}}}}`, number, decision)
+		case "/repos/github/hub/pulls/7":
+			fmt.Fprint(w, `{"number":7,"mergeable_state":"unknown"}`)
+		case "/repos/github/hub/pulls/3":
+			if count == 1 {
+				fmt.Fprint(w, `{"number":3,"mergeable_state":"unknown"}`)
+			} else {
+				fmt.Fprint(w, `{"number":3,"mergeable_state":"clean"}`)
+			}
+		default:
+			t.Errorf("unexpected request to %s", r.URL.Path)
+			w.WriteHeader(404)
+		}
+	})
+	defer cleanup()
+
+	project := &Project{Owner: "github", Name: "hub"}
+	pr := func(number int, sha string) *PullRequest {
+		return &PullRequest{
+			Number: number,
+			State:  "open",
+			Base:   &PullRequestSpec{Ref: "main"},
+			Head:   &PullRequestSpec{Sha: sha},
+		}
+	}
+
+	readiness, err := client.PullRequestReadiness(project, pr(1, "failing"))
+	assert.Equal(t, nil, err)
+	assert.Equal(t, PullRequestBlockedByChecks, readiness)
+	assert.Equal(t, 0, requests["/graphql"])
+
+	readiness, err = client.PullRequestReadiness(project, pr(2, "passing"))
+	assert.Equal(t, nil, err)
+	assert.Equal(t, PullRequestBlockedByReviews, readiness)
+	assert.Equal(t, 0, requests["/repos/github/hub/pulls/2"])
+
+	readiness, err = client.PullRequestReadiness(project, pr(3, "passing"))
+	assert.Equal(t, nil, err)
+	assert.Equal(t, PullRequestReady, readiness)
+	assert.Equal(t, 2, requests["/repos/github/hub/pulls/3"])
+
+	conflicting := pr(4, "passing")
+	conflicting.MergeableState = "dirty"
+	readiness, err = client.PullRequestReadiness(project, conflicting)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, PullRequestConflicts, readiness)
+
+	for state, expected := range map[string]string{
+		"behind":  PullRequestBehind,
+		"blocked": PullRequestBlocked,
+		"draft":   PullRequestDraft,
+	} {
+		blocked := pr(6, "passing")
+		blocked.MergeableState = state
+		readiness, err = client.PullRequestReadiness(project, blocked)
+		assert.Equal(t, nil, err)
+		assert.Equal(t, expected, readiness)
+	}
+
+	closed := pr(5, "passing")
+	closed.State = "closed"
+	readiness, err = client.PullRequestReadiness(project, closed)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, "", readiness)
+
+	readiness, err = client.PullRequestReadiness(project, pr(7, "passing"))
+	assert.Equal(t, nil, err)
+	assert.Equal(t, PullRequestPending, readiness)
+	assert.Equal(t, 2, requests["/repos/github/hub/pulls/7"])
+
+	assert.Equal(t, 1, requests["/repos/github/hub/branches/main"])
+}
+
+func TestClient_ProtectedStatusChecks(t *testing.T) {
+	client, cleanup := setupTestClient(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/repos/github/hub/branches/main/protection/required_status_checks":
+			fmt.Fprint(w, `{"contexts":["build"],"checks":[{"context":"build"},{"context":"lint"}]}`)
+		case "/repos/github/hub/branches/wip/protection/required_status_checks":
+			w.WriteHeader(http.StatusNotFound)
+			fmt.Fprint(w, `{"message":"Branch not protected"}`)
+		case "/repos/github/hub/branches/secret/protection/required_status_checks":
+			w.WriteHeader(http.StatusNotFound)
+			fmt.Fprint(w, `{"message":"Not Found"}`)
+		default:
+			t.Errorf("unexpected request to %s", r.URL.Path)
+		}
+	})
+	defer cleanup()
+
+	project := &Project{Owner: "github", Name: "hub"}
+	checks, readable, err := client.ProtectedStatusChecks(project, "main")
+	assert.Equal(t, nil, err)
+	assert.T(t, readable)
+	assert.Equal(t, []string{"build", "lint"}, checks)
+
+	checks, readable, err = client.ProtectedStatusChecks(project, "wip")
+	assert.Equal(t, nil, err)
+	assert.T(t, readable)
+	assert.Equal(t, []string{}, checks)
+
+	_, readable, err = client.ProtectedStatusChecks(project, "secret")
+	assert.Equal(t, nil, err)
+	assert.T(t, !readable)
+}
@@ -0,0 +1,207 @@
+package github
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// pullRequestStatusBatch is how many pull requests are looked up with a single
+// GraphQL query.
+const pullRequestStatusBatch = 50
+
+// PullRequestStatus holds the checks of the head commit of a pull request and
+// its review decision, such as "APPROVED", "CHANGES_REQUESTED" or
+// "REVIEW_REQUIRED". The review decision is empty for pull requests that
+// don't require a review.
+type PullRequestStatus struct {
+	Number         int
+	ReviewDecision string
+	Checks         []CIStatus
+}
+
+type pullRequestStatusNode struct {
+	Number         int    `json:"number"`
+	ReviewDecision string `json:"reviewDecision"`
+	Commits        struct {
+		Nodes []struct {
+			Commit struct {
+				StatusCheckRollup *struct {
+					Contexts struct {
+						Nodes []statusCheckContextNode `json:"nodes"`
+					} `json:"contexts"`
+				} `json:"statusCheckRollup"`
+			} `json:"commit"`
+		} `json:"nodes"`
+	} `json:"commits"`
+}
+
+// statusCheckContextNode is either a check run or a commit status in the
+// status check rollup of a commit.
+type statusCheckContextNode struct {
+	Typename   string `json:"__typename"`
+	Name       string `json:"name"`
+	Status     string `json:"status"`
+	Conclusion string `json:"conclusion"`
+	Context    string `json:"context"`
+	State      string `json:"state"`
+	IsRequired bool   `json:"isRequired"`
+	// StartedAt and CompletedAt are only queried for checks of a single
+	// commit.
+	StartedAt   *time.Time `json:"startedAt"`
+	CompletedAt *time.Time `json:"completedAt"`
+}
+
+// FetchPullRequestStatuses looks up the checks and review decisions of pull
+// requests with one GraphQL query per batch of them, rather than a request
+// for each pull request.
+func (client *Client) FetchPullRequestStatuses(project *Project, numbers []int) (map[int]*PullRequestStatus, error) {
+	statuses := map[int]*PullRequestStatus{}
+	for start := 0; start < len(numbers); start += pullRequestStatusBatch {
+		end := start + pullRequestStatusBatch
+		if end > len(numbers) {
+			end = len(numbers)
+		}
+
+		fields := []string{}
+		for _, number := range numbers[start:end] {
+			fields = append(fields, fmt.Sprintf("pr%d: pullRequest(number: %d) { ...status }", number, number))
+		}
+		query := fmt.Sprintf(`query($owner: String!, $name: String!) {
+  repository(owner: $owner, name: $name) {
+    %s
+  }
+}
+fragment status on PullRequest {
+  number
+  reviewDecision
+  commits(last: 1) {
+    nodes {
+      commit {
+        statusCheckRollup {
+          contexts(first: 100) {
+            nodes {
+              __typename
+              ... on CheckRun { name status conclusion }
+              ... on StatusContext { context state }
+            }
+          }
+        }
+      }
+    }
+  }
+}`, strings.Join(fields, "\n    "))
+
+		data := struct {
+			Repository map[string]*pullRequestStatusNode `json:"repository"`
+		}{}
+		variables := map[string]interface{}{"owner": project.Owner, "name": project.Name}
+		if err := client.graphQL("fetching pull request statuses", query, variables, &data); err != nil {
+			return nil, err
+		}
+
+		for _, node := range data.Repository {
+			if node != nil {
+				statuses[node.Number] = node.status()
+			}
+		}
+	}
+	return statuses, nil
+}
+
+// status turns the checks of the GraphQL API into the states that the REST
+// API reports, e.g. "success" or "pending".
+func (node *pullRequestStatusNode) status() *PullRequestStatus {
+	status := &PullRequestStatus{
+		Number:         node.Number,
+		ReviewDecision: node.ReviewDecision,
+		Checks:         []CIStatus{},
+	}
+	for _, commit := range node.Commits.Nodes {
+		if commit.Commit.StatusCheckRollup == nil {
+			continue
+		}
+		for _, context := range commit.Commit.StatusCheckRollup.Contexts.Nodes {
+			status.Checks = append(status.Checks, context.ciStatus())
+		}
+	}
+	return status
+}
+
+// ciStatus turns a check of the GraphQL API into the state that the REST API
+// reports, e.g. "success" or "pending".
+func (context statusCheckContextNode) ciStatus() CIStatus {
+	if context.Typename == "CheckRun" {
+		checkRun := CheckRun{
+			Status:     strings.ToLower(context.Status),
+			Conclusion: strings.ToLower(context.Conclusion),
+			Name:       context.Name,
+		}
+		return CIStatus{
+			State:       checkRun.State(),
+			Context:     context.Name,
+			StartedAt:   context.StartedAt,
+			CompletedAt: context.CompletedAt,
+		}
+	}
+	state := strings.ToLower(context.State)
+	if state == "expected" {
+		state = "pending"
+	}
+	return CIStatus{State: state, Context: context.Context}
+}
+
+// FetchRequiredPullRequestChecks returns the checks of the head commit of a
+// pull request that are required for merging it, according to the status
+// check rollup of the GraphQL API. Unlike the branch protection settings, the
+// rollup is readable without admin access to the repository, but it only
+// knows about the required checks that have reported on the commit.
+func (client *Client) FetchRequiredPullRequestChecks(project *Project, number int) ([]CIStatus, error) {
+	query := `query($owner: String!, $name: String!, $number: Int!) {
+  repository(owner: $owner, name: $name) {
+    pullRequest(number: $number) {
+      commits(last: 1) {
+        nodes {
+          commit {
+            statusCheckRollup {
+              contexts(first: 100) {
+                nodes {
+                  __typename
+                  ... on CheckRun { name status conclusion startedAt completedAt isRequired(pullRequestNumber: $number) }
+                  ... on StatusContext { context state isRequired(pullRequestNumber: $number) }
+                }
+              }
+            }
+          }
+        }
+      }
+    }
+  }
+}`
+
+	data := struct {
+		Repository struct {
+			PullRequest *pullRequestStatusNode `json:"pullRequest"`
+		} `json:"repository"`
+	}{}
+	variables := map[string]interface{}{"owner": project.Owner, "name": project.Name, "number": number}
+	if err := client.graphQL("fetching required checks", query, variables, &data); err != nil {
+		return nil, err
+	}
+	if data.Repository.PullRequest == nil {
+		return nil, fmt.Errorf("Error fetching required checks: pull request #%d not found", number)
+	}
+
+	checks := []CIStatus{}
+	for _, commit := range data.Repository.PullRequest.Commits.Nodes {
+		if commit.Commit.StatusCheckRollup == nil {
+			continue
+		}
+		for _, context := range commit.Commit.StatusCheckRollup.Contexts.Nodes {
+			if context.IsRequired {
+				checks = append(checks, context.ciStatus())
+			}
+		}
+	}
+	return checks, nil
+}
@@ -0,0 +1,77 @@
+package github
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/bmizerany/assert"
+)
+
+func TestClient_FetchPullRequestStatuses(t *testing.T) {
+	queries := 0
+	client, cleanup := setupTestClient(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/graphql", r.URL.Path)
+		payload := struct {
+			Query     string                 `json:"query"`
+			Variables map[string]interface{} `json:"variables"`
+		}{}
+		json.NewDecoder(r.Body).Decode(&payload)
+		assert.Equal(t, "mislav", payload.Variables["owner"])
+		assert.T(t, strings.Contains(payload.Query, "pr12: pullRequest(number: 12)"))
+		assert.T(t, strings.Contains(payload.Query, "pr13: pullRequest(number: 13)"))
+		queries++
+
+		fmt.Fprint(w, `{"data":{"repository":{
+			"pr12":{"number":12,"reviewDecision":"APPROVED","commits":{"nodes":[{"commit":{"statusCheckRollup":{"contexts":{"nodes":[
+				{"__typename":"CheckRun","name":"build","status":"COMPLETED","conclusion":"SUCCESS"},
+				{"__typename":"CheckRun","name":"lint","status":"IN_PROGRESS","conclusion":null},
+				{"__typename":"StatusContext","context":"ci/legacy","state":"EXPECTED"}
+			]}}}}]}},
+			"pr13":{"number":13,"reviewDecision":null,"commits":{"nodes":[{"commit":{"statusCheckRollup":null}}]}}
+		}}}`)
+	})
+	defer cleanup()
+
+	statuses, err := client.FetchPullRequestStatuses(&Project{Owner: "mislav", Name: "dotfiles"}, []int{12, 13})
+	assert.Equal(t, nil, err)
+	assert.Equal(t, 1, queries)
+
+	assert.Equal(t, "APPROVED", statuses[12].ReviewDecision)
+	assert.Equal(t, []CIStatus{
+		{State: "success", Context: "build"},
+		{State: "pending", Context: "lint"},
+		{State: "pending", Context: "ci/legacy"},
+	}, statuses[12].Checks)
+
+	assert.Equal(t, "", statuses[13].ReviewDecision)
+	assert.Equal(t, []CIStatus{}, statuses[13].Checks)
+}
+
+func TestClient_FetchRequiredPullRequestChecks(t *testing.T) {
+	client, cleanup := setupTestClient(func(w http.ResponseWriter, r *http.Request) {
+		payload := struct {
+			Query     string                 `json:"query"`
+			Variables map[string]interface{} `json:"variables"`
+		}{}
+		json.NewDecoder(r.Body).Decode(&payload)
+		assert.Equal(t, float64(12), payload.Variables["number"])
+		assert.T(t, strings.Contains(payload.Query, "isRequired(pullRequestNumber: $number)"))
+
+		fmt.Fprint(w, `{"data":{"repository":{"pullRequest":{"commits":{"nodes":[{"commit":{"statusCheckRollup":{"contexts":{"nodes":[
+			{"__typename":"CheckRun","name":"build","status":"COMPLETED","conclusion":"FAILURE","isRequired":true},
+			{"__typename":"CheckRun","name":"coverage","status":"COMPLETED","conclusion":"FAILURE","isRequired":false},
+			{"__typename":"StatusContext","context":"ci/legacy","state":"SUCCESS","isRequired":true}
+		]}}}}]}}}}}`)
+	})
+	defer cleanup()
+
+	checks, err := client.FetchRequiredPullRequestChecks(&Project{Owner: "mislav", Name: "dotfiles"}, 12)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, []CIStatus{
+		{State: "failure", Context: "build"},
+		{State: "success", Context: "ci/legacy"},
+	}, checks)
+}
@@ -0,0 +1,89 @@
+package github_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/bmizerany/assert"
+	"github.com/github/hub/github"
+	"github.com/github/hub/github/githubtest"
+)
+
+func TestClient_FetchReleasesSince(t *testing.T) {
+	client, server := githubtest.NewClient()
+	defer server.Close()
+	server.Handle(githubtest.Fixture{
+		Path:    "/repos/mislav/dotfiles/releases",
+		PerPage: 2,
+		Items: []json.RawMessage{
+			json.RawMessage(`{"tag_name": "v3", "draft": true, "created_at": "2024-05-09T00:00:00Z"}`),
+			json.RawMessage(`{"tag_name": "v2", "created_at": "2024-05-05T00:00:00Z", "published_at": "2024-05-06T00:00:00Z"}`),
+			json.RawMessage(`{"tag_name": "v1.1", "created_at": "2024-04-28T00:00:00Z", "published_at": "2024-05-02T00:00:00Z"}`),
+			json.RawMessage(`{"tag_name": "v1", "created_at": "2024-04-20T00:00:00Z", "published_at": "2024-04-20T00:00:00Z"}`),
+			json.RawMessage(`{"tag_name": "v0.9", "created_at": "2024-04-01T00:00:00Z", "published_at": "2024-04-01T00:00:00Z"}`),
+		},
+	})
+
+	since := time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)
+	releases, err := client.FetchReleasesSince(&github.Project{Owner: "mislav", Name: "dotfiles"}, since)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, 2, len(releases))
+	assert.Equal(t, "v2", releases[0].TagName)
+	assert.Equal(t, "v1.1", releases[1].TagName)
+
+	server.AssertRequests(t,
+		"GET /repos/mislav/dotfiles/releases",
+		"GET /repos/mislav/dotfiles/releases",
+	)
+}
+
+func TestClient_UploadReleaseAsset_retry(t *testing.T) {
+	file, _ := ioutil.TempFile("", "asset")
+	file.WriteString("hello world")
+	file.Close()
+	defer os.Remove(file.Name())
+	name := filepath.Base(file.Name())
+
+	client, server := githubtest.NewClient()
+	defer server.Close()
+	server.Handle(githubtest.Fixture{
+		Method:    "POST",
+		Path:      "/uploads/releases/1/assets",
+		Status:    201,
+		Body:      json.RawMessage(fmt.Sprintf(`{"name": %q}`, name)),
+		FailTimes: 1,
+	})
+	server.Handle(githubtest.Fixture{
+		Path: "/repos/mislav/dotfiles/releases/1/assets",
+		Body: json.RawMessage(fmt.Sprintf(`[{"name": "other"}, {"name": %q, "url": "%s/repos/mislav/dotfiles/releases/assets/9"}]`, name, server.URL)),
+	})
+	server.Handle(githubtest.Fixture{Method: "DELETE", Path: "/repos/mislav/dotfiles/releases/assets/9", Status: 204})
+
+	release := &github.Release{
+		ApiUrl:    server.URL + "/repos/mislav/dotfiles/releases/1",
+		UploadUrl: server.URL + "/uploads/releases/1/assets{?name,label}",
+	}
+	sent := int64(0)
+	asset, err := client.UploadReleaseAsset(release, file.Name(), "", 3, func(s, total int64) {
+		assert.Equal(t, int64(11), total)
+		sent = s
+	})
+	assert.Equal(t, nil, err)
+	assert.Equal(t, name, asset.Name)
+	assert.Equal(t, int64(11), sent)
+
+	server.AssertRequests(t,
+		"POST /uploads/releases/1/assets",
+		"GET /repos/mislav/dotfiles/releases/1/assets",
+		"DELETE /repos/mislav/dotfiles/releases/assets/9",
+		"POST /uploads/releases/1/assets",
+	)
+	upload := server.AssertRequested(t, "POST", "/uploads/releases/1/assets")
+	assert.Equal(t, name, upload.Query.Get("name"))
+	assert.Equal(t, "hello world", string(upload.Body))
+}
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"net/url"
 	"regexp"
+	"sort"
 	"strings"
 
 	"github.com/github/hub/git"
@@ -17,24 +18,68 @@ type Remote struct {
 	Name    string
 	URL     *url.URL
 	PushURL *url.URL
+
+	// explicitPushURL is "remote.<name>.pushurl" with only the insteadOf
+	// rewrites applied, since pushInsteadOf shouldn't affect which project a
+	// remote is for.
+	explicitPushURL *url.URL
 }
 
 func (remote *Remote) String() string {
 	return remote.Name
 }
 
+// Project is the GitHub project that remote fetches from.
 func (remote *Remote) Project() (*Project, error) {
 	p, err := NewProjectFromURL(remote.URL)
-	if _, ok := err.(*GithubHostError); ok {
-		return NewProjectFromURL(remote.PushURL)
+	if _, ok := err.(*GithubHostError); ok && remote.explicitPushURL != nil {
+		return NewProjectFromURL(remote.explicitPushURL)
 	}
 	return p, err
 }
 
-func Remotes() (remotes []Remote, err error) {
-	re := regexp.MustCompile(`(.+)\s+(.+)\s+\((push|fetch)\)`)
+// PushProject is the GitHub project that git pushes to through remote, which
+// can differ from its Project because of "url.<base>.pushInsteadOf" rules.
+func (remote *Remote) PushProject() (*Project, error) {
+	if remote.PushURL != nil {
+		if p, err := NewProjectFromURL(remote.PushURL); err == nil {
+			return p, nil
+		}
+	}
+	return remote.Project()
+}
+
+// remoteHead returns the name of the branch that the HEAD of remote refers
+// to, as set by git-clone(1) or `git remote set-head`.
+func remoteHead(remote *Remote) string {
+	name, err := git.SymbolicFullName(fmt.Sprintf("refs/remotes/%s/HEAD", remote.Name))
+	if err != nil {
+		return ""
+	}
+	return strings.TrimPrefix(name, fmt.Sprintf("refs/remotes/%s/", remote.Name))
+}
+
+// setRemoteHead points the HEAD of remote to its branch, provided that the
+// branch has been fetched.
+func setRemoteHead(remote *Remote, branch string) {
+	ref := fmt.Sprintf("refs/remotes/%s/%s", remote.Name, branch)
+	if _, err := git.Ref(ref); err == nil {
+		git.Quiet("symbolic-ref", fmt.Sprintf("refs/remotes/%s/HEAD", remote.Name), ref)
+	}
+}
+
+var remoteURLRe = regexp.MustCompile(`^remote\.(.+)\.(url|pushurl)$`)
 
-	rs, err := git.Remotes()
+// Remotes lists the git remotes with their URLs rewritten according to the
+// "url.<base>.insteadOf" and "url.<base>.pushInsteadOf" rules, like git does
+// when it contacts them.
+func Remotes() (remotes []Remote, err error) {
+	entries, err := git.ConfigEntries(`^remote\..*\.(url|pushurl)$`)
+	if err != nil {
+		err = fmt.Errorf("Can't load git remote")
+		return
+	}
+	rewrites, err := git.ReadURLRewrites()
 	if err != nil {
 		err = fmt.Errorf("Can't load git remote")
 		return
@@ -42,18 +87,20 @@ func Remotes() (remotes []Remote, err error) {
 
 	// build the remotes map
 	remotesMap := make(map[string]map[string]string)
-	for _, r := range rs {
-		if re.MatchString(r) {
-			match := re.FindStringSubmatch(r)
-			name := strings.TrimSpace(match[1])
-			url := strings.TrimSpace(match[2])
-			urlType := strings.TrimSpace(match[3])
-			utm, ok := remotesMap[name]
-			if !ok {
-				utm = make(map[string]string)
-				remotesMap[name] = utm
-			}
-			utm[urlType] = url
+	for _, entry := range entries {
+		match := remoteURLRe.FindStringSubmatch(entry.Name)
+		if match == nil {
+			continue
+		}
+		name, urlType := match[1], match[2]
+		utm, ok := remotesMap[name]
+		if !ok {
+			utm = make(map[string]string)
+			remotesMap[name] = utm
+		}
+		// git uses the first of several URLs of a remote
+		if _, ok := utm[urlType]; !ok {
+			utm[urlType] = entry.Value
 		}
 	}
 
@@ -61,7 +108,7 @@ func Remotes() (remotes []Remote, err error) {
 	names := OriginNamesInLookupOrder
 	for _, name := range names {
 		if u, ok := remotesMap[name]; ok {
-			r, err := newRemote(name, u)
+			r, err := newRemote(name, u, rewrites)
 			if err == nil {
 				remotes = append(remotes, r)
 				delete(remotesMap, name)
@@ -69,9 +116,14 @@ func Remotes() (remotes []Remote, err error) {
 		}
 	}
 
-	// the rest of the remotes
-	for n, u := range remotesMap {
-		r, err := newRemote(n, u)
+	// the rest of the remotes, by name
+	rest := []string{}
+	for name := range remotesMap {
+		rest = append(rest, name)
+	}
+	sort.Strings(rest)
+	for _, name := range rest {
+		r, err := newRemote(name, remotesMap[name], rewrites)
 		if err == nil {
 			remotes = append(remotes, r)
 		}
@@ -80,11 +132,23 @@ func Remotes() (remotes []Remote, err error) {
 	return
 }
 
-func newRemote(name string, urlMap map[string]string) (Remote, error) {
+func newRemote(name string, urlMap map[string]string, rewrites *git.URLRewrites) (Remote, error) {
 	r := Remote{}
 
-	fetchURL, ferr := git.ParseURL(urlMap["fetch"])
-	pushURL, perr := git.ParseURL(urlMap["push"])
+	rawURL, hasURL := urlMap["url"]
+	pushURL := rewrites.Push(rawURL)
+	if explicit, ok := urlMap["pushurl"]; ok {
+		pushURL = rewrites.Fetch(explicit)
+		if u, err := git.ParseURL(pushURL); err == nil {
+			r.explicitPushURL = u
+		}
+	}
+
+	fetchURL, ferr := git.ParseURL(rewrites.Fetch(rawURL))
+	if !hasURL {
+		ferr = fmt.Errorf("No fetch URL")
+	}
+	parsedPushURL, perr := git.ParseURL(pushURL)
 	if ferr != nil && perr != nil {
 		return r, fmt.Errorf("No valid remote URLs")
 	}
@@ -94,7 +158,7 @@ func newRemote(name string, urlMap map[string]string) (Remote, error) {
 		r.URL = fetchURL
 	}
 	if perr == nil {
-		r.PushURL = pushURL
+		r.PushURL = parsedPushURL
 	}
 
 	return r, nil
@@ -5,6 +5,7 @@ import (
 
 	"github.com/bmizerany/assert"
 	"github.com/github/hub/fixtures"
+	"github.com/github/hub/git"
 )
 
 func TestGithubRemote_NoPush(t *testing.T) {
@@ -78,3 +79,90 @@ func TestGithubRemote_ColonSlash(t *testing.T) {
 	assert.Equal(t, remotes[1].Name, "origin")
 	assert.Equal(t, remotes[1].URL.Path, repo.Remote)
 }
+
+func TestRemoteHead(t *testing.T) {
+	repo := fixtures.SetupTestRepo()
+	defer repo.TearDown()
+
+	remote := &Remote{Name: "origin"}
+	assert.Equal(t, "master", remoteHead(remote))
+
+	git.Quiet("symbolic-ref", "--delete", "refs/remotes/origin/HEAD")
+	assert.Equal(t, "", remoteHead(remote))
+
+	setRemoteHead(remote, "nonexistent")
+	assert.Equal(t, "", remoteHead(remote))
+
+	setRemoteHead(remote, "master")
+	assert.Equal(t, "master", remoteHead(remote))
+}
+
+func TestGithubRemote_InsteadOf(t *testing.T) {
+	repo := fixtures.SetupTestRepo()
+	defer repo.TearDown()
+
+	git.Quiet("config", "url.git@github.com:.insteadOf", "https://github.com/")
+	git.Quiet("config", "url.https://github.com/.insteadOf", "gh:")
+	git.Quiet("config", "url.git@github.com:mislav/.pushInsteadOf", "https://github.com/mislav-fork/")
+	repo.AddRemote("upstream", "https://github.com/github/hub.git", "")
+	repo.AddRemote("mislav", "gh:mislav/hub.git", "")
+	repo.AddRemote("fork", "https://github.com/mislav-fork/hub.git", "")
+
+	remotes, err := Remotes()
+	assert.Equal(t, nil, err)
+	byName := map[string]Remote{}
+	for _, remote := range remotes {
+		byName[remote.Name] = remote
+	}
+
+	upstream := byName["upstream"]
+	assert.Equal(t, "ssh", upstream.URL.Scheme)
+	assert.Equal(t, "/github/hub.git", upstream.URL.Path)
+	assert.Equal(t, "ssh", upstream.PushURL.Scheme)
+
+	mislav := byName["mislav"]
+	assert.Equal(t, "https", mislav.URL.Scheme)
+	assert.Equal(t, "/mislav/hub.git", mislav.URL.Path)
+
+	// pushInsteadOf only affects where git pushes to
+	fork := byName["fork"]
+	project, err := fork.Project()
+	assert.Equal(t, nil, err)
+	assert.Equal(t, "mislav-fork", project.Owner)
+	pushProject, err := fork.PushProject()
+	assert.Equal(t, nil, err)
+	assert.Equal(t, "mislav", pushProject.Owner)
+	assert.Equal(t, "hub", pushProject.Name)
+}
+
+func TestGithubRemote_PushURLFallback(t *testing.T) {
+	repo := fixtures.SetupTestRepo()
+	defer repo.TearDown()
+
+	git.Quiet("config", "url.git@github.com:.insteadOf", "https://github.com/")
+	git.Quiet("config", "url.https://github.com/.pushInsteadOf", "https://mirror.example.com/")
+	repo.AddRemote("explicit", "https://mirror.example.com/github/hub.git", "https://github.com/github/hub.git")
+	repo.AddRemote("implicit", "https://mirror.example.com/github/docs.git", "")
+
+	remotes, err := Remotes()
+	assert.Equal(t, nil, err)
+	byName := map[string]Remote{}
+	for _, remote := range remotes {
+		byName[remote.Name] = remote
+	}
+
+	explicit := byName["explicit"]
+	project, err := explicit.Project()
+	assert.Equal(t, nil, err)
+	assert.Equal(t, "github/hub", project.String())
+	assert.Equal(t, "ssh", explicit.PushURL.Scheme)
+
+	implicit := byName["implicit"]
+	assert.Equal(t, "mirror.example.com", implicit.URL.Host)
+	assert.Equal(t, "github.com", implicit.PushURL.Host)
+	_, err = implicit.Project()
+	assert.NotEqual(t, nil, err)
+	pushProject, err := implicit.PushProject()
+	assert.Equal(t, nil, err)
+	assert.Equal(t, "github/docs", pushProject.String())
+}
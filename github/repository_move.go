@@ -0,0 +1,206 @@
+package github
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+	"sync"
+)
+
+// A RepositoryMove records that API requests for a repository were redirected
+// because it was renamed or transferred to another owner.
+type RepositoryMove struct {
+	From *Project
+	To   *Project
+}
+
+var (
+	repositoryMoves      []RepositoryMove
+	repositoryMovesMutex sync.Mutex
+
+	repositoryPathRe = regexp.MustCompile(`^repos/([^/]+)/([^/]+)(/.*)?$`)
+	repositoryIdRe   = regexp.MustCompile(`^repositories/(\d+)(/.*)?$`)
+)
+
+// MovedRepositories lists the repositories that moved elsewhere according to
+// the API responses seen so far.
+func MovedRepositories() []RepositoryMove {
+	repositoryMovesMutex.Lock()
+	defer repositoryMovesMutex.Unlock()
+	return append([]RepositoryMove{}, repositoryMoves...)
+}
+
+func addRepositoryMove(from, to *Project) {
+	repositoryMovesMutex.Lock()
+	defer repositoryMovesMutex.Unlock()
+	for _, move := range repositoryMoves {
+		if move.From.SameAs(from) {
+			return
+		}
+	}
+	repositoryMoves = append(repositoryMoves, RepositoryMove{From: from, To: to})
+}
+
+func movedRepository(project *Project) *Project {
+	repositoryMovesMutex.Lock()
+	defer repositoryMovesMutex.Unlock()
+	for _, move := range repositoryMoves {
+		if move.From.SameAs(project) {
+			return move.To
+		}
+	}
+	return nil
+}
+
+// repositoryPath returns the repository that an API URL is about, along with
+// the rest of its path.
+func (c *simpleClient) repositoryPath(u *url.URL) (project *Project, rest string) {
+	if c.host == "" || !strings.EqualFold(u.Host, c.rootUrl.Host) || !strings.HasPrefix(u.Path, c.rootUrl.Path) {
+		return
+	}
+	if m := repositoryPathRe.FindStringSubmatch(strings.TrimPrefix(u.Path, c.rootUrl.Path)); m != nil {
+		project = &Project{Owner: m[1], Name: m[2], Host: c.host}
+		rest = m[3]
+	}
+	return
+}
+
+// rewriteMovedRepository points requests for a moved repository to its new
+// location, so that it's only looked up once per invocation.
+func (c *simpleClient) rewriteMovedRepository(u *url.URL) *url.URL {
+	project, rest := c.repositoryPath(u)
+	if project == nil {
+		return u
+	}
+	to := movedRepository(project)
+	if to == nil {
+		return u
+	}
+	moved := *u
+	moved.Path = fmt.Sprintf("%srepos/%s/%s%s", c.rootUrl.Path, to.Owner, to.Name, rest)
+	moved.RawPath = ""
+	return &moved
+}
+
+func isRepositoryMoveStatus(status int) bool {
+	return status == http.StatusMovedPermanently ||
+		status == http.StatusTemporaryRedirect ||
+		status == http.StatusPermanentRedirect
+}
+
+// isRepositoryMove reports whether a redirect is one that GitHub answers
+// requests for a renamed or transferred repository with. Those aren't followed
+// by the HTTP client, but by simpleClient itself.
+func (c *simpleClient) isRepositoryMove(req *http.Request, via []*http.Request) bool {
+	if len(via) != 1 || req.Response == nil || !isRepositoryMoveStatus(req.Response.StatusCode) {
+		return false
+	}
+	if project, _ := c.repositoryPath(via[0].URL); project == nil {
+		return false
+	}
+	if !strings.EqualFold(req.URL.Host, c.rootUrl.Host) || !strings.HasPrefix(req.URL.Path, c.rootUrl.Path) {
+		return false
+	}
+	target := strings.TrimPrefix(req.URL.Path, c.rootUrl.Path)
+	return repositoryIdRe.MatchString(target) || repositoryPathRe.MatchString(target)
+}
+
+// repositoryMoveLocation returns where a request for a repository was
+// redirected to if the repository was renamed or transferred.
+func (c *simpleClient) repositoryMoveLocation(req *http.Request, res *http.Response) *url.URL {
+	if !isRepositoryMoveStatus(res.StatusCode) {
+		return nil
+	}
+	if from, _ := c.repositoryPath(req.URL); from == nil {
+		return nil
+	}
+	location, err := res.Location()
+	if err != nil || !c.isRepositoryMove(&http.Request{URL: location, Response: res}, []*http.Request{req}) {
+		return nil
+	}
+	// the scheme and host may differ when talking to a test server
+	moved := *req.URL
+	moved.Path = location.Path
+	moved.RawPath = ""
+	moved.RawQuery = req.URL.RawQuery
+	if location.RawQuery != "" {
+		moved.RawQuery = location.RawQuery
+	}
+	return &moved
+}
+
+// recordRepositoryMove finds out the new name of the repository that the
+// request redirected to location was about. Locations such as
+// "repositories/ID" are resolved from the response to a request for the
+// repository itself, or by looking it up.
+func (c *simpleClient) recordRepositoryMove(req *http.Request, location *url.URL, res *simpleResponse) {
+	from, _ := c.repositoryPath(req.URL)
+	if from == nil {
+		return
+	}
+
+	var to *Project
+	target := strings.TrimPrefix(location.Path, c.rootUrl.Path)
+	if m := repositoryPathRe.FindStringSubmatch(target); m != nil {
+		to = &Project{Owner: m[1], Name: m[2], Host: c.host}
+	} else if m := repositoryIdRe.FindStringSubmatch(target); m != nil {
+		var repo *Repository
+		if m[2] == "" && res.StatusCode == http.StatusOK {
+			repo = peekRepository(res)
+		} else {
+			repoURL := *location
+			repoURL.Path = c.rootUrl.Path + "repositories/" + m[1]
+			repoURL.RawQuery = ""
+			if repoRes, err := c.doRequest("GET", &repoURL, nil, nil, false); err == nil {
+				if repoRes.StatusCode == http.StatusOK {
+					repo = peekRepository(repoRes)
+				}
+				repoRes.discard()
+			}
+		}
+		to = c.repositoryProject(repo)
+	}
+
+	if to != nil && !to.SameAs(from) {
+		addRepositoryMove(from, to)
+	}
+}
+
+// peekRepository decodes a repository from the response without consuming
+// its body.
+func peekRepository(res *simpleResponse) *Repository {
+	body, err := ioutil.ReadAll(res.Body)
+	res.Body.Close()
+	res.Body = ioutil.NopCloser(bytes.NewReader(body))
+	if err != nil {
+		return nil
+	}
+	repo := &Repository{}
+	if json.Unmarshal(body, repo) != nil {
+		return nil
+	}
+	return repo
+}
+
+func (c *simpleClient) repositoryProject(repo *Repository) *Project {
+	if repo == nil {
+		return nil
+	}
+	if parts := strings.SplitN(repo.FullName, "/", 2); len(parts) == 2 {
+		return &Project{Owner: parts[0], Name: parts[1], Host: c.host}
+	}
+	if repo.Owner != nil && repo.Owner.Login != "" && repo.Name != "" {
+		return &Project{Owner: repo.Owner.Login, Name: repo.Name, Host: c.host}
+	}
+	if u, err := url.Parse(repo.HtmlUrl); err == nil {
+		if parts := strings.Split(strings.Trim(u.Path, "/"), "/"); len(parts) == 2 {
+			return &Project{Owner: parts[0], Name: parts[1], Host: c.host}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,162 @@
+package github
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/bmizerany/assert"
+)
+
+// setupMovedRepositoryServer starts an API server for which the repository
+// "mislav/dotfiles" was transferred to "acme/settings", which has the ID 42.
+func setupMovedRepositoryServer(handler http.HandlerFunc) (*httptest.Server, *[]string) {
+	repositoryMoves = nil
+	requests := []string{}
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		r.URL.Path = strings.TrimPrefix(r.URL.Path, "/api/v3")
+		requests = append(requests, r.Method+" "+r.URL.Path)
+		switch {
+		case strings.HasPrefix(r.URL.Path, "/repos/mislav/dotfiles"):
+			status := http.StatusMovedPermanently
+			if r.Method != "GET" {
+				status = http.StatusTemporaryRedirect
+			}
+			rest := strings.TrimPrefix(r.URL.Path, "/repos/mislav/dotfiles")
+			w.Header().Set("Location", "http://"+r.Host+"/api/v3/repositories/42"+rest)
+			w.WriteHeader(status)
+			w.Write([]byte(`{"message": "Moved Permanently"}`))
+		case r.URL.Path == "/repositories/42":
+			w.Write([]byte(`{"name": "settings", "full_name": "acme/settings"}`))
+		default:
+			handler(w, r)
+		}
+	}))
+	return s, &requests
+}
+
+func TestClient_FollowsMovedRepository(t *testing.T) {
+	s, requests := setupMovedRepositoryServer(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == "PATCH" && r.URL.Path == "/repos/acme/settings/issues/12" {
+			w.Write([]byte(`{"number": 12}`))
+		} else {
+			w.WriteHeader(http.StatusNotFound)
+		}
+	})
+	defer s.Close()
+	defer func() { repositoryMoves = nil }()
+
+	client := connReuseTestClient(s)
+	project := &Project{Owner: "mislav", Name: "dotfiles", Host: client.Host.Host}
+
+	repo, err := client.Repository(project)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, "acme/settings", repo.FullName)
+
+	// later requests go to the new location right away
+	err = client.UpdateIssue(project, 12, map[string]interface{}{"state": "closed"})
+	assert.Equal(t, nil, err)
+
+	assert.Equal(t, []string{
+		"GET /repos/mislav/dotfiles",
+		"GET /repositories/42",
+		"PATCH /repos/acme/settings/issues/12",
+	}, *requests)
+
+	moves := MovedRepositories()
+	assert.Equal(t, 1, len(moves))
+	assert.Equal(t, "mislav/dotfiles", moves[0].From.String())
+	assert.Equal(t, "acme/settings", moves[0].To.String())
+}
+
+func TestClient_FollowsMovedRepositoryWithBody(t *testing.T) {
+	s, requests := setupMovedRepositoryServer(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == "PATCH" && r.URL.Path == "/repositories/42/issues/12" {
+			body, _ := ioutil.ReadAll(r.Body)
+			params := map[string]interface{}{}
+			json.Unmarshal(body, &params)
+			assert.Equal(t, "closed", params["state"])
+			w.Write([]byte(`{"number": 12}`))
+		} else {
+			w.WriteHeader(http.StatusNotFound)
+		}
+	})
+	defer s.Close()
+	defer func() { repositoryMoves = nil }()
+
+	client := connReuseTestClient(s)
+	project := &Project{Owner: "mislav", Name: "dotfiles", Host: client.Host.Host}
+
+	err := client.UpdateIssue(project, 12, map[string]interface{}{"state": "closed"})
+	assert.Equal(t, nil, err)
+	assert.Equal(t, []string{
+		"PATCH /repos/mislav/dotfiles/issues/12",
+		"PATCH /repositories/42/issues/12",
+		"GET /repositories/42",
+	}, *requests)
+	assert.Equal(t, "acme/settings", MovedRepositories()[0].To.String())
+}
+
+func TestClient_FollowsMovedRepositoryOnce(t *testing.T) {
+	repositoryMoves = nil
+	requests := []string{}
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests = append(requests, r.Method+" "+strings.TrimPrefix(r.URL.Path, "/api/v3"))
+		// the new location was moved as well
+		next := map[string]string{
+			"/api/v3/repos/mislav/dotfiles": "/api/v3/repos/acme/settings",
+			"/api/v3/repos/acme/settings":   "/api/v3/repos/umbrella/settings",
+		}
+		w.Header().Set("Location", "http://"+r.Host+next[r.URL.Path])
+		w.WriteHeader(http.StatusMovedPermanently)
+		w.Write([]byte(`{"message": "Moved Permanently"}`))
+	}))
+	defer s.Close()
+	defer func() { repositoryMoves = nil }()
+
+	client := connReuseTestClient(s)
+	project := &Project{Owner: "mislav", Name: "dotfiles", Host: client.Host.Host}
+
+	_, err := client.Repository(project)
+	assert.NotEqual(t, nil, err)
+	assert.T(t, strings.Contains(err.Error(), "HTTP 301"))
+	assert.Equal(t, []string{
+		"GET /repos/mislav/dotfiles",
+		"GET /repos/acme/settings",
+	}, requests)
+	assert.Equal(t, 1, len(MovedRepositories()))
+}
+
+func TestClient_FollowsOtherRedirects(t *testing.T) {
+	s, requests := setupMovedRepositoryServer(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/repos/acme/tools/releases/assets/9":
+			w.Header().Set("Location", "http://"+r.Host+"/downloads/tools.zip")
+			w.WriteHeader(http.StatusFound)
+		case "/downloads/tools.zip":
+			w.Write([]byte("ZIP"))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	})
+	defer s.Close()
+	defer func() { repositoryMoves = nil }()
+
+	client := connReuseTestClient(s)
+	api, err := client.simpleApi()
+	assert.Equal(t, nil, err)
+
+	res, err := api.GetFile("repos/acme/tools/releases/assets/9", "application/octet-stream")
+	assert.Equal(t, nil, err)
+	body, _ := ioutil.ReadAll(res.Body)
+	res.Body.Close()
+	assert.Equal(t, "ZIP", string(body))
+	assert.Equal(t, []string{
+		"GET /repos/acme/tools/releases/assets/9",
+		"GET /downloads/tools.zip",
+	}, *requests)
+	assert.Equal(t, 0, len(MovedRepositories()))
+}
@@ -6,6 +6,7 @@ import (
 	"os"
 
 	"github.com/github/hub/cmd"
+	"github.com/github/hub/ui"
 )
 
 func setConsole(cmd *cmd.Cmd) {
@@ -15,3 +16,17 @@ func setConsole(cmd *cmd.Cmd) {
 		cmd.Stdin = stdin
 	}
 }
+
+// hasConsole reports whether there's a terminal for an editor to interact
+// with, either as stdin or as the controlling terminal of the process.
+func hasConsole() bool {
+	if ui.IsTerminal(os.Stdin) {
+		return true
+	}
+	tty, err := os.OpenFile("/dev/tty", os.O_RDONLY, 0660)
+	if err != nil {
+		return false
+	}
+	tty.Close()
+	return true
+}
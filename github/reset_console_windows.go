@@ -7,3 +7,9 @@ import "github.com/github/hub/cmd"
 // This does nothing on windows
 func setConsole(cmd *cmd.Cmd) {
 }
+
+// hasConsole assumes that there's a console on Windows, where running from a
+// terminal emulator such as mintty makes stdin look like a pipe.
+func hasConsole() bool {
+	return true
+}
@@ -0,0 +1,178 @@
+package github
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/textproto"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/github/hub/git"
+	"github.com/mitchellh/go-homedir"
+)
+
+// responseCacheMaxSize is how many bytes of responses the cache on disk keeps
+// before it evicts the ones that were used least recently.
+const responseCacheMaxSize = 20 * 1024 * 1024
+
+var (
+	sharedResponseCache     *conditionalCache
+	sharedResponseCacheOnce sync.Once
+)
+
+// persistentResponseCache returns the cache that keeps the ETag and body of
+// GET responses on disk, so that hub processes run in quick succession, such
+// as from a shell prompt, can revalidate them instead of fetching them again.
+// It's opted into with HUB_CACHE=1 or the "hub.cache" git setting; otherwise
+// it's nil.
+func persistentResponseCache() *conditionalCache {
+	sharedResponseCacheOnce.Do(func() {
+		enabled := os.Getenv("HUB_CACHE")
+		if enabled == "" {
+			enabled, _ = git.Config("hub.cache")
+		}
+		if enabled != "1" && enabled != "true" {
+			return
+		}
+		if dir := cacheDir("responses"); dir != "" {
+			sharedResponseCache = newPersistentConditionalCache(dir, responseCacheMaxSize)
+		}
+	})
+	return sharedResponseCache
+}
+
+// cacheDir is "hub/<name>" within XDG_CACHE_HOME, or within "~/.cache" if
+// that isn't set. Unlike the temporary directory, it belongs to the user alone.
+// It's empty if the home directory is unknown.
+func cacheDir(name string) string {
+	cacheHome := os.Getenv("XDG_CACHE_HOME")
+	if cacheHome == "" {
+		home, err := homedir.Dir()
+		if err != nil {
+			return ""
+		}
+		cacheHome = filepath.Join(home, ".cache")
+	}
+	return filepath.Join(cacheHome, "hub", name)
+}
+
+func newPersistentConditionalCache(dir string, maxSize int64) *conditionalCache {
+	cc := newConditionalCache()
+	cc.dir = dir
+	cc.maxSize = maxSize
+	return cc
+}
+
+// The file of an entry has the ETag on the first line, followed by the
+// response headers, a blank line and the body. The key includes a hash of the
+// token, so responses are never shared between identities.
+func (cc *conditionalCache) entryFile(key string) string {
+	return filepath.Join(cc.dir, filepath.FromSlash(key))
+}
+
+func (cc *conditionalCache) load(key string) *conditionalEntry {
+	f, err := os.Open(cc.entryFile(key))
+	if err != nil {
+		return nil
+	}
+	defer f.Close()
+
+	r := bufio.NewReader(f)
+	etag, err := r.ReadString('\n')
+	if err != nil {
+		return nil
+	}
+	header, err := textproto.NewReader(r).ReadMIMEHeader()
+	if err != nil && err != io.EOF {
+		return nil
+	}
+	body, err := ioutil.ReadAll(r)
+	if err != nil {
+		return nil
+	}
+	return &conditionalEntry{
+		etag:   strings.TrimRight(etag, "\r\n"),
+		header: http.Header(header),
+		body:   body,
+	}
+}
+
+// store writes the entry to a temporary file that is then renamed into place,
+// so that concurrent hub processes never read a partially written entry.
+func (cc *conditionalCache) store(key string, entry *conditionalEntry) {
+	file := cc.entryFile(key)
+	if err := os.MkdirAll(filepath.Dir(file), 0700); err != nil {
+		return
+	}
+	tmp, err := ioutil.TempFile(filepath.Dir(file), ".tmp-")
+	if err != nil {
+		return
+	}
+
+	buf := &bytes.Buffer{}
+	fmt.Fprintf(buf, "%s\r\n", entry.etag)
+	entry.header.Write(buf)
+	buf.WriteString("\r\n")
+	buf.Write(entry.body)
+
+	_, err = tmp.Write(buf.Bytes())
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Rename(tmp.Name(), file)
+	}
+	if err != nil {
+		os.Remove(tmp.Name())
+		return
+	}
+	cc.evict()
+}
+
+// touch marks the entry as recently used for the sake of eviction.
+func (cc *conditionalCache) touch(key string) {
+	now := time.Now()
+	os.Chtimes(cc.entryFile(key), now, now)
+}
+
+// evict removes the least recently used entries until the cache fits within
+// its maximum size.
+func (cc *conditionalCache) evict() {
+	type cachedFile struct {
+		path    string
+		size    int64
+		modTime time.Time
+	}
+	files := []cachedFile{}
+	var total int64
+	filepath.Walk(cc.dir, func(path string, info os.FileInfo, err error) error {
+		if err == nil && info.Mode().IsRegular() && !strings.HasPrefix(info.Name(), ".tmp-") {
+			files = append(files, cachedFile{path, info.Size(), info.ModTime()})
+			total += info.Size()
+		}
+		return nil
+	})
+	if total <= cc.maxSize {
+		return
+	}
+
+	sort.Slice(files, func(i, j int) bool {
+		return files[i].modTime.Before(files[j].modTime)
+	})
+	for _, file := range files {
+		if total <= cc.maxSize {
+			break
+		}
+		if os.Remove(file.path) == nil {
+			total -= file.size
+		}
+	}
+}
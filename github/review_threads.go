@@ -0,0 +1,121 @@
+package github
+
+import (
+	"time"
+)
+
+// ReviewThread is a conversation started by a review comment on a line of a
+// pull request. Line is 0 for threads on a whole file; for outdated threads,
+// it's the line in the diff the thread was started on.
+type ReviewThread struct {
+	Path       string
+	Line       int
+	IsResolved bool
+	IsOutdated bool
+	Comments   []ReviewThreadComment
+}
+
+type ReviewThreadComment struct {
+	Author    string
+	Body      string
+	CreatedAt time.Time
+}
+
+// FetchReviewThreads fetches the review threads of a pull request in the order
+// they were started, with up to 100 comments each.
+func (client *Client) FetchReviewThreads(project *Project, number int) ([]ReviewThread, error) {
+	query := `query($owner: String!, $name: String!, $number: Int!, $after: String) {
+  repository(owner: $owner, name: $name) {
+    pullRequest(number: $number) {
+      reviewThreads(first: 100, after: $after) {
+        pageInfo { hasNextPage endCursor }
+        nodes {
+          path
+          line
+          originalLine
+          isResolved
+          isOutdated
+          comments(first: 100) {
+            nodes {
+              author { login }
+              body
+              createdAt
+            }
+          }
+        }
+      }
+    }
+  }
+}`
+	variables := map[string]interface{}{
+		"owner":  project.Owner,
+		"name":   project.Name,
+		"number": number,
+	}
+
+	threads := []ReviewThread{}
+	for {
+		data := struct {
+			Repository struct {
+				PullRequest *struct {
+					ReviewThreads struct {
+						PageInfo struct {
+							HasNextPage bool   `json:"hasNextPage"`
+							EndCursor   string `json:"endCursor"`
+						} `json:"pageInfo"`
+						Nodes []struct {
+							Path         string `json:"path"`
+							Line         int    `json:"line"`
+							OriginalLine int    `json:"originalLine"`
+							IsResolved   bool   `json:"isResolved"`
+							IsOutdated   bool   `json:"isOutdated"`
+							Comments     struct {
+								Nodes []struct {
+									Author *struct {
+										Login string `json:"login"`
+									} `json:"author"`
+									Body      string    `json:"body"`
+									CreatedAt time.Time `json:"createdAt"`
+								} `json:"nodes"`
+							} `json:"comments"`
+						} `json:"nodes"`
+					} `json:"reviewThreads"`
+				} `json:"pullRequest"`
+			} `json:"repository"`
+		}{}
+		if err := client.graphQL("fetching review threads", query, variables, &data); err != nil {
+			return nil, err
+		}
+		pr := data.Repository.PullRequest
+		if pr == nil {
+			return threads, nil
+		}
+
+		for _, node := range pr.ReviewThreads.Nodes {
+			thread := ReviewThread{
+				Path:       node.Path,
+				Line:       node.Line,
+				IsResolved: node.IsResolved,
+				IsOutdated: node.IsOutdated,
+			}
+			if thread.Line == 0 {
+				thread.Line = node.OriginalLine
+			}
+			for _, c := range node.Comments.Nodes {
+				comment := ReviewThreadComment{Body: c.Body, CreatedAt: c.CreatedAt}
+				if c.Author != nil {
+					// the author of a comment is missing once their account is deleted
+					comment.Author = c.Author.Login
+				}
+				thread.Comments = append(thread.Comments, comment)
+			}
+			threads = append(threads, thread)
+		}
+
+		pageInfo := pr.ReviewThreads.PageInfo
+		if !pageInfo.HasNextPage {
+			return threads, nil
+		}
+		variables["after"] = pageInfo.EndCursor
+	}
+}
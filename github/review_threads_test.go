@@ -0,0 +1,50 @@
+package github
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/bmizerany/assert"
+)
+
+func TestClient_FetchReviewThreads(t *testing.T) {
+	client, cleanup := setupTestClient(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/graphql", r.URL.Path)
+		payload := struct {
+			Variables map[string]interface{} `json:"variables"`
+		}{}
+		json.NewDecoder(r.Body).Decode(&payload)
+		assert.Equal(t, "mislav", payload.Variables["owner"])
+		assert.Equal(t, float64(12), payload.Variables["number"])
+
+		if payload.Variables["after"] == nil {
+			fmt.Fprint(w, `{"data":{"repository":{"pullRequest":{"reviewThreads":{
+				"pageInfo":{"hasNextPage":true,"endCursor":"CURSOR"},
+				"nodes":[{"path":"main.go","line":null,"originalLine":7,"isResolved":true,"isOutdated":true,
+					"comments":{"nodes":[{"author":null,"body":"Old","createdAt":"2024-03-01T10:00:00Z"}]}}]}}}}}`)
+		} else {
+			assert.Equal(t, "CURSOR", payload.Variables["after"])
+			fmt.Fprint(w, `{"data":{"repository":{"pullRequest":{"reviewThreads":{
+				"pageInfo":{"hasNextPage":false,"endCursor":null},
+				"nodes":[{"path":"README.md","line":3,"originalLine":3,"isResolved":false,"isOutdated":false,
+					"comments":{"nodes":[{"author":{"login":"hubot"},"body":"Typo","createdAt":"2024-03-01T11:00:00Z"}]}}]}}}}}`)
+		}
+	})
+	defer cleanup()
+
+	threads, err := client.FetchReviewThreads(&Project{Owner: "mislav", Name: "dotfiles"}, 12)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, 2, len(threads))
+
+	assert.Equal(t, "main.go", threads[0].Path)
+	assert.Equal(t, 7, threads[0].Line)
+	assert.T(t, threads[0].IsResolved)
+	assert.Equal(t, "", threads[0].Comments[0].Author)
+
+	assert.Equal(t, "README.md", threads[1].Path)
+	assert.Equal(t, 3, threads[1].Line)
+	assert.T(t, !threads[1].IsResolved)
+	assert.Equal(t, "hubot", threads[1].Comments[0].Author)
+}
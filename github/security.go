@@ -0,0 +1,161 @@
+package github
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/github/hub/utils"
+)
+
+// DependabotAlert is a vulnerable dependency that Dependabot found in a
+// manifest of a repository.
+type DependabotAlert struct {
+	Number     int    `json:"number"`
+	State      string `json:"state"`
+	HtmlUrl    string `json:"html_url"`
+	Dependency struct {
+		Package struct {
+			Ecosystem string `json:"ecosystem"`
+			Name      string `json:"name"`
+		} `json:"package"`
+		ManifestPath string `json:"manifest_path"`
+	} `json:"dependency"`
+	SecurityAdvisory struct {
+		GhsaID   string `json:"ghsa_id"`
+		CveID    string `json:"cve_id"`
+		Summary  string `json:"summary"`
+		Severity string `json:"severity"`
+	} `json:"security_advisory"`
+	SecurityVulnerability struct {
+		Severity               string `json:"severity"`
+		VulnerableVersionRange string `json:"vulnerable_version_range"`
+	} `json:"security_vulnerability"`
+}
+
+// SecurityAdvisory is an advisory that the maintainers of a repository
+// published about a vulnerability in it.
+type SecurityAdvisory struct {
+	GhsaID      string     `json:"ghsa_id"`
+	CveID       string     `json:"cve_id"`
+	Summary     string     `json:"summary"`
+	Severity    string     `json:"severity"`
+	State       string     `json:"state"`
+	PublishedAt *time.Time `json:"published_at"`
+	HtmlUrl     string     `json:"html_url"`
+}
+
+// A SecurityScopeError means that the access token isn't allowed to read the
+// security alerts of a repository.
+type SecurityScopeError struct {
+	Project *Project
+}
+
+func (e *SecurityScopeError) Error() string {
+	return fmt.Sprintf("can't read Dependabot alerts of %s: the access token lacks the 'security_events' or 'repo' scope", e.Project)
+}
+
+func (e *SecurityScopeError) ExitStatus() int {
+	return utils.ExitAuth
+}
+
+// checkSecurityScope reports a SecurityScopeError for a response denying
+// access if the token has none of the scopes that grant reading alerts. With
+// one of them, the error of the API says best what went wrong.
+func checkSecurityScope(project *Project, res *simpleResponse) error {
+	if res.StatusCode != 403 && res.StatusCode != 404 {
+		return nil
+	}
+	header, present := res.Header["X-Oauth-Scopes"]
+	if !present {
+		// fine-grained tokens and app tokens don't report scopes
+		return nil
+	}
+	for _, scope := range strings.Split(strings.Join(header, ","), ",") {
+		switch strings.TrimSpace(scope) {
+		case "security_events", "repo", "public_repo":
+			return nil
+		}
+	}
+	return &SecurityScopeError{project}
+}
+
+// FetchDependabotAlerts lists the Dependabot alerts of a project in the given
+// state, or in any state if state is empty. Unless severities is empty, only
+// alerts of those severities are listed.
+func (client *Client) FetchDependabotAlerts(project *Project, state string, severities []string) (alerts []DependabotAlert, err error) {
+	api, err := client.simpleApi()
+	if err != nil {
+		return
+	}
+
+	query := url.Values{}
+	if state != "" {
+		query.Set("state", state)
+	}
+	if len(severities) > 0 {
+		query.Set("severity", strings.Join(severities, ","))
+	}
+	query.Set("per_page", "100")
+	path := fmt.Sprintf("repos/%s/%s/dependabot/alerts?%s", project.Owner, project.Name, query.Encode())
+
+	alerts = []DependabotAlert{}
+	var res *simpleResponse
+	for path != "" {
+		res, err = api.Get(path)
+		if err == nil {
+			if scopeErr := checkSecurityScope(project, res); scopeErr != nil {
+				res.discard()
+				return nil, scopeErr
+			}
+		}
+		if err = checkStatus(200, "fetching Dependabot alerts", res, err); err != nil {
+			return
+		}
+		path = res.Link("next")
+
+		alertsPage := []DependabotAlert{}
+		if err = res.Unmarshal(&alertsPage); err != nil {
+			return
+		}
+		alerts = append(alerts, alertsPage...)
+	}
+
+	return
+}
+
+// FetchSecurityAdvisories lists the repository security advisories of a
+// project in the given state, or in any state visible to the user if state is
+// empty.
+func (client *Client) FetchSecurityAdvisories(project *Project, state string) (advisories []SecurityAdvisory, err error) {
+	api, err := client.simpleApi()
+	if err != nil {
+		return
+	}
+
+	query := url.Values{}
+	if state != "" {
+		query.Set("state", state)
+	}
+	query.Set("per_page", "100")
+	path := fmt.Sprintf("repos/%s/%s/security-advisories?%s", project.Owner, project.Name, query.Encode())
+
+	advisories = []SecurityAdvisory{}
+	var res *simpleResponse
+	for path != "" {
+		res, err = api.Get(path)
+		if err = checkStatus(200, "fetching security advisories", res, err); err != nil {
+			return
+		}
+		path = res.Link("next")
+
+		advisoriesPage := []SecurityAdvisory{}
+		if err = res.Unmarshal(&advisoriesPage); err != nil {
+			return
+		}
+		advisories = append(advisories, advisoriesPage...)
+	}
+
+	return
+}
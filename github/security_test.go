@@ -0,0 +1,80 @@
+package github
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/bmizerany/assert"
+	"github.com/github/hub/utils"
+)
+
+func TestClient_FetchDependabotAlerts(t *testing.T) {
+	var serverURL string
+	client, cleanup := setupTestClient(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/repos/mislav/dotfiles/dependabot/alerts", r.URL.Path)
+		assert.Equal(t, "open", r.URL.Query().Get("state"))
+		assert.Equal(t, "high,critical", r.URL.Query().Get("severity"))
+		w.Header().Set("X-OAuth-Scopes", "repo, security_events")
+		if r.URL.Query().Get("after") == "" {
+			w.Header().Set("Link", fmt.Sprintf(`<%s/api/v3/repos/mislav/dotfiles/dependabot/alerts?state=open&severity=high,critical&after=Y3Vyc29y>; rel="next"`, serverURL))
+			fmt.Fprint(w, `[{"number":3,"state":"open","dependency":{"package":{"ecosystem":"npm","name":"lodash"},"manifest_path":"package-lock.json"},
+			"security_advisory":{"ghsa_id":"GHSA-p6mc-m468-83gw","cve_id":"CVE-2020-8203","severity":"high"},
+			"security_vulnerability":{"severity":"high","vulnerable_version_range":"< 4.17.19"}}]`)
+		} else {
+			fmt.Fprint(w, `[{"number":1,"state":"open","security_vulnerability":{"severity":"critical"}}]`)
+		}
+	})
+	defer cleanup()
+	serverURL = "http://" + client.Host.Host
+
+	alerts, err := client.FetchDependabotAlerts(&Project{Owner: "mislav", Name: "dotfiles"}, "open", []string{"high", "critical"})
+	assert.Equal(t, nil, err)
+	assert.Equal(t, 2, len(alerts))
+	assert.Equal(t, "lodash", alerts[0].Dependency.Package.Name)
+	assert.Equal(t, "package-lock.json", alerts[0].Dependency.ManifestPath)
+	assert.Equal(t, "CVE-2020-8203", alerts[0].SecurityAdvisory.CveID)
+	assert.Equal(t, "< 4.17.19", alerts[0].SecurityVulnerability.VulnerableVersionRange)
+	assert.Equal(t, 1, alerts[1].Number)
+}
+
+func TestClient_FetchDependabotAlerts_scope(t *testing.T) {
+	client, cleanup := setupTestClient(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/repos/mislav/dotfiles/dependabot/alerts" {
+			w.Header().Set("X-OAuth-Scopes", "gist, read:org")
+		} else if r.URL.Path == "/repos/mislav/private/dependabot/alerts" {
+			w.Header().Set("X-OAuth-Scopes", "public_repo, read:org")
+		}
+		w.WriteHeader(http.StatusForbidden)
+		fmt.Fprint(w, `{"message":"Resource not accessible by integration"}`)
+	})
+	defer cleanup()
+
+	_, err := client.FetchDependabotAlerts(&Project{Owner: "mislav", Name: "dotfiles"}, "", nil)
+	assert.Equal(t, "can't read Dependabot alerts of mislav/dotfiles: the access token lacks the 'security_events' or 'repo' scope", err.Error())
+	assert.Equal(t, utils.ExitAuth, utils.ExitStatus(err))
+
+	// with a scope that grants reading alerts, the error of the API is reported
+	_, err = client.FetchDependabotAlerts(&Project{Owner: "mislav", Name: "private"}, "", nil)
+	assert.Equal(t, "Error fetching Dependabot alerts: Forbidden (HTTP 403)\nResource not accessible by integration", err.Error())
+
+	// without a scopes header, the error of the API is reported as is
+	_, err = client.FetchDependabotAlerts(&Project{Owner: "mislav", Name: "secrets"}, "", nil)
+	assert.Equal(t, "Error fetching Dependabot alerts: Forbidden (HTTP 403)\nResource not accessible by integration", err.Error())
+}
+
+func TestClient_FetchSecurityAdvisories(t *testing.T) {
+	client, cleanup := setupTestClient(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/repos/mislav/dotfiles/security-advisories", r.URL.Path)
+		assert.Equal(t, "published", r.URL.Query().Get("state"))
+		fmt.Fprint(w, `[{"ghsa_id":"GHSA-abcd-efgh-ijkl","cve_id":null,"summary":"Path traversal","severity":"medium","state":"published","published_at":"2026-03-01T12:00:00Z"}]`)
+	})
+	defer cleanup()
+
+	advisories, err := client.FetchSecurityAdvisories(&Project{Owner: "mislav", Name: "dotfiles"}, "published")
+	assert.Equal(t, nil, err)
+	assert.Equal(t, 1, len(advisories))
+	assert.Equal(t, "GHSA-abcd-efgh-ijkl", advisories[0].GhsaID)
+	assert.Equal(t, "", advisories[0].CveID)
+	assert.Equal(t, 2026, advisories[0].PublishedAt.Year())
+}
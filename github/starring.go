@@ -0,0 +1,162 @@
+package github
+
+import (
+	"fmt"
+)
+
+// Subscription is how the authenticated user watches a repository. A
+// subscription that is neither subscribed nor ignored only notifies of
+// custom events, such as releases, or of participation.
+type Subscription struct {
+	Subscribed bool `json:"subscribed"`
+	Ignored    bool `json:"ignored"`
+}
+
+func starredPath(project *Project) string {
+	return fmt.Sprintf("user/starred/%s/%s", project.Owner, project.Name)
+}
+
+// IsStarred reports whether the authenticated user has starred the project.
+func (client *Client) IsStarred(project *Project) (bool, error) {
+	api, err := client.simpleApi()
+	if err != nil {
+		return false, err
+	}
+
+	res, err := api.Get(starredPath(project))
+	if err == nil && res.StatusCode == 404 {
+		res.discard()
+		return false, nil
+	}
+	if err = checkStatus(204, "checking star", res, err); err != nil {
+		return false, err
+	}
+	res.discard()
+	return true, nil
+}
+
+func (client *Client) StarRepository(project *Project) error {
+	api, err := client.simpleApi()
+	if err != nil {
+		return err
+	}
+
+	res, err := api.PutJSON(starredPath(project), map[string]interface{}{})
+	if err = checkStatus(204, "starring repository", res, err); err != nil {
+		return err
+	}
+	res.discard()
+	return nil
+}
+
+func (client *Client) UnstarRepository(project *Project) error {
+	api, err := client.simpleApi()
+	if err != nil {
+		return err
+	}
+
+	res, err := api.Delete(starredPath(project))
+	if err = checkStatus(204, "unstarring repository", res, err); err != nil {
+		return err
+	}
+	res.discard()
+	return nil
+}
+
+// FetchStarredRepositories lists the repositories starred by user, or by the
+// authenticated user if user is empty, most recently starred first.
+func (client *Client) FetchStarredRepositories(user string, limit int) (repos []Repository, err error) {
+	api, err := client.simpleApi()
+	if err != nil {
+		return
+	}
+
+	path := "user/starred"
+	if user != "" {
+		path = fmt.Sprintf("users/%s/starred", user)
+	}
+	path = fmt.Sprintf("%s?per_page=%d", path, perPage(limit, 100))
+
+	repos = []Repository{}
+	var res *simpleResponse
+
+	for path != "" {
+		res, err = api.Get(path)
+		if err = checkStatus(200, "fetching starred repositories", res, err); err != nil {
+			return
+		}
+		path = res.Link("next")
+
+		reposPage := []Repository{}
+		if err = res.Unmarshal(&reposPage); err != nil {
+			return
+		}
+		for _, repo := range reposPage {
+			repos = append(repos, repo)
+			if limit > 0 && len(repos) == limit {
+				path = ""
+				break
+			}
+		}
+	}
+
+	return
+}
+
+func subscriptionPath(project *Project) string {
+	return fmt.Sprintf("repos/%s/%s/subscription", project.Owner, project.Name)
+}
+
+// FetchSubscription returns nil if the authenticated user doesn't watch the
+// project.
+func (client *Client) FetchSubscription(project *Project) (*Subscription, error) {
+	api, err := client.simpleApi()
+	if err != nil {
+		return nil, err
+	}
+
+	res, err := api.Get(subscriptionPath(project))
+	if err == nil && res.StatusCode == 404 {
+		res.discard()
+		return nil, nil
+	}
+	if err = checkStatus(200, "fetching subscription", res, err); err != nil {
+		return nil, err
+	}
+
+	subscription := &Subscription{}
+	err = res.Unmarshal(subscription)
+	return subscription, err
+}
+
+func (client *Client) SetSubscription(project *Project, subscription Subscription) error {
+	api, err := client.simpleApi()
+	if err != nil {
+		return err
+	}
+
+	params := map[string]interface{}{
+		"subscribed": subscription.Subscribed,
+		"ignored":    subscription.Ignored,
+	}
+	res, err := api.PutJSON(subscriptionPath(project), params)
+	if err = checkStatus(200, "watching repository", res, err); err != nil {
+		return err
+	}
+	res.discard()
+	return nil
+}
+
+func (client *Client) DeleteSubscription(project *Project) error {
+	api, err := client.simpleApi()
+	if err != nil {
+		return err
+	}
+
+	res, err := api.Delete(subscriptionPath(project))
+	if err = checkStatus(204, "unwatching repository", res, err); err != nil {
+		return err
+	}
+	res.discard()
+	return nil
+}
@@ -0,0 +1,69 @@
+package github
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/bmizerany/assert"
+)
+
+func TestClient_IsStarred(t *testing.T) {
+	client, cleanup := setupTestClient(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/user/starred/mislav/dotfiles" {
+			w.WriteHeader(http.StatusNoContent)
+		} else {
+			w.WriteHeader(http.StatusNotFound)
+		}
+	})
+	defer cleanup()
+
+	starred, err := client.IsStarred(&Project{Owner: "mislav", Name: "dotfiles"})
+	assert.Equal(t, nil, err)
+	assert.T(t, starred)
+
+	starred, err = client.IsStarred(&Project{Owner: "mislav", Name: "secrets"})
+	assert.Equal(t, nil, err)
+	assert.T(t, !starred)
+}
+
+func TestClient_FetchStarredRepositories(t *testing.T) {
+	var serverURL string
+	client, cleanup := setupTestClient(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/users/mona/starred", r.URL.Path)
+		if r.URL.Query().Get("page") == "" {
+			w.Header().Set("Link", fmt.Sprintf(`<%s/api/v3/users/mona/starred?page=2>; rel="next"`, serverURL))
+			json.NewEncoder(w).Encode([]Repository{{FullName: "mislav/dotfiles"}})
+		} else {
+			json.NewEncoder(w).Encode([]Repository{{FullName: "github/hub"}, {FullName: "cli/cli"}})
+		}
+	})
+	defer cleanup()
+	serverURL = "http://" + client.Host.Host
+
+	repos, err := client.FetchStarredRepositories("mona", 2)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, 2, len(repos))
+	assert.Equal(t, "mislav/dotfiles", repos[0].FullName)
+	assert.Equal(t, "github/hub", repos[1].FullName)
+}
+
+func TestClient_FetchSubscription(t *testing.T) {
+	client, cleanup := setupTestClient(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/repos/mislav/dotfiles/subscription" {
+			fmt.Fprint(w, `{"subscribed":false,"ignored":true,"reason":null}`)
+		} else {
+			w.WriteHeader(http.StatusNotFound)
+		}
+	})
+	defer cleanup()
+
+	subscription, err := client.FetchSubscription(&Project{Owner: "mislav", Name: "dotfiles"})
+	assert.Equal(t, nil, err)
+	assert.Equal(t, Subscription{Ignored: true}, *subscription)
+
+	subscription, err = client.FetchSubscription(&Project{Owner: "mislav", Name: "secrets"})
+	assert.Equal(t, nil, err)
+	assert.T(t, subscription == nil)
+}
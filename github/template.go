@@ -1,6 +1,7 @@
 package github
 
 import (
+	"fmt"
 	"io/ioutil"
 	"os"
 	"path/filepath"
@@ -33,6 +34,64 @@ func ReadTemplate(kind, workdir string) (body string, err error) {
 	return
 }
 
+// ReadNamedTemplate reads the template called name from the directory of
+// templates of kind, e.g. ".github/PULL_REQUEST_TEMPLATE/", which is looked up
+// in the same places as the default template. If there's no such template, the
+// error lists the names of the available ones.
+func ReadNamedTemplate(kind, name, workdir string) (body string, err error) {
+	names := []string{}
+	seen := map[string]bool{}
+	for _, dir := range []string{filepath.Join(workdir, githubTemplateDir), filepath.Join(workdir, docsDir), workdir} {
+		templateDir := getTemplateDir(dir, kind)
+		if templateDir == "" {
+			continue
+		}
+		if path, _ := getFilePath(templateDir, templateFileName(name)); path != "" {
+			return readContentsFromFile(path)
+		}
+
+		files, _ := ioutil.ReadDir(templateDir)
+		for _, file := range files {
+			if file.IsDir() {
+				continue
+			}
+			templateName := templateFileName(file.Name())
+			if !seen[strings.ToLower(templateName)] {
+				seen[strings.ToLower(templateName)] = true
+				names = append(names, templateName)
+			}
+		}
+	}
+
+	if len(names) == 0 {
+		err = fmt.Errorf("template '%s' not found; there are no %s templates", name, strings.Replace(kind, "_template", "", 1))
+	} else {
+		sort.Slice(names, func(i, j int) bool {
+			return strings.ToLower(names[i]) < strings.ToLower(names[j])
+		})
+		err = fmt.Errorf("template '%s' not found; available templates: %s", name, strings.Join(names, ", "))
+	}
+	return
+}
+
+// getTemplateDir finds the directory of templates of kind within dir, whose
+// name may be in any case.
+func getTemplateDir(dir, kind string) string {
+	files, _ := ioutil.ReadDir(dir)
+	for _, file := range files {
+		if file.IsDir() && strings.EqualFold(file.Name(), kind) {
+			return filepath.Join(dir, file.Name())
+		}
+	}
+	return ""
+}
+
+// templateFileName is the name of a template file without its extension.
+func templateFileName(fileName string) string {
+	name := strings.TrimSuffix(fileName, ".md")
+	return strings.TrimSuffix(name, ".txt")
+}
+
 type sortedFiles []os.FileInfo
 
 func (s sortedFiles) Len() int {
@@ -55,10 +114,7 @@ func getFilePath(dir, pattern string) (found string, err error) {
 
 	for _, file := range files {
 		fileName := file.Name()
-		path := strings.TrimSuffix(fileName, ".md")
-		path = strings.TrimSuffix(path, ".txt")
-
-		if strings.EqualFold(pattern, path) {
+		if strings.EqualFold(pattern, templateFileName(fileName)) {
 			found = filepath.Join(dir, fileName)
 			return
 		}
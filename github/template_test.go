@@ -1,6 +1,7 @@
 package github
 
 import (
+	"io/ioutil"
 	"os"
 	"path/filepath"
 	"testing"
@@ -136,6 +137,41 @@ func TestGithubTemplate_WithTemplateInDocsDir(t *testing.T) {
 	assert.Equal(t, issueContent, tpl)
 }
 
+func TestGithubTemplate_ReadNamedTemplate(t *testing.T) {
+	workdir, err := ioutil.TempDir("", "hub-templates")
+	assert.Equal(t, nil, err)
+	defer os.RemoveAll(workdir)
+
+	_, err = ReadNamedTemplate(PullRequestTemplate, "bugfix", workdir)
+	assert.Equal(t, "template 'bugfix' not found; there are no pull_request templates", err.Error())
+
+	write := func(path, content string) {
+		path = filepath.Join(workdir, path)
+		os.MkdirAll(filepath.Dir(path), 0755)
+		ioutil.WriteFile(path, []byte(content), 0644)
+	}
+	write(".github/pull_request_template.md", "default")
+	write(".github/PULL_REQUEST_TEMPLATE/bugfix.md", "Fixes a bug\n")
+	write(".github/PULL_REQUEST_TEMPLATE/Feature.txt", "Adds a feature")
+	write("docs/pull_request_template/release.md", "Releases\r\nthings")
+	write("docs/pull_request_template/bugfix.md", "shadowed")
+
+	tpl, err := ReadNamedTemplate(PullRequestTemplate, "BugFix", workdir)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, "Fixes a bug", tpl)
+
+	tpl, err = ReadNamedTemplate(PullRequestTemplate, "feature.txt", workdir)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, "Adds a feature", tpl)
+
+	tpl, err = ReadNamedTemplate(PullRequestTemplate, "release", workdir)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, "Releases\nthings", tpl)
+
+	_, err = ReadNamedTemplate(PullRequestTemplate, "docs", workdir)
+	assert.Equal(t, "template 'docs' not found; available templates: bugfix, Feature, release", err.Error())
+}
+
 func addGithubTemplates(r *fixtures.TestRepo, config map[string]string) {
 	repoDir := "test.git"
 	if dir := config["dir"]; dir != "" {
@@ -0,0 +1,152 @@
+package github
+
+import (
+	"crypto/md5"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/github/hub/ui"
+)
+
+// TokenExpirationHeader is sent by GitHub along with responses to requests
+// made with tokens that expire, such as fine-grained personal access tokens.
+const TokenExpirationHeader = "GitHub-Authentication-Token-Expiration"
+
+// tokenExpirationWarningPeriod is how long before its expiration hub starts
+// warning about a token.
+const tokenExpirationWarningPeriod = 7 * 24 * time.Hour
+
+var (
+	// tokenStateDir is looked up late, since the home directory is cached
+	// once it's known
+	tokenStateDir   = func() string { return cacheDir("tokens") }
+	tokenStateMutex sync.Mutex
+)
+
+// tokenState is what hub remembers about a token between invocations. It's
+// stored under a hash of the token, never the token itself.
+type tokenState struct {
+	Host      string    `json:"host"`
+	ExpiresAt time.Time `json:"expires_at"`
+	WarnedOn  string    `json:"warned_on,omitempty"`
+}
+
+// ParseTokenExpiration parses the value of TokenExpirationHeader, e.g.
+// "2023-03-10 16:22:17 UTC".
+func ParseTokenExpiration(value string) (time.Time, error) {
+	for _, layout := range []string{"2006-01-02 15:04:05 MST", "2006-01-02 15:04:05 -0700"} {
+		if t, err := time.Parse(layout, strings.TrimSpace(value)); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("invalid token expiration: %q", value)
+}
+
+func tokenStateFile(res *http.Response) string {
+	if res.Request == nil {
+		return ""
+	}
+	auth := res.Request.Header.Get("Authorization")
+	dir := tokenStateDir()
+	if !strings.HasPrefix(auth, "token ") || dir == "" {
+		return ""
+	}
+	return filepath.Join(dir, fmt.Sprintf("%x", md5.Sum([]byte(auth))))
+}
+
+func readTokenState(file string) *tokenState {
+	data, err := ioutil.ReadFile(file)
+	if err != nil {
+		return nil
+	}
+	state := &tokenState{}
+	if json.Unmarshal(data, state) != nil {
+		return nil
+	}
+	return state
+}
+
+func writeTokenState(file string, state *tokenState) {
+	if data, err := json.Marshal(state); err == nil && os.MkdirAll(filepath.Dir(file), 0700) == nil {
+		ioutil.WriteFile(file, data, 0600)
+	}
+}
+
+// checkTokenExpiration records when the token used for res expires and warns,
+// at most once a day, if that's less than a week away.
+func checkTokenExpiration(host string, res *http.Response) {
+	header := res.Header.Get(TokenExpirationHeader)
+	if header == "" {
+		return
+	}
+	expiresAt, err := ParseTokenExpiration(header)
+	file := tokenStateFile(res)
+	if err != nil || file == "" {
+		return
+	}
+
+	tokenStateMutex.Lock()
+	defer tokenStateMutex.Unlock()
+
+	state := readTokenState(file)
+	if state == nil {
+		state = &tokenState{}
+	}
+	changed := state.Host != host || !state.ExpiresAt.Equal(expiresAt)
+	state.Host = host
+	state.ExpiresAt = expiresAt
+
+	today := time.Now().Format("2006-01-02")
+	if time.Until(expiresAt) < tokenExpirationWarningPeriod && state.WarnedOn != today {
+		ui.Errorf("warning: the access token for %s expires on %s\n", host, expiresAt.Format("2006-01-02"))
+		state.WarnedOn = today
+		changed = true
+	}
+
+	if changed {
+		writeTokenState(file, state)
+	}
+}
+
+// tokenExpiredOn returns the date on which the token used for res expired, or
+// an empty string if it isn't known to have expired.
+func tokenExpiredOn(res *http.Response) string {
+	file := tokenStateFile(res)
+	if file == "" {
+		return ""
+	}
+	tokenStateMutex.Lock()
+	state := readTokenState(file)
+	tokenStateMutex.Unlock()
+	if state == nil || state.ExpiresAt.IsZero() || state.ExpiresAt.After(time.Now()) {
+		return ""
+	}
+	return state.ExpiresAt.Format("2006-01-02")
+}
+
+// TokenExpiration returns when the access token of the client expires. Tokens
+// that don't expire result in a zero time.
+func (client *Client) TokenExpiration() (expiresAt time.Time, err error) {
+	api, err := client.simpleApi()
+	if err != nil {
+		return
+	}
+
+	res, err := api.Get("user")
+	if err = checkStatus(200, "getting current user", res, err); err != nil {
+		return
+	}
+	res.discard()
+
+	if header := res.Header.Get(TokenExpirationHeader); header != "" {
+		expiresAt, err = ParseTokenExpiration(header)
+	}
+	return
+}
@@ -0,0 +1,77 @@
+package github
+
+import (
+	"bytes"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/bmizerany/assert"
+	"github.com/github/hub/ui"
+)
+
+func setupTokenStateDir(t *testing.T) (*bytes.Buffer, func()) {
+	dir, err := ioutil.TempDir("", "hub-tokens-")
+	assert.Equal(t, nil, err)
+	originalDir, originalUI := tokenStateDir, ui.Default
+	tokenStateDir = func() string { return dir }
+	stderr := &bytes.Buffer{}
+	ui.Default = ui.Console{Stdout: ioutil.Discard, Stderr: stderr}
+	return stderr, func() {
+		tokenStateDir, ui.Default = originalDir, originalUI
+		os.RemoveAll(dir)
+	}
+}
+
+func tokenResponse(token string, status int, expiration string) *http.Response {
+	req, _ := http.NewRequest("GET", "https://api.github.com/user", nil)
+	req.Header.Set("Authorization", "token "+token)
+	res := &http.Response{StatusCode: status, Header: http.Header{}, Request: req}
+	if expiration != "" {
+		res.Header.Set(TokenExpirationHeader, expiration)
+	}
+	return res
+}
+
+func TestParseTokenExpiration(t *testing.T) {
+	expiresAt, err := ParseTokenExpiration("2023-03-10 16:22:17 UTC")
+	assert.Equal(t, nil, err)
+	assert.Equal(t, time.Date(2023, 3, 10, 16, 22, 17, 0, time.UTC), expiresAt.UTC())
+
+	expiresAt, err = ParseTokenExpiration("2023-03-10 16:22:17 -0800")
+	assert.Equal(t, nil, err)
+	assert.Equal(t, time.Date(2023, 3, 11, 0, 22, 17, 0, time.UTC), expiresAt.UTC())
+
+	_, err = ParseTokenExpiration("next tuesday")
+	assert.NotEqual(t, nil, err)
+}
+
+func TestCheckTokenExpiration_warnsOncePerDay(t *testing.T) {
+	stderr, cleanup := setupTokenStateDir(t)
+	defer cleanup()
+
+	soon := time.Now().Add(3 * 24 * time.Hour).UTC()
+	header := soon.Format("2006-01-02 15:04:05 MST")
+
+	checkTokenExpiration("github.com", tokenResponse("SOON", 200, header))
+	checkTokenExpiration("github.com", tokenResponse("SOON", 200, header))
+	assert.Equal(t, "warning: the access token for github.com expires on "+soon.Format("2006-01-02")+"\n", stderr.String())
+
+	stderr.Reset()
+	later := time.Now().Add(30 * 24 * time.Hour).UTC()
+	checkTokenExpiration("github.com", tokenResponse("LATER", 200, later.Format("2006-01-02 15:04:05 MST")))
+	assert.Equal(t, "", stderr.String())
+}
+
+func TestTokenExpiredOn(t *testing.T) {
+	_, cleanup := setupTokenStateDir(t)
+	defer cleanup()
+
+	assert.Equal(t, "", tokenExpiredOn(tokenResponse("OLD", 401, "")))
+
+	checkTokenExpiration("github.com", tokenResponse("OLD", 200, "2023-03-10 16:22:17 UTC"))
+	assert.Equal(t, "2023-03-10", tokenExpiredOn(tokenResponse("OLD", 401, "")))
+	assert.Equal(t, "", tokenExpiredOn(tokenResponse("OTHER", 401, "")))
+}
@@ -10,12 +10,16 @@ import (
 	"github.com/github/hub/commands"
 	"github.com/github/hub/github"
 	"github.com/github/hub/ui"
+	"github.com/github/hub/utils"
 )
 
 func main() {
 	defer github.CaptureCrash()
 	err := commands.CmdRunner.Execute(os.Args)
 	exitCode := handleError(err)
+	if exitCode != 0 {
+		ui.EmitFailure(err.Error(), exitCode)
+	}
 	os.Exit(exitCode)
 }
 
@@ -38,6 +42,6 @@ func handleError(err error) int {
 		if errString := err.Error(); errString != "" {
 			ui.Errorln(err)
 		}
-		return 1
+		return utils.ExitStatus(err)
 	}
 }
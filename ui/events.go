@@ -0,0 +1,93 @@
+package ui
+
+import (
+	"encoding/json"
+	"io"
+	"sync"
+)
+
+// EventsVersion is the "v" field of every event. It changes only when events
+// change in ways that break their consumers, such as a field being renamed.
+const EventsVersion = 1
+
+// The events that '--porcelain' prints, with the fields that they carry besides
+// "v" and "event":
+//
+//	asset-upload    name, done, total: bytes of a release asset sent so far
+//	asset-download  name, done, total: bytes of a release asset received so far
+//	fork-wait       repo: waiting for a new fork to become available
+//	checks-wait     ref, state, pending: waiting for pending CI checks
+//	result          command, and what the command did; or error and exit
+//	                when the command failed
+//
+// A result is always the last event.
+const (
+	EventAssetUpload   = "asset-upload"
+	EventAssetDownload = "asset-download"
+	EventForkWait      = "fork-wait"
+	EventChecksWait    = "checks-wait"
+	EventResult        = "result"
+)
+
+// Fields are the details of an event.
+type Fields map[string]interface{}
+
+var (
+	eventsOut     io.Writer
+	eventsMutex   sync.Mutex
+	resultEmitted bool
+)
+
+// EnablePorcelain makes Emit print events to standard output as JSON, one per
+// line, and sends everything else that would be printed there to standard
+// error instead.
+func EnablePorcelain() {
+	eventsOut = Stdout
+	Stdout = Stderr
+	Default = Console{Stdout: Stderr, Stderr: Stderr}
+}
+
+// Porcelain reports whether events are printed.
+func Porcelain() bool {
+	return eventsOut != nil
+}
+
+// Emit prints event with fields if events are enabled. It's safe to call from
+// several goroutines.
+func Emit(event string, fields Fields) {
+	if eventsOut == nil {
+		return
+	}
+	emit(eventsOut, event, fields)
+}
+
+// EmitFailure emits the result of a command that failed with message and exit
+// status, unless the command emitted its result before failing.
+func EmitFailure(message string, status int) {
+	eventsMutex.Lock()
+	emitted := resultEmitted
+	eventsMutex.Unlock()
+	if !emitted {
+		Emit(EventResult, Fields{"error": message, "exit": status})
+	}
+}
+
+func emit(out io.Writer, event string, fields Fields) {
+	data := Fields{}
+	for key, value := range fields {
+		data[key] = value
+	}
+	data["v"] = EventsVersion
+	data["event"] = event
+	line, err := json.Marshal(data)
+	if err != nil {
+		line, _ = json.Marshal(Fields{"v": EventsVersion, "event": event, "error": err.Error()})
+	}
+
+	eventsMutex.Lock()
+	defer eventsMutex.Unlock()
+	if event == EventResult {
+		resultEmitted = true
+	}
+	out.Write(append(line, '\n'))
+}
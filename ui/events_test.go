@@ -0,0 +1,97 @@
+package ui
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"strings"
+	"testing"
+)
+
+// assertEvents checks that every line of out is a JSON object of a known event
+// with the current version, and returns them.
+func assertEvents(t *testing.T, out string) []map[string]interface{} {
+	t.Helper()
+	known := map[string]bool{
+		EventAssetUpload:   true,
+		EventAssetDownload: true,
+		EventForkWait:      true,
+		EventChecksWait:    true,
+		EventResult:        true,
+	}
+	events := []map[string]interface{}{}
+	for _, line := range strings.Split(strings.TrimSuffix(out, "\n"), "\n") {
+		event := map[string]interface{}{}
+		if err := json.Unmarshal([]byte(line), &event); err != nil {
+			t.Fatalf("invalid JSON %q: %s", line, err)
+		}
+		if event["v"] != float64(EventsVersion) {
+			t.Errorf("expected version %d in %q", EventsVersion, line)
+		}
+		if name, _ := event["event"].(string); !known[name] {
+			t.Errorf("unknown event in %q", line)
+		}
+		events = append(events, event)
+	}
+	return events
+}
+
+func TestEmit(t *testing.T) {
+	out := &bytes.Buffer{}
+	emit(out, EventAssetUpload, Fields{"name": "hub.tgz", "done": int64(1048576), "total": int64(9437184)})
+	emit(out, EventResult, Fields{"command": "release create", "event": "overridden", "message": "line\nbreak"})
+
+	want := `{"done":1048576,"event":"asset-upload","name":"hub.tgz","total":9437184,"v":1}
+{"command":"release create","event":"result","message":"line\nbreak","v":1}
+`
+	if out.String() != want {
+		t.Errorf("got:\n%s\nwant:\n%s", out.String(), want)
+	}
+	assertEvents(t, out.String())
+}
+
+func TestEmit_disabled(t *testing.T) {
+	if Porcelain() {
+		t.Fatal("events shouldn't be enabled by default")
+	}
+	Emit(EventResult, Fields{"command": "fork"})
+}
+
+func TestProgress_events(t *testing.T) {
+	out := &bytes.Buffer{}
+	eventsOut = out
+	defer func() { eventsOut = nil }()
+
+	p := &Progress{Label: "Downloading hub.tgz", Total: 2048, Out: ioutil.Discard, Event: EventAssetDownload, Name: "hub.tgz"}
+	w := p.Writer(ioutil.Discard)
+	w.Write(make([]byte, 1024))
+	w.Write(make([]byte, 1024))
+	p.Done()
+	p.Done()
+
+	events := assertEvents(t, out.String())
+	if len(events) != 2 {
+		t.Fatalf("expected 2 events, got %d", len(events))
+	}
+	if events[0]["done"] != float64(1024) || events[1]["done"] != float64(2048) || events[1]["name"] != "hub.tgz" {
+		t.Errorf("unexpected events: %v", events)
+	}
+}
+
+func TestEmitFailure(t *testing.T) {
+	out := &bytes.Buffer{}
+	eventsOut, resultEmitted = out, false
+	defer func() { eventsOut, resultEmitted = nil, false }()
+
+	EmitFailure("Error creating release: Validation Failed", 1)
+	Emit(EventResult, Fields{"command": "fork"})
+	EmitFailure("exit status 128", 128)
+
+	want := `{"error":"Error creating release: Validation Failed","event":"result","exit":1,"v":1}
+{"command":"fork","event":"result","v":1}
+`
+	if out.String() != want {
+		t.Errorf("got:\n%s\nwant:\n%s", out.String(), want)
+	}
+	assertEvents(t, out.String())
+}
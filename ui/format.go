@@ -1,27 +1,96 @@
 package ui
 
 import (
+	"bytes"
+	"fmt"
 	"regexp"
 	"strconv"
 	"strings"
+	"unicode/utf8"
 )
 
 // Expand expands a format string using `git log` message syntax.
 func Expand(format string, values map[string]string, colorize bool) string {
-	f := &expander{values: values, colorize: colorize}
-	return f.Expand(format)
+	c := &compiler{}
+	return c.compile(format).Expand(values, colorize)
 }
 
-// An expander is a stateful helper to expand a format string.
-type expander struct {
-	// formatted holds the parts of the string that have already been formatted.
-	formatted []string
+// Format is a format string in `git log` message syntax that was parsed once
+// so that it can be expanded repeatedly, e.g. for every row of a listing.
+type Format struct {
+	nodes []*formatNode
 
-	// values is the map of values that should be expanded.
-	values map[string]string
+	// size is an estimate of the length of an expanded format.
+	size int
+}
 
-	// colorize is a flag to indicate whether to use colors.
-	colorize bool
+// CompileFormat parses format for later expansion. Unlike Expand, which
+// outputs malformed directives verbatim, it reports them as errors.
+func CompileFormat(format string) (*Format, error) {
+	c := &compiler{strict: true}
+	f := c.compile(format)
+	if c.err != nil {
+		return nil, c.err
+	}
+	return f, nil
+}
+
+// Uses reports whether the format may reference the placeholder name. This
+// lets callers skip computing values that are costly to obtain.
+func (f *Format) Uses(name string) bool {
+	for _, n := range f.nodes {
+		for ; n != nil; n = n.inner {
+			if n.kind == nodePlaceholder && strings.HasPrefix(n.value, name) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+type nodeKind int
+
+const (
+	// nodeText expands to nothing; only the text following it is output.
+	nodeText nodeKind = iota
+	// nodeConstant expands to its value regardless of placeholder values.
+	nodeConstant
+	// nodeColor expands to the escape sequence for its color code.
+	nodeColor
+	// nodePlaceholder expands to the value of the shortest placeholder name
+	// that its text starts with.
+	nodePlaceholder
+	// nodePad pads the expansion of its inner node.
+	nodePad
+	// nodeLineIfSet prefixes a non-empty expansion of its inner node with a
+	// line-feed.
+	nodeLineIfSet
+	// nodeSpaceIfSet prefixes a non-empty expansion of its inner node with a
+	// blank.
+	nodeSpaceIfSet
+	// nodeTrimIfEmpty removes preceding line-feeds when the expansion of its
+	// inner node is empty.
+	nodeTrimIfEmpty
+)
+
+// A formatNode is the parsed form of the text between two "%" signs of a
+// format string. Expanding it yields the expansion of its directive followed by
+// the literal text that came after the directive.
+type formatNode struct {
+	kind    nodeKind
+	value   string
+	rest    string
+	inner   *formatNode
+	padding *padder
+}
+
+// A compiler is a stateful helper to parse a format string.
+type compiler struct {
+	// strict is a flag to indicate whether to report malformed directives.
+	strict bool
+
+	// err holds the first malformed directive found in strict mode.
+	err error
 
 	// skipNext is true if the next placeholder is not a placeholder and can be
 	// output directly as such.
@@ -31,25 +100,25 @@ type expander struct {
 	padNext *padder
 }
 
-func (f *expander) Expand(format string) string {
+func (c *compiler) compile(format string) *Format {
 	parts := strings.Split(format, "%")
-	f.formatted = make([]string, 0, len(parts))
-	f.append(parts[0])
+	f := &Format{nodes: make([]*formatNode, 0, len(parts))}
+	f.nodes = append(f.nodes, &formatNode{kind: nodeText, rest: parts[0]})
+	f.size = len(format) * 2
 	for _, p := range parts[1:] {
-		v, t := f.expandOneVar(p)
-		f.append(v, t)
+		n := c.compileOneVar(p)
+		if n.kind == nodePad {
+			f.size += int(n.padding.size)
+		}
+		f.nodes = append(f.nodes, n)
 	}
-	return f.crush()
-}
-
-func (f *expander) append(formattedText ...string) {
-	f.formatted = append(f.formatted, formattedText...)
+	return f
 }
 
-func (f *expander) crush() string {
-	s := strings.Join(f.formatted, "")
-	f.formatted = nil
-	return s
+func (c *compiler) fail(format string, a ...interface{}) {
+	if c.strict && c.err == nil {
+		c.err = fmt.Errorf(format, a...)
+	}
 }
 
 var colorMap = map[string]string{
@@ -64,124 +133,211 @@ var colorMap = map[string]string{
 	"reset":   "",
 }
 
-func (f *expander) expandOneVar(format string) (expand string, untouched string) {
-	if f.skipNext {
-		f.skipNext = false
-		return "", format
+func (c *compiler) compileOneVar(format string) *formatNode {
+	if c.skipNext {
+		c.skipNext = false
+		return &formatNode{kind: nodeText, rest: format}
 	}
 	if format == "" {
-		f.skipNext = true
-		return "", "%"
+		c.skipNext = true
+		return &formatNode{kind: nodeText, rest: "%"}
 	}
 
-	if f.padNext != nil {
-		p := f.padNext
-		f.padNext = nil
-		e, u := f.expandOneVar(format)
-		return f.pad(e, p), u
+	if c.padNext != nil {
+		p := c.padNext
+		c.padNext = nil
+		return &formatNode{kind: nodePad, inner: c.compileOneVar(format), padding: p}
 	}
 
-	if e, u, ok := f.expandSpecialChar(format[0], format[1:]); ok {
-		return e, u
+	if n := c.compileSpecialChar(format[0], format[1:]); n != nil {
+		return n
 	}
 
-	if f.values != nil {
-		for i := 1; i <= len(format); i++ {
-			if v, exists := f.values[format[0:i]]; exists {
-				return v, format[i:]
-			}
-		}
-	}
-
-	return "", "%" + format
+	return &formatNode{kind: nodePlaceholder, value: format, rest: "%" + format}
 }
 
-func (f *expander) expandSpecialChar(firstChar byte, format string) (expand string, untouched string, wasExpanded bool) {
+func (c *compiler) compileSpecialChar(firstChar byte, format string) *formatNode {
 	switch firstChar {
 	case 'n':
-		return "\n", format, true
+		return &formatNode{kind: nodeConstant, value: "\n", rest: format}
 	case 'C':
 		for k, v := range colorMap {
 			if strings.HasPrefix(format, k) {
-				if f.colorize {
-					return "\033[" + v + "m", format[len(k):], true
-				}
-				return "", format[len(k):], true
+				return &formatNode{kind: nodeColor, value: v, rest: format[len(k):]}
 			}
 		}
 		// TODO: Add custom color as specified in color.branch.* options.
 		// TODO: Handle auto-coloring.
+		c.fail("unknown color in format: %%C%s", format)
 	case 'x':
 		if len(format) >= 2 {
 			if v, err := strconv.ParseInt(format[:2], 16, 32); err == nil {
-				return string(v), format[2:], true
+				return &formatNode{kind: nodeConstant, value: string(rune(v)), rest: format[2:]}
 			}
 		}
 	case '+':
-		if e, u := f.expandOneVar(format); e != "" {
-			return "\n" + e, u, true
-		} else {
-			return "", u, true
-		}
+		return &formatNode{kind: nodeLineIfSet, inner: c.compileOneVar(format)}
 	case ' ':
-		if e, u := f.expandOneVar(format); e != "" {
-			return " " + e, u, true
-		} else {
-			return "", u, true
-		}
+		return &formatNode{kind: nodeSpaceIfSet, inner: c.compileOneVar(format)}
 	case '-':
-		if e, u := f.expandOneVar(format); e != "" {
-			return e, u, true
-		} else {
-			f.append(strings.TrimRight(f.crush(), "\n"))
-			return "", u, true
-		}
+		return &formatNode{kind: nodeTrimIfEmpty, inner: c.compileOneVar(format)}
 	case '<', '>':
 		if m := paddingPattern.FindStringSubmatch(string(firstChar) + format); len(m) == 7 {
 			if p := padderFromConfig(m[1], m[2], m[3], m[4], m[5]); p != nil {
-				f.padNext = p
-				return "", m[6], true
+				c.padNext = p
+				return &formatNode{kind: nodeText, rest: m[6]}
+			}
+		}
+		c.fail("unclosed or invalid padding in format: %%%c%s", firstChar, format)
+	}
+	return nil
+}
+
+// Expand expands the format with the given placeholder values.
+func (f *Format) Expand(values map[string]string, colorize bool) string {
+	e := &expander{
+		formatted: make([]byte, 0, f.size),
+		values:    values,
+		colorize:  colorize,
+	}
+	for _, n := range f.nodes {
+		e.formatted = append(e.formatted, e.expand(n)...)
+	}
+	return string(e.formatted)
+}
+
+// An expander is a stateful helper to expand a compiled format.
+type expander struct {
+	// formatted holds the parts of the string that have already been formatted.
+	formatted []byte
+
+	// values is the map of values that should be expanded.
+	values map[string]string
+
+	// colorize is a flag to indicate whether to use colors.
+	colorize bool
+
+	// scratch holds the expansion that is being padded.
+	scratch []byte
+}
+
+// expand appends the expansion of the directive of n to the formatted text and
+// returns the literal text that should follow it.
+func (f *expander) expand(n *formatNode) (untouched string) {
+	switch n.kind {
+	case nodeConstant:
+		f.formatted = append(f.formatted, n.value...)
+	case nodeColor:
+		if f.colorize {
+			f.formatted = append(f.formatted, "\033["...)
+			f.formatted = append(f.formatted, n.value...)
+			f.formatted = append(f.formatted, 'm')
+		}
+	case nodePlaceholder:
+		for i := 1; i <= len(n.value); i++ {
+			if v, exists := f.values[n.value[0:i]]; exists {
+				f.formatted = append(f.formatted, v...)
+				return n.value[i:]
 			}
 		}
+	case nodePad:
+		start := len(f.formatted)
+		untouched = f.expand(n.inner)
+		f.pad(start, n.padding)
+		return
+	case nodeLineIfSet:
+		return f.expandIfSet(n.inner, '\n')
+	case nodeSpaceIfSet:
+		return f.expandIfSet(n.inner, ' ')
+	case nodeTrimIfEmpty:
+		start := len(f.formatted)
+		untouched = f.expand(n.inner)
+		if len(f.formatted) <= start {
+			f.formatted = bytes.TrimRight(f.formatted, "\n")
+		}
+		return
+	}
+	return n.rest
+}
+
+// expandIfSet expands n and prefixes the expansion with c unless it's empty.
+func (f *expander) expandIfSet(n *formatNode, c byte) (untouched string) {
+	start := len(f.formatted)
+	untouched = f.expand(n)
+	if len(f.formatted) > start {
+		f.formatted = append(f.formatted, 0)
+		copy(f.formatted[start+1:], f.formatted[start:])
+		f.formatted[start] = c
 	}
-	return "", "", false
+	return
 }
 
-func (f *expander) pad(s string, p *padder) string {
+// pad pads or truncates the text that was formatted after start.
+func (f *expander) pad(start int, p *padder) {
+	// the expansion could have trimmed text before it
+	if start > len(f.formatted) {
+		start = len(f.formatted)
+	}
+	previous, s := f.formatted[:start], f.formatted[start:]
+
 	size := int(p.size)
 	if p.sizeAsColumn {
-		previous := f.crush()
-		f.append(previous)
-		size -= len(previous) - strings.LastIndex(previous, "\n") - 1
+		lastLine := previous[bytes.LastIndexByte(previous, '\n')+1:]
+		size -= visibleLen(lastLine)
 	}
 
-	numPadding := size - len(s)
+	numPadding := size - visibleLen(s)
 	if numPadding == 0 {
-		return s
+		return
 	}
 
 	if numPadding < 0 {
+		// s is about to be moved, so it mustn't share memory with formatted
+		f.scratch = append(f.scratch[:0], s...)
+		s = f.scratch
+		f.formatted = previous
+
 		if p.usePreviousSpace {
-			previous := f.crush()
-			noBlanks := strings.TrimRight(previous, " ")
-			f.append(noBlanks)
-			numPadding += len(previous) - len(noBlanks)
+			noBlanks := bytes.TrimRight(f.formatted, " ")
+			numPadding += len(f.formatted) - len(noBlanks)
+			f.formatted = noBlanks
 		}
 
 		if numPadding <= 0 {
-			return p.truncate(s, -numPadding)
+			f.formatted = p.truncate(f.formatted, s, -numPadding)
+			return
 		}
+		f.formatted = append(f.formatted, s...)
 	}
 
+	start = len(f.formatted) - len(s)
 	switch p.orientation {
 	case padLeft:
-		return strings.Repeat(" ", numPadding) + s
+		f.insertBlanks(start, numPadding)
 	case padMiddle:
-		return strings.Repeat(" ", numPadding/2) + s + strings.Repeat(" ", (numPadding+1)/2)
+		f.insertBlanks(start, numPadding/2)
+		f.appendBlanks((numPadding + 1) / 2)
+	default:
+		// Pad right by default.
+		f.appendBlanks(numPadding)
 	}
+}
+
+// insertBlanks inserts n blanks into the formatted text at offset i.
+func (f *expander) insertBlanks(i, n int) {
+	f.appendBlanks(n)
+	copy(f.formatted[i+n:], f.formatted[i:len(f.formatted)-n])
+	copy(f.formatted[i:i+n], blanks)
+}
+
+const blanks = "                                                                "
 
-	// Pad right by default.
-	return s + strings.Repeat(" ", numPadding)
+// appendBlanks appends n blanks to the formatted text.
+func (f *expander) appendBlanks(n int) {
+	for ; n > len(blanks); n -= len(blanks) {
+		f.formatted = append(f.formatted, blanks...)
+	}
+	f.formatted = append(f.formatted, blanks[:n]...)
 }
 
 type paddingOrientation int
@@ -243,22 +399,106 @@ func padderFromConfig(alsoLeft, orientation, asColumn, size, trunc string) *padd
 	return p
 }
 
-func (p *padder) truncate(s string, numReduce int) string {
+// truncate appends s to b, shortened by numReduce characters. Only visible
+// characters are dropped, so that colors are still reset and hyperlinks still
+// terminated by the escape sequences that s ends with.
+func (p *padder) truncate(b, s []byte, numReduce int) []byte {
 	if numReduce == 0 {
-		return s
+		return append(b, s...)
 	}
-	numLeft := len(s) - numReduce - 2
+	numVisible := visibleLen(s)
+	numLeft := numVisible - numReduce - 2
 	if numLeft < 0 {
 		numLeft = 0
 	}
 
+	// the characters from cutStart up to cutEnd are replaced with ".."
+	var cutStart, cutEnd int
 	switch p.truncing {
 	case truncRight:
-		return ".." + s[len(s)-numLeft:len(s)]
+		cutStart, cutEnd = 0, numVisible-numLeft
 	case truncMiddle:
-		return s[:numLeft/2] + ".." + s[len(s)-(numLeft+1)/2:len(s)]
+		cutStart, cutEnd = numLeft/2, numVisible-(numLeft+1)/2
+	default:
+		// Trunc left by default.
+		cutStart, cutEnd = numLeft, numVisible
+	}
+
+	n := 0
+	for i := 0; i < len(s); {
+		if l := escapeLen(s[i:]); l > 0 {
+			b = append(b, s[i:i+l]...)
+			i += l
+			continue
+		}
+		_, size := utf8.DecodeRune(s[i:])
+		if n == cutStart {
+			b = append(b, ".."...)
+		}
+		if n < cutStart || n >= cutEnd {
+			b = append(b, s[i:i+size]...)
+		}
+		n++
+		i += size
+	}
+	return b
+}
+
+// escapeLen is the length of the color or hyperlink escape sequence at the
+// start of s, or 0 if s doesn't start with one.
+func escapeLen(s []byte) int {
+	if len(s) < 2 || s[0] != '\033' {
+		return 0
+	}
+	switch s[1] {
+	case '[':
+		i := 2
+		for i < len(s) && (s[i] >= '0' && s[i] <= '9' || s[i] == ';') {
+			i++
+		}
+		if i < len(s) && s[i] == 'm' {
+			return i + 1
+		}
+	case ']':
+		if !bytes.HasPrefix(s[2:], []byte("8;")) {
+			return 0
+		}
+		for i := 4; i < len(s); i++ {
+			switch s[i] {
+			case '\a':
+				return i + 1
+			case '\033':
+				if i+1 < len(s) && s[i+1] == '\\' {
+					return i + 2
+				}
+				return 0
+			}
+		}
 	}
+	return 0
+}
+
+// visibleLen is the number of characters of s as displayed in a terminal, i.e.
+// without any escape sequences.
+func visibleLen(s []byte) int {
+	if bytes.IndexByte(s, '\033') < 0 {
+		return utf8.RuneCount(s)
+	}
+	n := 0
+	for i := 0; i < len(s); {
+		if l := escapeLen(s[i:]); l > 0 {
+			i += l
+			continue
+		}
+		_, size := utf8.DecodeRune(s[i:])
+		n++
+		i += size
+	}
+	return n
+}
 
-	// Trunc left by default.
-	return s[:numLeft] + ".."
+// Hyperlink wraps text in an OSC 8 escape sequence that makes it a clickable
+// link to url in terminals that support it.
+func Hyperlink(url, text string) string {
+	return "\033]8;;" + url + "\033\\" + text + "\033]8;;\033\\"
 }
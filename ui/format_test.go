@@ -1,6 +1,7 @@
 package ui
 
 import (
+	"fmt"
 	"testing"
 )
 
@@ -17,6 +18,11 @@ func testExpander(t *testing.T, tests []expanderTest) {
 		if got := Expand(test.format, test.values, test.colorize); got != test.expect {
 			t.Errorf("%s: Expand(%q, ...) = %q, want %q", test.name, test.format, got, test.expect)
 		}
+		if f, err := CompileFormat(test.format); err == nil {
+			if got := f.Expand(test.values, test.colorize); got != test.expect {
+				t.Errorf("%s: CompileFormat(%q).Expand(...) = %q, want %q", test.name, test.format, got, test.expect)
+			}
+		}
 	}
 }
 
@@ -169,5 +175,111 @@ func TestExpand_Truncing(t *testing.T) {
 			values: map[string]string{"a": "0123456"},
 			expect: "|0123..",
 		},
+		{
+			name:   "padding ignores hyperlink escape sequences",
+			format: "%>(5)%a|",
+			values: map[string]string{"a": Hyperlink("https://github.com/", "#42")},
+			expect: "  \033]8;;https://github.com/\033\\#42\033]8;;\033\\|",
+		},
+		{
+			name:     "column padding ignores color escape sequences",
+			format:   "%Cred%a%Creset%<|(6)%b|",
+			values:   map[string]string{"a": "ab", "b": "cd"},
+			colorize: true,
+			expect:   "\033[31mab\033[mcd  |",
+		},
+		{
+			name:   "truncing keeps the color reset",
+			format: "%<(5,trunc)%a|",
+			values: map[string]string{"a": "\033[31m0123456\033[m"},
+			expect: "\033[31m012..\033[m|",
+		},
+		{
+			name:   "truncing keeps the hyperlink terminator",
+			format: "%<(4,rtrunc)%a|",
+			values: map[string]string{"a": Hyperlink("https://github.com/", "#4242")},
+			expect: "\033]8;;https://github.com/\033\\..42\033]8;;\033\\|",
+		},
+		{
+			name:   "truncing counts characters rather than bytes",
+			format: "%<(6,mtrunc)%a|",
+			values: map[string]string{"a": "Ünïcödé"},
+			expect: "Ün..dé|",
+		},
 	})
 }
+
+func TestCompileFormat_Errors(t *testing.T) {
+	tests := []struct {
+		format string
+		err    string
+	}{
+		{"%Cpurple%t", "unknown color in format: %Cpurple"},
+		{"%<(10%t", "unclosed or invalid padding in format: %<(10"},
+		{"%<(1a)%a", "unclosed or invalid padding in format: %<(1a)"},
+		{"%>%t", "unclosed or invalid padding in format: %>"},
+	}
+	for _, test := range tests {
+		_, err := CompileFormat(test.format)
+		if err == nil || err.Error() != test.err {
+			t.Errorf("CompileFormat(%q) error = %v, want %q", test.format, err, test.err)
+		}
+	}
+
+	if _, err := CompileFormat("%pC%>(8)%i%Creset  %t%  l%n"); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
+
+func TestFormat_Uses(t *testing.T) {
+	f, err := CompileFormat("%i %<(10)%ab%  l%%ab")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for name, want := range map[string]bool{"i": true, "ab": true, "l": true, "t": false, "au": false} {
+		if got := f.Uses(name); got != want {
+			t.Errorf("Uses(%q) = %t, want %t", name, got, want)
+		}
+	}
+}
+
+const benchmarkFormat = "%pC%>(8)%i%Creset  %<(50,trunc)%t%  l%>|(110)%<(12)%au %<(20,trunc)%H %<(20,trunc)%B %<(14)%cr%+b%n"
+
+func benchmarkValues() []map[string]string {
+	rows := make([]map[string]string, 500)
+	for i := range rows {
+		rows[i] = map[string]string{
+			"pC": "\033[32m",
+			"i":  fmt.Sprintf("#%d", i),
+			"t":  "Improve the performance of expanding format strings in listings",
+			"l":  " \033[38;5;1mbug\033[m \033[38;5;2mperformance\033[m",
+			"au": "mislav",
+			"H":  "feature/precompiled-formats",
+			"B":  "master",
+			"cr": "3 days ago",
+			"b":  "",
+		}
+	}
+	return rows
+}
+
+func BenchmarkExpand(b *testing.B) {
+	rows := benchmarkValues()
+	b.ResetTimer()
+	for n := 0; n < b.N; n++ {
+		for _, values := range rows {
+			Expand(benchmarkFormat, values, true)
+		}
+	}
+}
+
+func BenchmarkFormat_Expand(b *testing.B) {
+	rows := benchmarkValues()
+	b.ResetTimer()
+	for n := 0; n < b.N; n++ {
+		f, _ := CompileFormat(benchmarkFormat)
+		for _, values := range rows {
+			f.Expand(values, true)
+		}
+	}
+}
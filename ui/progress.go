@@ -0,0 +1,110 @@
+package ui
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"time"
+)
+
+// Progress reports on stderr how much of a large transfer has been written.
+// Nothing is reported unless stderr is a terminal. With Event set, the progress
+// is also emitted as that event about Name for '--porcelain'.
+type Progress struct {
+	Label   string
+	Total   int64
+	Out     io.Writer
+	Event   string
+	Name    string
+	written int64
+	shown   time.Time
+	emitted time.Time
+	reached int64
+	enabled bool
+}
+
+// NewProgress returns a Progress for a transfer of total bytes, or of unknown
+// size if total is negative.
+func NewProgress(label string, total int64) *Progress {
+	return &Progress{
+		Label:   label,
+		Total:   total,
+		Out:     Stderr,
+		enabled: IsTerminal(os.Stderr),
+	}
+}
+
+// Writer wraps w so that everything written through it counts towards the
+// progress.
+func (p *Progress) Writer(w io.Writer) io.Writer {
+	return &progressWriter{w, p}
+}
+
+// Done prints the final state of the transfer and ends the progress line.
+func (p *Progress) Done() {
+	p.emit()
+	if p.enabled {
+		p.print()
+		fmt.Fprintln(p.Out)
+	}
+}
+
+func (p *Progress) add(n int) {
+	p.written += int64(n)
+	if time.Since(p.emitted) > 100*time.Millisecond {
+		p.emit()
+	}
+	if p.enabled && time.Since(p.shown) > 100*time.Millisecond {
+		p.print()
+		p.shown = time.Now()
+	}
+}
+
+// emit reports the progress unless that was done already.
+func (p *Progress) emit() {
+	if p.Event == "" || !Porcelain() || (!p.emitted.IsZero() && p.written == p.reached) {
+		return
+	}
+	Emit(p.Event, Fields{"name": p.Name, "done": p.written, "total": p.Total})
+	p.emitted = time.Now()
+	p.reached = p.written
+}
+
+func (p *Progress) print() {
+	if p.Total > 0 {
+		fmt.Fprintf(p.Out, "\r%s: %3d%% (%s / %s)", p.Label, p.written*100/p.Total, formatBytes(p.written), formatBytes(p.Total))
+	} else {
+		fmt.Fprintf(p.Out, "\r%s: %s", p.Label, formatBytes(p.written))
+	}
+}
+
+type progressWriter struct {
+	io.Writer
+	progress *Progress
+}
+
+func (w *progressWriter) Write(b []byte) (int, error) {
+	n, err := w.Writer.Write(b)
+	w.progress.add(n)
+	return n, err
+}
+
+// formatBytes matches utils.HumanSize, which can't be used here since the
+// utils package depends on this one.
+func formatBytes(n int64) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%d B", n)
+	}
+	div, exp := int64(unit), 0
+	for m := n / unit; m >= unit; m /= unit {
+		div *= unit
+		exp++
+	}
+	value := float64(n) / float64(div)
+	if value >= unit-0.05 && exp < 5 {
+		value /= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %ciB", value, "KMGTPE"[exp])
+}
@@ -0,0 +1,44 @@
+package ui
+
+import (
+	"bytes"
+	"io/ioutil"
+	"testing"
+)
+
+func TestProgress(t *testing.T) {
+	out := &bytes.Buffer{}
+	p := &Progress{Label: "Downloading hub.tgz", Total: 2048, Out: out, enabled: true}
+	w := p.Writer(ioutil.Discard)
+	w.Write(make([]byte, 1024))
+	w.Write(make([]byte, 1024))
+	p.Done()
+
+	want := "\rDownloading hub.tgz:  50% (1.0 KiB / 2.0 KiB)\rDownloading hub.tgz: 100% (2.0 KiB / 2.0 KiB)\n"
+	if got := out.String(); got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
+func TestProgress_disabled(t *testing.T) {
+	out := &bytes.Buffer{}
+	p := &Progress{Label: "Downloading", Total: -1, Out: out}
+	p.Writer(ioutil.Discard).Write([]byte("data"))
+	p.Done()
+
+	if out.Len() != 0 {
+		t.Errorf("expected no output, got %q", out.String())
+	}
+}
+
+func TestFormatBytes(t *testing.T) {
+	for n, want := range map[int64]string{
+		512:         "512 B",
+		1536:        "1.5 KiB",
+		5 * 1 << 20: "5.0 MiB",
+	} {
+		if got := formatBytes(n); got != want {
+			t.Errorf("formatBytes(%d) = %q, want %q", n, got, want)
+		}
+	}
+}
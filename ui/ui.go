@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"io"
 	"os"
+	"strconv"
 
 	"github.com/mattn/go-colorable"
 	"github.com/mattn/go-isatty"
@@ -72,6 +73,30 @@ func IsTerminal(f *os.File) bool {
 	return isatty.IsTerminal(f.Fd())
 }
 
+var hyperlinkTerminals = []string{
+	"iTerm.app",
+	"WezTerm",
+	"vscode",
+	"Hyper",
+}
+
+// TerminalSupportsHyperlinks reports whether the terminal that hub is running
+// in is known to render OSC 8 hyperlinks.
+func TerminalSupportsHyperlinks() bool {
+	program := os.Getenv("TERM_PROGRAM")
+	for _, t := range hyperlinkTerminals {
+		if program == t {
+			return true
+		}
+	}
+	if vte, err := strconv.Atoi(os.Getenv("VTE_VERSION")); err == nil && vte >= 5000 {
+		return true
+	}
+	return os.Getenv("TERM") == "xterm-kitty" ||
+		os.Getenv("WT_SESSION") != "" ||
+		os.Getenv("KONSOLE_VERSION") != ""
+}
+
 type Console struct {
 	Stdout io.Writer
 	Stderr io.Writer
@@ -103,6 +103,7 @@ func (p *ArgsParser) Parse(args []string) ([]string, error) {
 			}
 		} else if strings.HasPrefix(arg, "--") {
 			flagName = arg
+			flagValue = ""
 			eq := strings.IndexByte(arg, '=')
 			hasFlagValue = eq >= 0
 			if hasFlagValue {
@@ -183,6 +184,20 @@ func (p *ArgsParser) HasReceived(name string) bool {
 	return found && len(f.values) > 0
 }
 
+// HasFlag reports whether name is a flag that the parser knows about.
+func (p *ArgsParser) HasFlag(name string) bool {
+	_, found := p.flagMap[name]
+	return found
+}
+
+// AddValue gives the flag name another value as if it had been parsed. Flags
+// that don't take a value get an empty one.
+func (p *ArgsParser) AddValue(name, value string) {
+	if f, found := p.flagMap[name]; found {
+		f.addValue(value)
+	}
+}
+
 func NewArgsParser() *ArgsParser {
 	return &ArgsParser{
 		flagMap:     make(map[string]*argsFlag),
@@ -199,3 +199,37 @@ func TestArgsParser_WithUsage(t *testing.T) {
 	equal(t, true, p.Bool("--draft"))
 	equal(t, "hello", p.Value("--message"))
 }
+
+func TestArgsParser_BoolWithOptionalValue(t *testing.T) {
+	p := NewArgsParserWithUsage(`
+		-m, --message MSG
+		--color[=<WHEN>]
+	`)
+	rest, err := p.Parse([]string{"-m", "hello", "--color"})
+	equal(t, nil, err)
+	equal(t, []string{}, rest)
+	equal(t, true, p.HasReceived("--color"))
+	equal(t, "", p.Value("--color"))
+
+	_, err = p.Parse([]string{"--color=never"})
+	equal(t, nil, err)
+	equal(t, "never", p.Value("--color"))
+}
+
+func TestArgsParser_AddValue(t *testing.T) {
+	p := NewArgsParser()
+	p.RegisterValue("--origin", "-o")
+	p.RegisterBool("--draft")
+	_, err := p.Parse([]string{"-o", "a"})
+	equal(t, nil, err)
+	equal(t, true, p.HasFlag("--origin"))
+	equal(t, false, p.HasFlag("-o"))
+	equal(t, false, p.HasFlag("--bogus"))
+
+	p.AddValue("--origin", "b")
+	p.AddValue("--draft", "")
+	p.AddValue("--bogus", "c")
+	equal(t, []string{"a", "b"}, p.AllValues("--origin"))
+	equal(t, true, p.Bool("--draft"))
+	equal(t, false, p.HasReceived("--bogus"))
+}
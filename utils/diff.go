@@ -0,0 +1,149 @@
+package utils
+
+import (
+	"fmt"
+	"strings"
+)
+
+type diffOp struct {
+	kind byte // ' ', '-' or '+'
+	line string
+}
+
+// UnifiedDiff compares two texts line by line and returns the differences in
+// the unified format of diff(1), with contextLines of unchanged lines around
+// each change. Identical texts result in an empty string.
+func UnifiedDiff(fromName, toName, from, to string, contextLines int) string {
+	ops := diffLines(splitLines(from), splitLines(to))
+
+	changed := false
+	for _, op := range ops {
+		if op.kind != ' ' {
+			changed = true
+			break
+		}
+	}
+	if !changed {
+		return ""
+	}
+
+	out := fmt.Sprintf("--- %s\n+++ %s\n", fromName, toName)
+	for start := 0; start < len(ops); {
+		// find the next change and the hunk of changes close enough to it
+		first := start
+		for first < len(ops) && ops[first].kind == ' ' {
+			first++
+		}
+		if first == len(ops) {
+			break
+		}
+		last := first
+		for i := first; i < len(ops); i++ {
+			if ops[i].kind != ' ' {
+				last = i
+			} else if i-last > 2*contextLines {
+				break
+			}
+		}
+
+		hunkStart := first - contextLines
+		if hunkStart < start {
+			hunkStart = start
+		}
+		hunkEnd := last + contextLines + 1
+		if hunkEnd > len(ops) {
+			hunkEnd = len(ops)
+		}
+		out += diffHunk(ops, hunkStart, hunkEnd)
+		start = hunkEnd
+	}
+	return out
+}
+
+func diffHunk(ops []diffOp, hunkStart, hunkEnd int) string {
+	fromLine, toLine := 0, 0
+	for _, op := range ops[:hunkStart] {
+		if op.kind != '+' {
+			fromLine++
+		}
+		if op.kind != '-' {
+			toLine++
+		}
+	}
+
+	fromCount, toCount := 0, 0
+	body := ""
+	for _, op := range ops[hunkStart:hunkEnd] {
+		if op.kind != '+' {
+			fromCount++
+		}
+		if op.kind != '-' {
+			toCount++
+		}
+		body += fmt.Sprintf("%c%s\n", op.kind, op.line)
+	}
+
+	return fmt.Sprintf("@@ -%s +%s @@\n%s", hunkRange(fromLine, fromCount), hunkRange(toLine, toCount), body)
+}
+
+// hunkRange formats the line numbers of a hunk like diff(1) does, where an
+// empty range refers to the line before it.
+func hunkRange(offset, count int) string {
+	if count == 1 {
+		return fmt.Sprintf("%d", offset+1)
+	} else if count == 0 {
+		return fmt.Sprintf("%d,0", offset)
+	}
+	return fmt.Sprintf("%d,%d", offset+1, count)
+}
+
+func splitLines(text string) []string {
+	text = strings.Replace(text, "\r\n", "\n", -1)
+	if text == "" {
+		return []string{}
+	}
+	return strings.Split(strings.TrimSuffix(text, "\n"), "\n")
+}
+
+// diffLines lines up the longest common subsequence of both texts, leaving
+// the rest as removed and added lines.
+func diffLines(a, b []string) []diffOp {
+	lcs := make([][]int, len(a)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else if lcs[i+1][j] >= lcs[i][j+1] {
+				lcs[i][j] = lcs[i+1][j]
+			} else {
+				lcs[i][j] = lcs[i][j+1]
+			}
+		}
+	}
+
+	ops := []diffOp{}
+	i, j := 0, 0
+	for i < len(a) && j < len(b) {
+		if a[i] == b[j] {
+			ops = append(ops, diffOp{' ', a[i]})
+			i++
+			j++
+		} else if lcs[i+1][j] >= lcs[i][j+1] {
+			ops = append(ops, diffOp{'-', a[i]})
+			i++
+		} else {
+			ops = append(ops, diffOp{'+', b[j]})
+			j++
+		}
+	}
+	for ; i < len(a); i++ {
+		ops = append(ops, diffOp{'-', a[i]})
+	}
+	for ; j < len(b); j++ {
+		ops = append(ops, diffOp{'+', b[j]})
+	}
+	return ops
+}
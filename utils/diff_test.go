@@ -0,0 +1,63 @@
+package utils
+
+import (
+	"testing"
+
+	"github.com/bmizerany/assert"
+)
+
+func TestUnifiedDiff_Identical(t *testing.T) {
+	assert.Equal(t, "", UnifiedDiff("a", "b", "one\ntwo\n", "one\ntwo", 3))
+	assert.Equal(t, "", UnifiedDiff("a", "b", "one\r\ntwo\r\n", "one\ntwo\n", 3))
+	assert.Equal(t, "", UnifiedDiff("a", "b", "", "", 3))
+}
+
+func TestUnifiedDiff_Changes(t *testing.T) {
+	from := "a\nb\nc\nd\ne\nf\ng\nh\ni\nj\nk\n"
+	to := "a\nB\nc\nd\ne\nf\ng\nh\ni\nj\nk\nl\n"
+
+	expected := `--- v1
++++ v2
+@@ -1,3 +1,3 @@
+ a
+-b
++B
+ c
+@@ -11 +11,2 @@
+ k
++l
+`
+	assert.Equal(t, expected, UnifiedDiff("v1", "v2", from, to, 1))
+}
+
+func TestUnifiedDiff_MergesCloseHunks(t *testing.T) {
+	expected := `--- v1
++++ v2
+@@ -1,5 +1,5 @@
+-a
++A
+ b
+ c
+-d
++D
+ e
+`
+	assert.Equal(t, expected, UnifiedDiff("v1", "v2", "a\nb\nc\nd\ne\n", "A\nb\nc\nD\ne\n", 1))
+}
+
+func TestUnifiedDiff_EmptySide(t *testing.T) {
+	expected := `--- v1
++++ v2
+@@ -0,0 +1,2 @@
++hello
++world
+`
+	assert.Equal(t, expected, UnifiedDiff("v1", "v2", "", "hello\nworld", 3))
+
+	expected = `--- v1
++++ v2
+@@ -1 +0,0 @@
+-hello
+`
+	assert.Equal(t, expected, UnifiedDiff("v1", "v2", "hello\n", "", 3))
+}
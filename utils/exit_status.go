@@ -0,0 +1,46 @@
+package utils
+
+// Exit statuses that hub commands share so that scripts can tell apart why a
+// command failed. Commands may document additional statuses for their own
+// outcomes, e.g. 'api' exits with 22 for HTTP errors like curl does.
+const (
+	ExitSuccess     = 0
+	ExitError       = 1 // generic failure, including API validation errors
+	ExitPending     = 2 // the outcome isn't final yet or was only partially successful
+	ExitNotFound    = 3
+	ExitAuth        = 4 // authentication failed, access was denied, or rate limit exceeded
+	ExitUsage       = 5
+	ExitInterrupted = 130
+)
+
+type exitStatusError struct {
+	error
+	status int
+}
+
+func (e *exitStatusError) ExitStatus() int {
+	return e.status
+}
+
+// WithExitStatus annotates err so that hub exits with status when it aborts
+// because of it.
+func WithExitStatus(err error, status int) error {
+	if err == nil {
+		return nil
+	}
+	return &exitStatusError{error: err, status: status}
+}
+
+// ExitStatus returns what hub should exit with when it aborts because of err.
+// Errors that don't call for any particular status result in ExitError.
+func ExitStatus(err error) int {
+	if err == nil {
+		return ExitSuccess
+	}
+	if e, ok := err.(interface {
+		ExitStatus() int
+	}); ok {
+		return e.ExitStatus()
+	}
+	return ExitError
+}
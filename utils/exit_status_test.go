@@ -0,0 +1,19 @@
+package utils
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/bmizerany/assert"
+)
+
+func TestExitStatus(t *testing.T) {
+	assert.Equal(t, ExitSuccess, ExitStatus(nil))
+	assert.Equal(t, ExitError, ExitStatus(errors.New("oops")))
+
+	err := WithExitStatus(errors.New("no such repository"), ExitNotFound)
+	assert.Equal(t, "no such repository", err.Error())
+	assert.Equal(t, ExitNotFound, ExitStatus(err))
+
+	assert.Equal(t, nil, WithExitStatus(nil, ExitUsage))
+}
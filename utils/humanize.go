@@ -0,0 +1,39 @@
+package utils
+
+import (
+	"fmt"
+	"strconv"
+)
+
+// HumanSize formats a number of bytes in binary units with one decimal, e.g.
+// "512 B", "1.5 KiB" or "3.2 GiB". The output doesn't depend on the locale.
+func HumanSize(bytes int64) string {
+	return humanize(float64(bytes), 1024, " ", []string{"B", "KiB", "MiB", "GiB", "TiB", "PiB", "EiB"})
+}
+
+// HumanCount abbreviates large counts with one decimal, e.g. "999", "1.2k" or
+// "3.4M". The output doesn't depend on the locale.
+func HumanCount(n int) string {
+	return humanize(float64(n), 1000, "", []string{"", "k", "M", "B", "T"})
+}
+
+func humanize(value, base float64, separator string, units []string) string {
+	// negating the value as an integer would overflow for the smallest one
+	if value < 0 {
+		return "-" + humanize(-value, base, separator, units)
+	}
+	if value < base {
+		return strconv.FormatFloat(value, 'f', 0, 64) + separator + units[0]
+	}
+	exp := 0
+	for value >= base && exp < len(units)-1 {
+		value /= base
+		exp++
+	}
+	// don't let rounding produce e.g. "1024.0 KiB"
+	if value >= base-0.05 && exp < len(units)-1 {
+		value /= base
+		exp++
+	}
+	return fmt.Sprintf("%.1f%s%s", value, separator, units[exp])
+}
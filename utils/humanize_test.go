@@ -0,0 +1,32 @@
+package utils
+
+import (
+	"math"
+	"testing"
+
+	"github.com/bmizerany/assert"
+)
+
+func TestHumanSize(t *testing.T) {
+	assert.Equal(t, "0 B", HumanSize(0))
+	assert.Equal(t, "1023 B", HumanSize(1023))
+	assert.Equal(t, "1.0 KiB", HumanSize(1024))
+	assert.Equal(t, "1.5 KiB", HumanSize(1536))
+	assert.Equal(t, "1.0 MiB", HumanSize(1024*1024-1))
+	assert.Equal(t, "500.0 MiB", HumanSize(500*1024*1024))
+	assert.Equal(t, "2.3 GiB", HumanSize(2500000000))
+	assert.Equal(t, "-1.5 KiB", HumanSize(-1536))
+	assert.Equal(t, "8.0 EiB", HumanSize(math.MaxInt64))
+	assert.Equal(t, "-8.0 EiB", HumanSize(math.MinInt64))
+}
+
+func TestHumanCount(t *testing.T) {
+	assert.Equal(t, "0", HumanCount(0))
+	assert.Equal(t, "999", HumanCount(999))
+	assert.Equal(t, "1.0k", HumanCount(1000))
+	assert.Equal(t, "1.2k", HumanCount(1234))
+	assert.Equal(t, "1.0M", HumanCount(999999))
+	assert.Equal(t, "3.4M", HumanCount(3400000))
+	assert.Equal(t, "-12", HumanCount(-12))
+	assert.Equal(t, "-"+HumanCount(math.MaxInt), HumanCount(math.MinInt))
+}
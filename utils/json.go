@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
+	"strconv"
 	"strings"
 )
 
@@ -98,3 +99,44 @@ func JSONPath(out io.Writer, src io.Reader, colorize bool) {
 		}
 	}
 }
+
+// JSONQuery extracts the value at a dot-separated path such as
+// "head.repo.name" or "labels.0" from decoded JSON data.
+func JSONQuery(data interface{}, query string) (interface{}, error) {
+	value := data
+	for _, key := range strings.Split(strings.TrimPrefix(query, "."), ".") {
+		switch v := value.(type) {
+		case map[string]interface{}:
+			found, ok := v[key]
+			if !ok {
+				return nil, fmt.Errorf("query %q: key %q not found", query, key)
+			}
+			value = found
+		case []interface{}:
+			index, err := strconv.Atoi(key)
+			if err != nil || index < 0 || index >= len(v) {
+				return nil, fmt.Errorf("query %q: invalid index %q for an array of %d items", query, key, len(v))
+			}
+			value = v[index]
+		default:
+			return nil, fmt.Errorf("query %q: cannot look up %q in a value that is not an object or array", query, key)
+		}
+	}
+	return value, nil
+}
+
+// JSONQueryValue formats the result of JSONQuery for printing: strings are
+// printed as-is, null as an empty string, and other values as JSON.
+func JSONQueryValue(value interface{}) (string, error) {
+	switch v := value.(type) {
+	case nil:
+		return "", nil
+	case string:
+		return v, nil
+	case map[string]interface{}, []interface{}:
+		out, err := json.MarshalIndent(v, "", "  ")
+		return string(out), err
+	}
+	out, err := json.Marshal(value)
+	return string(out), err
+}
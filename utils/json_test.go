@@ -0,0 +1,56 @@
+package utils
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/bmizerany/assert"
+)
+
+func decodeTestJSON(t *testing.T, data string) interface{} {
+	var value interface{}
+	dec := json.NewDecoder(strings.NewReader(data))
+	dec.UseNumber()
+	assert.Equal(t, nil, dec.Decode(&value))
+	return value
+}
+
+func TestJSONQuery(t *testing.T) {
+	data := decodeTestJSON(t, `{"number": 42, "head": {"ref": "feature", "repo": null}, "labels": ["bug", "ui"]}`)
+
+	value, err := JSONQuery(data, "head.ref")
+	assert.Equal(t, nil, err)
+	assert.Equal(t, "feature", value)
+
+	value, err = JSONQuery(data, ".labels.1")
+	assert.Equal(t, nil, err)
+	assert.Equal(t, "ui", value)
+
+	value, err = JSONQuery(data, "number")
+	assert.Equal(t, nil, err)
+	out, _ := JSONQueryValue(value)
+	assert.Equal(t, "42", out)
+
+	value, err = JSONQuery(data, "head.repo")
+	assert.Equal(t, nil, err)
+	out, _ = JSONQueryValue(value)
+	assert.Equal(t, "", out)
+
+	value, _ = JSONQuery(data, "labels")
+	out, _ = JSONQueryValue(value)
+	assert.Equal(t, "[\n  \"bug\",\n  \"ui\"\n]", out)
+}
+
+func TestJSONQuery_Errors(t *testing.T) {
+	data := decodeTestJSON(t, `{"head": {"ref": "feature"}, "labels": ["bug"]}`)
+
+	_, err := JSONQuery(data, "head.sha")
+	assert.Equal(t, `query "head.sha": key "sha" not found`, err.Error())
+
+	_, err = JSONQuery(data, "labels.3")
+	assert.Equal(t, `query "labels.3": invalid index "3" for an array of 1 items`, err.Error())
+
+	_, err = JSONQuery(data, "head.ref.name")
+	assert.Equal(t, `query "head.ref.name": cannot look up "name" in a value that is not an object or array`, err.Error())
+}
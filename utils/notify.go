@@ -0,0 +1,78 @@
+package utils
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+)
+
+// A Notifier lets the user know that something they were waiting for has
+// happened, e.g. with a desktop notification.
+type Notifier interface {
+	Notify(title, message string) error
+}
+
+// DefaultNotifier is used by commands that take '--notify'. Tests can replace
+// it with a fake.
+var DefaultNotifier Notifier = &SystemNotifier{
+	GOOS:     runtime.GOOS,
+	LookPath: exec.LookPath,
+	Run: func(name string, args ...string) error {
+		return exec.Command(name, args...).Run()
+	},
+	Bell: os.Stderr,
+}
+
+// SystemNotifier shows desktop notifications with the tools of the operating
+// system: osascript(1) on macOS, notify-send(1) on Linux and other Unix
+// systems, and PowerShell on Windows. When none of them is available or works,
+// it rings the terminal bell instead.
+type SystemNotifier struct {
+	GOOS     string
+	LookPath func(file string) (string, error)
+	Run      func(name string, args ...string) error
+	Bell     io.Writer
+}
+
+func (n *SystemNotifier) Notify(title, message string) error {
+	if name, args := notifyCommand(n.GOOS, title, message); name != "" {
+		if _, err := n.LookPath(name); err == nil {
+			if err = n.Run(name, args...); err == nil {
+				return nil
+			}
+		}
+	}
+	_, err := fmt.Fprint(n.Bell, "\a")
+	return err
+}
+
+func notifyCommand(goos, title, message string) (string, []string) {
+	switch goos {
+	case "darwin":
+		script := fmt.Sprintf("display notification %s with title %s", appleScriptString(message), appleScriptString(title))
+		return "osascript", []string{"-e", script}
+	case "windows":
+		script := fmt.Sprintf(`[Windows.UI.Notifications.ToastNotificationManager, Windows.UI.Notifications, ContentType = WindowsRuntime] > $null
+$template = [Windows.UI.Notifications.ToastNotificationManager]::GetTemplateContent([Windows.UI.Notifications.ToastTemplateType]::ToastText02)
+$texts = $template.GetElementsByTagName('text')
+$texts.Item(0).AppendChild($template.CreateTextNode(%s)) > $null
+$texts.Item(1).AppendChild($template.CreateTextNode(%s)) > $null
+[Windows.UI.Notifications.ToastNotificationManager]::CreateToastNotifier('hub').Show([Windows.UI.Notifications.ToastNotification]::new($template))`,
+			powerShellString(title), powerShellString(message))
+		return "powershell", []string{"-NoProfile", "-NonInteractive", "-Command", script}
+	default:
+		return "notify-send", []string{title, message}
+	}
+}
+
+func appleScriptString(s string) string {
+	s = strings.Replace(s, `\`, `\\`, -1)
+	return `"` + strings.Replace(s, `"`, `\"`, -1) + `"`
+}
+
+func powerShellString(s string) string {
+	return "'" + strings.Replace(s, "'", "''", -1) + "'"
+}
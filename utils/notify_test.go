@@ -0,0 +1,54 @@
+package utils
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/bmizerany/assert"
+)
+
+func TestNotifyCommand(t *testing.T) {
+	name, args := notifyCommand("darwin", `hub "ci-status"`, `mislav/dotfiles@main: success \o/`)
+	assert.Equal(t, "osascript", name)
+	assert.Equal(t, []string{"-e", `display notification "mislav/dotfiles@main: success \\o/" with title "hub \"ci-status\""`}, args)
+
+	name, args = notifyCommand("linux", "hub ci-status", "mislav/dotfiles@main: failure")
+	assert.Equal(t, "notify-send", name)
+	assert.Equal(t, []string{"hub ci-status", "mislav/dotfiles@main: failure"}, args)
+
+	name, args = notifyCommand("windows", "hub ci-status", "mislav/dotfiles@it's: pending")
+	assert.Equal(t, "powershell", name)
+	assert.Equal(t, "-Command", args[2])
+	assert.T(t, strings.Contains(args[3], `CreateTextNode('mislav/dotfiles@it''s: pending')`))
+}
+
+func TestSystemNotifier(t *testing.T) {
+	ran := []string{}
+	bell := &bytes.Buffer{}
+	notifier := &SystemNotifier{
+		GOOS:     "linux",
+		LookPath: func(file string) (string, error) { return "/usr/bin/" + file, nil },
+		Run: func(name string, args ...string) error {
+			ran = append(ran, name+" "+strings.Join(args, " "))
+			return nil
+		},
+		Bell: bell,
+	}
+
+	assert.Equal(t, nil, notifier.Notify("hub", "done"))
+	assert.Equal(t, []string{"notify-send hub done"}, ran)
+	assert.Equal(t, "", bell.String())
+
+	notifier.Run = func(name string, args ...string) error {
+		return errors.New("no session bus")
+	}
+	assert.Equal(t, nil, notifier.Notify("hub", "done"))
+	assert.Equal(t, "\a", bell.String())
+
+	bell.Reset()
+	notifier.LookPath = func(file string) (string, error) { return "", errors.New("not found") }
+	assert.Equal(t, nil, notifier.Notify("hub", "done"))
+	assert.Equal(t, "\a", bell.String())
+}
@@ -18,7 +18,8 @@ var timeNow = time.Now
 func Check(err error) {
 	if err != nil {
 		ui.Errorln(err)
-		os.Exit(1)
+		ui.EmitFailure(err.Error(), ExitStatus(err))
+		os.Exit(ExitStatus(err))
 	}
 }
 